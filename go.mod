@@ -7,13 +7,16 @@ require github.com/go-chi/chi/v5 v5.2.2
 require (
 	github.com/alecthomas/chroma/v2 v2.20.0
 	github.com/chromedp/chromedp v0.15.1
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/go-git/go-git/v5 v5.16.4
 	github.com/go-pdf/fpdf v0.8.0
+	github.com/lib/pq v1.12.3
 	github.com/sergi/go-diff v1.3.2-0.20230802210424-5b0b94c5c0d3
 	github.com/swaggo/http-swagger/v2 v2.0.2
 	github.com/swaggo/swag v1.16.6
 	github.com/yuin/goldmark v1.7.8
 	golang.org/x/text v0.34.0
+	golang.org/x/time v0.15.0
 	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.50.1
 )
@@ -22,18 +22,15 @@ var storage CacheStorage
 
 // Init initializes cache storage with the specified provider
 func Init(provider, storagePath string) error {
-	var err error
-
 	switch provider {
-	case "sqlite":
-		storage, err = newSQLiteStorage(storagePath)
-	case "json":
-		storage, err = newJSONStorage(storagePath)
+	case "sqlite", "json", "memory":
 	default:
 		logging.LogWarning(logging.KeyApp, "unknown cache storage provider '%s', using sqlite", provider)
-		storage, err = newSQLiteStorage(storagePath)
+		provider = "sqlite"
 	}
 
+	var err error
+	storage, err = Open(provider, storagePath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize cache storage: %w", err)
 	}
@@ -42,6 +39,21 @@ func Init(provider, storagePath string) error {
 	return nil
 }
 
+// Open creates a CacheStorage instance for the given provider without making it the active
+// storage, e.g. for the in-app storage suite.
+func Open(provider, storagePath string) (CacheStorage, error) {
+	switch provider {
+	case "sqlite":
+		return newSQLiteStorage(storagePath)
+	case "json":
+		return newJSONStorage(storagePath)
+	case "memory":
+		return newMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown cache storage provider: %s", provider)
+	}
+}
+
 // Get retrieves data by key
 func Get(key string) ([]byte, error) {
 	return storage.Get(key)
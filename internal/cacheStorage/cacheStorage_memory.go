@@ -0,0 +1,94 @@
+// Package cacheStorage - in-memory backend implementation
+package cacheStorage
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"knov/internal/logging"
+)
+
+// memoryStorage implements CacheStorage interface in memory.
+// Nothing is persisted: all data is lost when the storage is initialized again.
+type memoryStorage struct {
+	data  map[string][]byte
+	mutex sync.RWMutex
+}
+
+// newMemoryStorage creates a new empty in-memory cache storage instance
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+// Get retrieves data by key
+func (ms *memoryStorage) Get(key string) ([]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	data, ok := ms.data[key]
+	if !ok {
+		return nil, nil
+	}
+	logging.LogDebug(logging.KeyApp, "retrieved cache data for key: %s", key)
+	return slices.Clone(data), nil
+}
+
+// Set stores data with key
+func (ms *memoryStorage) Set(key string, data []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.data[key] = slices.Clone(data)
+	logging.LogDebug(logging.KeyApp, "stored cache data for key: %s", key)
+	return nil
+}
+
+// Delete removes data by key
+func (ms *memoryStorage) Delete(key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.data, key)
+	logging.LogDebug(logging.KeyApp, "deleted cache data for key: %s", key)
+	return nil
+}
+
+// List returns all keys with given prefix, sorted like the json backend's directory walk
+func (ms *memoryStorage) List(prefix string) ([]string, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	var keys []string
+	for _, key := range slices.Sorted(maps.Keys(ms.data)) {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Exists checks if key exists
+func (ms *memoryStorage) Exists(key string) bool {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	_, ok := ms.data[key]
+	return ok
+}
+
+// GetBackendType returns the backend type
+func (ms *memoryStorage) GetBackendType() string {
+	return "memory"
+}
+
+// Flush removes all cache entries
+func (ms *memoryStorage) Flush() error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	clear(ms.data)
+	logging.LogInfo(logging.KeyApp, "cache flushed")
+	return nil
+}
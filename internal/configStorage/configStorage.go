@@ -22,16 +22,15 @@ var storage ConfigStorage
 
 // Init initializes config storage with the specified provider
 func Init(provider, storagePath string) error {
-	var err error
-
 	switch provider {
-	case "json":
-		storage, err = newJSONStorage(storagePath)
+	case "json", "memory":
 	default:
 		logging.LogWarning(logging.KeyApp, "unknown config storage provider '%s', using json", provider)
-		storage, err = newJSONStorage(storagePath)
+		provider = "json"
 	}
 
+	var err error
+	storage, err = Open(provider, storagePath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize config storage: %w", err)
 	}
@@ -40,6 +39,19 @@ func Init(provider, storagePath string) error {
 	return nil
 }
 
+// Open creates a ConfigStorage instance for the given provider without making it the active
+// storage, e.g. for the in-app storage suite.
+func Open(provider, storagePath string) (ConfigStorage, error) {
+	switch provider {
+	case "json":
+		return newJSONStorage(storagePath)
+	case "memory":
+		return newMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown config storage provider: %s", provider)
+	}
+}
+
 // Get retrieves config value by key
 func Get(key string) ([]byte, error) {
 	return storage.Get(key)
@@ -0,0 +1,96 @@
+// Package configStorage - in-memory backend implementation
+package configStorage
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"knov/internal/logging"
+)
+
+// memoryStorage implements ConfigStorage interface in memory.
+// Nothing is persisted: all data is lost when the storage is initialized again.
+type memoryStorage struct {
+	data  map[string][]byte
+	mutex sync.RWMutex
+}
+
+// newMemoryStorage creates a new empty in-memory config storage instance
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+// Get retrieves config value by key
+func (ms *memoryStorage) Get(key string) ([]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	data, ok := ms.data[key]
+	if !ok {
+		return nil, nil
+	}
+	logging.LogDebug(logging.KeyApp, "retrieved config for key: %s", key)
+	return slices.Clone(data), nil
+}
+
+// Set stores config value with key
+func (ms *memoryStorage) Set(key string, data []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.data[key] = slices.Clone(data)
+	logging.LogDebug(logging.KeyApp, "stored config for key: %s", key)
+	return nil
+}
+
+// Delete removes config value by key
+func (ms *memoryStorage) Delete(key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.data, key)
+	logging.LogDebug(logging.KeyApp, "deleted config for key: %s", key)
+	return nil
+}
+
+// GetAll returns all config key-value pairs
+func (ms *memoryStorage) GetAll() (map[string][]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	result := make(map[string][]byte, len(ms.data))
+	for key, data := range ms.data {
+		result[key] = slices.Clone(data)
+	}
+	return result, nil
+}
+
+// List returns all keys with given prefix, sorted like the json backend's directory walk
+func (ms *memoryStorage) List(prefix string) ([]string, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	var keys []string
+	for _, key := range slices.Sorted(maps.Keys(ms.data)) {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Exists checks if config key exists
+func (ms *memoryStorage) Exists(key string) bool {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	_, ok := ms.data[key]
+	return ok
+}
+
+// GetBackendType returns the backend type
+func (ms *memoryStorage) GetBackendType() string {
+	return "memory"
+}
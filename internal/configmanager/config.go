@@ -38,10 +38,16 @@ type AppConfig struct {
 	GitPassword             string
 	GitToken                string
 	GitSSHKey               string
+	GitSnapshot             bool // periodic snapshot commits of the data directory
+	GitSnapshotInterval     string
+	GitSnapshotMessage      string // {timestamp} is replaced with the commit time
 	ConfigStorageProvider   string
 	MetadataStorageProvider string
+	MetadataPostgresDSN     string
 	CacheStorageProvider    string
 	SearchStorageProvider   string
+	StorageCompactOnStart   bool
+	StorageCompactThreshold int // percent of free pages above which a database is vacuumed on start
 	KanbanEventsEnabled     bool
 	KanbanEventsProvider    string
 	SearchEngine            string
@@ -49,6 +55,9 @@ type AppConfig struct {
 	CronjobInterval         string
 	SearchIndexInterval     string
 	MetadataRebuildInterval string
+	BackupInterval          string // empty disables the periodic backup
+	BackupPath              string
+	BackupKeep              int // number of most recent backups kept
 	KanbanPrefix            string
 	KanbanStatuses          []string
 	KanbanColumns           []string
@@ -59,6 +68,9 @@ type AppConfig struct {
 	KanbanBoards            []KanbanBoard
 	NotifyDuration          int
 	DefaultEditor           string
+	AgendaToken             string // required by the agenda feed when set
+	APIToken                string // bearer token required by the api when set
+	APITokenProtectReads    bool   // also require the api token for GET and HEAD requests
 }
 
 // KanbanBoard maps a folder to a kanban board with a display name and a stable URL slug
@@ -103,10 +115,16 @@ func InitAppConfig() {
 		GitPassword:             getEnv("KNOV_GIT_PASSWORD", ""),
 		GitToken:                getEnv("KNOV_GIT_TOKEN", ""),
 		GitSSHKey:               getEnv("KNOV_GIT_SSH_KEY", ""),
+		GitSnapshot:             getBoolEnv("KNOV_GIT_SNAPSHOT", false),
+		GitSnapshotInterval:     getEnv("KNOV_GIT_SNAPSHOT_INTERVAL", "1h"),
+		GitSnapshotMessage:      getEnv("KNOV_GIT_SNAPSHOT_MESSAGE", "auto-snapshot {timestamp}"),
 		ConfigStorageProvider:   getEnv("KNOV_CONFIG_STORAGE_PROVIDER", "json"),
 		MetadataStorageProvider: getEnv("KNOV_METADATA_STORAGE_PROVIDER", "sqlite"),
+		MetadataPostgresDSN:     getEnv("KNOV_METADATA_POSTGRES_DSN", ""),
 		CacheStorageProvider:    getEnv("KNOV_CACHE_STORAGE_PROVIDER", "sqlite"),
 		SearchStorageProvider:   getEnv("KNOV_SEARCH_STORAGE_PROVIDER", "sqlite"),
+		StorageCompactOnStart:   getBoolEnv("KNOV_STORAGE_COMPACT_ON_START", false),
+		StorageCompactThreshold: getIntEnv("KNOV_STORAGE_COMPACT_THRESHOLD", 20),
 		KanbanEventsEnabled:     getBoolEnv("KNOV_KANBAN_EVENTS_ENABLED", true),
 		KanbanEventsProvider:    getEnv("KNOV_KANBAN_EVENTS_STORAGE_PROVIDER", "sqlite"),
 		SearchEngine:            getEnv("KNOV_SEARCH_ENGINE", "repository"),
@@ -119,6 +137,9 @@ func InitAppConfig() {
 		CronjobInterval:         getEnv("KNOV_CRONJOB_INTERVAL", "5m"),
 		SearchIndexInterval:     getEnv("KNOV_SEARCH_INDEX_INTERVAL", "15m"),
 		MetadataRebuildInterval: getEnv("KNOV_METADATA_REBUILD_INTERVAL", "60m"),
+		BackupInterval:          getEnv("KNOV_BACKUP_INTERVAL", ""),
+		BackupPath:              getEnv("KNOV_BACKUP_PATH", filepath.Join(baseDir, "backups")),
+		BackupKeep:              getIntEnv("KNOV_BACKUP_KEEP", 7),
 		KanbanPrefix:            getEnv("KNOV_KANBAN_PREFIX", "kb"),
 		KanbanStatuses:          getStringListEnv("KNOV_KANBAN_STATUS", []string{"inbox", "inprogress", "blocked", "archive"}),
 		KanbanColumns:           getStringListEnv("KNOV_KANBAN_COLUMNS", []string{"inbox", "inprogress", "blocked"}),
@@ -129,6 +150,9 @@ func InitAppConfig() {
 		KanbanBoards:            getKanbanBoardsEnv("KNOV_KANBAN_BOARDS"),
 		NotifyDuration:          getIntEnv("KNOV_NOTIFY_DURATION", 3500),
 		DefaultEditor:           getEnv("KNOV_DEFAULT_EDITOR", ""),
+		AgendaToken:             getEnv("KNOV_AGENDA_TOKEN", ""),
+		APIToken:                getEnv("KNOV_API_TOKEN", ""),
+		APITokenProtectReads:    getBoolEnv("KNOV_API_TOKEN_PROTECT_READS", false),
 	}
 
 	initLogLevel()
@@ -489,6 +513,21 @@ func InitGitRepository() error {
 	return nil
 }
 
+// GetAgendaToken returns the token the agenda feed requires, empty when the feed is public.
+func GetAgendaToken() string {
+	return appConfig.AgendaToken
+}
+
+// GetAPIToken returns the bearer token the api requires, empty when the api is open.
+func GetAPIToken() string {
+	return appConfig.APIToken
+}
+
+// GetAPITokenProtectReads reports whether GET and HEAD api requests need the api token too.
+func GetAPITokenProtectReads() bool {
+	return appConfig.APITokenProtectReads
+}
+
 // GetSearchEngine ..
 func GetSearchEngine() string {
 	return appConfig.SearchEngine
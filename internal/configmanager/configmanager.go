@@ -55,6 +55,9 @@ func SetLanguage(lang string) {
 // GetTheme returns the current theme name.
 func GetTheme() string { return Theme.Get() }
 
+// GetThemeQueryOverride reports whether ?theme= may render a request with another theme.
+func GetThemeQueryOverride() bool { return ThemeQueryOverride.Get() }
+
 // SetTheme updates the theme and persists.
 func SetTheme(theme string) {
 	Theme.SetFromString(theme) //nolint:errcheck // theme is a dynamic-select, no Options to validate against
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"mime"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"knov/internal/configStorage"
 	"knov/internal/logging"
 	"knov/internal/translation"
+	"knov/internal/utils"
 )
 
 // ── init/save ─────────────────────────────────────────────────────────────────
@@ -51,6 +54,7 @@ func InitSettings() {
 
 func applyLanguage(lang string) {
 	translation.SetLanguage(CheckLanguage(lang))
+	translation.SetFallbackLanguages(LanguageFallback.Get())
 }
 
 // SaveSettings persists all registry values to storage.
@@ -196,6 +200,7 @@ func GetBorderStyle() string {
 func GetShowCaption() bool          { return ShowCaption.Get() }
 func GetClickToEnlarge() bool       { return ClickToEnlarge.Get() }
 func GetAllowedMimeTypes() []string { return AllowedMimeTypes.Get() }
+func GetCacheExternalImages() bool  { return CacheExternalImages.Get() }
 
 func GetTablePageSize() int {
 	s := PageSize.Get()
@@ -204,8 +209,280 @@ func GetTablePageSize() int {
 	}
 	return s
 }
-func GetShowHiddenFiles() bool { return ShowHiddenFiles.Get() }
-func GetHomeDashboard() string { return HomeDashboard.Get() }
+func GetDatalistOptionsLimit() int {
+	l := DatalistOptionsLimit.Get()
+	if l <= 0 {
+		return 200
+	}
+	return l
+}
+func GetFilterResultCap() int {
+	c := FilterResultCap.Get()
+	if c <= 0 {
+		return 1000
+	}
+	return c
+}
+
+func GetFilterDefaultLogic() string {
+	if l := FilterDefaultLogic.Get(); l != "" {
+		return l
+	}
+	return "and"
+}
+
+func GetFilterTableColumns() []string {
+	c := FilterTableColumns.Get()
+	if len(c) == 0 {
+		return []string{"title", "collection", "tags", "lastEdited"}
+	}
+	return c
+}
+
+// GetSearchExportLimit returns the maximum number of files in a search result export.
+func GetSearchExportLimit() int { return SearchExportLimit.Get() }
+
+// GetEmbedMaxBytes returns the size after which embedded notes are truncated, 0 for no limit.
+func GetEmbedMaxBytes() int { return max(EmbedMaxBytes.Get(), 0) }
+
+// GetSearchFuzzyDistance returns the edit distance of the fuzzy search fallback, 0 when disabled.
+func GetSearchFuzzyDistance() int { return SearchFuzzyDistance.Get() }
+
+// GetSearchSnippetTokens returns the number of words in the excerpts of search results.
+func GetSearchSnippetTokens() int { return SearchSnippetTokens.Get() }
+
+// GetSearchMaxIndexBytes returns the size above which files are not search indexed, 0 for no limit.
+func GetSearchMaxIndexBytes() int64 { return int64(max(SearchMaxIndexBytes.Get(), 0)) }
+func GetDashboardRenderWorkers() int {
+	n := DashboardRenderWorkers.Get()
+	if n <= 0 {
+		return 4
+	}
+	return n
+}
+
+// GetResponseSizeWarnBytes returns the response body size above which a warning is logged, 0 for never.
+func GetResponseSizeWarnBytes() int { return max(ResponseSizeWarnBytes.Get(), 0) }
+
+// MaxPageSize is the largest pageSize a paginated file list request may ask for.
+const MaxPageSize = 1000
+
+// GetFileListPageSize returns the default page size of the file list and filter results.
+func GetFileListPageSize() int { return min(max(FileListPageSize.Get(), 1), MaxPageSize) }
+func GetFileListSort() string {
+	s := FileListSort.Get()
+	if s == "" {
+		return "path"
+	}
+	return s
+}
+func GetFileListOrder() string       { return FileListOrder.Get() }
+func GetShowHiddenFiles() bool       { return ShowHiddenFiles.Get() }
+func GetArchiveTier() bool           { return ArchiveTier.Get() }
+func GetLastModifiedHeaders() bool   { return LastModifiedHeaders.Get() }
+func GetPruneEmptyFolders() bool     { return PruneEmptyFolders.Get() }
+func GetRetitleOnSave() bool         { return RetitleOnSave.Get() }
+func GetCreateMissingParents() bool  { return CreateMissingParents.Get() }
+func GetFrontMatterWins() bool       { return FrontMatterWins.Get() }
+func GetStrictMetadataJSON() bool    { return StrictMetadataJSON.Get() }
+func GetStrictPaths() bool           { return StrictPaths.Get() }
+func GetStripTrailingSlashes() bool  { return StripTrailingSlashes.Get() }
+func GetCaseInsensitiveRoutes() bool { return CaseInsensitiveRoutes.Get() }
+func GetHomeDashboard() string       { return HomeDashboard.Get() }
+func GetDefaultTags() []string       { return DefaultTags.Get() }
+func GetDefaultStatus() string       { return DefaultStatus.Get() }
+func GetDefaultPriority() string     { return DefaultPriority.Get() }
+func GetJournalTemplate() string     { return JournalTemplate.Get() }
+func GetLabelColors() []string       { return LabelColors.Get() }
+func GetMetadataHistoryLimit() int   { return max(MetadataHistoryLimit.Get(), 0) }
+func GetSuggestSimilarNotes() int    { return max(SuggestSimilarNotes.Get(), 0) }
+func GetEventStreamLimit() int       { return max(EventStreamLimit.Get(), 1) }
+func GetJobQueueSize() int           { return max(JobQueueSize.Get(), 1) }
+func GetRateLimitRebuild() int       { return max(RateLimitRebuild.Get(), 0) }
+func GetRateLimitSearch() int        { return max(RateLimitSearch.Get(), 0) }
+func GetMaxHierarchyDepth() int      { return max(MaxHierarchyDepth.Get(), 1) }
+
+// GetNewFileTemplate returns the docs-relative template configured for new files of editor,
+// or "" when there is none.
+func GetNewFileTemplate(editor string) string {
+	for _, entry := range NewFileTemplates.Get() {
+		if e, tpl, ok := strings.Cut(entry, "="); ok && strings.TrimSpace(e) == editor {
+			return strings.TrimSpace(tpl)
+		}
+	}
+	return ""
+}
+
+// faIconPattern matches a Font Awesome icon class such as fa-file-lines.
+var faIconPattern = regexp.MustCompile(`^fa-[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validateFiletypeIcons checks that every filetypeIcons entry is an editor=icon pair
+// whose icon is a Font Awesome class.
+func validateFiletypeIcons(entries []string) error {
+	for _, entry := range entries {
+		editor, icon, ok := strings.Cut(entry, "=")
+		editor, icon = strings.TrimSpace(editor), strings.TrimSpace(icon)
+		if !ok || editor == "" {
+			return fmt.Errorf("invalid filetype icon %q, expected editor=icon", entry)
+		}
+		if !faIconPattern.MatchString(icon) {
+			return fmt.Errorf("invalid icon %q for %s, expected a Font Awesome class like fa-file", icon, editor)
+		}
+	}
+	return nil
+}
+
+// labelColorPattern matches a css color name or a #rgb/#rrggbb hex color.
+var labelColorPattern = regexp.MustCompile(`^([a-z]+|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+
+// validateLabelColors checks that every labelColors entry is a color name or hex color,
+// so labels can be put into style attributes as they are.
+func validateLabelColors(colors []string) error {
+	for _, color := range colors {
+		if !labelColorPattern.MatchString(color) {
+			return fmt.Errorf("invalid label color %q, expected a color name like red or a hex color like #ff8800", color)
+		}
+	}
+	return nil
+}
+
+// GetFiletypeIcons returns the configured editor type to icon overrides.
+func GetFiletypeIcons() map[string]string {
+	icons := make(map[string]string)
+	for _, entry := range FiletypeIcons.Get() {
+		if editor, icon, ok := strings.Cut(entry, "="); ok {
+			icons[strings.TrimSpace(editor)] = strings.TrimSpace(icon)
+		}
+	}
+	return icons
+}
+
+// CustomFiletype is a filetype registered in the customFiletypes setting. Editor is the
+// editor type its files open in, "" for the textarea editor.
+type CustomFiletype struct {
+	Name   string `json:"name"`
+	Label  string `json:"label"`
+	Editor string `json:"editor,omitempty"`
+}
+
+// String returns the customFiletypes entry of the filetype.
+func (f CustomFiletype) String() string {
+	if f.Editor == "" {
+		return f.Name + "=" + f.Label
+	}
+	return f.Name + "=" + f.Label + ":" + f.Editor
+}
+
+// filetypeNamePattern matches a custom filetype name such as recipe or meeting-notes.
+var filetypeNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// parseCustomFiletype reads a customFiletypes entry of the form type=label or
+// type=label:editor.
+func parseCustomFiletype(entry string) (CustomFiletype, error) {
+	name, rest, ok := strings.Cut(entry, "=")
+	label, editor, _ := strings.Cut(rest, ":")
+	filetype := CustomFiletype{Name: strings.TrimSpace(name), Label: strings.TrimSpace(label), Editor: strings.TrimSpace(editor)}
+	if !ok || filetype.Label == "" {
+		return filetype, fmt.Errorf("invalid custom filetype %q, expected type=label:editor", entry)
+	}
+	if !filetypeNamePattern.MatchString(filetype.Name) {
+		return filetype, fmt.Errorf("invalid filetype name %q, expected lowercase letters, digits and dashes", filetype.Name)
+	}
+	return filetype, nil
+}
+
+// validateCustomFiletypes checks that every customFiletypes entry parses and that no
+// filetype is registered twice.
+func validateCustomFiletypes(entries []string) error {
+	var names []string
+	for _, entry := range entries {
+		filetype, err := parseCustomFiletype(entry)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(names, filetype.Name) {
+			return fmt.Errorf("filetype %s is registered twice", filetype.Name)
+		}
+		names = append(names, filetype.Name)
+	}
+	return nil
+}
+
+// GetCustomFiletypes returns the filetypes registered in the customFiletypes setting.
+func GetCustomFiletypes() []CustomFiletype {
+	var filetypes []CustomFiletype
+	for _, entry := range CustomFiletypes.Get() {
+		if filetype, err := parseCustomFiletype(entry); err == nil {
+			filetypes = append(filetypes, filetype)
+		}
+	}
+	return filetypes
+}
+
+// GetTagSynonyms returns the configured tag synonym pairs, lowercased.
+func GetTagSynonyms() [][2]string {
+	var pairs [][2]string
+	for _, entry := range TagSynonyms.Get() {
+		tag, synonym, ok := strings.Cut(entry, "=")
+		tag, synonym = strings.ToLower(strings.TrimSpace(tag)), strings.ToLower(strings.TrimSpace(synonym))
+		if ok && tag != "" && synonym != "" && tag != synonym {
+			pairs = append(pairs, [2]string{tag, synonym})
+		}
+	}
+	return pairs
+}
+
+// GetCollectionMOCPath returns the docs-relative path the MOC of collection is generated at.
+// The collection is inserted as a single path segment, see mocCollectionSegment.
+func GetCollectionMOCPath(collection string) string {
+	p := CollectionMOCPath.Get()
+	if p == "" {
+		p = "{{collection}}/{{collection}}.moc"
+	}
+	return strings.Trim(strings.ReplaceAll(p, "{{collection}}", mocCollectionSegment(collection)), "/")
+}
+
+// mocCollectionSegment makes a collection name safe to use as one path segment. Collections
+// are top level folder names and pass unchanged; path separators become "-" and a name of
+// only dots, which would point at the docs folder or above it, is sanitized like a filename.
+func mocCollectionSegment(collection string) string {
+	segment := strings.NewReplacer("/", "-", `\`, "-", "\x00", "").Replace(collection)
+	if strings.Trim(segment, ". ") == "" {
+		return utils.SanitizeFilename(collection, 0, false, false)
+	}
+	return segment
+}
+
+// GetJournalFolder returns the docs-relative folder daily journal entries live in.
+func GetJournalFolder() string {
+	f := strings.Trim(JournalFolder.Get(), "/")
+	if f == "" {
+		return "journal"
+	}
+	return f
+}
+
+// IsCollectionExcluded reports whether a collection is hidden from collection aggregations.
+func IsCollectionExcluded(collection string) bool {
+	return slices.Contains(ExcludedCollections.Get(), collection)
+}
+
+// IsCollectionPrivate reports whether a collection is kept out of exports.
+func IsCollectionPrivate(collection string) bool {
+	return collection != "" && slices.Contains(PrivateCollections.Get(), collection)
+}
+
+// GetDefaultMarkdownEditor returns the editor assigned to new and unassigned markdown files.
+// KNOV_DEFAULT_EDITOR env var takes precedence over the user setting.
+func GetDefaultMarkdownEditor() string {
+	if appConfig.DefaultEditor != "" {
+		return appConfig.DefaultEditor
+	}
+	return DefaultMarkdownEditor.Get()
+}
+
+// GetEditorLinkTemplate returns the external editor deep link template with its {path} placeholder.
+func GetEditorLinkTemplate() string { return EditorLinkTemplate.Get() }
 
 // ── mime / extension helpers ──────────────────────────────────────────────────
 
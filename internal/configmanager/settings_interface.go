@@ -85,6 +85,7 @@ type BoolSetting struct {
 	Trigger  string
 	Target   string
 	OnChange func(interface{})
+	Validate func(bool) error
 }
 
 func (s *BoolSetting) Get() bool {
@@ -100,12 +101,25 @@ func (s *BoolSetting) GetMeta() Meta {
 	return Meta{Section: s.Section, Group: s.Group, Label: s.Label, Desc: s.Desc, Trigger: s.Trigger, Target: s.Target}
 }
 func (s *BoolSetting) setFromJSON(v interface{}) {
-	if b, ok := v.(bool); ok {
-		s.val.Store(&b)
+	b, ok := v.(bool)
+	if !ok {
+		return
+	}
+	if s.Validate != nil {
+		if err := s.Validate(b); err != nil {
+			logging.LogWarning(logging.KeyApp, "setting %q: ignoring stored value %v: %v", s.key, b, err)
+			return
+		}
 	}
+	s.val.Store(&b)
 }
 func (s *BoolSetting) SetFromString(v string) error {
 	b, _ := strconv.ParseBool(v) // empty string → false (unchecked checkbox)
+	if s.Validate != nil {
+		if err := s.Validate(b); err != nil {
+			return err
+		}
+	}
 	s.val.Store(&b)
 	if s.OnChange != nil {
 		s.OnChange(b)
@@ -273,6 +287,7 @@ type StringSliceSetting struct {
 	Trigger  string
 	Target   string
 	OnChange func(interface{})
+	Validate func([]string) error
 }
 
 func (s *StringSliceSetting) Get() []string {
@@ -288,18 +303,27 @@ func (s *StringSliceSetting) GetMeta() Meta {
 	return Meta{Section: s.Section, Group: s.Group, Label: s.Label, Desc: s.Desc, Trigger: s.Trigger, Target: s.Target}
 }
 func (s *StringSliceSetting) setFromJSON(v interface{}) {
+	var result []string
 	switch val := v.(type) {
 	case []interface{}:
-		result := make([]string, 0, len(val))
+		result = make([]string, 0, len(val))
 		for _, item := range val {
 			if str, ok := item.(string); ok {
 				result = append(result, str)
 			}
 		}
-		s.val.Store(&result)
 	case []string:
-		s.val.Store(&val)
+		result = val
+	default:
+		return
+	}
+	if s.Validate != nil {
+		if err := s.Validate(result); err != nil {
+			logging.LogWarning(logging.KeyApp, "setting %q: ignoring stored value %v: %v", s.key, result, err)
+			return
+		}
 	}
+	s.val.Store(&result)
 }
 func (s *StringSliceSetting) SetFromString(v string) error {
 	parts := strings.Split(v, ",")
@@ -310,6 +334,11 @@ func (s *StringSliceSetting) SetFromString(v string) error {
 			result = append(result, p)
 		}
 	}
+	if s.Validate != nil {
+		if err := s.Validate(result); err != nil {
+			return err
+		}
+	}
 	s.val.Store(&result)
 	if s.OnChange != nil {
 		s.OnChange(result)
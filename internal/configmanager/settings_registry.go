@@ -2,9 +2,14 @@ package configmanager
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"knov/internal/translation"
+
+	"golang.org/x/text/language"
 )
 
 //nolint:gochecknoglobals
@@ -111,6 +116,19 @@ var (
 		Label: "Wiki Link Autocomplete: Jump Cursor Past ]]",
 		Desc:  "when off, the cursor lands before ]] after autocomplete (between the path and the closing brackets)",
 	})
+	EditorLinkTemplate = register(&StringSetting{
+		key: "editorLinkTemplate", Default: "vscode://file{path}",
+		Section: SectionEditor, Group: GroupAllEditors,
+		Label:   "External Editor Link",
+		Desc:    "deep link opening a file in a desktop editor, {path} is replaced with the url-encoded absolute file path (e.g. vscode://file{path} or obsidian://open?path={path})",
+		Trigger: "change delay:500ms",
+		Validate: func(v string) error {
+			if !strings.Contains(v, "{path}") || !strings.Contains(v, ":") {
+				return fmt.Errorf("must contain a scheme and {path}")
+			}
+			return nil
+		},
+	})
 
 	// ── Editor / Section Editing ──────────────────────────────────────────────
 	SectionEditIncludeSubheaders = register(&BoolSetting{
@@ -203,6 +221,12 @@ var (
 		Desc:    "comma-separated MIME types accepted for upload (e.g. image/*, application/pdf)",
 		Trigger: "change delay:1s",
 	})
+	CacheExternalImages = register(&BoolSetting{
+		key: "cacheExternalImages", Default: false,
+		Section: SectionMedia,
+		Label:   "Cache External Images",
+		Desc:    "when a markdown file is saved, download the external images it embeds (![alt](https://...)) into the media folder and link the local copy instead; the max upload size and allowed MIME types apply",
+	})
 	EnablePreviews = register(&BoolSetting{
 		key: "enablePreviews", Default: true,
 		Section: SectionMedia, Group: GroupPreviewSettings,
@@ -342,6 +366,12 @@ var (
 		DynURL:  "/api/themes/",
 		Refresh: true,
 	})
+	ThemeQueryOverride = register(&BoolSetting{
+		key: "themeQueryOverride", Default: true,
+		Section: SectionGeneral, Group: GroupNone,
+		Label: "Theme Query Parameter",
+		Desc:  "let ?theme=<name> render a single page with another installed theme, e.g. for testing or embedding, without changing the theme above",
+	})
 
 	Language = register(&StringSetting{
 		key: "language", Default: "en",
@@ -364,6 +394,26 @@ var (
 			}
 		},
 	})
+	LanguageFallback = register(&StringSliceSetting{
+		key: "languageFallback", Default: []string{"en"},
+		Section: SectionGeneral, Group: GroupNone,
+		Label:   "Language Fallback",
+		Desc:    "comma-separated languages tried in order when a text isn't translated in the chosen language or its base language (e.g. de, en); the untranslated text is shown last",
+		Trigger: "change delay:1s",
+		Validate: func(v []string) error {
+			for _, lang := range v {
+				if _, err := language.Parse(lang); err != nil {
+					return fmt.Errorf("invalid language %q", lang)
+				}
+			}
+			return nil
+		},
+		OnChange: func(v interface{}) {
+			if langs, ok := v.([]string); ok {
+				translation.SetFallbackLanguages(langs)
+			}
+		},
+	})
 	DateFormat = register(&StringSetting{
 		key: "dateFormat", Default: "DD.MM.YYYY",
 		Section: SectionGeneral, Group: GroupNone,
@@ -392,10 +442,354 @@ var (
 		Label: "Show Hidden Files",
 		Desc:  "show files and folders starting with a dot",
 	})
+	FileListSort = register(&StringSetting{
+		key: "fileListSort", Default: "path",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Default File Sort",
+		Desc:  "how the file list, browse pages and filter results are sorted",
+		Options: []SettingOption{
+			{"path", "Path"}, {"title", "Title"}, {"createdAt", "Created at"},
+			{"lastEdited", "Last edited"}, {"size", "File size"}, {"wordCount", "Word count"},
+		},
+	})
+	FileListOrder = register(&StringSetting{
+		key: "fileListOrder", Default: "asc",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Default File Order",
+		Desc:    "direction of the default file sort",
+		Options: []SettingOption{{"asc", "Ascending"}, {"desc", "Descending"}},
+	})
+	FileListPageSize = register(&IntSetting{
+		key: "fileListPageSize", Default: 100,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "File List Page Size",
+		Desc:  "how many files a page of the file list and of filter results shows when the request sets no pageSize",
+		Min:   intPtr(1), Max: intPtr(MaxPageSize),
+		Trigger: "change delay:500ms",
+	})
+	ArchiveTier = register(&BoolSetting{
+		key: "archiveTier", Default: false,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Archive Storage Tier",
+		Desc:  "store the metadata of files with the kanban archive status apart from the other files and leave them out of tag, collection, folder and editor counts and search unless includeArchived=true is asked for (not available with the yaml metadata storage)",
+		Validate: func(v bool) error {
+			// yaml keeps the metadata in the front matter of each file, there is no second place to move it to
+			if v && GetMetadataStorageProvider() == "yaml" {
+				return fmt.Errorf("the yaml metadata storage keeps metadata in the files' front matter and has no archive tier")
+			}
+			return nil
+		},
+	})
+	LastModifiedHeaders = register(&BoolSetting{
+		key: "lastModifiedHeaders", Default: true,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Last-Modified Headers",
+		Desc:  "send Last-Modified on file and static responses and answer If-Modified-Since with 304 Not Modified",
+	})
+	StrictPaths = register(&BoolSetting{
+		key: "strictPaths", Default: true,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Strict Paths",
+		Desc:  "reject absolute file paths in requests, even ones pointing into the data folder; paths with .. or leaving the data folder are always rejected",
+	})
+	PruneEmptyFolders = register(&BoolSetting{
+		key: "pruneEmptyFolders", Default: false,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Prune Empty Folders",
+		Desc:  "remove folders left empty after moving, renaming or deleting files",
+	})
+	RetitleOnSave = register(&BoolSetting{
+		key: "retitleOnSave", Default: true,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Update Title On Save",
+		Desc:  "refresh a file's title (its first heading) and word count whenever its content is saved; otherwise they only update on the next metadata save or rebuild",
+	})
+	CreateMissingParents = register(&BoolSetting{
+		key: "createMissingParents", Default: false,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Create Missing Parents",
+		Desc:  "when a file's parent is set to a note that doesn't exist yet, create it as an empty note so the file shows up in its kids",
+	})
+	FrontMatterWins = register(&BoolSetting{
+		key: "frontMatterWins", Default: false,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Front Matter Wins Conflicts",
+		Desc:  "when a file's yaml front matter (tags, status, priority, type, collection, targetDate) differs from its stored metadata, the front matter replaces it on save; otherwise front matter only fills in empty fields",
+	})
+	StrictMetadataJSON = register(&BoolSetting{
+		key: "strictMetadataJSON", Default: true,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Strict Metadata JSON",
+		Desc:  "reject unknown fields, invalid editors and invalid kanban status tags in metadata posted as JSON to /api/metadata instead of ignoring them",
+	})
+	StripTrailingSlashes = register(&BoolSetting{
+		key: "stripTrailingSlashes", Default: true,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Ignore Trailing Slashes",
+		Desc:  "route /files/foo/ like /files/foo instead of answering 404",
+	})
+	CaseInsensitiveRoutes = register(&BoolSetting{
+		key: "caseInsensitiveRoutes", Default: false,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Case-Insensitive Routes",
+		Desc:  "route /HOME or /Files/... like /home and /files/...; only the first path segment is lowercased, file paths stay case-sensitive",
+	})
 	HomeDashboard = register(&StringSetting{
 		key: "homeDashboard", Default: "home",
 		Section: SectionGeneral, Group: GroupFiles,
 		Label: "Home Dashboard",
 		Desc:  "set a dashboard ID to use as the home page",
 	})
+	DefaultTags = register(&StringSliceSetting{
+		key: "defaultTags", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Default Tags",
+		Desc:    "comma-separated tags added to the metadata of new files created without tags (e.g. draft)",
+		Trigger: "change delay:1s",
+	})
+	DefaultStatus = register(&StringSetting{
+		key: "defaultStatus", Default: "",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Default Status",
+		Desc:    "kanban status given to new files created without one (e.g. draft), empty for none - must be one of the kanban statuses",
+		Trigger: "change delay:1s",
+		Validate: func(v string) error {
+			if v != "" && !slices.Contains(GetKanbanStatuses(), v) {
+				return fmt.Errorf("unknown kanban status %q, expected one of %v", v, GetKanbanStatuses())
+			}
+			return nil
+		},
+	})
+	DefaultPriority = register(&StringSetting{
+		key: "defaultPriority", Default: "",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Default Priority",
+		Desc:    "priority custom field given to new files created without one, none to leave it unset",
+		Options: []SettingOption{{"", "None"}, {"low", "Low"}, {"medium", "Medium"}, {"high", "High"}},
+	})
+	TagSynonyms = register(&StringSliceSetting{
+		key: "tagSynonyms", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Tag Synonyms",
+		Desc:    "comma-separated tag=synonym pairs (e.g. ml=machine-learning); saving a file with both tags of a pair shows a warning, the tags are still saved",
+		Trigger: "change delay:1s",
+	})
+	JournalFolder = register(&StringSetting{
+		key: "journalFolder", Default: "journal",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Journal Folder",
+		Desc:    "folder the daily journal entries (YYYY-MM-DD.md) are created in",
+		Trigger: "change delay:1s",
+		Validate: func(v string) error {
+			slashed := strings.ReplaceAll(v, `\`, "/")
+			if strings.HasPrefix(slashed, "/") || filepath.IsAbs(v) || filepath.VolumeName(v) != "" {
+				return fmt.Errorf("must be a folder inside the docs folder, not an absolute path")
+			}
+			if slices.Contains(strings.Split(slashed, "/"), "..") {
+				return fmt.Errorf("must not contain ..")
+			}
+			return nil
+		},
+	})
+	JournalTemplate = register(&StringSetting{
+		key: "journalTemplate", Default: "",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Journal Template",
+		Desc:    "file used as content for new journal entries, {{date}} is replaced with the entry date; empty uses a date heading",
+		Trigger: "change delay:1s",
+	})
+	NewFileTemplates = register(&StringSliceSetting{
+		key: "newFileTemplates", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "New File Templates",
+		Desc:    "comma-separated editor=template pairs (e.g. toastui-editor=templates/literature.md); new files of that editor type created without content start from the template, {{date}} is replaced with today's date",
+		Trigger: "change delay:1s",
+	})
+	FiletypeIcons = register(&StringSliceSetting{
+		key: "filetypeIcons", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:    "Filetype Icons",
+		Desc:     "comma-separated editor=icon pairs (e.g. todo-editor=fa-list-check) overriding the Font Awesome icon shown for files of that editor type",
+		Trigger:  "change delay:1s",
+		Validate: validateFiletypeIcons,
+	})
+	CustomFiletypes = register(&StringSliceSetting{
+		key: "customFiletypes", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:    "Custom Filetypes",
+		Desc:     "comma-separated type=label:editor entries (e.g. recipe=Recipe:toastui-editor) registering filetypes beyond the editor types; files whose type custom field or front matter key names one open in its editor, the textarea editor when none is given",
+		Trigger:  "change delay:1s",
+		Validate: validateCustomFiletypes,
+	})
+	LabelColors = register(&StringSliceSetting{
+		key: "labelColors", Default: []string{"red", "orange", "yellow", "green", "blue", "purple", "gray"},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:    "Label Colors",
+		Desc:     "comma-separated colors files can be labelled with, color names (e.g. red) or hex colors (e.g. #ff8800)",
+		Trigger:  "change delay:1s",
+		Validate: validateLabelColors,
+	})
+	CollectionMOCPath = register(&StringSetting{
+		key: "collectionMocPath", Default: "{{collection}}/{{collection}}.moc",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Collection MOC Path",
+		Desc:    "where generated collection MOCs are written, {{collection}} is replaced with the collection name",
+		Trigger: "change delay:1s",
+	})
+	DatalistOptionsLimit = register(&IntSetting{
+		key: "datalistOptionsLimit", Default: 200,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Datalist Options",
+		Desc:  "how many tag/collection/folder suggestions autocomplete inputs load, most used first; the rest are found by typing",
+		Min:   intPtr(10), Max: intPtr(10000),
+		Trigger: "change delay:500ms",
+	})
+	FilterResultCap = register(&IntSetting{
+		key: "filterResultCap", Default: 1000,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Filter Results",
+		Desc:  "safety cap on how many files a filter returns, regardless of its own limit; capped results are marked as truncated",
+		Min:   intPtr(1), Max: intPtr(100000),
+		Trigger: "change delay:500ms",
+	})
+	MetadataHistoryLimit = register(&IntSetting{
+		key: "metadataHistoryLimit", Default: 100,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Metadata History Limit",
+		Desc:  "how many metadata changes are kept per file in its history, older ones are dropped; 0 records no history",
+		Min:   intPtr(0), Max: intPtr(10000),
+		Trigger: "change delay:500ms",
+	})
+	SuggestSimilarNotes = register(&IntSetting{
+		key: "suggestSimilarNotes", Default: 5,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Suggestion Sources",
+		Desc:  "how many of the most content-similar notes tag and collection suggestions for a new note are taken from; 0 disables suggestions",
+		Min:   intPtr(0), Max: intPtr(100),
+		Trigger: "change delay:500ms",
+	})
+	FilterDefaultLogic = register(&StringSetting{
+		key: "filterDefaultLogic", Default: "and",
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Default Filter Logic",
+		Desc:    "how filter criteria combine when a filter doesn't say: all must match (and) or any may match (or)",
+		Options: []SettingOption{{"and", "All criteria match (and)"}, {"or", "Any criterion matches (or)"}},
+	})
+	FilterTableColumns = register(&StringSliceSetting{
+		key: "filterTableColumns", Default: []string{"title", "collection", "tags", "lastEdited"},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Filter Table Columns",
+		Desc:    "comma-separated metadata fields shown as columns when a filter is displayed as a table (title, path, collection, folders, tags, parents, editor, label, createdAt, lastEdited, kanbanAddedAt, kanbanMovedAt, size, wordCount, readingTimeMinutes or custom.<key>); filters can override them",
+		Trigger: "change delay:1s",
+	})
+	SearchMaxIndexBytes = register(&IntSetting{
+		key: "searchMaxIndexBytes", Default: 5 * 1024 * 1024,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Search Index Size (bytes)",
+		Desc:  "files larger than this are left out of the full-text search index (0 = no limit)",
+		Min:   intPtr(0), Max: intPtr(1024 * 1024 * 1024),
+		Trigger: "change delay:500ms",
+	})
+	SearchExportLimit = register(&IntSetting{
+		key: "searchExportLimit", Default: 1000,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Search Export Limit",
+		Desc:  "maximum number of files a search result export (csv or json) contains",
+		Min:   intPtr(1), Max: intPtr(100000),
+		Trigger: "change delay:500ms",
+	})
+	EmbedMaxBytes = register(&IntSetting{
+		key: "embedMaxBytes", Default: 200 * 1024,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Embedded Content Size (bytes)",
+		Desc:  "notes embedded in another page (file content widgets, filters displayed as content) are cut after this many bytes with a link to the full note (0 = no limit)",
+		Min:   intPtr(0), Max: intPtr(1024 * 1024 * 1024),
+		Trigger: "change delay:500ms",
+	})
+	SearchFuzzyDistance = register(&IntSetting{
+		key: "searchFuzzyDistance", Default: 2,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Fuzzy Search Distance",
+		Desc:  "when a search finds nothing, titles and filenames within this many typos (edit distance) of the query are shown instead; at most a third of the query length, 0 disables",
+		Min:   intPtr(0), Max: intPtr(5),
+		Trigger: "change delay:500ms",
+	})
+	SearchSnippetTokens = register(&IntSetting{
+		key: "searchSnippetTokens", Default: 16,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Search Snippet Length (words)",
+		Desc:  "how many words of context the excerpts of search results show around the matched terms",
+		Min:   intPtr(1), Max: intPtr(64),
+		Trigger: "change delay:500ms",
+	})
+	DashboardRenderWorkers = register(&IntSetting{
+		key: "dashboardRenderWorkers", Default: 4,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Dashboard Render Workers",
+		Desc:  "how many widgets are rendered at the same time when a whole dashboard is rendered server-side; 1 renders them one after another",
+		Min:   intPtr(1), Max: intPtr(32),
+		Trigger: "change delay:500ms",
+	})
+	MaxHierarchyDepth = register(&IntSetting{
+		key: "maxHierarchyDepth", Default: 100,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Parent Chain Depth",
+		Desc:  "how many parents up the ancestor of a file is looked for; longer chains and loops are listed as hierarchy problems",
+		Min:   intPtr(1), Max: intPtr(10000),
+		Trigger: "change delay:500ms",
+	})
+	RateLimitRebuild = register(&IntSetting{
+		key: "rateLimitRebuild", Default: 30,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Rebuild Rate Limit",
+		Desc:  "how many rebuilds, cronjob runs and queued jobs one client can start per minute; 0 disables the limit",
+		Min:   intPtr(0), Max: intPtr(10000),
+		Trigger: "change delay:500ms",
+	})
+	RateLimitSearch = register(&IntSetting{
+		key: "rateLimitSearch", Default: 600,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Search Rate Limit",
+		Desc:  "how many searches, filter runs and file queries one client can make per minute; 0 disables the limit",
+		Min:   intPtr(0), Max: intPtr(100000),
+		Trigger: "change delay:500ms",
+	})
+	JobQueueSize = register(&IntSetting{
+		key: "jobQueueSize", Default: 10,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Job Queue Size",
+		Desc:  "how many jobs started over the API (rebuild, reindex, backup, ...) can wait for the job worker; further jobs are rejected until one started",
+		Min:   intPtr(1), Max: intPtr(100),
+		Trigger: "change delay:500ms",
+	})
+	EventStreamLimit = register(&IntSetting{
+		key: "eventStreamLimit", Default: 20,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label: "Max Live Update Connections",
+		Desc:  "how many browser tabs can listen for live file change events at the same time; further tabs get no live updates",
+		Min:   intPtr(1), Max: intPtr(1000),
+		Trigger: "change delay:500ms",
+	})
+	ResponseSizeWarnBytes = register(&IntSetting{
+		key: "responseSizeWarnBytes", Default: 5 * 1024 * 1024,
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Large Response Warning (bytes)",
+		Desc:    "log a warning when a response body is larger than this, to spot runaway widgets and filters; 0 disables the warning",
+		Min:     intPtr(0),
+		Trigger: "change delay:500ms",
+	})
+	ExcludedCollections = register(&StringSliceSetting{
+		key: "excludedCollections", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Excluded Collections",
+		Desc:    "comma-separated collections hidden from collection lists and browse pages (e.g. default); their files stay filterable",
+		Trigger: "change delay:1s",
+	})
+	PrivateCollections = register(&StringSliceSetting{
+		key: "privateCollections", Default: []string{},
+		Section: SectionGeneral, Group: GroupFiles,
+		Label:   "Private Collections",
+		Desc:    "comma-separated collections whose files are left out of zip exports",
+		Trigger: "change delay:1s",
+	})
 )
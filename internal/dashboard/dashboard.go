@@ -4,6 +4,7 @@ package dashboard
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"knov/internal/configStorage"
 	"knov/internal/logging"
@@ -142,6 +143,72 @@ func Update(dashboard *Dashboard) error {
 	return nil
 }
 
+// maxDuplicateSuffix bounds the "(copy n)" names tried when duplicating a dashboard.
+const maxDuplicateSuffix = 100
+
+// Duplicate creates a copy of the dashboard id named "<name> (copy)" - "(copy 2)" and so on
+// when that name is taken. Widgets are deep-copied and get new IDs, so editing the copy
+// never changes the original.
+func Duplicate(id string) (*Dashboard, error) {
+	original, err := Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for n := 1; n <= maxDuplicateSuffix; n++ {
+		name := original.Name + " (copy)"
+		if n > 1 {
+			name = fmt.Sprintf("%s (copy %d)", original.Name, n)
+		}
+		if existing, _ := Get(utils.CleanseID(name)); existing != nil {
+			continue
+		}
+
+		dup := &Dashboard{Name: name, Layout: original.Layout, Widgets: make([]Widget, len(original.Widgets))}
+		for i, widget := range original.Widgets {
+			dup.Widgets[i] = widget.clone()
+			dup.Widgets[i].ID = "" // regenerated by Create
+		}
+		if err := Create(dup); err != nil {
+			return nil, err
+		}
+		logging.LogDebug(logging.KeyApp, "duplicated dashboard %s as %s", id, dup.ID)
+		return dup, nil
+	}
+	return nil, fmt.Errorf("too many copies of dashboard '%s'", id)
+}
+
+// clone returns a deep copy of the widget: config pointers and slices aren't shared.
+func (w Widget) clone() Widget {
+	if w.Config.Filter != nil {
+		filterConfig := *w.Config.Filter
+		filterConfig.Criteria = slices.Clone(filterConfig.Criteria)
+		filterConfig.Columns = slices.Clone(filterConfig.Columns)
+		w.Config.Filter = &filterConfig
+	}
+	if w.Config.Static != nil {
+		static := *w.Config.Static
+		w.Config.Static = &static
+	}
+	if w.Config.FileContent != nil {
+		fileContent := *w.Config.FileContent
+		w.Config.FileContent = &fileContent
+	}
+	if w.Config.Calendar != nil {
+		calendar := *w.Config.Calendar
+		w.Config.Calendar = &calendar
+	}
+	if w.Config.Kanban != nil {
+		kanban := *w.Config.Kanban
+		w.Config.Kanban = &kanban
+	}
+	if w.Config.Query != nil {
+		query := *w.Config.Query
+		w.Config.Query = &query
+	}
+	return w
+}
+
 // isValidLayout checks if the layout is one of the allowed enum values
 func isValidLayout(layout Layout) bool {
 	switch layout {
@@ -0,0 +1,65 @@
+// Package dashboard - export and import of dashboards as versioned JSON
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/logging"
+)
+
+// ExportVersion is the version of the dashboard export format written by Export.
+const ExportVersion = 1
+
+// exportFile is the export format: the dashboard with its widgets and their configs,
+// wrapped with the format version.
+type exportFile struct {
+	Version   int       `json:"version"`
+	Dashboard Dashboard `json:"dashboard"`
+}
+
+// Export serializes the dashboard id with all its widgets to versioned JSON.
+func Export(id string) ([]byte, error) {
+	dashboard, err := Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(exportFile{Version: ExportVersion, Dashboard: *dashboard}, "", "  ")
+}
+
+// Import creates a dashboard from JSON written by Export, named name when given and under
+// a fresh ID derived from the name. Unknown widget types are rejected, not dropped.
+func Import(data []byte, name string) (*Dashboard, error) {
+	var file exportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid dashboard json: %w", err)
+	}
+	if file.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported dashboard export version %d, expected %d", file.Version, ExportVersion)
+	}
+
+	dashboard := file.Dashboard
+	var unknown []string
+	for _, widget := range dashboard.Widgets {
+		if !slices.Contains(WidgetTypes, widget.Type) && !slices.Contains(unknown, string(widget.Type)) {
+			unknown = append(unknown, string(widget.Type))
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown widget types: %s", strings.Join(unknown, ", "))
+	}
+
+	if name != "" {
+		dashboard.Name = name
+	}
+	// Create derives a fresh id from the (possibly new) name
+	dashboard.ID = ""
+	if err := Create(&dashboard); err != nil {
+		return nil, err
+	}
+
+	logging.LogInfo(logging.KeyApp, "imported dashboard %s with %d widgets", dashboard.ID, len(dashboard.Widgets))
+	return &dashboard, nil
+}
@@ -0,0 +1,109 @@
+// Package dashboard - Widget form parsing
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"knov/internal/filter"
+)
+
+// ParseWidgetsForm builds the widget list from posted dashboard form fields
+func ParseWidgetsForm(r *http.Request) ([]Widget, error) {
+	var widgets []Widget
+	form := r.PostForm
+
+	// Find the highest widget index
+	maxIndex := -1
+	for key := range form {
+		if strings.HasPrefix(key, "widgets[") && strings.Contains(key, "][type]") {
+			start := strings.Index(key, "[") + 1
+			end := strings.Index(key[start:], "]")
+			if end > 0 {
+				if idx, err := strconv.Atoi(key[start : start+end]); err == nil && idx > maxIndex {
+					maxIndex = idx
+				}
+			}
+		}
+	}
+
+	// Build widgets from form data
+	for i := 0; i <= maxIndex; i++ {
+		widgetType := WidgetType(r.FormValue(fmt.Sprintf("widgets[%d][type]", i)))
+		if widgetType == "" {
+			continue // skip empty widget types
+		}
+
+		xPos, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("widgets[%d][position][x]", i)))
+		yPos, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("widgets[%d][position][y]", i)))
+		title := r.FormValue(fmt.Sprintf("widgets[%d][title]", i))
+		refreshSeconds, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("widgets[%d][refreshSeconds]", i)))
+
+		// Build config from form fields
+		var config WidgetConfig
+		switch widgetType {
+		case WidgetTypeFilter:
+			filterConfig := filter.ParseFilterConfigFromForm(r, i)
+			config.Filter = &FilterConfig{
+				Criteria:  filterConfig.Criteria,
+				Logic:     filterConfig.Logic,
+				Display:   filterConfig.Display,
+				Limit:     filterConfig.Limit,
+				SortBy:    filterConfig.SortBy,
+				SortOrder: filterConfig.SortOrder,
+				GroupBy:   filterConfig.GroupBy,
+				Columns:   filterConfig.Columns,
+				Preset:    strings.TrimSpace(r.FormValue(fmt.Sprintf("widgets[%d][config][preset]", i))),
+			}
+		case WidgetTypeFileContent:
+			filePath := r.FormValue(fmt.Sprintf("widgets[%d][config][filePath]", i))
+			config.FileContent = &FileContentConfig{
+				FilePath: filePath,
+			}
+		case WidgetTypeStatic:
+			format := r.FormValue(fmt.Sprintf("widgets[%d][config][format]", i))
+			content := r.FormValue(fmt.Sprintf("widgets[%d][config][content]", i))
+			config.Static = &StaticConfig{
+				Format:  format,
+				Content: content,
+			}
+		case WidgetTypeCalendar:
+			config.Calendar = &CalendarConfig{
+				DateField: r.FormValue(fmt.Sprintf("widgets[%d][config][dateField]", i)),
+				Range:     r.FormValue(fmt.Sprintf("widgets[%d][config][range]", i)),
+			}
+		case WidgetTypeKanban:
+			config.Kanban = &KanbanConfig{
+				GroupField: r.FormValue(fmt.Sprintf("widgets[%d][config][groupField]", i)),
+			}
+		case WidgetTypeQuery:
+			config.Query = &QueryConfig{
+				Query: strings.TrimSpace(r.FormValue(fmt.Sprintf("widgets[%d][config][query]", i))),
+			}
+		}
+
+		// fallback: try to parse JSON config if present
+		configJSON := r.FormValue(fmt.Sprintf("widgets[%d][config]", i))
+		if configJSON != "" {
+			json.Unmarshal([]byte(configJSON), &config)
+		}
+
+		widget := Widget{
+			ID:    fmt.Sprintf("widget-%d", i),
+			Type:  widgetType,
+			Title: title,
+			Position: WidgetPosition{
+				X: xPos,
+				Y: yPos,
+			},
+			Config:         config,
+			RefreshSeconds: max(refreshSeconds, 0),
+		}
+		widgets = append(widgets, widget)
+	}
+
+	return widgets, nil
+}
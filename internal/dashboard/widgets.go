@@ -2,6 +2,10 @@
 package dashboard
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"knov/internal/filter"
 )
 
@@ -18,6 +22,8 @@ type Widget struct {
 	Title    string         `json:"title"`
 	Position WidgetPosition `json:"position"`
 	Config   WidgetConfig   `json:"config"`
+	// RefreshSeconds re-renders the widget every that many seconds, 0 renders it once
+	RefreshSeconds int `json:"refreshSeconds,omitempty"`
 }
 
 // WidgetType represents widget types
@@ -31,14 +37,29 @@ const (
 	WidgetTypeTags        WidgetType = "tags"
 	WidgetTypeCollections WidgetType = "collections"
 	WidgetTypeFolders     WidgetType = "folders"
+	WidgetTypeCalendar    WidgetType = "calendar"
+	WidgetTypeKanban      WidgetType = "kanban"
+	WidgetTypeQuery       WidgetType = "query"
 )
 
+// WidgetTypes lists every widget type.
+var WidgetTypes = []WidgetType{
+	WidgetTypeFilter, WidgetTypeFilterForm, WidgetTypeFileContent, WidgetTypeStatic,
+	WidgetTypeTags, WidgetTypeCollections, WidgetTypeFolders, WidgetTypeCalendar,
+	WidgetTypeKanban, WidgetTypeQuery,
+}
+
 // FilterConfig represents filter configuration for widgets
 type FilterConfig struct {
-	Criteria []filter.Criteria `json:"criteria"`
-	Logic    string            `json:"logic"`
-	Display  string            `json:"display"` // list, cards, dropdown, content
-	Limit    int               `json:"limit"`
+	Criteria  []filter.Criteria `json:"criteria"`
+	Logic     string            `json:"logic"`
+	Display   string            `json:"display"` // one of filter.GetDisplayModes
+	Limit     int               `json:"limit"`
+	SortBy    string            `json:"sortBy,omitempty"`    // one of files.SortKeys, empty uses the default sort
+	SortOrder string            `json:"sortOrder,omitempty"` // asc or desc, empty uses the default order
+	GroupBy   string            `json:"groupBy,omitempty"`   // list display grouping, see filter.GroupFiles
+	Columns   []string          `json:"columns,omitempty"`   // table display columns
+	Preset    string            `json:"preset,omitempty"`    // filter preset loaded at render time instead of the fields above
 }
 
 // StaticConfig represents static content configuration
@@ -52,9 +73,86 @@ type FileContentConfig struct {
 	FilePath string `json:"filePath"`
 }
 
+// Calendar ranges: the current week (monday to sunday) or the current month.
+const (
+	CalendarRangeWeek  = "week"
+	CalendarRangeMonth = "month"
+)
+
+// CalendarRanges lists the ranges a calendar widget can show.
+var CalendarRanges = []string{CalendarRangeMonth, CalendarRangeWeek}
+
+// CalendarConfig represents calendar widget configuration
+type CalendarConfig struct {
+	DateField string `json:"dateField"` // one of files.CalendarDateFields
+	Range     string `json:"range"`     // one of CalendarRanges, empty is month
+}
+
+// CalendarPeriod returns the first day of the range containing now and the day after its
+// last one, both at local midnight. Unknown ranges are a month.
+func CalendarPeriod(rangeName string, now time.Time) (from, to time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if rangeName == CalendarRangeWeek {
+		// weeks start on monday
+		from = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 7)
+	}
+	from = today.AddDate(0, 0, 1-today.Day())
+	return from, from.AddDate(0, 1, 0)
+}
+
+// KanbanGroupFields lists the fields offered for grouping a kanban widget; any custom
+// field ("custom.<key>") is accepted as well. priority is the priority custom field.
+var KanbanGroupFields = []string{"status", "priority"}
+
+// KanbanConfig represents kanban widget configuration
+type KanbanConfig struct {
+	GroupField string `json:"groupField"` // status, priority or custom.<key>
+}
+
+// GroupBy returns the filter group field of the kanban columns.
+func (c KanbanConfig) GroupBy() string {
+	if c.GroupField == "priority" {
+		return "custom.priority"
+	}
+	return c.GroupField
+}
+
+// QueryConfig represents query widget configuration
+type QueryConfig struct {
+	Query string `json:"query"` // text query, see filter.ParseQueryString
+}
+
 // WidgetConfig represents widget-specific configuration
 type WidgetConfig struct {
 	Filter      *FilterConfig      `json:"filter,omitempty"`
 	Static      *StaticConfig      `json:"static,omitempty"`
 	FileContent *FileContentConfig `json:"fileContent,omitempty"`
+	Calendar    *CalendarConfig    `json:"calendar,omitempty"`
+	Kanban      *KanbanConfig      `json:"kanban,omitempty"`
+	Query       *QueryConfig       `json:"query,omitempty"`
+}
+
+// RenderEach renders widgets concurrently on up to workers goroutines and returns the output
+// of render in widget order. Widgets not started before ctx is done are still passed to
+// render, which is expected to check ctx itself.
+func RenderEach(ctx context.Context, widgets []Widget, workers int, render func(context.Context, *Widget) string) []string {
+	rendered := make([]string, len(widgets))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(widgets)) {
+		wg.Go(func() {
+			for i := range jobs {
+				rendered[i] = render(ctx, &widgets[i])
+			}
+		})
+	}
+	for i := range widgets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return rendered
 }
@@ -0,0 +1,70 @@
+package dbmigration
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"knov/internal/logging"
+
+	_ "modernc.org/sqlite"
+)
+
+// CompactIfFragmented runs VACUUM on db when at least thresholdPercent of its pages are
+// on the freelist (PRAGMA freelist_count / page_count). Reports whether it vacuumed.
+func CompactIfFragmented(db *sql.DB, thresholdPercent int) (bool, error) {
+	name := dbName(db)
+
+	var freePages, pageCount int
+	if err := db.QueryRow(`PRAGMA freelist_count`).Scan(&freePages); err != nil {
+		return false, fmt.Errorf("failed to read freelist count: %w", err)
+	}
+	if err := db.QueryRow(`PRAGMA page_count`).Scan(&pageCount); err != nil {
+		return false, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if pageCount == 0 || freePages*100 < thresholdPercent*pageCount {
+		logging.LogDebug(logging.KeyDBMigration, "%s: %d of %d pages free, skipping vacuum", name, freePages, pageCount)
+		return false, nil
+	}
+
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		return false, fmt.Errorf("failed to vacuum: %w", err)
+	}
+	logging.LogInfo(logging.KeyDBMigration, "%s: vacuumed, %d of %d pages were free", name, freePages, pageCount)
+	return true, nil
+}
+
+// CompactDatabases runs CompactIfFragmented on every .db file below dir and returns how many
+// were vacuumed. Call it before the storages open their databases.
+func CompactDatabases(dir string, thresholdPercent int) int {
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".db" {
+			return err
+		}
+
+		db, err := sql.Open("sqlite", path+"?mode=rw")
+		if err != nil {
+			logging.LogWarning(logging.KeyDBMigration, "failed to open %s for compaction: %v", path, err)
+			return nil
+		}
+		defer db.Close()
+
+		vacuumed, err := CompactIfFragmented(db, thresholdPercent)
+		if err != nil {
+			logging.LogWarning(logging.KeyDBMigration, "failed to compact %s: %v", path, err)
+			return nil
+		}
+		if vacuumed {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		logging.LogWarning(logging.KeyDBMigration, "failed to walk %s for compaction: %v", dir, err)
+	}
+
+	logging.LogInfo(logging.KeyDBMigration, "compaction on start: vacuumed %d databases", count)
+	return count
+}
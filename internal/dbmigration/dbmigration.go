@@ -87,7 +87,8 @@ func runStep(db *sql.DB, from, to int, step func(*sql.Tx) error) error {
 		return fmt.Errorf("migration %d→%d failed: %w", from, to, err)
 	}
 
-	if _, err := tx.Exec(`UPDATE schema_version SET version = ?`, to); err != nil {
+	// version is inlined rather than bound so the statement works with sqlite and postgres placeholders
+	if _, err := tx.Exec(fmt.Sprintf(`UPDATE schema_version SET version = %d`, to)); err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to set version %d: %w", to, err)
 	}
@@ -0,0 +1,67 @@
+// Package files - the agenda as an iCalendar feed
+package files
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"knov/internal/pathutils"
+)
+
+// AgendaICS renders the agenda as an iCalendar feed of all-day events, linking each
+// event back to its file under baseURL.
+func AgendaICS(items []AgendaItem, baseURL string, now time.Time) string {
+	var ics strings.Builder
+	line := func(format string, args ...any) {
+		ics.WriteString(foldICSLine(fmt.Sprintf(format, args...)) + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//knov//agenda//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:knov agenda")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, item := range items {
+		relativePath := pathutils.ToRelative(item.Path)
+		link := baseURL + "/files/" + (&url.URL{Path: relativePath}).EscapedPath()
+		line("BEGIN:VEVENT")
+		line("UID:%x@knov", sha256.Sum256([]byte(item.Path)))
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART;VALUE=DATE:%s", item.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:%s", item.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:%s", escapeICSText(item.Title))
+		line("DESCRIPTION:%s", escapeICSText(relativePath))
+		line("URL:%s", link)
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return ics.String()
+}
+
+// escapeICSText escapes an iCalendar TEXT value (RFC 5545 3.3.11).
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine folds a content line longer than 75 octets into continuation lines starting
+// with a space, without splitting a utf-8 character (RFC 5545 3.1).
+func foldICSLine(s string) string {
+	var folded strings.Builder
+	width := 0
+	for _, r := range s {
+		size := utf8.RuneLen(r)
+		if width+size > 75 {
+			folded.WriteString("\r\n ")
+			width = 1
+		}
+		folded.WriteRune(r)
+		width += size
+	}
+	return folded.String()
+}
@@ -0,0 +1,142 @@
+// Package files - zip archives of the data directory for exports and backups
+package files
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+)
+
+// backupFilePrefix names the archives of WriteBackup; the timestamp after it sorts
+// chronologically, which the rotation relies on.
+const backupFilePrefix = "knov-backup_"
+
+// WriteDataArchive writes the files of the data directory as a zip archive to w. The .git
+// directory and a backup directory inside the data directory are left out, and so are
+// files of private collections unless includePrivate is set. Unreadable files are skipped.
+func WriteDataArchive(w io.Writer, includePrivate bool) error {
+	dataPath := configmanager.GetAppConfig().DataPath
+	backupPath, _ := filepath.Abs(configmanager.GetAppConfig().BackupPath)
+
+	zipWriter := zip.NewWriter(w)
+	err := filepath.Walk(dataPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if abs, _ := filepath.Abs(path); abs == backupPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dataPath, path)
+		if err != nil {
+			return err
+		}
+
+		if !includePrivate && IsPrivate(relPath) {
+			logging.LogDebug(logging.KeyApp, "skip (private collection): %s", relPath)
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to read file %s: %v", path, err)
+			return nil // skip this file but continue
+		}
+
+		zipFile, err := zipWriter.Create(filepath.ToSlash(relPath))
+		if err != nil {
+			return err
+		}
+		_, err = zipFile.Write(content)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zipWriter.Close()
+}
+
+// WriteBackup writes a zip archive of the data directory, private collections included, into
+// dir and deletes all but the keep most recent archives there. Returns the path of the new
+// archive and the number of deleted ones.
+func WriteBackup(dir string, keep int) (string, int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, backupFilePrefix+time.Now().Format("2006-01-02_15-04-05.000000")+".zip")
+	if err := writeBackupArchive(path); err != nil {
+		return "", 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+	logging.LogInfo(logging.KeyBackup, "created backup %s", path)
+
+	pruned, err := pruneBackups(dir, max(keep, 1))
+	if err != nil {
+		return path, pruned, fmt.Errorf("failed to prune backups: %w", err)
+	}
+	return path, pruned, nil
+}
+
+// writeBackupArchive writes the archive to a temp file renamed into place, so a failed run
+// never leaves a partial backup that would count towards the kept ones.
+func writeBackupArchive(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".backup-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteDataArchive(tmp, true); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// pruneBackups deletes the backup archives in dir beyond the keep most recent ones and
+// returns how many were deleted.
+func pruneBackups(dir string, keep int) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupFilePrefix) && strings.HasSuffix(entry.Name(), ".zip") {
+			backups = append(backups, entry.Name())
+		}
+	}
+	if len(backups) <= keep {
+		return 0, nil
+	}
+
+	slices.Sort(backups)
+	pruned := 0
+	for _, name := range backups[:len(backups)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			logging.LogError(logging.KeyBackup, "failed to delete old backup %s: %v", name, err)
+			continue
+		}
+		logging.LogDebug(logging.KeyBackup, "deleted old backup %s", name)
+		pruned++
+	}
+	return pruned, nil
+}
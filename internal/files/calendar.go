@@ -0,0 +1,93 @@
+// Package files - grouping files by the day of a metadata date
+package files
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// CalendarDateFields are the metadata dates FilesByDay can group on.
+var CalendarDateFields = []string{"createdAt", "lastEdited", TargetDateField}
+
+// calendarDayFormat is the key format of FilesByDay.
+const calendarDayFormat = "2006-01-02"
+
+// FilesByDay groups the files whose dateField falls within [from, to) by day (YYYY-MM-DD
+// in from's location). Files without that date - a zero createdAt/lastEdited, an empty or
+// invalid targetDate - are left out rather than counted on the zero date.
+func FilesByDay(dateField string, from, to time.Time) (map[string][]File, error) {
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string][]File)
+	for _, file := range allFiles {
+		date, ok := calendarDate(file.Metadata, dateField, from.Location())
+		if !ok || date.Before(from) || !date.Before(to) {
+			continue
+		}
+		day := date.Format(calendarDayFormat)
+		days[day] = append(days[day], file)
+	}
+	return days, nil
+}
+
+// calendarDate returns the dateField of metadata in loc, and false when it isn't set.
+func calendarDate(metadata *Metadata, dateField string, loc *time.Location) (time.Time, bool) {
+	if metadata == nil {
+		return time.Time{}, false
+	}
+
+	var date time.Time
+	switch dateField {
+	case "createdAt":
+		date = metadata.CreatedAt
+	case "lastEdited":
+		date = metadata.LastEdited
+	case TargetDateField:
+		// a plain date is a day in the calendar's location, not a utc instant
+		parsed, err := time.ParseInLocation(calendarDayFormat, metadata.Custom[TargetDateField], loc)
+		if err != nil {
+			return time.Time{}, false
+		}
+		date = parsed
+	}
+	if date.IsZero() {
+		return time.Time{}, false
+	}
+	return date.In(loc), true
+}
+
+// AgendaItem is a file with a targetDate, see TargetDateAgenda.
+type AgendaItem struct {
+	Path  string
+	Title string
+	Date  time.Time // the target day, midnight utc
+}
+
+// TargetDateAgenda returns the files with a valid targetDate, ordered by date then path.
+// Files of private collections are left out unless includePrivate is set.
+func TargetDateAgenda(includePrivate bool) ([]AgendaItem, error) {
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []AgendaItem
+	for _, file := range allFiles {
+		if !includePrivate && IsPrivate(file.Path) {
+			continue
+		}
+		date, ok := calendarDate(file.Metadata, TargetDateField, time.UTC)
+		if !ok {
+			continue
+		}
+		items = append(items, AgendaItem{Path: file.Path, Title: fileTitle(file), Date: date})
+	}
+	slices.SortFunc(items, func(a, b AgendaItem) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.Path, b.Path))
+	})
+	return items, nil
+}
@@ -0,0 +1,38 @@
+// Package files - deep links opening files in an external desktop editor
+package files
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/pathutils"
+)
+
+// EditorLink returns the deep link of the editorLinkTemplate setting for a docs or media
+// file, with {path} replaced by its absolute filesystem path. The path is query-escaped
+// when {path} is in the query of the template and escaped per segment otherwise, so
+// vscode://file{path} keeps its slashes while obsidian://open?path={path} gets one value.
+func EditorLink(filePath string) (string, error) {
+	absPath, err := filepath.Abs(pathutils.ToFullPath(filePath))
+	if err != nil {
+		return "", err
+	}
+	absPath = filepath.ToSlash(absPath)
+	if !strings.HasPrefix(absPath, "/") {
+		absPath = "/" + absPath // windows drive letter, as in file:///C:/...
+	}
+
+	template := configmanager.GetEditorLinkTemplate()
+	placeholder := strings.Index(template, "{path}")
+	if query := strings.Index(template, "?"); query >= 0 && query < placeholder {
+		return strings.ReplaceAll(template, "{path}", url.QueryEscape(absPath)), nil
+	}
+
+	segments := strings.Split(absPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.ReplaceAll(template, "{path}", strings.Join(segments, "/")), nil
+}
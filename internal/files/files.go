@@ -1,9 +1,11 @@
 package files
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -37,16 +39,26 @@ func FolderFromPath(path string) string {
 	return folderPath
 }
 
+// IsPrivate reports whether a docs file belongs to a collection marked private.
+// Media files have no collection and are never private.
+func IsPrivate(path string) bool {
+	return pathutils.IsDocs(path) && configmanager.IsCollectionPrivate(CollectionFromPath(path))
+}
+
 // File represents a file in the system
 type File struct {
 	Name     string    `json:"name"`
 	Path     string    `json:"path"`
 	Metadata *Metadata `json:"metadata,omitempty"`
+	// Fuzzy marks search results found by the typo-tolerant fallback, not an exact match
+	Fuzzy bool `json:"fuzzy,omitempty"`
 }
 
 type FileContent struct {
 	HTML string
 	TOC  []parser.TOCItem
+	// Truncated is set by GetFileContentLimited when only the start of the file was rendered
+	Truncated bool
 }
 
 // pathsToFiles converts file paths to File structs
@@ -94,6 +106,79 @@ func GetAllFiles() ([]File, error) {
 	return GetAllPhysicalFiles()
 }
 
+// FilePage is one page of the visible file list, see GetAllFilesPage.
+type FilePage struct {
+	Files    []File `json:"files"`
+	Total    int    `json:"total"` // visible files over all pages
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"`
+}
+
+// TotalPages returns the number of pages, at least 1.
+func (p *FilePage) TotalPages() int {
+	if p.PageSize <= 0 || p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
+// GetAllFilesPage returns page (1-based) of the visible docs files in the default file list
+// sort, pageSize files each. With the path sort and no editor type hidden the order and
+// visibility only depend on the paths, so only the metadata of the returned files is read;
+// otherwise the page is cut from the cached full list. A page past the end is empty.
+func GetAllFilesPage(page, pageSize int) (*FilePage, error) {
+	page, pageSize = max(page, 1), max(pageSize, 1)
+	result := &FilePage{Page: page, PageSize: pageSize}
+	start := (page - 1) * pageSize
+
+	if configmanager.GetFileListSort() != SortByPath || anyEditorTypeHidden() {
+		allFiles, err := GetAllFilesCached()
+		if err != nil {
+			return nil, err
+		}
+		allFiles = FilterByVisibility(allFiles)
+		SortFilesDefault(allFiles)
+		result.Total = len(allFiles)
+		if start < len(allFiles) {
+			result.Files = allFiles[start:min(start+pageSize, len(allFiles))]
+		}
+		return result, nil
+	}
+
+	paths, err := contentStorage.ListFiles()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to list files: %v", err)
+		return nil, err
+	}
+	visible := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !isHiddenByType(File{Path: path}) {
+			visible = append(visible, path)
+		}
+	}
+	desc := configmanager.GetFileListOrder() == "desc"
+	slices.SortStableFunc(visible, func(a, b string) int {
+		c := strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		if desc {
+			return -c
+		}
+		return c
+	})
+
+	result.Total = len(visible)
+	if start < len(visible) {
+		result.Files = pathsToFiles(visible[start:min(start+pageSize, len(visible))], "")
+	}
+	return result, nil
+}
+
+// anyEditorTypeHidden reports whether a hide setting excludes docs by their metadata editor.
+func anyEditorTypeHidden() bool {
+	return slices.ContainsFunc([]EditorType{EditorTypeToastUI, EditorTypeTextarea, EditorTypeFilter, EditorTypeList, EditorTypeTodo, EditorTypeIndex}, func(editor EditorType) bool {
+		return configmanager.IsFileTypeHidden(string(editor))
+	})
+}
+
 // GetAllMediaFiles returns list of all media files using contentStorage
 func GetAllMediaFiles() ([]File, error) {
 	paths, err := contentStorage.ListMediaFiles()
@@ -120,6 +205,42 @@ func GetFileContent(filePath string) (*FileContent, error) {
 		return nil, err
 	}
 
+	return renderFileContent(handler, filePath, content)
+}
+
+// GetFileContentLimited is GetFileContent for content embedded in another page: files over
+// maxBytes (0 = no limit) are cut at the last line break within maxBytes before rendering
+// and flagged Truncated. When the cut-off source doesn't parse (e.g. json of specialized
+// editors) the content is left empty.
+func GetFileContentLimited(filePath string, maxBytes int) (*FileContent, error) {
+	handler := parser.GetParserRegistry().GetHandler(filePath)
+	if handler == nil {
+		return nil, fmt.Errorf("no handler found for file: %s", filePath)
+	}
+
+	content, err := contentStorage.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return renderFileContent(handler, filePath, content)
+	}
+
+	cut := content[:maxBytes]
+	if i := bytes.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	fileContent, err := renderFileContent(handler, filePath, cut)
+	if err != nil {
+		logging.LogDebug(logging.KeyApp, "truncated content of %s doesn't render: %v", filePath, err)
+		fileContent = &FileContent{}
+	}
+	fileContent.Truncated = true
+	return fileContent, nil
+}
+
+// renderFileContent parses and renders the content of filePath with handler.
+func renderFileContent(handler parser.Parser, filePath string, content []byte) (*FileContent, error) {
 	parsed, err := handler.Parse(content)
 	if err != nil {
 		return nil, err
@@ -237,3 +358,38 @@ func sortTreeNode(node *TreeNode) {
 		sortTreeNode(child)
 	}
 }
+
+// VaultStats counts the files and metadata values of the vault.
+type VaultStats struct {
+	Files       int `json:"files"`
+	Media       int `json:"media"`
+	Tags        int `json:"tags"`
+	Collections int `json:"collections"`
+	Folders     int `json:"folders"`
+}
+
+// CollectVaultStats counts the vault from the caches, falling back to live data when empty.
+func CollectVaultStats() VaultStats {
+	var stats VaultStats
+	if allFiles, err := GetAllFilesCached(); err == nil {
+		stats.Files = len(allFiles)
+	}
+	if media, err := GetAllMediaFiles(); err == nil {
+		stats.Media = len(media)
+	}
+
+	tags, err := GetAllTagsCountFromCache()
+	if err != nil || len(tags) == 0 {
+		tags, _ = GetAllTags(false)
+	}
+	collections, err := GetAllCollectionsCountFromCache()
+	if err != nil || len(collections) == 0 {
+		collections, _ = GetAllCollections(false)
+	}
+	folders, err := GetAllFoldersCountFromCache()
+	if err != nil || len(folders) == 0 {
+		folders, _ = GetAllFolders(false)
+	}
+	stats.Tags, stats.Collections, stats.Folders = len(tags), len(collections), len(folders)
+	return stats
+}
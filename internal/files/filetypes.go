@@ -0,0 +1,93 @@
+// Package files - filetypes: the editor types plus the custom filetypes of the settings
+package files
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+)
+
+// FiletypeField is the custom field naming the filetype of a file, also set by the type
+// front matter key.
+const FiletypeField = "type"
+
+// Filetype is a kind of file and the editor its files open in. Every editor type is a
+// built-in filetype; the customFiletypes setting registers more.
+type Filetype struct {
+	Name   string     `json:"name"`
+	Label  string     `json:"label"`
+	Editor EditorType `json:"editor"`
+	Custom bool       `json:"custom"`
+}
+
+var (
+	// ErrInvalidFiletype is returned by RegisterFiletype for a name that isn't lowercase
+	// letters, digits and dashes, an empty label or an unknown editor type.
+	ErrInvalidFiletype = errors.New("invalid filetype")
+	// ErrBuiltinFiletype is returned by RegisterFiletype for the name of an editor type.
+	ErrBuiltinFiletype = errors.New("filetype is built in")
+)
+
+// AllFiletypes returns the built-in filetypes, one per editor type, followed by the
+// custom filetypes. Custom filetypes without a valid editor type open in the textarea editor.
+func AllFiletypes() []Filetype {
+	var filetypes []Filetype
+	for _, editor := range AllEditorTypes() {
+		filetypes = append(filetypes, Filetype{Name: string(editor), Label: string(editor), Editor: editor})
+	}
+	for _, custom := range configmanager.GetCustomFiletypes() {
+		editor := EditorType(custom.Editor)
+		if !slices.Contains(AllEditorTypes(), editor) {
+			editor = EditorTypeTextarea
+		}
+		filetypes = append(filetypes, Filetype{Name: custom.Name, Label: custom.Label, Editor: editor, Custom: true})
+	}
+	return filetypes
+}
+
+// IsValidFiletype reports whether name is an editor type or a registered custom filetype.
+func IsValidFiletype(name string) bool {
+	return FiletypeEditor(name) != ""
+}
+
+// FiletypeEditor returns the editor files of filetype name open in, "" for an unknown
+// filetype.
+func FiletypeEditor(name string) EditorType {
+	for _, filetype := range AllFiletypes() {
+		if filetype.Name == name {
+			return filetype.Editor
+		}
+	}
+	return ""
+}
+
+// RegisterFiletype adds the custom filetype name to the customFiletypes setting, or
+// replaces its label and editor when it is registered already. An empty editor opens its
+// files in the textarea editor. The caller saves the settings.
+func RegisterFiletype(name, label string, editor EditorType) error {
+	name, label = strings.TrimSpace(name), strings.TrimSpace(label)
+	if slices.Contains(AllEditorTypes(), EditorType(name)) {
+		return ErrBuiltinFiletype
+	}
+	if editor != "" && !slices.Contains(AllEditorTypes(), editor) {
+		return fmt.Errorf("%w: unknown editor type %s", ErrInvalidFiletype, editor)
+	}
+	if strings.ContainsAny(label, ",:") {
+		return fmt.Errorf("%w: the label must not contain , or :", ErrInvalidFiletype)
+	}
+
+	entries := []string{configmanager.CustomFiletype{Name: name, Label: label, Editor: string(editor)}.String()}
+	for _, custom := range configmanager.GetCustomFiletypes() {
+		if custom.Name != name {
+			entries = append(entries, custom.String())
+		}
+	}
+	slices.Sort(entries)
+	if err := configmanager.CustomFiletypes.SetFromString(strings.Join(entries, ",")); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFiletype, err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package files - YAML front matter of docs files, synced onto their metadata and imported
+package files
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/metadataStorage"
+	"knov/internal/parser"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the metadata read from a page's YAML front matter or Logseq page
+// properties. tags/tag become tags and aliases/alias aliases, status is the kanban status
+// and collection the collection. Every other key but knov's own metadata fields
+// (frontMatterSkippedKeys) is kept as a custom field of the same name.
+type FrontMatter struct {
+	Tags       []string
+	Aliases    []string
+	Status     string
+	Collection string
+	Custom     map[string]string
+}
+
+// frontMatterSkippedKeys are keys that collide with knov's own metadata fields (e.g. front
+// matter written by the yaml metadata backend) and are never read.
+var frontMatterSkippedKeys = []string{
+	"path", "title", "createdAt", "lastEdited", "folders", "ancestor",
+	"parents", "kids", "usedLinks", "linksToHere", "related", "editor", "size",
+	"wordCount", "readingTimeMinutes", "references", "conflictFile", "conflictOf", "kanbanAddedAt", "kanbanMovedAt", "custom",
+}
+
+// frontMatterCustomFields are the custom fields the front matter sync keeps in step with
+// the file; the import takes every custom field.
+var frontMatterCustomFields = []string{PriorityField, FiletypeField, TargetDateField}
+
+// parseFrontMatter decodes the leading --- block of content. Returns nil properties when
+// content has no front matter and an error when its yaml is malformed.
+func parseFrontMatter(content []byte) (map[string]any, error) {
+	raw, _ := parser.StripFrontMatterBytes(content)
+	if raw == nil {
+		return nil, nil
+	}
+	properties := make(map[string]any)
+	if err := yaml.Unmarshal(raw, &properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// mapFrontMatter maps front matter or page properties onto FrontMatter. Returns nil when
+// there are none.
+func mapFrontMatter(properties map[string]any) *FrontMatter {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	fm := &FrontMatter{Custom: make(map[string]string)}
+	for key, value := range properties {
+		values := frontMatterValues(value)
+		switch strings.ToLower(key) {
+		case "tags", "tag":
+			for _, tag := range values {
+				if !slices.Contains(fm.Tags, tag) {
+					fm.Tags = append(fm.Tags, tag)
+				}
+			}
+		case "aliases", "alias":
+			fm.Aliases = append(fm.Aliases, values...)
+		case "status":
+			fm.Status = strings.Join(values, ", ")
+		case "collection":
+			fm.Collection = strings.Join(values, ", ")
+		default:
+			if slices.Contains(frontMatterSkippedKeys, key) {
+				continue
+			}
+			fm.Custom[key] = strings.Join(values, ", ")
+		}
+	}
+	return fm
+}
+
+// frontMatterValues flattens a property value into clean strings: yaml lists stay lists,
+// comma separated strings are split, and Obsidian/Logseq "#tag" and "[[page]]" markup is removed.
+func frontMatterValues(value any) []string {
+	var raw []string
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			raw = append(raw, frontMatterValues(item)...)
+		}
+		return raw
+	case string:
+		raw = strings.Split(v, ",")
+	case time.Time:
+		raw = []string{v.Format("2006-01-02")}
+	case nil:
+		return nil
+	default:
+		raw = []string{fmt.Sprint(v)}
+	}
+
+	var values []string
+	for _, r := range raw {
+		r = strings.TrimSpace(r)
+		r = strings.TrimPrefix(r, "#")
+		r = strings.TrimSuffix(strings.TrimPrefix(r, "[["), "]]")
+		if r != "" {
+			values = append(values, r)
+		}
+	}
+	return values
+}
+
+// readFrontMatter reads the front matter of a docs file. Returns nil for media, files
+// without front matter and malformed yaml (logged), and with the yaml metadata backend,
+// whose front matter already is the stored metadata.
+func readFrontMatter(metadataPath, fullPath string) *FrontMatter {
+	if !strings.HasPrefix(metadataPath, "docs/") || metadataStorage.GetBackendType() == "yaml" {
+		return nil
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil
+	}
+	properties, err := parseFrontMatter(content)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "ignoring malformed front matter in %s: %v", metadataPath, err)
+		return nil
+	}
+	return mapFrontMatter(properties)
+}
+
+// withStatusTag returns tags with its kanban status tag replaced by the one of status.
+// Unknown statuses are logged and leave tags unchanged.
+func (fm *FrontMatter) withStatusTag(path string, tags []string) []string {
+	if !slices.Contains(configmanager.GetKanbanStatuses(), fm.Status) {
+		logging.LogWarning(logging.KeyApp, "ignoring unknown front matter status %q in %s", fm.Status, path)
+		return tags
+	}
+	tags = slices.DeleteFunc(slices.Clone(tags), func(tag string) bool { return KanbanStatusFromTags([]string{tag}) != "" })
+	return append(tags, configmanager.KanbanStatusTag(fm.Status))
+}
+
+// reconcileTags returns the tags to save given the stored tags and the tags passed to the
+// save (empty when not passed). With the front matter authoritative its tags and status
+// replace the others; otherwise they only fill in for a file that has none.
+func (fm *FrontMatter) reconcileTags(path string, stored, passed []string) []string {
+	current := passed
+	if len(current) == 0 {
+		current = stored
+	}
+
+	wins := configmanager.GetFrontMatterWins()
+	tags := slices.Clone(current)
+	if len(fm.Tags) > 0 && (wins || len(current) == 0) {
+		// keep the kanban status tag, it is reconciled with the status key below
+		tags = slices.DeleteFunc(tags, func(tag string) bool { return KanbanStatusFromTags([]string{tag}) == "" })
+		for _, tag := range fm.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	if fm.Status != "" && (wins || KanbanStatusFromTags(current) == "") {
+		tags = fm.withStatusTag(path, tags)
+	}
+
+	if slices.Equal(tags, current) {
+		return passed
+	}
+	return tags
+}
+
+// reconcileFields applies the collection and custom field keys to metadata. With the
+// front matter authoritative they overwrite the stored values, otherwise they only fill
+// in empty ones - which for the collection, derived from the folder, means files at the
+// root of docs.
+func (fm *FrontMatter) reconcileFields(metadata *Metadata) {
+	wins := configmanager.GetFrontMatterWins()
+	if fm.Collection != "" && (wins || metadata.Collection == "") {
+		metadata.Collection = fm.Collection
+	}
+
+	// the custom map may be the one passed to the save, so changes go to a copy
+	custom := maps.Clone(metadata.Custom)
+	for _, field := range frontMatterCustomFields {
+		value := fm.Custom[field]
+		if value == "" || (!wins && custom[field] != "") {
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]string)
+		}
+		custom[field] = value
+	}
+	metadata.Custom = custom
+}
@@ -0,0 +1,183 @@
+// Package files - link graph of all files for graph visualizations
+package files
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/pathutils"
+)
+
+// node types of a LinkGraph
+const (
+	GraphNodeDoc     = "doc"
+	GraphNodeMedia   = "media"
+	GraphNodeMissing = "missing" // link or parent target without a file
+)
+
+// edge relations of a LinkGraph
+const (
+	GraphRelationLink   = "link"   // source links to target
+	GraphRelationParent = "parent" // target is a parent of source
+)
+
+// GraphNode is a file of the link graph. ID is its metadata path (docs/ or media/ prefixed).
+type GraphNode struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+// GraphEdge is a directed edge between two nodes of the link graph.
+type GraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+// LinkGraph is the link network of the files, in the node/edge shape force-directed graph
+// libraries expect. Every edge endpoint is a node.
+type LinkGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// BuildLinkGraph builds the link graph of all visible files from their used links and
+// parents. LinksToHere and Kids are the reverse of those, so they add no edges.
+// Nodes are sorted by id, edges by source, target and relation.
+func BuildLinkGraph() (*LinkGraph, error) {
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+	allFiles = FilterByVisibility(allFiles)
+
+	nodes := make(map[string]GraphNode)
+	var sources []*Metadata
+	for _, file := range allFiles {
+		id := pathutils.ToWithPrefix(file.Path)
+		node := GraphNode{ID: id, Title: filepath.Base(id), Type: GraphNodeDoc}
+		if pathutils.IsMedia(id) {
+			node.Type = GraphNodeMedia
+		}
+		if file.Metadata != nil {
+			if file.Metadata.Title != "" {
+				node.Title = file.Metadata.Title
+			}
+			sources = append(sources, file.Metadata)
+		}
+		nodes[id] = node
+	}
+
+	graph := &LinkGraph{Edges: []GraphEdge{}}
+	seen := make(map[GraphEdge]bool)
+	addEdge := func(source, target, relation string) {
+		target = pathutils.ToWithPrefix(target)
+		edge := GraphEdge{Source: source, Target: target, Relation: relation}
+		if target == "" || target == source || seen[edge] {
+			return
+		}
+		seen[edge] = true
+		graph.Edges = append(graph.Edges, edge)
+		if _, ok := nodes[target]; !ok {
+			nodes[target] = GraphNode{ID: target, Title: filepath.Base(target), Type: GraphNodeMissing}
+		}
+	}
+	for _, metadata := range sources {
+		source := pathutils.ToWithPrefix(metadata.Path)
+		for _, link := range metadata.UsedLinks {
+			addEdge(source, link, GraphRelationLink)
+		}
+		for _, parent := range metadata.Parents {
+			addEdge(source, parent, GraphRelationParent)
+		}
+	}
+
+	graph.Nodes = slices.SortedFunc(maps.Values(nodes), func(a, b GraphNode) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(graph.Edges, func(a, b GraphEdge) int {
+		return cmp.Or(strings.Compare(a.Source, b.Source), strings.Compare(a.Target, b.Target), strings.Compare(a.Relation, b.Relation))
+	})
+	return graph, nil
+}
+
+// Subgraph returns the part of the graph within depth hops of root, following edges in
+// both directions, with the edges between the nodes it keeps. Each node is visited once,
+// so link and parent cycles end the walk instead of looping.
+func (g *LinkGraph) Subgraph(root string, depth int) (*LinkGraph, error) {
+	root = pathutils.ToWithPrefix(root)
+	if !slices.ContainsFunc(g.Nodes, func(n GraphNode) bool { return n.ID == root }) {
+		return nil, fmt.Errorf("file %s is not in the link graph", root)
+	}
+
+	neighbors := make(map[string][]string)
+	for _, edge := range g.Edges {
+		neighbors[edge.Source] = append(neighbors[edge.Source], edge.Target)
+		neighbors[edge.Target] = append(neighbors[edge.Target], edge.Source)
+	}
+
+	reached := map[string]bool{root: true}
+	frontier := []string{root}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range neighbors[id] {
+				if !reached[neighbor] {
+					reached[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sub := &LinkGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, node := range g.Nodes {
+		if reached[node.ID] {
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+	for _, edge := range g.Edges {
+		if reached[edge.Source] && reached[edge.Target] {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+	return sub, nil
+}
+
+// DOT returns the graph in the GraphViz DOT language. Nodes are labelled with their titles,
+// media nodes are boxes and missing targets dashed; parent edges are dashed too.
+func (g *LinkGraph) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph links {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&dot, "  %s [label=%s", quoteDOT(node.ID), quoteDOT(node.Title))
+		switch node.Type {
+		case GraphNodeMedia:
+			dot.WriteString(", shape=box")
+		case GraphNodeMissing:
+			dot.WriteString(", style=dashed")
+		}
+		dot.WriteString("];\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&dot, "  %s -> %s", quoteDOT(edge.Source), quoteDOT(edge.Target))
+		if edge.Relation == GraphRelationParent {
+			dot.WriteString(" [style=dashed]")
+		}
+		dot.WriteString(";\n")
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// dotEscaper escapes the characters a DOT quoted string can't hold literally
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// quoteDOT quotes s as a DOT string id
+func quoteDOT(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,93 @@
+// Package files - report of parent chains the ancestor computation had to stop early
+package files
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"knov/internal/cacheStorage"
+	"knov/internal/logging"
+)
+
+// CacheKeyHierarchyProblems holds the hierarchy problems by file path.
+const CacheKeyHierarchyProblems CacheKey = "hierarchy_problems"
+
+// kinds of hierarchy problems
+const (
+	HierarchyCycle   = "cycle"    // the parent chain leads back to a file already in it
+	HierarchyTooDeep = "too-deep" // the parent chain is longer than maxHierarchyDepth
+)
+
+// HierarchyProblem is a file whose ancestor could not be computed because a parent chain
+// loops or is too deep. Chain is the followed chain, starting at the parent of Path.
+type HierarchyProblem struct {
+	Path       string    `json:"path"`
+	Kind       string    `json:"kind"`
+	Chain      []string  `json:"chain"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+var hierarchyProblemsMu sync.Mutex
+
+// GetHierarchyProblems returns the recorded hierarchy problems of existing files, by path.
+func GetHierarchyProblems() []HierarchyProblem {
+	hierarchyProblemsMu.Lock()
+	defer hierarchyProblemsMu.Unlock()
+
+	problems := loadHierarchyProblems()
+	result := make([]HierarchyProblem, 0, len(problems))
+	for _, path := range slices.Sorted(maps.Keys(problems)) {
+		if metadataStored(path) {
+			result = append(result, problems[path])
+		}
+	}
+	return result
+}
+
+// setHierarchyProblem records the problem of a file, or clears its entry when problem is nil.
+// The report is only written when it changes.
+func setHierarchyProblem(path string, problem *HierarchyProblem) {
+	hierarchyProblemsMu.Lock()
+	defer hierarchyProblemsMu.Unlock()
+
+	problems := loadHierarchyProblems()
+	previous, known := problems[path]
+	switch {
+	case problem == nil && !known:
+		return
+	case problem == nil:
+		delete(problems, path)
+	case known && previous.Kind == problem.Kind && slices.Equal(previous.Chain, problem.Chain):
+		return
+	default:
+		problem.Path, problem.DetectedAt = path, time.Now()
+		problems[path] = *problem
+		logging.LogWarning(logging.KeyApp, "parent chain of %s stopped (%s): %v", path, problem.Kind, problem.Chain)
+	}
+
+	data, err := json.Marshal(problems)
+	if err == nil {
+		err = cacheStorage.Set(string(CacheKeyHierarchyProblems), data)
+	}
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to save hierarchy problems: %v", err)
+	}
+}
+
+// loadHierarchyProblems reads the report, empty when it was never written or the cache was
+// flushed. Called with hierarchyProblemsMu held.
+func loadHierarchyProblems() map[string]HierarchyProblem {
+	problems := map[string]HierarchyProblem{}
+	data, err := cacheStorage.Get(string(CacheKeyHierarchyProblems))
+	if err != nil || data == nil {
+		return problems
+	}
+	if err := json.Unmarshal(data, &problems); err != nil {
+		logging.LogWarning(logging.KeyApp, "invalid cached hierarchy problems: %v", err)
+		return map[string]HierarchyProblem{}
+	}
+	return problems
+}
@@ -0,0 +1,128 @@
+// Package files - metadata import from Obsidian front matter and Logseq page properties
+package files
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// logseqPropertyRe matches a Logseq page property line ("key:: value").
+var logseqPropertyRe = regexp.MustCompile(`^([A-Za-z0-9_-]+)::\s*(.*)$`)
+
+// ParseExternalMetadata reads Obsidian-style YAML front matter or Logseq page
+// properties ("key:: value" lines at the top of the page) from content and maps them like
+// the front matter sync, see FrontMatter. Returns nil when the page has neither.
+func ParseExternalMetadata(content []byte) *FrontMatter {
+	properties, err := parseFrontMatter(content)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to parse front matter: %v", err)
+		return nil
+	}
+	if properties == nil {
+		properties = make(map[string]any)
+		for line := range strings.Lines(string(content)) {
+			m := logseqPropertyRe.FindStringSubmatch(strings.TrimSpace(line))
+			if m == nil {
+				break
+			}
+			properties[m[1]] = m[2]
+		}
+	}
+	return mapFrontMatter(properties)
+}
+
+// ImportExternalMetadata merges the Obsidian/Logseq metadata of a docs file into its
+// knov metadata: tags are added to the existing ones, the status replaces the kanban
+// status tag, aliases are stored as the "aliases" custom field and all other properties
+// as custom fields. The collection stays the one derived from the folder.
+// Reports whether the file carried any importable metadata.
+func ImportExternalMetadata(relPath string) (bool, error) {
+	imported, err := importExternalMetadata(relPath)
+	if err != nil || !imported {
+		return imported, err
+	}
+	RefreshCaches()
+	return true, nil
+}
+
+// ImportAllExternalMetadata runs ImportExternalMetadata over every markdown file
+// and returns how many files had metadata imported.
+func ImportAllExternalMetadata() (int, error) {
+	allFiles, err := GetAllFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, file := range allFiles {
+		relPath := pathutils.ToRelative(file.Path)
+		if ext := filepath.Ext(relPath); ext != ".md" && ext != ".markdown" {
+			continue
+		}
+		imported, err := importExternalMetadata(relPath)
+		if err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to import metadata for %s: %v", relPath, err)
+			continue
+		}
+		if imported {
+			count++
+		}
+	}
+	if count > 0 {
+		RefreshCaches()
+	}
+
+	logging.LogInfo(logging.KeyApp, "imported external metadata for %d files", count)
+	return count, nil
+}
+
+// importExternalMetadata is ImportExternalMetadata without the aggregate cache refresh.
+func importExternalMetadata(relPath string) (bool, error) {
+	content, err := contentStorage.ReadFile(pathutils.ToDocsPath(relPath))
+	if err != nil {
+		return false, err
+	}
+	external := ParseExternalMetadata(content)
+	if external == nil {
+		return false, nil
+	}
+
+	metadataPath := pathutils.ToWithPrefix(relPath)
+	current, err := MetaDataGet(metadataPath)
+	if err != nil {
+		return false, err
+	}
+
+	update := &Metadata{Path: metadataPath, Custom: make(map[string]string)}
+	if current != nil {
+		update.Tags = slices.Clone(current.Tags)
+		for k, v := range current.Custom {
+			update.Custom[k] = v
+		}
+	}
+	for _, tag := range external.Tags {
+		if !slices.Contains(update.Tags, tag) {
+			update.Tags = append(update.Tags, tag)
+		}
+	}
+	if external.Status != "" {
+		update.Tags = external.withStatusTag(metadataPath, update.Tags)
+	}
+	if len(external.Aliases) > 0 {
+		update.Custom["aliases"] = strings.Join(external.Aliases, ", ")
+	}
+	for k, v := range external.Custom {
+		update.Custom[k] = v
+	}
+
+	if err := MetaDataSaveNoRefresh(update); err != nil {
+		return false, err
+	}
+	return true, nil
+}
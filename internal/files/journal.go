@@ -0,0 +1,66 @@
+// Package files - daily journal entries
+package files
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// journalDateLayout is the date format used for journal entry filenames and the {{date}} placeholder.
+const journalDateLayout = "2006-01-02"
+
+// JournalEntryPath returns the docs-relative path of the journal entry for date,
+// e.g. "journal/2026-01-31.md" with the default journal folder.
+func JournalEntryPath(date time.Time) string {
+	return path.Join(configmanager.GetJournalFolder(), date.Format(journalDateLayout)+".md")
+}
+
+// EnsureJournalEntry returns the docs-relative path of the journal entry for date,
+// creating it from the configured journal template when it does not exist yet.
+// created reports whether the entry was created by this call.
+func EnsureJournalEntry(date time.Time) (relPath string, created bool, err error) {
+	relPath = JournalEntryPath(date)
+	// the journalFolder setting may still point outside the docs folder
+	fullPath, err := pathutils.SafeVaultPath(pathutils.ToWithPrefix(relPath))
+	if err != nil {
+		return "", false, err
+	}
+	exists, err := contentStorage.FileExists(fullPath)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return relPath, false, nil
+	}
+
+	if err := contentStorage.WriteFile(fullPath, []byte(journalContent(date)), 0644); err != nil {
+		return "", false, err
+	}
+	if err := MetaDataSave(&Metadata{Path: pathutils.ToWithPrefix(relPath)}); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to save metadata for journal entry %s: %v", relPath, err)
+	}
+
+	logging.LogInfo(logging.KeyApp, "created journal entry: %s", relPath)
+	return relPath, true, nil
+}
+
+// journalContent renders the initial content of a new journal entry from the configured
+// template, falling back to a date heading when no template is set or it can't be read.
+func journalContent(date time.Time) string {
+	day := date.Format(journalDateLayout)
+	if tpl := configmanager.GetJournalTemplate(); tpl != "" {
+		content, err := contentStorage.ReadFile(pathutils.ToDocsPath(tpl))
+		if err == nil {
+			return strings.ReplaceAll(string(content), "{{date}}", day)
+		}
+		logging.LogWarning(logging.KeyApp, "failed to read journal template %s: %v", tpl, err)
+	}
+	return fmt.Sprintf("# %s\n\n", day)
+}
@@ -26,9 +26,6 @@ type MediaUploadResult struct {
 
 // UploadMedia handles the core media upload logic
 func UploadMedia(file multipart.File, header *multipart.FileHeader, contextPath string) (*MediaUploadResult, error) {
-	// get max upload size from settings
-	maxUploadSize := configmanager.GetMaxUploadSize()
-
 	// read file content
 	fileBytes, err := io.ReadAll(file)
 	if err != nil {
@@ -36,6 +33,16 @@ func UploadMedia(file multipart.File, header *multipart.FileHeader, contextPath
 		return nil, fmt.Errorf("failed to read uploaded file")
 	}
 
+	return storeMedia(fileBytes, header.Filename, contextPath, nil)
+}
+
+// storeMedia validates fileBytes against the upload size and mime type settings, writes
+// them to the media folder mirroring the docs folder of contextPath, and creates the
+// media file's metadata with the given references.
+func storeMedia(fileBytes []byte, filename, contextPath string, references []Reference) (*MediaUploadResult, error) {
+	// get max upload size from settings
+	maxUploadSize := configmanager.GetMaxUploadSize()
+
 	// check file size after reading
 	if int64(len(fileBytes)) > maxUploadSize {
 		logging.LogWarning(logging.KeyApp, "uploaded file too large: %d bytes (max: %d)", len(fileBytes), maxUploadSize)
@@ -66,7 +73,7 @@ func UploadMedia(file multipart.File, header *multipart.FileHeader, contextPath
 	}
 
 	// sanitize filename
-	sanitizedName := utils.SanitizeFilename(header.Filename, 255, true, false)
+	sanitizedName := utils.SanitizeFilename(filename, 255, true, false)
 
 	// create media path mirroring docs structure
 	var mediaPath string
@@ -96,6 +103,7 @@ func UploadMedia(file multipart.File, header *multipart.FileHeader, contextPath
 		// Editor is intentionally not set for media files — metaDataUpdate skips
 		// the editor fallback for media/ paths, so it stays empty.
 		// Filtering uses the path prefix + mime type via isHiddenByType instead.
+		References: references,
 	}
 
 	if err := MetaDataSave(metadata); err != nil {
@@ -0,0 +1,150 @@
+// Package files - caching of external images embedded in markdown files
+package files
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// externalImageRegex matches markdown images with an http(s) url: ![alt](url "title").
+// Group 1 is everything up to the url, group 2 the url.
+var externalImageRegex = regexp.MustCompile(`(!\[[^\]]*\]\(\s*<?)(https?://[^\s)>]+)`)
+
+// externalImageClient downloads external images; the timeout keeps a slow host from
+// blocking the save.
+var externalImageClient = &http.Client{Timeout: 15 * time.Second}
+
+// CacheExternalImages downloads the external images embedded in a markdown docs file into
+// the media folder next to it, rewrites their links to the local copies and records the
+// original url as a reference in the media metadata. Images exceeding the upload size or
+// not of an allowed image type are left linked externally. Does nothing unless the
+// cacheExternalImages setting is on. Returns the number of rewritten links.
+func CacheExternalImages(filePath string) (int, error) {
+	if !configmanager.GetCacheExternalImages() {
+		return 0, nil
+	}
+	filePath = pathutils.ToWithPrefix(filePath)
+	if !strings.HasPrefix(filePath, "docs/") || !strings.EqualFold(path.Ext(filePath), ".md") {
+		return 0, nil
+	}
+
+	fullPath := pathutils.ToDocsPath(pathutils.ToRelative(filePath))
+	content, err := contentStorage.ReadFile(fullPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// each url is downloaded once, even when embedded several times
+	cached := make(map[string]string)
+	rewritten := 0
+	newContent := externalImageRegex.ReplaceAllStringFunc(string(content), func(match string) string {
+		parts := externalImageRegex.FindStringSubmatch(match)
+		src := parts[2]
+		local, ok := cached[src]
+		if !ok {
+			local = findCachedExternalImage(src)
+		}
+		if !ok && local == "" {
+			result, err := downloadExternalImage(src, filePath)
+			if err != nil {
+				logging.LogWarning(logging.KeyApp, "not caching external image %s of %s: %v", src, filePath, err)
+			} else {
+				local = "/media/" + result.Path
+			}
+		}
+		cached[src] = local
+		if local == "" {
+			return match
+		}
+		rewritten++
+		return parts[1] + local
+	})
+	if rewritten == 0 {
+		return 0, nil
+	}
+
+	if err := contentStorage.WriteFile(fullPath, []byte(newContent), 0644); err != nil {
+		return 0, err
+	}
+	logging.LogInfo(logging.KeyApp, "cached %d external images of %s", rewritten, filePath)
+	return rewritten, nil
+}
+
+// findCachedExternalImage returns the link of a media file already cached from src, so
+// saving content that still embeds the url (e.g. an editor buffer) doesn't download it again.
+func findCachedExternalImage(src string) string {
+	mediaFiles, err := GetAllMediaFiles()
+	if err != nil {
+		return ""
+	}
+	for _, file := range mediaFiles {
+		if file.Metadata == nil {
+			continue
+		}
+		for _, ref := range file.Metadata.References {
+			if ref.URL == src {
+				return "/" + file.Path
+			}
+		}
+	}
+	return ""
+}
+
+// downloadExternalImage fetches src and stores it as media of contextPath.
+func downloadExternalImage(src, contextPath string) (*MediaUploadResult, error) {
+	resp, err := externalImageClient.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// read one byte past the limit so storeMedia rejects oversized images
+	maxUploadSize := configmanager.GetMaxUploadSize()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("not an image: %s", contentType)
+	}
+
+	references := []Reference{{URL: src, Description: "cached external image", AddedAt: time.Now()}}
+	return storeMedia(data, externalImageFilename(src, contentType), contextPath, references)
+}
+
+// externalImageFilename derives a media filename from the last url path segment, adding an
+// extension for the content type when the segment has none.
+func externalImageFilename(src, contentType string) string {
+	name := "image"
+	if u, err := url.Parse(src); err == nil {
+		if base := path.Base(u.Path); base != "/" && base != "." {
+			name = base
+		}
+	}
+	if path.Ext(name) == "" {
+		switch subtype := strings.TrimPrefix(contentType, "image/"); subtype {
+		case "jpeg":
+			name += ".jpg"
+		case "x-icon":
+			name += ".ico"
+		default:
+			name += "." + subtype
+		}
+	}
+	return name
+}
@@ -3,15 +3,20 @@ package files
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"knov/internal/chat"
 	"knov/internal/configmanager"
 	"knov/internal/logging"
+	"knov/internal/metadataHistoryStorage"
 	"knov/internal/metadataStorage"
 	"knov/internal/pathutils"
 	"knov/internal/searchStorage"
@@ -49,6 +54,89 @@ func AllEditorTypes() []EditorType {
 	}
 }
 
+// defaultEditorIcons are the Font Awesome icons shown for files of each editor type
+// unless the filetypeIcons setting overrides them.
+var defaultEditorIcons = map[EditorType]string{
+	EditorTypeToastUI:    "fa-file-lines",
+	EditorTypeTextarea:   "fa-file-alt",
+	EditorTypeFilter:     "fa-filter",
+	EditorTypeList:       "fa-list",
+	EditorTypeTodo:       "fa-list-check",
+	EditorTypeIndex:      "fa-sitemap",
+	EditorTypeCodeMirror: "fa-file-code",
+}
+
+// EditorTypeIcons returns the icon of every editor type: the configured filetypeIcons
+// entry where there is one, the built-in default otherwise. Entries for unknown
+// editor types are ignored.
+func EditorTypeIcons() map[EditorType]string {
+	icons := make(map[EditorType]string, len(defaultEditorIcons))
+	for editor, icon := range defaultEditorIcons {
+		icons[editor] = icon
+	}
+	for editor, icon := range configmanager.GetFiletypeIcons() {
+		if _, ok := icons[EditorType(editor)]; ok {
+			icons[EditorType(editor)] = icon
+		}
+	}
+	return icons
+}
+
+// IsValidLabel reports whether label is one of the configured label colors.
+func IsValidLabel(label string) bool {
+	return slices.Contains(configmanager.GetLabelColors(), label)
+}
+
+// ErrInvalidLabel is returned by MetaDataSetLabel for a label not in the labelColors setting.
+var ErrInvalidLabel = errors.New("label is not one of the label colors")
+
+// MetaDataSetLabel sets the color label of filePath, "" removes it. The metadata is saved
+// raw: a label changes nothing else about the file. The change is recorded in the metadata
+// history. Returns nil when the file has no metadata.
+func MetaDataSetLabel(filePath, label string) (*Metadata, error) {
+	if label != "" && !IsValidLabel(label) {
+		return nil, ErrInvalidLabel
+	}
+	var previous Metadata
+	metadata, err := MetaDataModifyRaw(filePath, func(metadata *Metadata) error {
+		previous = *metadata
+		metadata.Label = label
+		return nil
+	})
+	if err != nil || metadata == nil {
+		return nil, err
+	}
+	recordMetadataHistory(&previous, metadata)
+	InvalidateFileListCache()
+	return metadata, nil
+}
+
+// MetaDataSetCustomField sets the custom field key of filePath to value, "" removes it.
+// Like MetaDataSetLabel the metadata is saved raw and the change recorded in the metadata
+// history. Returns nil when the file has no metadata.
+func MetaDataSetCustomField(filePath, key, value string) (*Metadata, error) {
+	var previous Metadata
+	metadata, err := MetaDataModifyRaw(filePath, func(metadata *Metadata) error {
+		previous = *metadata
+		previous.Custom = maps.Clone(metadata.Custom)
+		if value == "" {
+			delete(metadata.Custom, key)
+			return nil
+		}
+		if metadata.Custom == nil {
+			metadata.Custom = make(map[string]string)
+		}
+		metadata.Custom[key] = value
+		return nil
+	})
+	if err != nil || metadata == nil {
+		return nil, err
+	}
+	recordMetadataHistory(&previous, metadata)
+	InvalidateFileListCache()
+	return metadata, nil
+}
+
 // EditorFromExtension infers an editor type from a file extension.
 // Returns empty string for generic/ambiguous extensions (e.g. .md).
 func EditorFromExtension(path string) EditorType {
@@ -68,30 +156,56 @@ func EditorFromExtension(path string) EditorType {
 	}
 }
 
+// defaultEditorFor returns the editor a file without one gets on save: the editor of its
+// filetype (see FiletypeField) when that is known, else derived from the extension for docs
+// files, the default markdown editor otherwise, none for media.
+func defaultEditorFor(path, filetype string) EditorType {
+	if pathutils.IsMedia(path) {
+		return ""
+	}
+	if editor := FiletypeEditor(filetype); editor != "" {
+		return editor
+	}
+	if editor := EditorFromExtension(path); editor != "" {
+		return editor
+	}
+	return EditorType(configmanager.GetDefaultMarkdownEditor())
+}
+
 // Metadata represents file metadata
 type Metadata struct {
-	Path          string      `json:"path"`                    // auto
-	Title         string      `json:"title"`                   // auto
-	CreatedAt     time.Time   `json:"createdAt"`               // auto
-	LastEdited    time.Time   `json:"lastEdited"`              // auto
-	Collection    string      `json:"collection"`              // auto
-	Folders       []string    `json:"folders"`                 // auto
-	Tags          []string    `json:"tags"`                    // manual
-	Ancestor      []string    `json:"ancestor"`                // auto
-	Parents       []string    `json:"parents"`                 // manual
-	Kids          []string    `json:"kids"`                    // auto
-	UsedLinks     []string    `json:"usedLinks"`               // auto
-	LinksToHere   []string    `json:"linksToHere"`             // auto
-	Related       []string    `json:"related,omitempty"`       // auto
-	Editor        EditorType  `json:"editor"`                  // manual
-	Size          int64       `json:"size"`                    // auto
-	References    []Reference `json:"references,omitempty"`    // manual
-	ConflictFile  string      `json:"conflictFile,omitempty"`  // auto
-	ConflictOf    string      `json:"conflictOf,omitempty"`    // auto
-	KanbanAddedAt time.Time   `json:"kanbanAddedAt,omitempty"` // auto
-	KanbanMovedAt time.Time   `json:"kanbanMovedAt,omitempty"` // auto
+	Path               string            `json:"path"`                    // auto
+	Title              string            `json:"title"`                   // auto
+	CreatedAt          time.Time         `json:"createdAt"`               // auto
+	LastEdited         time.Time         `json:"lastEdited"`              // auto
+	Collection         string            `json:"collection"`              // auto
+	Folders            []string          `json:"folders"`                 // auto
+	Tags               []string          `json:"tags"`                    // manual
+	Ancestor           []string          `json:"ancestor"`                // auto
+	Parents            []string          `json:"parents"`                 // manual
+	Kids               []string          `json:"kids"`                    // auto
+	UsedLinks          []string          `json:"usedLinks"`               // auto
+	LinksToHere        []string          `json:"linksToHere"`             // auto
+	Related            []string          `json:"related,omitempty"`       // auto
+	Editor             EditorType        `json:"editor"`                  // manual
+	Label              string            `json:"label,omitempty"`         // manual, one of the labelColors
+	Size               int64             `json:"size"`                    // auto
+	WordCount          int               `json:"wordCount"`               // auto
+	ReadingTimeMinutes int               `json:"readingTimeMinutes"`      // auto
+	References         []Reference       `json:"references,omitempty"`    // manual
+	ConflictFile       string            `json:"conflictFile,omitempty"`  // auto
+	ConflictOf         string            `json:"conflictOf,omitempty"`    // auto
+	KanbanAddedAt      time.Time         `json:"kanbanAddedAt,omitempty"` // auto
+	KanbanMovedAt      time.Time         `json:"kanbanMovedAt,omitempty"` // auto
+	Custom             map[string]string `json:"custom,omitempty"`        // manual
 }
 
+// TargetDateField is the custom field a file's planned target date (YYYY-MM-DD) is stored in.
+const TargetDateField = "targetDate"
+
+// PriorityField is the custom field a file's priority is stored in.
+const PriorityField = "priority"
+
 // Reference represents an external resource linked to a file
 type Reference struct {
 	URL         string    `json:"url"`
@@ -99,8 +213,8 @@ type Reference struct {
 	AddedAt     time.Time `json:"addedAt,omitempty"`
 }
 
-// kanbanStatusFromTags extracts the kanban status value from a tag list; returns "" if absent.
-func kanbanStatusFromTags(tags []string) string {
+// KanbanStatusFromTags extracts the kanban status value from a tag list; returns "" if absent.
+func KanbanStatusFromTags(tags []string) string {
 	prefix := configmanager.GetKanbanPrefix() + "-status-"
 	for _, t := range tags {
 		if strings.HasPrefix(t, prefix) {
@@ -113,7 +227,7 @@ func kanbanStatusFromTags(tags []string) string {
 // applyKanbanTimestamps updates KanbanAddedAt/KanbanMovedAt when the kanban
 // status tag transitions to a new (non-empty) value.
 func applyKanbanTimestamps(m *Metadata, oldStatus string) {
-	newStatus := kanbanStatusFromTags(m.Tags)
+	newStatus := KanbanStatusFromTags(m.Tags)
 	if newStatus == "" || newStatus == oldStatus {
 		return
 	}
@@ -124,26 +238,43 @@ func applyKanbanTimestamps(m *Metadata, oldStatus string) {
 	m.KanbanMovedAt = now
 }
 
+// metadataFullPath returns the filesystem path of the docs or media file a metadata path refers to.
+func metadataFullPath(filePath string) string {
+	if pathutils.IsMedia(filePath) {
+		return pathutils.ToMediaPath(pathutils.ToRelative(filePath))
+	}
+	return pathutils.ToDocsPath(pathutils.ToRelative(filePath))
+}
+
+// metaDataUpdate merges newMetadata into the stored metadata of filePath and
+// updates the kids lists of parents that were added or removed.
 func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
+	merged, oldParents, parentsChanged := metaDataMerge(filePath, newMetadata)
+	if parentsChanged {
+		updateParentChildRelationships(merged, oldParents)
+	}
+	return merged
+}
+
+// MetaDataPreview returns the metadata MetaDataSave would store for m without
+// saving it or touching the kids lists of its parents.
+func MetaDataPreview(m *Metadata) *Metadata {
+	merged, _, _ := metaDataMerge(m.Path, m)
+	return merged
+}
+
+// metaDataMerge computes the merged metadata for filePath without persisting anything.
+// When newMetadata carries parents it also returns the previous parents and
+// parentsChanged=true so the caller can update the parents' kids lists.
+func metaDataMerge(filePath string, newMetadata *Metadata) (merged *Metadata, oldParents []string, parentsChanged bool) {
 	currentMetadata, _ := MetaDataGet(filePath)
 
 	// determine if this is a media file or docs file based on the original path
 	isMediaFile := pathutils.IsMedia(filePath)
 
-	var fullPath string
-	var metadataPath string
-
-	if isMediaFile {
-		// for media files, keep the media/ prefix in metadata but get actual file path
-		normalizedPath := pathutils.ToRelative(filePath)
-		fullPath = pathutils.ToMediaPath(normalizedPath)
-		metadataPath = filePath // keep original path with media/ prefix
-	} else {
-		// for docs files, keep the docs/ prefix in metadata but get actual file path
-		normalizedPath := pathutils.ToRelative(filePath)
-		fullPath = pathutils.ToDocsPath(normalizedPath)
-		metadataPath = filePath // keep original path with docs/ prefix
-	}
+	// keep the original path with its docs/ or media/ prefix in metadata
+	fullPath := metadataFullPath(filePath)
+	metadataPath := filePath
 
 	// get file size
 	fileInfo, err := os.Stat(fullPath)
@@ -153,7 +284,8 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 		newMetadata.Size = fileInfo.Size()
 	}
 
-	if currentMetadata == nil {
+	isNew := currentMetadata == nil
+	if isNew {
 		// initialize new metadata
 		currentMetadata = &Metadata{
 			Path:      metadataPath,
@@ -161,6 +293,23 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 		}
 	}
 
+	// front matter tags and status are reconciled before the kanban tag sanitization below
+	tags := newMetadata.Tags
+	frontMatter := readFrontMatter(metadataPath, fullPath)
+	if frontMatter != nil {
+		tags = frontMatter.reconcileTags(metadataPath, currentMetadata.Tags, tags)
+	}
+
+	// configured default tags, status and priority only apply to genuinely new files -
+	// metadata carried over from a move/rename already has its CreatedAt set
+	applyDefaults := isNew && !isMediaFile && newMetadata.CreatedAt.IsZero()
+	if applyDefaults && len(tags) == 0 {
+		tags = configmanager.GetDefaultTags()
+	}
+	if status := configmanager.GetDefaultStatus(); applyDefaults && status != "" && KanbanStatusFromTags(tags) == "" {
+		tags = append(slices.Clone(tags), configmanager.KanbanStatusTag(status))
+	}
+
 	// update path and time fields
 	currentMetadata.Path = metadataPath
 	currentMetadata.LastEdited = time.Now()
@@ -177,9 +326,9 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 	}
 
 	// handle optional fields from newMetadata - only update if provided
-	if len(newMetadata.Tags) > 0 {
-		oldKanbanStatus := kanbanStatusFromTags(currentMetadata.Tags)
-		cleaned, err := sanitizeKanbanTags(newMetadata.Tags)
+	if len(tags) > 0 {
+		oldKanbanStatus := KanbanStatusFromTags(currentMetadata.Tags)
+		cleaned, err := sanitizeKanbanTags(tags)
 		if err != nil {
 			logging.LogWarning(logging.KeyApp, "tag sanitization for %s: %v", filePath, err)
 		}
@@ -189,7 +338,6 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 
 	if len(newMetadata.Parents) > 0 {
 		// store old parents for cleanup
-		var oldParents []string
 		if currentMetadata.Parents != nil {
 			oldParents = make([]string, len(currentMetadata.Parents))
 			copy(oldParents, currentMetadata.Parents)
@@ -201,21 +349,13 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 			normalized = append(normalized, utils.CleanLink(parent))
 		}
 		currentMetadata.Parents = normalized
-		// update parent-child relationships when parents change
-		updateParentChildRelationships(currentMetadata, oldParents)
+		parentsChanged = true
 	}
 	if newMetadata.Editor != "" {
 		currentMetadata.Editor = newMetadata.Editor
 	}
-
-	// only infer editor type for docs files — media files are identified
-	// by path prefix + mime type in filtering, not by editor type
-	if !isMediaFile && currentMetadata.Editor == "" {
-		if et := EditorFromExtension(metadataPath); et != "" {
-			currentMetadata.Editor = et
-		} else {
-			currentMetadata.Editor = EditorTypeToastUI
-		}
+	if newMetadata.Label != "" {
+		currentMetadata.Label = newMetadata.Label
 	}
 
 	if !newMetadata.CreatedAt.IsZero() {
@@ -224,6 +364,27 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 	if newMetadata.References != nil {
 		currentMetadata.References = newMetadata.References
 	}
+	if newMetadata.Custom != nil {
+		currentMetadata.Custom = newMetadata.Custom
+	}
+	if frontMatter != nil {
+		frontMatter.reconcileFields(currentMetadata)
+	}
+
+	// only infer editor type for docs files — media files are identified
+	// by path prefix + mime type in filtering, not by editor type
+	if !isMediaFile && currentMetadata.Editor == "" {
+		currentMetadata.Editor = defaultEditorFor(metadataPath, currentMetadata.Custom[FiletypeField])
+	}
+
+	if priority := configmanager.GetDefaultPriority(); applyDefaults && priority != "" && currentMetadata.Custom[PriorityField] == "" {
+		custom := maps.Clone(currentMetadata.Custom)
+		if custom == nil {
+			custom = map[string]string{}
+		}
+		custom[PriorityField] = priority
+		currentMetadata.Custom = custom
+	}
 
 	// make sure required fields are initialized
 	if currentMetadata.Tags == nil {
@@ -250,10 +411,40 @@ func metaDataUpdate(filePath string, newMetadata *Metadata) *Metadata {
 
 	updateAncestors(currentMetadata, nil)
 	updateUsedLinks(currentMetadata)
-	updateTitle(currentMetadata)
+	updateTitleAndWordCount(currentMetadata)
 	// updateKidsAndLinksToHere(currentMetadata) // shouldnt run with every filesave since it loops through all files
 
-	return currentMetadata
+	return currentMetadata, oldParents, parentsChanged
+}
+
+// DecodeMetadataJSON decodes metadata posted by a client. With the strictMetadataJSON setting
+// on, a field Metadata doesn't have is an error instead of being ignored.
+func DecodeMetadataJSON(r io.Reader) (*Metadata, error) {
+	var metadata Metadata
+	decoder := json.NewDecoder(r)
+	if configmanager.GetStrictMetadataJSON() {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// ValidateMetadataEnums checks the enumerated fields of metadata posted by a client: the editor
+// must be empty or one of AllEditorTypes, the label empty or a valid label and the kanban
+// tags must pass SanitizeKanbanTags.
+func ValidateMetadataEnums(metadata *Metadata) error {
+	if metadata.Editor != "" && !slices.Contains(AllEditorTypes(), metadata.Editor) {
+		return fmt.Errorf("invalid editor %q, expected one of %v", metadata.Editor, AllEditorTypes())
+	}
+	if metadata.Label != "" && !IsValidLabel(metadata.Label) {
+		return fmt.Errorf("invalid label %q, expected one of %v", metadata.Label, configmanager.GetLabelColors())
+	}
+	if _, err := sanitizeKanbanTags(metadata.Tags); err != nil {
+		return err
+	}
+	return nil
 }
 
 // SanitizeKanbanTags ensures at most one kanban status tag is present, that
@@ -386,8 +577,28 @@ func MetaDataSaveNoRefresh(m *Metadata) error {
 	return err
 }
 
-// metaDataSave does the actual write and reports whether anything was saved.
+// MetaDataRecompute refreshes the fields of a file's metadata derived from its path and
+// content (folders, collection, title, word count, used links, ancestors, size) by saving
+// it with an empty patch, so the manual fields are kept. Returns the updated metadata, nil
+// when the file has none.
+func MetaDataRecompute(filePath string) (*Metadata, error) {
+	normalizedPath := pathutils.ToWithPrefix(filePath)
+	if metadata, err := MetaDataGet(normalizedPath); err != nil || metadata == nil {
+		return nil, err
+	}
+	if err := MetaDataSave(&Metadata{Path: normalizedPath}); err != nil {
+		return nil, err
+	}
+	return MetaDataGet(normalizedPath)
+}
+
+// metaDataSave does the actual write and reports whether anything was saved. The
+// read-modify-write of the merge holds the path's lock, see lockMetadataPath.
 func metaDataSave(m *Metadata) (bool, error) {
+	unlock := lockMetadataPath(m.Path)
+	defer unlock()
+
+	previous, _ := MetaDataGet(m.Path)
 	finalMetadata := metaDataUpdate(m.Path, m)
 	if finalMetadata == nil {
 		return false, nil
@@ -399,11 +610,12 @@ func metaDataSave(m *Metadata) (bool, error) {
 		return false, err
 	}
 
-	if err := metadataStorage.Set(finalMetadata.Path, data); err != nil {
+	if err := storeMetadata(finalMetadata, data); err != nil {
 		logging.LogError(logging.KeyApp, "failed to save metadata for %s: %v", finalMetadata.Path, err)
 		return false, err
 	}
 
+	recordMetadataHistory(previous, finalMetadata)
 	logging.LogDebug(logging.KeyApp, "metadata saved for: %s", finalMetadata.Path)
 	return true, nil
 }
@@ -416,7 +628,7 @@ func MetaDataSaveRaw(m *Metadata) error {
 		return err
 	}
 
-	if err := metadataStorage.Set(m.Path, data); err != nil {
+	if err := storeMetadata(m, data); err != nil {
 		logging.LogError(logging.KeyApp, "failed to save metadata for %s: %v", m.Path, err)
 		return err
 	}
@@ -435,7 +647,7 @@ func MetaDataGet(filepath string) (*Metadata, error) {
 
 	logging.LogDebug(logging.KeyApp, "MetaDataGet: filepath='%s' -> normalizedPath='%s'", filepath, normalizedPath)
 
-	data, err := metadataStorage.Get(normalizedPath)
+	data, err := getStoredMetadata(normalizedPath)
 	if err != nil {
 		return nil, err
 	}
@@ -448,6 +660,8 @@ func MetaDataGet(filepath string) (*Metadata, error) {
 	if err := json.Unmarshal(data, &metadata); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal metadata for %s: %w", normalizedPath, err)
 	}
+	// backends deriving the path from the storage key report the archive tier key
+	metadata.Path = metadataKeyPath(metadata.Path)
 
 	return &metadata, nil
 }
@@ -513,6 +727,56 @@ func MetaDataInitializeAll() error {
 	return nil
 }
 
+// MetaDataRefreshTimes re-stats every docs and media file with metadata and sets
+// LastEdited and Size to what is on disk (CreatedAt too when it is zero), e.g. after
+// a git checkout changed file mtimes. All other fields are left as they are.
+// Returns the number of files whose metadata changed.
+func MetaDataRefreshTimes() (int, error) {
+	allFiles, err := GetAllPhysicalFiles()
+	if err != nil {
+		return 0, err
+	}
+	allMediaFiles, err := GetAllMediaFiles()
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to get media files for time refresh: %v", err)
+	}
+
+	updated := 0
+	for _, file := range append(allFiles, allMediaFiles...) {
+		metadataPath := pathutils.ToWithPrefix(file.Path)
+		metadata, err := MetaDataGet(metadataPath)
+		if err != nil || metadata == nil {
+			continue
+		}
+
+		info, err := os.Stat(metadataFullPath(metadataPath))
+		if err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to stat %s for time refresh: %v", metadataPath, err)
+			continue
+		}
+		if metadata.LastEdited.Equal(info.ModTime()) && metadata.Size == info.Size() && !metadata.CreatedAt.IsZero() {
+			continue
+		}
+
+		metadata.LastEdited = info.ModTime()
+		metadata.Size = info.Size()
+		if metadata.CreatedAt.IsZero() {
+			metadata.CreatedAt = info.ModTime()
+		}
+		if err := MetaDataSaveRaw(metadata); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to save refreshed times for %s: %v", metadataPath, err)
+			continue
+		}
+		updated++
+	}
+	if updated > 0 {
+		RefreshCaches()
+	}
+
+	logging.LogInfo(logging.KeyApp, "refreshed file times for %d files", updated)
+	return updated, nil
+}
+
 // MetaDataDelete removes metadata for a file path and refreshes the aggregate
 // caches. For deleting many files in one request, call MetaDataDeleteNoRefresh
 // in the loop and RefreshCaches() once afterwards instead - otherwise each
@@ -529,10 +793,27 @@ func MetaDataDelete(filepath string) error {
 // the aggregate caches (tags/collections/folders/editors/file list). See
 // MetaDataDelete.
 func MetaDataDeleteNoRefresh(key logging.Key, filepath string) error {
+	forgetDeletedFile(key, filepath)
+	normalized := pathutils.ToWithPrefix(filepath)
+	if err := metadataStorage.Delete(normalized); err != nil {
+		return err
+	}
+	if metadataStorage.Exists(archiveKeyPrefix + normalized) {
+		return metadataStorage.Delete(archiveKeyPrefix + normalized)
+	}
+	return nil
+}
+
+// forgetDeletedFile removes the chat messages, metadata history and search index entry of
+// a file whose metadata is being deleted.
+func forgetDeletedFile(key logging.Key, filepath string) {
 	normalized := pathutils.ToWithPrefix(filepath)
 	if err := chat.DeleteForFile(normalized); err != nil {
 		logging.LogWarning(key, "failed to delete chat messages for %s: %v", normalized, err)
 	}
+	if err := metadataHistoryStorage.DeleteByPath(normalized); err != nil {
+		logging.LogWarning(key, "failed to delete metadata history for %s: %v", normalized, err)
+	}
 	// remove from the live full-text search index too - otherwise a deleted
 	// file's content stays searchable forever, since IndexAllFiles only ever
 	// adds/updates entries for files that still exist, never prunes ones that
@@ -544,7 +825,82 @@ func MetaDataDeleteNoRefresh(key logging.Key, filepath string) error {
 	if err := searchStorage.DeleteIndexedContent(pathutils.ToRelative(filepath)); err != nil {
 		logging.LogWarning(key, "failed to remove %s from search index: %v", normalized, err)
 	}
-	return metadataStorage.Delete(normalized)
+}
+
+// MetaDataDeletePrefix removes the metadata of every file whose path starts with prefix
+// (e.g. "foo/" for a deleted folder) and returns the number of deleted entries. The
+// entries are deleted in one statement where the backend supports it. The deleted files
+// are dropped from the kids of their remaining parents and from the parents of their
+// remaining kids, and the caches are refreshed.
+func MetaDataDeletePrefix(prefix string) (int, error) {
+	if pathutils.ToRelative(prefix) == "" {
+		return 0, fmt.Errorf("prefix is required")
+	}
+	prefix = pathutils.ToWithPrefix(prefix)
+
+	all, err := metadataStorage.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	var deletedFiles []*Metadata
+	for key := range all {
+		path := metadataKeyPath(key)
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		metadata, err := MetaDataGet(path)
+		if err != nil || metadata == nil {
+			logging.LogWarning(logging.KeyApp, "failed to get metadata for %s: %v", path, err)
+			metadata = &Metadata{Path: path}
+		}
+		deletedFiles = append(deletedFiles, metadata)
+	}
+
+	for _, metadata := range deletedFiles {
+		forgetDeletedFile(logging.KeyApp, metadata.Path)
+	}
+	deleted, err := metadataStorage.DeletePrefix(prefix)
+	if err != nil {
+		return 0, err
+	}
+	archived, err := metadataStorage.DeletePrefix(archiveKeyPrefix + prefix)
+	if err != nil {
+		return 0, err
+	}
+	deleted += archived
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	isDeleted := func(path string) bool { return strings.HasPrefix(pathutils.ToWithPrefix(path), prefix) }
+	for _, metadata := range deletedFiles {
+		for _, parent := range metadata.Parents {
+			if !isDeleted(parent) {
+				removeBackReference(parent, metadata.Path, func(m *Metadata) *[]string { return &m.Kids })
+			}
+		}
+		for _, kid := range metadata.Kids {
+			if !isDeleted(kid) {
+				removeBackReference(kid, metadata.Path, func(m *Metadata) *[]string { return &m.Parents })
+			}
+		}
+	}
+
+	RefreshCaches()
+	logging.LogInfo(logging.KeyApp, "deleted metadata of %d files under %s", deleted, prefix)
+	return deleted, nil
+}
+
+// removeBackReference drops deletedPath from the list field of the metadata of path.
+func removeBackReference(path, deletedPath string, field func(*Metadata) *[]string) {
+	_, err := MetaDataModifyRaw(path, func(metadata *Metadata) error {
+		list := field(metadata)
+		*list = slices.DeleteFunc(*list, func(p string) bool { return pathutils.ToWithPrefix(p) == deletedPath })
+		return nil
+	})
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to remove %s from %s: %v", deletedPath, path, err)
+	}
 }
 
 // MetaDataExportAll returns all metadata entries
@@ -0,0 +1,147 @@
+// Package files - archive storage tier for the metadata of archived files
+package files
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/metadataStorage"
+)
+
+// archiveKeyPrefix prefixes the metadata storage keys of files in the archive tier, keeping
+// them apart from the docs/ and media/ keys of the main store.
+const archiveKeyPrefix = "archive/"
+
+// IsArchived reports whether metadata carries the kanban archive status tag.
+func IsArchived(m *Metadata) bool {
+	return m != nil && slices.Contains(m.Tags, configmanager.KanbanStatusTag(configmanager.GetKanbanArchiveStatus()))
+}
+
+// InArchiveTier reports whether the metadata of a file belongs in the archive tier: the
+// archiveTier setting is on and the file is archived. Tag, collection, folder and editor
+// counts and search leave these files out unless archived files are asked for.
+func InArchiveTier(m *Metadata) bool {
+	return configmanager.GetArchiveTier() && IsArchived(m)
+}
+
+// metadataStorageKey returns the storage key of the metadata of path in the main store or,
+// if archived, in the archive tier.
+func metadataStorageKey(path string, archived bool) string {
+	if archived {
+		return archiveKeyPrefix + path
+	}
+	return path
+}
+
+// metadataKeyPath returns the file path of a metadata storage key of either tier.
+func metadataKeyPath(key string) string {
+	return strings.TrimPrefix(key, archiveKeyPrefix)
+}
+
+// getStoredMetadata reads the stored metadata of path from the main store, falling back to
+// the archive tier. Returns nil data when neither has it.
+func getStoredMetadata(path string) ([]byte, error) {
+	data, err := metadataStorage.Get(path)
+	if err != nil || data != nil {
+		return data, err
+	}
+	return metadataStorage.Get(archiveKeyPrefix + path)
+}
+
+// metadataStored reports whether either tier has metadata for path.
+func metadataStored(path string) bool {
+	return metadataStorage.Exists(path) || metadataStorage.Exists(archiveKeyPrefix+path)
+}
+
+// storeMetadata writes the encoded metadata m to the tier it belongs in and removes the
+// copy in the other tier, so archiving and unarchiving a file moves its metadata.
+func storeMetadata(m *Metadata, data []byte) error {
+	archived := InArchiveTier(m)
+	if err := metadataStorage.Set(metadataStorageKey(m.Path, archived), data); err != nil {
+		return err
+	}
+	dropOtherTier(m.Path, archived)
+	return nil
+}
+
+// dropOtherTier deletes the metadata of path from the tier it no longer belongs in.
+func dropOtherTier(path string, archived bool) {
+	stale := metadataStorageKey(path, !archived)
+	if !metadataStorage.Exists(stale) {
+		return
+	}
+	if err := metadataStorage.Delete(stale); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to remove metadata of %s from the previous storage tier: %v", path, err)
+	}
+}
+
+// MigrateArchiveTier moves every metadata entry into the tier it belongs in, after the
+// archiveTier setting or the kanban archive status changed. Returns the number of moved
+// entries; the caches are refreshed when any moved. Each entry is read again under its path
+// lock, so a save landing after the listing is moved instead of overwritten.
+func MigrateArchiveTier() (int, error) {
+	all, err := metadataStorage.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	seen := make(map[string]bool, len(all))
+	for key := range all {
+		path := metadataKeyPath(key)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		unlock := lockMetadataPath(path)
+		ok, err := migrateArchiveTierEntry(path)
+		unlock()
+		if err != nil {
+			logging.LogError(logging.KeyApp, "archive tier: failed to move metadata of %s: %v", path, err)
+			continue
+		}
+		if ok {
+			moved++
+		}
+	}
+
+	if moved > 0 {
+		logging.LogInfo(logging.KeyApp, "archive tier: moved the metadata of %d files", moved)
+		RefreshCaches()
+	}
+	return moved, nil
+}
+
+// migrateArchiveTierEntry moves the stored metadata of path into the tier it belongs in and
+// reports whether it moved. The caller holds the path lock.
+func migrateArchiveTierEntry(path string) (bool, error) {
+	data, err := getStoredMetadata(path)
+	if err != nil || data == nil {
+		return false, err
+	}
+	var metadata Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		logging.LogWarning(logging.KeyApp, "archive tier: failed to decode metadata of %s: %v", path, err)
+		return false, nil
+	}
+	metadata.Path = path
+
+	current := metadataStorageKey(path, !metadataStorage.Exists(path))
+	archived := InArchiveTier(&metadata)
+	if current == metadataStorageKey(path, archived) {
+		return false, nil
+	}
+	encoded, err := json.Marshal(&metadata)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "archive tier: failed to encode metadata of %s: %v", path, err)
+		return false, nil
+	}
+	if err := metadataStorage.Set(metadataStorageKey(path, archived), encoded); err != nil {
+		return false, err
+	}
+	return true, metadataStorage.Delete(current)
+}
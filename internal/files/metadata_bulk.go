@@ -0,0 +1,243 @@
+// Package files - metadata updates and imports applied to many files at once
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/metadataStorage"
+	"knov/internal/pathutils"
+)
+
+// MetadataChanges is a partial metadata update for MetaDataBulkSet. Like MetaDataSave only
+// the fields that are set change: Tags replaces the tag list, Status the kanban status tag
+// (keeping the other tags), Custom sets its keys and removes those with an empty value,
+// the others replace the field.
+type MetadataChanges struct {
+	Tags    []string          `json:"tags,omitempty"`
+	Status  string            `json:"status,omitempty"`
+	Parents []string          `json:"parents,omitempty"`
+	Editor  EditorType        `json:"editor,omitempty"`
+	Label   string            `json:"label,omitempty"`
+	Custom  map[string]string `json:"custom,omitempty"`
+}
+
+// IsEmpty reports whether the changes set no field.
+func (c MetadataChanges) IsEmpty() bool {
+	return len(c.Tags) == 0 && c.Status == "" && len(c.Parents) == 0 && c.Editor == "" && c.Label == "" && len(c.Custom) == 0
+}
+
+// Validate checks the enumerated fields like ValidateMetadataEnums, the status must be one
+// of the configured kanban statuses.
+func (c MetadataChanges) Validate() error {
+	if c.Status != "" && !slices.Contains(configmanager.GetKanbanStatuses(), c.Status) {
+		return fmt.Errorf("invalid status %q, expected one of %v", c.Status, configmanager.GetKanbanStatuses())
+	}
+	return ValidateMetadataEnums(&Metadata{Tags: c.Tags, Editor: c.Editor, Label: c.Label})
+}
+
+// patchFor returns the MetaDataSave patch applying the changes to current.
+func (c MetadataChanges) patchFor(current *Metadata) *Metadata {
+	patch := &Metadata{Path: current.Path, Tags: c.Tags, Parents: c.Parents, Editor: c.Editor, Label: c.Label}
+
+	if c.Status != "" {
+		base := c.Tags
+		if len(base) == 0 {
+			base = current.Tags
+		}
+		tags := make([]string, 0, len(base)+1)
+		for _, tag := range base {
+			if !configmanager.IsKanbanTag(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		patch.Tags = append(tags, configmanager.KanbanStatusTag(c.Status))
+	}
+
+	if len(c.Custom) > 0 {
+		custom := maps.Clone(current.Custom)
+		if custom == nil {
+			custom = map[string]string{}
+		}
+		for key, value := range c.Custom {
+			if value == "" {
+				delete(custom, key)
+			} else {
+				custom[key] = value
+			}
+		}
+		patch.Custom = custom
+	}
+	return patch
+}
+
+// BulkSetResult is the outcome of MetaDataBulkSet for one path.
+type BulkSetResult struct {
+	Path    string `json:"path"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// MetaDataBulkSet applies the same changes to the metadata of every path and stores all of
+// them in one metadataStorage.BulkSet, so a transactional backend writes them in a single
+// transaction. Paths without metadata fail on their own, a failing write fails every path
+// it carried. The results are in the order of paths, duplicates reported once.
+func MetaDataBulkSet(paths []string, changes MetadataChanges) []BulkSetResult {
+	var normalized []string
+	for _, path := range paths {
+		if path = pathutils.ToWithPrefix(path); !slices.Contains(normalized, path) {
+			normalized = append(normalized, path)
+		}
+	}
+
+	// the locks are taken in sorted order, so two bulk saves can't deadlock each other
+	for _, path := range slices.Sorted(slices.Values(normalized)) {
+		defer lockMetadataPath(path)()
+	}
+
+	errs := make(map[string]error, len(normalized))
+	previous := make(map[string]*Metadata, len(normalized))
+	saved := make(map[string]*Metadata, len(normalized))
+	entries := make(map[string][]byte, len(normalized))
+	for _, path := range normalized {
+		current, err := MetaDataGet(path)
+		if err != nil {
+			errs[path] = err
+			continue
+		}
+		if current == nil {
+			errs[path] = fmt.Errorf("metadata not found")
+			continue
+		}
+
+		merged := metaDataUpdate(path, changes.patchFor(current))
+		data, err := json.Marshal(merged)
+		if err != nil {
+			errs[path] = err
+			continue
+		}
+		previous[path], saved[path], entries[path] = current, merged, data
+	}
+
+	if err := saveMetadataBulk(entries, previous, saved); err != nil {
+		for path := range entries {
+			errs[path] = err
+		}
+	}
+
+	results := make([]BulkSetResult, 0, len(normalized))
+	for _, path := range normalized {
+		result := BulkSetResult{Path: path, Success: errs[path] == nil}
+		if errs[path] != nil {
+			result.Error = errs[path].Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// MetadataImportSummary is the outcome of MetaDataImport: how many rows were saved, how many
+// were skipped and why.
+type MetadataImportSummary struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors"`
+}
+
+// MetaDataImport upserts imported metadata rows like MetaDataSave, creating the metadata of
+// files that have none, and stores all of them in one metadataStorage.BulkSet. Rows without a
+// path, for files that don't exist on disk, with invalid enumerated fields or repeating an
+// earlier path are skipped and reported by their 1-based row number.
+func MetaDataImport(rows []*Metadata) MetadataImportSummary {
+	summary := MetadataImportSummary{Errors: []string{}}
+	skip := func(row int, err error) {
+		summary.Skipped++
+		summary.Errors = append(summary.Errors, fmt.Sprintf("row %d: %v", row, err))
+	}
+
+	imports := make(map[string]*Metadata, len(rows))
+	rowOf := make(map[string]int, len(rows))
+	var paths []string
+	for i, row := range rows {
+		if row == nil || strings.TrimSpace(row.Path) == "" {
+			skip(i+1, fmt.Errorf("missing path"))
+			continue
+		}
+		path := pathutils.ToWithPrefix(row.Path)
+		if _, err := os.Stat(metadataFullPath(path)); err != nil {
+			skip(i+1, fmt.Errorf("%s does not exist", path))
+			continue
+		}
+		if _, ok := imports[path]; ok {
+			skip(i+1, fmt.Errorf("duplicate path %s", path))
+			continue
+		}
+		if err := ValidateMetadataEnums(row); err != nil {
+			skip(i+1, err)
+			continue
+		}
+		row.Path = path
+		imports[path], rowOf[path] = row, i+1
+		paths = append(paths, path)
+	}
+
+	// the locks are taken in sorted order, like MetaDataBulkSet
+	for _, path := range slices.Sorted(slices.Values(paths)) {
+		defer lockMetadataPath(path)()
+	}
+
+	previous := make(map[string]*Metadata, len(paths))
+	saved := make(map[string]*Metadata, len(paths))
+	entries := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		current, err := MetaDataGet(path)
+		if err != nil {
+			skip(rowOf[path], err)
+			continue
+		}
+		merged := metaDataUpdate(path, imports[path])
+		data, err := json.Marshal(merged)
+		if err != nil {
+			skip(rowOf[path], err)
+			continue
+		}
+		previous[path], saved[path], entries[path] = current, merged, data
+	}
+
+	if err := saveMetadataBulk(entries, previous, saved); err != nil {
+		summary.Skipped += len(entries)
+		summary.Errors = append(summary.Errors, fmt.Sprintf("failed to save %d rows: %v", len(entries), err))
+		return summary
+	}
+	summary.Imported = len(entries)
+	return summary
+}
+
+// saveMetadataBulk stores the encoded metadata entries in one metadataStorage.BulkSet, each in
+// its storage tier, and records the history of each file from its previous and saved metadata.
+func saveMetadataBulk(entries map[string][]byte, previous, saved map[string]*Metadata) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	keyed := make(map[string][]byte, len(entries))
+	for path, data := range entries {
+		keyed[metadataStorageKey(path, InArchiveTier(saved[path]))] = data
+	}
+	if err := metadataStorage.BulkSet(keyed); err != nil {
+		logging.LogError(logging.KeyApp, "failed to bulk save metadata of %d files: %v", len(entries), err)
+		return err
+	}
+	for path := range entries {
+		dropOtherTier(path, InArchiveTier(saved[path]))
+		recordMetadataHistory(previous[path], saved[path])
+	}
+	RefreshCaches()
+	logging.LogInfo(logging.KeyApp, "bulk saved metadata of %d files", len(entries))
+	return nil
+}
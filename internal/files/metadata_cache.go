@@ -2,13 +2,17 @@
 package files
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	"knov/internal/cacheStorage"
+	"knov/internal/configmanager"
 	"knov/internal/logging"
 	"knov/internal/pathutils"
 	"knov/internal/utils"
@@ -79,6 +83,8 @@ func GetAllFilesCached() ([]File, error) {
 		return cached, nil
 	}
 
+	rebuildCachesMu.Lock()
+	defer rebuildCachesMu.Unlock()
 	allFiles, err := GetAllPhysicalFiles()
 	if err != nil {
 		return nil, err
@@ -100,6 +106,16 @@ func InvalidateFileListCache() {
 	}
 }
 
+// InvalidateTagCache drops the cached tag names and counts, so tag lists fall back to
+// live data until the next rebuild. Called after tags were changed across many files.
+func InvalidateTagCache() {
+	for _, key := range []CacheKey{CacheKeyTags, CacheKeyTagCounts} {
+		if err := cacheStorage.Delete(string(key)); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to invalidate %s cache: %v", key, err)
+		}
+	}
+}
+
 // RefreshCaches invalidates the file list cache immediately (so the very next
 // request gets fresh data) and rebuilds all other caches - tags, collections,
 // folders, file/folder paths, orphaned media - in the background. Call this
@@ -195,8 +211,32 @@ func sortedCountKeys(counts map[string]int) []string {
 	return keys
 }
 
-// GetAllTags returns all unique tags with their counts
-func GetAllTags() (TagCount, error) {
+// TopCountKeys returns the names from a name->count map ordered by count (highest
+// first, ties alphabetically), narrowed to names containing query (case-insensitive)
+// and capped at limit entries. A limit <= 0 disables the cap.
+func TopCountKeys[M ~map[string]int](counts M, query string, limit int) []string {
+	query = strings.ToLower(query)
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		if query == "" || strings.Contains(strings.ToLower(k), query) {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+// GetAllTags returns all unique tags with their counts. Files in the archive tier (see
+// InArchiveTier) are only counted with includeArchived, like in the other GetAll counts.
+func GetAllTags(includeArchived bool) (TagCount, error) {
 	allFiles, err := GetAllFiles()
 	if err != nil {
 		return nil, err
@@ -205,7 +245,7 @@ func GetAllTags() (TagCount, error) {
 	tagCount := make(TagCount)
 	for _, file := range allFiles {
 		metadata, err := MetaDataGet(file.Path)
-		if err != nil || metadata == nil {
+		if err != nil || metadata == nil || !includeArchived && InArchiveTier(metadata) {
 			continue
 		}
 		for _, tag := range metadata.Tags {
@@ -218,8 +258,8 @@ func GetAllTags() (TagCount, error) {
 	return tagCount, nil
 }
 
-// GetAllCollections returns all unique collections with their counts
-func GetAllCollections() (CollectionCount, error) {
+// GetAllCollections returns all unique collections with their counts, see GetAllTags
+func GetAllCollections(includeArchived bool) (CollectionCount, error) {
 	allFiles, err := GetAllFiles()
 	if err != nil {
 		return nil, err
@@ -228,10 +268,10 @@ func GetAllCollections() (CollectionCount, error) {
 	collectionCount := make(CollectionCount)
 	for _, file := range allFiles {
 		metadata, err := MetaDataGet(file.Path)
-		if err != nil || metadata == nil {
+		if err != nil || metadata == nil || !includeArchived && InArchiveTier(metadata) {
 			continue
 		}
-		if metadata.Collection != "" {
+		if metadata.Collection != "" && !configmanager.IsCollectionExcluded(metadata.Collection) {
 			collectionCount[metadata.Collection]++
 		}
 	}
@@ -239,8 +279,8 @@ func GetAllCollections() (CollectionCount, error) {
 	return collectionCount, nil
 }
 
-// GetAllFolders returns all unique folders with their counts
-func GetAllFolders() (FolderCount, error) {
+// GetAllFolders returns all unique folders with their counts, see GetAllTags
+func GetAllFolders(includeArchived bool) (FolderCount, error) {
 	allFiles, err := GetAllFiles()
 	if err != nil {
 		return nil, err
@@ -249,7 +289,7 @@ func GetAllFolders() (FolderCount, error) {
 	folderCount := make(FolderCount)
 	for _, file := range allFiles {
 		metadata, err := MetaDataGet(file.Path)
-		if err != nil || metadata == nil {
+		if err != nil || metadata == nil || !includeArchived && InArchiveTier(metadata) {
 			continue
 		}
 		for _, f := range metadata.Folders {
@@ -262,8 +302,8 @@ func GetAllFolders() (FolderCount, error) {
 	return folderCount, nil
 }
 
-// GetAllEditors returns all unique filetypes with their counts
-func GetAllEditors() (EditorTypeCount, error) {
+// GetAllEditors returns all unique filetypes with their counts, see GetAllTags
+func GetAllEditors(includeArchived bool) (EditorTypeCount, error) {
 	allFiles, err := GetAllFiles()
 	if err != nil {
 		return nil, err
@@ -272,7 +312,7 @@ func GetAllEditors() (EditorTypeCount, error) {
 	editorTypeCount := make(EditorTypeCount)
 	for _, file := range allFiles {
 		metadata, err := MetaDataGet(file.Path)
-		if err != nil || metadata == nil {
+		if err != nil || metadata == nil || !includeArchived && InArchiveTier(metadata) {
 			continue
 		}
 		if metadata.Editor != "" {
@@ -285,7 +325,7 @@ func GetAllEditors() (EditorTypeCount, error) {
 
 // SaveAllTagsToCache saves all unique tags, and their counts, to cache storage
 func SaveAllTagsToCache() error {
-	allTags, err := GetAllTags()
+	allTags, err := GetAllTags(false)
 	if err != nil {
 		return err
 	}
@@ -313,7 +353,7 @@ func GetAllTagsCountFromCache() (TagCount, error) {
 
 // SaveAllCollectionsToCache saves all unique collections, and their counts, to cache storage
 func SaveAllCollectionsToCache() error {
-	allCollections, err := GetAllCollections()
+	allCollections, err := GetAllCollections(false)
 	if err != nil {
 		return err
 	}
@@ -324,9 +364,14 @@ func SaveAllCollectionsToCache() error {
 	return saveStringListToCache(CacheKeyCollections, sortedCountKeys(allCollections))
 }
 
-// GetAllCollectionsFromCache retrieves cached collection names from cache storage
+// GetAllCollectionsFromCache retrieves cached collection names from cache storage.
+// Excluded collections are dropped on read so a settings change applies without a cache rebuild.
 func GetAllCollectionsFromCache() ([]string, error) {
-	return getStringListFromCache(CacheKeyCollections)
+	collections, err := getStringListFromCache(CacheKeyCollections)
+	if err != nil || collections == nil {
+		return collections, err
+	}
+	return slices.DeleteFunc(collections, configmanager.IsCollectionExcluded), nil
 }
 
 // GetAllCollectionsCountFromCache retrieves cached collection counts from cache storage.
@@ -336,12 +381,15 @@ func GetAllCollectionsCountFromCache() (CollectionCount, error) {
 	if err != nil || counts == nil {
 		return nil, err
 	}
+	maps.DeleteFunc(counts, func(collection string, _ int) bool {
+		return configmanager.IsCollectionExcluded(collection)
+	})
 	return CollectionCount(counts), nil
 }
 
 // SaveAllFoldersToCache saves all unique folders, and their counts, to cache storage
 func SaveAllFoldersToCache() error {
-	allFolders, err := GetAllFolders()
+	allFolders, err := GetAllFolders(false)
 	if err != nil {
 		return err
 	}
@@ -369,7 +417,7 @@ func GetAllFoldersCountFromCache() (FolderCount, error) {
 
 // SaveAllEditorsToCache saves all editor type counts to cache storage
 func SaveAllEditorsToCache() error {
-	allEditors, err := GetAllEditors()
+	allEditors, err := GetAllEditors(false)
 	if err != nil {
 		return err
 	}
@@ -412,6 +460,33 @@ func GetAllTitlesFromCache() ([]string, error) {
 	return getStringListFromCache(CacheKeyTitles)
 }
 
+// GetRecentlyCreated returns the metadata of the most recently created visible files,
+// newest first, read from the cached file list. limit <= 0 returns all of them.
+func GetRecentlyCreated(limit int) ([]*Metadata, error) {
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+
+	var created []*Metadata
+	for _, file := range FilterByVisibility(allFiles) {
+		if file.Metadata != nil && !file.Metadata.CreatedAt.IsZero() {
+			created = append(created, file.Metadata)
+		}
+	}
+	slices.SortStableFunc(created, func(a, b *Metadata) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	if limit > 0 && len(created) > limit {
+		created = created[:limit]
+	}
+	return created, nil
+}
+
 // GetAllTitles returns all unique non-empty titles, reading from file content if the DB title is empty
 func GetAllTitles() ([]string, error) {
 	allFiles, err := GetAllFiles()
@@ -430,7 +505,7 @@ func GetAllTitles() ([]string, error) {
 		}
 		title := meta.Title
 		if title == "" {
-			updateTitle(meta)
+			updateTitleAndWordCount(meta)
 			title = meta.Title
 		}
 		logging.LogDebug(logging.KeyApp, "getAllTitles: %s -> %q", file.Path, title)
@@ -472,32 +547,35 @@ func NewMetadataCollector() *MetadataCollector {
 	}
 }
 
-// CollectFromMetadata adds metadata to the collector
+// CollectFromMetadata adds metadata to the collector. Files in the archive tier are left out
+// of the tag, collection, folder and editor counts, like in GetAllTags.
 func (mc *MetadataCollector) CollectFromMetadata(filePath string, metadata *Metadata) {
 	// collect file path
 	mc.FilePaths = append(mc.FilePaths, filePath)
 
-	// collect tags
-	for _, tag := range metadata.Tags {
-		if tag != "" {
-			mc.Tags[tag]++
+	if !InArchiveTier(metadata) {
+		// collect tags
+		for _, tag := range metadata.Tags {
+			if tag != "" {
+				mc.Tags[tag]++
+			}
 		}
-	}
 
-	// collect collections
-	if metadata.Collection != "" {
-		mc.Collections[metadata.Collection]++
-	}
+		// collect collections
+		if metadata.Collection != "" {
+			mc.Collections[metadata.Collection]++
+		}
 
-	for _, f := range metadata.Folders {
-		if f != "" {
-			mc.Folders[f]++
+		for _, f := range metadata.Folders {
+			if f != "" {
+				mc.Folders[f]++
+			}
 		}
-	}
 
-	// collect editor type
-	if metadata.Editor != "" {
-		mc.Editors[string(metadata.Editor)]++
+		// collect editor type
+		if metadata.Editor != "" {
+			mc.Editors[string(metadata.Editor)]++
+		}
 	}
 
 	// collect folder paths from file path
@@ -513,7 +591,7 @@ func (mc *MetadataCollector) CollectFromMetadata(filePath string, metadata *Meta
 	// collect title (fall back to reading from file content if not in DB)
 	title := metadata.Title
 	if title == "" {
-		updateTitle(metadata)
+		updateTitleAndWordCount(metadata)
 		title = metadata.Title
 	}
 	if title != "" {
@@ -572,8 +650,15 @@ func (mc *MetadataCollector) SaveAllToCache() error {
 	return nil
 }
 
+// rebuildCachesMu serializes the writers of the file list cache, RebuildAllCaches and the
+// live fallback of GetAllFilesCached: the background rebuild of an earlier RefreshCaches
+// could otherwise finish last and write back a file list from before a later mutation.
+var rebuildCachesMu sync.Mutex
+
 // RebuildAllCaches saves all metadata lists to cache storage in a single pass
 func RebuildAllCaches() error {
+	rebuildCachesMu.Lock()
+	defer rebuildCachesMu.Unlock()
 	logging.LogInfo(logging.KeyFileSync, "collecting all system metadata for cache update")
 
 	collector := NewMetadataCollector()
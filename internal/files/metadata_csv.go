@@ -0,0 +1,142 @@
+// Package files - metadata csv export and the csv and json metadata import formats
+package files
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+)
+
+// MetadataCSVColumns are the columns of the metadata CSV export, in order. The list columns
+// (tags, folders) join their values with ';'.
+var MetadataCSVColumns = []string{"path", "title", "collection", "editor", "createdat", "lastedited", "tags", "folders"}
+
+// ErrEmptyImport is returned by ParseMetadataImport for an import without content.
+var ErrEmptyImport = errors.New("empty import")
+
+// MetadataCSV generates CSV content for metadata export
+func MetadataCSV(metadata []*Metadata) string {
+	var csv strings.Builder
+
+	// header
+	csv.WriteString(strings.Join(MetadataCSVColumns, ",") + "\n")
+
+	for _, m := range metadata {
+		if m == nil {
+			continue
+		}
+
+		// escape csv values
+		path := escapeCSV(m.Path)
+		name := escapeCSV(m.Title)
+		collection := escapeCSV(m.Collection)
+		editor := escapeCSV(string(m.Editor))
+		createdat := configmanager.FormatDateTimeSeconds(m.CreatedAt)
+		lastedited := configmanager.FormatDateTimeSeconds(m.LastEdited)
+		tags := escapeCSV(strings.Join(m.Tags, ";"))
+		folders := escapeCSV(strings.Join(m.Folders, ";"))
+
+		csv.WriteString(fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s\n",
+			path, name, collection, editor, createdat, lastedited,
+			tags, folders))
+	}
+
+	return csv.String()
+}
+
+// escapeCSV escapes a string for CSV format
+func escapeCSV(s string) string {
+	if strings.Contains(s, ",") || strings.Contains(s, "\"") || strings.Contains(s, "\n") {
+		s = strings.ReplaceAll(s, "\"", "\"\"")
+		return "\"" + s + "\""
+	}
+	return s
+}
+
+// ParseMetadataImport reads the rows of a metadata import for MetaDataImport: a JSON array
+// of metadata or a CSV in the layout of MetadataCSV. Returns ErrEmptyImport for blank data.
+func ParseMetadataImport(data []byte) ([]*Metadata, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, ErrEmptyImport
+	}
+	if trimmed[0] == '[' {
+		var rows []*Metadata
+		if err := json.Unmarshal(trimmed, &rows); err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+	return ParseMetadataCSV(trimmed)
+}
+
+// ParseMetadataCSV reads the rows of a metadata CSV, the first line naming the columns out
+// of MetadataCSVColumns. Dates are read in the format of the export.
+func ParseMetadataCSV(data []byte) ([]*Metadata, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	header := records[0]
+	for _, column := range header {
+		if !slices.Contains(MetadataCSVColumns, column) {
+			return nil, fmt.Errorf("unknown column %s", column)
+		}
+	}
+	if !slices.Contains(header, "path") {
+		return nil, fmt.Errorf("missing path column")
+	}
+
+	splitList := func(s string) []string {
+		var values []string
+		for _, value := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	rows := make([]*Metadata, 0, len(records)-1)
+	for i, record := range records[1:] {
+		m := &Metadata{}
+		for col, value := range record {
+			value = strings.TrimSpace(value)
+			switch header[col] {
+			case "path":
+				m.Path = value
+			case "title":
+				m.Title = value
+			case "collection":
+				m.Collection = value
+			case "editor":
+				m.Editor = EditorType(value)
+			case "createdat", "lastedited":
+				if value == "" {
+					continue
+				}
+				t, err := configmanager.ParseDateTimeSeconds(value)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: invalid %s %q", i+1, header[col], value)
+				}
+				if header[col] == "createdat" {
+					m.CreatedAt = t
+				} else {
+					m.LastEdited = t
+				}
+			case "tags":
+				m.Tags = splitList(value)
+			case "folders":
+				m.Folders = splitList(value)
+			}
+		}
+		rows = append(rows, m)
+	}
+	return rows, nil
+}
@@ -0,0 +1,81 @@
+// Package files - change history of the user-facing metadata fields
+package files
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/metadataHistoryStorage"
+	"knov/internal/pathutils"
+)
+
+// MetadataChange is one changed metadata field of a file, see metadataHistoryStorage.Change.
+type MetadataChange = metadataHistoryStorage.Change
+
+// GetMetadataHistory returns the recorded metadata changes of path, newest first.
+func GetMetadataHistory(path string) ([]MetadataChange, error) {
+	return metadataHistoryStorage.Get(pathutils.ToWithPrefix(path), 0)
+}
+
+// historyFields returns the metadata fields the history records, in display form. Fields
+// derived from the content, links or path (kids, used links, size, folders, ...) are left
+// out: they change with every edit or link rebuild and would drown the manual changes.
+func historyFields(m *Metadata) map[string]string {
+	references := make([]string, 0, len(m.References))
+	for _, ref := range m.References {
+		references = append(references, ref.URL)
+	}
+	createdAt := ""
+	if !m.CreatedAt.IsZero() {
+		createdAt = m.CreatedAt.Format(time.RFC3339)
+	}
+
+	fields := map[string]string{
+		"title":      m.Title,
+		"collection": m.Collection,
+		"tags":       strings.Join(m.Tags, ", "),
+		"parents":    strings.Join(m.Parents, ", "),
+		"editor":     string(m.Editor),
+		"label":      m.Label,
+		"createdAt":  createdAt,
+		"references": strings.Join(references, ", "),
+	}
+	for key, value := range m.Custom {
+		fields["custom."+key] = value
+	}
+	return fields
+}
+
+// recordMetadataHistory records the fields that differ between the previous and the saved
+// metadata of a file. Nothing is recorded for new files or when the metadataHistoryLimit
+// setting is 0. Failures are logged, the save itself already succeeded.
+func recordMetadataHistory(previous, saved *Metadata) {
+	keep := configmanager.GetMetadataHistoryLimit()
+	if previous == nil || saved == nil || keep == 0 {
+		return
+	}
+
+	path := pathutils.ToWithPrefix(saved.Path)
+	before, after := historyFields(previous), historyFields(saved)
+	now := time.Now()
+	var changes []MetadataChange
+	for _, field := range slices.Sorted(maps.Keys(after)) {
+		if before[field] != after[field] {
+			changes = append(changes, MetadataChange{Path: path, Field: field, OldValue: before[field], NewValue: after[field], Timestamp: now})
+		}
+	}
+	// custom fields that were removed
+	for _, field := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[field]; !ok {
+			changes = append(changes, MetadataChange{Path: path, Field: field, OldValue: before[field], Timestamp: now})
+		}
+	}
+
+	if err := metadataHistoryStorage.Add(changes, keep); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to record metadata history of %s: %v", path, err)
+	}
+}
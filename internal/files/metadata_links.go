@@ -10,8 +10,10 @@ import (
 	"strings"
 
 	"knov/internal/chat"
+	"knov/internal/configmanager"
 	"knov/internal/contentStorage"
 	"knov/internal/logging"
+	"knov/internal/metadataHistoryStorage"
 	"knov/internal/parser"
 	"knov/internal/pathutils"
 	"knov/internal/utils"
@@ -108,7 +110,7 @@ func MetaDataLinksRebuild(key logging.Key) error {
 			kidsMap[parent] = append(kidsMap[parent], normalizedPath)
 		}
 
-		updateTitle(metadata)
+		updateTitleAndWordCount(metadata)
 
 		if err := MetaDataSaveRaw(metadata); err != nil {
 			logging.LogWarning(key, "failed to save metadata for %s: %v", metadata.Path, err)
@@ -199,7 +201,7 @@ func MetaDataLinksRebuildForFile(filePath string) error {
 
 	updateAncestors(metadata, nil)
 	updateUsedLinks(metadata)
-	updateTitle(metadata)
+	updateTitleAndWordCount(metadata)
 
 	if err := MetaDataSaveRaw(metadata); err != nil {
 		return err
@@ -219,9 +221,13 @@ func MetaDataLinksRebuildForFile(filePath string) error {
 	return nil
 }
 
+// updateAncestors sets the top ancestor of every parent chain of metadata. A chain that
+// loops or is deeper than the maxHierarchyDepth setting gives no ancestor and is recorded in
+// the hierarchy problems report, which is cleared for the file once its chains are fine.
 func updateAncestors(metadata *Metadata, cache map[string]*Metadata) {
 	visited := make(map[string]bool)
 	var ancestors []string
+	var problem *HierarchyProblem
 
 	for _, parent := range metadata.Parents {
 		if visited[parent] {
@@ -229,47 +235,54 @@ func updateAncestors(metadata *Metadata, cache map[string]*Metadata) {
 		}
 		visited[parent] = true
 
-		ancestor := findTopAncestor(parent, make(map[string]bool), cache)
+		ancestor, chainProblem := findTopAncestor(parent, cache)
+		if chainProblem != nil && problem == nil {
+			problem = chainProblem
+		}
 		if ancestor != "" && ancestor != metadata.Path {
 			ancestors = append(ancestors, ancestor)
 		}
 	}
 
 	metadata.Ancestor = ancestors
+	setHierarchyProblem(pathutils.ToWithPrefix(metadata.Path), problem)
 }
 
-func findTopAncestor(filePath string, visited map[string]bool, cache map[string]*Metadata) string {
-	if visited[filePath] {
-		logging.LogWarning(logging.KeyApp, "cycle detected in parent chain for %s", filePath)
-		return ""
-	}
-	visited[filePath] = true
-
-	var metadata *Metadata
-	if cache != nil {
-		cacheKey := pathutils.ToWithPrefix(filePath)
-		metadata = cache[cacheKey]
-
-	}
+// findTopAncestor follows the first parent of filePath up to the top of its chain. A chain
+// that loops or has more than maxHierarchyDepth files returns no ancestor and the problem.
+func findTopAncestor(filePath string, cache map[string]*Metadata) (string, *HierarchyProblem) {
+	maxDepth := configmanager.GetMaxHierarchyDepth()
+	visited := make(map[string]bool)
+	var chain []string
 
-	if metadata == nil {
-		var err error
-		metadata, err = MetaDataGet(filePath)
-		if err != nil || metadata == nil {
-			logging.LogWarning(logging.KeyApp, "cannot find metadata for parent %s", filePath)
-			return filePath
+	for {
+		if visited[filePath] {
+			return "", &HierarchyProblem{Kind: HierarchyCycle, Chain: append(chain, filePath)}
 		}
-	}
+		if len(chain) >= maxDepth {
+			return "", &HierarchyProblem{Kind: HierarchyTooDeep, Chain: chain}
+		}
+		visited[filePath] = true
+		chain = append(chain, filePath)
 
-	if len(metadata.Parents) == 0 {
-		return filePath
-	}
+		var metadata *Metadata
+		if cache != nil {
+			metadata = cache[pathutils.ToWithPrefix(filePath)]
+		}
+		if metadata == nil {
+			var err error
+			metadata, err = MetaDataGet(filePath)
+			if err != nil || metadata == nil {
+				logging.LogWarning(logging.KeyApp, "cannot find metadata for parent %s", filePath)
+				return filePath, nil
+			}
+		}
 
-	for _, parent := range metadata.Parents {
-		return findTopAncestor(parent, visited, cache)
+		if len(metadata.Parents) == 0 {
+			return filePath, nil
+		}
+		filePath = metadata.Parents[0]
 	}
-
-	return filePath
 }
 
 // resolveMediaLink promotes a link lacking the "media/" prefix to its prefixed
@@ -446,6 +459,9 @@ func UpdateLinksForMovedFileNoRefresh(key logging.Key, oldPath, newPath string)
 	if err := chat.MoveFilePath(normalizedOldPath, normalizedNewPath); err != nil {
 		logging.LogWarning(key, "failed to move chat messages for %s -> %s: %v", normalizedOldPath, normalizedNewPath, err)
 	}
+	if err := metadataHistoryStorage.MovePath(normalizedOldPath, normalizedNewPath); err != nil {
+		logging.LogWarning(key, "failed to move metadata history for %s -> %s: %v", normalizedOldPath, normalizedNewPath, err)
+	}
 
 	movedMetadata, err := MetaDataGet(normalizedNewPath)
 	if err != nil || movedMetadata == nil {
@@ -651,34 +667,48 @@ func moveFileMetadata(key logging.Key, oldPath, newPath string) error {
 	return nil
 }
 
-// updateTitle extracts the title from the first markdown header in the file.
-func updateTitle(metadata *Metadata) {
+// MetaDataRecountWords recounts the words and reading time of a file from its current
+// content and saves them without touching its other fields. Returns the updated metadata,
+// nil when the file has none.
+func MetaDataRecountWords(filePath string) (*Metadata, error) {
+	metadata, err := MetaDataGet(pathutils.ToWithPrefix(filePath))
+	if err != nil || metadata == nil {
+		return nil, err
+	}
+
+	updateTitleAndWordCount(metadata)
+	if err := MetaDataSaveRaw(metadata); err != nil {
+		return nil, err
+	}
+	RefreshCaches()
+	return metadata, nil
+}
+
+// wordsPerMinute is the reading speed ReadingTimeMinutes assumes.
+const wordsPerMinute = 200
+
+// updateTitleAndWordCount reads the file once to extract the title from its first markdown
+// header and to count its words (see parser.CountWords) and reading time, rounded up.
+func updateTitleAndWordCount(metadata *Metadata) {
 	if strings.HasPrefix(metadata.Path, "media/") {
 		return
 	}
 
 	fullPath := pathutils.ToFullPath(metadata.Path)
 
-	logging.LogDebug(logging.KeyApp, "extracting title for %s", metadata.Path)
+	logging.LogDebug(logging.KeyApp, "extracting title and word count for %s", metadata.Path)
 
-	file, err := os.Open(fullPath)
+	content, err := os.ReadFile(fullPath)
 	if err != nil {
-		logging.LogWarning(logging.KeyApp, "failed to open file %s: %v", fullPath, err)
-		return
-	}
-	defer file.Close()
-
-	buffer := make([]byte, 1024)
-	n, err := file.Read(buffer)
-	if err != nil && n == 0 {
 		logging.LogWarning(logging.KeyApp, "failed to read file %s: %v", fullPath, err)
 		return
 	}
 
-	content := string(buffer[:n])
+	metadata.WordCount = parser.CountWords(content)
+	metadata.ReadingTimeMinutes = (metadata.WordCount + wordsPerMinute - 1) / wordsPerMinute
 
 	// strip YAML front matter before scanning for the title header
-	body := parser.StripFrontMatter([]byte(content))
+	body := parser.StripFrontMatter(content)
 	lines := strings.Split(string(body), "\n")
 
 	for _, line := range lines {
@@ -730,6 +760,9 @@ func updateParentChildRelationships(metadata *Metadata, oldParents []string) {
 	for _, newParent := range metadata.Parents {
 		if !slices.Contains(oldParents, newParent) {
 			parentMetadata, err := MetaDataGet(newParent)
+			if err == nil && parentMetadata == nil {
+				parentMetadata = createMissingParent(newParent)
+			}
 			if err != nil || parentMetadata == nil {
 				logging.LogWarning(logging.KeyApp, "failed to get metadata for new parent %s: %v", newParent, err)
 				continue
@@ -748,10 +781,44 @@ func updateParentChildRelationships(metadata *Metadata, oldParents []string) {
 	}
 }
 
-// UpdateLinksForSingleFile updates link metadata for a single file incrementally.
+// createMissingParent creates an empty docs note for a parent that doesn't exist yet when
+// the createMissingParents setting is on, returning its unsaved metadata. Returns nil when
+// the setting is off, the parent is media, fails pathutils.SafeVaultPath or its file exists
+// without metadata.
+func createMissingParent(parentPath string) *Metadata {
+	if !configmanager.GetCreateMissingParents() || pathutils.IsMedia(parentPath) {
+		return nil
+	}
+	if _, err := pathutils.SafeVaultPath(parentPath); err != nil {
+		logging.LogWarning(logging.KeyApp, "skipping unsafe missing parent %s: %v", parentPath, err)
+		return nil
+	}
+	fullPath := pathutils.ToDocsPath(parentPath)
+	if exists, err := contentStorage.FileExists(fullPath); err != nil || exists {
+		return nil
+	}
+
+	if err := contentStorage.WriteFile(fullPath, []byte{}, 0644); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to create missing parent %s: %v", parentPath, err)
+		return nil
+	}
+	// merged rather than saved: the caller saves it raw with the kid, without taking its lock
+	metadataPath := pathutils.ToWithPrefix(parentPath)
+	metadata, _, _ := metaDataMerge(metadataPath, &Metadata{Path: metadataPath})
+	logging.LogInfo(logging.KeyApp, "created missing parent note: %s", parentPath)
+	return metadata
+}
+
+// UpdateLinksForSingleFile updates link metadata for a single file incrementally after its
+// content changed. With the retitleOnSave setting its title and word count are refreshed too.
 func UpdateLinksForSingleFile(filePath string) error {
 	logging.LogInfo(logging.KeyApp, "updating links for file: %s", filePath)
 
+	// rewrite external images to their cached copies first, so the links below point at them
+	if _, err := CacheExternalImages(filePath); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to cache external images of %s: %v", filePath, err)
+	}
+
 	metadata, err := MetaDataGet(filePath)
 	if err != nil || metadata == nil {
 		logging.LogWarning(logging.KeyApp, "failed to get metadata for file %s: %v", filePath, err)
@@ -760,10 +827,21 @@ func UpdateLinksForSingleFile(filePath string) error {
 
 	updateUsedLinks(metadata)
 
+	oldTitle, oldWordCount := metadata.Title, metadata.WordCount
+	if configmanager.GetRetitleOnSave() {
+		updateTitleAndWordCount(metadata)
+	}
+
 	if err := MetaDataSaveRaw(metadata); err != nil {
 		logging.LogError(logging.KeyApp, "failed to save updated metadata for file %s: %v", filePath, err)
 		return err
 	}
+	if metadata.Title != oldTitle {
+		logging.LogInfo(logging.KeyApp, "title of %s changed to %q", filePath, metadata.Title)
+		RefreshCaches()
+	} else if metadata.WordCount != oldWordCount {
+		InvalidateFileListCache()
+	}
 
 	logging.LogInfo(logging.KeyApp, "updated links for file %s: %d outbound links", filePath, len(metadata.UsedLinks))
 	return nil
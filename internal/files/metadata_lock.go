@@ -0,0 +1,70 @@
+// Package files - per-path locking of metadata read-modify-write sequences
+package files
+
+import (
+	"sync"
+
+	"knov/internal/pathutils"
+)
+
+// pathLock is the mutex of one metadata path; refs counts the goroutines holding or
+// waiting for it, so unused locks are dropped from metadataLocks.
+type pathLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+var (
+	metadataLocksMu sync.Mutex
+	metadataLocks   = make(map[string]*pathLock)
+)
+
+// lockMetadataPath locks the metadata of filePath until the returned unlock is called, so
+// concurrent saves to the same file (e.g. autosave and a manual save) serialize instead
+// of losing one another's update. Locks of different paths are independent; never take a
+// second one while holding one, parents and kids are saved raw without locking. The only
+// exception is MetaDataBulkSet, which takes all its paths' locks in sorted order.
+func lockMetadataPath(filePath string) (unlock func()) {
+	key := pathutils.ToWithPrefix(filePath)
+
+	metadataLocksMu.Lock()
+	lock, ok := metadataLocks[key]
+	if !ok {
+		lock = &pathLock{}
+		metadataLocks[key] = lock
+	}
+	lock.refs++
+	metadataLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		metadataLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(metadataLocks, key)
+		}
+		metadataLocksMu.Unlock()
+	}
+}
+
+// MetaDataModifyRaw reads the metadata of filePath, applies modify and saves the result
+// raw (see MetaDataSaveRaw), holding the path's lock throughout so concurrent saves can't
+// interleave. Returns nil without calling modify when the file has no metadata, and
+// doesn't save when modify returns an error. Caches are not refreshed.
+func MetaDataModifyRaw(filePath string, modify func(*Metadata) error) (*Metadata, error) {
+	unlock := lockMetadataPath(filePath)
+	defer unlock()
+
+	metadata, err := MetaDataGet(pathutils.ToWithPrefix(filePath))
+	if err != nil || metadata == nil {
+		return nil, err
+	}
+	if err := modify(metadata); err != nil {
+		return nil, err
+	}
+	if err := MetaDataSaveRaw(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -3,6 +3,7 @@ package files
 
 import (
 	"fmt"
+	"strings"
 
 	"knov/internal/logging"
 	"knov/internal/metadataStorage"
@@ -39,7 +40,7 @@ func MetaDataPurgeStale() (int, error) {
 
 	var purged int
 	for key := range all {
-		if _, ok := valid[key]; !ok {
+		if _, ok := valid[metadataKeyPath(key)]; !ok {
 			if err := metadataStorage.Delete(key); err != nil {
 				logging.LogWarning(logging.KeyApp, "failed to delete stale metadata for %s: %v", key, err)
 				continue
@@ -65,6 +66,9 @@ func MetaDataPurgeDuplicates() (int, error) {
 	var duplicates []string
 
 	for key := range all {
+		if strings.HasPrefix(key, archiveKeyPrefix) {
+			continue // archive tier keys are always normalized, see storeMetadata
+		}
 		norm := pathutils.ToWithPrefix(key)
 		if existing, ok := canonical[norm]; ok {
 			if key == norm {
@@ -0,0 +1,178 @@
+// Package files - repair of the metadata inconsistencies ValidateAllMetadata reports
+package files
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// RepairOptions selects the repairs RepairMetadata runs. With DryRun the report lists the
+// changes without saving them.
+type RepairOptions struct {
+	PruneRelations     bool `json:"pruneRelations"`     // drop parents and kids pointing to files that don't exist
+	ResetInvalid       bool `json:"resetInvalid"`       // reset unknown editors, clear unknown labels, drop unknown kanban status tags
+	RebuildLinksToHere bool `json:"rebuildLinksToHere"` // recompute linksToHere from the usedLinks of all files
+	DryRun             bool `json:"dryRun"`
+}
+
+// RepairChange is one change RepairMetadata made (or would make) to the metadata of Path.
+// Field is the json name of the changed metadata field.
+type RepairChange struct {
+	Path   string `json:"path"`
+	Field  string `json:"field"`
+	Change string `json:"change"`
+}
+
+// RepairReport is the result of RepairMetadata: the changes and the number of files they
+// touch.
+type RepairReport struct {
+	Changes []RepairChange `json:"changes"`
+	Files   int            `json:"files"`
+	DryRun  bool           `json:"dryRun"`
+}
+
+// RepairMetadata fixes the inconsistencies ValidateAllMetadata reports, as selected by
+// opts. Dangling used links are left alone: they come from the file content. Each file is
+// saved raw, under its metadata lock, so the repair doesn't cascade into link or parent
+// updates of other files; the caches are refreshed once at the end.
+func RepairMetadata(opts RepairOptions) (RepairReport, error) {
+	report := RepairReport{Changes: []RepairChange{}, DryRun: opts.DryRun}
+
+	allFiles, exists, err := existingFiles()
+	if err != nil {
+		return report, err
+	}
+
+	var linksToHere map[string][]string
+	if opts.RebuildLinksToHere {
+		linksToHere = make(map[string][]string)
+		for _, f := range allFiles {
+			metadata, err := MetaDataGet(f.Path)
+			if err != nil || metadata == nil {
+				continue
+			}
+			for _, target := range metadata.UsedLinks {
+				target = pathutils.ToWithPrefix(target)
+				if !slices.Contains(linksToHere[target], metadata.Path) {
+					linksToHere[target] = append(linksToHere[target], metadata.Path)
+				}
+			}
+		}
+	}
+
+	repair := func(metadata *Metadata) []RepairChange {
+		return repairFile(metadata, opts, exists, linksToHere)
+	}
+
+	for _, f := range allFiles {
+		metadata, err := MetaDataGet(f.Path)
+		if err != nil || metadata == nil {
+			continue
+		}
+		changes := repair(metadata)
+		if len(changes) == 0 {
+			continue
+		}
+
+		if !opts.DryRun {
+			// repair the current metadata, it may have changed since the read above
+			_, err := MetaDataModifyRaw(metadata.Path, func(current *Metadata) error {
+				changes = repair(current)
+				return nil
+			})
+			if err != nil {
+				logging.LogWarning(logging.KeyApp, "repair metadata: failed to save %s: %v", metadata.Path, err)
+				continue
+			}
+			if len(changes) == 0 {
+				continue
+			}
+		}
+		report.Changes = append(report.Changes, changes...)
+		report.Files++
+	}
+
+	if !opts.DryRun && report.Files > 0 {
+		InvalidateTagCache()
+		RefreshCaches()
+		logging.LogInfo(logging.KeyApp, "repaired metadata of %d files (%d changes)", report.Files, len(report.Changes))
+	}
+	return report, nil
+}
+
+// repairFile applies the repairs selected by opts to metadata and returns the changes.
+// linksToHere maps each link target to its sources, nil unless opts.RebuildLinksToHere.
+func repairFile(metadata *Metadata, opts RepairOptions, exists func(string) bool, linksToHere map[string][]string) []RepairChange {
+	var changes []RepairChange
+	change := func(field, format string, args ...any) {
+		changes = append(changes, RepairChange{Path: metadata.Path, Field: field, Change: fmt.Sprintf(format, args...)})
+	}
+
+	if opts.PruneRelations {
+		metadata.Parents = slices.DeleteFunc(metadata.Parents, func(parent string) bool {
+			if exists(parent) {
+				return false
+			}
+			change("parents", "removed missing parent %s", parent)
+			return true
+		})
+		metadata.Kids = slices.DeleteFunc(metadata.Kids, func(kid string) bool {
+			if exists(kid) {
+				return false
+			}
+			change("kids", "removed missing kid %s", kid)
+			return true
+		})
+	}
+
+	if opts.ResetInvalid {
+		if metadata.Editor != "" && !slices.Contains(AllEditorTypes(), metadata.Editor) {
+			editor := defaultEditorFor(metadata.Path, metadata.Custom[FiletypeField])
+			change("editor", "reset unknown editor %s to %q", metadata.Editor, editor)
+			metadata.Editor = editor
+		}
+		if metadata.Label != "" && !IsValidLabel(metadata.Label) {
+			change("label", "cleared unknown label %s", metadata.Label)
+			metadata.Label = ""
+		}
+		statusPrefix := configmanager.GetKanbanPrefix() + "-status-"
+		statuses := configmanager.GetKanbanStatuses()
+		metadata.Tags = slices.DeleteFunc(metadata.Tags, func(tag string) bool {
+			status, ok := strings.CutPrefix(tag, statusPrefix)
+			if !ok || slices.Contains(statuses, status) {
+				return false
+			}
+			change("tags", "removed unknown kanban status tag %s", tag)
+			return true
+		})
+	}
+
+	if opts.RebuildLinksToHere {
+		rebuilt := linksToHere[pathutils.ToWithPrefix(metadata.Path)]
+		if rebuilt == nil {
+			rebuilt = []string{}
+		}
+		if !sameElements(metadata.LinksToHere, rebuilt) {
+			change("linksToHere", "rebuilt from %v to %v", metadata.LinksToHere, rebuilt)
+			metadata.LinksToHere = rebuilt
+		}
+	}
+
+	return changes
+}
+
+// sameElements reports whether a and b hold the same strings, ignoring order.
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
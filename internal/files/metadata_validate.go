@@ -0,0 +1,95 @@
+// Package files - read-only consistency check of the stored metadata
+package files
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/pathutils"
+)
+
+// ValidationIssue is one inconsistency found by ValidateAllMetadata. Field is the json name
+// of the metadata field the problem is in.
+type ValidationIssue struct {
+	Path    string `json:"path"`
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
+
+// ValidateAllMetadata checks the metadata of every docs and media file for parents, kids
+// and used links pointing to files that don't exist, editors not in AllEditorTypes, labels
+// not in the configured label colors and kanban status tags not in the configured kanban
+// statuses. Nothing is changed. Each file's
+// metadata is read on its own, so no storage lock is held for the whole scan.
+func ValidateAllMetadata() ([]ValidationIssue, error) {
+	allFiles, exists, err := existingFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	statusPrefix := configmanager.GetKanbanPrefix() + "-status-"
+	statuses := configmanager.GetKanbanStatuses()
+
+	issues := []ValidationIssue{}
+	for _, f := range allFiles {
+		metadata, err := MetaDataGet(f.Path)
+		if err != nil || metadata == nil {
+			continue
+		}
+		report := func(field, problem string, args ...any) {
+			issues = append(issues, ValidationIssue{Path: metadata.Path, Field: field, Problem: fmt.Sprintf(problem, args...)})
+		}
+
+		for _, parent := range metadata.Parents {
+			if !exists(parent) {
+				report("parents", "parent %s does not exist", parent)
+			}
+		}
+		for _, kid := range metadata.Kids {
+			if !exists(kid) {
+				report("kids", "kid %s does not exist", kid)
+			}
+		}
+		for _, target := range metadata.UsedLinks {
+			if !exists(target) {
+				report("usedLinks", "link target %s does not exist", target)
+			}
+		}
+		if metadata.Editor != "" && !slices.Contains(AllEditorTypes(), metadata.Editor) {
+			report("editor", "unknown editor %s", metadata.Editor)
+		}
+		if metadata.Label != "" && !IsValidLabel(metadata.Label) {
+			report("label", "unknown label %s", metadata.Label)
+		}
+		for _, tag := range metadata.Tags {
+			if status, ok := strings.CutPrefix(tag, statusPrefix); ok && !slices.Contains(statuses, status) {
+				report("tags", "unknown kanban status %s", status)
+			}
+		}
+	}
+
+	slices.SortStableFunc(issues, func(a, b ValidationIssue) int { return strings.Compare(a.Path, b.Path) })
+	return issues, nil
+}
+
+// existingFiles returns the docs and media files and a lookup reporting whether a path, in
+// any of the forms pathutils accepts, is one of them.
+func existingFiles() ([]File, func(string) bool, error) {
+	docFiles, err := GetAllPhysicalFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	mediaFiles, err := GetAllMediaFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allFiles := slices.Concat(docFiles, mediaFiles)
+	validPaths := make(map[string]bool, len(allFiles))
+	for _, f := range allFiles {
+		validPaths[pathutils.ToWithPrefix(f.Path)] = true
+	}
+	return allFiles, func(path string) bool { return validPaths[pathutils.ToWithPrefix(path)] }, nil
+}
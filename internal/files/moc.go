@@ -0,0 +1,98 @@
+// Package files - generated collection MOCs (maps of content)
+package files
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// ErrCollectionEmpty is returned by GenerateCollectionMOC when no file belongs to the collection.
+var ErrCollectionEmpty = errors.New("collection has no files")
+
+// CollectionIndex returns the docs-relative path of the index file of the collection filePath
+// belongs to: the collection MOC at the collectionMocPath setting, whether generated or
+// written by hand. It is "" for files outside a collection, when the collection has no index
+// file and for the index file itself.
+func CollectionIndex(filePath string) string {
+	collection := CollectionFromPath(filePath)
+	if collection == "" {
+		return ""
+	}
+	mocPath := configmanager.GetCollectionMOCPath(collection)
+	if mocPath == pathutils.ToRelative(filePath) {
+		return ""
+	}
+	if _, err := os.Stat(pathutils.ToDocsPath(mocPath)); err != nil {
+		return ""
+	}
+	return mocPath
+}
+
+// GenerateCollectionMOC writes a MOC listing every file of collection as [[wikilinks]],
+// grouped under one "## folder" title per folder - the same format the index editor
+// saves - to the configured collection MOC path and returns that docs-relative path.
+// Regenerating overwrites the previous MOC; the MOC never lists itself.
+func GenerateCollectionMOC(collection string) (string, error) {
+	mocPath := configmanager.GetCollectionMOCPath(collection)
+	// the collectionMocPath setting may still point outside the docs folder
+	if _, err := pathutils.SafeVaultPath(pathutils.ToWithPrefix(mocPath)); err != nil {
+		return "", err
+	}
+
+	allFiles, err := GetAllFiles()
+	if err != nil {
+		return "", err
+	}
+
+	byFolder := make(map[string][]string)
+	for _, file := range allFiles {
+		relPath := pathutils.ToRelative(file.Path)
+		if relPath == mocPath {
+			continue
+		}
+		metadata, err := MetaDataGet(file.Path)
+		if err != nil || metadata == nil || metadata.Collection != collection {
+			continue
+		}
+		folder := FolderFromPath(relPath)
+		byFolder[folder] = append(byFolder[folder], relPath)
+	}
+	if len(byFolder) == 0 {
+		return "", ErrCollectionEmpty
+	}
+
+	var content strings.Builder
+	for _, folder := range slices.Sorted(maps.Keys(byFolder)) {
+		fmt.Fprintf(&content, "\n## %s\n\n", folder)
+		paths := byFolder[folder]
+		slices.Sort(paths)
+		for _, p := range paths {
+			fmt.Fprintf(&content, "- [[%s]]\n", p)
+		}
+	}
+
+	fullPath := pathutils.ToDocsPath(mocPath)
+	if err := contentStorage.WriteFile(fullPath, []byte(content.String()), 0644); err != nil {
+		return "", err
+	}
+
+	metadataPath := pathutils.ToWithPrefix(mocPath)
+	if err := MetaDataSave(&Metadata{Path: metadataPath, Editor: EditorTypeIndex}); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to save metadata for moc %s: %v", mocPath, err)
+	}
+	if err := UpdateLinksForSingleFile(metadataPath); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to update links for moc %s: %v", mocPath, err)
+	}
+
+	logging.LogInfo(logging.KeyApp, "generated moc for collection %s at %s", collection, mocPath)
+	return mocPath, nil
+}
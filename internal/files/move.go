@@ -0,0 +1,108 @@
+// Package files - batch folder moves with link rewriting
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+var (
+	// ErrFolderNotFound is returned by MoveFolder when the source folder does not exist.
+	ErrFolderNotFound = errors.New("folder does not exist")
+	// ErrFolderExists is returned by MoveFolder when the target folder already exists.
+	ErrFolderExists = errors.New("target folder already exists")
+	// ErrFolderIntoItself is returned by MoveFolder when the target lies inside the source.
+	ErrFolderIntoItself = errors.New("cannot move folder into itself")
+)
+
+// folderMove is a single file relocated as part of a folder move.
+type folderMove struct {
+	oldRel string
+	newRel string
+}
+
+// MoveFolder moves every file under oldPrefix to newPrefix (both docs-relative
+// folder paths) and rewrites all links in the vault that point at the moved
+// files, reusing the single-file rename logic (UpdateLinksForMovedFileNoRefresh)
+// for each file and refreshing the aggregate caches once at the end.
+func MoveFolder(oldPrefix, newPrefix string) error {
+	oldPrefix = strings.Trim(filepath.ToSlash(filepath.Clean(oldPrefix)), "/")
+	newPrefix = strings.Trim(filepath.ToSlash(filepath.Clean(newPrefix)), "/")
+
+	if oldPrefix == newPrefix {
+		return nil
+	}
+	if strings.HasPrefix(newPrefix+"/", oldPrefix+"/") {
+		return ErrFolderIntoItself
+	}
+
+	oldFullPath := pathutils.ToDocsPath(oldPrefix)
+	if info, err := os.Stat(oldFullPath); err != nil || !info.IsDir() {
+		return ErrFolderNotFound
+	}
+	newFullPath := pathutils.ToDocsPath(newPrefix)
+	if _, err := os.Stat(newFullPath); err == nil {
+		return ErrFolderExists
+	}
+
+	// collect all files before the move so we can update their links
+	var moves []folderMove
+	movedTo := make(map[string]string)
+	_ = filepath.Walk(oldFullPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		oldRel := pathutils.ToRelative(p)
+		newRel := pathutils.ToRelative(newFullPath + strings.TrimPrefix(p, oldFullPath))
+		moves = append(moves, folderMove{oldRel: oldRel, newRel: newRel})
+		movedTo[pathutils.ToWithPrefix(oldRel)] = pathutils.ToWithPrefix(newRel)
+		return nil
+	})
+
+	if err := os.MkdirAll(filepath.Dir(newFullPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(oldFullPath, newFullPath); err != nil {
+		return err
+	}
+
+	// files inside the folder that link to each other have already moved on
+	// disk, so their LinksToHere entries must point at the new location before
+	// the per-file rewrite reads them - otherwise the rewrite tries to open the
+	// linking file at its old (now missing) path and skips it.
+	for _, m := range moves {
+		metadata, err := MetaDataGet(m.oldRel)
+		if err != nil || metadata == nil {
+			continue
+		}
+		changed := false
+		for i, linker := range metadata.LinksToHere {
+			if newLinker, ok := movedTo[linker]; ok {
+				metadata.LinksToHere[i] = newLinker
+				changed = true
+			}
+		}
+		if changed {
+			if err := MetaDataSaveRaw(metadata); err != nil {
+				logging.LogWarning(logging.KeyApp, "failed to remap linkstohere for %s: %v", m.oldRel, err)
+			}
+		}
+	}
+
+	for _, m := range moves {
+		if err := UpdateLinksForMovedFileNoRefresh(logging.KeyApp, m.oldRel, m.newRel); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to update links for %s -> %s: %v", m.oldRel, m.newRel, err)
+		}
+	}
+	if len(moves) > 0 {
+		RefreshCaches()
+	}
+
+	logging.LogInfo(logging.KeyApp, "moved folder %s -> %s (%d files updated)", oldPrefix, newPrefix, len(moves))
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package files - removal of empty folders left behind by moves and deletes
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// PruneEmptyDirs removes empty directories under the data dir, deepest first, so a
+// folder whose only content was empty subfolders is removed as well. The docs and
+// media roots, hidden directories (e.g. .git) and the storage and logs directories
+// are never touched. Returns the number of removed directories.
+func PruneEmptyDirs() (int, error) {
+	dataPath := filepath.Clean(configmanager.GetDataPath())
+	keep := []string{dataPath, filepath.Clean(pathutils.ToDocsPath("")), filepath.Clean(pathutils.ToMediaPath(""))}
+	skip := []string{filepath.Clean(configmanager.GetStoragePath()), filepath.Clean(configmanager.GetLogsPath())}
+
+	var dirs []string
+	err := filepath.WalkDir(dataPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dataPath && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skip, path)) {
+			return filepath.SkipDir
+		}
+		if !slices.Contains(keep, path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, dir := range slices.Backward(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(dir); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to remove empty folder %s: %v", dir, err)
+			continue
+		}
+		logging.LogDebug(logging.KeyApp, "removed empty folder %s", dir)
+		removed++
+	}
+
+	if removed > 0 {
+		logging.LogInfo(logging.KeyApp, "pruned %d empty folders", removed)
+	}
+	return removed, nil
+}
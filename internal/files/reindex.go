@@ -0,0 +1,173 @@
+// Package files - incremental metadata reindex of the files changed since the last run
+package files
+
+import (
+	"os"
+	"time"
+
+	"knov/internal/cacheStorage"
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/metadataStorage"
+	"knov/internal/pathutils"
+)
+
+// CacheKeyLastReindex holds the start time of the last incremental reindex (RFC 3339).
+const CacheKeyLastReindex CacheKey = "last_reindex"
+
+// GetLastReindexTime returns the start time of the last incremental reindex, or the zero
+// time when none ran yet (or the cache was flushed since).
+func GetLastReindexTime() time.Time {
+	data, err := cacheStorage.Get(string(CacheKeyLastReindex))
+	if err != nil || data == nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(data))
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "invalid last reindex time %q: %v", data, err)
+		return time.Time{}
+	}
+	return t
+}
+
+// SetLastReindexTime persists the start time of an incremental reindex.
+func SetLastReindexTime(t time.Time) error {
+	return cacheStorage.Set(string(CacheKeyLastReindex), []byte(t.Format(time.RFC3339Nano)))
+}
+
+// reindexSlack is how much later than LastEdited a file may be modified and still count
+// as indexed: the yaml metadata backend writes the file right after setting LastEdited.
+const reindexSlack = time.Second
+
+// modifiedSince reports whether the file of a metadata path was modified after t and after
+// its metadata was last saved, so files saved through knov aren't reindexed again.
+func modifiedSince(path string, t time.Time) bool {
+	info, err := os.Stat(metadataFullPath(path))
+	if err != nil || !info.ModTime().After(t) {
+		return false
+	}
+	metadata, err := MetaDataGet(path)
+	if err != nil || metadata == nil {
+		return true
+	}
+	return info.ModTime().After(metadata.LastEdited.Add(reindexSlack))
+}
+
+// ReindexChangedSince updates the metadata of the docs and media files modified after t
+// and of files without metadata, and deletes the metadata of files that no longer exist.
+// Files modified after t are only reindexed when they changed after their LastEdited too;
+// the others are only stat'ed, not read. The zero time only initializes missing
+// metadata and purges deleted files: re-saving every file would reset their LastEdited.
+// The caches are refreshed when anything changed.
+func ReindexChangedSince(t time.Time) error {
+	changed, err := ReindexChangedSinceNoRefresh(logging.KeyApp, t)
+	if err != nil {
+		return err
+	}
+	if changed > 0 {
+		RefreshCaches()
+	}
+	return nil
+}
+
+// ReindexChangedSinceNoRefresh is ReindexChangedSince without the cache refresh, for
+// callers that rebuild the caches themselves. Returns the number of updated and deleted
+// files.
+func ReindexChangedSinceNoRefresh(key logging.Key, t time.Time) (int, error) {
+	docs, err := contentStorage.ListFiles()
+	if err != nil {
+		return 0, err
+	}
+	paths := make([]string, 0, len(docs))
+	for _, path := range docs {
+		paths = append(paths, pathutils.ToWithPrefix(path))
+	}
+	media, err := contentStorage.ListMediaFiles()
+	if err != nil {
+		// without the media list every media metadata entry would look deleted
+		return 0, err
+	}
+	for _, path := range media {
+		paths = append(paths, pathutils.ToWithPrefix("media/"+path))
+	}
+
+	updated := 0
+	existing := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		existing[path] = true
+
+		if metadataStored(path) && (t.IsZero() || !modifiedSince(path, t)) {
+			continue
+		}
+
+		if err := MetaDataSaveNoRefresh(&Metadata{Path: path}); err != nil {
+			logging.LogError(key, "reindex: failed to save metadata for %s: %v", path, err)
+			continue
+		}
+		logging.LogDebug(key, "reindex: updated metadata for %s", path)
+		updated++
+	}
+
+	all, err := metadataStorage.GetAll()
+	if err != nil {
+		return updated, err
+	}
+	deleted := 0
+	for storageKey := range all {
+		path := metadataKeyPath(storageKey)
+		if existing[pathutils.ToWithPrefix(path)] {
+			continue
+		}
+		if err := MetaDataDeleteNoRefresh(key, path); err != nil {
+			logging.LogError(key, "reindex: failed to delete metadata for %s: %v", path, err)
+			continue
+		}
+		logging.LogDebug(key, "reindex: deleted metadata for %s", path)
+		deleted++
+	}
+
+	if updated > 0 || deleted > 0 {
+		logging.LogInfo(key, "reindex: updated %d and deleted %d files changed since %s", updated, deleted, t.Format(time.RFC3339))
+	}
+	return updated + deleted, nil
+}
+
+// ReindexPaths updates the metadata of the given docs and media paths like
+// ReindexChangedSince, without listing every file: an existing file is reindexed when it has
+// no metadata or changed after its metadata was last saved, the metadata of a removed file
+// is deleted. The caches are refreshed when anything changed. Returns the number of updated
+// and deleted files.
+func ReindexPaths(key logging.Key, paths []string) int {
+	changed := 0
+	for _, path := range paths {
+		path = pathutils.ToWithPrefix(path)
+		info, err := os.Stat(metadataFullPath(path))
+		switch {
+		case err != nil:
+			if !metadataStored(path) {
+				continue
+			}
+			if err := MetaDataDeleteNoRefresh(key, path); err != nil {
+				logging.LogError(key, "reindex: failed to delete metadata for %s: %v", path, err)
+				continue
+			}
+			logging.LogDebug(key, "reindex: deleted metadata for %s", path)
+		case info.IsDir():
+			continue
+		default:
+			if metadataStored(path) && !modifiedSince(path, time.Time{}) {
+				continue
+			}
+			if err := MetaDataSaveNoRefresh(&Metadata{Path: path}); err != nil {
+				logging.LogError(key, "reindex: failed to save metadata for %s: %v", path, err)
+				continue
+			}
+			logging.LogDebug(key, "reindex: updated metadata for %s", path)
+		}
+		changed++
+	}
+	if changed > 0 {
+		RefreshCaches()
+	}
+	return changed
+}
@@ -0,0 +1,32 @@
+// Package files - initial content scaffolds for new files
+package files
+
+import (
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// NewFileScaffold returns the initial content for a new file of editor type, read from the
+// template configured for it with {{date}} replaced by today's date. An empty editor means
+// the default markdown editor. Returns "" when no template is configured or it can't be read.
+func NewFileScaffold(editor EditorType) string {
+	if editor == "" {
+		editor = EditorType(configmanager.GetDefaultMarkdownEditor())
+	}
+	tpl := configmanager.GetNewFileTemplate(string(editor))
+	if tpl == "" {
+		return ""
+	}
+
+	content, err := contentStorage.ReadFile(pathutils.ToDocsPath(tpl))
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to read new file template %s: %v", tpl, err)
+		return ""
+	}
+	return strings.ReplaceAll(string(content), "{{date}}", time.Now().Format(journalDateLayout))
+}
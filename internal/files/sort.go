@@ -0,0 +1,83 @@
+// Package files - sort order for file listings
+package files
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+)
+
+// sort keys for SortFiles, matching the fileListSort setting options
+const (
+	SortByPath       = "path"
+	SortByTitle      = "title"
+	SortByCreatedAt  = "createdAt"
+	SortByLastEdited = "lastEdited"
+	SortBySize       = "size"
+	SortByWordCount  = "wordCount"
+)
+
+// SortKeys returns the sort keys SortFiles understands.
+func SortKeys() []string {
+	return []string{SortByPath, SortByTitle, SortByCreatedAt, SortByLastEdited, SortBySize, SortByWordCount}
+}
+
+// SortFilesDefault sorts fileList in place by the configured default file list sort and order.
+// Used wherever files are listed without an explicit sort (file list, browse, filter results).
+func SortFilesDefault(fileList []File) {
+	SortFiles(fileList, configmanager.GetFileListSort(), configmanager.GetFileListOrder())
+}
+
+// SortFiles sorts fileList in place by sortBy ("path", "title", "createdAt", "lastEdited",
+// "size" or "wordCount") in order "asc" or "desc". Ties and unknown keys fall back to the path, so
+// the result is deterministic. Files without metadata sort as zero values.
+func SortFiles(fileList []File, sortBy, order string) {
+	desc := order == "desc"
+	slices.SortStableFunc(fileList, func(a, b File) int {
+		c := compareFiles(a, b, sortBy)
+		if c == 0 {
+			c = strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+		}
+		if desc {
+			return -c
+		}
+		return c
+	})
+}
+
+// compareFiles compares two files by a single sort key.
+func compareFiles(a, b File, sortBy string) int {
+	ma, mb := sortMetadata(a), sortMetadata(b)
+	switch sortBy {
+	case SortByTitle:
+		return strings.Compare(strings.ToLower(fileTitle(a)), strings.ToLower(fileTitle(b)))
+	case SortByCreatedAt:
+		return ma.CreatedAt.Compare(mb.CreatedAt)
+	case SortByLastEdited:
+		return ma.LastEdited.Compare(mb.LastEdited)
+	case SortBySize:
+		return cmp.Compare(ma.Size, mb.Size)
+	case SortByWordCount:
+		return cmp.Compare(ma.WordCount, mb.WordCount)
+	}
+	return 0
+}
+
+// sortMetadata returns the metadata of a file, or an empty one for files without metadata.
+func sortMetadata(f File) *Metadata {
+	if f.Metadata == nil {
+		return &Metadata{}
+	}
+	return f.Metadata
+}
+
+// fileTitle returns the metadata title of a file, falling back to its filename.
+func fileTitle(f File) string {
+	if f.Metadata != nil && f.Metadata.Title != "" {
+		return f.Metadata.Title
+	}
+	return strings.TrimSuffix(filepath.Base(f.Path), filepath.Ext(f.Path))
+}
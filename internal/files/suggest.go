@@ -0,0 +1,148 @@
+// Package files - tag and collection suggestions from the most content-similar notes
+package files
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// maxSuggestions caps how many tags and collections SuggestMetadata returns.
+const maxSuggestions = 5
+
+// suggestStopWords are frequent words that say nothing about the topic of a note.
+var suggestStopWords = []string{
+	"the", "and", "for", "are", "but", "not", "you", "all", "any", "can", "has", "have", "had",
+	"was", "were", "this", "that", "these", "those", "with", "from", "into", "onto", "its", "our",
+	"your", "they", "them", "their", "there", "then", "than", "what", "which", "when", "where",
+	"who", "how", "why", "will", "would", "should", "could", "about", "also", "just", "only",
+	"some", "such", "very", "more", "most", "other", "each", "been", "being", "does", "did",
+	"der", "die", "das", "und", "ist", "ein", "eine", "nicht", "mit", "von", "für", "auf", "den",
+}
+
+// Suggestion is a suggested tag or collection. Score is the share of the similarity of the
+// matched notes carrying it, between 0 and 1.
+type Suggestion struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// Suggestions are the tags and collections suggested for a note's content, best first, and
+// the notes they were taken from.
+type Suggestions struct {
+	Tags        []Suggestion `json:"tags"`
+	Collections []Suggestion `json:"collections"`
+	Similar     []string     `json:"similar"`
+}
+
+// SuggestMetadata suggests tags and a collection for content from the notes most similar to
+// it. Notes are compared by the cosine similarity of their term frequencies, the
+// suggestSimilarNotes setting most similar ones vote for their tags and collection with their
+// similarity. Kanban status tags and private collections are left out.
+func SuggestMetadata(content string) (*Suggestions, error) {
+	suggestions := &Suggestions{Tags: []Suggestion{}, Collections: []Suggestion{}, Similar: []string{}}
+	limit := configmanager.GetSuggestSimilarNotes()
+	terms := termFrequencies(content)
+	if limit == 0 || len(terms) == 0 {
+		return suggestions, nil
+	}
+
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		metadata   *Metadata
+		similarity float64
+	}
+	var matches []match
+	for _, file := range allFiles {
+		metadata := file.Metadata
+		if metadata == nil || !pathutils.IsDocs(file.Path) || IsPrivate(file.Path) {
+			continue
+		}
+		if len(metadata.Tags) == 0 && metadata.Collection == "" {
+			continue
+		}
+		data, err := os.ReadFile(pathutils.ToFullPath(file.Path))
+		if err != nil {
+			logging.LogDebug(logging.KeyApp, "skipping %s for metadata suggestions: %v", file.Path, err)
+			continue
+		}
+		if similarity := cosineSimilarity(terms, termFrequencies(string(data))); similarity > 0 {
+			matches = append(matches, match{metadata, similarity})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return cmp.Compare(b.similarity, a.similarity) })
+	matches = matches[:min(limit, len(matches))]
+
+	var total float64
+	tagScores := map[string]float64{}
+	collectionScores := map[string]float64{}
+	for _, m := range matches {
+		total += m.similarity
+		suggestions.Similar = append(suggestions.Similar, m.metadata.Path)
+		for _, tag := range m.metadata.Tags {
+			if !configmanager.IsKanbanTag(tag) {
+				tagScores[tag] += m.similarity
+			}
+		}
+		if m.metadata.Collection != "" {
+			collectionScores[m.metadata.Collection] += m.similarity
+		}
+	}
+	suggestions.Tags = rankSuggestions(tagScores, total)
+	suggestions.Collections = rankSuggestions(collectionScores, total)
+	return suggestions, nil
+}
+
+// rankSuggestions turns the summed similarities into the best maxSuggestions suggestions,
+// ties ordered by name.
+func rankSuggestions(scores map[string]float64, total float64) []Suggestion {
+	ranked := make([]Suggestion, 0, len(scores))
+	for _, name := range slices.Sorted(maps.Keys(scores)) {
+		ranked = append(ranked, Suggestion{Name: name, Score: math.Round(scores[name]/total*100) / 100})
+	}
+	slices.SortStableFunc(ranked, func(a, b Suggestion) int { return cmp.Compare(b.Score, a.Score) })
+	return ranked[:min(maxSuggestions, len(ranked))]
+}
+
+// termFrequencies counts the lowercased words of at least three letters or digits in text,
+// without stop words.
+func termFrequencies(text string) map[string]int {
+	terms := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len([]rune(word)) >= 3 && !slices.Contains(suggestStopWords, word) {
+			terms[word]++
+		}
+	}
+	return terms
+}
+
+// cosineSimilarity returns the cosine of the angle between two term frequency vectors.
+func cosineSimilarity(a, b map[string]int) float64 {
+	var dot, normA, normB float64
+	for term, count := range a {
+		normA += float64(count * count)
+		dot += float64(count * b[term])
+	}
+	for _, count := range b {
+		normB += float64(count * count)
+	}
+	if dot == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
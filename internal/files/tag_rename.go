@@ -0,0 +1,96 @@
+// Package files - renaming and merging of tags across all files
+package files
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+)
+
+// RenameTag replaces the tag oldName with newName in the metadata of every file and
+// returns the number of files touched. Files already carrying newName keep a single copy,
+// so renaming onto an existing tag merges the two. Metadata is saved raw, without the
+// link and parent processing of MetaDataSave, and the tag caches are invalidated once.
+func RenameTag(oldName, newName string) (int, error) {
+	affected, err := renameTag(oldName, newName, false)
+	if err != nil {
+		return 0, err
+	}
+	return len(affected), nil
+}
+
+// RenameTagPreview returns the metadata paths of the files RenameTag would touch,
+// without changing anything.
+func RenameTagPreview(oldName, newName string) ([]string, error) {
+	return renameTag(oldName, newName, true)
+}
+
+// renameTag renames the tag in every file carrying it, or only collects them on a dry run.
+func renameTag(oldName, newName string, dryRun bool) ([]string, error) {
+	oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+	if oldName == "" || newName == "" {
+		return nil, fmt.Errorf("old and new tag name are required")
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("old and new tag name are the same")
+	}
+	// kanban status tags are validated against the configured statuses, so they are moved on the board
+	prefixDash := configmanager.GetKanbanPrefix() + "-"
+	if strings.HasPrefix(oldName, prefixDash) || strings.HasPrefix(newName, prefixDash) {
+		return nil, fmt.Errorf("kanban tags (%s*) can't be renamed", prefixDash)
+	}
+
+	allFiles, err := GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+
+	affected := []string{}
+	for _, file := range allFiles {
+		if file.Metadata == nil || !slices.Contains(file.Metadata.Tags, oldName) {
+			continue
+		}
+		if dryRun {
+			affected = append(affected, file.Metadata.Path)
+			continue
+		}
+
+		// re-read so the raw save doesn't write back a stale cached copy
+		metadata, err := MetaDataGet(file.Metadata.Path)
+		if err != nil || metadata == nil || !slices.Contains(metadata.Tags, oldName) {
+			continue
+		}
+		metadata.Tags = replaceTag(metadata.Tags, oldName, newName)
+		if err := MetaDataSaveRaw(metadata); err != nil {
+			logging.LogWarning(logging.KeyApp, "rename tag: failed to save %s: %v", metadata.Path, err)
+			continue
+		}
+		affected = append(affected, metadata.Path)
+	}
+	slices.Sort(affected)
+
+	if !dryRun && len(affected) > 0 {
+		InvalidateTagCache()
+		RefreshCaches()
+		logging.LogInfo(logging.KeyApp, "renamed tag %s to %s in %d files", oldName, newName, len(affected))
+	}
+	return affected, nil
+}
+
+// replaceTag returns tags with oldName replaced by newName, keeping the order and
+// dropping the duplicate when newName was already present.
+func replaceTag(tags []string, oldName, newName string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == oldName {
+			tag = newName
+		}
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
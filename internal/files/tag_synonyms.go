@@ -0,0 +1,26 @@
+// Package files - near-duplicate tag detection based on the configured tag synonyms
+package files
+
+import (
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+)
+
+// TagSynonymConflicts returns the configured synonym pairs whose tags are both in tags
+// (case-insensitive). Synonyms are only reported, never removed.
+func TagSynonymConflicts(tags []string) [][2]string {
+	lower := make([]string, len(tags))
+	for i, tag := range tags {
+		lower[i] = strings.ToLower(tag)
+	}
+
+	var conflicts [][2]string
+	for _, pair := range configmanager.GetTagSynonyms() {
+		if slices.Contains(lower, pair[0]) && slices.Contains(lower, pair[1]) {
+			conflicts = append(conflicts, pair)
+		}
+	}
+	return conflicts
+}
@@ -2,10 +2,14 @@
 package filter
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -27,12 +31,36 @@ type Criteria struct {
 	Action   string `json:"action"`
 }
 
-// Config represents filter configuration
+// Config represents filter configuration.
+// An empty Criteria list is "no filter" and matches every visible file; a config with only
+// exclude criteria matches every visible file none of them excludes. Offset skips that many
+// results (negative is treated as 0) and Limit <= 0 means no limit, though the global filter
+// result cap always applies. An empty SortBy or SortOrder uses the default file list sort.
+// GroupBy buckets the results of the list display under a header per value (see GroupFiles).
+// CriteriaGroups nest parenthesized criteria groups, see CriteriaGroup.
 type Config struct {
+	Criteria       []Criteria      `json:"criteria"`
+	CriteriaGroups []CriteriaGroup `json:"criteriaGroups,omitempty"`
+	Logic          string          `json:"logic"`
+	Display        string          `json:"display"` // one of GetDisplayModes, empty means list
+	Limit          int             `json:"limit"`
+	Offset         int             `json:"offset,omitempty"`
+	SortBy         string          `json:"sortBy,omitempty"`    // one of files.SortKeys
+	SortOrder      string          `json:"sortOrder,omitempty"` // asc or desc
+	GroupBy        string          `json:"groupBy,omitempty"`   // one of GetGroupByFields or custom.<key>
+	Columns        []string        `json:"columns,omitempty"`   // table display columns, empty uses the filterTableColumns setting
+}
+
+// CriteriaGroup is a parenthesized group of criteria. Its criteria are matched like a flat
+// config's (joined by the group's Logic, its exclude criteria always excluding) and an
+// exclude group negates the result. Once a config has groups, its flat Criteria form one
+// more include group and all groups are joined by the config's Logic, so
+// "(a or b) and not (c and d)" is an "or" include group and an "and" exclude group under
+// "and" logic.
+type CriteriaGroup struct {
 	Criteria []Criteria `json:"criteria"`
 	Logic    string     `json:"logic"`
-	Display  string     `json:"display"` // list, cards, dropdown, content
-	Limit    int        `json:"limit"`
+	Action   string     `json:"action"` // include or exclude, empty means include
 }
 
 // Result represents filter result with metadata
@@ -41,32 +69,110 @@ type Result struct {
 	Total       int          `json:"total"`
 	FilterCount int          `json:"filter_count"`
 	Logic       string       `json:"logic"`
+	Offset      int          `json:"offset"`           // number of matching files skipped before Files
+	Truncated   bool         `json:"truncated"`        // more files matched than the configured filter result cap allows
+	Groups      []Group      `json:"groups,omitempty"` // Files grouped by the config's GroupBy field, if set
 }
 
-// FilterFiles filters files based on criteria
+// FilterFiles filters files based on criteria. Empty criteria return all visible files,
+// exclude-only criteria all visible files that aren't excluded (see Config).
+// Results are in the configured default file list sort. An invalid regex pattern is an error.
 func FilterFiles(criteria []Criteria, logic string) ([]files.File, error) {
-	allFiles, err := files.GetAllFilesCached()
+	return filterFiles(criteria, logic, nil)
+}
+
+// filterFiles filters files on flat criteria and criteria groups, see Config.
+func filterFiles(criteria []Criteria, logic string, groups []CriteriaGroup) ([]files.File, error) {
+	var filteredFiles []files.File
+	err := eachMatch(criteria, logic, groups, func(file files.File) {
+		filteredFiles = append(filteredFiles, file)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	files.SortFilesDefault(filteredFiles)
+	return filteredFiles, nil
+}
+
+// eachMatch calls visit for every visible file matching the flat criteria and criteria
+// groups, in file list order.
+func eachMatch(criteria []Criteria, logic string, groups []CriteriaGroup, visit func(files.File)) error {
+	allCriteria := slices.Clone(criteria)
+	for _, group := range groups {
+		allCriteria = append(allCriteria, group.Criteria...)
+	}
+	patterns, err := compileRegexCriteria(allCriteria)
+	if err != nil {
+		return err
+	}
+
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return err
+	}
+
 	allFiles = files.FilterByVisibility(allFiles)
 
-	if len(criteria) == 0 {
-		return allFiles, nil
+	if len(allCriteria) == 0 {
+		for _, file := range allFiles {
+			visit(file)
+		}
+		return nil
 	}
 
-	var filteredFiles []files.File
+	// exclude groups can't match a file without metadata, so only include criteria count here
+	excludeOnly := !hasIncludeCriteria(criteria) && !slices.ContainsFunc(groups, func(g CriteriaGroup) bool {
+		return g.Action != "exclude" && hasIncludeCriteria(g.Criteria)
+	})
+
 	for _, file := range allFiles {
 		if file.Metadata == nil { // already loaded by GetAllFiles
+			// nothing to exclude a file without metadata on, so only include criteria drop it
+			if excludeOnly {
+				visit(file)
+			}
 			continue
 		}
-		if matchesFilter(file.Metadata, criteria, logic) {
-			filteredFiles = append(filteredFiles, file)
+		if matchesGroups(file.Metadata, criteria, logic, groups, patterns) {
+			visit(file)
 		}
 	}
+	return nil
+}
 
-	return filteredFiles, nil
+// hasIncludeCriteria reports whether criteria has a criterion that isn't an exclude.
+func hasIncludeCriteria(criteria []Criteria) bool {
+	return slices.ContainsFunc(criteria, func(c Criteria) bool { return c.Action != "exclude" })
+}
+
+// matchesGroups reports whether metadata matches the flat criteria and criteria groups of
+// a config. Without groups this is matchesFilter on the flat criteria; with groups the flat
+// criteria are one more include group and the group results are joined by logic.
+func matchesGroups(metadata *files.Metadata, criteria []Criteria, logic string, groups []CriteriaGroup, patterns regexCache) bool {
+	if len(groups) == 0 {
+		return matchesFilter(metadata, criteria, logic, patterns)
+	}
+
+	var results []bool
+	if len(criteria) > 0 {
+		results = append(results, matchesFilter(metadata, criteria, logic, patterns))
+	}
+	for _, group := range groups {
+		if len(group.Criteria) == 0 {
+			continue
+		}
+		matched := matchesFilter(metadata, group.Criteria, group.Logic, patterns)
+		results = append(results, matched != (group.Action == "exclude"))
+	}
+	if len(results) == 0 {
+		return true
+	}
+
+	if logic == "or" {
+		return slices.Contains(results, true)
+	}
+	return !slices.Contains(results, false)
 }
 
 // FilterFilesWithConfig filters files using config and returns result
@@ -75,27 +181,144 @@ func FilterFilesWithConfig(config *Config) (*Result, error) {
 		return nil, fmt.Errorf("filter config is required")
 	}
 
-	filteredFiles, err := FilterFiles(config.Criteria, config.Logic)
+	filteredFiles, err := filterFiles(config.Criteria, config.Logic, config.CriteriaGroups)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.SortBy != "" || config.SortOrder != "" {
+		files.SortFiles(filteredFiles, cmp.Or(config.SortBy, configmanager.GetFileListSort()), cmp.Or(config.SortOrder, configmanager.GetFileListOrder()))
+	}
+
 	total := len(filteredFiles)
+	offset := max(config.Offset, 0)
+	filteredFiles = paginate(filteredFiles, offset, config.Limit)
 
-	// Apply limit
-	if config.Limit > 0 && len(filteredFiles) > config.Limit {
-		filteredFiles = filteredFiles[:config.Limit]
+	// Apply the global safety cap on top of the filter's own limit
+	truncated := false
+	if resultCap := configmanager.GetFilterResultCap(); len(filteredFiles) > resultCap {
+		filteredFiles = filteredFiles[:resultCap]
+		truncated = true
+		logging.LogDebug(logging.KeyApp, "filter result truncated to %d of %d files", resultCap, total)
 	}
 
-	return &Result{
+	result := &Result{
 		Files:       filteredFiles,
 		Total:       total,
 		FilterCount: len(config.Criteria),
 		Logic:       config.Logic,
-	}, nil
+		Offset:      offset,
+		Truncated:   truncated,
+	}
+	if config.GroupBy != "" {
+		result.Groups = GroupFiles(filteredFiles, config.GroupBy)
+	}
+	return result, nil
 }
 
-func matchesFilter(metadata *files.Metadata, criteria []Criteria, logic string) bool {
+// CountFilesWithConfig returns the number of files matching config, the Total of
+// FilterFilesWithConfig, without collecting, sorting or paginating them. For callers that
+// only show the count; sort, pagination and grouping settings are ignored.
+func CountFilesWithConfig(config *Config) (int, error) {
+	if config == nil {
+		return 0, fmt.Errorf("filter config is required")
+	}
+
+	count := 0
+	err := eachMatch(config.Criteria, config.Logic, config.CriteriaGroups, func(files.File) {
+		count++
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// paginate returns the page of matched files starting at offset with at most limit
+// entries; limit <= 0 returns everything from offset on.
+func paginate(matched []files.File, offset, limit int) []files.File {
+	if offset >= len(matched) {
+		return nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched
+}
+
+// TagByFilter merges tags into the metadata of every file matching criteria and
+// returns the number of matched files. Files that already carry all tags are left
+// untouched; the aggregate caches are refreshed once at the end.
+func TagByFilter(criteria []Criteria, logic string, tags []string) (int, error) {
+	matched, err := FilterFiles(criteria, logic)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := false
+	for _, file := range matched {
+		merged := slices.Clone(file.Metadata.Tags)
+		for _, tag := range tags {
+			if tag != "" && !slices.Contains(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+		if len(merged) == len(file.Metadata.Tags) {
+			continue
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: file.Metadata.Path, Tags: merged}); err != nil {
+			logging.LogWarning(logging.KeyApp, "tag by filter: failed to save %s: %v", file.Metadata.Path, err)
+			continue
+		}
+		changed = true
+	}
+	if changed {
+		files.RefreshCaches()
+	}
+
+	logging.LogInfo(logging.KeyApp, "tagged %d files matching filter with %v", len(matched), tags)
+	return len(matched), nil
+}
+
+// SetTargetDateByFilter sets the target date custom field of every file matching criteria
+// and returns the number of matched files. An empty date clears the field. Files already
+// carrying the date are left untouched; the aggregate caches are refreshed once at the end.
+func SetTargetDateByFilter(criteria []Criteria, logic string, date string) (int, error) {
+	matched, err := FilterFiles(criteria, logic)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := false
+	for _, file := range matched {
+		if file.Metadata.Custom[files.TargetDateField] == date {
+			continue
+		}
+		custom := make(map[string]string, len(file.Metadata.Custom)+1)
+		for k, v := range file.Metadata.Custom {
+			custom[k] = v
+		}
+		if date == "" {
+			delete(custom, files.TargetDateField)
+		} else {
+			custom[files.TargetDateField] = date
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: file.Metadata.Path, Custom: custom}); err != nil {
+			logging.LogWarning(logging.KeyApp, "target date by filter: failed to save %s: %v", file.Metadata.Path, err)
+			continue
+		}
+		changed = true
+	}
+	if changed {
+		files.RefreshCaches()
+	}
+
+	logging.LogInfo(logging.KeyApp, "set target date %q on %d files matching filter", date, len(matched))
+	return len(matched), nil
+}
+
+func matchesFilter(metadata *files.Metadata, criteria []Criteria, logic string, patterns regexCache) bool {
 	if len(criteria) == 0 {
 		return true
 	}
@@ -113,7 +336,7 @@ func matchesFilter(metadata *files.Metadata, criteria []Criteria, logic string)
 
 	// check exclude criteria first
 	for _, criterion := range excludeCriteria {
-		if matchesCriteria(metadata, criterion) {
+		if matchesCriteria(metadata, criterion, patterns) {
 			return false
 		}
 	}
@@ -126,14 +349,14 @@ func matchesFilter(metadata *files.Metadata, criteria []Criteria, logic string)
 	// check include criteria
 	if logic == "or" {
 		for _, criterion := range includeCriteria {
-			if matchesCriteria(metadata, criterion) {
+			if matchesCriteria(metadata, criterion, patterns) {
 				return true
 			}
 		}
 		return false
 	} else { // AND logic
 		for _, criterion := range includeCriteria {
-			if !matchesCriteria(metadata, criterion) {
+			if !matchesCriteria(metadata, criterion, patterns) {
 				return false
 			}
 		}
@@ -141,10 +364,19 @@ func matchesFilter(metadata *files.Metadata, criteria []Criteria, logic string)
 	}
 }
 
-func matchesCriteria(metadata *files.Metadata, criterion Criteria) bool {
+func matchesCriteria(metadata *files.Metadata, criterion Criteria, patterns regexCache) bool {
 	var metadataValue string
 
+	// custom.<key> matches against the file's custom key/value metadata
+	if key, ok := customFieldKey(criterion.Metadata); ok {
+		return matchesOperator(metadata.Custom[key], criterion.Operator, criterion.Value, patterns)
+	}
+
 	switch criterion.Metadata {
+	case "wordCount":
+		return matchesNumber(metadata.WordCount, criterion.Operator, criterion.Value)
+	case "readingTimeMinutes":
+		return matchesNumber(metadata.ReadingTimeMinutes, criterion.Operator, criterion.Value)
 	case "title":
 		metadataValue = metadata.Title
 		if metadataValue == "" {
@@ -154,13 +386,15 @@ func matchesCriteria(metadata *files.Metadata, criterion Criteria) bool {
 		metadataValue = metadata.Collection
 	case "tags":
 		for _, tag := range metadata.Tags {
-			if matchesOperator(tag, criterion.Operator, criterion.Value) {
+			if matchesOperator(tag, criterion.Operator, criterion.Value, patterns) {
 				return true
 			}
 		}
 		return false
 	case "editor":
 		metadataValue = string(metadata.Editor)
+	case "label":
+		metadataValue = metadata.Label
 	case "createdAt":
 		metadataValue = metadata.CreatedAt.Format("2006-01-02")
 	case "lastEdited":
@@ -174,39 +408,42 @@ func matchesCriteria(metadata *files.Metadata, criterion Criteria) bool {
 			metadataValue = metadata.KanbanMovedAt.Format("2006-01-02")
 		}
 	case "folders":
+		if criterion.Operator == "under" {
+			return isUnderFolder(metadata.Path, criterion.Value)
+		}
 		for _, folder := range metadata.Folders {
-			if matchesOperator(folder, criterion.Operator, criterion.Value) {
+			if matchesOperator(folder, criterion.Operator, criterion.Value, patterns) {
 				return true
 			}
 		}
 		return false
 	case "child-of":
 		for _, p := range metadata.Parents {
-			if matchesOperator(pathutils.ToRelative(p), criterion.Operator, pathutils.ToRelative(criterion.Value)) {
+			if matchesOperator(pathutils.ToRelative(p), criterion.Operator, pathutils.ToRelative(criterion.Value), patterns) {
 				return true
 			}
 		}
 		return false
 	case "parent-of":
 		for _, k := range metadata.Kids {
-			if matchesOperator(pathutils.ToRelative(k), criterion.Operator, pathutils.ToRelative(criterion.Value)) {
+			if matchesOperator(pathutils.ToRelative(k), criterion.Operator, pathutils.ToRelative(criterion.Value), patterns) {
 				return true
 			}
 		}
 		return false
 	case "ancestor-of":
 		for _, a := range metadata.Ancestor {
-			if matchesOperator(pathutils.ToRelative(a), criterion.Operator, pathutils.ToRelative(criterion.Value)) {
+			if matchesOperator(pathutils.ToRelative(a), criterion.Operator, pathutils.ToRelative(criterion.Value), patterns) {
 				return true
 			}
 		}
 		return false
 	case "references":
 		for _, ref := range metadata.References {
-			if matchesOperator(ref.URL, criterion.Operator, criterion.Value) {
+			if matchesOperator(ref.URL, criterion.Operator, criterion.Value, patterns) {
 				return true
 			}
-			if matchesOperator(ref.Description, criterion.Operator, criterion.Value) {
+			if matchesOperator(ref.Description, criterion.Operator, criterion.Value, patterns) {
 				return true
 			}
 		}
@@ -215,22 +452,57 @@ func matchesCriteria(metadata *files.Metadata, criterion Criteria) bool {
 		return false
 	}
 
-	return matchesOperator(metadataValue, criterion.Operator, criterion.Value)
+	return matchesOperator(metadataValue, criterion.Operator, criterion.Value, patterns)
 }
 
-func matchesOperator(metadataValue, operator, criteriaValue string) bool {
+// customFieldKey returns the key of a "custom.<key>" filter field.
+func customFieldKey(field string) (string, bool) {
+	key, ok := strings.CutPrefix(field, "custom.")
+	return key, ok && key != ""
+}
+
+// regexCache holds the compiled regex patterns of one filter run, so every pattern is
+// compiled once instead of once per file and value.
+type regexCache map[string]*regexp.Regexp
+
+// compileRegexCriteria compiles the patterns of all regex criteria, failing on the first invalid one.
+func compileRegexCriteria(criteria []Criteria) (regexCache, error) {
+	patterns := regexCache{}
+	for _, criterion := range criteria {
+		if criterion.Operator != "regex" {
+			continue
+		}
+		re, err := regexp.Compile(criterion.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q for %s: %v", criterion.Value, criterion.Metadata, err)
+		}
+		patterns[criterion.Value] = re
+	}
+	return patterns, nil
+}
+
+// match reports whether value matches pattern, compiling and caching patterns that were not
+// compiled up front (e.g. the relative form of a path pattern). An invalid pattern never matches.
+func (c regexCache) match(pattern, value string) bool {
+	re, ok := c[pattern]
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			logging.LogWarning(logging.KeyApp, "invalid regex pattern: %s", pattern)
+		}
+		c[pattern] = re
+	}
+	return re != nil && re.MatchString(value)
+}
+
+func matchesOperator(metadataValue, operator, criteriaValue string, patterns regexCache) bool {
 	switch operator {
 	case "equals":
 		return metadataValue == criteriaValue
 	case "contains":
 		return strings.Contains(strings.ToLower(metadataValue), strings.ToLower(criteriaValue))
 	case "regex":
-		matched, err := regexp.MatchString(criteriaValue, metadataValue)
-		if err != nil {
-			logging.LogWarning(logging.KeyApp, "invalid regex pattern: %s", criteriaValue)
-			return false
-		}
-		return matched
+		return patterns.match(criteriaValue, metadataValue)
 	case "greater":
 		// try date comparison first for date-like values
 		if len(metadataValue) == 10 && len(criteriaValue) == 10 &&
@@ -266,6 +538,43 @@ func matchesOperator(metadataValue, operator, criteriaValue string) bool {
 	}
 }
 
+// matchesNumber compares a numeric metadata field with equals, greater, less or in.
+func matchesNumber(value int, operator, criteriaValue string) bool {
+	if operator == "in" {
+		for _, v := range strings.Split(criteriaValue, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n == value {
+				return true
+			}
+		}
+		return false
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(criteriaValue))
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case "equals":
+		return value == n
+	case "greater":
+		return value > n
+	case "less":
+		return value < n
+	}
+	return false
+}
+
+// isUnderFolder reports whether the file at filePath lies in folder or one of its
+// subfolders. folder is a docs-relative folder path like "a/b"; an empty folder is the root.
+func isUnderFolder(filePath, folder string) bool {
+	folder = strings.Trim(filepath.ToSlash(strings.TrimSpace(folder)), "/")
+	if folder == "" {
+		return true
+	}
+	dir := filepath.ToSlash(filepath.Dir(pathutils.ToRelative(filePath)))
+	return dir == folder || strings.HasPrefix(dir, folder+"/")
+}
+
 // GetMetadataFields returns available metadata fields for filtering
 func GetMetadataFields() []string {
 	return []string{
@@ -273,10 +582,13 @@ func GetMetadataFields() []string {
 		"collection",
 		"tags",
 		"editor",
+		"label",
 		"createdAt",
 		"lastEdited",
 		"kanbanAddedAt",
 		"kanbanMovedAt",
+		"wordCount",
+		"readingTimeMinutes",
 		"folders",
 		"child-of",
 		"parent-of",
@@ -287,7 +599,12 @@ func GetMetadataFields() []string {
 
 // GetOperators returns available filter operators
 func GetOperators() []string {
-	return []string{"equals", "contains", "regex", "greater", "less", "in"}
+	return []string{"equals", "contains", "regex", "greater", "less", "in", "under"}
+}
+
+// GetLogics returns the ways filter criteria can be combined
+func GetLogics() []string {
+	return []string{"and", "or"}
 }
 
 // GetActions returns available filter actions
@@ -295,35 +612,238 @@ func GetActions() []string {
 	return []string{"include", "exclude"}
 }
 
+// GetDisplayModes returns the available filter result display modes
+func GetDisplayModes() []string {
+	return []string{"list", "list2", "list3", "list4", "cards", "dropdown", "content", "table", "count"}
+}
+
+// GetTableColumns returns the metadata fields the table display mode can show as columns,
+// besides custom fields ("custom.<key>").
+func GetTableColumns() []string {
+	return []string{"title", "path", "collection", "folders", "tags", "parents", "editor", "label", "createdAt", "lastEdited", "kanbanAddedAt", "kanbanMovedAt", "size", "wordCount", "readingTimeMinutes"}
+}
+
+// IsTableColumn reports whether field can be shown as a column of the table display mode.
+func IsTableColumn(field string) bool {
+	_, custom := customFieldKey(field)
+	return custom || slices.Contains(GetTableColumns(), field)
+}
+
+// TableColumns returns the columns the table display mode shows for configured, falling back
+// to the filterTableColumns setting. Fields IsTableColumn rejects are skipped.
+func TableColumns(configured []string) []string {
+	if len(configured) == 0 {
+		configured = configmanager.GetFilterTableColumns()
+	}
+	var columns []string
+	for _, column := range configured {
+		if IsTableColumn(column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// TableCellValue returns the value of column for file as plain text, "" when the file has no
+// metadata. Dates are formatted with the date time format setting.
+func TableCellValue(file files.File, column string) string {
+	if column == "path" {
+		return file.Path
+	}
+	metadata := file.Metadata
+	if metadata == nil {
+		return ""
+	}
+
+	formatDate := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return configmanager.FormatDateTime(t)
+	}
+	switch column {
+	case "title":
+		return metadata.Title
+	case "label":
+		return metadata.Label
+	case "collection":
+		return metadata.Collection
+	case "folders":
+		return strings.Join(metadata.Folders, "/")
+	case "tags":
+		return strings.Join(metadata.Tags, ", ")
+	case "parents":
+		return strings.Join(metadata.Parents, ", ")
+	case "editor":
+		return string(metadata.Editor)
+	case "createdAt":
+		return formatDate(metadata.CreatedAt)
+	case "lastEdited":
+		return formatDate(metadata.LastEdited)
+	case "kanbanAddedAt":
+		return formatDate(metadata.KanbanAddedAt)
+	case "kanbanMovedAt":
+		return formatDate(metadata.KanbanMovedAt)
+	case "size":
+		return strconv.FormatInt(metadata.Size, 10)
+	case "wordCount":
+		return strconv.Itoa(metadata.WordCount)
+	case "readingTimeMinutes":
+		return strconv.Itoa(metadata.ReadingTimeMinutes)
+	}
+	if key, ok := customFieldKey(column); ok {
+		return metadata.Custom[key]
+	}
+	return ""
+}
+
+// dateFields are the metadata fields matched as YYYY-MM-DD dates.
+var dateFields = []string{"createdAt", "lastEdited", "kanbanAddedAt", "kanbanMovedAt"}
+
+// numberFields are the metadata fields matched as whole numbers.
+var numberFields = []string{"wordCount", "readingTimeMinutes"}
+
+// GetOperatorsForField returns the operators supported for a metadata field. greater/less
+// compare dates as dates, numbers as numbers and every other field as strings (e.g. titles
+// alphabetically); under (folder path prefix, subfolders included) only applies to folders.
+func GetOperatorsForField(field string) []string {
+	if slices.Contains(numberFields, field) {
+		return []string{"equals", "greater", "less", "in"}
+	}
+	if field == "folders" {
+		return []string{"equals", "contains", "regex", "greater", "less", "in", "under"}
+	}
+	return []string{"equals", "contains", "regex", "greater", "less", "in"}
+}
+
+// CriterionValidation is the validation result of a single filter criterion.
+type CriterionValidation struct {
+	Index     int      `json:"index"`
+	Criterion Criteria `json:"criterion"`
+	Valid     bool     `json:"valid"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// ValidateCriterion checks that a criterion's field exists, its operator is supported for
+// that field, its action is known and its value can be parsed: regex values must compile
+// and date fields compared with equals/greater/less/in need YYYY-MM-DD dates, number
+// fields whole numbers.
+func ValidateCriterion(criterion Criteria) error {
+	if _, ok := customFieldKey(criterion.Metadata); !ok && !utils.Contains(GetMetadataFields(), criterion.Metadata) {
+		return fmt.Errorf("invalid metadata field: %s", criterion.Metadata)
+	}
+	if !utils.Contains(GetOperators(), criterion.Operator) {
+		return fmt.Errorf("invalid operator: %s", criterion.Operator)
+	}
+	if !slices.Contains(GetOperatorsForField(criterion.Metadata), criterion.Operator) {
+		return fmt.Errorf("operator %s is not supported for %s", criterion.Operator, criterion.Metadata)
+	}
+	if !utils.Contains(GetActions(), criterion.Action) {
+		return fmt.Errorf("invalid action: %s", criterion.Action)
+	}
+
+	switch {
+	case criterion.Operator == "regex":
+		if _, err := regexp.Compile(criterion.Value); err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	case slices.Contains(dateFields, criterion.Metadata) && criterion.Operator != "contains":
+		values := []string{criterion.Value}
+		if criterion.Operator == "in" {
+			values = strings.Split(criterion.Value, ",")
+		}
+		for _, v := range values {
+			if _, err := time.Parse("2006-01-02", strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("invalid date %q for %s, expected YYYY-MM-DD", strings.TrimSpace(v), criterion.Metadata)
+			}
+		}
+	case slices.Contains(numberFields, criterion.Metadata):
+		for _, v := range strings.Split(criterion.Value, ",") {
+			if _, err := strconv.Atoi(strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("invalid number %q for %s", strings.TrimSpace(v), criterion.Metadata)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateCriteria validates every criterion of config without running the filter.
+func ValidateCriteria(config *Config) []CriterionValidation {
+	results := make([]CriterionValidation, len(config.Criteria))
+	for i, criterion := range config.Criteria {
+		results[i] = CriterionValidation{Index: i, Criterion: criterion, Valid: true}
+		if err := ValidateCriterion(criterion); err != nil {
+			results[i].Valid = false
+			results[i].Error = err.Error()
+		}
+	}
+	return results
+}
+
 // ValidateConfig validates filter configuration
 func ValidateConfig(config *Config) error {
 	if config == nil {
 		return fmt.Errorf("config cannot be nil")
 	}
 
-	if config.Logic != "and" && config.Logic != "or" {
+	if !slices.Contains(GetLogics(), config.Logic) {
 		return fmt.Errorf("logic must be 'and' or 'or'")
 	}
 
-	validFields := GetMetadataFields()
-	validOperators := GetOperators()
-	validActions := GetActions()
+	if config.Display != "" && !slices.Contains(GetDisplayModes(), config.Display) {
+		return fmt.Errorf("invalid display mode: %s", config.Display)
+	}
 
-	for _, criteria := range config.Criteria {
-		if !utils.Contains(validFields, criteria.Metadata) {
-			return fmt.Errorf("invalid metadata field: %s", criteria.Metadata)
+	for _, column := range config.Columns {
+		if !IsTableColumn(column) {
+			return fmt.Errorf("invalid table column: %s", column)
 		}
-		if !utils.Contains(validOperators, criteria.Operator) {
-			return fmt.Errorf("invalid operator: %s", criteria.Operator)
+	}
+
+	if config.SortBy != "" && !slices.Contains(files.SortKeys(), config.SortBy) {
+		return fmt.Errorf("invalid sort field: %s", config.SortBy)
+	}
+	if config.SortOrder != "" && config.SortOrder != "asc" && config.SortOrder != "desc" {
+		return fmt.Errorf("sort order must be 'asc' or 'desc'")
+	}
+	if config.GroupBy != "" && !IsGroupByField(config.GroupBy) {
+		return fmt.Errorf("invalid group field: %s", config.GroupBy)
+	}
+
+	for _, criteria := range config.Criteria {
+		if err := ValidateCriterion(criteria); err != nil {
+			return err
 		}
-		if !utils.Contains(validActions, criteria.Action) {
-			return fmt.Errorf("invalid action: %s", criteria.Action)
+	}
+
+	for i, group := range config.CriteriaGroups {
+		if err := validateCriteriaGroup(group); err != nil {
+			return fmt.Errorf("criteria group %d: %w", i+1, err)
 		}
 	}
 
 	return nil
 }
 
+// validateCriteriaGroup checks a criteria group's logic, action and criteria.
+func validateCriteriaGroup(group CriteriaGroup) error {
+	if !slices.Contains(GetLogics(), group.Logic) {
+		return fmt.Errorf("logic must be 'and' or 'or'")
+	}
+	if group.Action != "" && !slices.Contains(GetActions(), group.Action) {
+		return fmt.Errorf("invalid action: %s", group.Action)
+	}
+	if len(group.Criteria) == 0 {
+		return fmt.Errorf("group has no criteria")
+	}
+	for _, criteria := range group.Criteria {
+		if err := ValidateCriterion(criteria); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // filterKey returns the configStorage key for a filter ID
 func filterKey(id string) string {
 	return "filter/" + id
@@ -471,17 +991,27 @@ func filterFieldName(widgetIndex int, name string) string {
 func ParseFilterConfigFromForm(r *http.Request, widgetIndex int) *Config {
 	logic := r.FormValue(filterFieldName(widgetIndex, "logic"))
 	if logic == "" {
-		logic = "and"
+		logic = configmanager.GetFilterDefaultLogic()
 	}
 	display := r.FormValue(filterFieldName(widgetIndex, "display"))
 	if display == "" {
 		display = "list"
 	}
+	var columns []string
+	for _, column := range strings.Split(r.FormValue(filterFieldName(widgetIndex, "columns")), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
 	limitStr := r.FormValue(filterFieldName(widgetIndex, "limit"))
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
 		limit = 50
 	}
+	offset, _ := strconv.Atoi(r.FormValue(filterFieldName(widgetIndex, "offset")))
+	sortBy := r.FormValue(filterFieldName(widgetIndex, "sortBy"))
+	sortOrder := r.FormValue(filterFieldName(widgetIndex, "sortOrder"))
+	groupBy := r.FormValue(filterFieldName(widgetIndex, "groupBy"))
 
 	formData := make(map[int]map[string]string)
 
@@ -582,8 +1112,10 @@ func ParseFilterConfigFromForm(r *http.Request, widgetIndex int) *Config {
 		}
 	}
 
+	// keep the form's row order so per-criterion results line up with the rows
 	var criteria []Criteria
-	for _, data := range formData {
+	for _, rowIdx := range slices.Sorted(maps.Keys(formData)) {
+		data := formData[rowIdx]
 		if data["metadata"] == "" || data["operator"] == "" || data["value"] == "" {
 			continue
 		}
@@ -600,5 +1132,5 @@ func ParseFilterConfigFromForm(r *http.Request, widgetIndex int) *Config {
 	}
 
 	logging.LogDebug(logging.KeyApp, "parsed %d filter criteria", len(criteria))
-	return &Config{Criteria: criteria, Logic: logic, Display: display, Limit: limit}
+	return &Config{Criteria: criteria, Logic: logic, Display: display, Limit: limit, Offset: max(offset, 0), SortBy: sortBy, SortOrder: sortOrder, GroupBy: groupBy, Columns: columns}
 }
@@ -0,0 +1,112 @@
+// Package filter - grouping of filter results by a metadata field
+package filter
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+)
+
+// Group is one bucket of grouped filter results: the files whose group field has Value.
+// Value is empty for the files without a value.
+type Group struct {
+	Value string       `json:"value"`
+	Files []files.File `json:"files"`
+}
+
+// GetGroupByFields returns the metadata fields filter results can be grouped by,
+// besides custom fields ("custom.<key>"). status is the kanban status.
+func GetGroupByFields() []string {
+	return []string{"collection", "editor", "label", "tags", "status"}
+}
+
+// IsGroupByField reports whether filter results can be grouped by field.
+func IsGroupByField(field string) bool {
+	_, custom := customFieldKey(field)
+	return custom || slices.Contains(GetGroupByFields(), field)
+}
+
+// GroupFiles buckets fileList by the values of the groupBy field. Files keep their order
+// within a group and a file with several values (tags) is listed in each of their groups.
+// Groups are sorted by value, kanban statuses in their configured order, and the group of
+// files without a value comes last.
+func GroupFiles(fileList []files.File, groupBy string) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, file := range fileList {
+		values := groupValues(file.Metadata, groupBy)
+		if len(values) == 0 {
+			values = []string{""}
+		}
+		for _, value := range values {
+			i, ok := index[value]
+			if !ok {
+				i = len(groups)
+				index[value] = i
+				groups = append(groups, Group{Value: value})
+			}
+			groups[i].Files = append(groups[i].Files, file)
+		}
+	}
+
+	statuses := configmanager.GetKanbanStatuses()
+	slices.SortFunc(groups, func(a, b Group) int {
+		if a.Value == "" || b.Value == "" {
+			return cmp.Compare(b.Value, a.Value) // "" last
+		}
+		if groupBy == "status" {
+			ia, ib := slices.Index(statuses, a.Value), slices.Index(statuses, b.Value)
+			if ia >= 0 && ib >= 0 {
+				return cmp.Compare(ia, ib)
+			}
+			if ia >= 0 || ib >= 0 {
+				return cmp.Compare(ib, ia) // configured statuses before unknown ones
+			}
+		}
+		if c := strings.Compare(strings.ToLower(a.Value), strings.ToLower(b.Value)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return groups
+}
+
+// groupValues returns the values of the groupBy field of a file's metadata.
+func groupValues(metadata *files.Metadata, groupBy string) []string {
+	if metadata == nil {
+		return nil
+	}
+	if key, ok := customFieldKey(groupBy); ok {
+		return nonEmpty(metadata.Custom[key])
+	}
+	switch groupBy {
+	case "collection":
+		return nonEmpty(metadata.Collection)
+	case "editor":
+		return nonEmpty(string(metadata.Editor))
+	case "label":
+		return nonEmpty(metadata.Label)
+	case "tags":
+		var tags []string
+		for _, tag := range metadata.Tags {
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	case "status":
+		return nonEmpty(files.KanbanStatusFromTags(metadata.Tags))
+	}
+	return nil
+}
+
+// nonEmpty returns value as a single-element slice, or nil when it is empty.
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
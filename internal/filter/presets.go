@@ -0,0 +1,92 @@
+// Package filter - named quick filter presets stored in config storage
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"knov/internal/configStorage"
+	"knov/internal/logging"
+)
+
+// ErrPresetNotFound is returned when a filter preset does not exist.
+var ErrPresetNotFound = errors.New("filter preset not found")
+
+// presetKey returns the configStorage key for a filter preset
+func presetKey(name string) string {
+	return "filterpreset/" + name
+}
+
+// SavePreset validates and stores config as the filter preset name, replacing an existing one.
+// Unlike saved filters, presets have no index file - they are only run on demand.
+func SavePreset(name string, config *Config) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("preset name must not contain '/'")
+	}
+	if err := ValidateConfig(config); err != nil {
+		return fmt.Errorf("invalid filter config: %w", err)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filter preset: %w", err)
+	}
+	if err := configStorage.Set(presetKey(name), data); err != nil {
+		return fmt.Errorf("failed to save filter preset: %w", err)
+	}
+
+	logging.LogInfo(logging.KeyApp, "saved filter preset: %s", name)
+	return nil
+}
+
+// GetPreset loads the filter preset name, returning ErrPresetNotFound when it doesn't exist.
+func GetPreset(name string) (*Config, error) {
+	data, err := configStorage.Get(presetKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrPresetNotFound
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filter preset: %w", err)
+	}
+	return &config, nil
+}
+
+// ListPresets returns the names of all filter presets.
+func ListPresets() ([]string, error) {
+	keys, err := configStorage.List("filterpreset/")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = strings.TrimPrefix(k, "filterpreset/")
+	}
+	return names, nil
+}
+
+// DeletePreset removes the filter preset name, returning ErrPresetNotFound when it doesn't exist.
+func DeletePreset(name string) error {
+	data, err := configStorage.Get(presetKey(name))
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrPresetNotFound
+	}
+	if err := configStorage.Delete(presetKey(name)); err != nil {
+		return err
+	}
+
+	logging.LogInfo(logging.KeyApp, "deleted filter preset: %s", name)
+	return nil
+}
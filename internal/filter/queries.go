@@ -0,0 +1,102 @@
+// Package filter - named text queries stored in config storage
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configStorage"
+	"knov/internal/logging"
+)
+
+// ErrQueryNotFound is returned when a saved query does not exist.
+var ErrQueryNotFound = errors.New("saved query not found")
+
+// SavedQuery is a named text query, see ParseQueryString.
+type SavedQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// queryKey returns the configStorage key for a saved query
+func queryKey(name string) string {
+	return "filterquery/" + name
+}
+
+// SaveQuery validates and stores q as the saved query name, replacing an existing one.
+// The query text is stored as is, so it is parsed again with the current fields on every run.
+func SaveQuery(name, q string) error {
+	name = strings.TrimSpace(name)
+	q = strings.TrimSpace(q)
+	if name == "" {
+		return fmt.Errorf("query name is required")
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("query name must not contain '/'")
+	}
+	if q == "" {
+		return fmt.Errorf("query is required")
+	}
+	if _, err := ParseQueryString(q); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	if err := configStorage.Set(queryKey(name), []byte(q)); err != nil {
+		return fmt.Errorf("failed to save query: %w", err)
+	}
+
+	logging.LogInfo(logging.KeyApp, "saved query: %s", name)
+	return nil
+}
+
+// GetQuery returns the text of the saved query name, returning ErrQueryNotFound when it doesn't exist.
+func GetQuery(name string) (string, error) {
+	data, err := configStorage.Get(queryKey(name))
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", ErrQueryNotFound
+	}
+	return string(data), nil
+}
+
+// ListQueries returns all saved queries, ordered by name.
+func ListQueries() ([]SavedQuery, error) {
+	keys, err := configStorage.List("filterquery/")
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]SavedQuery, 0, len(keys))
+	for _, k := range keys {
+		data, err := configStorage.Get(k)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+		queries = append(queries, SavedQuery{Name: strings.TrimPrefix(k, "filterquery/"), Query: string(data)})
+	}
+	slices.SortFunc(queries, func(a, b SavedQuery) int { return strings.Compare(a.Name, b.Name) })
+	return queries, nil
+}
+
+// DeleteQuery removes the saved query name, returning ErrQueryNotFound when it doesn't exist.
+func DeleteQuery(name string) error {
+	data, err := configStorage.Get(queryKey(name))
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrQueryNotFound
+	}
+	if err := configStorage.Delete(queryKey(name)); err != nil {
+		return err
+	}
+
+	logging.LogInfo(logging.KeyApp, "deleted saved query: %s", name)
+	return nil
+}
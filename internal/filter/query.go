@@ -0,0 +1,241 @@
+// Package filter - compact text query language translated into a filter Config
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"knov/internal/configmanager"
+)
+
+// queryFieldAliases maps the short query keys onto their filter metadata fields.
+var queryFieldAliases = map[string]string{
+	"tag":     "tags",
+	"folder":  "folders",
+	"created": "createdAt",
+	"edited":  "lastEdited",
+	"words":   "wordCount",
+	"parent":  "child-of",
+	"kid":     "parent-of",
+}
+
+// queryOperators maps the comparison characters of a "key<op>value" term onto filter operators.
+// ":" is equals, turned into in for comma separated values and regex for /pattern/ values.
+var queryOperators = map[byte]string{
+	':': "equals",
+	'~': "contains",
+	'>': "greater",
+	'<': "less",
+}
+
+// ParseQueryString translates a text query into a filter config. Terms are separated by
+// whitespace, values with spaces can be double quoted:
+//
+//	key:value     equals (key:a,b is in, key:/pattern/ is regex)
+//	key~value     contains
+//	key>value     greater, key<value less
+//	-key:value    excludes instead of includes
+//	word          title contains word
+//	or            combines the criteria with or instead of and
+//
+// Keys are the filter metadata fields (see GetMetadataFields) or their aliases tag, folder,
+// created, edited, words, parent and kid. under:folder matches a folder and its subfolders,
+// status:value the kanban status tag and any other key the custom field of that name, so
+// "type:literature tag:#ml created>2025-01-01" needs no custom. prefix. sort, order, limit,
+// offset, group and display set the matching config options. The criteria are validated
+// like the JSON filter configs.
+func ParseQueryString(q string) (*Config, error) {
+	terms, err := splitQueryTerms(q)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{Logic: "and", Display: "list", Limit: 50}
+	seenLogic := ""
+	for _, term := range terms {
+		if lower := strings.ToLower(term); lower == "and" || lower == "or" {
+			if seenLogic != "" && seenLogic != lower {
+				return nil, fmt.Errorf("a query can't mix and and or")
+			}
+			seenLogic = lower
+			config.Logic = lower
+			continue
+		}
+
+		action := "include"
+		if rest, ok := strings.CutPrefix(term, "-"); ok && len(rest) > 0 {
+			action = "exclude"
+			term = rest
+		}
+
+		key, op, value, ok := cutQueryTerm(term)
+		if !ok {
+			criterion := Criteria{Metadata: "title", Operator: "contains", Value: unquoteQueryValue(term), Action: action}
+			config.Criteria = append(config.Criteria, criterion)
+			continue
+		}
+		value = unquoteQueryValue(value)
+		if value == "" {
+			return nil, fmt.Errorf("missing value for %s", key)
+		}
+
+		if op == ':' {
+			if handled, err := applyQueryOption(config, key, value); handled {
+				if err == nil && action == "exclude" {
+					err = fmt.Errorf("%s can't be negated", key)
+				}
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		criterion, err := queryCriterion(key, op, value, action)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateCriterion(criterion); err != nil {
+			return nil, err
+		}
+		config.Criteria = append(config.Criteria, criterion)
+	}
+
+	if err := ValidateConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyQueryOption sets a config option of a "key:value" term, reporting false if key isn't one.
+func applyQueryOption(config *Config, key, value string) (bool, error) {
+	switch key {
+	case "sort":
+		config.SortBy = value
+	case "order":
+		config.SortOrder = value
+	case "group":
+		config.GroupBy = value
+	case "display":
+		config.Display = value
+	case "limit", "offset":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return true, fmt.Errorf("invalid %s: %s", key, value)
+		}
+		if key == "limit" {
+			config.Limit = n
+		} else {
+			config.Offset = n
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// queryCriterion builds the criterion of a "key<op>value" term.
+func queryCriterion(key string, op byte, value, action string) (Criteria, error) {
+	operator, ok := queryOperators[op]
+	if !ok {
+		return Criteria{}, fmt.Errorf("invalid operator %q", op)
+	}
+	if op == ':' {
+		switch {
+		case len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/"):
+			operator, value = "regex", value[1:len(value)-1]
+		case strings.Contains(value, ","):
+			operator = "in"
+		}
+	}
+
+	field := key
+	switch {
+	case key == "under":
+		field, operator = "folders", "under"
+		if op != ':' {
+			return Criteria{}, fmt.Errorf("under only supports :")
+		}
+	case key == "status":
+		field = "tags"
+		statuses := strings.Split(value, ",")
+		for i, status := range statuses {
+			statuses[i] = configmanager.KanbanStatusTag(strings.TrimSpace(status))
+		}
+		value = strings.Join(statuses, ",")
+	case queryFieldAliases[key] != "":
+		field = queryFieldAliases[key]
+	case strings.HasPrefix(key, "custom.") || slices.Contains(GetMetadataFields(), key):
+		// already a filter field
+	default:
+		field = "custom." + key
+	}
+
+	if field == "tags" {
+		tags := strings.Split(value, ",")
+		for i, tag := range tags {
+			tags[i] = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		}
+		value = strings.Join(tags, ",")
+	}
+
+	return Criteria{Metadata: field, Operator: operator, Value: value, Action: action}, nil
+}
+
+// cutQueryTerm splits a term at its first operator character. Terms whose key would be empty
+// or contain anything but letters, digits, '.', '-' and '_' aren't "key<op>value" terms.
+func cutQueryTerm(term string) (key string, op byte, value string, ok bool) {
+	for i := 0; i < len(term); i++ {
+		c := term[i]
+		if _, isOp := queryOperators[c]; isOp {
+			if i == 0 {
+				return "", 0, "", false
+			}
+			return term[:i], c, term[i+1:], true
+		}
+		if !isQueryKeyChar(c) {
+			return "", 0, "", false
+		}
+	}
+	return "", 0, "", false
+}
+
+func isQueryKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
+
+// unquoteQueryValue strips the double quotes splitQueryTerms kept around a value.
+func unquoteQueryValue(value string) string {
+	return strings.ReplaceAll(value, `"`, "")
+}
+
+// splitQueryTerms splits q at whitespace outside of double quotes. The quotes stay in the
+// terms, so key:"a b" still splits into key and value.
+func splitQueryTerms(q string) ([]string, error) {
+	var terms []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms, nil
+}
@@ -74,13 +74,14 @@ func openRepo() (*git.Repository, error) {
 // runs independently on a timer, so without this lock two of these can race
 // on the same index file and corrupt it or silently drop a commit. Only the
 // entrypoints called concurrently from goroutines/cron need it - functions
-// they call internally (Push, SyncBeforeCommit) must not also take this lock,
-// or the second Lock() call would deadlock.
+// they call internally (autoPush, SyncBeforeCommit) must not also take this lock,
+// or the second Lock() call would deadlock. Pull takes it too, so a pull never
+// moves HEAD under a commit in progress.
 var gitWriteMu sync.Mutex
 
-// gitPushMu serializes the background push goroutines Push() spawns, so two
-// concurrent pushes can't race each other. Deliberately separate from
-// gitWriteMu - see Push().
+// gitPushMu serializes pushes, the background ones autoPush() spawns and the
+// manual ones, so two concurrent pushes can't race each other. Deliberately
+// separate from gitWriteMu - see Push().
 var gitPushMu sync.Mutex
 
 // EnsureRepoConfig sets local git config options that should always be present.
@@ -213,6 +214,36 @@ func GetRecentlyChangedFiles(count, offset int) ([]GitHistoryFile, error) {
 	return files, nil
 }
 
+// RecentChanges returns the files of the count commits after offset, optionally only those
+// of a collection or (recursively) a folder. hasMore reports whether another page may follow.
+func RecentChanges(count, offset int, collection, folder string) ([]GitHistoryFile, bool, error) {
+	changed, err := GetRecentlyChangedFiles(count, offset)
+	if err != nil {
+		return nil, false, err
+	}
+
+	unfilteredCount := len(changed)
+	if collection != "" || folder != "" {
+		var filtered []GitHistoryFile
+		for _, f := range changed {
+			meta, err := files.MetaDataGet(pathutils.ToWithPrefix(f.Path))
+			if err != nil || meta == nil {
+				continue
+			}
+			if collection != "" && meta.Collection != collection {
+				continue
+			}
+			if folder != "" && !pathutils.FolderContains(strings.Join(meta.Folders, "/"), folder) {
+				continue
+			}
+			filtered = append(filtered, f)
+		}
+		changed = filtered
+	}
+
+	return changed, unfilteredCount == count, nil
+}
+
 // GetUntrackedFiles returns list of untracked files in git
 func GetUntrackedFiles() ([]string, error) {
 	repo, err := openRepo()
@@ -291,7 +322,7 @@ func AddNewFiles() ([]string, error) {
 	}
 
 	logging.LogInfo(logging.KeyApp, "auto-committed %d new files to git", len(untrackedFiles))
-	Push()
+	autoPush()
 	return untrackedFiles, nil
 }
 
@@ -347,7 +378,7 @@ func CommitDeletedFiles(deletedFiles []string) error {
 	}
 
 	logging.LogInfo(logging.KeyApp, "auto-committed %d deleted files to git", len(deletedFiles))
-	Push()
+	autoPush()
 	return nil
 }
 
@@ -403,7 +434,7 @@ func CommitFile(fullPath string) {
 	}
 
 	logging.LogInfo(logging.KeyApp, "git: committed %s", relPath)
-	Push()
+	autoPush()
 }
 
 // CommitDeletedFile stages and commits a single deleted file immediately.
@@ -457,7 +488,7 @@ func CommitDeletedFile(fullPath string) {
 	}
 
 	logging.LogInfo(logging.KeyApp, "git: committed deletion of %s", relPath)
-	Push()
+	autoPush()
 }
 
 // CommitModifiedFiles commits all modified files
@@ -504,7 +535,7 @@ func CommitModifiedFiles(modifiedFiles []string) error {
 	}
 
 	logging.LogInfo(logging.KeyApp, "auto-committed %d modified files to git", len(modifiedFiles))
-	Push()
+	autoPush()
 	return nil
 }
 
@@ -512,6 +543,42 @@ func CommitModifiedFiles(modifiedFiles []string) error {
 // using the equivalent of "git add -A" and commits them.
 // Returns true when a commit was actually made, false when the tree was already clean.
 func CommitAllPending() (bool, error) {
+	return commitAll(func(relPaths []string) string {
+		const fileListLimit = 5
+		switch {
+		case len(relPaths) == 0:
+			return "auto-commit: external changes"
+		case len(relPaths) <= fileListLimit:
+			return "auto-commit: " + strings.Join(relPaths, ", ")
+		default:
+			return fmt.Sprintf("auto-commit: %d files modified externally", len(relPaths))
+		}
+	})
+}
+
+// SnapshotMessage returns the snapshot commit message of template at now: {timestamp} is
+// replaced with now in RFC 3339, an empty template is "auto-snapshot {timestamp}".
+func SnapshotMessage(template string, now time.Time) string {
+	if strings.TrimSpace(template) == "" {
+		template = "auto-snapshot {timestamp}"
+	}
+	return strings.ReplaceAll(template, "{timestamp}", now.Format(time.RFC3339))
+}
+
+// CommitSnapshot commits every pending change like CommitAllPending, but with message.
+// A clean tree is skipped without an attempt to commit. Returns true when a commit was made.
+func CommitSnapshot(message string) (bool, error) {
+	return commitAll(func(relPaths []string) string {
+		if len(relPaths) == 0 {
+			return ""
+		}
+		return message
+	})
+}
+
+// commitAll stages and commits every pending change with the message built from their
+// paths relative to the data directory. An empty message skips the commit.
+func commitAll(message func(relPaths []string) string) (bool, error) {
 	gitWriteMu.Lock()
 	defer gitWriteMu.Unlock()
 
@@ -539,23 +606,17 @@ func CommitAllPending() (bool, error) {
 			}
 		}
 	}
+	commitMsg := message(relPaths)
+	if commitMsg == "" {
+		logging.LogDebug(logging.KeyFileSync, "git: nothing to commit")
+		return false, nil
+	}
 	SyncBeforeCommit(localFiles)
 
 	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
 		return false, fmt.Errorf("git add -A failed: %w", err)
 	}
 
-	const fileListLimit = 5
-	var commitMsg string
-	switch {
-	case len(relPaths) == 0:
-		commitMsg = "auto-commit: external changes"
-	case len(relPaths) <= fileListLimit:
-		commitMsg = "auto-commit: " + strings.Join(relPaths, ", ")
-	default:
-		commitMsg = fmt.Sprintf("auto-commit: %d files modified externally", len(relPaths))
-	}
-
 	_, err = worktree.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  "knov",
@@ -571,8 +632,8 @@ func CommitAllPending() (bool, error) {
 		return false, fmt.Errorf("git commit failed: %w", err)
 	}
 
-	logging.LogInfo(logging.KeyFileSync, "git: auto-committed pending changes")
-	Push()
+	logging.LogInfo(logging.KeyFileSync, "git: committed pending changes: %s", commitMsg)
+	autoPush()
 	return true, nil
 }
 
@@ -706,6 +767,26 @@ func GetFileHistory(filePath string) ([]FileVersion, error) {
 	return versions, nil
 }
 
+// FileHistory returns the limit newest versions of a file (all of them when limit <= 0),
+// following renames like GetFileHistory. A file without history, or a repository without
+// commits yet, has no versions rather than an error.
+func FileHistory(filePath string, limit int) ([]FileVersion, error) {
+	versions, err := GetFileHistory(filePath)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return []FileVersion{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []FileVersion{}
+	}
+	if limit > 0 && len(versions) > limit {
+		versions = versions[:limit]
+	}
+	return versions, nil
+}
+
 // GetCurrentCommit returns the current HEAD commit hash
 func GetCurrentCommit() (string, error) {
 	repo, err := openRepo()
@@ -1539,19 +1620,36 @@ func parsePushTimeout() time.Duration {
 	return d
 }
 
-// PullRebase fetches and rebases the local branch onto the remote.
-// Returns nil if remote is not configured or already up to date.
-// Returns errConflict if a merge conflict is detected.
-var errConflict = errors.New("merge conflict")
+// ErrNoRemote is returned by Pull and Push when no remote is configured.
+var ErrNoRemote = errors.New("no remote configured")
+
+// ConflictError is returned by Pull when the local and the remote branch diverged, so the
+// remote commits can't be fast-forwarded onto the local ones. Files lists the paths
+// (relative to the data dir) changed on both sides since they diverged, empty when the
+// branches only touched different files.
+type ConflictError struct {
+	Files []string `json:"files"`
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Files) == 0 {
+		return "local and remote branch diverged"
+	}
+	return fmt.Sprintf("merge conflict in %s", strings.Join(e.Files, ", "))
+}
 
-// PullRebase fetches the remote and fast-forwards the local branch.
-// Used by the cronjob (no dirty working tree).
-// Returns errConflict on merge conflicts, nil if already up to date or timed out.
-func PullRebase() error {
+// Pull fetches the remote and fast-forwards the local branch. It holds gitWriteMu, so it
+// never runs while a save or the cronjob stages and commits. Returns ErrNoRemote without a
+// remote, a *ConflictError when the branches diverged and nil if already up to date or
+// timed out (the next pull catches up).
+func Pull() error {
 	if !remoteEnabled() {
-		return nil
+		return ErrNoRemote
 	}
 
+	gitWriteMu.Lock()
+	defer gitWriteMu.Unlock()
+
 	repo, err := openRepo()
 	if err != nil {
 		return err
@@ -1591,9 +1689,13 @@ func PullRebase() error {
 			logging.LogInfo(logging.KeyGitRemote, "pull timed out after %s — continuing with local commit", timeout)
 			return nil // non-fatal: commit locally, push on next cycle
 		}
-		// check if it's a merge conflict
-		if isConflicted() {
-			return errConflict
+		if errors.Is(err, git.ErrNonFastForwardUpdate) {
+			conflicts, diffErr := divergedFiles(repo, branch)
+			if diffErr != nil {
+				logging.LogWarning(logging.KeyGitRemote, "pull: failed to list conflicting files: %v", diffErr)
+			}
+			logging.LogWarning(logging.KeyGitRemote, "pull: local and remote branch diverged, conflicting files: %v", conflicts)
+			return &ConflictError{Files: conflicts}
 		}
 		return fmt.Errorf("git pull failed: %w", err)
 	}
@@ -1602,6 +1704,84 @@ func PullRebase() error {
 	return nil
 }
 
+// divergedFiles returns the files changed both on the local HEAD and on the fetched remote
+// branch since their merge base, sorted.
+func divergedFiles(repo *git.Repository, branch string) ([]string, error) {
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return nil, err
+	}
+	localRef, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	remoteCommit, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+	localCommit, err := repo.CommitObject(localRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := localCommit.MergeBase(remoteCommit)
+	if err != nil {
+		return nil, err
+	}
+	var base *object.Commit
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+
+	localChanged, err := filesChangedBetween(base, localCommit)
+	if err != nil {
+		return nil, err
+	}
+	remoteChanged, err := filesChangedBetween(base, remoteCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for path := range localChanged {
+		if _, ok := remoteChanged[path]; ok {
+			conflicts = append(conflicts, path)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// filesChangedBetween returns the paths that differ between the trees of from and to. A nil
+// from (no common history) counts every file of to as changed.
+func filesChangedBetween(from, to *object.Commit) (map[string]struct{}, error) {
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	fromTree := &object.Tree{}
+	if from != nil {
+		if fromTree, err = from.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]struct{}, len(changes))
+	for _, change := range changes {
+		if change.From.Name != "" {
+			changed[change.From.Name] = struct{}{}
+		}
+		if change.To.Name != "" {
+			changed[change.To.Name] = struct{}{}
+		}
+	}
+	return changed, nil
+}
+
 // fetchAndReset fetches remote changes and hard-resets to remote HEAD.
 // Used by SyncBeforeCommit when the working tree may be dirty.
 // Returns the set of relative paths changed by the incoming commits.
@@ -1698,14 +1878,6 @@ func fetchAndReset() (map[string]struct{}, error) {
 	return changedFiles, nil
 }
 
-// isConflicted checks if the repo has unresolved merge conflicts by looking for
-// the MERGE_HEAD file that git creates during a conflicted merge.
-func isConflicted() bool {
-	dataDir := configmanager.GetAppConfig().DataPath
-	_, err := os.Stat(filepath.Join(dataDir, ".git", "MERGE_HEAD"))
-	return err == nil
-}
-
 // abortMerge removes the MERGE_HEAD file to clean up a failed merge state.
 func abortMerge() {
 	dataDir := configmanager.GetAppConfig().DataPath
@@ -1866,56 +2038,68 @@ func SyncBeforeCommit(localFiles []string) {
 	}
 }
 
-// Returns immediately; errors are logged but not propagated.
-// No-op if remote is not configured or auto-push is disabled.
-func Push() {
+// autoPush pushes in the background after a commit. Returns immediately; errors are logged
+// but not propagated. No-op if remote is not configured or auto-push is disabled.
+func autoPush() {
 	if !remoteEnabled() || !configmanager.GetGitAutoPush() {
 		return
 	}
 
+	// runs in the background so local commits never block on the network round trip,
+	// Push serializes concurrent pushes on gitPushMu
 	go func() {
-		// separate from gitWriteMu on purpose: push runs in the background so
-		// local commits never block on the network round trip, but concurrent
-		// pushes still need to be serialized against each other.
-		gitPushMu.Lock()
-		defer gitPushMu.Unlock()
-
-		repo, err := openRepo()
-		if err != nil {
-			logging.LogInfo(logging.KeyGitRemote, "push: failed to open repo: %v", err)
-			return
+		if err := Push(); err != nil {
+			logging.LogInfo(logging.KeyGitRemote, "push failed: %v", err)
 		}
+	}()
+}
 
-		timeout := parsePushTimeout()
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
+// Push pushes the configured branch to the remote and waits for it. Returns ErrNoRemote
+// without a remote and nil if there was nothing to push.
+func Push() error {
+	if !remoteEnabled() {
+		return ErrNoRemote
+	}
 
-		branch := configmanager.GetGitRemoteBranch()
-		remote := configmanager.GetGitRemote()
+	// separate from gitWriteMu on purpose: a push only reads refs, so saves and commits
+	// never wait for the network, but concurrent pushes still need to be serialized
+	gitPushMu.Lock()
+	defer gitPushMu.Unlock()
 
-		auth, authErr := buildAuth()
-		if authErr != nil {
-			logging.LogInfo(logging.KeyGitRemote, "push: failed to build auth: %v", authErr)
-		}
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("push: failed to open repo: %w", err)
+	}
 
-		err = repo.PushContext(ctx, &git.PushOptions{
-			RemoteName: "origin",
-			RemoteURL:  remote,
-			RefSpecs:   []gitcfg.RefSpec{gitcfg.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)},
-			Auth:       auth,
-		})
+	timeout := parsePushTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-		if err != nil {
-			if errors.Is(err, git.NoErrAlreadyUpToDate) {
-				logging.LogInfo(logging.KeyGitRemote, "nothing to push")
-				return
-			}
-			logging.LogInfo(logging.KeyGitRemote, "push failed: %v", err)
-			return
+	branch := configmanager.GetGitRemoteBranch()
+	remote := configmanager.GetGitRemote()
+
+	auth, authErr := buildAuth()
+	if authErr != nil {
+		logging.LogInfo(logging.KeyGitRemote, "push: failed to build auth: %v", authErr)
+	}
+
+	err = repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: "origin",
+		RemoteURL:  remote,
+		RefSpecs:   []gitcfg.RefSpec{gitcfg.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)},
+		Auth:       auth,
+	})
+
+	if err != nil {
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			logging.LogInfo(logging.KeyGitRemote, "nothing to push")
+			return nil
 		}
+		return fmt.Errorf("git push failed: %w", err)
+	}
 
-		logging.LogInfo(logging.KeyGitRemote, "pushed to %s/%s", remote, branch)
-	}()
+	logging.LogInfo(logging.KeyGitRemote, "pushed to %s/%s", remote, branch)
+	return nil
 }
 
 // EnsureRemote creates or updates the "origin" remote in .git/config
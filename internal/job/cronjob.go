@@ -2,9 +2,13 @@
 package job
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"slices"
+	"time"
 
+	"knov/internal/configmanager"
 	"knov/internal/files"
 	"knov/internal/git"
 	"knov/internal/logging"
@@ -24,12 +28,13 @@ func (j *fileJob) Run() error {
 	logging.MarkSessionStart(logging.KeyFileSync)
 	logging.LogDebug(logging.KeyFileSync, "running file cronjob")
 
-	if err := git.PullRebase(); err != nil {
+	if err := git.Pull(); err != nil && !errors.Is(err, git.ErrNoRemote) {
 		logging.LogWarning(logging.KeyFileSync, "git pull failed: %v", err)
 	}
 
 	var filesToProcess []string
 	var filesToDelete []string
+	moved := 0
 
 	if _, err := git.CommitAllPending(); err != nil {
 		logging.LogError(logging.KeyFileSync, "failed to commit pending changes: %v", err)
@@ -73,7 +78,7 @@ func (j *fileJob) Run() error {
 					oldNormalized := pathutils.ToWithPrefix(move.OldPath)
 					newNormalized := pathutils.ToWithPrefix(move.NewPath)
 					logging.LogInfo(logging.KeyFileSync, "processing file move: %s -> %s", oldNormalized, newNormalized)
-					// no-refresh: this whole run ends with one RebuildAllCaches() below
+					// no-refresh: this run ends with one RebuildAllCaches() below
 					if err := files.UpdateLinksForMovedFileNoRefresh(logging.KeyFileSync, oldNormalized, newNormalized); err != nil {
 						logging.LogError(logging.KeyFileSync, "failed to update links for moved file %s -> %s: %v", oldNormalized, newNormalized, err)
 						// fall back to generic add/delete handling so the new path still gets metadata
@@ -81,6 +86,7 @@ func (j *fileJob) Run() error {
 						filesToDelete = append(filesToDelete, move.OldPath)
 					} else {
 						logging.LogInfo(logging.KeyFileSync, "successfully updated links for moved file %s -> %s", oldNormalized, newNormalized)
+						moved++
 					}
 				}
 			}
@@ -139,8 +145,22 @@ func (j *fileJob) Run() error {
 		}
 	}
 
-	if err := files.RebuildAllCaches(); err != nil {
-		logging.LogError(logging.KeyFileSync, "failed to save system data to cache: %v", err)
+	// pick up what git doesn't report (git disabled, edits not committed yet) and purge
+	// deleted files, only re-reading the files modified since the last run
+	since := files.GetLastReindexTime()
+	reindexStart := time.Now()
+	reindexed, err := files.ReindexChangedSinceNoRefresh(logging.KeyFileSync, since)
+	if err != nil {
+		logging.LogError(logging.KeyFileSync, "incremental reindex failed: %v", err)
+	} else if err := files.SetLastReindexTime(reindexStart); err != nil {
+		logging.LogError(logging.KeyFileSync, "failed to save last reindex time: %v", err)
+	}
+
+	// the caches only need a rebuild when something changed, or on the first run
+	if since.IsZero() || reindexed > 0 || moved > 0 || len(filesToProcess) > 0 || len(filesToDelete) > 0 {
+		if err := files.RebuildAllCaches(); err != nil {
+			logging.LogError(logging.KeyFileSync, "failed to save system data to cache: %v", err)
+		}
 	}
 
 	// run filter index as a sub-step so it gets its own history entry
@@ -198,3 +218,59 @@ func (j *rebuildJob) Run() error {
 	logging.LogDebug(logging.KeyMetadataRebuild, "metadata rebuild cronjob completed")
 	return nil
 }
+
+// ----------------------------------------------------------------------------------------
+// --------------------------------------- backupJob --------------------------------------
+// ----------------------------------------------------------------------------------------
+
+// backupJob writes a zip archive of the data directory, private collections included, into
+// the backup directory and deletes all but the newest BackupKeep archives.
+type backupJob struct {
+	result BackupResult
+}
+
+func (j *backupJob) Name() string { return "backup" }
+
+func (j *backupJob) Run() error {
+	logging.MarkSessionStart(logging.KeyBackup)
+	cfg := configmanager.GetAppConfig()
+	path, pruned, err := files.WriteBackup(cfg.BackupPath, cfg.BackupKeep)
+	j.result = BackupResult{Path: path, Pruned: pruned}
+	return err
+}
+
+func (j *backupJob) Output() any { return j.result }
+
+func (j *backupJob) Message() string {
+	return fmt.Sprintf("created %s, pruned %d old backups", filepath.Base(j.result.Path), j.result.Pruned)
+}
+
+// ----------------------------------------------------------------------------------------
+// -------------------------------------- snapshotJob -------------------------------------
+// ----------------------------------------------------------------------------------------
+
+// snapshotJob commits every pending change of the data directory with the configured
+// snapshot message, and nothing when the tree is clean.
+type snapshotJob struct {
+	committed bool
+	message   string
+}
+
+func (j *snapshotJob) Name() string { return "git-snapshot" }
+
+func (j *snapshotJob) Run() error {
+	j.message = git.SnapshotMessage(configmanager.GetAppConfig().GitSnapshotMessage, time.Now())
+	committed, err := git.CommitSnapshot(j.message)
+	if err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	j.committed = committed
+	return nil
+}
+
+func (j *snapshotJob) Message() string {
+	if !j.committed {
+		return "nothing to commit"
+	}
+	return fmt.Sprintf("committed %q", j.message)
+}
@@ -49,3 +49,9 @@ type MediaCleanupResult struct {
 	Size    int64
 	Failed  int
 }
+
+// BackupResult holds the outcome of a backup run.
+type BackupResult struct {
+	Path   string // the archive written
+	Pruned int    // old archives deleted
+}
@@ -2,10 +2,10 @@
 package job
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"knov/internal/configmanager"
 	"knov/internal/contentStorage"
 	"knov/internal/files"
 	"knov/internal/filter"
@@ -205,13 +205,18 @@ type gitPullJob struct{}
 
 func (j *gitPullJob) Name() string { return "git-pull" }
 
+// Run pulls and then runs the file sync, which reindexes the metadata of the files changed
+// since the last processed commit, so the pulled changes show up right away.
 func (j *gitPullJob) Run() error {
-	if configmanager.GetGitRemote() == "" {
-		return fmt.Errorf("no remote configured")
-	}
-	if err := git.PullRebase(); err != nil {
+	if err := git.Pull(); err != nil {
+		if errors.Is(err, git.ErrNoRemote) {
+			return err
+		}
 		return fmt.Errorf("git pull failed: %w", err)
 	}
+	if err := RunFileSync(); err != nil {
+		logging.LogWarning(logging.KeyApp, "file sync after git pull failed: %v", err)
+	}
 	return nil
 }
 
@@ -224,9 +229,5 @@ type gitPushJob struct{}
 func (j *gitPushJob) Name() string { return "git-push" }
 
 func (j *gitPushJob) Run() error {
-	if configmanager.GetGitRemote() == "" {
-		return fmt.Errorf("no remote configured")
-	}
-	git.Push()
-	return nil
+	return git.Push()
 }
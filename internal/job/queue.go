@@ -0,0 +1,233 @@
+// Package job - queue of long operations started over the API and polled for their status.
+package job
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"knov/internal/cacheStorage"
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+)
+
+// JobStatusQueued is the status of a queued job that didn't start yet.
+const JobStatusQueued JobStatus = "queued"
+
+var (
+	// ErrUnknownOperation is returned by Enqueue for an operation name that can't be queued.
+	ErrUnknownOperation = errors.New("unknown job operation")
+	// ErrQueueFull is returned by Enqueue when jobQueueSize jobs are already waiting.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrJobNotFound is returned by GetQueuedJob for an unknown or expired job id.
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// QueuedJob is the status of a job started through Enqueue. Progress goes from 0 to 100,
+// Result holds the typed result of the operations that have one.
+type QueuedJob struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	Status     JobStatus  `json:"status"`
+	Progress   int        `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done reports whether the job reached a terminal status.
+func (j *QueuedJob) Done() bool {
+	return j.Status == JobStatusOK || j.Status == JobStatusError
+}
+
+// queueStep is one step of a queued operation, the result of the last step is the job result.
+type queueStep func() (any, error)
+
+// queueOperations are the operations Enqueue accepts, each a list of steps run in order.
+// Progress advances after every step.
+var queueOperations = map[string][]queueStep{
+	"rebuild":        {noResult(RunFullRebuild)},
+	"reindex":        {noResult(RunFileSync)},
+	"search-reindex": {noResult(RunSearchReindex)},
+	"filter-reindex": {noResult(RunFilterReindex)},
+	"backup":         {func() (any, error) { return RunBackup() }},
+	"media-cleanup":  {func() (any, error) { return RunMediaCleanup() }},
+	"cronjob": {
+		noResult(RunFileSync), noResult(RunSearchReindex),
+		noResult(RunMetadataRebuild), noResult(RunNotificationPurge),
+	},
+}
+
+func noResult(run func() error) queueStep {
+	return func() (any, error) { return nil, run() }
+}
+
+// QueueOperations returns the operation names Enqueue accepts, sorted.
+func QueueOperations() []string {
+	names := make([]string, 0, len(queueOperations))
+	for name := range queueOperations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// maxQueueSize bounds the channel, the jobQueueSize setting can only lower it.
+const maxQueueSize = 100
+
+// queuedJobTTL is how long the status of a job is kept in the cache.
+const queuedJobTTL = 24 * time.Hour
+
+var (
+	queueMu     sync.Mutex
+	queuedJobs  = map[string]*QueuedJob{} // jobs of this process, by id
+	queueChan   = make(chan string, maxQueueSize)
+	queueWaits  int
+	workerStart sync.Once
+)
+
+// Enqueue queues an operation on the job worker, which runs one job at a time, and returns
+// the queued job. The status is kept in the cache, so it can still be polled after a restart.
+func Enqueue(operation string) (*QueuedJob, error) {
+	if _, ok := queueOperations[operation]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownOperation, operation)
+	}
+	workerStart.Do(func() { go runQueue() })
+
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if queueWaits >= min(configmanager.GetJobQueueSize(), maxQueueSize) {
+		return nil, ErrQueueFull
+	}
+
+	j := &QueuedJob{ID: newJobID(), Operation: operation, Status: JobStatusQueued, CreatedAt: time.Now()}
+	queuedJobs[j.ID] = j
+	saveQueuedJob(j)
+	queueWaits++
+	queueChan <- j.ID
+	logging.LogInfo(logging.KeyApp, "queued %s job %s", operation, j.ID)
+	return copyQueuedJob(j), nil
+}
+
+// GetQueuedJob returns the status of a queued job. A job the cache still knows as queued or
+// running but this process doesn't was interrupted by a restart and is reported as failed.
+func GetQueuedJob(id string) (*QueuedJob, error) {
+	queueMu.Lock()
+	if j, ok := queuedJobs[id]; ok {
+		defer queueMu.Unlock()
+		return copyQueuedJob(j), nil
+	}
+	queueMu.Unlock()
+
+	data, err := cacheStorage.Get(queuedJobKey(id))
+	if err != nil || data == nil {
+		return nil, ErrJobNotFound
+	}
+	var j QueuedJob
+	if err := json.Unmarshal(data, &j); err != nil {
+		logging.LogWarning(logging.KeyApp, "invalid cached status of job %s: %v", id, err)
+		return nil, ErrJobNotFound
+	}
+	if time.Since(j.CreatedAt) > queuedJobTTL {
+		return nil, ErrJobNotFound
+	}
+	if !j.Done() {
+		j.Status, j.Error = JobStatusError, "interrupted by a restart"
+	}
+	return &j, nil
+}
+
+// runQueue is the job worker, running the queued jobs one after the other.
+func runQueue() {
+	for id := range queueChan {
+		queueMu.Lock()
+		queueWaits--
+		j := queuedJobs[id]
+		now := time.Now()
+		j.Status, j.StartedAt = JobStatusRunning, &now
+		saveQueuedJob(j)
+		queueMu.Unlock()
+
+		result, err := runQueuedJob(j)
+
+		queueMu.Lock()
+		finished := time.Now()
+		j.FinishedAt, j.Result = &finished, result
+		if err != nil {
+			j.Status, j.Error = JobStatusError, err.Error()
+			logging.LogError(logging.KeyApp, "%s job %s failed: %v", j.Operation, j.ID, err)
+		} else {
+			j.Status, j.Progress = JobStatusOK, 100
+			logging.LogInfo(logging.KeyApp, "%s job %s finished", j.Operation, j.ID)
+		}
+		saveQueuedJob(j)
+		pruneQueuedJobs()
+		queueMu.Unlock()
+	}
+}
+
+// runQueuedJob runs the steps of a job, advancing its progress after each.
+func runQueuedJob(j *QueuedJob) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	steps := queueOperations[j.Operation]
+	for i, step := range steps {
+		if result, err = step(); err != nil {
+			return nil, err
+		}
+		queueMu.Lock()
+		j.Progress = (i + 1) * 100 / len(steps)
+		saveQueuedJob(j)
+		queueMu.Unlock()
+	}
+	return result, nil
+}
+
+// pruneQueuedJobs forgets finished jobs older than queuedJobTTL. Called with queueMu held.
+func pruneQueuedJobs() {
+	for id, j := range queuedJobs {
+		if j.Done() && time.Since(j.CreatedAt) > queuedJobTTL {
+			delete(queuedJobs, id)
+			if err := cacheStorage.Delete(queuedJobKey(id)); err != nil {
+				logging.LogDebug(logging.KeyApp, "failed to delete cached status of job %s: %v", id, err)
+			}
+		}
+	}
+}
+
+// saveQueuedJob writes the status of a job to the cache. Called with queueMu held.
+func saveQueuedJob(j *QueuedJob) {
+	data, err := json.Marshal(j)
+	if err == nil {
+		err = cacheStorage.Set(queuedJobKey(j.ID), data)
+	}
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to cache status of job %s: %v", j.ID, err)
+	}
+}
+
+func copyQueuedJob(j *QueuedJob) *QueuedJob {
+	c := *j
+	return &c
+}
+
+func queuedJobKey(id string) string {
+	return "job/" + id
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -16,6 +16,8 @@ var (
 	fileInterval            time.Duration
 	searchInterval          time.Duration
 	metadataRebuildInterval time.Duration
+	backupInterval          time.Duration
+	snapshotInterval        time.Duration
 
 	fileMu           sync.Mutex
 	searchMu         sync.Mutex
@@ -35,6 +37,13 @@ var (
 	chatTestMu       sync.Mutex
 	dashboardTestMu  sync.Mutex
 	kanbanTestMu     sync.Mutex
+	metadataTestMu   sync.Mutex
+	storageTestMu    sync.Mutex
+	browseTestMu     sync.Mutex
+	adminTestMu      sync.Mutex
+	settingsTestMu   sync.Mutex
+	backupMu         sync.Mutex
+	snapshotMu       sync.Mutex
 	runAllTestsMu    sync.Mutex
 	runMu            sync.Mutex // prevents concurrent manual Run() calls
 )
@@ -98,10 +107,34 @@ func Start() {
 	}
 	metadataRebuildInterval = parsedMetadataRebuildInterval
 
+	backupInterval = 0
+	if backupIntervalStr := configmanager.GetAppConfig().BackupInterval; backupIntervalStr != "" {
+		parsedBackupInterval, err := time.ParseDuration(backupIntervalStr)
+		if err != nil || parsedBackupInterval <= 0 {
+			logging.LogWarning(logging.KeyApp, "invalid backup interval '%s', periodic backup disabled", backupIntervalStr)
+		} else {
+			backupInterval = parsedBackupInterval
+		}
+	}
+
+	snapshotInterval = 0
+	if cfg := configmanager.GetAppConfig(); cfg.GitSnapshot {
+		parsedSnapshotInterval, err := time.ParseDuration(cfg.GitSnapshotInterval)
+		if err != nil || parsedSnapshotInterval <= 0 {
+			logging.LogWarning(logging.KeyApp, "invalid git snapshot interval '%s', git snapshots disabled", cfg.GitSnapshotInterval)
+		} else {
+			snapshotInterval = parsedSnapshotInterval
+		}
+	}
+
+	// closed once the startup file sync has indexed the changed files
+	initialSyncDone := make(chan struct{})
+
 	go func() {
 		ticker := time.NewTicker(fileInterval)
 		defer ticker.Stop()
 		RunFileSync() // run once on startup
+		close(initialSyncDone)
 		for {
 			select {
 			case <-ticker.C:
@@ -142,7 +175,49 @@ func Start() {
 		}
 	}()
 
-	logging.LogInfo(logging.KeyApp, "cronjob scheduler started (file: %v, search: %v, metadata rebuild: %v)", fileInterval, searchInterval, metadataRebuildInterval)
+	if backupInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(backupInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if _, err := RunBackup(); err != nil {
+						logging.LogError(logging.KeyBackup, "backup failed: %v", err)
+					}
+				case <-stopChan:
+					logging.LogInfo(logging.KeyApp, "backup cronjob stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	if snapshotInterval > 0 {
+		go func() {
+			// the first snapshot must not race the startup indexing
+			select {
+			case <-initialSyncDone:
+			case <-stopChan:
+				return
+			}
+			ticker := time.NewTicker(snapshotInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := RunGitSnapshot(); err != nil {
+						logging.LogError(logging.KeyFileSync, "git snapshot failed: %v", err)
+					}
+				case <-stopChan:
+					logging.LogInfo(logging.KeyApp, "git snapshot cronjob stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	logging.LogInfo(logging.KeyApp, "cronjob scheduler started (file: %v, search: %v, metadata rebuild: %v, backup: %v, git snapshot: %v)", fileInterval, searchInterval, metadataRebuildInterval, backupInterval, snapshotInterval)
 }
 
 // Stop stops the cronjob scheduler.
@@ -167,6 +242,19 @@ func RunMetadataRebuild() error {
 	return execute(&rebuildMu, &rebuildJob{})
 }
 
+// RunBackup writes a backup archive and prunes the old ones with dedup protection.
+// Returns the backup result alongside any error.
+func RunBackup() (BackupResult, error) {
+	j := &backupJob{}
+	err := execute(&backupMu, j)
+	return j.result, err
+}
+
+// RunGitSnapshot commits all pending changes as a snapshot with dedup protection.
+func RunGitSnapshot() error {
+	return execute(&snapshotMu, &snapshotJob{})
+}
+
 // RunFilterReindex runs the filter-reindex job with dedup protection.
 func RunFilterReindex() error {
 	return execute(&filterMu, &filterJob{})
@@ -275,6 +363,51 @@ func RunKanbanTest() (*test.SuiteResult, error) {
 	return j.results, nil
 }
 
+// RunMetadataTest runs the metadata test suite and returns its results alongside any error.
+func RunMetadataTest() (*test.SuiteResult, error) {
+	j := &metadataTestJob{}
+	if err := execute(&metadataTestMu, j); err != nil {
+		return nil, err
+	}
+	return j.results, nil
+}
+
+// RunStorageTest runs the storage test suite and returns its results alongside any error.
+func RunStorageTest() (*test.SuiteResult, error) {
+	j := &storageTestJob{}
+	if err := execute(&storageTestMu, j); err != nil {
+		return nil, err
+	}
+	return j.results, nil
+}
+
+// RunBrowseTest runs the browse test suite and returns its results alongside any error.
+func RunBrowseTest() (*test.SuiteResult, error) {
+	j := &browseTestJob{}
+	if err := execute(&browseTestMu, j); err != nil {
+		return nil, err
+	}
+	return j.results, nil
+}
+
+// RunAdminTest runs the admin test suite and returns its results alongside any error.
+func RunAdminTest() (*test.SuiteResult, error) {
+	j := &adminTestJob{}
+	if err := execute(&adminTestMu, j); err != nil {
+		return nil, err
+	}
+	return j.results, nil
+}
+
+// RunSettingsTest runs the settings test suite and returns its results alongside any error.
+func RunSettingsTest() (*test.SuiteResult, error) {
+	j := &settingsTestJob{}
+	if err := execute(&settingsTestMu, j); err != nil {
+		return nil, err
+	}
+	return j.results, nil
+}
+
 // RunAllTests runs every registered test suite and returns the aggregated results.
 func RunAllTests() (*test.SuiteResult, error) {
 	j := &runAllTestsJob{}
@@ -6,13 +6,17 @@ import (
 	"fmt"
 
 	"knov/internal/test"
+	"knov/internal/test/browsetest"
 	"knov/internal/test/chattest"
 	"knov/internal/test/dashboardtest"
 	"knov/internal/test/editorstest"
 	"knov/internal/test/filtertest"
 	"knov/internal/test/githistorytest"
 	"knov/internal/test/kanbantest"
+	"knov/internal/test/metadatatest"
 	"knov/internal/test/searchtest"
+	"knov/internal/test/settingstest"
+	"knov/internal/test/storagetest"
 )
 
 // ----------------------------------------------------------------------------------------
@@ -213,6 +217,131 @@ func (j *kanbanTestJob) Message() string {
 	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
 }
 
+type metadataTestJob struct {
+	results *test.SuiteResult
+}
+
+func (j *metadataTestJob) Name() string { return "metadata-test" }
+
+func (j *metadataTestJob) Run() error {
+	results, err := (metadatatest.Suite{}).Run()
+	j.results = results
+	if err != nil {
+		return fmt.Errorf("metadata tests failed: %w", err)
+	}
+	return nil
+}
+
+func (j *metadataTestJob) Output() any { return j.results }
+
+func (j *metadataTestJob) Message() string {
+	if j.results == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
+}
+
+type storageTestJob struct {
+	results *test.SuiteResult
+}
+
+func (j *storageTestJob) Name() string { return "storage-test" }
+
+func (j *storageTestJob) Run() error {
+	results, err := (storagetest.Suite{}).Run()
+	j.results = results
+	if err != nil {
+		return fmt.Errorf("storage tests failed: %w", err)
+	}
+	return nil
+}
+
+func (j *storageTestJob) Output() any { return j.results }
+
+func (j *storageTestJob) Message() string {
+	if j.results == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
+}
+
+type browseTestJob struct {
+	results *test.SuiteResult
+}
+
+func (j *browseTestJob) Name() string { return "browse-test" }
+
+func (j *browseTestJob) Run() error {
+	results, err := (browsetest.Suite{}).Run()
+	j.results = results
+	if err != nil {
+		return fmt.Errorf("browse tests failed: %w", err)
+	}
+	return nil
+}
+
+func (j *browseTestJob) Output() any { return j.results }
+
+func (j *browseTestJob) Message() string {
+	if j.results == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
+}
+
+type adminTestJob struct {
+	results *test.SuiteResult
+}
+
+func (j *adminTestJob) Name() string { return "admin-test" }
+
+func (j *adminTestJob) Run() error {
+	// admintest exercises the job queue, it registers itself instead of being imported here
+	suite, ok := test.Lookup("admin")
+	if !ok {
+		return fmt.Errorf("admin test suite is not registered")
+	}
+	results, err := suite.Run()
+	j.results = results
+	if err != nil {
+		return fmt.Errorf("admin tests failed: %w", err)
+	}
+	return nil
+}
+
+func (j *adminTestJob) Output() any { return j.results }
+
+func (j *adminTestJob) Message() string {
+	if j.results == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
+}
+
+type settingsTestJob struct {
+	results *test.SuiteResult
+}
+
+func (j *settingsTestJob) Name() string { return "settings-test" }
+
+func (j *settingsTestJob) Run() error {
+	results, err := (settingstest.Suite{}).Run()
+	j.results = results
+	if err != nil {
+		return fmt.Errorf("settings tests failed: %w", err)
+	}
+	return nil
+}
+
+func (j *settingsTestJob) Output() any { return j.results }
+
+func (j *settingsTestJob) Message() string {
+	if j.results == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d passed, %d failed", j.results.Passed, j.results.Failed)
+}
+
 type runAllTestsJob struct {
 	results *test.SuiteResult
 }
@@ -0,0 +1,91 @@
+package kanban
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+var (
+	// ErrInvalidFieldValue is returned by MoveCardToField for a value that is not a column of the board.
+	ErrInvalidFieldValue = errors.New("value is not a column of the board")
+	// ErrCardNotFound is returned by MoveCardToField for a file without metadata.
+	ErrCardNotFound = errors.New("file has no metadata")
+)
+
+// IsFieldGroupBy reports whether a board can be grouped by groupBy: the kanban status or a
+// custom field ("custom.<key>"). Other fields aren't a single value a card can be moved to.
+func IsFieldGroupBy(groupBy string) bool {
+	return groupBy == "status" || (strings.HasPrefix(groupBy, "custom.") && filter.IsGroupByField(groupBy))
+}
+
+// FieldValues returns the columns a card of a board grouped by groupBy can be moved to:
+// the configured statuses, or the values the custom field has in any file plus "" (unset).
+func FieldValues(groupBy string) ([]string, error) {
+	if groupBy == "status" {
+		return configmanager.GetKanbanStatuses(), nil
+	}
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+	values := []string{""}
+	for _, group := range filter.GroupFiles(allFiles, groupBy) {
+		if group.Value != "" {
+			values = append(values, group.Value)
+		}
+	}
+	return values, nil
+}
+
+// MoveCardToField sets the groupBy field of a file to value, moving its card to that column
+// of a board grouped by groupBy, and stores order (relative file paths) as the card order of
+// the column. Status moves go through MoveCard and are logged like board moves. Returns the
+// previous value.
+func MoveCardToField(filePath, groupBy, value string, order []string) (oldValue string, err error) {
+	if !IsFieldGroupBy(groupBy) {
+		return "", ErrInvalidFieldValue
+	}
+	values, err := FieldValues(groupBy)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(values, value) {
+		return "", ErrInvalidFieldValue
+	}
+
+	normalizedPath := pathutils.ToWithPrefix(filePath)
+	metadata, err := files.MetaDataGet(normalizedPath)
+	if err != nil {
+		return "", err
+	}
+	if metadata == nil {
+		return "", ErrCardNotFound
+	}
+
+	if groupBy == "status" {
+		if oldValue, err = MoveCard("", filePath, value); err != nil {
+			return "", err
+		}
+	} else {
+		key := strings.TrimPrefix(groupBy, "custom.")
+		oldValue = metadata.Custom[key]
+		if _, err := files.MetaDataSetCustomField(normalizedPath, key, value); err != nil {
+			return "", err
+		}
+	}
+
+	stored, err := GetFieldOrder(groupBy)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "kanban: load order failed for %s: %v", groupBy, err)
+		stored = Order{}
+	}
+	stored[value] = order
+	return oldValue, SaveFieldOrder(groupBy, stored)
+}
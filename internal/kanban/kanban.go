@@ -23,6 +23,7 @@ type Card struct {
 	FilePath      string
 	Title         string
 	Collection    string
+	Label         string
 	Status        string
 	Tags          []string
 	CreatedAt     string
@@ -208,29 +209,26 @@ func BuildBoard(folderPath string, cfg *filter.Config, searchQuery string, sortB
 // file's own location (used when the caller doesn't know which board triggered the move).
 func MoveCard(boardFolder, filePath, newStatus string) (oldStatus string, err error) {
 	normalizedPath := pathutils.ToWithPrefix(filePath)
-	meta, err := files.MetaDataGet(normalizedPath)
-	if err != nil || meta == nil {
-		return "", err
-	}
+	meta, err := files.MetaDataModifyRaw(normalizedPath, func(meta *files.Metadata) error {
+		oldStatus = StatusFromTags(meta.Tags, configmanager.GetKanbanPrefix())
 
-	oldStatus = StatusFromTags(meta.Tags, configmanager.GetKanbanPrefix())
-
-	newTag := configmanager.KanbanStatusTag(newStatus)
-	filtered := meta.Tags[:0:0]
-	for _, t := range meta.Tags {
-		if !configmanager.IsKanbanTag(t) {
-			filtered = append(filtered, t)
+		newTag := configmanager.KanbanStatusTag(newStatus)
+		filtered := meta.Tags[:0:0]
+		for _, t := range meta.Tags {
+			if !configmanager.IsKanbanTag(t) {
+				filtered = append(filtered, t)
+			}
 		}
-	}
-	meta.Tags = append(filtered, newTag)
-
-	now := time.Now()
-	if meta.KanbanAddedAt.IsZero() {
-		meta.KanbanAddedAt = now
-	}
-	meta.KanbanMovedAt = now
+		meta.Tags = append(filtered, newTag)
 
-	if err := files.MetaDataSaveRaw(meta); err != nil {
+		now := time.Now()
+		if meta.KanbanAddedAt.IsZero() {
+			meta.KanbanAddedAt = now
+		}
+		meta.KanbanMovedAt = now
+		return nil
+	})
+	if err != nil || meta == nil {
 		return "", err
 	}
 	files.RefreshCaches()
@@ -262,6 +260,7 @@ func cardFromFile(file files.File, status string) Card {
 		FilePath:   pathutils.ToRelative(file.Path),
 		Title:      meta.Title,
 		Collection: meta.Collection,
+		Label:      meta.Label,
 		Status:     status,
 		Tags:       meta.Tags,
 		CreatedAt:  meta.CreatedAt.Format("2006-01-02"),
@@ -15,9 +15,34 @@ func orderKey(folderPath string) string {
 	return fmt.Sprintf("kanban-order/%s", folderPath)
 }
 
+// fieldOrderKey is the order key of the columns of a metadata field board (kanban widget).
+func fieldOrderKey(groupBy string) string {
+	return fmt.Sprintf("kanban-field-order/%s", groupBy)
+}
+
 // GetOrder loads the stored card order for a board folder.
 func GetOrder(folderPath string) (Order, error) {
-	data, err := configStorage.Get(orderKey(folderPath))
+	return loadOrder(orderKey(folderPath))
+}
+
+// SaveOrder persists the card order for a board folder.
+func SaveOrder(folderPath string, o Order) error {
+	return saveOrder(orderKey(folderPath), o)
+}
+
+// GetFieldOrder loads the stored card order of a board grouped by a metadata field,
+// keyed by field value instead of status.
+func GetFieldOrder(groupBy string) (Order, error) {
+	return loadOrder(fieldOrderKey(groupBy))
+}
+
+// SaveFieldOrder persists the card order of a board grouped by a metadata field.
+func SaveFieldOrder(groupBy string, o Order) error {
+	return saveOrder(fieldOrderKey(groupBy), o)
+}
+
+func loadOrder(key string) (Order, error) {
+	data, err := configStorage.Get(key)
 	if err != nil {
 		return Order{}, err
 	}
@@ -26,19 +51,18 @@ func GetOrder(folderPath string) (Order, error) {
 	}
 	var o Order
 	if err := json.Unmarshal(data, &o); err != nil {
-		logging.LogWarning(logging.KeyApp, "kanban: corrupt order for folder %s, resetting: %v", folderPath, err)
+		logging.LogWarning(logging.KeyApp, "kanban: corrupt order %s, resetting: %v", key, err)
 		return Order{}, nil
 	}
 	return o, nil
 }
 
-// SaveOrder persists the card order for a board folder.
-func SaveOrder(folderPath string, o Order) error {
+func saveOrder(key string, o Order) error {
 	data, err := json.MarshalIndent(o, "", "  ")
 	if err != nil {
 		return fmt.Errorf("kanban: marshal order failed: %w", err)
 	}
-	return configStorage.Set(orderKey(folderPath), data)
+	return configStorage.Set(key, data)
 }
 
 // ApplyOrder reorders cards according to stored order.
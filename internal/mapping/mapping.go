@@ -41,6 +41,10 @@ func GetDisplayName(dbField string) string {
 		return "kanban added at"
 	case "kanbanMovedAt":
 		return "kanban moved at"
+	case "wordCount":
+		return "word count"
+	case "readingTimeMinutes":
+		return "reading time (min)"
 	default:
 		return dbField
 	}
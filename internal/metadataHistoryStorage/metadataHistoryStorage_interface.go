@@ -0,0 +1,68 @@
+// Package metadataHistoryStorage provides the append-only change history of file metadata.
+package metadataHistoryStorage
+
+import (
+	"fmt"
+	"time"
+
+	"knov/internal/logging"
+)
+
+// Change is one changed metadata field of a file. Field is the json name of the metadata
+// field, custom fields are custom.<key>. Values are in display form, "" when unset.
+type Change struct {
+	Path      string    `json:"path"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"oldValue"`
+	NewValue  string    `json:"newValue"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MetadataHistoryStorage defines the storage backend interface.
+type MetadataHistoryStorage interface {
+	Add(changes []Change, keep int) error
+	Get(path string, limit int) ([]Change, error)
+	MovePath(oldPath, newPath string) error
+	DeleteByPath(path string) error
+	GetBackendType() string
+}
+
+var storage MetadataHistoryStorage
+
+// Init initializes metadata history storage.
+func Init(storagePath string) error {
+	var err error
+
+	storage, err = newSQLiteStorage(storagePath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize metadata history storage: %w", err)
+	}
+
+	logging.LogInfo(logging.KeyApp, "metadata history storage initialized: sqlite")
+	return nil
+}
+
+// Add appends changes and drops all but the newest keep changes of each of their paths.
+func Add(changes []Change, keep int) error {
+	return storage.Add(changes, keep)
+}
+
+// Get returns the changes of path, newest first; limit=0 means no limit.
+func Get(path string, limit int) ([]Change, error) {
+	return storage.Get(path, limit)
+}
+
+// MovePath reattaches the history of oldPath to newPath (used when a file is renamed/moved)
+func MovePath(oldPath, newPath string) error {
+	return storage.MovePath(oldPath, newPath)
+}
+
+// DeleteByPath removes the history of path (used when a file is deleted)
+func DeleteByPath(path string) error {
+	return storage.DeleteByPath(path)
+}
+
+// GetBackendType returns the storage backend type.
+func GetBackendType() string {
+	return storage.GetBackendType()
+}
@@ -0,0 +1,183 @@
+// Package metadataHistoryStorage - SQLite backend implementation
+package metadataHistoryStorage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"knov/internal/dbmigration"
+	"knov/internal/logging"
+
+	_ "modernc.org/sqlite"
+)
+
+type sqliteStorage struct {
+	db    *sql.DB
+	mutex sync.RWMutex
+}
+
+func newSQLiteStorage(storagePath string) (*sqliteStorage, error) {
+	dir := filepath.Join(storagePath, "metadatahistory")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create metadata history storage directory: %w", err)
+	}
+
+	dbPath := filepath.Join(dir, "history.db")
+
+	db, err := sql.Open("sqlite", dbPath+"?mode=rwc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metadata history database: %w", err)
+	}
+
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to set wal mode for metadata history: %v", err)
+	}
+	if _, err := db.Exec("PRAGMA synchronous=NORMAL"); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to set synchronous mode for metadata history: %v", err)
+	}
+
+	s := &sqliteStorage{db: db}
+	if err := s.initialize(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *sqliteStorage) initialize() error {
+	const version = 1
+	steps := []dbmigration.Migration{
+		{
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS metadata_history (
+					id        INTEGER PRIMARY KEY AUTOINCREMENT,
+					path      TEXT NOT NULL,
+					field     TEXT NOT NULL,
+					old_value TEXT NOT NULL,
+					new_value TEXT NOT NULL,
+					timestamp DATETIME NOT NULL
+				);
+				CREATE INDEX IF NOT EXISTS idx_metadata_history_path ON metadata_history(path);
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS metadata_history`)
+				return err
+			},
+		},
+	}
+
+	if err := dbmigration.Migrate(s.db, version, steps); err != nil {
+		return fmt.Errorf("metadata history storage migration failed: %w", err)
+	}
+
+	logging.LogDebug(logging.KeyApp, "metadata history sqlite storage ready at version %d", version)
+	return nil
+}
+
+// Add inserts the changes and prunes their paths in one transaction. Rows are ordered by
+// id rather than timestamp, so changes saved within the same clock tick keep their order.
+func (s *sqliteStorage) Add(changes []Change, keep int) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin metadata history transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var paths []string
+	for _, c := range changes {
+		if _, err := tx.Exec(
+			`INSERT INTO metadata_history (path, field, old_value, new_value, timestamp) VALUES (?, ?, ?, ?, ?)`,
+			c.Path, c.Field, c.OldValue, c.NewValue, c.Timestamp,
+		); err != nil {
+			return fmt.Errorf("failed to insert metadata history: %w", err)
+		}
+		if !slices.Contains(paths, c.Path) {
+			paths = append(paths, c.Path)
+		}
+	}
+
+	for _, path := range paths {
+		if _, err := tx.Exec(`
+			DELETE FROM metadata_history
+			WHERE path = ? AND id NOT IN (
+				SELECT id FROM metadata_history WHERE path = ? ORDER BY id DESC LIMIT ?
+			)`, path, path, keep,
+		); err != nil {
+			return fmt.Errorf("failed to prune metadata history of %s: %w", path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit metadata history: %w", err)
+	}
+
+	logging.LogDebug(logging.KeyApp, "stored %d metadata history changes", len(changes))
+	return nil
+}
+
+func (s *sqliteStorage) Get(path string, limit int) ([]Change, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	query := `SELECT path, field, old_value, new_value, timestamp FROM metadata_history WHERE path = ? ORDER BY id DESC`
+	args := []any{path}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]Change, 0)
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.Path, &c.Field, &c.OldValue, &c.NewValue, &c.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata history: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (s *sqliteStorage) MovePath(oldPath, newPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.db.Exec(`UPDATE metadata_history SET path = ? WHERE path = ?`, newPath, oldPath); err != nil {
+		return fmt.Errorf("failed to move metadata history: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStorage) DeleteByPath(path string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM metadata_history WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("failed to delete metadata history: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStorage) GetBackendType() string {
+	return "sqlite"
+}
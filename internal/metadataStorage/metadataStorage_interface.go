@@ -3,9 +3,11 @@ package metadataStorage
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"knov/internal/configStorage"
+	"knov/internal/configmanager"
 	"knov/internal/logging"
 )
 
@@ -24,6 +26,22 @@ type MetadataStorage interface {
 	Cleanup() error
 }
 
+// prefixDeleter is implemented by backends that delete every key under a prefix in a
+// single statement.
+type prefixDeleter interface {
+	DeletePrefix(prefix string) (int, error)
+}
+
+// bulkSetter is implemented by backends that store many keys in a single transaction.
+type bulkSetter interface {
+	BulkSet(entries map[string][]byte) error
+}
+
+// sqliteExporter is implemented by backends that can copy themselves into an SQLite file.
+type sqliteExporter interface {
+	ExportSQLite(path string) error
+}
+
 var storage MetadataStorage
 
 // readMarker returns the previously active backend name from configStorage, or "".
@@ -42,15 +60,20 @@ func writeMarker(provider string) {
 	}
 }
 
-// newBackend creates a MetadataStorage instance for the given provider.
-func newBackend(provider, storagePath string) (MetadataStorage, error) {
+// Open creates a MetadataStorage instance for the given provider without making it the
+// active storage, e.g. for a migration or the in-app storage suite.
+func Open(provider, storagePath string) (MetadataStorage, error) {
 	switch provider {
+	case "memory":
+		return newMemoryStorage(), nil
 	case "json":
 		return newJSONStorage(storagePath)
 	case "yaml":
 		return newYAMLStorage(storagePath)
 	case "sqlite":
 		return newSQLiteStorage(storagePath)
+	case "postgres":
+		return newPostgresStorage(configmanager.GetAppConfig().MetadataPostgresDSN)
 	default:
 		return nil, fmt.Errorf("unknown metadata storage provider: %s", provider)
 	}
@@ -103,9 +126,15 @@ func migrate(src, dst MetadataStorage) error {
 
 // Init initializes metadata storage with the specified provider.
 // If a different provider was previously active, all metadata is migrated automatically.
+// The ephemeral "memory" provider starts empty and never migrates or records itself as
+// the active backend, so the persisted metadata is left untouched.
 func Init(provider, storagePath string) error {
 	switch provider {
-	case "json", "yaml", "sqlite":
+	case "memory":
+		storage = newMemoryStorage()
+		logging.LogInfo(logging.KeyApp, "metadata storage initialized: %s", provider)
+		return nil
+	case "json", "yaml", "sqlite", "postgres":
 	default:
 		logging.LogWarning(logging.KeyApp, "unknown metadata storage provider '%s', using json", provider)
 		provider = "json"
@@ -116,11 +145,11 @@ func Init(provider, storagePath string) error {
 	if needsMigration {
 		logging.LogInfo(logging.KeyApp, "metadata storage provider changed: %s -> %s, running migration", previous, provider)
 
-		oldBackend, err := newBackend(previous, storagePath)
+		oldBackend, err := Open(previous, storagePath)
 		if err != nil {
 			logging.LogWarning(logging.KeyApp, "metadata migration: could not open old backend %s: %v", previous, err)
 		} else {
-			newB, err := newBackend(provider, storagePath)
+			newB, err := Open(provider, storagePath)
 			if err != nil {
 				return fmt.Errorf("failed to initialize new metadata storage %s: %w", provider, err)
 			}
@@ -135,7 +164,7 @@ func Init(provider, storagePath string) error {
 	}
 
 	var err error
-	storage, err = newBackend(provider, storagePath)
+	storage, err = Open(provider, storagePath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize metadata storage: %w", err)
 	}
@@ -160,6 +189,75 @@ func Delete(key string) error {
 	return storage.Delete(key)
 }
 
+// DeletePrefix removes the metadata of every key starting with prefix and returns the
+// number of deleted keys. Backends without a bulk delete remove the keys one by one.
+func DeletePrefix(prefix string) (int, error) {
+	if deleter, ok := storage.(prefixDeleter); ok {
+		return deleter.DeletePrefix(prefix)
+	}
+
+	all, err := storage.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for key := range all {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := storage.Delete(key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// BulkSet stores every entry. Backends with a transactional bulk write store all or none
+// of them, the others store the entries one by one and stop at the first failure.
+func BulkSet(entries map[string][]byte) error {
+	if setter, ok := storage.(bulkSetter); ok {
+		return setter.BulkSet(entries)
+	}
+
+	for key, data := range entries {
+		if err := storage.Set(key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportSQLite writes a consistent SQLite copy of all metadata to path, which must not exist
+// yet. The SQLite backend copies its own database, the other backends are written into a
+// temporary database with the SQLite backend's schema first, so every export has the same
+// metadata table.
+func ExportSQLite(path string) error {
+	if exporter, ok := storage.(sqliteExporter); ok {
+		return exporter.ExportSQLite(path)
+	}
+
+	all, err := storage.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp("", "knov-metadata-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmp, err := newSQLiteStorage(tmpDir)
+	if err != nil {
+		return err
+	}
+	defer tmp.Cleanup() //nolint:errcheck
+	if err := tmp.BulkSet(all); err != nil {
+		return fmt.Errorf("failed to copy metadata: %w", err)
+	}
+	return tmp.ExportSQLite(path)
+}
+
 // GetAll returns all metadata key-value pairs
 func GetAll() (map[string][]byte, error) {
 	return storage.GetAll()
@@ -0,0 +1,89 @@
+// Package metadataStorage - in-memory backend implementation
+package metadataStorage
+
+import (
+	"slices"
+	"sync"
+
+	"knov/internal/logging"
+)
+
+// memoryStorage implements MetadataStorage interface in memory.
+// Nothing is persisted: all data is lost when the storage is initialized again.
+type memoryStorage struct {
+	data  map[string][]byte
+	mutex sync.RWMutex
+}
+
+// newMemoryStorage creates a new empty in-memory metadata storage instance
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+// Get retrieves metadata by key
+func (ms *memoryStorage) Get(key string) ([]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	data, ok := ms.data[key]
+	if !ok {
+		return nil, nil
+	}
+	logging.LogDebug(logging.KeyApp, "retrieved metadata for key: %s", key)
+	return slices.Clone(data), nil
+}
+
+// Set stores metadata with key
+func (ms *memoryStorage) Set(key string, data []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.data[key] = slices.Clone(data)
+	logging.LogDebug(logging.KeyApp, "stored metadata for key: %s", key)
+	return nil
+}
+
+// Delete removes metadata by key
+func (ms *memoryStorage) Delete(key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.data, key)
+	logging.LogDebug(logging.KeyApp, "deleted metadata for key: %s", key)
+	return nil
+}
+
+// GetAll returns all metadata key-value pairs
+func (ms *memoryStorage) GetAll() (map[string][]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	result := make(map[string][]byte, len(ms.data))
+	for key, data := range ms.data {
+		result[key] = slices.Clone(data)
+	}
+	return result, nil
+}
+
+// Exists checks if metadata key exists
+func (ms *memoryStorage) Exists(key string) bool {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	_, ok := ms.data[key]
+	return ok
+}
+
+// GetBackendType returns the backend type
+func (ms *memoryStorage) GetBackendType() string {
+	return "memory"
+}
+
+// Cleanup removes all metadata held in memory
+func (ms *memoryStorage) Cleanup() error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	clear(ms.data)
+	return nil
+}
@@ -0,0 +1,327 @@
+// Package metadataStorage - PostgreSQL backend implementation
+package metadataStorage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"knov/internal/dbmigration"
+	"knov/internal/logging"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresStorage implements MetadataStorage interface using PostgreSQL, so several knov
+// instances can share one metadata store. It uses the column layout of the sqlite backend
+// with JSONB for the array, references and custom columns.
+type postgresStorage struct {
+	db *sql.DB
+}
+
+// newPostgresStorage creates a new PostgreSQL metadata storage instance for dsn
+func newPostgresStorage(dsn string) (*postgresStorage, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("postgres metadata storage needs KNOV_METADATA_POSTGRES_DSN")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	storage := &postgresStorage{db: db}
+	if err := storage.initialize(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return storage, nil
+}
+
+// initialize runs all pending migrations for this storage.
+// Bump version and append a step whenever the schema changes.
+func (ps *postgresStorage) initialize() error {
+	const version = 3
+	steps := []dbmigration.Migration{
+		{Up: postgresMigrationV1Up, Down: postgresMigrationV1Down},
+		{Up: postgresMigrationV2Up, Down: postgresMigrationV2Down},
+		{Up: postgresMigrationV3Up, Down: postgresMigrationV3Down},
+	}
+	if err := dbmigration.Migrate(ps.db, version, steps); err != nil {
+		return fmt.Errorf("metadata storage migration failed: %w", err)
+	}
+	logging.LogDebug(logging.KeyApp, "metadata postgres storage ready at version %d", version)
+	return nil
+}
+
+func postgresMigrationV1Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS metadata (
+		path TEXT PRIMARY KEY,
+		title TEXT,
+		created_at TIMESTAMPTZ,
+		last_edited TIMESTAMPTZ,
+		collection TEXT,
+		folders JSONB,
+		tags JSONB,
+		ancestor JSONB,
+		parents JSONB,
+		kids JSONB,
+		used_links JSONB,
+		links_to_here JSONB,
+		related JSONB,
+		editor TEXT,
+		size BIGINT,
+		"references" JSONB,
+		conflict_file TEXT,
+		conflict_of TEXT,
+		kanban_added_at TIMESTAMPTZ,
+		kanban_moved_at TIMESTAMPTZ,
+		custom JSONB
+	);
+	CREATE INDEX IF NOT EXISTS idx_collection ON metadata(collection);
+	CREATE INDEX IF NOT EXISTS idx_editor ON metadata(editor);
+	CREATE INDEX IF NOT EXISTS idx_tags ON metadata USING GIN (tags);
+	`)
+	return err
+}
+
+func postgresMigrationV1Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS metadata`)
+	return err
+}
+
+func postgresMigrationV2Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	ALTER TABLE metadata ADD COLUMN IF NOT EXISTS word_count INTEGER;
+	ALTER TABLE metadata ADD COLUMN IF NOT EXISTS reading_time_minutes INTEGER;
+	`)
+	return err
+}
+
+func postgresMigrationV2Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	ALTER TABLE metadata DROP COLUMN IF EXISTS word_count;
+	ALTER TABLE metadata DROP COLUMN IF EXISTS reading_time_minutes;
+	`)
+	return err
+}
+
+func postgresMigrationV3Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata ADD COLUMN IF NOT EXISTS label TEXT`)
+	return err
+}
+
+func postgresMigrationV3Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata DROP COLUMN IF EXISTS label`)
+	return err
+}
+
+// jsonbValue returns the JSON text of a metadataRow column as a JSONB parameter, NULL when empty.
+func jsonbValue(column string) any {
+	if column == "" {
+		return nil
+	}
+	return column
+}
+
+// postgresSelectColumns lists the metadata columns in metadataRow.scanTargets order.
+const postgresSelectColumns = `COALESCE(title, ''), created_at, last_edited, COALESCE(collection, ''),
+	COALESCE(folders::text, ''), COALESCE(tags::text, ''), COALESCE(ancestor::text, ''),
+	COALESCE(parents::text, ''), COALESCE(kids::text, ''), COALESCE(used_links::text, ''),
+	COALESCE(links_to_here::text, ''), COALESCE(related::text, ''),
+	COALESCE(editor, ''), COALESCE(size, 0), COALESCE("references"::text, ''),
+	COALESCE(conflict_file, ''), COALESCE(conflict_of, ''),
+	kanban_added_at, kanban_moved_at, COALESCE(custom::text, ''),
+	COALESCE(word_count, 0), COALESCE(reading_time_minutes, 0), COALESCE(label, '')`
+
+// Get retrieves metadata by key and returns as JSON
+func (ps *postgresStorage) Get(key string) ([]byte, error) {
+	query := `SELECT ` + postgresSelectColumns + ` FROM metadata WHERE path = $1`
+
+	var row metadataRow
+	err := ps.db.QueryRow(query, key).Scan(row.scanTargets()...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get metadata for key %s: %v", key, err)
+		return nil, err
+	}
+
+	data, err := row.toJSON(key)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to marshal metadata for key %s: %v", key, err)
+		return nil, err
+	}
+
+	logging.LogDebug(logging.KeyApp, "retrieved metadata for key: %s", key)
+	return data, nil
+}
+
+// postgresUpsertQuery inserts a metadata row or replaces the one stored under the same path.
+const postgresUpsertQuery = `INSERT INTO metadata (path, ` + metadataColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+	ON CONFLICT (path) DO UPDATE SET
+		title = EXCLUDED.title, created_at = EXCLUDED.created_at, last_edited = EXCLUDED.last_edited,
+		collection = EXCLUDED.collection, folders = EXCLUDED.folders, tags = EXCLUDED.tags,
+		ancestor = EXCLUDED.ancestor, parents = EXCLUDED.parents, kids = EXCLUDED.kids,
+		used_links = EXCLUDED.used_links, links_to_here = EXCLUDED.links_to_here, related = EXCLUDED.related,
+		editor = EXCLUDED.editor, size = EXCLUDED.size, "references" = EXCLUDED."references",
+		conflict_file = EXCLUDED.conflict_file, conflict_of = EXCLUDED.conflict_of,
+		kanban_added_at = EXCLUDED.kanban_added_at, kanban_moved_at = EXCLUDED.kanban_moved_at,
+		custom = EXCLUDED.custom, word_count = EXCLUDED.word_count,
+		reading_time_minutes = EXCLUDED.reading_time_minutes, label = EXCLUDED.label`
+
+// postgresUpsertArgs returns the postgresUpsertQuery parameters for a row.
+func postgresUpsertArgs(key string, row *metadataRow) []any {
+	return []any{
+		key, row.Title, row.CreatedAt, row.LastEdited, row.Collection,
+		jsonbValue(row.Folders), jsonbValue(row.Tags), jsonbValue(row.Ancestor), jsonbValue(row.Parents),
+		jsonbValue(row.Kids), jsonbValue(row.UsedLinks), jsonbValue(row.LinksToHere), jsonbValue(row.Related),
+		row.Editor, row.Size, jsonbValue(row.References), row.ConflictFile, row.ConflictOf,
+		row.KanbanAddedAt, row.KanbanMovedAt, jsonbValue(row.Custom),
+		row.WordCount, row.ReadingTimeMinutes, row.Label,
+	}
+}
+
+// Set stores metadata from JSON data
+func (ps *postgresStorage) Set(key string, data []byte) error {
+	row, err := metadataRowFromJSON(data)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to read metadata for key %s: %v", key, err)
+		return err
+	}
+
+	if _, err := ps.db.Exec(postgresUpsertQuery, postgresUpsertArgs(key, row)...); err != nil {
+		logging.LogError(logging.KeyApp, "failed to store metadata for key %s: %v", key, err)
+		return err
+	}
+
+	logging.LogDebug(logging.KeyApp, "stored metadata for key: %s", key)
+	return nil
+}
+
+// BulkSet stores many metadata entries in one transaction, all or none of them.
+func (ps *postgresStorage) BulkSet(entries map[string][]byte) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(postgresUpsertQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for key, data := range entries {
+		row, err := metadataRowFromJSON(data)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata for key %s: %w", key, err)
+		}
+		if _, err := stmt.Exec(postgresUpsertArgs(key, row)...); err != nil {
+			return fmt.Errorf("failed to store metadata for key %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logging.LogError(logging.KeyApp, "failed to commit %d metadata entries: %v", len(entries), err)
+		return err
+	}
+
+	logging.LogDebug(logging.KeyApp, "stored %d metadata entries", len(entries))
+	return nil
+}
+
+// Delete removes metadata by key
+func (ps *postgresStorage) Delete(key string) error {
+	if _, err := ps.db.Exec("DELETE FROM metadata WHERE path = $1", key); err != nil {
+		logging.LogError(logging.KeyApp, "failed to delete metadata for key %s: %v", key, err)
+		return err
+	}
+
+	logging.LogDebug(logging.KeyApp, "deleted metadata for key: %s", key)
+	return nil
+}
+
+// DeletePrefix removes the metadata of every path starting with prefix in one statement.
+func (ps *postgresStorage) DeletePrefix(prefix string) (int, error) {
+	res, err := ps.db.Exec("DELETE FROM metadata WHERE starts_with(path, $1)", prefix)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to delete metadata with prefix %s: %v", prefix, err)
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logging.LogDebug(logging.KeyApp, "deleted %d metadata entries with prefix: %s", deleted, prefix)
+	return int(deleted), nil
+}
+
+// GetAll returns all metadata key-value pairs as JSON
+func (ps *postgresStorage) GetAll() (map[string][]byte, error) {
+	rows, err := ps.db.Query(`SELECT path, ` + postgresSelectColumns + ` FROM metadata`)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get all metadata: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]byte)
+	for rows.Next() {
+		var path string
+		var row metadataRow
+		if err := rows.Scan(append([]any{&path}, row.scanTargets()...)...); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to scan metadata row: %v", err)
+			continue
+		}
+
+		data, err := row.toJSON(path)
+		if err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to marshal metadata for %s: %v", path, err)
+			continue
+		}
+		result[path] = data
+	}
+
+	if err := rows.Err(); err != nil {
+		logging.LogError(logging.KeyApp, "error iterating metadata rows: %v", err)
+		return nil, err
+	}
+
+	logging.LogDebug(logging.KeyApp, "retrieved %d metadata entries", len(result))
+	return result, nil
+}
+
+// Exists checks if metadata key exists
+func (ps *postgresStorage) Exists(key string) bool {
+	var exists bool
+	err := ps.db.QueryRow("SELECT EXISTS(SELECT 1 FROM metadata WHERE path = $1)", key).Scan(&exists)
+	return err == nil && exists
+}
+
+// GetBackendType returns the backend type
+func (ps *postgresStorage) GetBackendType() string {
+	return "postgres"
+}
+
+// Cleanup drops the metadata and schema version tables and closes the db
+func (ps *postgresStorage) Cleanup() error {
+	defer ps.db.Close()
+
+	if _, err := ps.db.Exec(`DROP TABLE IF EXISTS metadata; DROP TABLE IF EXISTS schema_version`); err != nil {
+		logging.LogError(logging.KeyApp, "postgres metadata cleanup: failed to drop tables: %v", err)
+		return err
+	}
+
+	logging.LogInfo(logging.KeyApp, "postgres metadata cleanup: dropped metadata tables")
+	return nil
+}
@@ -62,12 +62,15 @@ func newSQLiteStorage(storagePath string) (*sqliteStorage, error) {
 // initialize runs all pending migrations for this storage.
 // Bump version and append a step whenever the schema changes.
 func (ss *sqliteStorage) initialize() error {
-	const version = 4
+	const version = 7
 	steps := []dbmigration.Migration{
 		{Up: migrationV1Up, Down: migrationV1Down},
 		{Up: migrationV2Up, Down: migrationV2Down},
 		{Up: migrationV3Up, Down: migrationV3Down},
 		{Up: migrationV4Up, Down: migrationV4Down},
+		{Up: migrationV5Up, Down: migrationV5Down},
+		{Up: migrationV6Up, Down: migrationV6Down},
+		{Up: migrationV7Up, Down: migrationV7Down},
 	}
 	if err := dbmigration.Migrate(ss.db, version, steps); err != nil {
 		return fmt.Errorf("metadata storage migration failed: %w", err)
@@ -149,167 +152,174 @@ func migrationV4Down(tx *sql.Tx) error {
 	return err
 }
 
-// Get retrieves metadata by key and returns as JSON
-func (ss *sqliteStorage) Get(key string) ([]byte, error) {
-	ss.mutex.RLock()
-	defer ss.mutex.RUnlock()
+func migrationV5Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata ADD COLUMN custom TEXT`)
+	return err
+}
 
-	query := `
-	SELECT title, created_at, last_edited, collection,
-	       folders, tags, ancestor, parents, kids, used_links, links_to_here, related,
-	       editor, size, COALESCE("references", '') as "references",
-	       COALESCE(conflict_file, '') as conflict_file, COALESCE(conflict_of, '') as conflict_of,
-	       kanban_added_at, kanban_moved_at
-	FROM metadata WHERE path = ?
-	`
+func migrationV5Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata DROP COLUMN custom`)
+	return err
+}
 
-	var meta struct {
-		Title         string
-		CreatedAt     *time.Time
-		LastEdited    *time.Time
-		Collection    string
-		Folders       string
-		Tags          string
-		Ancestor      string
-		Parents       string
-		Kids          string
-		UsedLinks     string
-		LinksToHere   string
-		Related       string
-		Editor        string
-		Size          int64
-		References    string
-		ConflictFile  string
-		ConflictOf    string
-		KanbanAddedAt *time.Time
-		KanbanMovedAt *time.Time
-	}
-
-	err := ss.db.QueryRow(query, key).Scan(
-		&meta.Title, &meta.CreatedAt, &meta.LastEdited,
-		&meta.Collection, &meta.Folders, &meta.Tags, &meta.Ancestor,
-		&meta.Parents, &meta.Kids, &meta.UsedLinks, &meta.LinksToHere, &meta.Related,
-		&meta.Editor, &meta.Size, &meta.References,
-		&meta.ConflictFile, &meta.ConflictOf,
-		&meta.KanbanAddedAt, &meta.KanbanMovedAt,
-	)
+func migrationV6Up(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE metadata ADD COLUMN word_count INTEGER`); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`ALTER TABLE metadata ADD COLUMN reading_time_minutes INTEGER`)
+	return err
+}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+func migrationV6Down(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE metadata DROP COLUMN word_count`); err != nil {
+		return err
 	}
-	if err != nil {
-		logging.LogError(logging.KeyApp, "failed to get metadata for key %s: %v", key, err)
-		return nil, err
+	_, err := tx.Exec(`ALTER TABLE metadata DROP COLUMN reading_time_minutes`)
+	return err
+}
+
+func migrationV7Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata ADD COLUMN label TEXT`)
+	return err
+}
+
+func migrationV7Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE metadata DROP COLUMN label`)
+	return err
+}
+
+// metadataColumns lists the columns of the column based (sqlite, postgres) metadata tables
+// after path, in the order of metadataRow.scanTargets and metadataRow.values.
+const metadataColumns = `title, created_at, last_edited, collection,
+		folders, tags, ancestor, parents, kids, used_links, links_to_here, related,
+		editor, size, "references", conflict_file, conflict_of,
+		kanban_added_at, kanban_moved_at, custom, word_count, reading_time_minutes, label`
+
+// metadataRow is one row of a column based metadata table. Array, references and custom
+// columns hold JSON text, "" when empty.
+type metadataRow struct {
+	Title              string
+	CreatedAt          *time.Time
+	LastEdited         *time.Time
+	Collection         string
+	Folders            string
+	Tags               string
+	Ancestor           string
+	Parents            string
+	Kids               string
+	UsedLinks          string
+	LinksToHere        string
+	Related            string
+	Editor             string
+	Size               int64
+	References         string
+	ConflictFile       string
+	ConflictOf         string
+	KanbanAddedAt      *time.Time
+	KanbanMovedAt      *time.Time
+	Custom             string
+	WordCount          int
+	ReadingTimeMinutes int
+	Label              string
+}
+
+func (row *metadataRow) scanTargets() []any {
+	return []any{
+		&row.Title, &row.CreatedAt, &row.LastEdited,
+		&row.Collection, &row.Folders, &row.Tags, &row.Ancestor,
+		&row.Parents, &row.Kids, &row.UsedLinks, &row.LinksToHere, &row.Related,
+		&row.Editor, &row.Size, &row.References,
+		&row.ConflictFile, &row.ConflictOf,
+		&row.KanbanAddedAt, &row.KanbanMovedAt, &row.Custom,
+		&row.WordCount, &row.ReadingTimeMinutes, &row.Label,
 	}
+}
 
-	// convert to metadata JSON format
+func (row *metadataRow) values() []any {
+	return []any{
+		row.Title, row.CreatedAt, row.LastEdited,
+		row.Collection, row.Folders, row.Tags, row.Ancestor,
+		row.Parents, row.Kids, row.UsedLinks, row.LinksToHere, row.Related,
+		row.Editor, row.Size, row.References,
+		row.ConflictFile, row.ConflictOf,
+		row.KanbanAddedAt, row.KanbanMovedAt, row.Custom,
+		row.WordCount, row.ReadingTimeMinutes, row.Label,
+	}
+}
+
+// toJSON converts the row of key to the metadata JSON format.
+func (row *metadataRow) toJSON(key string) ([]byte, error) {
 	result := map[string]interface{}{
-		"path":       key,
-		"title":      meta.Title,
-		"collection": meta.Collection,
-		"editor":     meta.Editor,
-		"size":       meta.Size,
+		"path":               key,
+		"title":              row.Title,
+		"collection":         row.Collection,
+		"editor":             row.Editor,
+		"size":               row.Size,
+		"wordCount":          row.WordCount,
+		"readingTimeMinutes": row.ReadingTimeMinutes,
 	}
 
-	if meta.CreatedAt != nil {
-		result["createdAt"] = meta.CreatedAt.Format(time.RFC3339)
+	if row.CreatedAt != nil {
+		result["createdAt"] = row.CreatedAt.Format(time.RFC3339)
 	}
-	if meta.LastEdited != nil {
-		result["lastEdited"] = meta.LastEdited.Format(time.RFC3339)
+	if row.LastEdited != nil {
+		result["lastEdited"] = row.LastEdited.Format(time.RFC3339)
 	}
 
 	// parse JSON arrays
-	if meta.Folders != "" {
-		var folders []string
-		if err := json.Unmarshal([]byte(meta.Folders), &folders); err == nil {
-			result["folders"] = folders
-		}
-	}
-	if meta.Tags != "" {
-		var tags []string
-		if err := json.Unmarshal([]byte(meta.Tags), &tags); err == nil {
-			result["tags"] = tags
-		}
-	}
-	if meta.Ancestor != "" {
-		var ancestor []string
-		if err := json.Unmarshal([]byte(meta.Ancestor), &ancestor); err == nil {
-			result["ancestor"] = ancestor
-		}
-	}
-	if meta.Parents != "" {
-		var parents []string
-		if err := json.Unmarshal([]byte(meta.Parents), &parents); err == nil {
-			result["parents"] = parents
-		}
-	}
-	if meta.Kids != "" {
-		var kids []string
-		if err := json.Unmarshal([]byte(meta.Kids), &kids); err == nil {
-			result["kids"] = kids
-		}
-	}
-	if meta.UsedLinks != "" {
-		var usedLinks []string
-		if err := json.Unmarshal([]byte(meta.UsedLinks), &usedLinks); err == nil {
-			result["usedLinks"] = usedLinks
-		}
-	}
-	if meta.LinksToHere != "" {
-		var linksToHere []string
-		if err := json.Unmarshal([]byte(meta.LinksToHere), &linksToHere); err == nil {
-			result["linksToHere"] = linksToHere
+	for field, column := range map[string]string{
+		"folders": row.Folders, "tags": row.Tags, "ancestor": row.Ancestor, "parents": row.Parents,
+		"kids": row.Kids, "usedLinks": row.UsedLinks, "linksToHere": row.LinksToHere, "related": row.Related,
+	} {
+		if column == "" {
+			continue
 		}
-	}
-	if meta.Related != "" {
-		var related []string
-		if err := json.Unmarshal([]byte(meta.Related), &related); err == nil {
-			result["related"] = related
+		var values []string
+		if err := json.Unmarshal([]byte(column), &values); err == nil {
+			result[field] = values
 		}
 	}
 
-	if meta.References != "" {
+	if row.References != "" {
 		var refs []interface{}
-		if err := json.Unmarshal([]byte(meta.References), &refs); err == nil {
+		if err := json.Unmarshal([]byte(row.References), &refs); err == nil {
 			result["references"] = refs
 		}
 	}
-	if meta.ConflictFile != "" {
-		result["conflictFile"] = meta.ConflictFile
+	if row.ConflictFile != "" {
+		result["conflictFile"] = row.ConflictFile
 	}
-	if meta.ConflictOf != "" {
-		result["conflictOf"] = meta.ConflictOf
+	if row.ConflictOf != "" {
+		result["conflictOf"] = row.ConflictOf
 	}
-	if meta.KanbanAddedAt != nil {
-		result["kanbanAddedAt"] = meta.KanbanAddedAt.Format(time.RFC3339)
+	if row.KanbanAddedAt != nil {
+		result["kanbanAddedAt"] = row.KanbanAddedAt.Format(time.RFC3339)
 	}
-	if meta.KanbanMovedAt != nil {
-		result["kanbanMovedAt"] = meta.KanbanMovedAt.Format(time.RFC3339)
+	if row.KanbanMovedAt != nil {
+		result["kanbanMovedAt"] = row.KanbanMovedAt.Format(time.RFC3339)
 	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		logging.LogError(logging.KeyApp, "failed to marshal metadata for key %s: %v", key, err)
-		return nil, err
+	if row.Label != "" {
+		result["label"] = row.Label
+	}
+	if row.Custom != "" {
+		var custom map[string]string
+		if err := json.Unmarshal([]byte(row.Custom), &custom); err == nil {
+			result["custom"] = custom
+		}
 	}
 
-	logging.LogDebug(logging.KeyApp, "retrieved metadata for key: %s", key)
-	return data, nil
+	return json.Marshal(result)
 }
 
-// Set stores metadata from JSON data
-func (ss *sqliteStorage) Set(key string, data []byte) error {
-	ss.mutex.Lock()
-	defer ss.mutex.Unlock()
-
+// metadataRowFromJSON converts metadata JSON to a row of a column based metadata table.
+func metadataRowFromJSON(data []byte) (*metadataRow, error) {
 	if len(data) == 0 {
-		return fmt.Errorf("empty data provided")
+		return nil, fmt.Errorf("empty data provided")
 	}
 
 	var metadata map[string]interface{}
 	if err := json.Unmarshal(data, &metadata); err != nil {
-		logging.LogError(logging.KeyApp, "failed to unmarshal metadata for key %s: %v", key, err)
-		return fmt.Errorf("failed to unmarshal metadata: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
 	// helper functions
@@ -344,55 +354,99 @@ func (ss *sqliteStorage) Set(key string, data []byte) error {
 		return ""
 	}
 
-	// handle size
-	var size int64
-	if val, ok := metadata["size"]; ok {
-		if num, ok := val.(float64); ok {
-			size = int64(num)
+	row := &metadataRow{
+		Title:         getString("title"),
+		CreatedAt:     getTime("createdAt"),
+		LastEdited:    getTime("lastEdited"),
+		Collection:    getString("collection"),
+		Folders:       marshalArray("folders"),
+		Tags:          marshalArray("tags"),
+		Ancestor:      marshalArray("ancestor"),
+		Parents:       marshalArray("parents"),
+		Kids:          marshalArray("kids"),
+		UsedLinks:     marshalArray("usedLinks"),
+		LinksToHere:   marshalArray("linksToHere"),
+		Related:       marshalArray("related"),
+		Editor:        getString("editor"),
+		References:    marshalArray("references"),
+		ConflictFile:  getString("conflictFile"),
+		ConflictOf:    getString("conflictOf"),
+		KanbanAddedAt: getTime("kanbanAddedAt"),
+		KanbanMovedAt: getTime("kanbanMovedAt"),
+		Label:         getString("label"),
+	}
+
+	// handle size and word count
+	if num, ok := metadata["size"].(float64); ok {
+		row.Size = int64(num)
+	}
+	if num, ok := metadata["wordCount"].(float64); ok {
+		row.WordCount = int(num)
+	}
+	if num, ok := metadata["readingTimeMinutes"].(float64); ok {
+		row.ReadingTimeMinutes = int(num)
+	}
+
+	// handle custom key/value metadata
+	if custom, ok := metadata["custom"].(map[string]interface{}); ok && len(custom) > 0 {
+		if data, err := json.Marshal(custom); err == nil {
+			row.Custom = string(data)
 		}
 	}
 
-	// handle references
-	var referencesJSON string
-	if refs, ok := metadata["references"].([]interface{}); ok && len(refs) > 0 {
-		if data, err := json.Marshal(refs); err == nil {
-			referencesJSON = string(data)
-		}
-	}
+	return row, nil
+}
+
+// Get retrieves metadata by key and returns as JSON
+func (ss *sqliteStorage) Get(key string) ([]byte, error) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
 
 	query := `
-	INSERT OR REPLACE INTO metadata (
-		path, title, created_at, last_edited, collection,
-		folders, tags, ancestor, parents, kids, used_links, links_to_here, related,
-		editor, size, "references", conflict_file, conflict_of,
-		kanban_added_at, kanban_moved_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	SELECT title, created_at, last_edited, collection,
+	       folders, tags, ancestor, parents, kids, used_links, links_to_here, related,
+	       editor, size, COALESCE("references", '') as "references",
+	       COALESCE(conflict_file, '') as conflict_file, COALESCE(conflict_of, '') as conflict_of,
+	       kanban_added_at, kanban_moved_at, COALESCE(custom, '') as custom,
+	       COALESCE(word_count, 0), COALESCE(reading_time_minutes, 0), COALESCE(label, '')
+	FROM metadata WHERE path = ?
 	`
 
-	_, err := ss.db.Exec(query,
-		key,
-		getString("title"),
-		getTime("createdAt"),
-		getTime("lastEdited"),
-		getString("collection"),
-		marshalArray("folders"),
-		marshalArray("tags"),
-		marshalArray("ancestor"),
-		marshalArray("parents"),
-		marshalArray("kids"),
-		marshalArray("usedLinks"),
-		marshalArray("linksToHere"),
-		marshalArray("related"),
-		getString("editor"),
-		size,
-		referencesJSON,
-		getString("conflictFile"),
-		getString("conflictOf"),
-		getTime("kanbanAddedAt"),
-		getTime("kanbanMovedAt"),
-	)
+	var row metadataRow
+	err := ss.db.QueryRow(query, key).Scan(row.scanTargets()...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get metadata for key %s: %v", key, err)
+		return nil, err
+	}
 
+	data, err := row.toJSON(key)
 	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to marshal metadata for key %s: %v", key, err)
+		return nil, err
+	}
+
+	logging.LogDebug(logging.KeyApp, "retrieved metadata for key: %s", key)
+	return data, nil
+}
+
+// Set stores metadata from JSON data
+func (ss *sqliteStorage) Set(key string, data []byte) error {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	row, err := metadataRowFromJSON(data)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to read metadata for key %s: %v", key, err)
+		return err
+	}
+
+	query := `INSERT OR REPLACE INTO metadata (path, ` + metadataColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := ss.db.Exec(query, append([]any{key}, row.values()...)...); err != nil {
 		logging.LogError(logging.KeyApp, "failed to store metadata for key %s: %v", key, err)
 		return err
 	}
@@ -401,6 +455,57 @@ func (ss *sqliteStorage) Set(key string, data []byte) error {
 	return nil
 }
 
+// BulkSet stores many metadata entries in one transaction, all or none of them.
+func (ss *sqliteStorage) BulkSet(entries map[string][]byte) error {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO metadata (path, ` + metadataColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for key, data := range entries {
+		row, err := metadataRowFromJSON(data)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata for key %s: %w", key, err)
+		}
+		if _, err := stmt.Exec(append([]any{key}, row.values()...)...); err != nil {
+			return fmt.Errorf("failed to store metadata for key %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logging.LogError(logging.KeyApp, "failed to commit %d metadata entries: %v", len(entries), err)
+		return err
+	}
+
+	logging.LogDebug(logging.KeyApp, "stored %d metadata entries", len(entries))
+	return nil
+}
+
+// ExportSQLite copies the database to path with VACUUM INTO, a consistent snapshot that
+// includes the changes still in the write-ahead log.
+func (ss *sqliteStorage) ExportSQLite(path string) error {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+
+	if _, err := ss.db.Exec("VACUUM INTO ?", path); err != nil {
+		logging.LogError(logging.KeyApp, "failed to export metadata database to %s: %v", path, err)
+		return err
+	}
+	logging.LogDebug(logging.KeyApp, "exported metadata database to %s", path)
+	return nil
+}
+
 // Delete removes metadata by key
 func (ss *sqliteStorage) Delete(key string) error {
 	ss.mutex.Lock()
@@ -416,6 +521,26 @@ func (ss *sqliteStorage) Delete(key string) error {
 	return nil
 }
 
+// DeletePrefix removes the metadata of every path starting with prefix in one statement.
+// instr rather than LIKE: LIKE is case-insensitive and would need its wildcards escaped.
+func (ss *sqliteStorage) DeletePrefix(prefix string) (int, error) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	res, err := ss.db.Exec("DELETE FROM metadata WHERE instr(path, ?) = 1", prefix)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to delete metadata with prefix %s: %v", prefix, err)
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logging.LogDebug(logging.KeyApp, "deleted %d metadata entries with prefix: %s", deleted, prefix)
+	return int(deleted), nil
+}
+
 // GetAll returns all metadata key-value pairs as JSON
 func (ss *sqliteStorage) GetAll() (map[string][]byte, error) {
 	ss.mutex.RLock()
@@ -108,14 +108,17 @@ func (ys *yamlFrontmatterStorage) Get(key string) ([]byte, error) {
 
 // Set writes metadata as YAML front matter at the top of the docs file.
 // If the file does not yet exist the operation is a no-op (file must exist).
-// Media keys are silently ignored.
+// Media keys are silently ignored, any other key has no file to live in and fails.
 func (ys *yamlFrontmatterStorage) Set(key string, data []byte) error {
 	ys.mutex.Lock()
 	defer ys.mutex.Unlock()
 
 	filePath := ys.docFilePath(key)
 	if filePath == "" {
-		return nil
+		if strings.HasPrefix(key, "media/") {
+			return nil
+		}
+		return fmt.Errorf("yaml front matter: key %s is not a docs file", key)
 	}
 
 	content, err := os.ReadFile(filePath)
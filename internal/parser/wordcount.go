@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// fenced code blocks (``` / ~~~) and dokuwiki <code>/<file> blocks
+	wordCountCodeFence    = regexp.MustCompile("(?ms)^[ \t]*(```|~~~).*?^[ \t]*(```|~~~)[ \t]*$")
+	wordCountDokuwikiCode = regexp.MustCompile(`(?is)<(code|file)\b[^>]*>.*?</(code|file)>`)
+	// markdown images/links keep their text, reference definitions and autolinks are dropped
+	wordCountMarkdownLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	wordCountReferenceDef = regexp.MustCompile(`(?m)^[ \t]*\[[^\]]+\]:.*$`)
+	wordCountAutolink     = regexp.MustCompile(`<(https?|mailto):[^>]*>`)
+	// dokuwiki links keep their label, or the target when there is none; media only their caption
+	wordCountDokuwikiLink  = regexp.MustCompile(`\[\[([^\]|]*)(?:\|([^\]]*))?\]\]`)
+	wordCountDokuwikiMedia = regexp.MustCompile(`\{\{([^}|]*)(?:\|([^}]*))?\}\}`)
+	wordCountHTMLTag       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// CountWords counts the words of a markdown or dokuwiki document. Front matter, code
+// blocks, link targets, media embeds and html tags are left out, and markup tokens
+// without a letter or digit (#, *, |, ----) aren't words.
+func CountWords(content []byte) int {
+	text := string(StripFrontMatter(content))
+	text = wordCountCodeFence.ReplaceAllString(text, " ")
+	text = wordCountDokuwikiCode.ReplaceAllString(text, " ")
+	text = wordCountReferenceDef.ReplaceAllString(text, " ")
+	text = wordCountMarkdownLink.ReplaceAllString(text, " $1 ")
+	text = wordCountAutolink.ReplaceAllString(text, " ")
+	text = wordCountDokuwikiLink.ReplaceAllStringFunc(text, func(link string) string {
+		m := wordCountDokuwikiLink.FindStringSubmatch(link)
+		if strings.TrimSpace(m[2]) != "" {
+			return " " + m[2] + " "
+		}
+		return " " + m[1] + " "
+	})
+	text = wordCountDokuwikiMedia.ReplaceAllString(text, " $2 ")
+	text = wordCountHTMLTag.ReplaceAllString(text, " ")
+
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.ContainsFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			count++
+		}
+	}
+	return count
+}
@@ -2,8 +2,12 @@
 package pathutils
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"knov/internal/configmanager"
@@ -212,3 +216,50 @@ func ToMediaURL(rel string) string {
 	}
 	return "/media/" + strings.Join(parts, "/")
 }
+
+// ErrUnsafePath is returned by SafeVaultPath for paths that may point outside the data folder.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// SafeVaultPath validates a user supplied docs or media path and returns its full filesystem path.
+// It rejects ".." segments, paths that end up outside the data folder (also through symlinks)
+// and, in strict path mode, absolute paths even when they point into the data folder.
+func SafeVaultPath(input string) (string, error) {
+	if input == "" || strings.ContainsRune(input, 0) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, input)
+	}
+
+	slashed := strings.ReplaceAll(input, `\`, "/")
+	if slices.Contains(strings.Split(slashed, "/"), "..") {
+		return "", fmt.Errorf("%w: %q contains ..", ErrUnsafePath, input)
+	}
+	if configmanager.GetStrictPaths() && (strings.HasPrefix(slashed, "/") || filepath.IsAbs(input) || filepath.VolumeName(input) != "") {
+		return "", fmt.Errorf("%w: %q is absolute", ErrUnsafePath, input)
+	}
+
+	fullPath := ToFullPath(input)
+	root := resolveExisting(configmanager.GetAppConfig().DataPath)
+	rel, err := filepath.Rel(root, resolveExisting(fullPath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q is outside the data folder", ErrUnsafePath, input)
+	}
+	return fullPath, nil
+}
+
+// resolveExisting returns the absolute path with symlinks resolved for the longest part
+// of path that exists; the part that does not exist yet (e.g. a new file) is appended as is.
+func resolveExisting(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	var missing []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			slices.Reverse(missing)
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		} else if !errors.Is(err, os.ErrNotExist) || filepath.Dir(dir) == dir {
+			return abs
+		}
+		missing = append(missing, filepath.Base(dir))
+	}
+}
@@ -0,0 +1,114 @@
+// Package search - typo-tolerant fallback matching titles and filenames
+package search
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/logging"
+)
+
+// searchFilesFuzzy returns the files whose title or filename is within the searchFuzzyDistance
+// setting's edit distance of query, closest first and flagged Fuzzy. Only the cached file
+// list is scanned, never the contents. The distance is capped to a third of the query
+// length so short queries don't match nearly everything.
+func searchFilesFuzzy(query string, limit int, allFiles []files.File) []files.File {
+	queryLower := strings.ToLower(strings.TrimSpace(query))
+	maxDistance := min(configmanager.GetSearchFuzzyDistance(), utf8.RuneCountInString(queryLower)/3)
+	if maxDistance <= 0 {
+		return nil
+	}
+
+	type scored struct {
+		file     files.File
+		distance int
+	}
+	var ranked []scored
+	for _, f := range allFiles {
+		distance := fuzzyDistance(queryLower, strings.TrimSuffix(f.Name, path.Ext(f.Name)), maxDistance)
+		if f.Metadata != nil && f.Metadata.Title != "" {
+			distance = min(distance, fuzzyDistance(queryLower, f.Metadata.Title, maxDistance))
+		}
+		if distance <= maxDistance {
+			f.Fuzzy = true
+			ranked = append(ranked, scored{f, distance})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].distance < ranked[j].distance })
+
+	results := make([]files.File, 0, len(ranked))
+	for _, r := range ranked {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		results = append(results, r.file)
+	}
+	logging.LogDebug(logging.KeyApp, "fuzzy search for '%s' returned %d results", query, len(results))
+	return results
+}
+
+// fuzzyDistance returns how far the lowercase query is from text: the distance to the whole
+// text, or - when smaller - the largest distance of a query word to its closest word of
+// text. Distances above maxDistance are reported as maxDistance+1.
+func fuzzyDistance(query, text string, maxDistance int) int {
+	text = strings.ToLower(text)
+	best := levenshtein(query, text, maxDistance)
+
+	textWords := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	worst := 0
+	for _, queryWord := range strings.Fields(query) {
+		closest := maxDistance + 1
+		for _, textWord := range textWords {
+			closest = min(closest, levenshtein(queryWord, textWord, maxDistance))
+		}
+		worst = max(worst, closest)
+	}
+	if len(textWords) > 0 {
+		best = min(best, worst)
+	}
+	return best
+}
+
+// levenshtein returns the edit distance of a and b, or maxDistance+1 as soon as it is
+// certain to exceed maxDistance.
+func levenshtein(a, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > maxDistance {
+		return maxDistance + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		prev, curr = curr, prev
+	}
+	return min(prev[len(rb)], maxDistance+1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -4,6 +4,7 @@ package search
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"knov/internal/configmanager"
@@ -45,7 +46,8 @@ func IndexAllFiles() error {
 	logging.LogInfo(logging.KeySearchReindex, "checking %d files for search indexing", len(allFiles))
 
 	newTrigram := newTrigramIndex()
-	indexed, skipped := 0, 0
+	maxBytes := configmanager.GetSearchMaxIndexBytes()
+	indexed, skipped, oversized := 0, 0, 0
 	for _, file := range allFiles {
 		fullPath := pathutils.ToDocsPath(file.Path)
 
@@ -55,6 +57,19 @@ func IndexAllFiles() error {
 			continue
 		}
 
+		// files over the size limit (generated logs, data dumps, ...) are kept out of
+		// both indexes; drop any entry left over from before they grew or the limit changed
+		if maxBytes > 0 && info.Size() > maxBytes {
+			logging.LogInfo(logging.KeySearchReindex, "skipping search indexing of %s: %d bytes exceeds limit of %d", file.Path, info.Size(), maxBytes)
+			if indexedAt, err := searchStorage.GetIndexedAt(file.Path); err == nil && !indexedAt.IsZero() {
+				if err := searchStorage.DeleteIndexedContent(file.Path); err != nil {
+					logging.LogWarning(logging.KeySearchReindex, "failed to remove oversized file %s from search index: %v", file.Path, err)
+				}
+			}
+			oversized++
+			continue
+		}
+
 		// skip the FTS reindex if already indexed and unchanged, but still need
 		// its content to rebuild the trigram index below
 		if indexedAt, err := searchStorage.GetIndexedAt(file.Path); err == nil && !indexedAt.IsZero() && !info.ModTime().After(indexedAt) {
@@ -84,14 +99,24 @@ func IndexAllFiles() error {
 	}
 	replaceTrigramIndex(newTrigram)
 
-	logging.LogInfo(logging.KeySearchReindex, "search indexing complete: %d indexed, %d skipped (up to date)", indexed, skipped)
+	logging.LogInfo(logging.KeySearchReindex, "search indexing complete: %d indexed, %d skipped (up to date), %d skipped (too large)", indexed, skipped, oversized)
 	return nil
 }
 
+// searchableFiles returns the files a search looks through: the cached file list, without
+// the files in the archive tier unless includeArchived is set.
+func searchableFiles(includeArchived bool) ([]files.File, error) {
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil || includeArchived {
+		return allFiles, err
+	}
+	return slices.DeleteFunc(allFiles, func(f files.File) bool { return files.InArchiveTier(f.Metadata) }), nil
+}
+
 // SearchFilesByTitle searches only file titles/names, ignoring content.
 // Separate entry point — loads its own file list.
-func SearchFilesByTitle(query string, limit int) ([]files.File, error) {
-	allFiles, err := files.GetAllFilesCached()
+func SearchFilesByTitle(query string, limit int, includeArchived bool) ([]files.File, error) {
+	allFiles, err := searchableFiles(includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +135,24 @@ func SearchFilesByTitle(query string, limit int) ([]files.File, error) {
 	return results, nil
 }
 
-// SearchFiles performs full text + filename + tag search
-func SearchFiles(query string, limit int) ([]files.File, error) {
+// SearchFiles performs full text + filename + tag search. When nothing matches it falls back
+// to titles and filenames within the searchFuzzyDistance setting's edit distance of query.
+// Files in the archive tier are only searched with includeArchived.
+func SearchFiles(query string, limit int, includeArchived bool) ([]files.File, error) {
+	return searchFiles(query, limit, true, includeArchived)
+}
+
+// SearchFilesNoFuzzy is SearchFiles without the typo-tolerant fallback.
+func SearchFilesNoFuzzy(query string, limit int, includeArchived bool) ([]files.File, error) {
+	return searchFiles(query, limit, false, includeArchived)
+}
+
+func searchFiles(query string, limit int, fuzzy, includeArchived bool) ([]files.File, error) {
 	if query == "" {
 		return []files.File{}, nil
 	}
 
-	allFiles, err := files.GetAllFilesCached()
+	allFiles, err := searchableFiles(includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +195,10 @@ func SearchFiles(query string, limit int) ([]files.File, error) {
 		}
 	}
 
+	if len(results) == 0 && fuzzy {
+		results = searchFilesFuzzy(query, limit, allFiles)
+	}
+
 	if limit > 0 && len(results) > limit {
 		results = results[:limit]
 	}
@@ -0,0 +1,136 @@
+// Package search - search results with highlighted excerpts of the matches
+package search
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+	"knov/internal/searchStorage"
+)
+
+// SearchFilesWithSnippets searches the file contents and returns the matching files ordered
+// by relevance, each with an excerpt of the searchSnippetTokens setting's length that wraps
+// the matched terms in <mark>. Uses the FTS index; when the grep engine is configured or
+// the FTS query fails (FTS5 unavailable, query syntax) it falls back to a substring scan.
+// Files in the archive tier are only searched with includeArchived.
+func SearchFilesWithSnippets(query string, limit int, includeArchived bool) ([]searchStorage.SearchResult, error) {
+	if query == "" {
+		return []searchStorage.SearchResult{}, nil
+	}
+
+	allFiles, err := searchableFiles(includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := configmanager.GetSearchSnippetTokens()
+	if configmanager.GetSearchEngine() == "grep" {
+		return searchSnippetsFallback(query, limit, tokens, allFiles), nil
+	}
+
+	ftsLimit := max(limit*10, 100)
+	searchResults, err := searchStorage.SearchContentWithSnippets(query, ftsLimit, tokens)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "fts snippet search failed, falling back to substring search: %v", err)
+		return searchSnippetsFallback(query, limit, tokens, allFiles), nil
+	}
+
+	// only files still listed, the index can lag behind deletions
+	existing := make(map[string]bool, len(allFiles))
+	for _, f := range allFiles {
+		existing[f.Path] = true
+	}
+	results := []searchStorage.SearchResult{}
+	for _, sr := range searchResults {
+		if !existing[sr.Path] {
+			continue
+		}
+		results = append(results, sr)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// searchSnippetsFallback scans the indexed (or, failing that, the on-disk) content of every
+// file for query case-insensitively. Results are ordered as the files are listed, with
+// minus the number of matches as score.
+func searchSnippetsFallback(query string, limit, tokens int, allFiles []files.File) []searchStorage.SearchResult {
+	queryLower := strings.ToLower(query)
+	results := []searchStorage.SearchResult{}
+
+	for _, file := range allFiles {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+
+		content, err := searchStorage.GetIndexedContent(file.Path)
+		if err != nil || content == nil {
+			content, err = os.ReadFile(pathutils.ToDocsPath(file.Path))
+			if err != nil {
+				continue
+			}
+		}
+
+		text := string(content)
+		contentLower := strings.ToLower(text)
+		count := strings.Count(contentLower, queryLower)
+		// lowercasing can change byte lengths, positions only carry over when it didn't
+		if count == 0 || len(contentLower) != len(text) {
+			continue
+		}
+		results = append(results, searchStorage.SearchResult{
+			Path:    file.Path,
+			Snippet: substringSnippet(text, strings.Index(contentLower, queryLower), len(query), tokens),
+			Score:   -float64(count),
+		})
+	}
+	return results
+}
+
+// substringSnippet returns an HTML-escaped excerpt of content of about tokens words around
+// the match of matchLen bytes at hitPos, with the match wrapped in <mark>.
+func substringSnippet(content string, hitPos, matchLen, tokens int) string {
+	before := strings.Fields(content[:hitPos])
+	after := strings.Fields(content[hitPos+matchLen:])
+	// a match inside a word keeps the rest of the word attached
+	prefix, suffix := "", ""
+	if hitPos > 0 && !isSpace(content[hitPos-1]) && len(before) > 0 {
+		prefix, before = before[len(before)-1], before[:len(before)-1]
+	}
+	if end := hitPos + matchLen; end < len(content) && !isSpace(content[end]) && len(after) > 0 {
+		suffix, after = after[0], after[1:]
+	}
+
+	context := max(tokens-1, 0)
+	nBefore := min(len(before), context/2)
+	nAfter := min(len(after), context-nBefore)
+	nBefore = min(len(before), context-nAfter)
+
+	var b strings.Builder
+	if nBefore < len(before) {
+		b.WriteString("...")
+	}
+	for _, word := range before[len(before)-nBefore:] {
+		fmt.Fprintf(&b, "%s ", html.EscapeString(word))
+	}
+	fmt.Fprintf(&b, "%s<mark>%s</mark>%s", html.EscapeString(prefix), html.EscapeString(content[hitPos:hitPos+matchLen]), html.EscapeString(suffix))
+	for _, word := range after[:nAfter] {
+		fmt.Fprintf(&b, " %s", html.EscapeString(word))
+	}
+	if nAfter < len(after) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r'
+}
@@ -16,16 +16,20 @@ type SearchStorage interface {
 	DeleteIndexedContent(path string) error
 	ListAllIndexedFiles() ([]string, error)
 	SearchContent(query string, limit int) ([]SearchResult, error)
+	SearchContentWithSnippets(query string, limit, snippetTokens int) ([]SearchResult, error)
 	IndexDeletedFile(path string, content []byte) error
 	SearchDeletedContent(query string, limit int) ([]SearchResult, error)
 	GetBackendType() string
 }
 
-// SearchResult represents a search result
+// SearchResult represents a search result. Score is the bm25 rank, lower is more relevant.
+// Snippet is only set by SearchContentWithSnippets: an HTML-escaped excerpt with the
+// matched terms wrapped in <mark>.
 type SearchResult struct {
-	Path    string
-	Content []byte
-	Score   float64
+	Path    string  `json:"path"`
+	Content []byte  `json:"-"`
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score"`
 }
 
 var storage SearchStorage
@@ -80,6 +84,12 @@ func SearchContent(query string, limit int) ([]SearchResult, error) {
 	return storage.SearchContent(query, limit)
 }
 
+// SearchContentWithSnippets performs full-text search returning an excerpt of about
+// snippetTokens words around the matches of each file instead of its content
+func SearchContentWithSnippets(query string, limit, snippetTokens int) ([]SearchResult, error) {
+	return storage.SearchContentWithSnippets(query, limit, snippetTokens)
+}
+
 // IndexDeletedFile indexes a deleted file's pre-deletion content
 func IndexDeletedFile(path string, content []byte) error {
 	return storage.IndexDeletedFile(path, content)
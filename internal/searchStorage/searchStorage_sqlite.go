@@ -4,8 +4,10 @@ package searchStorage
 import (
 	"database/sql"
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -279,6 +281,60 @@ func (ss *sqliteStorage) SearchContent(query string, limit int) ([]SearchResult,
 	return results, rows.Err()
 }
 
+// snippet() marks matches with these control characters instead of <mark> so the indexed
+// content can be HTML-escaped before the marks are put in.
+const (
+	snippetMatchStart = "\x02"
+	snippetMatchEnd   = "\x03"
+)
+
+// snippetReplacer turns the match markers of an escaped snippet into <mark> tags.
+var snippetReplacer = strings.NewReplacer(snippetMatchStart, "<mark>", snippetMatchEnd, "</mark>")
+
+// SearchContentWithSnippets performs full-text search using FTS5, returning one result
+// per file with a snippet() excerpt of snippetTokens tokens (1-64) instead of the content
+func (ss *sqliteStorage) SearchContentWithSnippets(query string, limit, snippetTokens int) ([]SearchResult, error) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+
+	sqlQuery := `
+		SELECT
+			path,
+			snippet(search_index, 1, ?, ?, '...', ?) as snippet,
+			bm25(search_index) as score
+		FROM search_index
+		WHERE search_index MATCH ?
+		ORDER BY score
+		LIMIT ?
+	`
+
+	snippetTokens = min(max(snippetTokens, 1), 64)
+	rows, err := ss.db.Query(sqlQuery, snippetMatchStart, snippetMatchEnd, snippetTokens, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var result SearchResult
+		if err := rows.Scan(&result.Path, &result.Snippet, &result.Score); err != nil {
+			return nil, err
+		}
+		// the index may hold a stale duplicate row of a file, keep the best ranked one
+		if seen[result.Path] {
+			continue
+		}
+		seen[result.Path] = true
+		result.Snippet = snippetReplacer.Replace(html.EscapeString(strings.Join(strings.Fields(result.Snippet), " ")))
+		results = append(results, result)
+	}
+
+	logging.LogDebug(logging.KeyApp, "snippet search query '%s' returned %d results", query, len(results))
+	return results, rows.Err()
+}
+
 // IndexDeletedFile indexes a deleted file's pre-deletion content in the
 // separate deleted-files FTS table, so content search over deleted files
 // doesn't need to walk the commit log.
@@ -178,6 +178,9 @@ func handleAPIMoveChatMessage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "target is required"), http.StatusBadRequest)
 		return
 	}
+	if !checkSafePaths(w, r, target) {
+		return
+	}
 
 	msg, err := chat.GetByID(id)
 	if err != nil || msg == nil {
@@ -272,6 +275,9 @@ func handleAPIBulkMoveChatMessages(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "ids and target are required"), http.StatusBadRequest)
 		return
 	}
+	if !checkSafePaths(w, r, target) {
+		return
+	}
 
 	ids := strings.Split(rawIDs, ",")
 
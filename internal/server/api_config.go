@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"knov/internal/configmanager"
+	"knov/internal/files"
 	"knov/internal/git"
 	"knov/internal/logging"
 	"knov/internal/server/notify"
@@ -301,3 +303,121 @@ func handleAPIImportSettings(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("HX-Refresh", "true")
 	writeResponse(w, r, map[string]string{"status": "imported"}, "")
 }
+
+// @Summary Get metadata defaults
+// @Description Returns the defaults applied when initializing metadata for new files
+// @Tags config
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/config/metadata-defaults [get]
+func handleAPIGetMetadataDefaults(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, metadataDefaults(), "")
+}
+
+// metadataDefaults returns the configured metadata defaults of new files. The editor is the
+// file type of a new markdown file.
+func metadataDefaults() map[string]interface{} {
+	return map[string]interface{}{
+		"editor":   configmanager.GetDefaultMarkdownEditor(),
+		"tags":     configmanager.GetDefaultTags(),
+		"status":   configmanager.GetDefaultStatus(),
+		"priority": configmanager.GetDefaultPriority(),
+	}
+}
+
+// @Summary Set metadata defaults
+// @Description Sets the defaults applied when initializing metadata for new files. Only provided fields are changed.
+// @Tags config
+// @Accept application/x-www-form-urlencoded
+// @Param editor formData string false "default editor for new markdown files (e.g. toastui-editor, codemirror-editor)"
+// @Param tags formData string false "comma-separated default tags (e.g. draft), empty to clear"
+// @Param status formData string false "default kanban status, one of the kanban statuses (e.g. draft), empty to clear"
+// @Param priority formData string false "default priority custom field (low, medium or high), empty to clear"
+// @Produce json,html
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {string} string "invalid value"
+// @Router /api/config/metadata-defaults [post]
+func handleAPISetMetadataDefaults(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form data"))
+		return
+	}
+
+	if r.Form.Has("editor") {
+		if err := configmanager.DefaultMarkdownEditor.SetFromString(r.FormValue("editor")); err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid editor type"))
+			return
+		}
+	}
+	if r.Form.Has("tags") {
+		configmanager.DefaultTags.SetFromString(r.FormValue("tags")) //nolint:errcheck // string slices always parse
+	}
+	if r.Form.Has("status") {
+		if err := configmanager.DefaultStatus.SetFromString(r.FormValue("status")); err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid kanban status"))
+			return
+		}
+	}
+	if r.Form.Has("priority") {
+		if err := configmanager.DefaultPriority.SetFromString(r.FormValue("priority")); err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid priority"))
+			return
+		}
+	}
+
+	if err := configmanager.SaveSettings(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save"))
+		return
+	}
+
+	logging.LogInfo(logging.KeyApp, "metadata defaults updated: %v", metadataDefaults())
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata defaults saved"))
+	writeResponse(w, r, metadataDefaults(), "")
+}
+
+// @Summary Get filetypes
+// @Description Returns every filetype: one per editor type, then the custom filetypes of the customFiletypes setting with the editor their files open in
+// @Tags config
+// @Produce json
+// @Success 200 {array} files.Filetype
+// @Router /api/config/filetypes [get]
+func handleAPIGetFiletypes(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, files.AllFiletypes(), "")
+}
+
+// @Summary Register a custom filetype
+// @Description Adds a custom filetype to the customFiletypes setting, or changes the label and editor of a registered one. Files whose type custom field names it open in its editor.
+// @Tags config
+// @Accept application/x-www-form-urlencoded
+// @Param name formData string true "filetype name, lowercase letters, digits and dashes (e.g. recipe)"
+// @Param label formData string true "display label (e.g. Recipe)"
+// @Param editor formData string false "default editor type of its files, the textarea editor when empty"
+// @Produce json,html
+// @Success 200 {array} files.Filetype
+// @Failure 400 {string} string "invalid or built-in filetype"
+// @Router /api/config/filetypes [post]
+func handleAPIRegisterFiletype(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form data"))
+		return
+	}
+
+	name := r.FormValue("name")
+	if err := files.RegisterFiletype(name, r.FormValue("label"), files.EditorType(r.FormValue("editor"))); err != nil {
+		if errors.Is(err, files.ErrBuiltinFiletype) {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "filetype %s is built in", name))
+			return
+		}
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid filetype: %v", err))
+		return
+	}
+
+	if err := configmanager.SaveSettings(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save"))
+		return
+	}
+
+	logging.LogInfo(logging.KeyApp, "filetype %s registered", name)
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "filetype saved"))
+	writeResponse(w, r, files.AllFiletypes(), "")
+}
@@ -1,8 +1,8 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,7 +10,6 @@ import (
 
 	"knov/internal/configmanager"
 	"knov/internal/dashboard"
-	"knov/internal/filter"
 	"knov/internal/logging"
 	"knov/internal/server/render"
 	"knov/internal/translation"
@@ -38,82 +37,6 @@ func handleAPIGetDashboards(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, dashboards, html)
 }
 
-func parseWidgetsFromForm(r *http.Request) ([]dashboard.Widget, error) {
-	var widgets []dashboard.Widget
-	form := r.PostForm
-
-	// Find the highest widget index
-	maxIndex := -1
-	for key := range form {
-		if strings.HasPrefix(key, "widgets[") && strings.Contains(key, "][type]") {
-			start := strings.Index(key, "[") + 1
-			end := strings.Index(key[start:], "]")
-			if end > 0 {
-				if idx, err := strconv.Atoi(key[start : start+end]); err == nil && idx > maxIndex {
-					maxIndex = idx
-				}
-			}
-		}
-	}
-
-	// Build widgets from form data
-	for i := 0; i <= maxIndex; i++ {
-		widgetType := dashboard.WidgetType(r.FormValue(fmt.Sprintf("widgets[%d][type]", i)))
-		if widgetType == "" {
-			continue // skip empty widget types
-		}
-
-		xPos, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("widgets[%d][position][x]", i)))
-		yPos, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("widgets[%d][position][y]", i)))
-		title := r.FormValue(fmt.Sprintf("widgets[%d][title]", i))
-
-		// Build config from form fields
-		var config dashboard.WidgetConfig
-		switch widgetType {
-		case dashboard.WidgetTypeFilter:
-			filterConfig := filter.ParseFilterConfigFromForm(r, i)
-			config.Filter = &dashboard.FilterConfig{
-				Criteria: filterConfig.Criteria,
-				Logic:    filterConfig.Logic,
-				Display:  filterConfig.Display,
-				Limit:    filterConfig.Limit,
-			}
-		case dashboard.WidgetTypeFileContent:
-			filePath := r.FormValue(fmt.Sprintf("widgets[%d][config][filePath]", i))
-			config.FileContent = &dashboard.FileContentConfig{
-				FilePath: filePath,
-			}
-		case dashboard.WidgetTypeStatic:
-			format := r.FormValue(fmt.Sprintf("widgets[%d][config][format]", i))
-			content := r.FormValue(fmt.Sprintf("widgets[%d][config][content]", i))
-			config.Static = &dashboard.StaticConfig{
-				Format:  format,
-				Content: content,
-			}
-		}
-
-		// fallback: try to parse JSON config if present
-		configJSON := r.FormValue(fmt.Sprintf("widgets[%d][config]", i))
-		if configJSON != "" {
-			json.Unmarshal([]byte(configJSON), &config)
-		}
-
-		widget := dashboard.Widget{
-			ID:    fmt.Sprintf("widget-%d", i),
-			Type:  widgetType,
-			Title: title,
-			Position: dashboard.WidgetPosition{
-				X: xPos,
-				Y: yPos,
-			},
-			Config: config,
-		}
-		widgets = append(widgets, widget)
-	}
-
-	return widgets, nil
-}
-
 // @Summary Create new dashboard
 // @Description Create a new dashboard with optional widgets
 // @Tags dashboards
@@ -121,11 +44,12 @@ func parseWidgetsFromForm(r *http.Request) ([]dashboard.Widget, error) {
 // @Produce json,html
 // @Param name formData string true "Dashboard name"
 // @Param layout formData string true "Dashboard layout (oneColumn, twoColumns, threeColumns, fourColumns)"
-// @Param widgets[0][type] formData string false "Widget type (filter, filterForm, fileContent, static, tags, collections, folders)"
+// @Param widgets[0][type] formData string false "Widget type (filter, filterForm, fileContent, static, tags, collections, folders, calendar, kanban, query)"
 // @Param widgets[0][title] formData string false "Widget title"
 // @Param widgets[0][position][x] formData int false "Widget X position"
 // @Param widgets[0][position][y] formData int false "Widget Y position"
 // @Param widgets[0][config] formData string false "Widget configuration JSON"
+// @Param widgets[0][refreshSeconds] formData int false "Widget auto-refresh interval in seconds, 0 for none"
 // @Success 200 {string} string "dashboard created"
 // @Router /api/dashboards [post]
 func handleAPICreateDashboard(w http.ResponseWriter, r *http.Request) {
@@ -143,7 +67,7 @@ func handleAPICreateDashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse widgets from form data - handle both old and new format
-	widgets, err := parseWidgetsFromForm(r)
+	widgets, err := dashboard.ParseWidgetsForm(r)
 	if err != nil {
 		logging.LogError(logging.KeyApp, "failed to parse widgets: %v", err)
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse widgets"), http.StatusBadRequest)
@@ -200,6 +124,7 @@ func handleAPIGetDashboard(w http.ResponseWriter, r *http.Request) {
 // @Param widgets[0][position][x] formData int false "Widget X position"
 // @Param widgets[0][position][y] formData int false "Widget Y position"
 // @Param widgets[0][config] formData string false "Widget configuration JSON"
+// @Param widgets[0][refreshSeconds] formData int false "Widget auto-refresh interval in seconds, 0 for none"
 // @Produce json,html
 // @Success 200 {object} dashboard.Dashboard
 // @Router /api/dashboards/{id} [patch]
@@ -224,7 +149,7 @@ func handleAPIUpdateDashboard(w http.ResponseWriter, r *http.Request) {
 		dash.Layout = dashboard.Layout(layout)
 	}
 
-	widgets, err := parseWidgetsFromForm(r)
+	widgets, err := dashboard.ParseWidgetsForm(r)
 	if err != nil {
 		logging.LogError(logging.KeyApp, "failed to parse widgets: %v", err)
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse widgets"), http.StatusBadRequest)
@@ -432,6 +357,36 @@ func handleAPIRenderWidget(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(html))
 }
 
+// renderedWidget is the server-side rendered html of one dashboard widget.
+type renderedWidget struct {
+	ID   string `json:"id"`
+	HTML string `json:"html"`
+}
+
+// @Summary Render all dashboard widgets
+// @Description Renders every widget of a dashboard server-side, dashboardRenderWorkers at a time, in widget order
+// @Tags widgets
+// @Param id path string true "Dashboard ID"
+// @Produce json,html
+// @Success 200 {array} renderedWidget
+// @Failure 404 {string} string "dashboard not found"
+// @Router /api/dashboards/{id}/widgets [get]
+func handleAPIRenderDashboardWidgets(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	dash, err := dashboard.Get(id)
+	if err != nil || dash == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "dashboard not found"))
+		return
+	}
+
+	rendered := render.RenderWidgets(r.Context(), dash.Widgets, configmanager.GetDashboardRenderWorkers())
+	data := make([]renderedWidget, len(dash.Widgets))
+	for i, widget := range dash.Widgets {
+		data[i] = renderedWidget{ID: widget.ID, HTML: rendered[i]}
+	}
+	writeResponse(w, r, data, render.RenderDashboardWidgets(dash.ID, dash.Widgets, rendered))
+}
+
 // @Summary Rename dashboard
 // @Tags dashboards
 // @Accept application/x-www-form-urlencoded
@@ -474,26 +429,25 @@ func handleAPIRenameDashboard(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Export dashboard as JSON
-// @Description Export a dashboard definition as a downloadable JSON file
+// @Description Export a dashboard definition with its widgets as a downloadable, versioned JSON file ({"version": 1, "dashboard": {...}})
 // @Tags dashboards
 // @Param id path string true "Dashboard ID"
 // @Produce application/json
-// @Success 200 {object} dashboard.Dashboard
+// @Success 200 {string} string "dashboard export json"
 // @Failure 404 {string} string "dashboard not found"
 // @Router /api/dashboards/{id}/export [get]
 func handleAPIExportDashboard(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	dash, err := dashboard.Get(id)
-	if err != nil {
+	if _, err := dashboard.Get(id); err != nil {
 		logging.LogError(logging.KeyApp, "failed to get dashboard %s: %v", id, err)
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "dashboard not found"), http.StatusNotFound)
 		return
 	}
 
-	data, err := json.MarshalIndent(dash, "", "  ")
+	data, err := dashboard.Export(id)
 	if err != nil {
-		logging.LogError(logging.KeyApp, "failed to marshal dashboard %s: %v", id, err)
+		logging.LogError(logging.KeyApp, "failed to export dashboard %s: %v", id, err)
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "export failed"), http.StatusInternalServerError)
 		return
 	}
@@ -503,11 +457,39 @@ func handleAPIExportDashboard(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// @Summary Duplicate dashboard
+// @Description Create a copy of a dashboard named "<name> (copy)" with deep-copied widgets and new widget ids
+// @Tags dashboards
+// @Param id path string true "Dashboard ID"
+// @Produce json,html
+// @Success 200 {object} dashboard.Dashboard
+// @Failure 404 {string} string "dashboard not found"
+// @Failure 500 {string} string "failed to duplicate dashboard"
+// @Router /api/dashboards/{id}/duplicate [post]
+func handleAPIDuplicateDashboard(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if _, err := dashboard.Get(id); err != nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "dashboard not found"))
+		return
+	}
+
+	dash, err := dashboard.Duplicate(id)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to duplicate dashboard %s: %v", id, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to duplicate dashboard"))
+		return
+	}
+
+	writeResponse(w, r, dash, render.RenderDashboardCreated(dash.ID))
+}
+
 // @Summary Import dashboard from JSON
-// @Description Import a dashboard from an uploaded JSON file
+// @Description Import a dashboard from an uploaded JSON file written by the export endpoint. It gets a fresh id; unknown widget types are rejected.
 // @Tags dashboards
 // @Accept multipart/form-data
 // @Param file formData file true "Dashboard JSON file"
+// @Param name formData string false "Name of the imported dashboard, defaults to the exported name"
 // @Produce json,html
 // @Success 200 {string} string "dashboard imported"
 // @Failure 400 {string} string "invalid file"
@@ -525,26 +507,20 @@ func handleAPIImportDashboard(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	var dash dashboard.Dashboard
-	if err := json.NewDecoder(file).Decode(&dash); err != nil {
-		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid dashboard json"), http.StatusBadRequest)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "missing file"), http.StatusBadRequest)
 		return
 	}
 
-	// override name if provided
-	if name := r.FormValue("name"); name != "" {
-		dash.Name = name
-	}
-	// reset id so Create derives a fresh one from the (possibly new) name
-	dash.ID = ""
-	if err := dashboard.Create(&dash); err != nil {
+	dash, err := dashboard.Import(data, r.FormValue("name"))
+	if err != nil {
 		logging.LogError(logging.KeyApp, "failed to import dashboard: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to import dashboard: %v", err))
 		return
 	}
 
 	logging.LogInfo(logging.KeyApp, "imported dashboard: %s", dash.ID)
-	data := translation.SprintfForRequest(configmanager.GetLanguage(), "dashboard imported")
 	html := render.RenderDashboardCreated(dash.ID)
-	writeResponse(w, r, data, html)
+	writeResponse(w, r, translation.SprintfForRequest(configmanager.GetLanguage(), "dashboard imported"), html)
 }
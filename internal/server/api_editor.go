@@ -70,27 +70,18 @@ func handleAPIGetEditorHandler(w http.ResponseWriter, r *http.Request) {
 		// no filepath and no editor provided — use configured default for new files
 		et = defaultMarkdownEditor()
 	} else {
-		// existing file: read editor from metadata, fall back to handler detection
-		metadata, _ := files.MetaDataGet(fp)
-		if metadata != nil && metadata.Editor != "" {
-			et = metadata.Editor
-		} else {
-			handler := parser.GetParserRegistry().GetHandler(fp)
-			if handler != nil && handler.Name() != "markdown" {
-				et = files.EditorTypeTextarea
-			} else {
-				et = defaultMarkdownEditor()
-			}
-		}
+		et = editorForFile(fp)
 	}
 
-	// get file content if editing existing file
+	// get file content if editing existing file, the configured scaffold for new ones
 	var content string
 	if fp != "" {
 		fullPath := pathutils.ToDocsPath(fp)
 		if rawContent, err := contentStorage.ReadFile(fullPath); err == nil {
 			content = string(rawContent)
 		}
+	} else {
+		content = files.NewFileScaffold(et)
 	}
 
 	// render the appropriate editor
@@ -539,6 +530,9 @@ func handleAPITableEditorSave(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "missing file path"), http.StatusBadRequest)
 		return
 	}
+	if !checkSafePaths(w, r, filePath) {
+		return
+	}
 
 	headersJSON := r.FormValue("headers")
 	rowsJSON := r.FormValue("rows")
@@ -765,10 +759,26 @@ func handleAPIConvertFileToMarkdown(w http.ResponseWriter, r *http.Request) {
 }
 
 // defaultMarkdownEditor returns the configured default editor for markdown files.
-// KNOV_DEFAULT_EDITOR env var takes precedence over the user setting.
 func defaultMarkdownEditor() files.EditorType {
-	if env := configmanager.GetAppConfig().DefaultEditor; env != "" {
-		return files.EditorType(env)
+	return files.EditorType(configmanager.GetDefaultMarkdownEditor())
+}
+
+// editorForFile returns the editor an existing file opens in: the one in its metadata,
+// else the editor of its filetype, the textarea editor for files a non-markdown parser
+// handles and the default markdown editor for the rest.
+func editorForFile(fp string) files.EditorType {
+	metadata, _ := files.MetaDataGet(fp)
+	if metadata != nil && metadata.Editor != "" {
+		return metadata.Editor
+	}
+	if metadata != nil {
+		if editor := files.FiletypeEditor(metadata.Custom[files.FiletypeField]); editor != "" {
+			return editor
+		}
+	}
+	handler := parser.GetParserRegistry().GetHandler(fp)
+	if handler != nil && handler.Name() != "markdown" {
+		return files.EditorTypeTextarea
 	}
-	return files.EditorType(configmanager.DefaultMarkdownEditor.Get())
+	return defaultMarkdownEditor()
 }
@@ -0,0 +1,71 @@
+// Package server - live file change events
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/translation"
+	"knov/internal/watcher"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment, so proxies don't close it.
+const eventKeepAlive = 30 * time.Second
+
+// @Summary Stream file change events
+// @Description Server-sent event stream of files changed outside of knov (git pull, external editors), each as {"type":"file-changed"|"file-deleted","path":"docs/..."} after the file was reindexed. The number of open streams is limited by the eventStreamLimit setting.
+// @Tags system
+// @Produce text/event-stream
+// @Success 200 {object} watcher.Event
+// @Failure 500 {string} string "streaming not supported"
+// @Failure 503 {string} string "too many event streams"
+// @Router /api/events [get]
+func handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "streaming not supported"))
+		return
+	}
+
+	events, unsubscribe, err := watcher.Subscribe(configmanager.GetEventStreamLimit())
+	if errors.Is(err, watcher.ErrTooManySubscribers) {
+		logging.LogWarning(logging.KeyApp, "rejected event stream: limit of %d reached", configmanager.GetEventStreamLimit())
+		writeAPIError(w, http.StatusServiceUnavailable, translation.SprintfForRequest(configmanager.GetLanguage(), "too many event streams"))
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(eventKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-events:
+			if !ok {
+				// the watcher stopped
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logging.LogError(logging.KeyApp, "failed to encode %s event: %v", event.Type, err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
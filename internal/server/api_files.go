@@ -5,6 +5,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -140,6 +141,74 @@ func handleAPIGetFileContent(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(content.HTML))
 }
 
+// @Summary Get external editor link
+// @Description Deep link opening the file in a desktop editor, built from the editorLinkTemplate setting
+// @Tags files
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {object} map[string]string "filepath and link"
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "file not found"
+// @Router /api/files/editorlink [get]
+func handleAPIGetEditorLink(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+	if _, err := os.Stat(pathutils.ToFullPath(filePath)); err != nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "file not found"))
+		return
+	}
+
+	link, err := files.EditorLink(filePath)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to build editor link for %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to build editor link"))
+		return
+	}
+
+	data := map[string]string{
+		"filepath": filePath,
+		"link":     link,
+	}
+	writeResponse(w, r, data, render.RenderEditorLink(link))
+}
+
+// @Summary Get the parser and editor of a file
+// @Description Troubleshooting aid: the parser handler that renders the file and the editor it opens in (the one in its metadata, else derived from the parser)
+// @Tags files
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {object} map[string]string "filepath, handler and editor"
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "file not found"
+// @Router /api/files/handler [get]
+func handleAPIGetFileHandler(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+	if _, err := os.Stat(pathutils.ToFullPath(filePath)); err != nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "file not found"))
+		return
+	}
+
+	handlerName := ""
+	if handler := parser.GetParserRegistry().GetHandler(filePath); handler != nil {
+		handlerName = handler.Name()
+	}
+	editor := editorForFile(filePath)
+
+	data := map[string]string{
+		"filepath": filePath,
+		"handler":  handlerName,
+		"editor":   string(editor),
+	}
+	writeResponse(w, r, data, translation.SprintfForRequest(configmanager.GetLanguage(), "parser %s, editor %s", handlerName, editor))
+}
+
 // @Summary Get file header with link and breadcrumb
 // @Tags files
 // @Param filepath query string true "File path"
@@ -276,7 +345,8 @@ func handleAPIGetRawContent(w http.ResponseWriter, r *http.Request) {
 // @Tags files
 // @Accept application/x-www-form-urlencoded
 // @Param filepath formData string true "File path"
-// @Param content formData string true "File content"
+// @Param content formData string false "File content, new files without content start from the configured new file template"
+// @Param editor formData string false "Editor type for new files"
 // @Produce html
 // @Router /api/files/save [post]
 func handleAPIFileSave(w http.ResponseWriter, r *http.Request) {
@@ -304,6 +374,11 @@ func handleAPIFileSave(w http.ResponseWriter, r *http.Request) {
 	_, statErr := os.Stat(fullPath)
 	isNewFile := os.IsNotExist(statErr)
 
+	// new files saved without content start from the scaffold configured for their editor
+	if isNewFile && content == "" {
+		content = files.NewFileScaffold(files.EditorType(formEditor))
+	}
+
 	// create directories if they don't exist
 	if isNewFile {
 		dir := filepath.Dir(fullPath)
@@ -326,11 +401,8 @@ func handleAPIFileSave(w http.ResponseWriter, r *http.Request) {
 
 	// create metadata for new files
 	if isNewFile {
+		// an empty editor falls back to the extension/configured default in metaDataUpdate
 		editor := files.EditorType(formEditor)
-		if editor == "" {
-			editor = files.EditorTypeToastUI
-		}
-
 		metadata := &files.Metadata{
 			Path:   pathutils.ToWithPrefix(filePath),
 			Editor: editor,
@@ -351,6 +423,10 @@ func handleAPIFileSave(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if _, err := files.CacheExternalImages(metadata.Path); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to cache external images of %s: %v", filePath, err)
+		}
+
 		if err := files.MetaDataSave(metadata); err != nil {
 			logging.LogError(logging.KeyApp, "failed to save metadata for new file %s: %v", filePath, err)
 		} else {
@@ -386,6 +462,29 @@ func handleAPIFileSave(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, map[string]string{"filepath": filePath}, render.RenderStatusMessage(render.StatusOK, successMsg))
 }
 
+// @Summary Open today's journal entry
+// @Description Resolves today's entry in the configured journal folder (YYYY-MM-DD.md), creating it from the journal template if it does not exist yet, and redirects to it
+// @Tags files
+// @Produce json,html
+// @Success 200 {object} map[string]any "filepath and whether the entry was created"
+// @Failure 500 {string} string "failed to open journal entry"
+// @Router /api/files/journal/today [get]
+func handleAPIJournalToday(w http.ResponseWriter, r *http.Request) {
+	relPath, created, err := files.EnsureJournalEntry(time.Now())
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to open journal entry: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to open journal entry"))
+		return
+	}
+	if created {
+		go git.CommitFile(pathutils.ToDocsPath(relPath))
+		notify.SetFlash(notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "journal entry created"))
+	}
+
+	w.Header().Set("HX-Redirect", pathutils.ToFileURL(relPath))
+	writeResponse(w, r, map[string]any{"filepath": relPath, "created": created}, "")
+}
+
 // @Summary Cycle a todo checkbox's state in place from the rendered file view
 // @Description Advances open -> done -> cancelled -> waiting -> open for the checkbox on the given line and returns the re-rendered file content
 // @Tags files
@@ -548,7 +647,7 @@ func renderPDFSafely(content []byte) (pdf []byte, err error) {
 }
 
 // @Summary Export all files as zip
-// @Description Export all files from data directory as a zip archive
+// @Description Export all files from data directory as a zip archive, leaving out files of private collections
 // @Tags files
 // @Accept application/x-www-form-urlencoded
 // @Produce application/zip
@@ -556,69 +655,14 @@ func renderPDFSafely(content []byte) (pdf []byte, err error) {
 // @Failure 500 {string} string "export failed"
 // @Router /api/files/export/zip [post]
 func handleAPIExportAllFiles(w http.ResponseWriter, r *http.Request) {
-	dataPath := configmanager.GetAppConfig().DataPath
-
 	// create zip in memory
 	buf := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(buf)
-
-	// walk through data directory
-	err := filepath.Walk(dataPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// skip .git directory
-		if info.IsDir() && info.Name() == ".git" {
-			return filepath.SkipDir
-		}
-
-		// skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		// get relative path
-		relPath, err := filepath.Rel(dataPath, path)
-		if err != nil {
-			return err
-		}
-
-		// read file content
-		content, err := os.ReadFile(path)
-		if err != nil {
-			logging.LogWarning(logging.KeyApp, "failed to read file %s: %v", path, err)
-			return nil // skip this file but continue
-		}
-
-		// add file to zip
-		zipFile, err := zipWriter.Create(relPath)
-		if err != nil {
-			return err
-		}
-
-		_, err = zipFile.Write(content)
-		if err != nil {
-			return err
-		}
-
-		return nil
-	})
-
-	if err != nil {
+	if err := files.WriteDataArchive(buf, false); err != nil {
 		logging.LogError(logging.KeyApp, "failed to create zip archive: %v", err)
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to export files"), http.StatusInternalServerError)
 		return
 	}
 
-	// close zip writer
-	err = zipWriter.Close()
-	if err != nil {
-		logging.LogError(logging.KeyApp, "failed to close zip writer: %v", err)
-		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to export files"), http.StatusInternalServerError)
-		return
-	}
-
 	// prepare download
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
 	filename := fmt.Sprintf("knov-export_%s.zip", timestamp)
@@ -631,7 +675,7 @@ func handleAPIExportAllFiles(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Export all files with dokuwiki to markdown conversion
-// @Description Export all files from data directory as a zip archive, converting dokuwiki files to markdown
+// @Description Export all files from data directory as a zip archive, converting dokuwiki files to markdown and leaving out files of private collections
 // @Tags files
 // @Accept application/x-www-form-urlencoded
 // @Produce application/zip
@@ -669,6 +713,12 @@ func handleAPIExportAllFilesWithMarkdownConversion(w http.ResponseWriter, r *htt
 			return err
 		}
 
+		// skip files of private collections
+		if files.IsPrivate(relPath) {
+			logging.LogDebug(logging.KeyDokuwikiExport, "skip (private collection): %s", relPath)
+			return nil
+		}
+
 		// read file content
 		content, err := os.ReadFile(path)
 		if err != nil {
@@ -901,6 +951,7 @@ func handleAPIRenameFile(w http.ResponseWriter, r *http.Request) {
 		logging.LogWarning(logging.KeyApp, "failed to invalidate file history cache for %s: %v", currentPath, err)
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "successfully renamed file: %s -> %s", currentPath, newPath)
 
 	// redirect to the new file location
@@ -935,6 +986,9 @@ func handleAPIMoveFolderFile(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "target folder is required"))
 		return
 	}
+	if !checkSafePaths(w, r, targetParent) {
+		return
+	}
 
 	folderName := r.FormValue("name")
 	if folderName == "" {
@@ -945,66 +999,63 @@ func handleAPIMoveFolderFile(w http.ResponseWriter, r *http.Request) {
 	}
 	newPath := filepath.Clean(targetParent + "/" + folderName)
 
-	if newPath == currentPath {
-		writeResponse(w, r, map[string]string{"folderpath": newPath}, "")
-		return
-	}
-
-	// prevent moving a folder into itself or a descendant
-	if strings.HasPrefix(newPath+"/", currentPath+"/") {
-		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "cannot move folder into itself"))
-		return
-	}
+	moveFolder(w, r, currentPath, newPath)
+}
 
-	currentFullPath := pathutils.ToDocsPath(currentPath)
-	if _, err := os.Stat(currentFullPath); os.IsNotExist(err) {
-		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "folder does not exist"))
+// @Summary Move a folder to a new path
+// @Description Moves every file under a folder to a new folder path and rewrites all links in the vault pointing at the moved files
+// @Tags files
+// @Accept application/x-www-form-urlencoded
+// @Param folderpath formData string true "Current folder path (relative, no docs/ prefix)"
+// @Param newpath formData string true "New folder path (relative, no docs/ prefix)"
+// @Produce json,html
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "missing or invalid folder path"
+// @Failure 404 {string} string "folder does not exist"
+// @Failure 409 {string} string "target folder already exists"
+// @Router /api/files/movefolder [post]
+func handleAPIMoveFolder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form data"))
 		return
 	}
 
-	newFullPath := pathutils.ToDocsPath(newPath)
-	if _, err := os.Stat(newFullPath); err == nil {
-		writeAPIError(w, http.StatusConflict, translation.SprintfForRequest(configmanager.GetLanguage(), "folder with new name already exists"))
+	currentPath := strings.TrimSpace(r.FormValue("folderpath"))
+	newPath := strings.TrimSpace(r.FormValue("newpath"))
+	if currentPath == "" || newPath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing folder path"))
 		return
 	}
 
-	// collect all files before the move so we can update their links
-	var filesToUpdate []struct{ oldRel, newRel string }
-	_ = filepath.Walk(currentFullPath, func(p string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
-			return nil
-		}
-		oldRel := pathutils.ToRelative(p)
-		suffix := strings.TrimPrefix(p, currentFullPath)
-		newRel := pathutils.ToRelative(newFullPath + suffix)
-		filesToUpdate = append(filesToUpdate, struct{ oldRel, newRel string }{oldRel, newRel})
-		return nil
-	})
-
-	if err := os.MkdirAll(filepath.Dir(newFullPath), 0755); err != nil {
-		logging.LogError(logging.KeyApp, "failed to create parent directory for %s: %v", newFullPath, err)
-		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to create directory"))
-		return
-	}
+	moveFolder(w, r, currentPath, filepath.Clean(newPath))
+}
 
-	if err := os.Rename(currentFullPath, newFullPath); err != nil {
-		logging.LogError(logging.KeyApp, "failed to move folder %s -> %s: %v", currentPath, newPath, err)
-		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to move folder"))
+// moveFolder runs files.MoveFolder and writes the matching response, shared by
+// the move-folder (target parent) and movefolder (full new path) handlers.
+func moveFolder(w http.ResponseWriter, r *http.Request, currentPath, newPath string) {
+	if !checkSafePaths(w, r, currentPath, newPath) {
 		return
 	}
-
-	for _, f := range filesToUpdate {
-		if err := files.UpdateLinksForMovedFileNoRefresh(logging.KeyApp, f.oldRel, f.newRel); err != nil {
-			logging.LogWarning(logging.KeyApp, "failed to update links for %s -> %s: %v", f.oldRel, f.newRel, err)
+	logging.LogInfo(logging.KeyApp, "moving folder: %s -> %s", currentPath, newPath)
+
+	if err := files.MoveFolder(currentPath, newPath); err != nil {
+		switch {
+		case errors.Is(err, files.ErrFolderIntoItself):
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "cannot move folder into itself"))
+		case errors.Is(err, files.ErrFolderNotFound):
+			writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "folder does not exist"))
+		case errors.Is(err, files.ErrFolderExists):
+			writeAPIError(w, http.StatusConflict, translation.SprintfForRequest(configmanager.GetLanguage(), "folder with new name already exists"))
+		default:
+			logging.LogError(logging.KeyApp, "failed to move folder %s -> %s: %v", currentPath, newPath, err)
+			writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to move folder"))
 		}
+		return
 	}
-	if len(filesToUpdate) > 0 {
-		files.RefreshCaches()
-	}
+	pruneEmptyFoldersAfterChange()
 
-	logging.LogInfo(logging.KeyApp, "successfully moved folder: %s -> %s (%d files updated)", currentPath, newPath, len(filesToUpdate))
 	notify.SetFlash(notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "folder moved"))
-	writeResponse(w, r, map[string]string{"folderpath": newPath}, "")
+	writeResponse(w, r, map[string]string{"folderpath": filepath.ToSlash(newPath)}, "")
 }
 
 // cleanupDeletedFileMetadata deletes a file's metadata, refreshes the
@@ -1032,6 +1083,17 @@ func cleanupDeletedFileMetadataNoRefresh(fullPath string) {
 	}
 }
 
+// pruneEmptyFoldersAfterChange removes the folders a move or delete left empty
+// when the pruneEmptyFolders setting is on.
+func pruneEmptyFoldersAfterChange() {
+	if !configmanager.GetPruneEmptyFolders() {
+		return
+	}
+	if _, err := files.PruneEmptyDirs(); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to prune empty folders: %v", err)
+	}
+}
+
 // removeFileAndMetadata removes a single file from disk, then cleans up its
 // metadata and git history via cleanupDeletedFileMetadata.
 func removeFileAndMetadata(fullPath string) error {
@@ -1084,6 +1146,7 @@ func handleAPIDeleteFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "successfully deleted file: %s", filePath)
 
 	// redirect to browse or home page
@@ -1144,6 +1207,7 @@ func handleAPIDeleteFolder(w http.ResponseWriter, r *http.Request) {
 		}()
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "successfully deleted folder: %s (%d files)", folderPath, len(filesInFolder))
 
 	w.Header().Set("HX-Redirect", "/browse")
@@ -1236,6 +1300,7 @@ func handleAPIDeleteFilesBulk(w http.ResponseWriter, r *http.Request) {
 		}()
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "bulk deleted %d files from %s=%s", deleted, groupType, value)
 	notify.SetFlash(notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "deleted %d files", deleted))
 	w.Header().Set("HX-Redirect", "/browse/"+groupType)
@@ -4,6 +4,7 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"knov/internal/configmanager"
 	"knov/internal/files"
@@ -30,9 +31,14 @@ func handleAPIGetFileTree(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Get all files
+// @Description Lists the visible files, all of them or, with page or pageSize, a page at a time. X-Total-Count carries the number of files over all pages, Link the next and prev pages
 // @Tags files
-// @Param format query string false "Response format (options for HTML select options)"
+// @Param format query string false "Response format (options for HTML select options, datalist for a datalist, both unpaginated)"
+// @Param page query int false "1-based page, without page and pageSize all files are listed"
+// @Param pageSize query int false "Files per page, defaults to the fileListPageSize setting"
 // @Produce json,html
+// @Header 200 {int} X-Total-Count "Number of visible files over all pages"
+// @Header 200 {string} Link "next and prev pages"
 // @Router /api/files/list [get]
 func handleAPIGetAllFiles(w http.ResponseWriter, r *http.Request) {
 	format := r.URL.Query().Get("format")
@@ -50,21 +56,52 @@ func handleAPIGetAllFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allFiles, err := files.GetAllFilesCached()
-	if err != nil {
-		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get files"), http.StatusInternalServerError)
-		return
-	}
-
-	allFiles = files.FilterByVisibility(allFiles)
-
 	if format == "datalist" {
+		allFiles, err := visibleFilesSorted()
+		if err != nil {
+			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get files"), http.StatusInternalServerError)
+			return
+		}
+
 		html := render.RenderFilesDatalist(allFiles)
 		w.Header().Set("Content-Type", "text/html")
 		fmt.Fprint(w, html)
 		return
 	}
 
-	html := render.RenderFilesList(allFiles, r.URL.Query().Get("actions") == "true")
-	writeResponse(w, r, allFiles, html)
+	page, pageSize, paged := pageParams(r)
+	if !paged {
+		allFiles, err := visibleFilesSorted()
+		if err != nil {
+			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get files"), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(allFiles)))
+		html := render.RenderFilesList(allFiles, r.URL.Query().Get("actions") == "true")
+		writeResponse(w, r, allFiles, html)
+		return
+	}
+
+	filePage, err := files.GetAllFilesPage(page, pageSize)
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get files"), http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationHeaders(w, r, filePage.Page, filePage.PageSize, filePage.Total)
+	html := render.RenderFilesListPage(filePage, r.URL.Query().Get("actions") == "true")
+	writeResponse(w, r, filePage.Files, html)
+}
+
+// visibleFilesSorted returns the cached files left visible by the hide settings in the default
+// file list sort.
+func visibleFilesSorted() ([]files.File, error) {
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return nil, err
+	}
+	allFiles = files.FilterByVisibility(allFiles)
+	files.SortFilesDefault(allFiles)
+	return allFiles, nil
 }
@@ -2,6 +2,7 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,9 +12,12 @@ import (
 	"knov/internal/configmanager"
 	"knov/internal/filter"
 	"knov/internal/logging"
+	"knov/internal/mapping"
 	"knov/internal/server/notify"
 	"knov/internal/server/render"
 	"knov/internal/translation"
+
+	"github.com/go-chi/chi/v5"
 )
 
 // @Summary Filter files by metadata
@@ -21,14 +25,19 @@ import (
 // @Tags filter
 // @Accept application/x-www-form-urlencoded
 // @Param metadata[] formData array false "Metadata field names"
-// @Param operator[] formData array false "Filter operators (equals, contains, greater, less, in)"
+// @Param operator[] formData array false "Filter operators (equals, contains, regex, greater, less, in, under)"
 // @Param value[] formData array false "Filter values"
 // @Param action[] formData array false "Filter actions (include, exclude)"
-// @Param logic formData string false "Logic operator (and/or)" default(and)
+// @Param logic formData string false "Logic operator (and/or), defaults to the filterDefaultLogic setting"
 // @Param display formData string false "Display type (list, cards, dropdown, table)" default(list)
 // @Param limit formData int false "Maximum number of results" default(50)
+// @Param offset formData int false "Number of matching files to skip" default(0)
+// @Param page query int false "1-based page, replaces limit and offset"
+// @Param pageSize query int false "Files per page, defaults to the fileListPageSize setting"
 // @Produce json,html
 // @Success 200 {object} filter.Result
+// @Header 200 {int} X-Total-Count "Number of matching files over all pages"
+// @Header 200 {string} Link "next and prev pages, when paginated with page or pageSize"
 // @Router /api/filters [post]
 func handleAPIFilterFiles(w http.ResponseWriter, r *http.Request) {
 	logging.LogDebug(logging.KeyApp, "filter request received")
@@ -52,6 +61,7 @@ func handleAPIFilterFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, pageSize, paged := applyPageParams(r, config)
 	logging.LogDebug(logging.KeyApp, "built filter config: %+v", config)
 
 	result, err := filter.FilterFilesWithConfig(config)
@@ -62,8 +72,120 @@ func handleAPIFilterFiles(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logging.LogDebug(logging.KeyApp, "filtered %d files from %d total", len(result.Files), result.Total)
+	writeFilterPagination(w, r, result, page, pageSize, paged)
+
+	html := render.RenderFilterResult(result, config.Display, config.Columns)
+	writeResponse(w, r, result, html)
+}
+
+// @Summary Query files
+// @Description Filter files with a compact text query, e.g. "type:literature status:published tag:#ml created>2025-01-01". See filter.ParseQueryString for the syntax
+// @Tags filter
+// @Param q query string false "Text query"
+// @Param name query string false "Name of a saved query, used when q is empty"
+// @Param page query int false "1-based page, replaces the limit and offset of the query"
+// @Param pageSize query int false "Files per page, defaults to the fileListPageSize setting"
+// @Produce json,html
+// @Success 200 {object} filter.Result
+// @Header 200 {int} X-Total-Count "Number of matching files over all pages"
+// @Header 200 {string} Link "next and prev pages, when paginated with page or pageSize"
+// @Failure 400 {string} string "invalid query"
+// @Failure 404 {string} string "saved query not found"
+// @Router /api/files/query [get]
+func handleAPIQueryFiles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if name := r.URL.Query().Get("name"); strings.TrimSpace(q) == "" && name != "" {
+		saved, ok := loadSavedQuery(w, name)
+		if !ok {
+			return
+		}
+		q = saved
+	}
+	if strings.TrimSpace(q) == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing q parameter"))
+		return
+	}
+
+	config, err := filter.ParseQueryString(q)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf(translation.SprintfForRequest(configmanager.GetLanguage(), "invalid query: %v"), err))
+		return
+	}
+	page, pageSize, paged := applyPageParams(r, config)
+	logging.LogDebug(logging.KeyApp, "parsed query %q into filter config: %+v", q, config)
+
+	result, err := filter.FilterFilesWithConfig(config)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to run query %q: %v", q, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to filter files"))
+		return
+	}
+	writeFilterPagination(w, r, result, page, pageSize, paged)
+
+	writeResponse(w, r, result, render.RenderFilterResult(result, config.Display, config.Columns))
+}
+
+// applyPageParams replaces the limit and offset of config with the page of the page and
+// pageSize request parameters, if the request sets either of them.
+func applyPageParams(r *http.Request, config *filter.Config) (page, pageSize int, paged bool) {
+	page, pageSize, paged = pageParams(r)
+	if paged {
+		config.Limit, config.Offset = pageSize, (page-1)*pageSize
+	}
+	return page, pageSize, paged
+}
+
+// writeFilterPagination sets the pagination headers of a filter result: X-Total-Count always,
+// the Link pages only for requests paginated with page or pageSize.
+func writeFilterPagination(w http.ResponseWriter, r *http.Request, result *filter.Result, page, pageSize int, paged bool) {
+	if paged {
+		setPaginationHeaders(w, r, page, pageSize, result.Total)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(result.Total))
+}
+
+// filterValidationResult is the JSON response of handleAPIValidateFilter.
+type filterValidationResult struct {
+	Valid    bool                         `json:"valid"`
+	Error    string                       `json:"error,omitempty"`
+	Criteria []filter.CriterionValidation `json:"criteria"`
+}
+
+// @Summary Validate a filter config
+// @Description Checks every criterion (field exists, operator supported for the field, value parseable) without running the filter
+// @Tags filter
+// @Accept application/x-www-form-urlencoded
+// @Param metadata[] formData array false "Metadata field names"
+// @Param operator[] formData array false "Filter operators"
+// @Param value[] formData array false "Filter values"
+// @Param action[] formData array false "Filter actions (include, exclude)"
+// @Param logic formData string false "Logic operator (and/or), defaults to the filterDefaultLogic setting"
+// @Param widget_index formData int false "Dashboard widget index for widget-namespaced fields"
+// @Produce json,html
+// @Success 200 {object} filterValidationResult
+// @Router /api/filters/validate [post]
+func handleAPIValidateFilter(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"), http.StatusBadRequest)
+		return
+	}
+
+	widgetIndex := -1
+	if s := r.FormValue("widget_index"); s != "" {
+		if idx, err := strconv.Atoi(s); err == nil {
+			widgetIndex = idx
+		}
+	}
+	config := filter.ParseFilterConfigFromForm(r, widgetIndex)
+
+	result := filterValidationResult{Valid: true, Criteria: filter.ValidateCriteria(config)}
+	if err := filter.ValidateConfig(config); err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+	}
 
-	html := render.RenderFilterResult(result, config.Display)
+	html := render.RenderFilterValidation(result.Error, result.Criteria)
 	writeResponse(w, r, result, html)
 }
 
@@ -97,10 +219,10 @@ func handleAPIGetFilterCriteriaRow(w http.ResponseWriter, r *http.Request) {
 // @Accept application/x-www-form-urlencoded
 // @Param filterid formData string true "Filter identifier (name)"
 // @Param metadata[] formData array false "Metadata field names"
-// @Param operator[] formData array false "Filter operators (equals, contains, greater, less, in)"
+// @Param operator[] formData array false "Filter operators (equals, contains, regex, greater, less, in, under)"
 // @Param value[] formData array false "Filter values"
 // @Param action[] formData array false "Filter actions (include, exclude)"
-// @Param logic formData string false "Logic operator (and/or)" default(and)
+// @Param logic formData string false "Logic operator (and/or), defaults to the filterDefaultLogic setting"
 // @Produce html
 // @Success 200 {string} string "success message"
 // @Router /api/filters/save [post]
@@ -236,3 +358,276 @@ func handleAPIFilterDelete(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, `<div class="status-ok">%s</div><script>setTimeout(() => window.location.href = '/', 1000);</script>`,
 		translation.SprintfForRequest(configmanager.GetLanguage(), "filter deleted"))
 }
+
+type filterFieldInfo struct {
+	Name      string   `json:"name"`
+	Label     string   `json:"label"`
+	Operators []string `json:"operators"`
+}
+
+type filterFieldsResponse struct {
+	Fields       []filterFieldInfo `json:"fields"`
+	Actions      []string          `json:"actions"`
+	Logics       []string          `json:"logics"`
+	DefaultLogic string            `json:"defaultLogic"`
+	DisplayModes []string          `json:"displayModes"`
+}
+
+// @Summary Get filter fields
+// @Description Returns the metadata fields filters can use with their operators, the criterion actions, the logic values with the configured default, and the display modes. Custom fields (custom.<key>) are accepted as well. The html response is the field select options.
+// @Tags filter
+// @Produce json,html
+// @Success 200 {object} filterFieldsResponse
+// @Router /api/filters/fields [get]
+func handleAPIGetFilterFields(w http.ResponseWriter, r *http.Request) {
+	response := filterFieldsResponse{
+		Actions:      filter.GetActions(),
+		Logics:       filter.GetLogics(),
+		DefaultLogic: configmanager.GetFilterDefaultLogic(),
+		DisplayModes: filter.GetDisplayModes(),
+	}
+	for _, field := range filter.GetMetadataFields() {
+		response.Fields = append(response.Fields, filterFieldInfo{
+			Name:      field,
+			Label:     mapping.GetDisplayName(field),
+			Operators: filter.GetOperatorsForField(field),
+		})
+	}
+	writeResponse(w, r, response, render.RenderMetadataFieldOptions(""))
+}
+
+// @Summary List filter presets
+// @Description Returns the names of all quick filter presets
+// @Tags filter
+// @Produce json,html
+// @Success 200 {array} string
+// @Router /api/filters/presets [get]
+func handleAPIListFilterPresets(w http.ResponseWriter, r *http.Request) {
+	names, err := filter.ListPresets()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to list filter presets: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to list filter presets"))
+		return
+	}
+	writeResponse(w, r, names, render.RenderFilterPresetList(names))
+}
+
+// @Summary Save filter preset
+// @Description Creates or replaces a named quick filter preset. Presets are run on demand and have no index file.
+// @Tags filter
+// @Accept application/x-www-form-urlencoded
+// @Param name formData string true "Preset name"
+// @Param metadata[] formData array false "Metadata field names"
+// @Param operator[] formData array false "Filter operators"
+// @Param value[] formData array false "Filter values"
+// @Param action[] formData array false "Filter actions (include, exclude)"
+// @Param logic formData string false "Logic operator (and/or), defaults to the filterDefaultLogic setting"
+// @Param display formData string false "Display type" default(list)
+// @Param limit formData int false "Result limit" default(50)
+// @Param columns formData string false "Comma-separated table display columns"
+// @Produce json,html
+// @Success 200 {object} filter.Config
+// @Failure 400 {string} string "missing name or invalid filter config"
+// @Router /api/filters/presets [post]
+func handleAPISaveFilterPreset(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	config := filter.ParseFilterConfigFromForm(r, -1)
+	if err := filter.SavePreset(name, config); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save filter preset: %v", err))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset %s saved", name)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, config, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Get filter preset
+// @Description Returns the filter config of a quick filter preset
+// @Tags filter
+// @Param name path string true "Preset name"
+// @Produce json
+// @Success 200 {object} filter.Config
+// @Failure 404 {string} string "filter preset not found"
+// @Router /api/filters/presets/{name} [get]
+func handleAPIGetFilterPreset(w http.ResponseWriter, r *http.Request) {
+	config, ok := loadFilterPreset(w, chi.URLParam(r, "name"))
+	if !ok {
+		return
+	}
+	writeResponse(w, r, config, "")
+}
+
+// @Summary Delete filter preset
+// @Tags filter
+// @Param name path string true "Preset name"
+// @Produce json,html
+// @Success 200 {string} string "filter preset deleted"
+// @Failure 404 {string} string "filter preset not found"
+// @Router /api/filters/presets/{name} [delete]
+func handleAPIDeleteFilterPreset(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := filter.DeletePreset(name); err != nil {
+		if errors.Is(err, filter.ErrPresetNotFound) {
+			writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset not found"))
+			return
+		}
+		logging.LogError(logging.KeyApp, "failed to delete filter preset %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to delete filter preset"))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset %s deleted", name)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, "filter preset deleted", render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Run filter preset
+// @Description Runs a quick filter preset by name and returns the matching files in the preset's display
+// @Tags filter
+// @Param name query string true "Preset name"
+// @Produce json,html
+// @Success 200 {object} filter.Result
+// @Failure 404 {string} string "filter preset not found"
+// @Router /api/files/filter/preset [get]
+func handleAPIRunFilterPreset(w http.ResponseWriter, r *http.Request) {
+	config, ok := loadFilterPreset(w, r.URL.Query().Get("name"))
+	if !ok {
+		return
+	}
+
+	result, err := filter.FilterFilesWithConfig(config)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to run filter preset: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to filter files"))
+		return
+	}
+
+	writeResponse(w, r, result, render.RenderFilterResult(result, config.Display, config.Columns))
+}
+
+// loadFilterPreset loads the preset name, writing a 400/404/500 and returning false when it can't.
+func loadFilterPreset(w http.ResponseWriter, name string) (*filter.Config, bool) {
+	if name == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing name parameter"))
+		return nil, false
+	}
+	config, err := filter.GetPreset(name)
+	if errors.Is(err, filter.ErrPresetNotFound) {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset not found"))
+		return nil, false
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to load filter preset %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to load filter preset"))
+		return nil, false
+	}
+	return config, true
+}
+
+// @Summary List saved queries
+// @Description Returns all named text queries, see /api/files/query for the syntax
+// @Tags filter
+// @Produce json,html
+// @Success 200 {array} filter.SavedQuery
+// @Router /api/filters/queries [get]
+func handleAPIListSavedQueries(w http.ResponseWriter, r *http.Request) {
+	queries, err := filter.ListQueries()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to list saved queries: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to list saved queries"))
+		return
+	}
+	writeResponse(w, r, queries, render.RenderSavedQueryList(queries))
+}
+
+// @Summary Save query
+// @Description Creates or replaces a named text query. The query is validated but stored as text, so it is parsed again on every run.
+// @Tags filter
+// @Accept application/x-www-form-urlencoded
+// @Param name formData string true "Query name"
+// @Param q formData string true "Text query"
+// @Produce json,html
+// @Success 200 {object} filter.SavedQuery
+// @Failure 400 {string} string "missing name or invalid query"
+// @Router /api/filters/queries [post]
+func handleAPISaveQuery(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	saved := filter.SavedQuery{Name: strings.TrimSpace(r.FormValue("name")), Query: strings.TrimSpace(r.FormValue("q"))}
+	if err := filter.SaveQuery(saved.Name, saved.Query); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save query: %v", err))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "query %s saved", saved.Name)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, saved, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Get saved query
+// @Tags filter
+// @Param name path string true "Query name"
+// @Produce json
+// @Success 200 {object} filter.SavedQuery
+// @Failure 404 {string} string "saved query not found"
+// @Router /api/filters/queries/{name} [get]
+func handleAPIGetSavedQuery(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	q, ok := loadSavedQuery(w, name)
+	if !ok {
+		return
+	}
+	writeResponse(w, r, filter.SavedQuery{Name: name, Query: q}, "")
+}
+
+// @Summary Delete saved query
+// @Tags filter
+// @Param name path string true "Query name"
+// @Produce json,html
+// @Success 200 {string} string "saved query deleted"
+// @Failure 404 {string} string "saved query not found"
+// @Router /api/filters/queries/{name} [delete]
+func handleAPIDeleteSavedQuery(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := filter.DeleteQuery(name); err != nil {
+		if errors.Is(err, filter.ErrQueryNotFound) {
+			writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "saved query not found"))
+			return
+		}
+		logging.LogError(logging.KeyApp, "failed to delete saved query %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to delete saved query"))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "query %s deleted", name)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, "saved query deleted", render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// loadSavedQuery loads the saved query name, writing a 400/404/500 and returning false when it can't.
+func loadSavedQuery(w http.ResponseWriter, name string) (string, bool) {
+	if name == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing name parameter"))
+		return "", false
+	}
+	q, err := filter.GetQuery(name)
+	if errors.Is(err, filter.ErrQueryNotFound) {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "saved query not found"))
+		return "", false
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to load saved query %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to load saved query"))
+		return "", false
+	}
+	return q, true
+}
@@ -1,14 +1,14 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"knov/internal/configmanager"
-	"knov/internal/files"
 	"knov/internal/git"
 	"knov/internal/job"
+	"knov/internal/logging"
 	"knov/internal/pathutils"
 	"knov/internal/server/notify"
 	"knov/internal/server/render"
@@ -58,76 +58,97 @@ func handleAPIGetRecentlyChanged(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allFiles, err := git.GetRecentlyChangedFiles(count, offset)
+	allFiles, hasMore, err := git.RecentChanges(count, offset, collection, folder)
 	if err != nil {
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get recent files"), http.StatusInternalServerError)
 		return
 	}
 
-	unfilteredCount := len(allFiles)
-	if collection != "" || folder != "" {
-		var filtered []git.GitHistoryFile
-		for _, f := range allFiles {
-			meta, err := files.MetaDataGet(pathutils.ToWithPrefix(f.Path))
-			if err != nil || meta == nil {
-				continue
-			}
-			if collection != "" && meta.Collection != collection {
-				continue
-			}
-			if folder != "" && !pathutils.FolderContains(strings.Join(meta.Folders, "/"), folder) {
-				continue
-			}
-			filtered = append(filtered, f)
-		}
-		allFiles = filtered
-	}
-
-	hasMore := unfilteredCount == count
 	html := render.RenderGitHistoryFileList(allFiles, collection, folder, offset+count, hasMore)
 	writeResponse(w, r, allFiles, html)
 }
 
+// @Summary Get the git history of a file
+// @Description Returns the commits that changed a file, newest first, following renames. A file without git history has none.
+// @Tags git
+// @Param filepath query string true "File path"
+// @Param limit query int false "Maximum number of commits (default all)"
+// @Produce json,html
+// @Success 200 {array} git.FileVersion
+// @Failure 400 {string} string "missing filepath parameter"
+// @Router /api/git/history [get]
+func handleAPIGetGitFileHistory(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	versions, err := git.FileHistory(pathutils.ToFullPath(filePath), limit)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get git history of %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get git history"))
+		return
+	}
+
+	html := render.RenderFileVersionsList(versions, filePath, "compact", false)
+	writeResponse(w, r, versions, html)
+}
+
 // @Summary Push to remote
-// @Description Manually trigger a git push to the configured remote
+// @Description Push the configured branch to the remote and wait for it
 // @Tags git
 // @Produce json,html
-// @Success 200 {string} string "push triggered"
+// @Success 200 {string} string "push completed"
 // @Router /api/git/push [post]
 func handleAPIGitPush(w http.ResponseWriter, r *http.Request) {
 	if err := job.RunGitPush(); err != nil {
-		level := notify.LevelError
-		if configmanager.GetGitRemote() == "" {
-			level = notify.LevelWarning
-		}
-		notify.SetHeader(w, level, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
-		writeResponse(w, r, nil, render.RenderStatusMessage(render.StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error())))
+		writeGitSyncError(w, r, err)
 		return
 	}
-	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "push triggered"))
-	writeResponse(w, r, map[string]string{"status": "push triggered"}, "")
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "push completed"))
+	writeResponse(w, r, map[string]string{"status": "push completed"}, "")
+}
+
+// gitConflictResponse is the JSON response of a pull whose branches diverged.
+type gitConflictResponse struct {
+	Error string   `json:"error"`
+	Files []string `json:"files"`
 }
 
 // @Summary Pull from remote
-// @Description Manually trigger a git pull --rebase from the configured remote
+// @Description Fast-forward the local branch to the configured remote, then reindex the metadata of the pulled files. Diverged branches fail with 409 and the files changed on both sides
 // @Tags git
 // @Produce json,html
 // @Success 200 {string} string "pull completed"
+// @Failure 409 {object} gitConflictResponse
 // @Router /api/git/pull [post]
 func handleAPIGitPull(w http.ResponseWriter, r *http.Request) {
 	if err := job.RunGitPull(); err != nil {
-		level := notify.LevelError
-		if configmanager.GetGitRemote() == "" {
-			level = notify.LevelWarning
+		var conflict *git.ConflictError
+		if errors.As(err, &conflict) {
+			notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), "git pull failed: merge conflict"))
+			writeResponseStatus(w, r, http.StatusConflict, gitConflictResponse{Error: conflict.Error(), Files: conflict.Files}, render.RenderGitConflict(conflict.Files))
+			return
 		}
-		notify.SetHeader(w, level, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
-		writeResponse(w, r, nil, render.RenderStatusMessage(render.StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error())))
+		writeGitSyncError(w, r, err)
 		return
 	}
 	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "pull completed"))
 	writeResponse(w, r, map[string]string{"status": "pull completed"}, "")
 }
 
+// writeGitSyncError notifies about a failed push or pull, as a warning when no remote is configured.
+func writeGitSyncError(w http.ResponseWriter, r *http.Request, err error) {
+	level := notify.LevelError
+	if errors.Is(err, git.ErrNoRemote) {
+		level = notify.LevelWarning
+	}
+	notify.SetHeader(w, level, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+	writeResponse(w, r, nil, render.RenderStatusMessage(render.StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error())))
+}
+
 // @Summary Test git SSH auth (debug)
 // @Description Tests SSH/HTTPS authentication against the configured remote and logs the result
 // @Tags git
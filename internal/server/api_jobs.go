@@ -0,0 +1,64 @@
+// Package server - queued job API handlers
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"knov/internal/configmanager"
+	"knov/internal/job"
+	"knov/internal/logging"
+	"knov/internal/server/notify"
+	"knov/internal/server/render"
+	"knov/internal/translation"
+)
+
+// @Summary Queue a job
+// @Description Queues a long operation on the job worker and returns its id right away; poll GET /api/jobs/{id} for the status. Operations: rebuild, reindex, search-reindex, filter-reindex, backup, media-cleanup, cronjob.
+// @Tags system
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param operation formData string true "operation to run"
+// @Success 202 {object} job.QueuedJob
+// @Failure 400 {string} string "unknown job operation"
+// @Failure 503 {string} string "job queue is full"
+// @Router /api/jobs [post]
+func handleAPIEnqueueJob(w http.ResponseWriter, r *http.Request) {
+	operation := r.FormValue("operation")
+	queued, err := job.Enqueue(operation)
+	switch {
+	case errors.Is(err, job.ErrUnknownOperation):
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "unknown job operation %s, expected one of %v", operation, job.QueueOperations()))
+		return
+	case errors.Is(err, job.ErrQueueFull):
+		logging.LogWarning(logging.KeyApp, "rejected %s job: queue is full", operation)
+		writeAPIError(w, http.StatusServiceUnavailable, translation.SprintfForRequest(configmanager.GetLanguage(), "job queue is full"))
+		return
+	case err != nil:
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to queue job"))
+		return
+	}
+
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "%s job queued", operation))
+	w.Header().Set("Location", "/api/jobs/"+queued.ID)
+	writeResponseStatus(w, r, http.StatusAccepted, queued, render.RenderQueuedJob(queued))
+}
+
+// @Summary Get a queued job
+// @Description Returns the status (queued, running, ok, error), progress (0-100) and result of a job queued with POST /api/jobs. Jobs interrupted by a restart are reported as error.
+// @Tags system
+// @Produce json,html
+// @Param id path string true "job id"
+// @Success 200 {object} job.QueuedJob
+// @Failure 404 {string} string "job not found"
+// @Router /api/jobs/{id} [get]
+func handleAPIGetQueuedJob(w http.ResponseWriter, r *http.Request) {
+	queued, err := job.GetQueuedJob(chi.URLParam(r, "id"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "job not found"))
+		return
+	}
+	writeResponse(w, r, queued, render.RenderQueuedJob(queued))
+}
@@ -4,10 +4,12 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"knov/internal/configmanager"
 	"knov/internal/files"
+	"knov/internal/logging"
 	"knov/internal/pathutils"
 	"knov/internal/search"
 	"knov/internal/server/render"
@@ -70,6 +72,17 @@ func handleAPIGetAncestors(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, metadata.Ancestor, html)
 }
 
+// @Summary Get hierarchy problems
+// @Description Lists the files whose ancestor could not be computed because a parent chain loops (cycle) or has more files than the maxHierarchyDepth setting (too-deep), with the followed chain. Updated whenever ancestors are computed.
+// @Tags links
+// @Produce json,html
+// @Success 200 {array} files.HierarchyProblem
+// @Router /api/links/hierarchy/problems [get]
+func handleAPIGetHierarchyProblems(w http.ResponseWriter, r *http.Request) {
+	problems := files.GetHierarchyProblems()
+	writeResponse(w, r, problems, render.RenderHierarchyProblems(problems))
+}
+
 // @Summary Get kids links for a file
 // @Tags links
 // @Param filepath query string true "File path"
@@ -265,6 +278,53 @@ func handleAPIGetRelatedFiles(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, paths, render.RenderRelatedFiles(paths))
 }
 
+// @Summary Get the link graph
+// @Description Returns the link network of all files as nodes and directed edges (relation link or parent) for graph visualizations. With root, only the files within depth hops of root (following edges both ways) are returned. With format=dot the graph is returned in the GraphViz DOT language.
+// @Tags links
+// @Param root query string false "File path of the root note"
+// @Param depth query int false "Hops from root, used with root" default(1)
+// @Param format query string false "dot for GraphViz DOT instead of json or html"
+// @Produce json,html,plain
+// @Success 200 {object} files.LinkGraph
+// @Failure 400 {string} string "invalid depth or format"
+// @Failure 404 {string} string "root not found"
+// @Router /api/links/graph [get]
+func handleAPIGetLinkGraph(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "dot" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid format"))
+		return
+	}
+
+	graph, err := files.BuildLinkGraph()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to build link graph: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to build link graph"))
+		return
+	}
+
+	if root := r.URL.Query().Get("root"); root != "" {
+		depth := 1
+		if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+			if depth, err = strconv.Atoi(depthStr); err != nil || depth < 0 {
+				writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid depth"))
+				return
+			}
+		}
+		if graph, err = graph.Subgraph(root, depth); err != nil {
+			writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "root not found"))
+			return
+		}
+	}
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		fmt.Fprint(w, graph.DOT())
+		return
+	}
+	writeResponse(w, r, graph, render.RenderLinkGraph(graph))
+}
+
 // @Summary Get live diff between a file and its conflict copy
 // @Description Compares current file on disk with a .conflict.md copy using text diff
 // @Tags links
@@ -44,6 +44,9 @@ func handleAPIMediaUpload(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "save document first to enable media uploads"), http.StatusBadRequest)
 		return
 	}
+	if !checkSafePaths(w, r, contextPath) {
+		return
+	}
 
 	// prevent uploads to unsaved files (context_path like "new")
 	if contextPath == "new" || strings.HasPrefix(contextPath, "new/") {
@@ -285,6 +288,7 @@ func handleAPIDeleteMedia(w http.ResponseWriter, r *http.Request) {
 		// don't fail the whole operation, just log warning
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "successfully deleted media file: %s", fullMediaPath)
 
 	// return updated media list with current filter preserved
@@ -517,6 +521,7 @@ func handleAPIMediaRename(w http.ResponseWriter, r *http.Request) {
 	if err := files.MoveMediaMetadata(oldMediaPath, newMediaPath); err != nil {
 		logging.LogWarning(logging.KeyApp, "media rename: failed to move metadata %s -> %s: %v", oldMediaPath, newMediaPath, err)
 	}
+	pruneEmptyFoldersAfterChange()
 
 	logging.LogInfo(logging.KeyApp, "media renamed: %s -> %s", oldMediaPath, newMediaPath)
 
@@ -2,23 +2,29 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"knov/internal/configmanager"
+	"knov/internal/dashboard"
 	"knov/internal/files"
 	"knov/internal/filter"
 	"knov/internal/git"
 	"knov/internal/job"
 	"knov/internal/kanban"
 	"knov/internal/logging"
+	"knov/internal/metadataStorage"
 	"knov/internal/pathutils"
 	"knov/internal/server/notify"
 	"knov/internal/server/render"
@@ -168,6 +174,58 @@ func applyBulkPatch(current *files.Metadata, p bulkUpdatePatch) error {
 	return nil
 }
 
+type bulkSetRequest struct {
+	Paths   []string              `json:"paths"`
+	Changes files.MetadataChanges `json:"changes"`
+}
+
+// @Summary Set the same metadata on many files
+// @Description Applies the same partial metadata to every listed path in one storage write. Like the single file save only the given fields change: tags replaces the tags, status the kanban status tag, custom sets its keys (an empty value removes one), parents, editor and label replace the field. Returns whether each path was updated.
+// @Tags metadata
+// @Accept json
+// @Produce json,html
+// @Param body body bulkSetRequest true "Paths and changes"
+// @Success 200 {array} files.BulkSetResult
+// @Failure 400 {string} string "invalid json, no paths, no changes or invalid changes"
+// @Router /api/metadata/bulk [post]
+func handleAPIBulkSetMetadata(w http.ResponseWriter, r *http.Request) {
+	var req bulkSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid json"))
+		return
+	}
+	if len(req.Paths) == 0 {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "no paths provided"))
+		return
+	}
+	if !checkSafePaths(w, r, append(slices.Clone(req.Paths), req.Changes.Parents...)...) {
+		return
+	}
+	if req.Changes.IsEmpty() {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "no changes provided"))
+		return
+	}
+	if err := req.Changes.Validate(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results := files.MetaDataBulkSet(req.Paths, req.Changes)
+
+	updated := 0
+	for _, result := range results {
+		if result.Success {
+			updated++
+		}
+	}
+	level := notify.LevelSuccess
+	if updated < len(results) {
+		level = notify.LevelWarning
+	}
+	notify.SetHeader(w, level, translation.SprintfForRequest(configmanager.GetLanguage(), "%d of %d files updated", updated, len(results)))
+	writeResponse(w, r, results, render.RenderMetadataBulkSetHTML(results))
+}
+
 // @Summary Get metadata for a single file
 // @Description Get metadata for a file using filepath query parameter. Supports both media/ and docs/ paths.
 // @Tags metadata
@@ -221,35 +279,101 @@ func handleAPIGetMetadata(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Set metadata for a single file
-// @Description Set metadata for a file using JSON payload
+// @Description Set metadata for a file using JSON payload. With the strictMetadataJSON setting (default) unknown fields, invalid editors and invalid kanban status tags are rejected.
 // @Tags metadata
 // @Accept json
 // @Produce json,html
 // @Param metadata body files.Metadata true "Metadata object"
 // @Success 200 {string} string "metadata saved"
-// @Failure 400 {string} string "invalid json or missing path"
+// @Failure 400 {string} string "invalid json, unknown field, invalid enum value or missing path"
 // @Failure 500 {string} string "failed to save metadata"
 // @Router /api/metadata [post]
 func handleAPISetMetadata(w http.ResponseWriter, r *http.Request) {
-	var metadata files.Metadata
+	metadata, ok := decodeMetadataJSON(w, r)
+	if !ok {
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&metadata); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if err := files.MetaDataSave(metadata); err != nil {
+		http.Error(w, "failed to save metadata", http.StatusInternalServerError)
 		return
 	}
 
-	if metadata.Path == "" {
-		http.Error(w, "path is required", http.StatusBadRequest)
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata saved"))
+	writeResponse(w, r, "metadata saved", "")
+}
+
+// @Summary Delete the metadata of all files under a path prefix
+// @Description Deletes the metadata of every file whose path starts with prefix (e.g. foo/ for all files of the folder foo) and drops them from the kids and parents of the remaining files. The files themselves are left alone.
+// @Tags metadata
+// @Produce json,html
+// @Param prefix query string true "Path prefix (with or without media/docs prefix)"
+// @Success 200 {object} map[string]int "count of deleted entries"
+// @Failure 400 {string} string "missing prefix parameter"
+// @Failure 500 {string} string "failed to delete metadata"
+// @Router /api/metadata [delete]
+func handleAPIDeleteMetadataPrefix(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if pathutils.ToRelative(prefix) == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing prefix parameter"))
 		return
 	}
 
-	if err := files.MetaDataSave(&metadata); err != nil {
-		http.Error(w, "failed to save metadata", http.StatusInternalServerError)
+	count, err := files.MetaDataDeletePrefix(prefix)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to delete metadata under %s: %v", prefix, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to delete metadata"))
 		return
 	}
 
-	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata saved"))
-	writeResponse(w, r, "metadata saved", "")
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "deleted metadata of %d files", count)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, map[string]int{"count": count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Preview merged metadata for a single file
+// @Description Returns the metadata that POST /api/metadata would store for the same payload, without saving anything. Fields that are empty in the payload keep their stored values; collection, folders, size and links are always derived.
+// @Tags metadata
+// @Accept json
+// @Produce json,html
+// @Param metadata body files.Metadata true "Metadata object"
+// @Success 200 {object} files.Metadata
+// @Failure 400 {string} string "invalid json, unknown field, invalid enum value or missing path"
+// @Router /api/metadata/preview [post]
+func handleAPIPreviewMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := decodeMetadataJSON(w, r)
+	if !ok {
+		return
+	}
+
+	merged := files.MetaDataPreview(metadata)
+	writeResponse(w, r, merged, render.RenderFileMetadataSimple(merged))
+}
+
+// decodeMetadataJSON decodes and validates a metadata JSON body, writing a 400 and
+// returning false when it is rejected.
+func decodeMetadataJSON(w http.ResponseWriter, r *http.Request) (*files.Metadata, bool) {
+	metadata, err := files.DecodeMetadataJSON(r.Body)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid json: %v", err))
+		return nil, false
+	}
+
+	if metadata.Path == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "path is required"))
+		return nil, false
+	}
+	if !checkSafePaths(w, r, append([]string{metadata.Path}, metadata.Parents...)...) {
+		return nil, false
+	}
+
+	if configmanager.GetStrictMetadataJSON() {
+		if err := files.ValidateMetadataEnums(metadata); err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid metadata: %v", err))
+			return nil, false
+		}
+	}
+	return metadata, true
 }
 
 // @Summary Initialize/Rebuild metadata for all files
@@ -274,6 +398,26 @@ func handleAPIRebuildMetadata(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, map[string]string{"status": "metadata initialized"}, "")
 }
 
+// @Summary Refresh file times from disk
+// @Description Re-stats every file and updates lastEdited and size (and createdAt when unset) to match disk, e.g. after a git checkout. Manual fields are not touched.
+// @Tags metadata
+// @Produce json,html
+// @Success 200 {object} map[string]int "count of updated files"
+// @Failure 500 {string} string "failed to refresh file times"
+// @Router /api/metadata/refreshtimes [post]
+func handleAPIRefreshMetadataTimes(w http.ResponseWriter, r *http.Request) {
+	count, err := files.MetaDataRefreshTimes()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to refresh file times: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to refresh file times"))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "file times refreshed for %d files", count)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, map[string]int{"count": count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
 // @Summary Rebuild metadata links for a single file
 // @Description Rebuilds metadata links (ancestors, kids, usedLinks, linksToHere) for one file
 // @Tags metadata
@@ -301,6 +445,41 @@ func handleAPIRebuildFileMetadata(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, map[string]string{"status": "metadata links rebuilt"}, "")
 }
 
+// @Summary Recompute the derived metadata of a file
+// @Description Refreshes the fields derived from the file's path and content (folders, collection, title, word count, used links, ancestors, size) while keeping the manually set ones
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Success 200 {object} files.Metadata
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "metadata not found"
+// @Failure 500 {string} string "failed to save metadata"
+// @Router /api/metadata/recompute [post]
+func handleAPIRecomputeFileMetadata(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	filePath := r.FormValue("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	metadata, err := files.MetaDataRecompute(filePath)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to recompute metadata of %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save metadata"))
+		return
+	}
+	if metadata == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	}
+
+	msg := translation.SprintfForRequest(configmanager.GetLanguage(), "metadata recomputed")
+	notify.SetHeader(w, notify.LevelSuccess, msg)
+	writeResponse(w, r, metadata, render.RenderStatusMessage(render.StatusOK, msg))
+}
+
 // @Summary Export all metadata
 // @Description Export all metadata as JSON or CSV file
 // @Tags metadata
@@ -323,24 +502,146 @@ func handleAPIExportMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeMetadataExport(w, allMetadata, format, "metadata_export")
+}
+
+// @Summary Export metadata as SQLite database
+// @Description Downloads a consistent SQLite copy of all metadata for querying with SQL. The SQLite backend is copied as is, the other backends are exported into a database with the same metadata table.
+// @Tags metadata
+// @Produce application/vnd.sqlite3
+// @Success 200 {file} file "metadata database"
+// @Failure 500 {string} string "failed to export metadata"
+// @Router /api/metadata/export.db [get]
+func handleAPIExportMetadataSQLite(w http.ResponseWriter, r *http.Request) {
+	tmpDir, err := os.MkdirTemp("", "knov-metadata-export-")
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to create metadata export dir: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to export metadata"))
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "metadata_export.db")
+	if err := metadataStorage.ExportSQLite(dbPath); err != nil {
+		logging.LogError(logging.KeyApp, "failed to export metadata as sqlite: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to export metadata"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", "attachment; filename=metadata_export.db")
+	http.ServeFile(w, r, dbPath)
+}
+
+// @Summary Target date agenda feed
+// @Description iCalendar feed of the files with a targetDate as all-day events titled like the file and linking back to it, for subscribing from a calendar app. When KNOV_AGENDA_TOKEN is set the feed requires it as token and includes private collections, otherwise it is public and leaves them out.
+// @Tags metadata
+// @Produce text/calendar
+// @Param token query string false "agenda feed token"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 401 {string} string "invalid agenda token"
+// @Failure 500 {string} string "failed to build agenda"
+// @Router /api/metadata/agenda.ics [get]
+func handleAPIAgendaICS(w http.ResponseWriter, r *http.Request) {
+	token := configmanager.GetAgendaToken()
+	if token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		writeAPIError(w, http.StatusUnauthorized, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid agenda token"))
+		return
+	}
+
+	items, err := files.TargetDateAgenda(token != "")
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to build agenda: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to build agenda"))
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=agenda.ics")
+	fmt.Fprint(w, files.AgendaICS(items, scheme+"://"+r.Host, time.Now()))
+}
+
+// writeMetadataExport writes metadata as a csv or (any other format) json file download
+// named filename plus the format's extension.
+func writeMetadataExport(w http.ResponseWriter, metadata []*files.Metadata, format, filename string) {
 	switch format {
 	case "csv":
 		w.Header().Set("Content-Type", "text/csv")
-		w.Header().Set("Content-Disposition", "attachment; filename=metadata_export.csv")
-		csvData := render.RenderMetadataCSV(allMetadata)
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+		csvData := files.MetadataCSV(metadata)
 		w.Write([]byte(csvData))
 	case "json":
 		fallthrough
 	default:
+		if metadata == nil {
+			metadata = []*files.Metadata{}
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename=metadata_export.json")
-		if err := json.NewEncoder(w).Encode(allMetadata); err != nil {
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
+		if err := json.NewEncoder(w).Encode(metadata); err != nil {
 			http.Error(w, "failed to encode json", http.StatusInternalServerError)
 			return
 		}
 	}
 }
 
+// @Summary Import metadata
+// @Description Upserts metadata from a CSV in the column layout of the CSV export or a JSON array of metadata, as the request body or an uploaded file. List columns (tags, folders) split at ';' or ','. Rows without a path or for files that don't exist on disk are skipped and reported, all other rows are stored in one bulk write.
+// @Tags metadata
+// @Accept text/csv,application/json,multipart/form-data
+// @Param file formData file false "CSV or JSON file, instead of the request body"
+// @Produce json,html
+// @Success 200 {object} files.MetadataImportSummary
+// @Failure 400 {string} string "empty or unparsable import"
+// @Router /api/metadata/import [post]
+func handleAPIImportMetadata(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing file"))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to read import"))
+		return
+	}
+
+	rows, err := files.ParseMetadataImport(data)
+	if errors.Is(err, files.ErrEmptyImport) {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "empty import"))
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid import: %v", err))
+		return
+	}
+	for _, row := range rows {
+		if row != nil && !checkSafePaths(w, r, append([]string{row.Path}, row.Parents...)...) {
+			return
+		}
+	}
+
+	summary := files.MetaDataImport(rows)
+	level := notify.LevelSuccess
+	if summary.Skipped > 0 {
+		level = notify.LevelWarning
+	}
+	notify.SetHeader(w, level, translation.SprintfForRequest(configmanager.GetLanguage(), "%d rows imported, %d skipped", summary.Imported, summary.Skipped))
+	writeResponse(w, r, summary, render.RenderMetadataImportSummary(summary))
+}
+
 // @Summary Scan for broken links
 // @Description Scans link metadata (no file content is read) for outbound links pointing to files that no longer exist, suggesting a repair target where the broken link's filename uniquely matches an existing file.
 // @Tags metadata
@@ -360,6 +661,64 @@ func handleAPIScanBrokenLinks(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, broken, html)
 }
 
+// @Summary Validate all metadata
+// @Description Read-only diagnostic listing metadata inconsistencies: parents, kids and used links pointing to files that don't exist, unknown editors and kanban status tags not in the configured statuses.
+// @Tags metadata
+// @Produce json,html
+// @Success 200 {array} files.ValidationIssue
+// @Failure 500 {string} string "failed to validate metadata"
+// @Router /api/metadata/validate [get]
+func handleAPIValidateMetadata(w http.ResponseWriter, r *http.Request) {
+	issues, err := files.ValidateAllMetadata()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to validate metadata: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to validate metadata"))
+		return
+	}
+
+	writeResponse(w, r, issues, render.RenderMetadataValidationHTML(issues))
+}
+
+// @Summary Repair metadata inconsistencies
+// @Description Fixes the inconsistencies GET /api/metadata/validate reports, as selected: prune drops parents and kids pointing to files that don't exist, reset resets unknown editors to the default and drops unknown kanban status tags, linkstohere rebuilds linksToHere from the usedLinks of all files. With dryrun the changes are only reported.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param prune formData bool false "Prune dangling parents and kids"
+// @Param reset formData bool false "Reset unknown editors and kanban statuses"
+// @Param linkstohere formData bool false "Rebuild linksToHere"
+// @Param dryrun formData bool false "Only report the changes"
+// @Success 200 {object} files.RepairReport
+// @Failure 400 {string} string "no repair selected"
+// @Failure 500 {string} string "failed to repair metadata"
+// @Router /api/metadata/repair [post]
+func handleAPIRepairMetadata(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	opts := files.RepairOptions{
+		PruneRelations:     r.FormValue("prune") == "true",
+		ResetInvalid:       r.FormValue("reset") == "true",
+		RebuildLinksToHere: r.FormValue("linkstohere") == "true",
+		DryRun:             r.FormValue("dryrun") == "true",
+	}
+	if !opts.PruneRelations && !opts.ResetInvalid && !opts.RebuildLinksToHere {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "no repair selected"))
+		return
+	}
+
+	report, err := files.RepairMetadata(opts)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to repair metadata: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to repair metadata"))
+		return
+	}
+
+	writeResponse(w, r, report, render.RenderMetadataRepairHTML(report))
+}
+
 // @Summary Repair selected broken links
 // @Description Applies the selected repairs from a broken-links scan, rewriting each link to its suggested target
 // @Tags metadata
@@ -422,11 +781,18 @@ func handleAPIRepairBrokenLinks(w http.ResponseWriter, r *http.Request) {
 // ---------------------------------- GET INDIVIDUAL ----------------------------------
 // ----------------------------------------------------------------------------------------
 
+// metadataCollection is the JSON response of handleAPIGetMetadataCollection.
+type metadataCollection struct {
+	Collection string `json:"collection"`
+	Index      string `json:"index"` // docs-relative path of the collection's index file, "" when it has none
+}
+
 // @Summary Get file collection
+// @Description Returns the collection of a file and the index file (MOC at the collectionMocPath setting) of that collection, when it exists
 // @Tags metadata
 // @Param filepath query string true "File path"
 // @Produce json,html
-// @Success 200 {string} string
+// @Success 200 {object} metadataCollection
 // @Router /api/metadata/collection [get]
 func handleAPIGetMetadataCollection(w http.ResponseWriter, r *http.Request) {
 	filePath := r.URL.Query().Get("filepath")
@@ -445,8 +811,8 @@ func handleAPIGetMetadataCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	html := render.RenderMetadataLinkHTML(metadata.Collection, "collection")
-	writeResponse(w, r, metadata.Collection, html)
+	collection := metadataCollection{Collection: metadata.Collection, Index: files.CollectionIndex(metadata.Path)}
+	writeResponse(w, r, collection, render.RenderMetadataCollectionHTML(collection.Collection, collection.Index))
 }
 
 // @Summary Get editor type for a file
@@ -531,6 +897,30 @@ func handleAPIGetMetadataCreatedAt(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, createdAt, html)
 }
 
+// @Summary Get recently created files
+// @Description Returns the metadata of the most recently created files, newest first
+// @Tags metadata
+// @Param limit query int false "Number of results (default 50)"
+// @Produce json,html
+// @Success 200 {array} files.Metadata
+// @Failure 500 {string} string "failed to get recently created files"
+// @Router /api/metadata/recentlycreated [get]
+func handleAPIGetRecentlyCreated(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	created, err := files.GetRecentlyCreated(limit)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get recently created files: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get recently created files"))
+		return
+	}
+
+	writeResponse(w, r, created, render.RenderRecentlyCreatedList(created))
+}
+
 // @Summary Get file last edited date
 // @Tags metadata
 // @Param filepath query string true "File path"
@@ -559,10 +949,93 @@ func handleAPIGetMetadataLastEdited(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, lastEdited, html)
 }
 
+// wordCountResponse is the word count and reading time of a file.
+type wordCountResponse struct {
+	WordCount          int `json:"wordCount"`
+	ReadingTimeMinutes int `json:"readingTimeMinutes"`
+}
+
+// @Summary Get file word count and reading time
+// @Description Word count (markup, code blocks and link targets left out) and reading time at 200 words per minute, rounded up
+// @Tags metadata
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {object} wordCountResponse
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "metadata not found"
+// @Router /api/metadata/wordcount [get]
+func handleAPIGetMetadataWordCount(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(filePath))
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get metadata for %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get metadata"))
+		return
+	}
+	if metadata == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	}
+
+	writeResponse(w, r, wordCountResponse{metadata.WordCount, metadata.ReadingTimeMinutes}, render.RenderWordCount(metadata))
+}
+
 // ----------------------------------------------------------------------------------------
 // ---------------------------------- SET INDIVIDUAL ----------------------------------
 // ----------------------------------------------------------------------------------------
 
+// @Summary Generate the MOC of a collection
+// @Description (Re)generates the MOC listing every file of the collection grouped by folder, written to the configured collection MOC path
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param collection formData string true "Collection name"
+// @Success 200 {object} map[string]string "filepath of the generated moc"
+// @Failure 400 {string} string "missing collection or moc path outside the docs folder"
+// @Failure 404 {string} string "collection has no files"
+// @Failure 500 {string} string "failed to generate moc"
+// @Router /api/metadata/collection/moc [post]
+func handleAPIGenerateCollectionMOC(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	collection := strings.TrimSpace(r.FormValue("collection"))
+	if collection == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing collection"))
+		return
+	}
+
+	mocPath, err := files.GenerateCollectionMOC(collection)
+	if errors.Is(err, files.ErrCollectionEmpty) {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "collection has no files"))
+		return
+	}
+	if errors.Is(err, pathutils.ErrUnsafePath) {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid path"))
+		return
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to generate moc for collection %s: %v", collection, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to generate moc"))
+		return
+	}
+	go git.CommitFile(pathutils.ToDocsPath(mocPath))
+
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "moc generated"))
+	html := render.RenderStatusMessageWithLink(render.StatusOK,
+		translation.SprintfForRequest(configmanager.GetLanguage(), "moc generated"),
+		pathutils.ToFileURL(mocPath),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "view file"))
+	writeResponse(w, r, map[string]string{"filepath": mocPath}, html)
+}
+
 // @Summary Set file collection
 // @Tags metadata
 // @Accept application/x-www-form-urlencoded
@@ -697,6 +1170,7 @@ func handleAPISetMetadataPath(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	pruneEmptyFoldersAfterChange()
 	logging.LogInfo(logging.KeyApp, "successfully moved file via metadata: %s -> %s", filePath, newpath)
 	newRelPath := pathutils.ToRelative(newpath)
 	notify.SetFlash(notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "file moved successfully"))
@@ -780,6 +1254,38 @@ func handleAPISetMetadataLastEdited(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, "lastedited updated", "")
 }
 
+// @Summary Recount file words
+// @Description Recounts the words and reading time of a file from its current content
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Success 200 {object} wordCountResponse
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "metadata not found"
+// @Router /api/metadata/wordcount [post]
+func handleAPIRecountMetadataWordCount(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	filePath := r.FormValue("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	metadata, err := files.MetaDataRecountWords(filePath)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to recount words of %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save metadata"))
+		return
+	}
+	if metadata == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	}
+
+	writeResponse(w, r, wordCountResponse{metadata.WordCount, metadata.ReadingTimeMinutes}, render.RenderWordCount(metadata))
+}
+
 // @Summary Set file folders
 // @Tags metadata
 // @Accept application/x-www-form-urlencoded
@@ -820,21 +1326,223 @@ func handleAPISetMetadataFolders(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, "folders updated", "")
 }
 
-// @Summary Set file tags
+// @Summary List files by several tags
+// @Description Returns the files carrying all (and) or any (or) of the given tags, run through the filter engine
 // @Tags metadata
-// @Accept application/x-www-form-urlencoded
+// @Param tags query string true "Comma-separated tag list"
+// @Param logic query string false "and: files with every tag, or: files with any of them (defaults to the filterDefaultLogic setting)" Enums(and, or)
+// @Param actions query bool false "Render file action buttons"
 // @Produce json,html
-// @Param filepath formData string true "File path"
-// @Param tags formData string true "Comma-separated tag list"
-// @Success 200 {string} string
-// @Router /api/metadata/tags [post]
-func handleAPISetMetadataTags(w http.ResponseWriter, r *http.Request) {
-	r.ParseForm()
-	filePath := r.FormValue("filepath")
-	tagsStr := r.FormValue("tags")
-
-	if filePath == "" {
-		http.Error(w, "missing filepath parameter", http.StatusBadRequest)
+// @Success 200 {array} files.File
+// @Failure 400 {string} string "missing tags or invalid logic"
+// @Failure 500 {string} string "failed to filter files"
+// @Router /api/metadata/bytags [get]
+func handleAPIGetFilesByTags(w http.ResponseWriter, r *http.Request) {
+	var criteria []filter.Criteria
+	for _, tag := range strings.Split(r.URL.Query().Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			criteria = append(criteria, filter.Criteria{Metadata: "tags", Operator: "contains", Value: tag, Action: "include"})
+		}
+	}
+	if len(criteria) == 0 {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing tags parameter"))
+		return
+	}
+
+	logic := r.URL.Query().Get("logic")
+	if logic == "" {
+		logic = configmanager.GetFilterDefaultLogic()
+	}
+	if !slices.Contains(filter.GetLogics(), logic) {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid logic %s, expected one of %s", logic, strings.Join(filter.GetLogics(), ", ")))
+		return
+	}
+
+	taggedFiles, err := filter.FilterFiles(criteria, logic)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to filter files by tags: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to filter files"))
+		return
+	}
+
+	html := render.RenderBrowseFilesHTML(taggedFiles, r.URL.Query().Get("actions") == "true")
+	writeResponse(w, r, taggedFiles, html)
+}
+
+// @Summary Tag all files matching a filter
+// @Description Runs the filter and merges the given tags into the metadata of every matching file. Existing tags are kept.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param tags formData string true "Comma-separated tag list"
+// @Param logic formData string false "Filter logic (and/or)"
+// @Param metadata[] formData []string true "Metadata fields to filter on"
+// @Param operator[] formData []string true "Filter operators"
+// @Param value[] formData []string true "Filter values"
+// @Param action[] formData []string false "Filter actions (include/exclude)"
+// @Success 200 {object} map[string]int "count of matched files"
+// @Failure 400 {string} string "missing tags, missing criteria or invalid filter config"
+// @Failure 500 {string} string "failed to filter files"
+// @Router /api/metadata/tag/byfilter [post]
+func handleAPITagByFilter(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(r.FormValue("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing tags parameter"))
+		return
+	}
+
+	config := filter.ParseFilterConfigFromForm(r, -1)
+	// an empty filter matches every file - refuse instead of tagging the whole vault
+	if len(config.Criteria) == 0 {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filter criteria"))
+		return
+	}
+	if err := filter.ValidateConfig(config); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid filter config: %v", err))
+		return
+	}
+
+	count, err := filter.TagByFilter(config.Criteria, config.Logic, tags)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "tag by filter failed: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to filter files"))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "%d files tagged", count)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, map[string]int{"count": count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+type tagRenameResult struct {
+	Files   []string `json:"files"`
+	Count   int      `json:"count"`
+	Preview bool     `json:"preview"`
+}
+
+// @Summary Rename a tag in all files
+// @Description Replaces the tag old with new in the metadata of every file. Files already carrying new keep a single copy, so renaming onto an existing tag merges them. Pass preview=true to list the affected files without changing anything.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param old formData string true "Tag to rename"
+// @Param new formData string true "New tag name"
+// @Param preview query bool false "Only list the affected files"
+// @Success 200 {object} tagRenameResult
+// @Failure 400 {string} string "missing or invalid tag names"
+// @Router /api/metadata/tags/rename [post]
+func handleAPIRenameTag(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	oldName, newName := r.FormValue("old"), r.FormValue("new")
+
+	if r.URL.Query().Get("preview") == "true" {
+		affected, err := files.RenameTagPreview(oldName, newName)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to rename tag: %v", err))
+			return
+		}
+		msg := translation.SprintfForRequest(configmanager.GetLanguage(), "%d files would be changed", len(affected))
+		writeResponse(w, r, tagRenameResult{Files: affected, Count: len(affected), Preview: true},
+			render.RenderStatusMessage(render.StatusOK, msg)+render.RenderLinksList(affected, false))
+		return
+	}
+
+	count, err := files.RenameTag(oldName, newName)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to rename tag: %v", err))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "tag renamed in %d files", count)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, tagRenameResult{Files: []string{}, Count: count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Set the target date of all files matching a filter
+// @Description Runs the filter and sets the targetDate custom field of every matching file. An empty date clears it.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param date formData string false "Target date (YYYY-MM-DD), empty to clear"
+// @Param logic formData string false "Filter logic (and/or)"
+// @Param metadata[] formData []string true "Metadata fields to filter on"
+// @Param operator[] formData []string true "Filter operators"
+// @Param value[] formData []string true "Filter values"
+// @Param action[] formData []string false "Filter actions (include/exclude)"
+// @Success 200 {object} map[string]int "count of matched files"
+// @Failure 400 {string} string "invalid date, missing criteria or invalid filter config"
+// @Failure 500 {string} string "failed to filter files"
+// @Router /api/metadata/targetdate/byfilter [post]
+func handleAPITargetDateByFilter(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	date := strings.TrimSpace(r.FormValue("date"))
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid date format, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	config := filter.ParseFilterConfigFromForm(r, -1)
+	// an empty filter matches every file - refuse instead of dating the whole vault
+	if len(config.Criteria) == 0 {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filter criteria"))
+		return
+	}
+	if err := filter.ValidateConfig(config); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid filter config: %v", err))
+		return
+	}
+
+	count, err := filter.SetTargetDateByFilter(config.Criteria, config.Logic, date)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "target date by filter failed: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to filter files"))
+		return
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "target date set on %d files", count)
+	if date == "" {
+		successMsg = translation.SprintfForRequest(configmanager.GetLanguage(), "target date cleared on %d files", count)
+	}
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, map[string]int{"count": count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
+// @Summary Set file tags
+// @Description Replaces the tags of a file. Tags that are synonyms per the tagSynonyms setting are saved but reported as warnings.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Param tags formData string true "Comma-separated tag list"
+// @Success 200 {object} tagsUpdateResult
+// @Router /api/metadata/tags [post]
+func handleAPISetMetadataTags(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	filePath := r.FormValue("filepath")
+	tagsStr := r.FormValue("tags")
+
+	if filePath == "" {
+		http.Error(w, "missing filepath parameter", http.StatusBadRequest)
 		return
 	}
 
@@ -880,12 +1588,30 @@ func handleAPISetMetadataTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// near-duplicate tags are saved anyway, the user only gets warned
+	var warnings []string
+	for _, pair := range files.TagSynonymConflicts(sanitized) {
+		warnings = append(warnings, translation.SprintfForRequest(configmanager.GetLanguage(), "%q and %q are synonyms", pair[0], pair[1]))
+	}
+	if len(warnings) > 0 {
+		warning := translation.SprintfForRequest(configmanager.GetLanguage(), "tags updated, but %s", strings.Join(warnings, "; "))
+		notify.SetHeader(w, notify.LevelWarning, warning)
+		writeResponse(w, r, tagsUpdateResult{Status: "tags updated", Warnings: warnings}, render.RenderStatusMessage(render.StatusWarning, html.EscapeString(warning)))
+		return
+	}
+
 	if msg := kanban.TagNotifyMsg(oldKbTag, newKbTag); msg != "" {
 		notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), msg))
 	} else {
 		notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "tags updated"))
 	}
-	writeResponse(w, r, "tags updated", "")
+	writeResponse(w, r, tagsUpdateResult{Status: "tags updated"}, "")
+}
+
+// tagsUpdateResult is the JSON response of handleAPISetMetadataTags.
+type tagsUpdateResult struct {
+	Status   string   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"` // near-duplicate tags per the tagSynonyms setting
 }
 
 // @Summary Set file parents
@@ -912,6 +1638,9 @@ func handleAPISetMetadataParents(w http.ResponseWriter, r *http.Request) {
 		for i := range parents {
 			parents[i] = strings.TrimSpace(parents[i])
 		}
+		if !checkSafePaths(w, r, parents...) {
+			return
+		}
 		for _, parent := range parents {
 			if parent == "" {
 				continue
@@ -950,7 +1679,9 @@ func handleAPISetMetadataParents(w http.ResponseWriter, r *http.Request) {
 // @Description Get all tags with counts, or tags for a specific file if filepath is provided
 // @Tags metadata
 // @Param filepath query string false "File path (optional - if provided, returns tags for that specific file)"
-// @Param format query string false "Response format (options for HTML select options)"
+// @Param format query string false "Response format (options for HTML datalist options, most used first, capped by the datalistOptionsLimit setting)"
+// @Param q query string false "Only return options containing this text (format=options only)"
+// @Param includeArchived query bool false "Count the files in the archive tier too, see the archiveTier setting"
 // @Produce json,html
 // @Success 200 {object} files.TagCount
 // @Router /api/metadata/tags [get]
@@ -961,50 +1692,35 @@ func handleAPIGetAllTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	format := r.URL.Query().Get("format")
-	if format == "options" {
-		cachedTags, err := files.GetAllTagsFromCache()
-		if err != nil || len(cachedTags) == 0 {
-			logging.LogError(logging.KeyApp, "failed to get cached tags, fallback to live data: %v", err)
-			tags, err := files.GetAllTags()
-			if err != nil {
-				http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get tags"), http.StatusInternalServerError)
-				return
-			}
-			var tagList []string
-			for tag := range tags {
-				tagList = append(tagList, tag)
-			}
-			slices.Sort(tagList)
-			cachedTags = tagList
-		}
-		var html strings.Builder
-		for _, tag := range cachedTags {
-			html.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, tag, tag))
-		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(html.String()))
+	if r.URL.Query().Get("format") == "options" {
+		writeMetadataOptions(w, "tags", r.URL.Query().Get("q"))
 		return
 	}
 
-	tags, err := files.GetAllTagsCountFromCache()
-	if err != nil || len(tags) == 0 {
+	var counts files.TagCount
+	var err error
+	if includeArchivedParam(r) {
+		counts, err = files.GetAllTags(true)
+	} else if counts, err = files.GetAllTagsCountFromCache(); err != nil || len(counts) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached tag counts, fallback to live data: %v", err)
-		tags, err = files.GetAllTags()
-		if err != nil {
-			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get tags"), http.StatusInternalServerError)
-			return
-		}
+		counts, err = files.GetAllTags(false)
 	}
-	html := render.RenderBrowseHTML(tags, "/browse/tag", r.URL.Query().Get("actions") == "true", "tag")
-	writeResponse(w, r, tags, html)
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get tags"), http.StatusInternalServerError)
+		return
+	}
+
+	html := render.RenderBrowseHTML(counts, "/browse/tag", r.URL.Query().Get("actions") == "true", "tag")
+	writeResponse(w, r, counts, html)
 }
 
 // @Summary Get all collections or collection for a specific file
 // @Description Get all collections with counts, or collection for a specific file if filepath is provided
 // @Tags metadata
 // @Param filepath query string false "File path (optional - if provided, returns collection for that specific file)"
-// @Param format query string false "Response format (options for HTML select options)"
+// @Param format query string false "Response format (options for HTML datalist options, most used first, capped by the datalistOptionsLimit setting)"
+// @Param q query string false "Only return options containing this text (format=options only)"
+// @Param includeArchived query bool false "Count the files in the archive tier too, see the archiveTier setting"
 // @Produce json,html
 // @Success 200 {object} files.CollectionCount
 // @Router /api/metadata/collections [get]
@@ -1015,50 +1731,35 @@ func handleAPIGetAllCollections(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	format := r.URL.Query().Get("format")
-	if format == "options" {
-		cachedCollections, err := files.GetAllCollectionsFromCache()
-		if err != nil || len(cachedCollections) == 0 {
-			logging.LogError(logging.KeyApp, "failed to get cached collections, fallback to live data: %v", err)
-			collections, err := files.GetAllCollections()
-			if err != nil {
-				http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get collections"), http.StatusInternalServerError)
-				return
-			}
-			var collectionList []string
-			for collection := range collections {
-				collectionList = append(collectionList, collection)
-			}
-			slices.Sort(collectionList)
-			cachedCollections = collectionList
-		}
-		var html strings.Builder
-		for _, collection := range cachedCollections {
-			html.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, collection, collection))
-		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(html.String()))
+	if r.URL.Query().Get("format") == "options" {
+		writeMetadataOptions(w, "collections", r.URL.Query().Get("q"))
 		return
 	}
 
-	collections, err := files.GetAllCollectionsCountFromCache()
-	if err != nil || len(collections) == 0 {
+	var counts files.CollectionCount
+	var err error
+	if includeArchivedParam(r) {
+		counts, err = files.GetAllCollections(true)
+	} else if counts, err = files.GetAllCollectionsCountFromCache(); err != nil || len(counts) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached collection counts, fallback to live data: %v", err)
-		collections, err = files.GetAllCollections()
-		if err != nil {
-			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get collections"), http.StatusInternalServerError)
-			return
-		}
+		counts, err = files.GetAllCollections(false)
 	}
-	html := render.RenderBrowseHTML(collections, "/browse/collection", r.URL.Query().Get("actions") == "true", "collection")
-	writeResponse(w, r, collections, html)
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get collections"), http.StatusInternalServerError)
+		return
+	}
+
+	html := render.RenderBrowseHTML(counts, "/browse/collection", r.URL.Query().Get("actions") == "true", "collection")
+	writeResponse(w, r, counts, html)
 }
 
 // @Summary Get all folders or folders for a specific file
 // @Description Get all folders with counts, or folders for a specific file if filepath is provided
 // @Tags metadata
 // @Param filepath query string false "File path (optional - if provided, returns folders for that specific file)"
-// @Param format query string false "Response format (options for HTML select options)"
+// @Param format query string false "Response format (options for HTML datalist options, most used first, capped by the datalistOptionsLimit setting)"
+// @Param q query string false "Only return options containing this text (format=options only)"
+// @Param includeArchived query bool false "Count the files in the archive tier too, see the archiveTier setting"
 // @Produce json,html
 // @Success 200 {object} files.FolderCount
 // @Router /api/metadata/folders [get]
@@ -1069,43 +1770,171 @@ func handleAPIGetAllFolders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	format := r.URL.Query().Get("format")
-	if format == "options" {
-		cachedFolders, err := files.GetAllFoldersFromCache()
-		if err != nil || len(cachedFolders) == 0 {
-			logging.LogError(logging.KeyApp, "failed to get cached folders, fallback to live data: %v", err)
-			folders, err := files.GetAllFolders()
-			if err != nil {
-				http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get folders"), http.StatusInternalServerError)
-				return
-			}
-			var folderList []string
-			for folder := range folders {
-				folderList = append(folderList, folder)
+	if r.URL.Query().Get("format") == "options" {
+		writeMetadataOptions(w, "folders", r.URL.Query().Get("q"))
+		return
+	}
+
+	var counts files.FolderCount
+	var err error
+	if includeArchivedParam(r) {
+		counts, err = files.GetAllFolders(true)
+	} else if counts, err = files.GetAllFoldersCountFromCache(); err != nil || len(counts) == 0 {
+		logging.LogError(logging.KeyApp, "failed to get cached folder counts, fallback to live data: %v", err)
+		counts, err = files.GetAllFolders(false)
+	}
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get folders"), http.StatusInternalServerError)
+		return
+	}
+
+	html := render.RenderBrowseHTML(counts, "/browse/folder", r.URL.Query().Get("actions") == "true", "folder")
+	writeResponse(w, r, counts, html)
+}
+
+// metadataSuggestion is a suggested metadata value with the number of files using it
+// (not set for paths).
+type metadataSuggestion struct {
+	Value string `json:"value"`
+	Count int    `json:"count,omitempty"`
+}
+
+// metadataSuggestFields are the fields /api/metadata/suggest completes.
+var metadataSuggestFields = []string{"tags", "collections", "folders", "paths"}
+
+// metadataSuggestions returns the values of field containing query (case-insensitive),
+// most used first, at most limit of them. Reads the cached lists, falling back to live data.
+func metadataSuggestions(field, query string, limit int) ([]metadataSuggestion, error) {
+	var counts map[string]int
+	var err error
+	switch field {
+	case "tags":
+		counts, err = files.GetAllTagsCountFromCache()
+		if err != nil || len(counts) == 0 {
+			counts, err = files.GetAllTags(false)
+		}
+	case "collections":
+		counts, err = files.GetAllCollectionsCountFromCache()
+		if err != nil || len(counts) == 0 {
+			counts, err = files.GetAllCollections(false)
+		}
+	case "folders":
+		counts, err = files.GetAllFoldersCountFromCache()
+		if err != nil || len(counts) == 0 {
+			counts, err = files.GetAllFolders(false)
+		}
+	case "paths":
+		var paths []string
+		paths, err = files.GetAllFilePathsFromCache()
+		if err != nil || len(paths) == 0 {
+			var allFiles []files.File
+			allFiles, err = files.GetAllFilesCached()
+			paths = paths[:0]
+			for _, f := range allFiles {
+				paths = append(paths, f.Path)
 			}
-			slices.Sort(folderList)
-			cachedFolders = folderList
 		}
-		var html strings.Builder
-		for _, folder := range cachedFolders {
-			html.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, folder, folder))
+		counts = make(map[string]int, len(paths))
+		for _, path := range paths {
+			counts[path] = 0
 		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(html.String()))
+	default:
+		return nil, fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(metadataSuggestFields, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := files.TopCountKeys(counts, query, limit)
+	suggestions := make([]metadataSuggestion, 0, len(values))
+	for _, value := range values {
+		suggestions = append(suggestions, metadataSuggestion{Value: value, Count: counts[value]})
+	}
+	return suggestions, nil
+}
+
+// suggestionValues returns the values of suggestions.
+func suggestionValues(suggestions []metadataSuggestion) []string {
+	values := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		values = append(values, s.Value)
+	}
+	return values
+}
+
+// writeMetadataOptions writes the datalist options of the format=options metadata
+// endpoints, capped by the datalistOptionsLimit setting.
+func writeMetadataOptions(w http.ResponseWriter, field, query string) {
+	suggestions, err := metadataSuggestions(field, query, configmanager.GetDatalistOptionsLimit())
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get %s options: %v", field, err)
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get %s", field), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(render.RenderDatalistOptions(suggestionValues(suggestions))))
+}
+
+// @Summary Suggest metadata values
+// @Description Autocomplete for tags, collections, folders and file paths: the values containing q, most used first
+// @Tags metadata
+// @Param field query string true "Field to complete" Enums(tags, collections, folders, paths)
+// @Param q query string false "Only return values containing this text (case-insensitive)"
+// @Param limit query int false "Maximum number of suggestions (default 20, at most the datalistOptionsLimit setting)"
+// @Produce json,html
+// @Success 200 {array} metadataSuggestion
+// @Failure 400 {string} string "unknown field"
+// @Router /api/metadata/suggest [get]
+func handleAPISuggestMetadata(w http.ResponseWriter, r *http.Request) {
+	field := r.URL.Query().Get("field")
+	if !slices.Contains(metadataSuggestFields, field) {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "unknown field %s, expected one of %s", field, strings.Join(metadataSuggestFields, ", ")))
 		return
 	}
 
-	folders, err := files.GetAllFoldersCountFromCache()
-	if err != nil || len(folders) == 0 {
-		logging.LogError(logging.KeyApp, "failed to get cached folder counts, fallback to live data: %v", err)
-		folders, err = files.GetAllFolders()
-		if err != nil {
-			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get folders"), http.StatusInternalServerError)
-			return
-		}
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	limit = min(limit, configmanager.GetDatalistOptionsLimit())
+
+	suggestions, err := metadataSuggestions(field, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to suggest %s: %v", field, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get %s", field))
+		return
 	}
-	html := render.RenderBrowseHTML(folders, "/browse/folder", r.URL.Query().Get("actions") == "true", "folder")
-	writeResponse(w, r, folders, html)
+
+	writeResponse(w, r, suggestions, render.RenderDatalistOptions(suggestionValues(suggestions)))
+}
+
+// @Summary Suggest metadata for content
+// @Description Suggests tags and a collection for the content of a new note from the tags and collections of the most content-similar notes (term frequency cosine similarity). The suggestSimilarNotes setting sets how many notes are compared.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param content formData string true "Note content"
+// @Success 200 {object} files.Suggestions
+// @Failure 400 {string} string "missing content"
+// @Router /api/metadata/suggest [post]
+func handleAPISuggestMetadataFromContent(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+	content := r.FormValue("content")
+	if strings.TrimSpace(content) == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing content"))
+		return
+	}
+
+	suggestions, err := files.SuggestMetadata(content)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to suggest metadata: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to suggest metadata"))
+		return
+	}
+	writeResponse(w, r, suggestions, render.RenderMetadataSuggestions(suggestions))
 }
 
 // @Summary Get all file titles
@@ -1148,10 +1977,22 @@ func handleAPIGetAllTitles(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, titles, "")
 }
 
+// @Summary Get the icon of every editor type
+// @Description Returns the Font Awesome icon class per editor type (filetype): the filetypeIcons setting where configured, the built-in default otherwise
+// @Tags metadata
+// @Produce json,html
+// @Success 200 {object} map[string]string
+// @Router /api/metadata/filetype/icons [get]
+func handleAPIGetFiletypeIcons(w http.ResponseWriter, r *http.Request) {
+	icons := files.EditorTypeIcons()
+	writeResponse(w, r, icons, render.RenderEditorTypeIcons(icons))
+}
+
 // @Summary Get all available editor types
 // @Tags metadata
 // @Param format query string false "Response format: options for HTML select options"
 // @Param context query string false "Context: chat excludes filter-editor from suggestions"
+// @Param includeArchived query bool false "Count the files in the archive tier too, see the archiveTier setting"
 // @Produce json,html
 // @Success 200 {object} files.EditorTypeCount
 // @Router /api/metadata/editors [get]
@@ -1172,14 +2013,17 @@ func handleAPIGetAllEditors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filetypes, err := files.GetAllEditorsCountFromCache()
-	if err != nil || len(filetypes) == 0 {
+	var filetypes files.EditorTypeCount
+	var err error
+	if includeArchivedParam(r) {
+		filetypes, err = files.GetAllEditors(true)
+	} else if filetypes, err = files.GetAllEditorsCountFromCache(); err != nil || len(filetypes) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached editor counts, fallback to live data: %v", err)
-		filetypes, err = files.GetAllEditors()
-		if err != nil {
-			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get editor types"), http.StatusInternalServerError)
-			return
-		}
+		filetypes, err = files.GetAllEditors(false)
+	}
+	if err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get editor types"), http.StatusInternalServerError)
+		return
 	}
 	html := render.RenderBrowseHTML(filetypes, "/browse/editor", false, "")
 	writeResponse(w, r, filetypes, html)
@@ -1390,6 +2234,301 @@ func handleAPIDeleteMetadataReference(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, metadata.References, html)
 }
 
+// ----------------------------------------------------------------------------------------
+// ---------------------------------- HISTORY ----------------------------------
+// ----------------------------------------------------------------------------------------
+
+// @Summary Get the metadata history of a file
+// @Description Returns the recorded changes of the file's title, collection, tags, parents, editor, label, createdAt, references and custom fields, newest first. Internal updates such as link rebuilds are not recorded.
+// @Tags metadata
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {array} metadataHistoryStorage.Change
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 500 {string} string "failed to get metadata history"
+// @Router /api/metadata/history [get]
+func handleAPIGetMetadataHistory(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	changes, err := files.GetMetadataHistory(filePath)
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to get metadata history of %s: %v", filePath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get metadata history"))
+		return
+	}
+	writeResponse(w, r, changes, render.RenderMetadataHistoryHTML(changes))
+}
+
+// ----------------------------------------------------------------------------------------
+// ---------------------------------- LABEL ----------------------------------
+// ----------------------------------------------------------------------------------------
+
+// @Summary Get the color label of a file
+// @Tags metadata
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {string} string "label, empty when the file has none"
+// @Failure 400 {string} string "missing filepath parameter"
+// @Failure 404 {string} string "metadata not found"
+// @Router /api/metadata/label [get]
+func handleAPIGetMetadataLabel(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(filePath))
+	if err != nil || metadata == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	}
+	writeResponse(w, r, metadata.Label, render.RenderMetadataLabelHTML(metadata.Label))
+}
+
+// @Summary Set the color label of a file
+// @Description Sets the file's color label to one of the labelColors setting; an empty label removes it
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Param label formData string false "Label color (empty removes the label)"
+// @Success 200 {string} string "label"
+// @Failure 400 {string} string "invalid label"
+// @Failure 404 {string} string "metadata not found"
+// @Router /api/metadata/label [post]
+func handleAPISetMetadataLabel(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	filePath := r.FormValue("filepath")
+	label := strings.TrimSpace(r.FormValue("label"))
+	if filePath == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"))
+		return
+	}
+
+	normalizedPath := pathutils.ToWithPrefix(filePath)
+	metadata, err := files.MetaDataSetLabel(normalizedPath, label)
+	if errors.Is(err, files.ErrInvalidLabel) {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid label %s, expected one of %s", label, strings.Join(configmanager.GetLabelColors(), ", ")))
+		return
+	}
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to save label for %s: %v", normalizedPath, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save metadata"))
+		return
+	}
+	if metadata == nil {
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	}
+
+	notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "label updated"))
+	writeResponse(w, r, metadata.Label, render.RenderMetadataLabelHTML(metadata.Label))
+}
+
+// @Summary Get the label colors
+// @Description Returns the colors files can be labelled with, as configured in the labelColors setting. The html response is datalist options.
+// @Tags metadata
+// @Produce json,html
+// @Success 200 {array} string
+// @Router /api/metadata/labels [get]
+func handleAPIGetLabelColors(w http.ResponseWriter, r *http.Request) {
+	colors := configmanager.GetLabelColors()
+	writeResponse(w, r, colors, render.RenderDatalistOptions(colors))
+}
+
+// ----------------------------------------------------------------------------------------
+// ---------------------------------- CUSTOM ----------------------------------
+// ----------------------------------------------------------------------------------------
+
+// @Summary Get custom metadata for a file
+// @Description Returns the file's arbitrary key/value metadata, filterable as custom.<key>
+// @Tags metadata
+// @Param filepath query string true "File path"
+// @Produce json,html
+// @Success 200 {object} map[string]string
+// @Router /api/metadata/custom [get]
+func handleAPIGetMetadataCustom(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filepath")
+	if filePath == "" {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "missing filepath parameter"), http.StatusBadRequest)
+		return
+	}
+
+	metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(filePath))
+	if err != nil || metadata == nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"), http.StatusNotFound)
+		return
+	}
+
+	custom := metadata.Custom
+	if custom == nil {
+		custom = map[string]string{}
+	}
+	writeResponse(w, r, custom, render.RenderCustomMetadataHTML(filePath, custom))
+}
+
+// @Summary Set a custom metadata field of a file
+// @Description Sets one key/value pair of the file's custom metadata; an empty value removes the key
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Param key formData string true "Custom field key"
+// @Param value formData string false "Custom field value (empty removes the key)"
+// @Success 200 {object} map[string]string
+// @Router /api/metadata/custom [post]
+func handleAPISetMetadataCustom(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"), http.StatusBadRequest)
+		return
+	}
+
+	filePath := r.FormValue("filepath")
+	key := strings.TrimSpace(r.FormValue("key"))
+	value := strings.TrimSpace(r.FormValue("value"))
+
+	if filePath == "" || key == "" {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "filepath and key are required"), http.StatusBadRequest)
+		return
+	}
+
+	normalizedPath := pathutils.ToWithPrefix(filePath)
+	metadata, err := files.MetaDataGet(normalizedPath)
+	if err != nil || metadata == nil {
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"), http.StatusNotFound)
+		return
+	}
+
+	if metadata.Custom == nil {
+		metadata.Custom = map[string]string{}
+	}
+	if value == "" {
+		delete(metadata.Custom, key)
+	} else {
+		metadata.Custom[key] = value
+	}
+
+	if err := files.MetaDataSave(metadata); err != nil {
+		logging.LogError(logging.KeyApp, "failed to save custom metadata for %s: %v", normalizedPath, err)
+		http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to save metadata"), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, r, metadata.Custom, render.RenderCustomMetadataHTML(filePath, metadata.Custom))
+}
+
+// ----------------------------------------------------------------------------------------
+// ---------------------------------- BOARD ----------------------------------
+// ----------------------------------------------------------------------------------------
+
+// @Summary Move a card of a kanban widget
+// @Description Sets the group field of a file to the value of the column its card was dragged to and stores the card order of that column. The value must be a configured status, or for a custom field one it already has (empty clears it).
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string true "File path"
+// @Param groupField formData string true "Group field of the board (status, priority or custom.<key>)"
+// @Param value formData string false "Value of the target column"
+// @Param order formData string false "Comma-separated file paths of the target column in display order"
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "invalid value"
+// @Failure 404 {string} string "metadata not found"
+// @Router /api/metadata/board/move [post]
+func handleAPIMoveBoardCard(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	filePath := r.FormValue("filepath")
+	groupField := r.FormValue("groupField")
+	value := strings.TrimSpace(r.FormValue("value"))
+	if filePath == "" || groupField == "" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "filepath and groupField are required"))
+		return
+	}
+	var order []string
+	for _, p := range strings.Split(r.FormValue("order"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			order = append(order, p)
+		}
+	}
+
+	groupBy := dashboard.KanbanConfig{GroupField: groupField}.GroupBy()
+	oldValue, err := kanban.MoveCardToField(filePath, groupBy, value, order)
+	switch {
+	case errors.Is(err, kanban.ErrInvalidFieldValue):
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid value %s for %s", value, groupField))
+		return
+	case errors.Is(err, kanban.ErrCardNotFound):
+		writeAPIError(w, http.StatusNotFound, translation.SprintfForRequest(configmanager.GetLanguage(), "metadata not found"))
+		return
+	case err != nil:
+		logging.LogError(logging.KeyApp, "failed to move board card %s to %s=%s: %v", filePath, groupBy, value, err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to update card"))
+		return
+	}
+
+	if oldValue != value {
+		notify.SetHeader(w, notify.LevelSuccess, translation.SprintfForRequest(configmanager.GetLanguage(), "%s changed: %s → %s", groupField, oldValue, value))
+	}
+	writeResponse(w, r, map[string]string{"filepath": filePath, "groupField": groupField, "value": value}, "")
+}
+
+// ----------------------------------------------------------------------------------------
+// ---------------------------------- IMPORT ----------------------------------
+// ----------------------------------------------------------------------------------------
+
+// @Summary Import Obsidian/Logseq metadata
+// @Description Reads Obsidian YAML front matter or Logseq page properties and merges them into the file metadata: tags are added, aliases and all other properties become custom fields. Imports a single file when filepath is given, otherwise every markdown file.
+// @Tags metadata
+// @Accept application/x-www-form-urlencoded
+// @Produce json,html
+// @Param filepath formData string false "File path (optional - imports all markdown files when empty)"
+// @Success 200 {object} map[string]int "count of files with imported metadata"
+// @Failure 500 {string} string "failed to import metadata"
+// @Router /api/metadata/import/obsidian [post]
+func handleAPIImportObsidianMetadata(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to parse form"))
+		return
+	}
+
+	var count int
+	if filePath := r.FormValue("filepath"); filePath != "" {
+		imported, err := files.ImportExternalMetadata(pathutils.ToRelative(filePath))
+		if err != nil {
+			logging.LogError(logging.KeyApp, "failed to import metadata for %s: %v", filePath, err)
+			writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to import metadata"))
+			return
+		}
+		if imported {
+			count = 1
+		}
+	} else {
+		var err error
+		count, err = files.ImportAllExternalMetadata()
+		if err != nil {
+			logging.LogError(logging.KeyApp, "failed to import metadata: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to import metadata"))
+			return
+		}
+	}
+
+	successMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "imported metadata for %d files", count)
+	notify.SetHeader(w, notify.LevelSuccess, successMsg)
+	writeResponse(w, r, map[string]int{"count": count}, render.RenderStatusMessage(render.StatusOK, successMsg))
+}
+
 // ----------------------------------------------------------------------------------------
 // ---------------------------------- HELPERS ----------------------------------
 // ----------------------------------------------------------------------------------------
@@ -3,9 +3,12 @@ package server
 import (
 	"net/http"
 
+	"knov/internal/configmanager"
 	"knov/internal/files"
+	"knov/internal/logging"
 	"knov/internal/search"
 	"knov/internal/server/render"
+	"knov/internal/translation"
 )
 
 // @Summary Search files
@@ -14,17 +17,39 @@ import (
 // @Param format query string false "Output format: dropdown, list, cards, json" Enums(dropdown, list, cards, json)
 // @Param titleonly query bool false "Search file titles only (no content)"
 // @Param history query bool false "Search deleted files in git history"
-// @Produce json,html
+// @Param fuzzy query bool false "Fall back to titles and filenames close to the query when nothing matches (default true)"
+// @Param snippets query bool false "With format json, return the content matches as path, highlighted snippet and score, ordered by relevance"
+// @Param includeArchived query bool false "Search the files in the archive tier too, see the archiveTier setting"
+// @Param export query string false "Download the metadata of the matching files instead, up to the searchExportLimit setting" Enums(csv, json)
+// @Produce json,html,text/csv
+// @Failure 400 {string} string "invalid export format"
 // @Router /api/search [get]
 func handleAPISearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	format := r.URL.Query().Get("format")
 	titleOnly := r.URL.Query().Get("titleonly") == "true"
 	history := r.URL.Query().Get("history") == "true"
+	withSnippets := r.URL.Query().Get("snippets") == "true"
+	fuzzy := r.URL.Query().Get("fuzzy") != "false"
+	export := r.URL.Query().Get("export")
+	includeArchived := includeArchivedParam(r)
 	if format == "" {
 		format = "dropdown"
 	}
 
+	if export != "" && export != "csv" && export != "json" {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid export format, expected csv or json"))
+		return
+	}
+	if export != "" && history {
+		writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "deleted files can't be exported"))
+		return
+	}
+
+	if query == "" && export != "" {
+		writeMetadataExport(w, nil, export, "search_export")
+		return
+	}
 	if query == "" {
 		emptyHTML := render.RenderSearchHint()
 		if format == "json" {
@@ -48,6 +73,9 @@ func handleAPISearch(w http.ResponseWriter, r *http.Request) {
 	default:
 		limit = 6
 	}
+	if export != "" {
+		limit = configmanager.GetSearchExportLimit()
+	}
 
 	// history search — returns git.GitHistoryFile results, rendered as list
 	if history {
@@ -71,18 +99,41 @@ func handleAPISearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if withSnippets && format == "json" && !titleOnly && export == "" {
+		snippetResults, err := search.SearchFilesWithSnippets(query, limit, includeArchived)
+		if err != nil {
+			http.Error(w, "search failed", http.StatusInternalServerError)
+			return
+		}
+		writeResponse(w, r, snippetResults, "")
+		return
+	}
+
 	var results []files.File
 	var err error
 	if titleOnly {
-		results, err = search.SearchFilesByTitle(query, limit)
+		results, err = search.SearchFilesByTitle(query, limit, includeArchived)
+	} else if fuzzy {
+		results, err = search.SearchFiles(query, limit, includeArchived)
 	} else {
-		results, err = search.SearchFiles(query, limit)
+		results, err = search.SearchFilesNoFuzzy(query, limit, includeArchived)
 	}
 	if err != nil {
 		http.Error(w, "search failed", http.StatusInternalServerError)
 		return
 	}
 
+	if export != "" {
+		var metadata []*files.Metadata
+		for _, file := range results {
+			if file.Metadata != nil {
+				metadata = append(metadata, file.Metadata)
+			}
+		}
+		writeMetadataExport(w, metadata, export, "search_export")
+		return
+	}
+
 	switch format {
 	case "json":
 		writeResponse(w, r, results, "")
@@ -93,7 +144,19 @@ func handleAPISearch(w http.ResponseWriter, r *http.Request) {
 		html := render.RenderSearchList(results, query)
 		writeResponse(w, r, results, html)
 	case "cards":
-		html := render.RenderSearchCards(results, query)
+		// content matches show the excerpt of the search index, filename and tag matches
+		// fall back to the context the render package extracts
+		snippets := make(map[string]string)
+		if !titleOnly {
+			snippetResults, err := search.SearchFilesWithSnippets(query, limit, includeArchived)
+			if err != nil {
+				logging.LogWarning(logging.KeyApp, "failed to get search snippets for %q: %v", query, err)
+			}
+			for _, sr := range snippetResults {
+				snippets[sr.Path] = sr.Snippet
+			}
+		}
+		html := render.RenderSearchCards(results, query, snippets)
 		writeResponse(w, r, results, html)
 	default:
 		html := render.RenderSearchDropdown(results, query)
@@ -13,8 +13,10 @@ import (
 	"strings"
 
 	"knov/internal/configmanager"
+	"knov/internal/files"
 	"knov/internal/job"
 	"knov/internal/logging"
+	"knov/internal/server/metrics"
 	"knov/internal/server/notify"
 	"knov/internal/server/render"
 	"knov/internal/translation"
@@ -40,6 +42,36 @@ func handleAPIInvalidateCache(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, map[string]string{"status": "cache invalidated"}, "")
 }
 
+// @Summary Prune empty folders
+// @Description Removes empty folders under the data directory; the docs and media roots, hidden folders and the storage and logs directories are kept
+// @Tags system
+// @Produce json,html
+// @Success 200 {object} map[string]int
+// @Failure 500 {string} string "failed to prune empty folders"
+// @Router /api/system/pruneempty [post]
+func handleAPIPruneEmptyDirs(w http.ResponseWriter, r *http.Request) {
+	removed, err := files.PruneEmptyDirs()
+	if err != nil {
+		logging.LogError(logging.KeyApp, "failed to prune empty folders: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to prune empty folders"))
+		return
+	}
+
+	msg := translation.SprintfForRequest(configmanager.GetLanguage(), "removed %d empty folders", removed)
+	notify.SetHeader(w, notify.LevelSuccess, msg)
+	writeResponse(w, r, map[string]int{"removed": removed}, render.RenderStatusMessage(render.StatusOK, msg))
+}
+
+// @Summary Get response sizes
+// @Description Returns the response body sizes recorded per endpoint since startup, largest single response first
+// @Tags system
+// @Produce json
+// @Success 200 {array} metrics.ResponseSizeStat
+// @Router /api/system/responsesizes [get]
+func handleAPIGetResponseSizes(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, metrics.ResponseSizes(), "")
+}
+
 // @Summary Get recent log entries
 // @Description Returns the most recent in-memory log entries across every key as an HTML table, newest first. Powers the "Live" view on the admin logs page.
 // @Tags system
@@ -9,6 +9,7 @@ import (
 	"knov/internal/logging"
 	"knov/internal/server/notify"
 	"knov/internal/server/render"
+	_ "knov/internal/test/admintest" // registers the admin suite job.RunAdminTest looks up
 	"knov/internal/test/filtertest"
 	"knov/internal/translation"
 )
@@ -258,6 +259,136 @@ func handleAPIKanbanTest(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, r, results, html)
 }
 
+// @Summary Run metadata tests
+// @Description Executes the metadata suite (metadata initialization defaults and bulk metadata operations)
+// @Tags testdata
+// @Produce json,html
+// @Success 200 {object} test.SuiteResult "metadata test results"
+// @Failure 500 {object} string "Internal server error"
+// @Router /api/testdata/metadatatest [post]
+func handleAPIMetadataTest(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebug(logging.KeyApp, "metadata test request received")
+
+	results, err := job.RunMetadataTest()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, job.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		logging.LogError(logging.KeyApp, "failed to run metadata tests: %v", err)
+		notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	html := render.RenderSuiteResult(results)
+	writeResponse(w, r, results, html)
+}
+
+// @Summary Run storage tests
+// @Description Executes the storage suite (config, cache and metadata storage backends opened in a temporary folder)
+// @Tags testdata
+// @Produce json,html
+// @Success 200 {object} test.SuiteResult "storage test results"
+// @Failure 500 {object} string "Internal server error"
+// @Router /api/testdata/storagetest [post]
+func handleAPIStorageTest(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebug(logging.KeyApp, "storage test request received")
+
+	results, err := job.RunStorageTest()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, job.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		logging.LogError(logging.KeyApp, "failed to run storage tests: %v", err)
+		notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	html := render.RenderSuiteResult(results)
+	writeResponse(w, r, results, html)
+}
+
+// @Summary Run browse tests
+// @Description Executes the browse suite (file list, browse, tree and info slideout logic on sample files)
+// @Tags testdata
+// @Produce json,html
+// @Success 200 {object} test.SuiteResult "browse test results"
+// @Failure 500 {object} string "Internal server error"
+// @Router /api/testdata/browsetest [post]
+func handleAPIBrowseTest(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebug(logging.KeyApp, "browse test request received")
+
+	results, err := job.RunBrowseTest()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, job.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		logging.LogError(logging.KeyApp, "failed to run browse tests: %v", err)
+		notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	html := render.RenderSuiteResult(results)
+	writeResponse(w, r, results, html)
+}
+
+// @Summary Run admin tests
+// @Description Executes the admin suite (export, import, backup and job logic on sample files)
+// @Tags testdata
+// @Produce json,html
+// @Success 200 {object} test.SuiteResult "admin test results"
+// @Failure 500 {object} string "Internal server error"
+// @Router /api/testdata/admintest [post]
+func handleAPIAdminTest(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebug(logging.KeyApp, "admin test request received")
+
+	results, err := job.RunAdminTest()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, job.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		logging.LogError(logging.KeyApp, "failed to run admin tests: %v", err)
+		notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	html := render.RenderSuiteResult(results)
+	writeResponse(w, r, results, html)
+}
+
+// @Summary Run settings tests
+// @Description Executes the settings suite (translation fallbacks and other settings driven behaviour)
+// @Tags testdata
+// @Produce json,html
+// @Success 200 {object} test.SuiteResult "settings test results"
+// @Failure 500 {object} string "Internal server error"
+// @Router /api/testdata/settingstest [post]
+func handleAPISettingsTest(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebug(logging.KeyApp, "settings test request received")
+
+	results, err := job.RunSettingsTest()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, job.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		logging.LogError(logging.KeyApp, "failed to run settings tests: %v", err)
+		notify.SetHeader(w, notify.LevelError, translation.SprintfForRequest(configmanager.GetLanguage(), err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	html := render.RenderSuiteResult(results)
+	writeResponse(w, r, results, html)
+}
+
 // @Summary Run all test suites
 // @Description Executes every registered in-app test suite and aggregates the results
 // @Tags testdata
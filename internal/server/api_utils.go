@@ -3,9 +3,12 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"knov/internal/configmanager"
 	"knov/internal/server/render"
 )
 
@@ -21,6 +24,22 @@ func writeResponse(w http.ResponseWriter, r *http.Request, jsonData any, htmlDat
 	}
 }
 
+// writeResponseStatus is writeResponse with a status code, for errors that carry a
+// structured JSON body.
+func writeResponseStatus(w http.ResponseWriter, r *http.Request, status int, jsonData any, htmlData string) {
+	contentType := "application/json"
+	if acceptHeader := r.Header.Get("Accept"); strings.Contains(acceptHeader, "text/html") || strings.Contains(acceptHeader, "*/*") {
+		contentType = "text/html"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if contentType == "text/html" {
+		w.Write([]byte(htmlData))
+	} else {
+		json.NewEncoder(w).Encode(jsonData)
+	}
+}
+
 // writeAPIError writes a status-coded HTML error response, replacing the
 // repeated header/status/write block previously duplicated across the file
 // rename/move/delete handlers.
@@ -29,3 +48,48 @@ func writeAPIError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	w.Write([]byte(render.RenderStatusMessage(render.StatusError, message)))
 }
+
+// pageParams reads the 1-based page and the pageSize request parameters. A missing or invalid
+// page is 1, a missing or invalid pageSize the fileListPageSize setting, capped at
+// configmanager.MaxPageSize. paged reports whether the request set either of them.
+func pageParams(r *http.Request) (page, pageSize int, paged bool) {
+	pageStr, pageSizeStr := r.FormValue("page"), r.FormValue("pageSize")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err = strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = configmanager.GetFileListPageSize()
+	}
+	return page, min(pageSize, configmanager.MaxPageSize), pageStr != "" || pageSizeStr != ""
+}
+
+// setPaginationHeaders sets X-Total-Count to the unpaginated total and a Link header with the
+// next and prev pages of the request URL, keeping its other query parameters.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, page, pageSize, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	pageURL := func(n int) string {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(n))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+		return r.URL.Path + "?" + query.Encode()
+	}
+	var links []string
+	if page*pageSize < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)))
+	}
+	if page > 1 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(page-1)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// includeArchivedParam reports whether the request asks for the files in the archive tier
+// with includeArchived=true, see files.InArchiveTier.
+func includeArchivedParam(r *http.Request) bool {
+	return r.URL.Query().Get("includeArchived") == "true"
+}
@@ -0,0 +1,70 @@
+// Package metrics - response body sizes recorded per endpoint since startup.
+package metrics
+
+import (
+	"sort"
+	"sync"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+)
+
+// ResponseSizeStat is the recorded response body size of one endpoint (method and route pattern).
+type ResponseSizeStat struct {
+	Endpoint   string `json:"endpoint"`
+	Count      int    `json:"count"`
+	TotalBytes int64  `json:"totalBytes"`
+	MaxBytes   int    `json:"maxBytes"`
+}
+
+// UnmatchedEndpoint is the response size entry of requests that matched no route.
+const UnmatchedEndpoint = "unmatched"
+
+var (
+	responseSizes   = make(map[string]*ResponseSizeStat)
+	responseSizesMu sync.Mutex
+)
+
+// RecordResponse records the body size of a response to method and path under the route
+// pattern that served it and logs a warning when it exceeds the configured responseSizeWarnBytes.
+// Requests no route matched, an empty pattern, share the UnmatchedEndpoint entry, so
+// arbitrary paths can't grow the recorded stats.
+func RecordResponse(method, path, pattern string, size int) {
+	endpoint := UnmatchedEndpoint
+	if pattern != "" {
+		endpoint = method + " " + pattern
+	}
+
+	responseSizesMu.Lock()
+	stat, ok := responseSizes[endpoint]
+	if !ok {
+		stat = &ResponseSizeStat{Endpoint: endpoint}
+		responseSizes[endpoint] = stat
+	}
+	stat.Count++
+	stat.TotalBytes += int64(size)
+	stat.MaxBytes = max(stat.MaxBytes, size)
+	responseSizesMu.Unlock()
+
+	if limit := configmanager.GetResponseSizeWarnBytes(); limit > 0 && size > limit {
+		logging.LogWarning(logging.KeyApp, "large response: %s %s wrote %d bytes (threshold %d)", method, path, size, limit)
+	}
+}
+
+// ResponseSizes returns the recorded response sizes, largest single response first.
+func ResponseSizes() []ResponseSizeStat {
+	responseSizesMu.Lock()
+	stats := make([]ResponseSizeStat, 0, len(responseSizes))
+	for _, stat := range responseSizes {
+		stats = append(stats, *stat)
+	}
+	responseSizesMu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].MaxBytes != stats[j].MaxBytes {
+			return stats[i].MaxBytes > stats[j].MaxBytes
+		}
+		return stats[i].Endpoint < stats[j].Endpoint
+	})
+	return stats
+}
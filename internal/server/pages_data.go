@@ -0,0 +1,100 @@
+// Package server - structured data of the theme pages, served as json to non-theme clients
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"knov/internal/dashboard"
+	"knov/internal/files"
+	"knov/internal/git"
+	"knov/internal/logging"
+)
+
+// homeRecentChanges is the number of recent changes in the home page data.
+const homeRecentChanges = 10
+
+// homePageData is the json of the home page: the home dashboard when one is set.
+type homePageData struct {
+	Dashboard     *dashboard.Dashboard `json:"dashboard,omitempty"`
+	Stats         files.VaultStats     `json:"stats"`
+	RecentChanges []git.GitHistoryFile `json:"recentChanges"`
+}
+
+// historyPageData is the json of the history page: the latest changes, paged with count and offset.
+type historyPageData struct {
+	Collection string               `json:"collection,omitempty"`
+	Folder     string               `json:"folder,omitempty"`
+	Changes    []git.GitHistoryFile `json:"changes"`
+	HasMore    bool                 `json:"hasMore"`
+}
+
+// fileHistoryPageData is the json of the history page of a single file.
+type fileHistoryPageData struct {
+	FilePath       string            `json:"filePath"`
+	CurrentCommit  string            `json:"currentCommit"`
+	SelectedCommit string            `json:"selectedCommit"`
+	Deleted        bool              `json:"deleted"`
+	Versions       []git.FileVersion `json:"versions"`
+}
+
+// overviewPageData is the json of the files overview page.
+type overviewPageData struct {
+	Stats files.VaultStats `json:"stats"`
+	Files []files.File     `json:"files"`
+}
+
+// wantsJSON reports whether a page request asks for json instead of the themed html page.
+// Browsers always accept text/html, so only clients explicitly preferring json get it.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// newHomePageData collects the home page json. A failing git history (e.g. no commits yet)
+// leaves the recent changes empty instead of failing the page.
+func newHomePageData(dash *dashboard.Dashboard) homePageData {
+	data := homePageData{Dashboard: dash, Stats: files.CollectVaultStats(), RecentChanges: []git.GitHistoryFile{}}
+	changes, _, err := git.RecentChanges(homeRecentChanges, 0, "", "")
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "home page: failed to get recent changes: %v", err)
+	} else if changes != nil {
+		data.RecentChanges = changes
+	}
+	return data
+}
+
+// newHistoryPageData collects the history page json from the count, offset, collection and
+// folder query parameters.
+func newHistoryPageData(r *http.Request) (historyPageData, error) {
+	query := r.URL.Query()
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count <= 0 {
+		count = 50
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	data := historyPageData{Collection: query.Get("collection"), Folder: query.Get("folder")}
+	changes, hasMore, err := git.RecentChanges(count, max(offset, 0), data.Collection, data.Folder)
+	if err != nil {
+		return data, err
+	}
+	data.Changes, data.HasMore = changes, hasMore
+	if data.Changes == nil {
+		data.Changes = []git.GitHistoryFile{}
+	}
+	return data, nil
+}
+
+// newOverviewPageData collects the files overview page json.
+func newOverviewPageData() (overviewPageData, error) {
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return overviewPageData{}, err
+	}
+	if allFiles == nil {
+		allFiles = []files.File{}
+	}
+	return overviewPageData{Stats: files.CollectVaultStats(), Files: allFiles}, nil
+}
@@ -0,0 +1,122 @@
+// Package server - per client rate limits of the expensive api endpoints
+package server
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/translation"
+)
+
+// maxRateLimitClients bounds how many client limiters a group keeps; the least recently
+// seen client is forgotten first, which at worst gives it a fresh bucket.
+const maxRateLimitClients = 4096
+
+// rateLimitGroup is a token bucket per client ip shared by the routes of one group. The
+// bucket holds perMinute() tokens and refills them over a minute, a perMinute of 0 turns the
+// limit off.
+type rateLimitGroup struct {
+	name      string
+	perMinute func() int
+
+	mu       sync.Mutex
+	clients  map[string]*list.Element
+	lru      *list.List // of *rateLimitClient, most recently seen first
+	maxCount int
+}
+
+type rateLimitClient struct {
+	ip        string
+	limiter   *rate.Limiter
+	perMinute int
+}
+
+func newRateLimitGroup(name string, perMinute func() int) *rateLimitGroup {
+	return &rateLimitGroup{
+		name:      name,
+		perMinute: perMinute,
+		clients:   map[string]*list.Element{},
+		lru:       list.New(),
+		maxCount:  maxRateLimitClients,
+	}
+}
+
+// rate limit groups, see the rateLimit* settings
+var (
+	rateLimitRebuild = newRateLimitGroup("rebuild", configmanager.GetRateLimitRebuild)
+	rateLimitSearch  = newRateLimitGroup("search", configmanager.GetRateLimitSearch)
+)
+
+// limiter returns the limiter of ip, creating it and evicting the least recently seen client
+// when the group is full. After the limit changed every client starts with a full bucket.
+func (g *rateLimitGroup) limiter(ip string, perMinute int) *rate.Limiter {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if element, ok := g.clients[ip]; ok {
+		g.lru.MoveToFront(element)
+		client := element.Value.(*rateLimitClient)
+		if client.perMinute != perMinute {
+			client.limiter, client.perMinute = newPerMinuteLimiter(perMinute), perMinute
+		}
+		return client.limiter
+	}
+
+	client := &rateLimitClient{ip: ip, limiter: newPerMinuteLimiter(perMinute), perMinute: perMinute}
+	g.clients[ip] = g.lru.PushFront(client)
+	if g.lru.Len() > g.maxCount {
+		oldest := g.lru.Back()
+		g.lru.Remove(oldest)
+		delete(g.clients, oldest.Value.(*rateLimitClient).ip)
+	}
+	return client.limiter
+}
+
+// newPerMinuteLimiter returns a full bucket of perMinute tokens refilled over a minute.
+func newPerMinuteLimiter(perMinute int) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)
+}
+
+// rateLimit answers 429 with a Retry-After header (in seconds) to a client that used up the
+// requests of the group.
+func rateLimit(g *rateLimitGroup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			perMinute := g.perMinute()
+			if perMinute <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip := clientIP(r)
+			reservation := g.limiter(ip, perMinute).Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				reservation.Cancel()
+				logging.LogWarning(logging.KeyApp, "rate limited %s %s from %s (%s: %d per minute)", r.Method, r.URL.Path, ip, g.name, perMinute)
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(delay.Seconds()))))
+				writeAPIError(w, http.StatusTooManyRequests, translation.SprintfForRequest(configmanager.GetLanguage(), "too many requests, try again later"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the ip of the connection. Forwarding headers are ignored, they can be set
+// by any client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,10 +3,13 @@ package render
 
 import (
 	"fmt"
+	"html/template"
 	"strings"
 
 	"knov/internal/configmanager"
 	"knov/internal/dashboard"
+	"knov/internal/files"
+	"knov/internal/filter"
 	"knov/internal/translation"
 )
 
@@ -154,6 +157,7 @@ func RenderDashboardForm(dash *dashboard.Dashboard, isEdit bool) string {
 				`<h4>%s</h4>`+
 				`<div class="dashboard-export-actions">`+
 				`<a href="/api/dashboards/%s/export" class="btn-secondary">%s</a>`+
+				`<button type="button" class="btn-secondary" hx-post="/api/dashboards/%s/duplicate" hx-target="#dashboard-result" hx-swap="innerHTML">%s</button>`+
 				`<button type="button" class="btn-danger"`+
 				` hx-delete="/api/dashboards/%s"`+
 				` hx-confirm="%s"`+
@@ -166,6 +170,8 @@ func RenderDashboardForm(dash *dashboard.Dashboard, isEdit bool) string {
 			dash.ID,
 			translation.SprintfForRequest(configmanager.GetLanguage(), "export"),
 			dash.ID,
+			translation.SprintfForRequest(configmanager.GetLanguage(), "duplicate"),
+			dash.ID,
 			translation.SprintfForRequest(configmanager.GetLanguage(), "are you sure you want to delete this dashboard?"),
 			translation.SprintfForRequest(configmanager.GetLanguage(), "delete dashboard"),
 		))
@@ -232,7 +238,7 @@ func RenderWidgetForm(index int, widget *dashboard.Widget) string {
 	html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "widget type")))
 	html.WriteString(fmt.Sprintf(`<select name="widgets[%d][type]" required class="form-select widget-type-select" hx-get="/api/dashboards/widget-config" hx-target="#widget-config-%d" hx-swap="innerHTML" hx-vals='{"index": "%d"}' hx-include="[name='widgets[%d][type]']">`, index, index, index, index))
 
-	widgetTypes := []string{"filter", "filterForm", "fileContent", "static", "tags", "collections", "folders"}
+	widgetTypes := []string{"filter", "filterForm", "fileContent", "static", "tags", "collections", "folders", "calendar", "kanban", "query"}
 	selectedType := ""
 	if widget != nil {
 		selectedType = string(widget.Type)
@@ -259,6 +265,16 @@ func RenderWidgetForm(index int, widget *dashboard.Widget) string {
 	html.WriteString(fmt.Sprintf(`<input type="text" name="widgets[%d][title]" value="%s" placeholder="%s" class="form-input"/>`, index, titleValue, translation.SprintfForRequest(configmanager.GetLanguage(), "optional title")))
 	html.WriteString(`</div>`)
 
+	// auto-refresh interval
+	html.WriteString(`<div class="form-group">`)
+	html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "refresh every (seconds)")))
+	refreshValue := 0
+	if widget != nil {
+		refreshValue = widget.RefreshSeconds
+	}
+	html.WriteString(fmt.Sprintf(`<input type="number" name="widgets[%d][refreshSeconds]" value="%d" min="0" class="form-input" title="%s"/>`, index, refreshValue, translation.SprintfForRequest(configmanager.GetLanguage(), "0 disables auto-refresh")))
+	html.WriteString(`</div>`)
+
 	// widget config container
 	html.WriteString(fmt.Sprintf(`<div id="widget-config-%d" class="widget-config-container">`, index))
 	if widget != nil {
@@ -333,6 +349,79 @@ func RenderWidgetConfig(index int, widgetType string, config *dashboard.WidgetCo
 		html.WriteString(`</div>`)
 		html.WriteString(`</div>`)
 
+	case "calendar":
+		html.WriteString(`<div class="config-form">`)
+		html.WriteString(fmt.Sprintf(`<h5>%s</h5>`, translation.SprintfForRequest(configmanager.GetLanguage(), "calendar configuration")))
+		selectedField, selectedRange := "createdAt", dashboard.CalendarRangeMonth
+		if config != nil && config.Calendar != nil {
+			selectedField, selectedRange = config.Calendar.DateField, config.Calendar.Range
+		}
+		html.WriteString(`<div class="config-row">`)
+		html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "date field")))
+		html.WriteString(fmt.Sprintf(`<select name="widgets[%d][config][dateField]" class="form-select">`, index))
+		for _, field := range files.CalendarDateFields {
+			selected := ""
+			if field == selectedField {
+				selected = "selected"
+			}
+			html.WriteString(fmt.Sprintf(`<option value="%s" %s>%s</option>`, field, selected, translation.SprintfForRequest(configmanager.GetLanguage(), field)))
+		}
+		html.WriteString(`</select>`)
+		html.WriteString(`</div>`)
+		html.WriteString(`<div class="config-row">`)
+		html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "range")))
+		html.WriteString(fmt.Sprintf(`<select name="widgets[%d][config][range]" class="form-select">`, index))
+		for _, option := range dashboard.CalendarRanges {
+			selected := ""
+			if option == selectedRange {
+				selected = "selected"
+			}
+			html.WriteString(fmt.Sprintf(`<option value="%s" %s>%s</option>`, option, selected, translation.SprintfForRequest(configmanager.GetLanguage(), option)))
+		}
+		html.WriteString(`</select>`)
+		html.WriteString(`</div>`)
+		html.WriteString(fmt.Sprintf(`<p class="config-note">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "shows the notes per day of the current week or month")))
+		html.WriteString(`</div>`)
+
+	case "kanban":
+		html.WriteString(`<div class="config-form">`)
+		html.WriteString(fmt.Sprintf(`<h5>%s</h5>`, translation.SprintfForRequest(configmanager.GetLanguage(), "kanban configuration")))
+		groupField := "status"
+		if config != nil && config.Kanban != nil && config.Kanban.GroupField != "" {
+			groupField = config.Kanban.GroupField
+		}
+		html.WriteString(`<div class="config-row">`)
+		html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "group by")))
+		html.WriteString(fmt.Sprintf(`<input type="text" name="widgets[%d][config][groupField]" value="%s" list="kanban-group-fields-%d" class="form-input" required/>`, index, template.HTMLEscapeString(groupField), index))
+		html.WriteString(fmt.Sprintf(`<datalist id="kanban-group-fields-%d">`, index))
+		for _, field := range dashboard.KanbanGroupFields {
+			html.WriteString(fmt.Sprintf(`<option value="%s"></option>`, field))
+		}
+		html.WriteString(`</datalist>`)
+		html.WriteString(`</div>`)
+		html.WriteString(fmt.Sprintf(`<p class="config-note">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "status, priority or a custom field (custom.name): one column per value with the matching files as cards")))
+		html.WriteString(`</div>`)
+
+	case "query":
+		html.WriteString(`<div class="config-form">`)
+		html.WriteString(fmt.Sprintf(`<h5>%s</h5>`, translation.SprintfForRequest(configmanager.GetLanguage(), "query configuration")))
+		query := ""
+		if config != nil && config.Query != nil {
+			query = config.Query.Query
+		}
+		html.WriteString(`<div class="config-row">`)
+		html.WriteString(fmt.Sprintf(`<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "query")))
+		html.WriteString(fmt.Sprintf(`<input type="text" name="widgets[%d][config][query]" value="%s" list="saved-queries-%d" class="form-input" placeholder="type:literature tag:#ml created>2025-01-01" required/>`, index, template.HTMLEscapeString(query), index))
+		html.WriteString(fmt.Sprintf(`<datalist id="saved-queries-%d">`, index))
+		if queries, err := filter.ListQueries(); err == nil {
+			for _, saved := range queries {
+				html.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, template.HTMLEscapeString(saved.Query), template.HTMLEscapeString(saved.Name)))
+			}
+		}
+		html.WriteString(`</datalist>`)
+		html.WriteString(`</div>`)
+		html.WriteString(`</div>`)
+
 	case "filterForm", "tags", "collections", "folders":
 		widgetName := string(widgetType)
 		html.WriteString(`<div class="config-form">`)
@@ -2,14 +2,20 @@
 package render
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"html"
+	"net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"knov/internal/configmanager"
 	"knov/internal/dashboard"
 	"knov/internal/files"
 	"knov/internal/filter"
+	"knov/internal/kanban"
 	"knov/internal/logging"
 	"knov/internal/mapping"
 	"knov/internal/pathutils"
@@ -24,11 +30,26 @@ func RenderWidget(widgetType dashboard.WidgetType, config dashboard.WidgetConfig
 		if config.Filter == nil {
 			return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "filter config is required"))
 		}
+		// a preset is looked up on every render, so the widget follows changes of the preset
+		if config.Filter.Preset != "" {
+			preset, err := filter.GetPreset(config.Filter.Preset)
+			if errors.Is(err, filter.ErrPresetNotFound) {
+				return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset %s not found", config.Filter.Preset))
+			}
+			if err != nil {
+				return "", err
+			}
+			return renderFilterWidget(preset)
+		}
 		filterConfig := &filter.Config{
-			Criteria: config.Filter.Criteria,
-			Logic:    config.Filter.Logic,
-			Display:  config.Filter.Display,
-			Limit:    config.Filter.Limit,
+			Criteria:  config.Filter.Criteria,
+			Logic:     config.Filter.Logic,
+			Display:   config.Filter.Display,
+			Limit:     config.Filter.Limit,
+			SortBy:    config.Filter.SortBy,
+			SortOrder: config.Filter.SortOrder,
+			GroupBy:   config.Filter.GroupBy,
+			Columns:   config.Filter.Columns,
 		}
 		return renderFilterWidget(filterConfig)
 	case dashboard.WidgetTypeFilterForm:
@@ -43,25 +64,74 @@ func RenderWidget(widgetType dashboard.WidgetType, config dashboard.WidgetConfig
 		return renderCollectionsWidget()
 	case dashboard.WidgetTypeFolders:
 		return renderFoldersWidget()
+	case dashboard.WidgetTypeCalendar:
+		return renderCalendarWidget(config.Calendar, time.Now())
+	case dashboard.WidgetTypeKanban:
+		return renderKanbanWidget(config.Kanban)
+	case dashboard.WidgetTypeQuery:
+		return renderQueryWidget(config.Query)
 	default:
 		msg := translation.SprintfForRequest(configmanager.GetLanguage(), "unknown widget type: %s", widgetType)
 		return "", errors.New(msg)
 	}
 }
 
+// RenderWidgets renders widgets concurrently on up to workers goroutines and returns their html
+// in widget order. A widget that fails, or is not started before ctx is done, renders as an
+// error message instead.
+func RenderWidgets(ctx context.Context, widgets []dashboard.Widget, workers int) []string {
+	return dashboard.RenderEach(ctx, widgets, workers, renderWidgetOrError)
+}
+
+func renderWidgetOrError(ctx context.Context, widget *dashboard.Widget) string {
+	err := ctx.Err()
+	if err == nil {
+		var html string
+		if html, err = RenderWidget(widget.Type, widget.Config); err == nil {
+			return html
+		}
+	}
+	logging.LogError(logging.KeyApp, "failed to render widget %s: %v", widget.ID, err)
+	return RenderStatusMessage(StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to render widget"))
+}
+
+// RenderDashboardWidgets wraps the rendered html of each widget of a dashboard in its widget box.
+func RenderDashboardWidgets(dashID string, widgets []dashboard.Widget, rendered []string) string {
+	var b strings.Builder
+	for i, widget := range widgets {
+		title := widget.Title
+		if title == "" {
+			title = string(widget.Type)
+		}
+		fmt.Fprintf(&b, `<div class="dashboard-widget" data-widget-id="%s"><h3>%s</h3><div class="widget-content"%s>%s</div></div>`,
+			html.EscapeString(widget.ID), html.EscapeString(title), widgetRefreshAttrs(dashID, widget), rendered[i])
+	}
+	return b.String()
+}
+
+// widgetRefreshAttrs returns the htmx attributes re-rendering a widget's content every
+// RefreshSeconds through the single widget endpoint, or nothing when it doesn't refresh.
+func widgetRefreshAttrs(dashID string, widget dashboard.Widget) string {
+	if widget.RefreshSeconds <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(` hx-post="/api/dashboards/widget/%s" hx-vals='{"dashboardId": "%s"}' hx-trigger="every %ds"`,
+		url.PathEscape(widget.ID), html.EscapeString(dashID), widget.RefreshSeconds)
+}
+
 func renderFileContentWidget(config *dashboard.FileContentConfig) (string, error) {
 	if config == nil || config.FilePath == "" {
 		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "file path is required"))
 	}
 
 	fullPath := pathutils.ToDocsPath(config.FilePath)
-	content, err := files.GetFileContent(fullPath)
+	content, err := files.GetFileContentLimited(fullPath, configmanager.GetEmbedMaxBytes())
 	if err != nil {
 		logging.LogError(logging.KeyApp, "failed to get file content: %v", err)
 		return "", err
 	}
 
-	return fmt.Sprintf(`<article class="file-content">%s</article>`, content.HTML), nil
+	return fmt.Sprintf(`<article class="file-content">%s</article>%s`, content.HTML, renderEmbedTruncated(config.FilePath, content)), nil
 }
 
 func renderStaticWidget(config *dashboard.StaticConfig) (string, error) {
@@ -84,7 +154,7 @@ func renderTagsWidget() (string, error) {
 	tagCount, err := files.GetAllTagsCountFromCache()
 	if err != nil || len(tagCount) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached tag counts, fallback to live data: %v", err)
-		tagCount, err = files.GetAllTags()
+		tagCount, err = files.GetAllTags(false)
 		if err != nil {
 			return "", err
 		}
@@ -97,7 +167,7 @@ func renderCollectionsWidget() (string, error) {
 	collectionCount, err := files.GetAllCollectionsCountFromCache()
 	if err != nil || len(collectionCount) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached collection counts, fallback to live data: %v", err)
-		collectionCount, err = files.GetAllCollections()
+		collectionCount, err = files.GetAllCollections(false)
 		if err != nil {
 			return "", err
 		}
@@ -110,7 +180,7 @@ func renderFoldersWidget() (string, error) {
 	folderCount, err := files.GetAllFoldersCountFromCache()
 	if err != nil || len(folderCount) == 0 {
 		logging.LogError(logging.KeyApp, "failed to get cached folder counts, fallback to live data: %v", err)
-		folderCount, err = files.GetAllFolders()
+		folderCount, err = files.GetAllFolders(false)
 		if err != nil {
 			return "", err
 		}
@@ -125,12 +195,41 @@ func renderFilterWidget(config *filter.Config) (string, error) {
 		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "filter config is required"))
 	}
 
+	// a count widget doesn't need the matching files themselves
+	if config.Display == "count" {
+		total, err := filter.CountFilesWithConfig(config)
+		if err != nil {
+			return "", err
+		}
+		return RenderFilterResult(&filter.Result{Total: total}, config.Display, nil), nil
+	}
+
 	result, err := filter.FilterFilesWithConfig(config)
 	if err != nil {
 		return "", err
 	}
 
-	return RenderFilterResult(result, config.Display), nil
+	return RenderFilterResult(result, config.Display, config.Columns), nil
+}
+
+// renderQueryWidget parses the text query of a query widget and renders its results like a
+// filter widget. An invalid query renders as an error placeholder inside the widget rather
+// than failing it, so the query itself is shown and can be fixed from the dashboard.
+func renderQueryWidget(config *dashboard.QueryConfig) (string, error) {
+	if config == nil || strings.TrimSpace(config.Query) == "" {
+		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "query is required"))
+	}
+
+	filterConfig, err := filter.ParseQueryString(config.Query)
+	if err != nil {
+		var b strings.Builder
+		b.WriteString(`<div class="query-widget-error">`)
+		b.WriteString(RenderStatusMessage(StatusError, fmt.Sprintf(translation.SprintfForRequest(configmanager.GetLanguage(), "invalid query: %v"), err)))
+		fmt.Fprintf(&b, `<code>%s</code>`, html.EscapeString(config.Query))
+		b.WriteString(`</div>`)
+		return b.String(), nil
+	}
+	return renderFilterWidget(filterConfig)
 }
 
 // RenderFilterWidgetConfig renders widget-specific configuration form for filter widgets
@@ -138,15 +237,39 @@ func RenderFilterWidgetConfig(index int, config *dashboard.WidgetConfig) string
 	var fc *filter.Config
 	if config != nil && config.Filter != nil {
 		fc = &filter.Config{
-			Criteria: config.Filter.Criteria,
-			Logic:    config.Filter.Logic,
-			Display:  config.Filter.Display,
-			Limit:    config.Filter.Limit,
+			Criteria:  config.Filter.Criteria,
+			Logic:     config.Filter.Logic,
+			Display:   config.Filter.Display,
+			Limit:     config.Filter.Limit,
+			SortBy:    config.Filter.SortBy,
+			SortOrder: config.Filter.SortOrder,
+			GroupBy:   config.Filter.GroupBy,
+			Columns:   config.Filter.Columns,
+		}
+	}
+
+	// the preset input comes first: a preset replaces the criteria of the form below
+	var presetRow strings.Builder
+	preset := ""
+	if config != nil && config.Filter != nil {
+		preset = config.Filter.Preset
+	}
+	presetRow.WriteString(`<div class="config-row">`)
+	fmt.Fprintf(&presetRow, `<label>%s</label>`, translation.SprintfForRequest(configmanager.GetLanguage(), "filter preset"))
+	fmt.Fprintf(&presetRow, `<input type="text" name="widgets[%d][config][preset]" value="%s" list="filter-presets-%d" class="form-input"/>`, index, html.EscapeString(preset), index)
+	fmt.Fprintf(&presetRow, `<datalist id="filter-presets-%d">`, index)
+	if names, err := filter.ListPresets(); err == nil {
+		for _, name := range names {
+			fmt.Fprintf(&presetRow, `<option value="%s"></option>`, html.EscapeString(name))
 		}
 	}
+	presetRow.WriteString(`</datalist>`)
+	presetRow.WriteString(`</div>`)
 
 	var html strings.Builder
 	html.WriteString(`<div class="config-form">`)
+	html.WriteString(presetRow.String())
+	fmt.Fprintf(&html, `<p class="config-note">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "a preset replaces the criteria below and the widget follows later changes of the preset"))
 	html.WriteString(RenderFilterForm(FilterFormOpts{
 		Context:     FilterFormContextDashboard,
 		Config:      fc,
@@ -178,3 +301,121 @@ func renderFilterFormWidget() (string, error) {
 	html.WriteString(`</div></div>`)
 	return html.String(), nil
 }
+
+// calendarDayLinks is the number of notes linked in a calendar day, the others are counted.
+const calendarDayLinks = 3
+
+// renderCalendarWidget renders the month or week around now as a grid of days, each with
+// the number of and links to the notes whose date field falls on it.
+func renderCalendarWidget(config *dashboard.CalendarConfig, now time.Time) (string, error) {
+	if config == nil || !slices.Contains(files.CalendarDateFields, config.DateField) {
+		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "calendar date field is required"))
+	}
+
+	from, to := dashboard.CalendarPeriod(config.Range, now)
+	days, err := files.FilesByDay(config.DateField, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	rangeName := dashboard.CalendarRangeMonth
+	title := translation.SprintfForRequest(configmanager.GetLanguage(), from.Format("January")) + " " + from.Format("2006")
+	if config.Range == dashboard.CalendarRangeWeek {
+		rangeName = dashboard.CalendarRangeWeek
+		title = configmanager.FormatDate(from) + " - " + configmanager.FormatDate(to.AddDate(0, 0, -1))
+	}
+	fmt.Fprintf(&b, `<div class="calendar-widget calendar-%s"><div class="calendar-title">%s</div><div class="calendar-grid">`, rangeName, html.EscapeString(title))
+	for _, weekday := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		fmt.Fprintf(&b, `<div class="calendar-weekday">%s</div>`, translation.SprintfForRequest(configmanager.GetLanguage(), weekday))
+	}
+
+	// pad the grid to whole weeks, monday to sunday
+	gridStart := from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+	gridEnd := to.AddDate(0, 0, (7-(int(to.Weekday())+6)%7)%7)
+	today := now.Format("2006-01-02")
+	for day := gridStart; day.Before(gridEnd); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		class := "calendar-day"
+		if day.Before(from) || !day.Before(to) {
+			class += " calendar-outside"
+		}
+		if key == today {
+			class += " calendar-today"
+		}
+		dayFiles := days[key]
+		if len(dayFiles) > 0 {
+			class += " calendar-has-notes"
+		}
+		fmt.Fprintf(&b, `<div class="%s" data-date="%s"><span class="calendar-day-number">%d</span>`, class, key, day.Day())
+		if len(dayFiles) > 0 {
+			files.SortFilesDefault(dayFiles)
+			fmt.Fprintf(&b, `<span class="calendar-count">%d</span><ul class="calendar-notes">`, len(dayFiles))
+			for _, file := range dayFiles[:min(len(dayFiles), calendarDayLinks)] {
+				fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, file.ViewURL(), html.EscapeString(GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)))
+			}
+			if more := len(dayFiles) - calendarDayLinks; more > 0 {
+				fmt.Fprintf(&b, `<li class="calendar-more">%s</li>`, translation.SprintfForRequest(configmanager.GetLanguage(), "+%d more", more))
+			}
+			b.WriteString(`</ul>`)
+		}
+		b.WriteString(`</div>`)
+	}
+	b.WriteString(`</div></div>`)
+	return b.String(), nil
+}
+
+// renderKanbanWidget renders one column per value of the configured group field, with the
+// files having that value as cards. Files without a value are collected in a last column.
+// Cards keep the order they were dragged into (see kanban.MoveCardToField), the others
+// follow in the default file order.
+func renderKanbanWidget(config *dashboard.KanbanConfig) (string, error) {
+	if config == nil || config.GroupField == "" {
+		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "kanban group field is required"))
+	}
+	groupBy := config.GroupBy()
+	if !kanban.IsFieldGroupBy(groupBy) {
+		return "", errors.New(translation.SprintfForRequest(configmanager.GetLanguage(), "invalid kanban group field: %s", config.GroupField))
+	}
+
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return "", err
+	}
+	files.SortFilesDefault(allFiles)
+	groups := filter.GroupFiles(allFiles, groupBy)
+	if len(groups) == 0 {
+		return fmt.Sprintf(`<p class="no-items">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no files")), nil
+	}
+	stored, err := kanban.GetFieldOrder(groupBy)
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to load kanban widget order for %s: %v", groupBy, err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="kanban-widget" data-group-field="%s">`, html.EscapeString(config.GroupField))
+	for _, group := range groups {
+		label := html.EscapeString(group.Value)
+		if group.Value == "" {
+			label = translation.SprintfForRequest(configmanager.GetLanguage(), "no value")
+		}
+		byPath := make(map[string]files.File, len(group.Files))
+		paths := make([]string, 0, len(group.Files))
+		for _, file := range group.Files {
+			path := pathutils.ToRelative(file.Path)
+			byPath[path] = file
+			paths = append(paths, path)
+		}
+
+		fmt.Fprintf(&b, `<div class="kanban-widget-column" data-value="%s"><h4 class="kanban-widget-header">%s <span class="kanban-widget-count">(%d)</span></h4><ul class="kanban-widget-cards">`,
+			html.EscapeString(group.Value), label, len(group.Files))
+		for _, path := range kanban.ApplyOrder(stored[group.Value], paths) {
+			file := byPath[path]
+			fmt.Fprintf(&b, `<li class="kanban-widget-card" data-filepath="%s">%s<a href="%s">%s</a></li>`,
+				html.EscapeString(path), renderFileLabel(file.Metadata), file.ViewURL(), html.EscapeString(GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)))
+		}
+		b.WriteString(`</ul></div>`)
+	}
+	b.WriteString(`</div>`)
+	return b.String(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"knov/internal/configmanager"
 	"knov/internal/contentHandler"
 	"knov/internal/contentStorage"
+	"knov/internal/files"
 	"knov/internal/pathutils"
 	"knov/internal/translation"
 )
@@ -112,6 +113,8 @@ func RenderCodeMirrorEditorForm(filePath, prefillPath string, editorParam ...str
 		if rawContent, err := contentStorage.ReadFile(fullPath); err == nil {
 			content = string(rawContent)
 		}
+	} else {
+		content = files.NewFileScaffold(files.EditorTypeCodeMirror)
 	}
 
 	action := "/api/files/save"
@@ -10,6 +10,7 @@ import (
 	"knov/internal/configmanager"
 	"knov/internal/contentHandler"
 	"knov/internal/contentStorage"
+	"knov/internal/files"
 	"knov/internal/logging"
 	"knov/internal/parser"
 	"knov/internal/pathutils"
@@ -528,6 +529,8 @@ func RenderToastUIEditorForm(filePath, prefillPath string, editor ...string) str
 				content = string(rawContent)
 			}
 		}
+	} else {
+		content = files.NewFileScaffold(files.EditorTypeToastUI)
 	}
 
 	action := "/api/files/save"
@@ -3,6 +3,7 @@ package render
 
 import (
 	"fmt"
+	"html"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -82,6 +83,37 @@ func RenderFilesList(allFiles []files.File, deletable bool) string {
 	return html.String()
 }
 
+// RenderFilesListPage renders a page of the file list like RenderFilesList, followed by
+// first/prev/next/last buttons that swap in the other pages.
+func RenderFilesListPage(filePage *files.FilePage, deletable bool) string {
+	var html strings.Builder
+	html.WriteString(`<div class="file-list-page">`)
+	html.WriteString(RenderFilesList(filePage.Files, deletable))
+
+	totalPages := filePage.TotalPages()
+	if totalPages > 1 {
+		pageButton := func(label string, page int, enabled bool) {
+			if !enabled {
+				fmt.Fprintf(&html, `<button class="page-btn" disabled>%s</button>`, label)
+				return
+			}
+			fmt.Fprintf(&html, `<button hx-get="/api/files/list?page=%d&pageSize=%d&actions=%t" hx-target="closest .file-list-page" hx-swap="outerHTML" class="page-btn">%s</button>`,
+				page, filePage.PageSize, deletable, label)
+		}
+		lang := configmanager.GetLanguage()
+		html.WriteString(`<div class="pagination">`)
+		pageButton(translation.SprintfForRequest(lang, "First"), 1, filePage.Page > 1)
+		pageButton(translation.SprintfForRequest(lang, "Prev"), filePage.Page-1, filePage.Page > 1)
+		fmt.Fprintf(&html, `<span class="page-info">%s</span>`,
+			translation.SprintfForRequest(lang, "Page %d of %d (%d files)", filePage.Page, totalPages, filePage.Total))
+		pageButton(translation.SprintfForRequest(lang, "Next"), filePage.Page+1, filePage.Page < totalPages)
+		pageButton(translation.SprintfForRequest(lang, "Last"), totalPages, filePage.Page < totalPages)
+		html.WriteString(`</div>`)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
 // RenderFilteredFiles renders filtered files list with count - reuses RenderFileList
 func RenderFilteredFiles(filteredFiles []files.File) string {
 	var html strings.Builder
@@ -95,6 +127,12 @@ func RenderFileHeader(filepath string) string {
 	return fmt.Sprintf(`<hr/><div id="current-file-breadcrumb"><a href="/files/%s">→ %s</a></div>`, filepath, filepath)
 }
 
+// RenderEditorLink renders the link opening a file in the external editor.
+func RenderEditorLink(link string) string {
+	return fmt.Sprintf(`<a href="%s" class="editor-link"><i class="fa fa-external-link"></i> %s</a>`,
+		html.EscapeString(link), translation.SprintfForRequest(configmanager.GetLanguage(), "open in editor"))
+}
+
 // RenderBrowseFilesHTML renders browsed files as list.
 // If deletable is true, each row includes a hover-revealed delete button.
 func RenderBrowseFilesHTML(files []files.File, deletable bool) string {
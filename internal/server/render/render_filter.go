@@ -3,6 +3,8 @@ package render
 
 import (
 	"fmt"
+	"html"
+	"net/url"
 	"strings"
 
 	"knov/internal/configmanager"
@@ -85,6 +87,14 @@ func RenderFilterForm(opts FilterFormOpts) string {
 		criteriaTarget,
 		widgetIndexVals(opts),
 		translation.SprintfForRequest(configmanager.GetLanguage(), "add filter")))
+	validationTarget := strings.Replace(criteriaTarget, "filter-criteria-container", "filter-validation", 1)
+	if opts.Context == FilterFormContextSave || opts.Context == FilterFormContextDashboard {
+		html.WriteString(fmt.Sprintf(
+			`<button type="button" hx-post="/api/filters/validate" hx-include="closest form" hx-target="#%s"%s class="btn-secondary">%s</button>`,
+			validationTarget,
+			widgetIndexVals(opts),
+			translation.SprintfForRequest(configmanager.GetLanguage(), "validate")))
+	}
 	html.WriteString(renderLogicToggle(opts))
 	if opts.Context != FilterFormContextKanban {
 		html.WriteString(`<span class="filter-controls-sep"></span>`)
@@ -92,8 +102,12 @@ func RenderFilterForm(opts FilterFormOpts) string {
 		html.WriteString(fmt.Sprintf(`<input type="number" name="%s" value="%s" min="1" class="form-input filter-limit-input" title="%s"/>`,
 			filterFieldName(opts, "limit"), resolvedLimitValue(opts.Config),
 			translation.SprintfForRequest(configmanager.GetLanguage(), "limit")))
+		html.WriteString(renderSortSelects(opts))
+		html.WriteString(renderGroupBySelect(opts))
+		html.WriteString(renderColumnsInput(opts))
 	}
 	html.WriteString(`</div>`)
+	html.WriteString(fmt.Sprintf(`<div id="%s" class="filter-validation-status"></div>`, validationTarget))
 
 	// criteria
 	html.WriteString(fmt.Sprintf(`<div id="%s" class="filter-criteria-container">`, criteriaTarget))
@@ -173,8 +187,8 @@ func resolveFilterFormActionTarget(opts FilterFormOpts) (action, submitTarget st
 }
 
 func renderLogicToggle(opts FilterFormOpts) string {
-	selected := "and"
-	if opts.Config != nil {
+	selected := configmanager.GetFilterDefaultLogic()
+	if opts.Config != nil && opts.Config.Logic != "" {
 		selected = opts.Config.Logic
 	}
 	name := filterFieldName(opts, "logic")
@@ -203,6 +217,8 @@ func renderDisplaySelect(opts FilterFormOpts) string {
 		{"cards", translation.SprintfForRequest(configmanager.GetLanguage(), "cards")},
 		{"dropdown", translation.SprintfForRequest(configmanager.GetLanguage(), "dropdown")},
 		{"content", translation.SprintfForRequest(configmanager.GetLanguage(), "content")},
+		{"table", translation.SprintfForRequest(configmanager.GetLanguage(), "table")},
+		{"count", translation.SprintfForRequest(configmanager.GetLanguage(), "count")},
 	}
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf(`<select name="%s" class="form-select">`, name))
@@ -214,6 +230,73 @@ func renderDisplaySelect(opts FilterFormOpts) string {
 	return b.String()
 }
 
+// renderSortSelects renders the sort field and order selects; the empty options keep the
+// default file list sort.
+func renderSortSelects(opts FilterFormOpts) string {
+	sortBy, sortOrder := "", ""
+	if opts.Config != nil {
+		sortBy, sortOrder = opts.Config.SortBy, opts.Config.SortOrder
+	}
+	lang := configmanager.GetLanguage()
+	fieldOpts := []struct{ v, l string }{
+		{"", translation.SprintfForRequest(lang, "default sort")},
+		{files.SortByPath, translation.SprintfForRequest(lang, "Path")},
+		{files.SortByTitle, translation.SprintfForRequest(lang, "Title")},
+		{files.SortByCreatedAt, translation.SprintfForRequest(lang, "Created at")},
+		{files.SortByLastEdited, translation.SprintfForRequest(lang, "Last edited")},
+		{files.SortBySize, translation.SprintfForRequest(lang, "File size")},
+		{files.SortByWordCount, translation.SprintfForRequest(lang, "Word count")},
+	}
+	orderOpts := []struct{ v, l string }{
+		{"", translation.SprintfForRequest(lang, "default order")},
+		{"asc", translation.SprintfForRequest(lang, "ascending")},
+		{"desc", translation.SprintfForRequest(lang, "descending")},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<select name="%s" class="form-select" title="%s">`, filterFieldName(opts, "sortBy"), translation.SprintfForRequest(lang, "sort by"))
+	for _, o := range fieldOpts {
+		fmt.Fprintf(&b, `<option value="%s" %s>%s</option>`, o.v, utils.Ternary(sortBy == o.v, "selected", ""), o.l)
+	}
+	b.WriteString(`</select>`)
+	fmt.Fprintf(&b, `<select name="%s" class="form-select" title="%s">`, filterFieldName(opts, "sortOrder"), translation.SprintfForRequest(lang, "sort order"))
+	for _, o := range orderOpts {
+		fmt.Fprintf(&b, `<option value="%s" %s>%s</option>`, o.v, utils.Ternary(sortOrder == o.v, "selected", ""), o.l)
+	}
+	b.WriteString(`</select>`)
+	return b.String()
+}
+
+// renderGroupBySelect renders the select of the field list results are grouped by.
+func renderGroupBySelect(opts FilterFormOpts) string {
+	selected := ""
+	if opts.Config != nil {
+		selected = opts.Config.GroupBy
+	}
+	lang := configmanager.GetLanguage()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<select name="%s" class="form-select" title="%s">`, filterFieldName(opts, "groupBy"), translation.SprintfForRequest(lang, "group by"))
+	fmt.Fprintf(&b, `<option value="" %s>%s</option>`, utils.Ternary(selected == "", "selected", ""), translation.SprintfForRequest(lang, "no grouping"))
+	for _, field := range filter.GetGroupByFields() {
+		fmt.Fprintf(&b, `<option value="%s" %s>%s</option>`, field, utils.Ternary(selected == field, "selected", ""), translation.SprintfForRequest(lang, field))
+	}
+	b.WriteString(`</select>`)
+	return b.String()
+}
+
+// renderColumnsInput renders the table display column input; the placeholder shows the
+// filterTableColumns setting used when it is left empty.
+func renderColumnsInput(opts FilterFormOpts) string {
+	value := ""
+	if opts.Config != nil {
+		value = strings.Join(opts.Config.Columns, ",")
+	}
+	return fmt.Sprintf(`<input type="text" name="%s" value="%s" placeholder="%s" class="form-input filter-columns-input" title="%s"/>`,
+		filterFieldName(opts, "columns"), html.EscapeString(value),
+		html.EscapeString(strings.Join(configmanager.GetFilterTableColumns(), ",")),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "table columns (comma-separated)"))
+}
+
 func resolvedLimitValue(config *filter.Config) string {
 	if config != nil && config.Limit > 0 {
 		return fmt.Sprintf("%d", config.Limit)
@@ -225,37 +308,177 @@ func resolvedLimitValue(config *filter.Config) string {
 // ----------------------------------------- RESULT ----------------------------------------
 // ----------------------------------------------------------------------------------------
 
-// RenderFilterResult renders filter results based on display type
-func RenderFilterResult(result *filter.Result, display string) string {
+// RenderFilterResult renders filter results based on display type. columns are the
+// table display columns, empty uses the filterTableColumns setting.
+func RenderFilterResult(result *filter.Result, display string, columns []string) string {
+	if display == "count" {
+		total := 0
+		if result != nil {
+			total = result.Total
+		}
+		return fmt.Sprintf(`<div id="filter-results" class="filter-count"><span class="filter-count-value">%d</span> %s</div>`,
+			total, translation.SprintfForRequest(configmanager.GetLanguage(), "matching files"))
+	}
 	if result == nil || len(result.Files) == 0 {
 		return `<div id="filter-results" class="filter-no-results">
 			<p>` + translation.SprintfForRequest(configmanager.GetLanguage(), "no files found matching filter criteria") + `</p>
 		</div>`
 	}
 
+	var html string
 	switch display {
 	case "cards":
-		return fmt.Sprintf(`<div id="filter-results">%s</div>`, RenderFileCards(result.Files))
+		html = fmt.Sprintf(`<div id="filter-results">%s</div>`, RenderFileCards(result.Files))
 	case "dropdown":
-		return RenderFileDropdown(result.Files, result.Total)
+		html = RenderFileDropdown(result.Files, result.Total)
 	case "content":
-		return RenderFileContent(result.Files)
+		html = RenderFileContent(result.Files)
+	case "table":
+		html = fmt.Sprintf(`<div id="filter-results" class="table-wrapper">%s</div>`, renderFilterTable(result.Files, columns))
 	case "list2":
-		return fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-2">%s</div>`, renderFileListItems(result.Files))
+		html = fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-2">%s</div>`, renderFileListItems(result.Files))
 	case "list3":
-		return fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-3">%s</div>`, renderFileListItems(result.Files))
+		html = fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-3">%s</div>`, renderFileListItems(result.Files))
 	case "list4":
-		return fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-4">%s</div>`, renderFileListItems(result.Files))
+		html = fmt.Sprintf(`<div id="filter-results" class="filter-list-grid filter-list-grid-4">%s</div>`, renderFileListItems(result.Files))
 	default:
-		return fmt.Sprintf(`<div id="filter-results">%s</div>`, RenderFileList(result.Files))
+		if result.Groups != nil {
+			html = fmt.Sprintf(`<div id="filter-results" class="filter-groups">%s</div>`, renderFileGroups(result.Groups))
+		} else {
+			html = fmt.Sprintf(`<div id="filter-results">%s</div>`, RenderFileList(result.Files))
+		}
+	}
+
+	if result.Truncated {
+		html += fmt.Sprintf(`<p class="filter-truncated">%s</p>`,
+			translation.SprintfForRequest(configmanager.GetLanguage(), "showing %d of %d matching files, refine the filter to see the rest", len(result.Files), result.Total))
+	}
+	return html
+}
+
+// renderFileGroups renders grouped filter results as a file list per group under a header
+// with the group value and its file count.
+func renderFileGroups(groups []filter.Group) string {
+	var b strings.Builder
+	for _, group := range groups {
+		label := html.EscapeString(group.Value)
+		if group.Value == "" {
+			label = translation.SprintfForRequest(configmanager.GetLanguage(), "no value")
+		}
+		fmt.Fprintf(&b, `<section class="filter-group"><h4 class="filter-group-header">%s <span class="filter-group-count">(%d)</span></h4>%s</section>`,
+			label, len(group.Files), RenderFileList(group.Files))
+	}
+	return b.String()
+}
+
+// RenderFilterPresetList renders the quick filter presets, each running into #filter-results
+// with a delete button.
+func RenderFilterPresetList(names []string) string {
+	lang := configmanager.GetLanguage()
+	if len(names) == 0 {
+		return fmt.Sprintf(`<p class="no-items">%s</p>`, translation.SprintfForRequest(lang, "no filter presets"))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="filter-presets">`)
+	for _, name := range names {
+		fmt.Fprintf(&b, `<li><button type="button" class="btn-secondary" hx-get="/api/files/filter/preset?name=%s" hx-target="#filter-results" hx-swap="outerHTML">%s</button>`,
+			url.QueryEscape(name), html.EscapeString(name))
+		fmt.Fprintf(&b, `<button type="button" class="btn-icon btn-danger-icon" hx-delete="/api/filters/presets/%s" hx-target="closest li" hx-swap="delete" title="%s"><i class="fa fa-trash"></i></button></li>`,
+			url.PathEscape(name), translation.SprintfForRequest(lang, "delete"))
+	}
+	b.WriteString(`</ul>`)
+	return b.String()
+}
+
+// RenderSavedQueryList renders the saved text queries, each running into #filter-results
+// with a delete button.
+func RenderSavedQueryList(queries []filter.SavedQuery) string {
+	lang := configmanager.GetLanguage()
+	if len(queries) == 0 {
+		return fmt.Sprintf(`<p class="no-items">%s</p>`, translation.SprintfForRequest(lang, "no saved queries"))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="filter-presets saved-queries">`)
+	for _, q := range queries {
+		fmt.Fprintf(&b, `<li><button type="button" class="btn-secondary" hx-get="/api/files/query?name=%s" hx-target="#filter-results" hx-swap="outerHTML" title="%s">%s</button>`,
+			url.QueryEscape(q.Name), html.EscapeString(q.Query), html.EscapeString(q.Name))
+		fmt.Fprintf(&b, `<button type="button" class="btn-icon btn-danger-icon" hx-delete="/api/filters/queries/%s" hx-target="closest li" hx-swap="delete" title="%s"><i class="fa fa-trash"></i></button></li>`,
+			url.PathEscape(q.Name), translation.SprintfForRequest(lang, "delete"))
+	}
+	b.WriteString(`</ul>`)
+	return b.String()
+}
+
+// RenderFilterValidation renders the validation result of a filter config: one line per
+// invalid criterion, or configErr when the config itself is invalid (e.g. its logic).
+func RenderFilterValidation(configErr string, criteria []filter.CriterionValidation) string {
+	lang := configmanager.GetLanguage()
+	if configErr == "" {
+		return RenderStatusMessage(StatusOK, translation.SprintfForRequest(lang, "filter is valid"))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="filter-validation">`)
+	invalidCriteria := 0
+	for _, c := range criteria {
+		if c.Valid {
+			continue
+		}
+		invalidCriteria++
+		fmt.Fprintf(&b, `<li>%s</li>`, RenderStatusMessage(StatusError,
+			translation.SprintfForRequest(lang, "criterion %d (%s %s): %s", c.Index+1, html.EscapeString(c.Criterion.Metadata), html.EscapeString(c.Criterion.Operator), html.EscapeString(c.Error))))
+	}
+	if invalidCriteria == 0 {
+		fmt.Fprintf(&b, `<li>%s</li>`, RenderStatusMessage(StatusError, html.EscapeString(configErr)))
+	}
+	b.WriteString(`</ul>`)
+	return b.String()
+}
+
+// renderFilterTable renders files as a table with one column per metadata field in columns,
+// falling back to the filterTableColumns setting. Unknown columns are skipped.
+func renderFilterTable(fileList []files.File, configured []string) string {
+	columns := filter.TableColumns(configured)
+
+	var b strings.Builder
+	b.WriteString(`<table class="filter-table"><thead><tr>`)
+	for _, column := range columns {
+		label, isCustom := strings.CutPrefix(column, "custom.")
+		if !isCustom {
+			label = translation.SprintfForRequest(configmanager.GetLanguage(), column)
+		}
+		fmt.Fprintf(&b, `<th data-column="%s">%s</th>`, html.EscapeString(column), html.EscapeString(label))
+	}
+	b.WriteString(`</tr></thead><tbody>`)
+	for _, file := range fileList {
+		b.WriteString(`<tr>`)
+		for _, column := range columns {
+			fmt.Fprintf(&b, `<td>%s</td>`, renderFilterTableCell(file, column))
+		}
+		b.WriteString(`</tr>`)
+	}
+	b.WriteString(`</tbody></table>`)
+	return b.String()
+}
+
+// renderFilterTableCell renders the value of one metadata field of a file as table cell content.
+func renderFilterTableCell(file files.File, column string) string {
+	if column == "title" {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, file.ViewURL(), GetLinkDisplayTextWithMetadata(file.Path, file.Metadata))
+	}
+	if column == "label" && file.Metadata != nil {
+		return renderFileLabel(file.Metadata) + html.EscapeString(file.Metadata.Label)
 	}
+	return html.EscapeString(filter.TableCellValue(file, column))
 }
 
 // renderFileListItems renders file list items as bare <a> tags for grid layouts
 func renderFileListItems(fileList []files.File) string {
 	var b strings.Builder
 	for _, file := range fileList {
-		b.WriteString(fmt.Sprintf(`<a class="filter-list-item" href="%s">%s</a>`, file.ViewURL(), GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)))
+		b.WriteString(fmt.Sprintf(`<a class="filter-list-item" href="%s">%s%s</a>`, file.ViewURL(), renderFileLabel(file.Metadata), GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)))
 	}
 	return b.String()
 }
@@ -372,6 +595,7 @@ func RenderOperatorOptions(selectedValue string) string {
 		translation.SprintfForRequest(configmanager.GetLanguage(), "greater than"),
 		translation.SprintfForRequest(configmanager.GetLanguage(), "less than"),
 		translation.SprintfForRequest(configmanager.GetLanguage(), "in array"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "under folder"),
 	}
 	for i, operator := range operators {
 		selected := ""
@@ -427,10 +651,14 @@ func filterValueInputMeta(metadataField string) (apiEndpoint, placeholder string
 		return "/api/metadata/folders?format=options", translation.SprintfForRequest(configmanager.GetLanguage(), "type or select folder")
 	case "editor":
 		return "/api/metadata/editors?format=options", translation.SprintfForRequest(configmanager.GetLanguage(), "select editor type")
+	case "label":
+		return "/api/metadata/labels", translation.SprintfForRequest(configmanager.GetLanguage(), "select label")
 	case "title":
 		return "/api/metadata/titles?format=options", translation.SprintfForRequest(configmanager.GetLanguage(), "type or select title")
 	case "child-of", "parent-of", "ancestor-of":
 		return "/api/files/list?format=options", translation.SprintfForRequest(configmanager.GetLanguage(), "select file")
+	case "wordCount", "readingTimeMinutes":
+		return "", translation.SprintfForRequest(configmanager.GetLanguage(), "enter a number")
 	default:
 		return "", translation.SprintfForRequest(configmanager.GetLanguage(), "enter value")
 	}
@@ -345,3 +345,21 @@ func RenderConflictDiff(originalPath, conflictPath string) string {
 	html.WriteString(`</div>`)
 	return html.String()
 }
+
+// RenderGitConflict renders the error of a pull that couldn't fast-forward, listing the
+// files changed both locally and on the remote.
+func RenderGitConflict(conflictFiles []string) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="git-conflict">`)
+	if len(conflictFiles) == 0 {
+		fmt.Fprintf(&sb, `<span class="%s">%s</span>`, StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), "local and remote branch diverged"))
+	} else {
+		fmt.Fprintf(&sb, `<span class="%s">%s</span><ul class="git-conflict-files">`, StatusError, translation.SprintfForRequest(configmanager.GetLanguage(), "pull failed, these files were changed locally and on the remote:"))
+		for _, file := range conflictFiles {
+			fmt.Fprintf(&sb, `<li>%s</li>`, htmlpkg.EscapeString(file))
+		}
+		sb.WriteString(`</ul>`)
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
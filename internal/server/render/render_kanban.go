@@ -52,6 +52,7 @@ func RenderKanbanCard(card kanban.Card) string {
 
 	// title + tag chips on the same row
 	html.WriteString(`<div class="kanban-card-header">`)
+	html.WriteString(renderLabelDot(card.Label))
 	fmt.Fprintf(&html, `<a class="kanban-card-title" href="/files/%s" title="%s">%s</a>`, card.FilePath, displayTitle, displayTitle)
 	if len(visibleTags) > 0 {
 		tagColors := configmanager.GetKanbanTagColors()
@@ -3,6 +3,7 @@ package render
 
 import (
 	"fmt"
+	htmlpkg "html"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -116,6 +117,30 @@ func RenderNoLinksMessage(message string) string {
 	return fmt.Sprintf(`<div class="connection-empty">%s</div>`, message)
 }
 
+// RenderLinkGraph renders the edges of a link graph as a list, grouped by source file
+func RenderLinkGraph(graph *files.LinkGraph) string {
+	if len(graph.Edges) == 0 {
+		return RenderNoLinksMessage(translation.SprintfForRequest(configmanager.GetLanguage(), "no links found"))
+	}
+
+	titles := make(map[string]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		titles[node.ID] = node.Title
+	}
+
+	var html strings.Builder
+	fmt.Fprintf(&html, `<div class="link-graph"><p>%s</p><ul>`,
+		translation.SprintfForRequest(configmanager.GetLanguage(), "%d files, %d connections", len(graph.Nodes), len(graph.Edges)))
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&html, `<li><a href="%s">%s</a> <span class="link-graph-relation">%s</span> <a href="%s">%s</a></li>`,
+			pathutils.ToFileURL(pathutils.ToRelative(edge.Source)), htmlpkg.EscapeString(titles[edge.Source]),
+			translation.SprintfForRequest(configmanager.GetLanguage(), edge.Relation),
+			pathutils.ToFileURL(pathutils.ToRelative(edge.Target)), htmlpkg.EscapeString(titles[edge.Target]))
+	}
+	html.WriteString(`</ul></div>`)
+	return html.String()
+}
+
 // RenderLinksList renders a list of file links (non-media) as HTML with configurable display text
 func RenderLinksList(links []string, _ bool) string {
 	if len(links) == 0 {
@@ -174,6 +199,31 @@ func RenderAncestorLinks(ancestors []string) string {
 	return RenderLinksList(ancestors, false)
 }
 
+// RenderHierarchyProblems renders the files whose parent chain loops or is too deep, each
+// with the chain that was followed.
+func RenderHierarchyProblems(problems []files.HierarchyProblem) string {
+	if len(problems) == 0 {
+		return RenderNoLinksMessage(translation.SprintfForRequest(configmanager.GetLanguage(), "no hierarchy problems"))
+	}
+
+	var html strings.Builder
+	html.WriteString(`<ul class="hierarchy-problems">`)
+	for _, problem := range problems {
+		kind := translation.SprintfForRequest(configmanager.GetLanguage(), "parent chain loops")
+		if problem.Kind == files.HierarchyTooDeep {
+			kind = translation.SprintfForRequest(configmanager.GetLanguage(), "parent chain too deep")
+		}
+		chain := make([]string, 0, len(problem.Chain))
+		for _, path := range problem.Chain {
+			chain = append(chain, htmlpkg.EscapeString(pathutils.ToRelative(path)))
+		}
+		fmt.Fprintf(&html, `<li class="hierarchy-problem hierarchy-problem-%s">%s <span class="hierarchy-problem-kind">%s</span>: <span class="hierarchy-problem-chain">%s</span></li>`,
+			htmlpkg.EscapeString(problem.Kind), RenderLinksList([]string{problem.Path}, false), kind, strings.Join(chain, " &rarr; "))
+	}
+	html.WriteString(`</ul>`)
+	return html.String()
+}
+
 // RenderKidsLinks renders children links or no children message
 func RenderKidsLinks(kids []string) string {
 	if len(kids) == 0 {
@@ -3,11 +3,15 @@ package render
 
 import (
 	"fmt"
+	htmlpkg "html"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"knov/internal/configmanager"
 	"knov/internal/files"
+	"knov/internal/pathutils"
 	"knov/internal/translation"
 )
 
@@ -102,6 +106,44 @@ func RenderReferencesSidebarHTML(refs []files.Reference) string {
 	return html.String()
 }
 
+// RenderRecentlyCreatedList renders recently created files, newest first, with their creation date
+func RenderRecentlyCreatedList(created []*files.Metadata) string {
+	var html strings.Builder
+	if len(created) == 0 {
+		fmt.Fprintf(&html, `<p class="no-items">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no files found"))
+		return html.String()
+	}
+	html.WriteString(`<ul class="recently-created">`)
+	for _, metadata := range created {
+		relPath := pathutils.ToRelative(metadata.Path)
+		title := metadata.Title
+		if title == "" {
+			title = filepath.Base(relPath)
+		}
+		fmt.Fprintf(&html, `<li>%s - <a href="%s"><strong>%s</strong></a></li>`,
+			configmanager.FormatDateTime(metadata.CreatedAt), pathutils.ToFileURL(relPath), htmlpkg.EscapeString(title))
+	}
+	html.WriteString(`</ul>`)
+	return html.String()
+}
+
+// RenderWordCount renders the word count and reading time of a file
+func RenderWordCount(metadata *files.Metadata) string {
+	return fmt.Sprintf(`<span class="wordcount">%s</span>`, translation.SprintfForRequest(configmanager.GetLanguage(),
+		"%d words, %d min read", metadata.WordCount, metadata.ReadingTimeMinutes))
+}
+
+// RenderEditorTypeIcons renders the icon of every editor type, sorted by editor type
+func RenderEditorTypeIcons(icons map[files.EditorType]string) string {
+	var html strings.Builder
+	html.WriteString(`<ul class="filetype-icons">`)
+	for _, editor := range slices.Sorted(maps.Keys(icons)) {
+		fmt.Fprintf(&html, `<li><i class="fa %s"></i> %s</li>`, icons[editor], htmlpkg.EscapeString(string(editor)))
+	}
+	html.WriteString(`</ul>`)
+	return html.String()
+}
+
 // RenderReferencesHTML renders the references list with a delete button per entry
 func RenderReferencesHTML(refs []files.Reference) string {
 	var html strings.Builder
@@ -130,6 +172,32 @@ func RenderReferencesHTML(refs []files.Reference) string {
 	return html.String()
 }
 
+// RenderCustomMetadataHTML renders a file's custom key/value metadata, sorted by key
+func RenderCustomMetadataHTML(filePath string, custom map[string]string) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-custom-metadata">`)
+	if len(custom) == 0 {
+		fmt.Fprintf(&html, `<p class="no-items">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no custom metadata"))
+	}
+	for _, key := range slices.Sorted(maps.Keys(custom)) {
+		html.WriteString(`<div class="reference-item">`)
+		fmt.Fprintf(&html, `<div class="reference-item-main"><strong>%s</strong><span class="reference-description">%s</span></div>`, key, custom[key])
+		fmt.Fprintf(&html, `<div class="reference-item-actions"><button hx-post="/api/metadata/custom" hx-vals='{"filepath":"%s","key":"%s","value":""}' hx-target="#component-custom-metadata" hx-swap="outerHTML" class="btn-icon btn-danger-icon" title="%s"><i class="fa fa-trash"></i></button></div>`,
+			filePath, key, translation.SprintfForRequest(configmanager.GetLanguage(), "remove"))
+		html.WriteString(`</div>`)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
+// RenderMetadataLabelHTML renders the color label of a file as a dot followed by its name
+func RenderMetadataLabelHTML(label string) string {
+	if label == "" {
+		return `<span class="meta-empty">-</span>`
+	}
+	return renderLabelDot(label) + htmlpkg.EscapeString(label)
+}
+
 // RenderBrokenLinksHTML renders the scan result of FindBrokenLinks as a
 // checkbox list of proposed repairs, all checked by default. Broken links
 // with no suggested fix are omitted - there's nothing to select for those.
@@ -169,6 +237,151 @@ func RenderBrokenLinksHTML(broken []files.BrokenLink) string {
 	return html.String()
 }
 
+// RenderMetadataValidationHTML renders the issues of files.ValidateAllMetadata as a table.
+func RenderMetadataValidationHTML(issues []files.ValidationIssue) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-validation">`)
+
+	if len(issues) == 0 {
+		fmt.Fprintf(&html, `<p class="no-items">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no metadata inconsistencies found"))
+		html.WriteString(`</div>`)
+		return html.String()
+	}
+
+	fmt.Fprintf(&html, `<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "%d metadata inconsistencies found", len(issues)))
+	fmt.Fprintf(&html, `<table class="metadata-validation-table"><thead><tr><th>%s</th><th>%s</th><th>%s</th></tr></thead><tbody>`,
+		translation.SprintfForRequest(configmanager.GetLanguage(), "file"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "field"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "problem"))
+	for _, issue := range issues {
+		url := pathutils.ToFileURL(pathutils.ToRelative(issue.Path))
+		if pathutils.IsMedia(issue.Path) {
+			url = pathutils.ToMediaURL(pathutils.ToRelative(issue.Path))
+		}
+		fmt.Fprintf(&html, `<tr><td><a href="%s">%s</a></td><td>%s</td><td>%s</td></tr>`,
+			url, htmlpkg.EscapeString(issue.Path),
+			htmlpkg.EscapeString(issue.Field), htmlpkg.EscapeString(issue.Problem))
+	}
+	html.WriteString(`</tbody></table></div>`)
+	return html.String()
+}
+
+// RenderMetadataHistoryHTML renders the metadata history of a file as a table of changes,
+// newest first.
+func RenderMetadataHistoryHTML(changes []files.MetadataChange) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-history">`)
+
+	if len(changes) == 0 {
+		fmt.Fprintf(&html, `<p class="no-items">%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no metadata changes recorded"))
+		html.WriteString(`</div>`)
+		return html.String()
+	}
+
+	fmt.Fprintf(&html, `<table class="metadata-history-table"><thead><tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr></thead><tbody>`,
+		translation.SprintfForRequest(configmanager.GetLanguage(), "changed at"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "field"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "old value"),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "new value"))
+	for _, change := range changes {
+		fmt.Fprintf(&html, `<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			configmanager.FormatDateTimeSeconds(change.Timestamp), htmlpkg.EscapeString(change.Field),
+			htmlpkg.EscapeString(change.OldValue), htmlpkg.EscapeString(change.NewValue))
+	}
+	html.WriteString(`</tbody></table></div>`)
+	return html.String()
+}
+
+// RenderMetadataRepairHTML renders the report of files.RepairMetadata as a table of changes.
+func RenderMetadataRepairHTML(report files.RepairReport) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-repair">`)
+
+	var summary string
+	switch {
+	case len(report.Changes) == 0:
+		summary = translation.SprintfForRequest(configmanager.GetLanguage(), "nothing to repair")
+	case report.DryRun:
+		summary = translation.SprintfForRequest(configmanager.GetLanguage(), "%d changes in %d files would be made", len(report.Changes), report.Files)
+	default:
+		summary = translation.SprintfForRequest(configmanager.GetLanguage(), "repaired %d files with %d changes", report.Files, len(report.Changes))
+	}
+	fmt.Fprintf(&html, `<p>%s</p>`, summary)
+
+	if len(report.Changes) > 0 {
+		fmt.Fprintf(&html, `<table class="metadata-validation-table"><thead><tr><th>%s</th><th>%s</th><th>%s</th></tr></thead><tbody>`,
+			translation.SprintfForRequest(configmanager.GetLanguage(), "file"),
+			translation.SprintfForRequest(configmanager.GetLanguage(), "field"),
+			translation.SprintfForRequest(configmanager.GetLanguage(), "change"))
+		for _, change := range report.Changes {
+			fmt.Fprintf(&html, `<tr><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				htmlpkg.EscapeString(change.Path), htmlpkg.EscapeString(change.Field), htmlpkg.EscapeString(change.Change))
+		}
+		html.WriteString(`</tbody></table>`)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
+// RenderMetadataBulkSetHTML renders the per-file report of files.MetaDataBulkSet, failures first.
+func RenderMetadataBulkSetHTML(results []files.BulkSetResult) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-bulk">`)
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	fmt.Fprintf(&html, `<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "%d of %d files updated", len(results)-failed, len(results)))
+
+	if failed > 0 {
+		fmt.Fprintf(&html, `<table class="metadata-validation-table"><thead><tr><th>%s</th><th>%s</th></tr></thead><tbody>`,
+			translation.SprintfForRequest(configmanager.GetLanguage(), "file"),
+			translation.SprintfForRequest(configmanager.GetLanguage(), "error"))
+		for _, result := range results {
+			if !result.Success {
+				fmt.Fprintf(&html, `<tr><td>%s</td><td>%s</td></tr>`, htmlpkg.EscapeString(result.Path), htmlpkg.EscapeString(result.Error))
+			}
+		}
+		html.WriteString(`</tbody></table>`)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
+// RenderMetadataSuggestions renders the suggested tags and collections of a new note with
+// their scores.
+func RenderMetadataSuggestions(suggestions *files.Suggestions) string {
+	lang := configmanager.GetLanguage()
+	if len(suggestions.Tags) == 0 && len(suggestions.Collections) == 0 {
+		return fmt.Sprintf(`<div id="component-metadata-suggestions"><p class="no-items">%s</p></div>`, translation.SprintfForRequest(lang, "no suggestions"))
+	}
+
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-suggestions">`)
+	for _, group := range []struct {
+		label string
+		items []files.Suggestion
+	}{
+		{translation.SprintfForRequest(lang, "tags"), suggestions.Tags},
+		{translation.SprintfForRequest(lang, "collection"), suggestions.Collections},
+	} {
+		if len(group.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&html, `<p><strong>%s:</strong> `, group.label)
+		for _, item := range group.items {
+			fmt.Fprintf(&html, `<span class="suggestion" data-value="%s" title="%.0f%%">%s</span> `,
+				htmlpkg.EscapeString(item.Name), item.Score*100, htmlpkg.EscapeString(item.Name))
+		}
+		html.WriteString(`</p>`)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
 // brokenLinkSuggestedCell renders the suggested-fix path, with a thumbnail
 // preview when the suggestion is an image, so the fix can be eyeballed before applying.
 func brokenLinkSuggestedCell(suggested string) string {
@@ -183,43 +396,33 @@ func brokenLinkSuggestedCell(suggested string) string {
 		relativePath, filepath.Base(relativePath), suggested)
 }
 
-// RenderMetadataCSV generates CSV content for metadata export
-func RenderMetadataCSV(metadata []*files.Metadata) string {
-	var csv strings.Builder
-
-	// header
-	csv.WriteString("path,title,collection,editor,createdat,lastedited,tags,folders\n")
-
-	for _, m := range metadata {
-		if m == nil {
-			continue
+// RenderMetadataImportSummary renders the outcome of a metadata import with the reasons
+// rows were skipped.
+func RenderMetadataImportSummary(summary files.MetadataImportSummary) string {
+	var html strings.Builder
+	html.WriteString(`<div id="component-metadata-import">`)
+	fmt.Fprintf(&html, `<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "%d rows imported, %d skipped", summary.Imported, summary.Skipped))
+	if len(summary.Errors) > 0 {
+		html.WriteString(`<ul class="metadata-import-errors">`)
+		for _, err := range summary.Errors {
+			fmt.Fprintf(&html, `<li>%s</li>`, htmlpkg.EscapeString(err))
 		}
-
-		// escape csv values
-		path := escapeCSV(m.Path)
-		name := escapeCSV(m.Title)
-		collection := escapeCSV(m.Collection)
-		editor := escapeCSV(string(m.Editor))
-		createdat := configmanager.FormatDateTimeSeconds(m.CreatedAt)
-		lastedited := configmanager.FormatDateTimeSeconds(m.LastEdited)
-		tags := escapeCSV(strings.Join(m.Tags, ";"))
-		folders := escapeCSV(strings.Join(m.Folders, ";"))
-
-		csv.WriteString(fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s\n",
-			path, name, collection, editor, createdat, lastedited,
-			tags, folders))
+		html.WriteString(`</ul>`)
 	}
-
-	return csv.String()
+	html.WriteString(`</div>`)
+	return html.String()
 }
 
-// escapeCSV escapes a string for CSV format
-func escapeCSV(s string) string {
-	if strings.Contains(s, ",") || strings.Contains(s, "\"") || strings.Contains(s, "\n") {
-		s = strings.ReplaceAll(s, "\"", "\"\"")
-		return "\"" + s + "\""
+// RenderMetadataCollectionHTML renders the collection of a file as a browse link followed by
+// a link to the collection's index file, when it has one.
+func RenderMetadataCollectionHTML(collection, index string) string {
+	html := RenderMetadataLinkHTML(collection, "collection")
+	if index == "" {
+		return html
 	}
-	return s
+	title := translation.SprintfForRequest(configmanager.GetLanguage(), "part of collection %s", collection)
+	return html + fmt.Sprintf(` <a href="/files/%s" class="meta-link collection-index" title="%s"><i class="fa fa-list"></i></a>`,
+		index, htmlpkg.EscapeString(title))
 }
 
 // RenderFileMetadataSimple renders a simple metadata display for regular files
@@ -235,7 +438,7 @@ func RenderFileMetadataSimple(metadata *files.Metadata) string {
 	fmt.Fprintf(&html, `<p>%s: %s</p>`,
 		translation.SprintfForRequest(configmanager.GetLanguage(), "path"), metadata.Path)
 	fmt.Fprintf(&html, `<p>%s: %s</p>`,
-		translation.SprintfForRequest(configmanager.GetLanguage(), "collection"), metadata.Collection)
+		translation.SprintfForRequest(configmanager.GetLanguage(), "collection"), RenderMetadataCollectionHTML(metadata.Collection, files.CollectionIndex(metadata.Path)))
 	fmt.Fprintf(&html, `<p>%s: %s</p>`,
 		translation.SprintfForRequest(configmanager.GetLanguage(), "editor"), metadata.Editor)
 
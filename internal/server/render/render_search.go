@@ -51,34 +51,45 @@ func RenderSearchDropdown(results []files.File, query string) string {
 	return html.String()
 }
 
-// RenderSearchCards creates cards HTML for file results with search context
-func RenderSearchCards(results []files.File, query string) string {
+// RenderSearchCards creates cards HTML for file results with search context. snippets maps
+// file paths to highlighted excerpts; files without one get a context extracted here.
+func RenderSearchCards(results []files.File, query string, snippets map[string]string) string {
 	var html strings.Builder
-	if query != "" {
-		html.WriteString(fmt.Sprintf(`<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "found %d results for \"%s\"", len(results), query)))
-	}
-	html.WriteString(RenderSearchResultsCards(results, query))
+	html.WriteString(renderSearchSummary(results, query))
+	html.WriteString(RenderSearchResultsCards(results, query, snippets))
 	return html.String()
 }
 
 // RenderSearchList creates simple list HTML for file results with search context
 func RenderSearchList(results []files.File, query string) string {
 	var html strings.Builder
-	if query != "" {
-		html.WriteString(fmt.Sprintf(`<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "found %d results for \"%s\"", len(results), query)))
-	}
+	html.WriteString(renderSearchSummary(results, query))
 	html.WriteString(RenderFileList(results))
 	return html.String()
 }
 
+// renderSearchSummary renders the result count line, telling fuzzy results apart
+func renderSearchSummary(results []files.File, query string) string {
+	if query == "" {
+		return ""
+	}
+	if len(results) > 0 && results[0].Fuzzy {
+		return fmt.Sprintf(`<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "no exact matches for \"%s\", showing %d similar titles", html.EscapeString(query), len(results)))
+	}
+	return fmt.Sprintf(`<p>%s</p>`, translation.SprintfForRequest(configmanager.GetLanguage(), "found %d results for \"%s\"", len(results), query))
+}
+
 // RenderSearchResultsCards renders search results as clickable cards with context
-func RenderSearchResultsCards(files []files.File, query string) string {
+func RenderSearchResultsCards(files []files.File, query string, snippets map[string]string) string {
 	var html strings.Builder
 	html.WriteString(`<div id="search-results-cards">`)
 
 	for _, file := range files {
 		displayText := GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)
-		context := extractSearchContext(file.Path, query)
+		context, ok := snippets[file.Path]
+		if !ok {
+			context = extractSearchContext(file.Path, query)
+		}
 
 		html.WriteString(fmt.Sprintf(`
 			<div class="search-result-card">
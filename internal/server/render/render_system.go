@@ -352,7 +352,7 @@ function loadMoreLogLines() {
 </script>`
 
 	tm := thememanager.GetThemeManager()
-	if err := tm.RenderSystemPage(w, "Logs", template.HTML(content)); err != nil {
+	if err := tm.RenderSystemPage(w, r, "Logs", template.HTML(content)); err != nil {
 		logging.LogError(logging.KeyApp, "failed to render logs page: %v", err)
 	}
 }
@@ -387,6 +387,25 @@ func RenderJobsTable(runs []job.JobRun) string {
 	return sb.String()
 }
 
+// RenderQueuedJob renders the status of a queued job. While the job isn't done the element
+// polls its own status and replaces itself.
+func RenderQueuedJob(j *job.QueuedJob) string {
+	poll := ""
+	if !j.Done() {
+		poll = fmt.Sprintf(` hx-get="/api/jobs/%s" hx-trigger="every 1s" hx-swap="outerHTML" hx-headers='{"Accept":"text/html"}'`, html.EscapeString(j.ID))
+	}
+	status := string(j.Status)
+	if j.Status == job.JobStatusRunning {
+		status = fmt.Sprintf("%s %d%%", status, j.Progress)
+	}
+	out := fmt.Sprintf(`<div class="queued-job job-status-%s" data-job-id="%s"%s><span class="queued-job-name">%s</span> <span class="queued-job-status">%s</span>`,
+		html.EscapeString(string(j.Status)), html.EscapeString(j.ID), poll, html.EscapeString(j.Operation), html.EscapeString(status))
+	if j.Error != "" {
+		out += fmt.Sprintf(` <span class="queued-job-error">%s</span>`, html.EscapeString(j.Error))
+	}
+	return out + `</div>`
+}
+
 func HandleSystemJobs(w http.ResponseWriter, r *http.Request) {
 	content := `<style>
 .jobs-table { width: 100%; border-collapse: collapse; font-size: .85rem; }
@@ -403,7 +422,7 @@ func HandleSystemJobs(w http.ResponseWriter, r *http.Request) {
 		`<div id="jobs-entries" hx-get="/api/system/jobs" hx-trigger="load, every 3s" hx-swap="innerHTML" hx-headers='{"Accept":"text/html"}'></div>`
 
 	tm := thememanager.GetThemeManager()
-	if err := tm.RenderSystemPage(w, "Jobs", template.HTML(content)); err != nil {
+	if err := tm.RenderSystemPage(w, r, "Jobs", template.HTML(content)); err != nil {
 		logging.LogError(logging.KeyApp, "failed to render jobs page: %v", err)
 	}
 }
@@ -451,7 +470,7 @@ func HandleSystemChangelog(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileViewTemplateData("Changelog", "system/changelog.md", fileContent)
 	data.SystemPage = true
-	if err := tm.Render(w, "fileview", data); err != nil {
+	if err := tm.Render(w, r, "fileview", data); err != nil {
 		logging.LogError(logging.KeyApp, "failed to render changelog page: %v", err)
 	}
 }
@@ -478,7 +497,7 @@ func HandleSystemVersion(w http.ResponseWriter, r *http.Request) {
 		`<a class="version-changelog-link" href="/system/changelog">Release notes / Changelog &rarr;</a>`
 
 	tm := thememanager.GetThemeManager()
-	if err := tm.RenderSystemPage(w, "Version", template.HTML(content)); err != nil {
+	if err := tm.RenderSystemPage(w, r, "Version", template.HTML(content)); err != nil {
 		logging.LogError(logging.KeyApp, "failed to render version page: %v", err)
 	}
 }
@@ -2,7 +2,9 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
+	"html"
 	"net/url"
 	"strings"
 
@@ -63,6 +65,16 @@ func RenderSelectOptions(options []SelectOption, selectedValue string) string {
 	return html
 }
 
+// RenderDatalistOptions renders option elements for a datalist, value and label being the same
+func RenderDatalistOptions(values []string) string {
+	var b strings.Builder
+	for _, value := range values {
+		escaped := html.EscapeString(value)
+		fmt.Fprintf(&b, `<option value="%s">%s</option>`, escaped, escaped)
+	}
+	return b.String()
+}
+
 // RenderCheckbox renders a checkbox input with htmx attributes
 func RenderCheckbox(name, endpoint string, checked bool, extraAttrs string) string {
 	checkedAttr := ""
@@ -87,6 +99,23 @@ func RenderTextarea(name, content string, rows int, extraAttrs string) string {
 	return fmt.Sprintf(`<textarea %s>%s</textarea>`, baseAttrs, content)
 }
 
+// renderFileLabel renders the color label of a file as a dot, "" when it has none.
+func renderFileLabel(metadata *files.Metadata) string {
+	if metadata == nil {
+		return ""
+	}
+	return renderLabelDot(metadata.Label)
+}
+
+// renderLabelDot renders a color label as a dot, "" for no label. Labels are validated
+// against the labelColors setting, so they are safe in a style attribute.
+func renderLabelDot(label string) string {
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<span class="file-label" style="background-color:%s" title="%s"></span>`, label, label)
+}
+
 // RenderFileCards renders files as cards without search context
 func RenderFileCards(files []files.File) string {
 	var html strings.Builder
@@ -96,9 +125,9 @@ func RenderFileCards(files []files.File) string {
 		displayText := GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)
 		html.WriteString(fmt.Sprintf(`
 			<div class="search-result-card">
-			<h4><a href="%s">%s</a></h4>
+			<h4>%s<a href="%s">%s</a></h4>
 			</div>`,
-			file.ViewURL(), displayText))
+			renderFileLabel(file.Metadata), file.ViewURL(), displayText))
 	}
 
 	html.WriteString(`</div>`)
@@ -113,8 +142,8 @@ func RenderFileList(files []files.File) string {
 	for _, file := range files {
 		displayText := GetLinkDisplayTextWithMetadata(file.Path, file.Metadata)
 		html.WriteString(fmt.Sprintf(`
-			<li><a href="%s">%s</a></li>`,
-			file.ViewURL(), displayText))
+			<li>%s<a href="%s">%s</a></li>`,
+			renderFileLabel(file.Metadata), file.ViewURL(), displayText))
 	}
 
 	html.WriteString(`</ul>`)
@@ -150,6 +179,18 @@ func RenderFileDropdown(files []files.File, limit int) string {
 	return html.String()
 }
 
+// renderEmbedTruncated renders the "view full note" link below embedded content that was cut
+// at the embedMaxBytes setting, nothing for content embedded whole.
+func renderEmbedTruncated(filePath string, content *files.FileContent) string {
+	if !content.Truncated {
+		return ""
+	}
+	return fmt.Sprintf(`<p class="embed-truncated">%s <a href="%s">%s</a></p>`,
+		translation.SprintfForRequest(configmanager.GetLanguage(), "content truncated."),
+		pathutils.ToFileURL(filePath),
+		translation.SprintfForRequest(configmanager.GetLanguage(), "view full note"))
+}
+
 // DONT RENAME filez to files since files.GetFileContent is not working than!!
 // RenderFileContent renders files with their actual content displayed
 func RenderFileContent(filez []files.File) string {
@@ -162,7 +203,7 @@ func RenderFileContent(filez []files.File) string {
 		  <h4><a href="%s">%s</a></h4>`, file.ViewURL(), displayText))
 
 		fullPath := pathutils.ToDocsPath(file.Path)
-		content, err := files.GetFileContent(fullPath)
+		content, err := files.GetFileContentLimited(fullPath, configmanager.GetEmbedMaxBytes())
 		if err != nil {
 			html.WriteString(`<p class="filter-content-error">` + translation.SprintfForRequest(configmanager.GetLanguage(), "error loading content: %s", err.Error()) + `</p>`)
 		} else {
@@ -176,6 +217,7 @@ func RenderFileContent(filez []files.File) string {
 				html.WriteString(`</nav>`)
 			}
 			html.WriteString(fmt.Sprintf(`<div class="filter-content-body file-content">%s</div>`, injected))
+			html.WriteString(renderEmbedTruncated(file.Path, content))
 		}
 
 		html.WriteString(`</div>`)
@@ -339,6 +381,12 @@ func GenerateInputWithSaveOnBlur(id, name, value, placeholder, filePath, saveEnd
 		id, name, value, placeholder, saveEndpoint, filePath)
 }
 
+// hxVals encodes vals as the JSON of an hx-vals attribute, escaped for a quoted attribute value.
+func hxVals(vals map[string]string) string {
+	data, _ := json.Marshal(vals) //nolint:errcheck // string maps always marshal
+	return html.EscapeString(string(data))
+}
+
 // RenderBrowseHTML renders a map of items with counts as browse links.
 // If deletable is true and groupType is set, each row includes a hover-revealed delete button.
 func RenderBrowseHTML(items map[string]int, urlPrefix string, deletable bool, groupType string) string {
@@ -351,19 +399,26 @@ func RenderBrowseHTML(items map[string]int, urlPrefix string, deletable bool, gr
 
 	deleteLabel := translation.SprintfForRequest(configmanager.GetLanguage(), "delete all files")
 	confirmMsg := translation.SprintfForRequest(configmanager.GetLanguage(), "delete all files in this") + " " + groupType + "?"
+	mocLabel := translation.SprintfForRequest(configmanager.GetLanguage(), "generate moc")
 
 	for item, count := range items {
 		if deletable {
+			mocButton := ""
+			if groupType == "collection" {
+				mocButton = fmt.Sprintf(`<button class="browse-moc-btn" hx-post="/api/metadata/collection/moc" hx-vals='%s' hx-swap="none" title="%s"><i class="fa fa-sitemap"></i></button>`,
+					hxVals(map[string]string{"collection": item}), mocLabel)
+			}
 			html.WriteString(fmt.Sprintf(`
 				<li class="browse-item-row">
 					<a href="%s/%s">%s (%d)</a>
+					%s
 					<button class="btn-danger-icon browse-delete-btn"
 					        hx-delete="/api/files/bulk?type=%s&value=%s"
 					        hx-confirm="%s"
 					        hx-swap="none"
 					        title="%s"><i class="fa fa-trash"></i></button>
 				</li>`,
-				urlPrefix, url.QueryEscape(item), item, count,
+				urlPrefix, url.QueryEscape(item), item, count, mocButton,
 				groupType, url.QueryEscape(item),
 				confirmMsg, deleteLabel))
 		} else {
@@ -2,13 +2,18 @@
 package server
 
 import (
+	"crypto/subtle"
 	"embed"
 	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"knov/internal/configmanager"
 	"knov/internal/dashboard"
@@ -16,6 +21,7 @@ import (
 	"knov/internal/git"
 	"knov/internal/logging"
 	"knov/internal/pathutils"
+	"knov/internal/server/metrics"
 	"knov/internal/server/render"
 	_ "knov/internal/server/swagger" // swaggo api docs
 	"knov/internal/thememanager"
@@ -29,6 +35,10 @@ import (
 var staticFiles embed.FS
 var docsFiles embed.FS
 
+// staticModTime is the Last-Modified of embedded static files; embed.FS carries no
+// modification times and the files only change with a new binary.
+var staticModTime = time.Now()
+
 func SetDocsFiles(files embed.FS) {
 	docsFiles = files
 	render.SetDocsFiles(files)
@@ -67,6 +77,9 @@ func NewRouter() *chi.Mux {
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(normalizeRoutePath)
+	r.Use(measureResponseSize)
+	r.Use(rejectUnsafePaths)
 
 	// ----------------------------------------------------------------------------------------
 	// ------------------------------------ template routes ------------------------------------
@@ -89,6 +102,7 @@ func NewRouter() *chi.Mux {
 
 	r.Get("/files", handleRedirectToBrowseFiles)
 	r.Get("/files/*", handleFileContent)
+	r.Head("/files/*", handleFileContent)
 	r.Get("/files/edit/*", handleFileEdit)
 	r.Get("/files/edittable/*", handleFileEditTable)
 	r.Get("/files/history/*", handleHistory)
@@ -133,20 +147,32 @@ func NewRouter() *chi.Mux {
 
 	r.Get("/swagger/*", httpSwagger.Handler())
 	r.Route("/api", func(r chi.Router) {
+		r.Use(requireAPIToken)
 		r.Get("/health", handleAPIHealth)
-		r.Get("/search", handleAPISearch)
+		r.With(rateLimit(rateLimitSearch)).Get("/search", handleAPISearch)
+		r.Get("/events", handleAPIEvents)
 
 		// ----------------------------------------------------------------------------------------
 		// ----------------------------------------- FILTER ----------------------------------------
 		// ----------------------------------------------------------------------------------------
 
 		r.Route("/filters", func(r chi.Router) {
-			r.Post("/", handleAPIFilterFiles)
+			r.With(rateLimit(rateLimitSearch)).Post("/", handleAPIFilterFiles)
+			r.Get("/fields", handleAPIGetFilterFields)
 			r.Get("/value-input", handleAPIGetFilterValueInput)
 			r.Get("/criteria-row", handleAPIGetFilterCriteriaRow)
 			r.Post("/add-criteria", handleAPIAddFilterCriteria)
 			r.Post("/save", handleAPIFilterSave)
+			r.Post("/validate", handleAPIValidateFilter)
 			r.Delete("/delete/*", handleAPIFilterDelete)
+			r.Get("/presets", handleAPIListFilterPresets)
+			r.Post("/presets", handleAPISaveFilterPreset)
+			r.Get("/presets/{name}", handleAPIGetFilterPreset)
+			r.Delete("/presets/{name}", handleAPIDeleteFilterPreset)
+			r.Get("/queries", handleAPIListSavedQueries)
+			r.Post("/queries", handleAPISaveQuery)
+			r.Get("/queries/{name}", handleAPIGetSavedQuery)
+			r.Delete("/queries/{name}", handleAPIDeleteSavedQuery)
 		})
 
 		// ----------------------------------------------------------------------------------------
@@ -174,6 +200,8 @@ func NewRouter() *chi.Mux {
 			r.Post("/restart", handleAPIRestartApp)
 			r.Delete("/cache", handleAPIInvalidateCache)
 			r.Get("/jobs", handleAPIGetJobs)
+			r.Get("/responsesizes", handleAPIGetResponseSizes)
+			r.Post("/pruneempty", handleAPIPruneEmptyDirs)
 		})
 
 		// ----------------------------------------------------------------------------------------
@@ -190,7 +218,9 @@ func NewRouter() *chi.Mux {
 		// --------------------------------------- CRONJOB ----------------------------------------
 		// ----------------------------------------------------------------------------------------
 
-		r.Post("/cronjob", handleAPIRunCronjob)
+		r.With(rateLimit(rateLimitRebuild)).Post("/cronjob", handleAPIRunCronjob)
+		r.With(rateLimit(rateLimitRebuild)).Post("/jobs", handleAPIEnqueueJob)
+		r.Get("/jobs/{id}", handleAPIGetQueuedJob)
 
 		// ----------------------------------------------------------------------------------------
 		// ---------------------------------------- SETTINGS ----------------------------------------
@@ -228,11 +258,15 @@ func NewRouter() *chi.Mux {
 			r.Get("/languages", handleAPIGetLanguages)
 			r.Get("/repository", handleAPIGetGitRepositoryURL)
 			r.Get("/export", handleAPIExportSettings)
+			r.Get("/metadata-defaults", handleAPIGetMetadataDefaults)
+			r.Get("/filetypes", handleAPIGetFiletypes)
 
 			// POST
 			r.Post("/import", handleAPIImportSettings)
 			r.Post("/repository", handleAPISetGitRepositoryURL)
 			r.Post("/datapath", handleAPISetDataPath)
+			r.Post("/metadata-defaults", handleAPISetMetadataDefaults)
+			r.Post("/filetypes", handleAPIRegisterFiletype)
 
 			r.Post("/favicon", handleAPIUploadFavicon)
 			r.Delete("/favicon", handleAPIDeleteFavicon)
@@ -246,8 +280,12 @@ func NewRouter() *chi.Mux {
 			r.Get("/tree", handleAPIGetFileTree)
 			r.Get("/overview", handleAPIGetFileOverview)
 			r.Get("/content/*", handleAPIGetFileContent)
-			r.Post("/filter", handleAPIFilterFiles)
+			r.With(rateLimit(rateLimitSearch)).Post("/filter", handleAPIFilterFiles)
+			r.Get("/filter/preset", handleAPIRunFilterPreset)
+			r.With(rateLimit(rateLimitSearch)).Get("/query", handleAPIQueryFiles)
 			r.Get("/header", handleAPIGetFileHeader)
+			r.Get("/editorlink", handleAPIGetEditorLink)
+			r.Get("/handler", handleAPIGetFileHandler)
 			r.Get("/raw", handleAPIGetRawContent)
 			r.Post("/save", handleAPIFileSave)
 			r.Post("/save/", handleAPIFileSave)
@@ -261,6 +299,7 @@ func NewRouter() *chi.Mux {
 			r.Get("/folder-suggestions", handleAPIGetFolderSuggestions)
 			r.Get("/autocomplete", handleAPIFilesAutocomplete)
 			r.Get("/headers", handleAPIFilesHeaders)
+			r.Get("/journal/today", handleAPIJournalToday)
 			r.Get("/export/markdown", handleAPIExportToMarkdown)
 			r.Get("/export/pdf", handleAPIExportToPDF)
 			r.Post("/export/zip", handleAPIExportAllFiles)
@@ -274,6 +313,7 @@ func NewRouter() *chi.Mux {
 			// file operations
 			r.Post("/rename/*", handleAPIRenameFile)
 			r.Post("/move-folder/*", handleAPIMoveFolderFile)
+			r.Post("/movefolder", handleAPIMoveFolder)
 			r.Delete("/delete/*", handleAPIDeleteFile)
 			r.Delete("/delete-folder/*", handleAPIDeleteFolder)
 			r.Delete("/bulk", handleAPIDeleteFilesBulk)
@@ -300,35 +340,64 @@ func NewRouter() *chi.Mux {
 		r.Route("/metadata", func(r chi.Router) {
 			r.Get("/", handleAPIGetMetadata)
 			r.Post("/", handleAPISetMetadata)
-			r.Post("/rebuild", handleAPIRebuildMetadata)
+			r.Delete("/", handleAPIDeleteMetadataPrefix)
+			r.Post("/preview", handleAPIPreviewMetadata)
+			r.With(rateLimit(rateLimitRebuild)).Post("/rebuild", handleAPIRebuildMetadata)
 			r.Post("/rebuild/*", handleAPIRebuildFileMetadata)
+			r.Post("/recompute", handleAPIRecomputeFileMetadata)
+			r.Post("/refreshtimes", handleAPIRefreshMetadataTimes)
 			r.Post("/export", handleAPIExportMetadata)
+			r.Get("/export.db", handleAPIExportMetadataSQLite)
+			r.Get("/agenda.ics", handleAPIAgendaICS)
+			r.Post("/import", handleAPIImportMetadata)
+			r.Post("/import/obsidian", handleAPIImportObsidianMetadata)
 			r.Post("/bulk-update", handleAPIBulkUpdateMetadata)
+			r.Post("/bulk", handleAPIBulkSetMetadata)
 			r.Get("/broken-links", handleAPIScanBrokenLinks)
 			r.Post("/broken-links/repair", handleAPIRepairBrokenLinks)
+			r.Get("/validate", handleAPIValidateMetadata)
+			r.Post("/repair", handleAPIRepairMetadata)
 
 			r.Get("/collection", handleAPIGetMetadataCollection)
 			r.Get("/editor", handleAPIGetMetadataEditor)
 			r.Get("/path", handleAPIGetMetadataPath)
 			r.Get("/createdat", handleAPIGetMetadataCreatedAt)
 			r.Get("/lastedited", handleAPIGetMetadataLastEdited)
+			r.Get("/wordcount", handleAPIGetMetadataWordCount)
+			r.Get("/recentlycreated", handleAPIGetRecentlyCreated)
 			r.Get("/references", handleAPIGetMetadataReferences)
 			r.Post("/references", handleAPIAddMetadataReference)
 			r.Delete("/references", handleAPIDeleteMetadataReference)
+			r.Get("/custom", handleAPIGetMetadataCustom)
+			r.Post("/custom", handleAPISetMetadataCustom)
+			r.Get("/label", handleAPIGetMetadataLabel)
+			r.Post("/label", handleAPISetMetadataLabel)
+			r.Get("/labels", handleAPIGetLabelColors)
+			r.Get("/history", handleAPIGetMetadataHistory)
+			r.Post("/board/move", handleAPIMoveBoardCard)
 
 			r.Post("/collection", handleAPISetMetadataCollection)
+			r.Post("/collection/moc", handleAPIGenerateCollectionMOC)
 			r.Post("/editor", handleAPISetMetadataEditor)
 			r.Post("/path", handleAPISetMetadataPath)
 			r.Post("/createdat", handleAPISetMetadataCreatedAt)
 			r.Post("/lastedited", handleAPISetMetadataLastEdited)
+			r.Post("/wordcount", handleAPIRecountMetadataWordCount)
 			r.Post("/tags", handleAPISetMetadataTags)
+			r.Post("/tags/rename", handleAPIRenameTag)
+			r.Post("/tag/byfilter", handleAPITagByFilter)
+			r.Post("/targetdate/byfilter", handleAPITargetDateByFilter)
 			r.Post("/parents", handleAPISetMetadataParents)
 
 			r.Get("/tags", handleAPIGetAllTags)
 			r.Get("/collections", handleAPIGetAllCollections)
 			r.Get("/folders", handleAPIGetAllFolders)
+			r.Get("/suggest", handleAPISuggestMetadata)
+			r.Post("/suggest", handleAPISuggestMetadataFromContent)
+			r.Get("/bytags", handleAPIGetFilesByTags)
 			r.Get("/titles", handleAPIGetAllTitles)
 			r.Get("/editors", handleAPIGetAllEditors)
+			r.Get("/filetype/icons", handleAPIGetFiletypeIcons)
 			r.Get("/tags/{fileId}", handleAPIGetFileMetadataTags)
 			r.Get("/folders/{fileId}", handleAPIGetFileMetadataFolders)
 			r.Get("/collection/{fileId}", handleAPIGetFileMetadataCollection)
@@ -344,12 +413,14 @@ func NewRouter() *chi.Mux {
 			r.Get("/parents", handleAPIGetParents)
 			r.Get("/ancestors", handleAPIGetAncestors)
 			r.Get("/ancestors-in-folder", handleAPIGetAncestorsInFolder)
+			r.Get("/hierarchy/problems", handleAPIGetHierarchyProblems)
 			r.Get("/kids", handleAPIGetKids)
 			r.Get("/grandchildren", handleAPIGetGrandchildren)
 			r.Get("/used", handleAPIGetUsedLinks)
 			r.Get("/linkstohere", handleAPIGetLinksToHere)
 			r.Get("/media", handleAPIGetMediaLinks)
 			r.Get("/related", handleAPIGetRelatedFiles)
+			r.Get("/graph", handleAPIGetLinkGraph)
 			r.Get("/conflicts/diff", handleAPIGetConflictDiff)
 			r.Get("/conflicts/banner", handleAPIGetConflictBanner)
 			r.Get("/conflicts/of-banner", handleAPIGetConflictOfBanner)
@@ -376,6 +447,7 @@ func NewRouter() *chi.Mux {
 
 		r.Route("/git", func(r chi.Router) {
 			r.Get("/latestchanges", handleAPIGetRecentlyChanged)
+			r.Get("/history", handleAPIGetGitFileHistory)
 			r.Post("/push", handleAPIGitPush)
 			r.Post("/pull", handleAPIGitPull)
 			r.Post("/test-auth", handleAPIGitTestAuth)
@@ -396,6 +468,8 @@ func NewRouter() *chi.Mux {
 			r.Patch("/{id}", handleAPIUpdateDashboard)
 			r.Delete("/{id}", handleAPIDeleteDashboard)
 			r.Get("/{id}/export", handleAPIExportDashboard)
+			r.Post("/{id}/duplicate", handleAPIDuplicateDashboard)
+			r.Get("/{id}/widgets", handleAPIRenderDashboardWidgets)
 			r.Post("/{id}/rename", handleAPIRenameDashboard)
 			r.Post("/widget/{id}", handleAPIRenderWidget)
 		})
@@ -415,6 +489,11 @@ func NewRouter() *chi.Mux {
 			r.Post("/chattest", handleAPIChatTest)
 			r.Post("/dashboardtest", handleAPIDashboardTest)
 			r.Post("/kanbantest", handleAPIKanbanTest)
+			r.Post("/metadatatest", handleAPIMetadataTest)
+			r.Post("/storagetest", handleAPIStorageTest)
+			r.Post("/browsetest", handleAPIBrowseTest)
+			r.Post("/admintest", handleAPIAdminTest)
+			r.Post("/settingstest", handleAPISettingsTest)
 			r.Post("/run-all", handleAPIRunAllTests)
 		})
 
@@ -551,6 +630,9 @@ func handleStatic(w http.ResponseWriter, r *http.Request) {
 		}
 
 		logging.LogDebug(logging.KeyApp, "serving theme file: %s", fullPath)
+		if info, err := os.Stat(fullPath); err == nil && checkNotModified(w, r, info.ModTime()) {
+			return
+		}
 		http.ServeFile(w, r, fullPath)
 	} else {
 		data, err := staticFiles.ReadFile(fullPath)
@@ -559,6 +641,9 @@ func handleStatic(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
+		if checkNotModified(w, r, staticModTime) {
+			return
+		}
 		w.Write(data)
 	}
 }
@@ -582,23 +667,30 @@ func handleWebfontsRedirect(w http.ResponseWriter, r *http.Request) {
 // ----------------------------------------------------------------------------------------
 
 func handleHome(w http.ResponseWriter, r *http.Request) {
+	var dash *dashboard.Dashboard
 	if id := configmanager.GetHomeDashboard(); id != "" {
-		dash, err := dashboard.Get(id)
-		if err != nil {
+		var err error
+		if dash, err = dashboard.Get(id); err != nil {
 			logging.LogWarning(logging.KeyApp, "home dashboard %q not found, falling back to home page: %v", id, err)
-		} else {
-			tm := thememanager.GetThemeManager()
-			data := thememanager.NewDashboardTemplateData(dash)
-			if err := tm.Render(w, "dashboardview", data); err != nil {
-				http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
-			}
-			return
 		}
 	}
 
+	if wantsJSON(r) {
+		writeResponse(w, r, newHomePageData(dash), "")
+		return
+	}
+
 	tm := thememanager.GetThemeManager()
+	if dash != nil {
+		data := thememanager.NewDashboardTemplateData(dash)
+		if err := tm.Render(w, r, "dashboardview", data); err != nil {
+			http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
 	data := thememanager.NewBaseTemplateData("home")
-	if err := tm.Render(w, "home", data); err != nil {
+	if err := tm.Render(w, r, "home", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -607,7 +699,7 @@ func handleSettings(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewSettingsTemplateData()
 
-	err := tm.Render(w, "settings", data)
+	err := tm.Render(w, r, "settings", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -619,7 +711,7 @@ func handleAdmin(w http.ResponseWriter, r *http.Request) {
 	data := thememanager.NewSettingsTemplateData()
 	data.Title = "Admin"
 
-	err := tm.Render(w, "admin", data)
+	err := tm.Render(w, r, "admin", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -630,7 +722,7 @@ func handleHelp(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("help")
 
-	err := tm.Render(w, "help", data)
+	err := tm.Render(w, r, "help", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -641,7 +733,7 @@ func handlePlayground(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("playground")
 
-	err := tm.Render(w, "playground", data)
+	err := tm.Render(w, r, "playground", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -689,13 +781,29 @@ func handleHistory(w http.ResponseWriter, r *http.Request) {
 			selectedCommit = selectedCommit[:7]
 		}
 
+		_, statErr := os.Stat(pathutils.ToFullPath(filePath))
+		deleted := os.IsNotExist(statErr)
+
+		if wantsJSON(r) {
+			if versions == nil {
+				versions = []git.FileVersion{}
+			}
+			writeResponse(w, r, fileHistoryPageData{
+				FilePath:       filePath,
+				CurrentCommit:  currentCommit,
+				SelectedCommit: selectedCommit,
+				Deleted:        deleted,
+				Versions:       versions,
+			}, "")
+			return
+		}
+
 		data := thememanager.NewHistoryTemplateData(filePath, currentCommit, selectedCommit, versions, false)
 		data.CompareFrom = r.URL.Query().Get("from")
 		data.CompareTo = r.URL.Query().Get("to")
-		_, statErr := os.Stat(pathutils.ToFullPath(filePath))
-		data.FileDeleted = os.IsNotExist(statErr)
+		data.FileDeleted = deleted
 
-		err = tm.Render(w, "history", data)
+		err = tm.Render(w, r, "history", data)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 			return
@@ -703,11 +811,22 @@ func handleHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsJSON(r) {
+		pageData, err := newHistoryPageData(r)
+		if err != nil {
+			logging.LogError(logging.KeyApp, "failed to get recent changes: %v", err)
+			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get recent files"), http.StatusInternalServerError)
+			return
+		}
+		writeResponse(w, r, pageData, "")
+		return
+	}
+
 	data := thememanager.NewHistoryTemplateData("", "", "", nil, false)
 	data.Collection = r.URL.Query().Get("collection")
 	data.Folder = r.URL.Query().Get("folder")
 
-	err := tm.Render(w, "history", data)
+	err := tm.Render(w, r, "history", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -715,10 +834,21 @@ func handleHistory(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleFileOverview(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		pageData, err := newOverviewPageData()
+		if err != nil {
+			logging.LogError(logging.KeyApp, "failed to get files: %v", err)
+			http.Error(w, translation.SprintfForRequest(configmanager.GetLanguage(), "failed to get files"), http.StatusInternalServerError)
+			return
+		}
+		writeResponse(w, r, pageData, "")
+		return
+	}
+
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("Files Overview")
 
-	err := tm.Render(w, "filesoverview", data)
+	err := tm.Render(w, r, "filesoverview", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -731,7 +861,7 @@ func handleSearchPage(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewSearchPageData(query)
 
-	err := tm.Render(w, "search", data)
+	err := tm.Render(w, r, "search", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -752,7 +882,7 @@ func handleBrowseFiles(w http.ResponseWriter, r *http.Request) {
 	data := thememanager.NewBrowseFilesTemplateData(metadataType, value)
 	data.Title = title
 
-	err := tm.Render(w, "browsefiles", data)
+	err := tm.Render(w, r, "browsefiles", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -763,7 +893,7 @@ func handleBrowse(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("Browse")
 
-	err := tm.Render(w, "browse", data)
+	err := tm.Render(w, r, "browse", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -774,7 +904,7 @@ func handleBrowseMedia(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewMediaOverviewTemplateData()
 
-	err := tm.Render(w, "mediaoverview", data)
+	err := tm.Render(w, r, "mediaoverview", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -813,7 +943,7 @@ func handleMedia(w http.ResponseWriter, r *http.Request) {
 		tm := thememanager.GetThemeManager()
 		data := thememanager.NewMediaViewTemplateData(mediaPath)
 
-		err := tm.Render(w, "mediaview", data)
+		err := tm.Render(w, r, "mediaview", data)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 			return
@@ -843,7 +973,7 @@ func handleBrowseMetadata(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBrowseMetadataTemplateData(metadataType)
 
-	err := tm.Render(w, "browsemetadata", data)
+	err := tm.Render(w, r, "browsemetadata", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -854,7 +984,7 @@ func handleDashboardNew(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("Create New Dashboard")
 
-	err := tm.Render(w, "dashboardnew", data)
+	err := tm.Render(w, r, "dashboardnew", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -872,7 +1002,7 @@ func handleDashboardEdit(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewDashboardEditTemplateData(dash)
 
-	err = tm.Render(w, "dashboardedit", data)
+	err = tm.Render(w, r, "dashboardedit", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -894,18 +1024,226 @@ func handleDashboardView(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewDashboardTemplateData(dash)
 
-	err = tm.Render(w, "dashboardview", data)
+	err = tm.Render(w, r, "dashboardview", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
 	}
 }
 
+// setFileContentHeaders sets the ETag that GET and HEAD on /files/* share, derived from the
+// file's size and modification time.
+func setFileContentHeaders(w http.ResponseWriter, info os.FileInfo) {
+	w.Header().Set("ETag", fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+}
+
+// normalizeRoutePath rewrites the request path before routing: a trailing slash is stripped
+// when stripTrailingSlashes is set and the first path segment is lowercased when
+// caseInsensitiveRoutes is set. The rest of the path is left alone since it usually is a
+// file path. r.URL.Path itself is rewritten (unlike middleware.StripSlashes, which only
+// changes the chi route path) because handlers such as handleFileContent read it directly.
+func normalizeRoutePath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Path
+		if configmanager.GetStripTrailingSlashes() && len(p) > 1 {
+			p = "/" + strings.TrimRight(p[1:], "/")
+		}
+		if configmanager.GetCaseInsensitiveRoutes() {
+			first, rest, found := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+			p = "/" + strings.ToLower(first)
+			if found {
+				p += "/" + rest
+			}
+		}
+		if p != r.URL.Path {
+			r.URL.Path = p
+			r.URL.RawPath = ""
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiTokenExempt are the api paths requireAPIToken lets through: the health check for
+// monitoring and the agenda feed, which checks its own token.
+var apiTokenExempt = []string{"/api/health", "/api/metadata/agenda.ics"}
+
+// requireAPIToken answers 401 to api requests without "Authorization: Bearer <token>" when
+// KNOV_API_TOKEN is set. GET and HEAD requests only need it with KNOV_API_TOKEN_PROTECT_READS.
+// The token is compared in constant time.
+func requireAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := configmanager.GetAPIToken()
+		isRead := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if token == "" || slices.Contains(apiTokenExempt, r.URL.Path) || (isRead && !configmanager.GetAPITokenProtectReads()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+			logging.LogWarning(logging.KeyApp, "rejected %s %s: missing or invalid api token", r.Method, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="knov"`)
+			writeAPIError(w, http.StatusUnauthorized, translation.SprintfForRequest(configmanager.GetLanguage(), "missing or invalid api token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pathParams are the query and form fields handlers read file and folder paths from.
+var pathParams = []string{"filepath", "newpath", "file", "path", "folder", "prefillpath"}
+
+// rejectUnsafePaths answers 400 before routing when the request path has a ".." segment, or when
+// a path parameter or the file path of a /files/ or /media/ URL fails pathutils.SafeVaultPath.
+// Urlencoded form bodies are parsed here (handlers can still call r.ParseForm); multipart
+// bodies are left to their handlers.
+func rejectUnsafePaths(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(strings.Split(r.URL.Path, "/"), "..") {
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid path"))
+			return
+		}
+
+		values := url.Values{}
+		for _, prefix := range []string{"/files/", "/media/"} {
+			if tail, ok := strings.CutPrefix(r.URL.Path, prefix); ok {
+				values.Add("path", strings.TrimPrefix(prefix, "/")+tail)
+			}
+		}
+		for key, vals := range r.URL.Query() {
+			values[key] = append(values[key], vals...)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+			if err := r.ParseForm(); err == nil {
+				for key, vals := range r.PostForm {
+					values[key] = append(values[key], vals...)
+				}
+			}
+		}
+		for _, key := range pathParams {
+			if !checkSafePaths(w, r, values[key]...) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkSafePaths checks every non-empty path with pathutils.SafeVaultPath, writing a 400 and
+// returning false for the first unsafe one. Handlers call it on the paths rejectUnsafePaths
+// doesn't see: json and multipart bodies and fields outside pathParams.
+func checkSafePaths(w http.ResponseWriter, r *http.Request, paths ...string) bool {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := pathutils.SafeVaultPath(path); err != nil {
+			logging.LogWarning(logging.KeyApp, "rejected %s %s: %v", r.Method, r.URL.Path, err)
+			writeAPIError(w, http.StatusBadRequest, translation.SprintfForRequest(configmanager.GetLanguage(), "invalid path"))
+			return false
+		}
+	}
+	return true
+}
+
+// measureResponseSize records the response body size per route pattern, see metrics.RecordResponse.
+func measureResponseSize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		var pattern string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			pattern = rctx.RoutePattern()
+		}
+		metrics.RecordResponse(r.Method, r.URL.Path, pattern, ww.BytesWritten())
+	})
+}
+
+// fileLastModified returns when a docs file last changed: the later of its metadata
+// lastEdited and its modification time, so edits made outside knov are not missed.
+func fileLastModified(filePath string, info os.FileInfo) time.Time {
+	modTime := info.ModTime()
+	if meta, err := files.MetaDataGet(pathutils.ToWithPrefix(filePath)); err == nil && meta != nil && meta.LastEdited.After(modTime) {
+		modTime = meta.LastEdited
+	}
+	return modTime
+}
+
+// checkNotModified sets the Last-Modified header to modTime and answers with 304 Not Modified
+// when the request's If-None-Match matches the ETag already set on w or, only without
+// If-None-Match, when its If-Modified-Since is not older. Reports whether the 304 was
+// written. Last-Modified and If-Modified-Since are skipped when Last-Modified headers are
+// disabled.
+func checkNotModified(w http.ResponseWriter, r *http.Request, modTime time.Time) bool {
+	lastModified := configmanager.GetLastModifiedHeaders() && !modTime.IsZero()
+	if lastModified {
+		modTime = modTime.UTC().Truncate(time.Second)
+		w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if !etagMatches(ifNoneMatch, w.Header().Get("ETag")) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if !lastModified || err != nil || modTime.After(since) {
+			return false
+		}
+	}
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header value lists etag, compared weakly as
+// If-None-Match requires. "*" matches any etag; an empty etag matches nothing.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func handleFileContent(w http.ResponseWriter, r *http.Request) {
 	filePath := strings.TrimPrefix(r.URL.Path, "/files/")
 	fullPath := pathutils.ToDocsPath(filePath)
 	ext := strings.ToLower(filepath.Ext(fullPath))
 
+	info, statErr := os.Stat(fullPath)
+	if r.Method == http.MethodHead {
+		if statErr != nil || info.IsDir() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		contentType := "text/html; charset=utf-8"
+		if ext == ".pdf" {
+			contentType = "application/pdf"
+		}
+		w.Header().Set("Content-Type", contentType)
+		setFileContentHeaders(w, info)
+		if checkNotModified(w, r, fileLastModified(filePath, info)) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if statErr == nil && !info.IsDir() {
+		setFileContentHeaders(w, info)
+		if checkNotModified(w, r, fileLastModified(filePath, info)) {
+			return
+		}
+	}
+
 	if ext == ".pdf" {
 		w.Header().Set("Content-Type", "application/pdf")
 		http.ServeFile(w, r, fullPath)
@@ -927,7 +1265,7 @@ func handleFileContent(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileViewTemplateData(filepath.Base(filePath), filePath, fileContent)
 
-	err = tm.Render(w, "fileview", data)
+	err = tm.Render(w, r, "fileview", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -941,7 +1279,7 @@ func handleFileEdit(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileEditTemplateData(filePath, sectionID)
 
-	err := tm.Render(w, "fileedit", data)
+	err := tm.Render(w, r, "fileedit", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -956,7 +1294,7 @@ func handleFileNewToastUI(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("toastui-editor")
 	data.PrefillPath = r.URL.Query().Get("prefillpath")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -964,7 +1302,7 @@ func handleFileNewToastUI(w http.ResponseWriter, r *http.Request) {
 func handleFileNewText(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("textarea-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -972,7 +1310,7 @@ func handleFileNewText(w http.ResponseWriter, r *http.Request) {
 func handleFileNewList(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("list-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -980,7 +1318,7 @@ func handleFileNewList(w http.ResponseWriter, r *http.Request) {
 func handleFileNewTodo(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("todo-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -988,7 +1326,7 @@ func handleFileNewTodo(w http.ResponseWriter, r *http.Request) {
 func handleFileNewFilter(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("filter-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -996,7 +1334,7 @@ func handleFileNewFilter(w http.ResponseWriter, r *http.Request) {
 func handleFileNewIndex(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("index-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -1004,7 +1342,7 @@ func handleFileNewIndex(w http.ResponseWriter, r *http.Request) {
 func handleFileNewCodeMirror(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileNewTemplateData("codemirror-editor")
-	if err := tm.Render(w, "filenew", data); err != nil {
+	if err := tm.Render(w, r, "filenew", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -1022,7 +1360,7 @@ func handleFileEditTable(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewFileEditTableTemplateData(filePath, tableIndex)
 
-	err := tm.Render(w, "filedittable", data)
+	err := tm.Render(w, r, "filedittable", data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 		return
@@ -1032,7 +1370,7 @@ func handleFileEditTable(w http.ResponseWriter, r *http.Request) {
 func handleChat(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewBaseTemplateData("chat")
-	if err := tm.Render(w, "chat", data); err != nil {
+	if err := tm.Render(w, r, "chat", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -1040,7 +1378,7 @@ func handleChat(w http.ResponseWriter, r *http.Request) {
 func handleKanbanSelect(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	data := thememanager.NewKanbanSelectTemplateData(configmanager.GetKanbanBoards())
-	if err := tm.Render(w, "kanban", data); err != nil {
+	if err := tm.Render(w, r, "kanban", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
@@ -1054,7 +1392,7 @@ func handleKanbanBoard(w http.ResponseWriter, r *http.Request) {
 	tm := thememanager.GetThemeManager()
 	filterPanel := render.RenderKanbanFilterPanel(board.Slug)
 	data := thememanager.NewKanbanTemplateData(board, nil, filterPanel)
-	if err := tm.Render(w, "kanban", data); err != nil {
+	if err := tm.Render(w, r, "kanban", data); err != nil {
 		http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 	}
 }
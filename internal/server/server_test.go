@@ -0,0 +1,573 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/dashboard"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/job"
+	"knov/internal/pathutils"
+	"knov/internal/server/render"
+	"knov/internal/testkit"
+)
+
+func TestFileContentHead(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	fullPath := pathutils.ToDocsPath("head/note.md")
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(fullPath, []byte("# head\n\nsome content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	head, err := http.Head(ts.URL + "/files/head/note.md")
+	if err != nil {
+		t.Fatalf("HEAD /files/head/note.md: %v", err)
+	}
+	body, _ := io.ReadAll(head.Body)
+	head.Body.Close()
+
+	if head.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", head.StatusCode)
+	}
+	if len(body) != 0 {
+		t.Fatalf("expected empty body, got %d bytes", len(body))
+	}
+	if ct := head.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected content-type %q", ct)
+	}
+	etag := head.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+	lastModified, err := time.Parse(http.TimeFormat, head.Header.Get("Last-Modified"))
+	if err != nil {
+		t.Fatalf("invalid Last-Modified header: %v", err)
+	}
+	info, _ := os.Stat(fullPath)
+	if !lastModified.Equal(info.ModTime().UTC().Truncate(time.Second)) {
+		t.Fatalf("Last-Modified %v does not match mtime %v", lastModified, info.ModTime())
+	}
+
+	get, err := http.Get(ts.URL + "/files/head/note.md")
+	if err != nil {
+		t.Fatalf("GET /files/head/note.md: %v", err)
+	}
+	get.Body.Close()
+	if get.Header.Get("ETag") != etag || get.Header.Get("Last-Modified") != head.Header.Get("Last-Modified") {
+		t.Fatalf("GET and HEAD headers differ: %v vs %v", get.Header, head.Header)
+	}
+}
+
+func TestFileContentHeadMissing(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	resp, err := http.Head(ts.URL + "/files/does/not/exist.md")
+	if err != nil {
+		t.Fatalf("HEAD missing file: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestFileContentIfModifiedSince(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	save := func(content string) {
+		t.Helper()
+		resp, err := http.PostForm(ts.URL+"/api/files/save", url.Values{"filepath": {"ims/note.md"}, "content": {content}})
+		if err != nil {
+			t.Fatalf("POST /api/files/save: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("save: expected 200, got %d", resp.StatusCode)
+		}
+	}
+	getSince := func(since string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files/ims/note.md", nil)
+		if since != "" {
+			req.Header.Set("If-Modified-Since", since)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /files/ims/note.md: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	save("# first\n")
+	first := getSince("")
+	lastModified := first.Header.Get("Last-Modified")
+	if first.StatusCode != http.StatusOK || lastModified == "" {
+		t.Fatalf("expected 200 with Last-Modified, got %d %q", first.StatusCode, lastModified)
+	}
+
+	if resp := getSince(lastModified); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching If-Modified-Since, got %d", resp.StatusCode)
+	}
+
+	// Last-Modified has second precision
+	time.Sleep(1100 * time.Millisecond)
+	save("# second\n")
+
+	resp := getSince(lastModified)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after edit, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Last-Modified") == lastModified {
+		t.Fatal("expected Last-Modified to change after edit")
+	}
+}
+
+// TestFileContentIfNoneMatch checks that If-None-Match is evaluated against the ETag and,
+// when sent, decides alone: If-Modified-Since is ignored then.
+func TestFileContentIfNoneMatch(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	resp, err := http.PostForm(ts.URL+"/api/files/save", url.Values{"filepath": {"inm/note.md"}, "content": {"# note\n"}})
+	if err != nil {
+		t.Fatalf("POST /api/files/save: %v", err)
+	}
+	resp.Body.Close()
+
+	request := func(method, ifNoneMatch, ifModifiedSince string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+"/files/inm/note.md", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s /files/inm/note.md: %v", method, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	first := request(http.MethodGet, "", "")
+	etag, lastModified := first.Header.Get("ETag"), first.Header.Get("Last-Modified")
+	if first.StatusCode != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("expected 200 with ETag and Last-Modified, got %d %q %q", first.StatusCode, etag, lastModified)
+	}
+	past := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name, method, ifNoneMatch, ifModifiedSince string
+		want                                       int
+	}{
+		{"matching etag", http.MethodGet, etag, "", http.StatusNotModified},
+		{"matching etag in a list", http.MethodGet, `"other", ` + etag, "", http.StatusNotModified},
+		{"wildcard", http.MethodGet, "*", "", http.StatusNotModified},
+		{"matching etag on head", http.MethodHead, etag, "", http.StatusNotModified},
+		{"matching etag, older If-Modified-Since", http.MethodGet, etag, past, http.StatusNotModified},
+		{"other etag, current If-Modified-Since", http.MethodGet, `"other"`, lastModified, http.StatusOK},
+	} {
+		if resp := request(tc.method, tc.ifNoneMatch, tc.ifModifiedSince); resp.StatusCode != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestRouteNormalization(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	prevStrip, prevCase := configmanager.StripTrailingSlashes.Get(), configmanager.CaseInsensitiveRoutes.Get()
+	t.Cleanup(func() {
+		configmanager.StripTrailingSlashes.SetFromString(strconv.FormatBool(prevStrip)) //nolint:errcheck
+		configmanager.CaseInsensitiveRoutes.SetFromString(strconv.FormatBool(prevCase)) //nolint:errcheck
+	})
+
+	for _, tc := range []struct {
+		strip, caseInsensitive bool
+		path                   string
+		want                   int
+	}{
+		{true, false, "/home/", http.StatusOK},
+		{false, false, "/home/", http.StatusNotFound},
+		{true, false, "/HOME", http.StatusNotFound},
+		{true, true, "/HOME", http.StatusOK},
+		{true, true, "/Home/", http.StatusOK},
+	} {
+		configmanager.StripTrailingSlashes.SetFromString(strconv.FormatBool(tc.strip))            //nolint:errcheck
+		configmanager.CaseInsensitiveRoutes.SetFromString(strconv.FormatBool(tc.caseInsensitive)) //nolint:errcheck
+		resp, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("GET %s (strip=%v, caseInsensitive=%v): expected %d, got %d", tc.path, tc.strip, tc.caseInsensitive, tc.want, resp.StatusCode)
+		}
+	}
+}
+
+// BenchmarkRenderWidgets compares rendering a dashboard of filter widgets one after another
+// with rendering them on a worker pool.
+func BenchmarkRenderWidgets(b *testing.B) {
+	testkit.NewApp(b)
+
+	for i := range 200 {
+		relPath := fmt.Sprintf("benchwidgets/note-%03d.md", i)
+		fullPath := pathutils.ToDocsPath(relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			b.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("# note\n"), 0644); err != nil {
+			b.Fatalf("write file: %v", err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(relPath), Tags: []string{fmt.Sprintf("tag-%d", i%8)}}); err != nil {
+			b.Fatalf("save metadata: %v", err)
+		}
+	}
+
+	var widgets []dashboard.Widget
+	for i := range 8 {
+		widgets = append(widgets, dashboard.Widget{
+			ID:   fmt.Sprintf("widget-%d", i),
+			Type: dashboard.WidgetTypeFilter,
+			Config: dashboard.WidgetConfig{Filter: &dashboard.FilterConfig{
+				Criteria: []filter.Criteria{{Metadata: "tags", Operator: "equals", Value: fmt.Sprintf("tag-%d", i), Action: "include"}},
+				Logic:    "and",
+				Display:  "list",
+			}},
+		})
+	}
+
+	for _, bc := range []struct {
+		name    string
+		workers int
+	}{{"serial", 1}, {"parallel", 8}} {
+		b.Run(bc.name, func(b *testing.B) {
+			for b.Loop() {
+				render.RenderWidgets(context.Background(), widgets, bc.workers)
+			}
+		})
+	}
+}
+
+func BenchmarkFilterPagination(b *testing.B) {
+	testkit.NewApp(b)
+
+	for i := range 10000 {
+		relPath := fmt.Sprintf("benchfilter/%02d/note-%05d.md", i%100, i)
+		fullPath := pathutils.ToDocsPath(relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			b.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("# note\n"), 0644); err != nil {
+			b.Fatalf("write file: %v", err)
+		}
+		if err := files.MetaDataSaveRaw(&files.Metadata{Path: pathutils.ToWithPrefix(relPath), Tags: []string{fmt.Sprintf("tag-%d", i%4)}}); err != nil {
+			b.Fatalf("save metadata: %v", err)
+		}
+	}
+	criteria := []filter.Criteria{{Metadata: "tags", Operator: "equals", Value: "tag-1", Action: "include"}}
+	if _, err := filter.FilterFilesWithConfig(&filter.Config{Criteria: criteria, Logic: "and"}); err != nil {
+		b.Fatalf("warm file list cache: %v", err)
+	}
+
+	for _, bc := range []struct {
+		name          string
+		offset, limit int
+	}{{"unlimited", 0, 0}, {"limit10", 0, 10}, {"offset100limit10", 100, 10}} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := filter.FilterFilesWithConfig(&filter.Config{Criteria: criteria, Logic: "and", Offset: bc.offset, Limit: bc.limit}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestFileEventStream(t *testing.T) {
+	ts := testkit.NewApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/events: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	if err := configmanager.EventStreamLimit.SetFromString("1"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { configmanager.EventStreamLimit.SetFromString("20") }) //nolint:errcheck
+	second, err := http.Get(ts.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("GET /api/events: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a second stream over the limit to be rejected, got %d", second.StatusCode)
+	}
+}
+
+// TestQueuedJobs covers the status codes of the job routes, the queue itself is checked by
+// the admin suite.
+func TestQueuedJobs(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	resp, err := http.PostForm(ts.URL+"/api/jobs", url.Values{"operation": {"rebuild"}})
+	if err != nil {
+		t.Fatalf("POST /api/jobs: %v", err)
+	}
+	var queued job.QueuedJob
+	err = json.NewDecoder(resp.Body).Decode(&queued)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode queued job: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted || queued.ID == "" {
+		t.Fatalf("expected an accepted job, got %d %+v", resp.StatusCode, queued)
+	}
+	if location := resp.Header.Get("Location"); location != "/api/jobs/"+queued.ID {
+		t.Errorf("expected Location /api/jobs/%s, got %q", queued.ID, location)
+	}
+
+	resp, err = http.PostForm(ts.URL+"/api/jobs", url.Values{"operation": {"nope"}})
+	if err != nil {
+		t.Fatalf("POST /api/jobs: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an unknown operation to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/jobs/unknown")
+	if err != nil {
+		t.Fatalf("GET /api/jobs/unknown: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected an unknown job to be 404, got %d", resp.StatusCode)
+	}
+}
+
+// TestAgendaICS covers the token of the agenda feed, the feed itself is checked by the
+// metadata suite.
+func TestAgendaICS(t *testing.T) {
+	t.Setenv("KNOV_AGENDA_TOKEN", "secret")
+	ts := testkit.NewApp(t)
+
+	resp, err := http.Get(ts.URL + "/api/metadata/agenda.ics")
+	if err != nil {
+		t.Fatalf("GET /api/metadata/agenda.ics: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the feed to require the token, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/metadata/agenda.ics?token=secret")
+	if err != nil {
+		t.Fatalf("GET /api/metadata/agenda.ics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/calendar") {
+		t.Fatalf("expected a calendar, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.HasPrefix(string(body), "BEGIN:VCALENDAR\r\n") {
+		t.Errorf("expected an iCalendar feed, got %q", body)
+	}
+}
+
+func TestAPIToken(t *testing.T) {
+	request := func(ts *httptest.Server, method, path, token string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		ts := testkit.NewApp(t)
+		if status := request(ts, http.MethodPost, "/api/jobs?operation=nope", ""); status != http.StatusBadRequest {
+			t.Errorf("expected the api to be open without a token configured, got %d", status)
+		}
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		t.Setenv("KNOV_API_TOKEN", "s3cret")
+		ts := testkit.NewApp(t)
+		if status := request(ts, http.MethodPost, "/api/jobs?operation=nope", ""); status != http.StatusUnauthorized {
+			t.Errorf("expected 401 without a token, got %d", status)
+		}
+		if status := request(ts, http.MethodPost, "/api/jobs?operation=nope", "wrong"); status != http.StatusUnauthorized {
+			t.Errorf("expected 401 with a wrong token, got %d", status)
+		}
+		if status := request(ts, http.MethodPost, "/api/jobs?operation=nope", "s3cret"); status != http.StatusBadRequest {
+			t.Errorf("expected the handler to run with the token, got %d", status)
+		}
+		if status := request(ts, http.MethodGet, "/api/jobs/unknown", ""); status != http.StatusNotFound {
+			t.Errorf("expected reads to stay open, got %d", status)
+		}
+	})
+
+	t.Run("reads", func(t *testing.T) {
+		t.Setenv("KNOV_API_TOKEN", "s3cret")
+		t.Setenv("KNOV_API_TOKEN_PROTECT_READS", "true")
+		ts := testkit.NewApp(t)
+		if status := request(ts, http.MethodGet, "/api/jobs/unknown", ""); status != http.StatusUnauthorized {
+			t.Errorf("expected 401 for a read without a token, got %d", status)
+		}
+		if status := request(ts, http.MethodGet, "/api/jobs/unknown", "s3cret"); status != http.StatusNotFound {
+			t.Errorf("expected the handler to run with the token, got %d", status)
+		}
+		if status := request(ts, http.MethodGet, "/api/health", ""); status != http.StatusOK {
+			t.Errorf("expected the health check to stay open, got %d", status)
+		}
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	ts := testkit.NewApp(t)
+	if err := configmanager.RateLimitSearch.SetFromString("2"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { configmanager.RateLimitSearch.SetFromString("600") }) //nolint:errcheck
+
+	search := func() *http.Response {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/api/search?q=note")
+		if err != nil {
+			t.Fatalf("GET /api/search: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	for i := range 2 {
+		if resp := search(); resp.StatusCode == http.StatusTooManyRequests {
+			t.Fatalf("expected search %d to be within the limit", i+1)
+		}
+	}
+	resp := search()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the third search to be rate limited, got %d", resp.StatusCode)
+	}
+	if retry, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || retry < 1 || retry > 60 {
+		t.Errorf("expected Retry-After in seconds, got %q", resp.Header.Get("Retry-After"))
+	}
+
+	// routes outside the group are not limited
+	jobResp, err := http.Get(ts.URL + "/api/jobs/unknown")
+	if err != nil {
+		t.Fatalf("GET /api/jobs/unknown: %v", err)
+	}
+	jobResp.Body.Close()
+	if jobResp.StatusCode == http.StatusTooManyRequests {
+		t.Error("expected routes outside the search group not to be limited")
+	}
+
+	if err := configmanager.RateLimitSearch.SetFromString("0"); err != nil {
+		t.Fatal(err)
+	}
+	if resp := search(); resp.StatusCode == http.StatusTooManyRequests {
+		t.Error("expected a limit of 0 to turn rate limiting off")
+	}
+}
+
+// TestFileListPagination covers the X-Total-Count and Link headers of paginated lists, the
+// pages themselves are checked by the browse suite.
+func TestFileListPagination(t *testing.T) {
+	ts := testkit.NewApp(t)
+
+	for _, name := range []string{"pagetest/a.md", "pagetest/b.md", "pagetest/c.md", "pagetest/d.md", "pagetest/e.md"} {
+		resp, err := http.PostForm(ts.URL+"/api/files/save", url.Values{"filepath": {name}, "content": {"# " + name + "\n"}})
+		if err != nil {
+			t.Fatalf("POST /api/files/save: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	list := func(query string) ([]files.File, http.Header) {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/api/files/list?" + query)
+		if err != nil {
+			t.Fatalf("GET /api/files/list: %v", err)
+		}
+		defer resp.Body.Close()
+		var fileList []files.File
+		if err := json.NewDecoder(resp.Body).Decode(&fileList); err != nil {
+			t.Fatalf("decode file list: %v", err)
+		}
+		return fileList, resp.Header
+	}
+
+	all, header := list("pageSize=1000")
+	total, _ := strconv.Atoi(header.Get("X-Total-Count"))
+	if total < 5 || total != len(all) {
+		t.Fatalf("expected X-Total-Count to match the %d listed files, got %q", len(all), header.Get("X-Total-Count"))
+	}
+
+	// without page and pageSize every file is listed, the rail filters the list client-side
+	t.Cleanup(func() { configmanager.FileListPageSize.SetFromString("100") }) //nolint:errcheck
+	if err := configmanager.FileListPageSize.SetFromString("2"); err != nil {
+		t.Fatalf("set fileListPageSize: %v", err)
+	}
+	if unpaged, header := list("actions=true"); len(unpaged) != total || header.Get("Link") != "" {
+		t.Errorf("expected all %d files without a Link header, got %d files and Link %q", total, len(unpaged), header.Get("Link"))
+	}
+
+	if _, header := list("page=2&pageSize=2"); header.Get("X-Total-Count") != strconv.Itoa(total) {
+		t.Errorf("expected X-Total-Count %d on a page, got %q", total, header.Get("X-Total-Count"))
+	} else if link := header.Get("Link"); !strings.Contains(link, "page=3&pageSize=2>; rel=\"next\"") || !strings.Contains(link, "page=1&pageSize=2>; rel=\"prev\"") {
+		t.Errorf("expected next and prev links, got %q", link)
+	}
+
+	if _, header := list(fmt.Sprintf("page=%d&pageSize=2", (total+1)/2)); strings.Contains(header.Get("Link"), "next") {
+		t.Errorf("expected no next link on the last page, got %q", header.Get("Link"))
+	}
+
+	form := url.Values{"metadata[]": {"folders"}, "operator[]": {"equals"}, "value[]": {"pagetest"}, "action[]": {"include"}}
+	resp, err := http.PostForm(ts.URL+"/api/filters?page=2&pageSize=2", form)
+	if err != nil {
+		t.Fatalf("POST /api/filters: %v", err)
+	}
+	resp.Body.Close()
+	if total := resp.Header.Get("X-Total-Count"); total != "5" {
+		t.Errorf("expected X-Total-Count 5 on the filter result, got %q", total)
+	}
+	if link := resp.Header.Get("Link"); !strings.Contains(link, `rel="next"`) || !strings.Contains(link, `rel="prev"`) {
+		t.Errorf("expected next and prev links on the filter result, got %q", link)
+	}
+}
@@ -0,0 +1,41 @@
+// Package admintest - admin suite: seeds real files and exercises the export, import, backup and job
+// logic behind the admin page directly, without going through HTTP.
+package admintest
+
+import "knov/internal/test"
+
+// Suite runs the admin test cases against real files, metadata and settings.
+type Suite struct{}
+
+func init() {
+	test.Register(Suite{})
+}
+
+func (Suite) Name() string { return "admin" }
+
+func (Suite) Run() (*test.SuiteResult, error) {
+	if err := resetTestDir(); err != nil {
+		return nil, err
+	}
+
+	cases := []func() test.CaseResult{
+		caseExportSkipsPrivate,
+		casePruneEmptyFolders,
+		caseBackupRotation,
+		caseQueuedJobs,
+	}
+
+	result := &test.SuiteResult{Suite: "admin"}
+	for _, c := range cases {
+		cr := c()
+		result.Cases = append(result.Cases, cr)
+		if cr.Success {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	result.Total = len(cases)
+	result.Success = result.Failed == 0
+	return result, nil
+}
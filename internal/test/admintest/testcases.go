@@ -0,0 +1,262 @@
+// Package admintest - test cases for the export, import, backup and job logic of the admin page
+package admintest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"knov/internal/cacheStorage"
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/job"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// archiveNames returns the slash separated names of the files in a zip archive.
+func archiveNames(data []byte) ([]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, filepath.ToSlash(f.Name))
+	}
+	return names, nil
+}
+
+// caseExportSkipsPrivate marks the collection of the sample folder private and checks that
+// the zip export (files.WriteDataArchive, as handleAPIExportAllFiles calls it) leaves its
+// files out while a backup including private files keeps them. The markdown converted
+// export skips the same files through files.IsPrivate.
+func caseExportSkipsPrivate() test.CaseResult {
+	name := "export skips private collections"
+	rel := testPath("private.md")
+	if err := writeFile(rel, "# private\n"); err != nil {
+		return errCase(name, err)
+	}
+	archived := filepath.ToSlash(pathutils.ToWithPrefix(rel))
+
+	restore, err := overrideSetting("privateCollections", files.CollectionFromPath(rel))
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var export, backup bytes.Buffer
+	if err := files.WriteDataArchive(&export, false); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.WriteDataArchive(&backup, true); err != nil {
+		return errCase(name, err)
+	}
+	exported, err := archiveNames(export.Bytes())
+	if err != nil {
+		return errCase(name, err)
+	}
+	backedUp, err := archiveNames(backup.Bytes())
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	inExport, inBackup := slices.Contains(exported, archived), slices.Contains(backedUp, archived)
+	convertedSkips := files.IsPrivate(archived)
+	success := !inExport && inBackup && convertedSkips
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%s left out of the export and the converted export, kept in the backup", archived),
+		Actual:   fmt.Sprintf("in export=%t, skipped by the converted export=%t, in backup=%t", inExport, convertedSkips, inBackup),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "private collection files are not left out of the exports"
+	}
+	return cr
+}
+
+// casePruneEmptyFolders deletes the only file of a folder with pruneEmptyFolders on, as the
+// delete handlers do, then creates nested empty folders in docs and media and prunes them via
+// files.PruneEmptyDirs (the pruneempty endpoint). Folders with files and the docs and media
+// roots are kept. Pruning covers the whole vault, so only a minimum count is checked.
+func casePruneEmptyFolders() test.CaseResult {
+	name := "prune empty folders"
+	emptied, kept := testPath("prune/sub/note.md"), testPath("prune/kept/note.md")
+	for _, rel := range []string{emptied, kept} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	restore, err := overrideSetting("pruneEmptyFolders", "true")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	// the delete handlers prune after removing the file when the setting is on
+	if err := os.Remove(pathutils.ToDocsPath(emptied)); err != nil {
+		return errCase(name, err)
+	}
+	if configmanager.GetPruneEmptyFolders() {
+		if _, err := files.PruneEmptyDirs(); err != nil {
+			return errCase(name, err)
+		}
+	}
+	emptiedGone := !exists(filepath.Dir(pathutils.ToDocsPath(emptied)))
+	keptStays := exists(pathutils.ToDocsPath(kept))
+
+	emptyDirs := []string{pathutils.ToDocsPath(testPath("prune/empty/a/b")), pathutils.ToMediaPath(testPath("empty"))}
+	for _, dir := range emptyDirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errCase(name, err)
+		}
+	}
+	removed, err := files.PruneEmptyDirs()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var left []string
+	for _, dir := range append(emptyDirs, pathutils.ToDocsPath(testPath("prune/empty"))) {
+		if exists(dir) {
+			left = append(left, dir)
+		}
+	}
+	rootsKept := exists(pathutils.ToDocsPath("")) && exists(pathutils.ToMediaPath(""))
+
+	success := emptiedGone && keptStays && removed >= 4 && len(left) == 0 && rootsKept
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "emptied folder removed after the delete, other folder kept, at least 4 empty folders pruned, roots kept",
+		Actual:   fmt.Sprintf("emptied removed=%t, kept=%t, %d pruned, left %v, roots kept=%t", emptiedGone, keptStays, removed, left, rootsKept),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "empty folders were not pruned or too much was removed"
+	}
+	return cr
+}
+
+// caseBackupRotation runs files.WriteBackup, the backup job's archive and rotation, three
+// times into a temporary folder keeping 2: the archive holds the sample note, the third run
+// deletes the oldest archive and the newest two are kept.
+func caseBackupRotation() test.CaseResult {
+	name := "backup rotation"
+	rel := testPath("backup/note.md")
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	dir, err := os.MkdirTemp("", "knov-backup-test-")
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer os.RemoveAll(dir)
+
+	first, _, err := files.WriteBackup(dir, 2)
+	if err != nil {
+		return errCase(name, err)
+	}
+	data, err := os.ReadFile(first)
+	if err != nil {
+		return errCase(name, err)
+	}
+	names, err := archiveNames(data)
+	if err != nil {
+		return errCase(name, err)
+	}
+	archived := filepath.ToSlash(pathutils.ToWithPrefix(rel))
+	inBackup := slices.Contains(names, archived)
+
+	var last string
+	var pruned int
+	for range 2 {
+		if last, pruned, err = files.WriteBackup(dir, 2); err != nil {
+			return errCase(name, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+	firstGone, lastKept := !exists(first), exists(last)
+
+	success := inBackup && pruned == 1 && len(entries) == 2 && firstGone && lastKept
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%s in the backup, the third run prunes 1, 2 kept, oldest deleted, newest kept", archived),
+		Actual:   fmt.Sprintf("in backup=%t, pruned %d, %d kept, oldest deleted=%t, newest kept=%t", inBackup, pruned, len(entries), firstGone, lastKept),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "backups were not written or rotated as expected"
+	}
+	return cr
+}
+
+// caseQueuedJobs queues a filter reindex with job.Enqueue, as handleAPIEnqueueJob does, and
+// polls job.GetQueuedJob until it finished ok. A job the cache still holds as running but
+// this process never queued was interrupted by a restart and is reported as failed; an
+// unknown operation and an unknown job id are rejected.
+func caseQueuedJobs() test.CaseResult {
+	name := "queued jobs"
+	queued, err := job.Enqueue("filter-reindex")
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	status, err := job.GetQueuedJob(queued.ID)
+	for err == nil && !status.Done() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+		status, err = job.GetQueuedJob(queued.ID)
+	}
+	if err != nil {
+		return errCase(name, err)
+	}
+	finished := status.Status == job.JobStatusOK && status.Progress == 100 && status.StartedAt != nil && status.FinishedAt != nil
+
+	// the status under the cache key saveQueuedJob uses, as a previous process left it
+	started := time.Now()
+	orphan := job.QueuedJob{ID: "admintest-orphan", Operation: "rebuild", Status: job.JobStatusRunning, CreatedAt: started, StartedAt: &started}
+	data, err := json.Marshal(orphan)
+	if err != nil {
+		return errCase(name, err)
+	}
+	if err := cacheStorage.Set("job/"+orphan.ID, data); err != nil {
+		return errCase(name, err)
+	}
+	defer cacheStorage.Delete("job/" + orphan.ID) //nolint:errcheck
+	interrupted, err := job.GetQueuedJob(orphan.ID)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	_, unknownOperation := job.Enqueue("nope")
+	_, unknownJob := job.GetQueuedJob("unknown")
+
+	success := queued.Operation == "filter-reindex" && finished &&
+		interrupted.Status == job.JobStatusError && interrupted.Error == "interrupted by a restart" &&
+		errors.Is(unknownOperation, job.ErrUnknownOperation) && errors.Is(unknownJob, job.ErrJobNotFound)
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "filter-reindex finished ok at 100%, the orphaned job failed as interrupted by a restart, unknown operation and job rejected",
+		Actual: fmt.Sprintf("filter-reindex %s at %d%%, orphaned job %s %q, unknown operation: %v, unknown job: %v",
+			status.Status, status.Progress, interrupted.Status, interrupted.Error, unknownOperation, unknownJob),
+		Success: success,
+	}
+	if !success {
+		cr.Error = "queued jobs did not run or report their status as expected"
+	}
+	return cr
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
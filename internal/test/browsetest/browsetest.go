@@ -0,0 +1,40 @@
+// Package browsetest - browse suite: seeds real files and exercises the file list, browse,
+// tree and info slideout logic of internal/files and internal/filter directly, without going through HTTP.
+package browsetest
+
+import "knov/internal/test"
+
+// Suite runs the browse test cases against real files, metadata and settings.
+type Suite struct{}
+
+func init() {
+	test.Register(Suite{})
+}
+
+func (Suite) Name() string { return "browse" }
+
+func (Suite) Run() (*test.SuiteResult, error) {
+	if err := resetTestDir(); err != nil {
+		return nil, err
+	}
+
+	cases := []func() test.CaseResult{
+		caseDefaultFileSort,
+		caseFileListPages,
+		caseVaultStats,
+	}
+
+	result := &test.SuiteResult{Suite: "browse"}
+	for _, c := range cases {
+		cr := c()
+		result.Cases = append(result.Cases, cr)
+		if cr.Success {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	result.Total = len(cases)
+	result.Success = result.Failed == 0
+	return result, nil
+}
@@ -0,0 +1,247 @@
+// Package browsetest - test cases for the file list, browse, tree and info slideout logic
+package browsetest
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// folderFiles returns the base names of the files directly in folder, in the order of fileList.
+func folderFiles(fileList []files.File, folder string) []string {
+	var names []string
+	for _, f := range fileList {
+		rel := pathutils.ToRelative(f.Path)
+		if filepath.Dir(rel) == folder {
+			names = append(names, filepath.Base(rel))
+		}
+	}
+	return names
+}
+
+// caseDefaultFileSort checks that the file list and browse results follow the fileListSort
+// and fileListOrder settings, replicating handleAPIGetAllFiles' visibleFilesSorted and
+// handleAPIBrowseFiles' folder browse.
+func caseDefaultFileSort() test.CaseResult {
+	name := "file list and browse follow the default file sort"
+	folder := testPath("sortcase")
+
+	// the size order b, a, c differs from the path order a, b, c
+	seeds := []struct{ file, content string }{
+		{"b.md", "# b\n"},
+		{"a.md", "# a\n\nsome longer content\n"},
+		{"c.md", "# c\n\nthe longest content of all three files\n"},
+	}
+	for _, seed := range seeds {
+		rel := filepath.ToSlash(filepath.Join(folder, seed.file))
+		if err := writeFile(rel, seed.content); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	tests := []struct {
+		sort, order string
+		want        []string
+	}{
+		{files.SortByPath, "asc", []string{"a.md", "b.md", "c.md"}},
+		{files.SortByPath, "desc", []string{"c.md", "b.md", "a.md"}},
+		{files.SortBySize, "asc", []string{"b.md", "a.md", "c.md"}},
+	}
+
+	var mismatches []string
+	for _, tc := range tests {
+		restoreSort, err := overrideSetting("fileListSort", tc.sort)
+		if err != nil {
+			return errCase(name, err)
+		}
+		restoreOrder, err := overrideSetting("fileListOrder", tc.order)
+		if err != nil {
+			restoreSort()
+			return errCase(name, err)
+		}
+
+		// every save refreshes the cached file list in the background, and a refresh started
+		// before the last save can store a list without it - rebuild right before reading
+		err = files.RebuildAllCaches()
+		var allFiles []files.File
+		if err == nil {
+			allFiles, err = files.GetAllFilesCached()
+		}
+		if err == nil {
+			allFiles = files.FilterByVisibility(allFiles)
+			files.SortFilesDefault(allFiles)
+		}
+		var browsed []files.File
+		if err == nil {
+			browsed, err = filter.FilterFiles([]filter.Criteria{{Metadata: "folders", Operator: "contains", Value: "sortcase"}}, "and")
+		}
+		restoreOrder()
+		restoreSort()
+		if err != nil {
+			return errCase(name, err)
+		}
+
+		if list := folderFiles(allFiles, folder); !slices.Equal(list, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s: file list %v", tc.sort, tc.order, list))
+		}
+		if browse := folderFiles(browsed, folder); !slices.Equal(browse, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s: browse %v", tc.sort, tc.order, browse))
+		}
+	}
+
+	return test.CaseResult{
+		Name:     name,
+		Expected: "path asc [a b c], path desc [c b a], size asc [b a c] in the file list and browse",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+}
+
+// caseFileListPages checks files.GetAllFilesPage (GET /api/files/list with page or pageSize)
+// and the Offset and Limit a paginated POST /api/filters sets: with the path sort, read
+// straight from the paths, and the size sort, cut from the cached list, the pages together
+// list the visible files in the default sort, a page past the end is empty, and the filter
+// returns the matches after the offset.
+func caseFileListPages() test.CaseResult {
+	name := "file list pages follow the default file sort"
+	folder := testPath("pages")
+	for i, file := range []string{"a.md", "b.md", "c.md", "d.md", "e.md"} {
+		rel := filepath.ToSlash(filepath.Join(folder, file))
+		// sizes differ from the path order
+		if err := writeFile(rel, fmt.Sprintf("# %s\n\n%s\n", file, strings.Repeat("x", (i*3)%5*10))); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	for _, sort := range []string{files.SortByPath, files.SortBySize} {
+		restore, err := overrideSetting("fileListSort", sort)
+		if err != nil {
+			return errCase(name, err)
+		}
+		// rebuild right before reading, see caseDefaultFileSort
+		err = files.RebuildAllCaches()
+		var allFiles []files.File
+		if err == nil {
+			allFiles, err = files.GetAllFilesCached()
+		}
+		var paged []string
+		var pages, total int
+		if err == nil {
+			allFiles = files.FilterByVisibility(allFiles)
+			files.SortFilesDefault(allFiles)
+			var page *files.FilePage
+			for p := 1; err == nil; p++ {
+				if page, err = files.GetAllFilesPage(p, 2); err != nil || len(page.Files) == 0 {
+					break
+				}
+				pages, total = page.TotalPages(), page.Total
+				for _, f := range page.Files {
+					paged = append(paged, f.Path)
+				}
+			}
+		}
+		restore()
+		if err != nil {
+			return errCase(name, err)
+		}
+
+		var want []string
+		for _, f := range allFiles {
+			want = append(want, f.Path)
+		}
+		if !slices.Equal(paged, want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: pages list %d files, want %d in the file list order", sort, len(paged), len(want)))
+		}
+		if total != len(want) || pages != (len(want)+1)/2 {
+			mismatches = append(mismatches, fmt.Sprintf("%s: total %d in %d pages for %d files", sort, total, pages, len(want)))
+		}
+	}
+
+	result, err := filter.FilterFilesWithConfig(&filter.Config{
+		Criteria: []filter.Criteria{{Metadata: "folders", Operator: "under", Value: folder, Action: "include"}},
+		Logic:    "and", Limit: 2, Offset: 2,
+	})
+	if err != nil {
+		return errCase(name, err)
+	}
+	if got := folderFiles(result.Files, folder); !slices.Equal(got, []string{"c.md", "d.md"}) || result.Offset != 2 || result.Total != 5 {
+		mismatches = append(mismatches, fmt.Sprintf("filter page %v at offset %d of %d", got, result.Offset, result.Total))
+	}
+
+	return test.CaseResult{
+		Name:     name,
+		Expected: "the pages of 2 list every visible file in the path and size sort, the filter page lists c.md and d.md at offset 2 of 5",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+}
+
+// caseVaultStats checks files.CollectVaultStats, the counts of the home and files overview
+// pages: after a cache rebuild they match the cached file list and the cached tag, collection
+// and folder counts, which include the case's note, its tag and its folder.
+func caseVaultStats() test.CaseResult {
+	name := "vault stats count the cached files and metadata values"
+	rel := testPath("stats/note.md")
+	if err := writeFile(rel, "# stats\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: []string{"browsetest-stats"}}); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	stats := files.CollectVaultStats()
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return errCase(name, err)
+	}
+	tags, err := files.GetAllTagsCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	folders, err := files.GetAllFoldersCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	collections, err := files.GetAllCollectionsCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	if stats.Files != len(allFiles) || !slices.ContainsFunc(allFiles, func(f files.File) bool { return pathutils.ToRelative(f.Path) == rel }) {
+		mismatches = append(mismatches, fmt.Sprintf("files %d of %d cached, note listed=%t", stats.Files, len(allFiles),
+			slices.ContainsFunc(allFiles, func(f files.File) bool { return pathutils.ToRelative(f.Path) == rel })))
+	}
+	if _, ok := tags["browsetest-stats"]; stats.Tags != len(tags) || !ok {
+		mismatches = append(mismatches, fmt.Sprintf("tags %d of %d cached, tag counted=%t", stats.Tags, len(tags), ok))
+	}
+	if _, ok := folders[filepath.Base(filepath.Dir(rel))]; stats.Folders != len(folders) || !ok {
+		mismatches = append(mismatches, fmt.Sprintf("folders %d of %d cached, folder counted=%t", stats.Folders, len(folders), ok))
+	}
+	if stats.Collections != len(collections) {
+		mismatches = append(mismatches, fmt.Sprintf("collections %d of %d cached", stats.Collections, len(collections)))
+	}
+
+	return test.CaseResult{
+		Name:     name,
+		Expected: "file, tag, folder and collection counts equal the cached ones and include the note, its tag and its folder",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+}
@@ -28,9 +28,17 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		caseRenameDashboard,
 		caseDeleteDashboard,
 		caseExportImportDashboard,
+		caseExportImportRoundTrip,
+		caseDuplicateDashboard,
 		caseWidgetFilterData,
 		caseWidgetFileContentData,
 		caseWidgetAggregateData,
+		caseWidgetRenderOrder,
+		caseWidgetEmbedMaxBytes,
+		caseWidgetCalendarData,
+		caseWidgetKanbanData,
+		caseWidgetFilterPreset,
+		caseWidgetRefreshInterval,
 	}
 
 	result := &test.SuiteResult{Suite: "dashboard"}
@@ -35,6 +35,14 @@ var dashboardNames = []string{
 	"Dashtest Delete",
 	"Dashtest Export",
 	"Dashtest Export Imported",
+	"Dashtest Export Full",
+	"Dashtest Export Full Copy",
+	"Dashtest Export Bad Copy",
+	"Dashtest Duplicate",
+	"Dashtest Duplicate (copy)",
+	"Dashtest Duplicate (copy 2)",
+	"Dashtest Widget Order",
+	"Dashtest Widget Preset",
 }
 
 func testPath(name string) string {
@@ -1,6 +1,7 @@
 package dashboardtest
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -178,9 +179,8 @@ func caseDeleteDashboard() test.CaseResult {
 	return cr
 }
 
-// caseExportImportDashboard mirrors handleAPIExportDashboard (json.MarshalIndent) followed
-// by handleAPIImportDashboard (decode, reset ID, Create) - both are one-liners around
-// dashboard.Get/Create in internal/server, trivial to replicate without importing it.
+// caseExportImportDashboard round-trips a dashboard through dashboard.Export and
+// dashboard.Import, which back the export and import endpoints.
 func caseExportImportDashboard() test.CaseResult {
 	name := "export-import-dashboard"
 
@@ -198,28 +198,17 @@ func caseExportImportDashboard() test.CaseResult {
 	}
 	defer dashboard.Delete(original.ID)
 
-	fetched, err := dashboard.Get(original.ID)
+	exported, err := dashboard.Export(original.ID)
 	if err != nil {
 		return errCase(name, err)
 	}
 
-	exported, err := json.MarshalIndent(fetched, "", "  ")
+	// the id derives from the name, importing under the name of the still existing
+	// original would collide - as in the import form, a distinct name is given
+	imported, err := dashboard.Import(exported, "Dashtest Export Imported")
 	if err != nil {
 		return errCase(name, err)
 	}
-
-	var imported dashboard.Dashboard
-	if err := json.Unmarshal(exported, &imported); err != nil {
-		return errCase(name, err)
-	}
-	// mirrors the handler's optional name override on import: Create derives id from Name,
-	// so importing under the exact same name as the still-existing original would collide -
-	// same as the real "import" form requires a distinct name when the original still exists.
-	imported.ID = ""
-	imported.Name = "Dashtest Export Imported"
-	if err := dashboard.Create(&imported); err != nil {
-		return errCase(name, err)
-	}
 	defer dashboard.Delete(imported.ID)
 
 	success := imported.ID != original.ID && imported.Layout == original.Layout &&
@@ -238,6 +227,154 @@ func caseExportImportDashboard() test.CaseResult {
 	return cr
 }
 
+// caseExportImportRoundTrip covers the export format in full: the export is versioned, an
+// import under a new name keeps every widget setting, an import under the name of the still
+// existing original collides, and unknown widget types are named in the error and nothing
+// is created.
+func caseExportImportRoundTrip() test.CaseResult {
+	name := "export-import-round-trip"
+
+	original := &dashboard.Dashboard{
+		Name:   "Dashtest Export Full",
+		Layout: dashboard.TwoColumns,
+		Widgets: []dashboard.Widget{
+			{Type: dashboard.WidgetTypeFilter, Title: "todo", Position: dashboard.WidgetPosition{X: 1, Y: 2}, Config: dashboard.WidgetConfig{
+				Filter: &dashboard.FilterConfig{Criteria: []filter.Criteria{{Metadata: "tags", Operator: "contains", Value: "todo", Action: "include"}}, Logic: "or", Display: "table", Limit: 5, Columns: []string{"title"}},
+			}},
+			{Type: dashboard.WidgetTypeFileContent, Config: dashboard.WidgetConfig{FileContent: &dashboard.FileContentConfig{FilePath: "index.md"}}},
+		},
+	}
+	if err := dashboard.Create(original); err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(original.ID)
+
+	exported, err := dashboard.Export(original.ID)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var mismatches []string
+	var envelope struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(exported, &envelope); err != nil || envelope.Version != dashboard.ExportVersion {
+		mismatches = append(mismatches, fmt.Sprintf("export version %d (%v)", envelope.Version, err))
+	}
+
+	if imported, err := dashboard.Import(exported, "Dashtest Export Full Copy"); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("import: %v", err))
+	} else {
+		defer dashboard.Delete(imported.ID)
+		stored, err := dashboard.Get(imported.ID)
+		if err != nil {
+			return errCase(name, err)
+		}
+		stored.ID, stored.Name = original.ID, original.Name
+		want, _ := json.Marshal(original)
+		got, _ := json.Marshal(stored)
+		if !bytes.Equal(got, want) {
+			mismatches = append(mismatches, fmt.Sprintf("round-trip %s", got))
+		}
+	}
+
+	if colliding, err := dashboard.Import(exported, ""); err == nil {
+		dashboard.Delete(colliding.ID)
+		mismatches = append(mismatches, "import under the original name accepted")
+	}
+
+	bad := bytes.Replace(exported, []byte(`"type": "fileContent"`), []byte(`"type": "clock"`), 1)
+	if _, err := dashboard.Import(bad, "Dashtest Export Bad Copy"); err == nil || !strings.Contains(err.Error(), "unknown widget types: clock") {
+		mismatches = append(mismatches, fmt.Sprintf("unknown widget type: %v", err))
+	}
+	if rejected, _ := dashboard.Get(utils.CleanseID("Dashtest Export Bad Copy")); rejected != nil {
+		mismatches = append(mismatches, "rejected dashboard created")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "versioned export, identical round-trip, colliding import and unknown widget type rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "dashboard export/import did not keep or validate the dashboard as expected"
+	}
+	return cr
+}
+
+// caseDuplicateDashboard covers dashboard.Duplicate (POST /api/dashboards/{id}/duplicate):
+// the copy is named "<name> (copy)" with new widget ids, editing it leaves the original
+// alone, a second copy is numbered and a missing dashboard can't be copied.
+func caseDuplicateDashboard() test.CaseResult {
+	name := "duplicate-dashboard"
+
+	original := &dashboard.Dashboard{
+		Name:   "Dashtest Duplicate",
+		Layout: dashboard.OneColumn,
+		Widgets: []dashboard.Widget{
+			{ID: "custom", Type: dashboard.WidgetTypeFilter, Title: "todo", Config: dashboard.WidgetConfig{
+				Filter: &dashboard.FilterConfig{Criteria: []filter.Criteria{{Metadata: "tags", Operator: "contains", Value: "todo", Action: "include"}}, Logic: "or", Display: "list", Limit: 5},
+			}},
+		},
+	}
+	if err := dashboard.Create(original); err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(original.ID)
+
+	dup, err := dashboard.Duplicate(original.ID)
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(dup.ID)
+
+	var mismatches []string
+	if dup.Name != "Dashtest Duplicate (copy)" || dup.ID != utils.CleanseID("Dashtest Duplicate (copy)") {
+		mismatches = append(mismatches, fmt.Sprintf("copy name %q id %q", dup.Name, dup.ID))
+	}
+	if len(dup.Widgets) != 1 || dup.Widgets[0].ID != "widget-0" || dup.Widgets[0].Title != "todo" {
+		mismatches = append(mismatches, fmt.Sprintf("copied widgets %+v", dup.Widgets))
+	}
+
+	// editing the copy leaves the original alone
+	stored, err := dashboard.Get(dup.ID)
+	if err != nil {
+		return errCase(name, err)
+	}
+	stored.Widgets[0].Config.Filter.Criteria[0].Value = "done"
+	if err := dashboard.Update(stored); err != nil {
+		return errCase(name, err)
+	}
+	if reloaded, err := dashboard.Get(original.ID); err != nil || reloaded.Widgets[0].Config.Filter.Criteria[0].Value != "todo" {
+		mismatches = append(mismatches, "original criteria changed with the copy")
+	}
+
+	if again, err := dashboard.Duplicate(original.ID); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("second copy: %v", err))
+	} else {
+		defer dashboard.Delete(again.ID)
+		if again.Name != "Dashtest Duplicate (copy 2)" {
+			mismatches = append(mismatches, fmt.Sprintf("second copy name %q", again.Name))
+		}
+	}
+	if _, err := dashboard.Duplicate("dashtest_missing"); err == nil {
+		mismatches = append(mismatches, "missing dashboard duplicated")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "copy named (copy) with new widget ids, original unchanged, second copy (copy 2), missing dashboard rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "dashboard was not duplicated as expected"
+	}
+	return cr
+}
+
 // caseWidgetFilterData covers the filter widget's underlying data resolution (the render
 // dispatch itself lives in internal/server/render, unreachable here - see package doc).
 func caseWidgetFilterData() test.CaseResult {
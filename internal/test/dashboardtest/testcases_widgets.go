@@ -0,0 +1,444 @@
+package dashboardtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/dashboard"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/kanban"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseWidgetRenderOrder renders the widgets of a stored dashboard on several workers via
+// dashboard.RenderEach, the pool render.RenderWidgets runs the widget renderer in. Earlier
+// widgets take longer, so they finish last - the output still has to be in widget order.
+func caseWidgetRenderOrder() test.CaseResult {
+	name := "widget-render-order"
+
+	d := &dashboard.Dashboard{Name: "Dashtest Widget Order", Layout: dashboard.OneColumn}
+	for i := range 8 {
+		d.Widgets = append(d.Widgets, dashboard.Widget{
+			Type:   dashboard.WidgetTypeStatic,
+			Config: dashboard.WidgetConfig{Static: &dashboard.StaticConfig{Content: fmt.Sprintf("static-widget-%d", i), Format: "text"}},
+		})
+	}
+	if err := dashboard.Create(d); err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(d.ID)
+
+	stored, err := dashboard.Get(d.ID)
+	if err != nil || stored == nil {
+		return errCase(name, fmt.Errorf("dashboard %s not stored: %v", d.ID, err))
+	}
+	rendered := dashboard.RenderEach(context.Background(), stored.Widgets, 3, func(_ context.Context, widget *dashboard.Widget) string {
+		index := slices.IndexFunc(stored.Widgets, func(w dashboard.Widget) bool { return w.ID == widget.ID })
+		time.Sleep(time.Duration(len(stored.Widgets)-index) * time.Millisecond)
+		return widget.ID + ":" + widget.Config.Static.Content
+	})
+
+	var want []string
+	for i := range 8 {
+		want = append(want, fmt.Sprintf("widget-%d:static-widget-%d", i, i))
+	}
+	success := slices.Equal(rendered, want)
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%v", want),
+		Actual:   fmt.Sprintf("%v", rendered),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "widgets rendered out of order"
+	}
+	return cr
+}
+
+// caseWidgetEmbedMaxBytes covers the embedMaxBytes setting through files.GetFileContentLimited,
+// which the fileContent widget and filters displayed as content embed notes with: a small note
+// is embedded whole, a large one is cut off and flagged Truncated (render adds the "view full
+// note" link for it), and at 0 nothing is cut.
+func caseWidgetEmbedMaxBytes() test.CaseResult {
+	name := "widget-embed-max-bytes"
+	small, large := testPath("embed/small.md"), testPath("embed/large.md")
+	if err := writeFile(small, "# Small\n\nthe whole small note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := writeFile(large, "# Large\n\n"+strings.Repeat("filler line of the large note\n", 100)+"the very end\n"); err != nil {
+		return errCase(name, err)
+	}
+
+	previous := configmanager.EmbedMaxBytes.Get()
+	defer configmanager.EmbedMaxBytes.SetFromString(strconv.Itoa(previous)) //nolint:errcheck
+	if err := configmanager.EmbedMaxBytes.SetFromString("500"); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	embed := func(relPath string) *files.FileContent {
+		content, err := files.GetFileContentLimited(pathutils.ToDocsPath(relPath), configmanager.GetEmbedMaxBytes())
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", relPath, err))
+			return &files.FileContent{}
+		}
+		return content
+	}
+
+	if content := embed(small); !strings.Contains(content.HTML, "the whole small note") || content.Truncated {
+		mismatches = append(mismatches, fmt.Sprintf("small note %+v", content))
+	}
+	if content := embed(large); strings.Contains(content.HTML, "the very end") || !strings.Contains(content.HTML, "filler line") || !content.Truncated {
+		mismatches = append(mismatches, fmt.Sprintf("large note at 500 bytes truncated=%v", content.Truncated))
+	}
+
+	if err := configmanager.EmbedMaxBytes.SetFromString("0"); err != nil {
+		return errCase(name, err)
+	}
+	if content := embed(large); !strings.Contains(content.HTML, "the very end") || content.Truncated {
+		mismatches = append(mismatches, fmt.Sprintf("large note without a limit truncated=%v", content.Truncated))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "small note whole, large note cut off and truncated at 500 bytes, whole at 0",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "embedded notes were not truncated at embedMaxBytes as expected"
+	}
+	return cr
+}
+
+// caseWidgetCalendarData covers the calendar widget's data resolution: files.FilesByDay over
+// the dashboard.CalendarPeriod of the month lists a note due today on today, and leaves out
+// notes without a target date or with one outside the month.
+func caseWidgetCalendarData() test.CaseResult {
+	name := "widget-calendar-data"
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	due := testPath("calendar/due.md")
+	for rel, targetDate := range map[string]string{
+		due:                             today,
+		testPath("calendar/undated.md"): "",
+		testPath("calendar/old.md"):     "2000-01-01",
+	} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		metadata := &files.Metadata{Path: pathutils.ToWithPrefix(rel)}
+		if targetDate != "" {
+			metadata.Custom = map[string]string{files.TargetDateField: targetDate}
+		}
+		if err := files.MetaDataSaveNoRefresh(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	from, to := dashboard.CalendarPeriod(dashboard.CalendarRangeMonth, now)
+	days, err := files.FilesByDay(files.TargetDateField, from, to)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var listed []string
+	for day, dayFiles := range days {
+		for _, file := range dayFiles {
+			if strings.HasPrefix(pathutils.ToRelative(file.Path), testPath("calendar/")) {
+				listed = append(listed, day+" "+filepath.Base(file.Path))
+			}
+		}
+	}
+
+	success := slices.Equal(listed, []string{today + " due.md"})
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("only due.md listed, on %s", today),
+		Actual:   fmt.Sprintf("listed %v", listed),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "calendar widget data did not list the notes by their target date"
+	}
+	return cr
+}
+
+// caseWidgetKanbanData covers the kanban widget's data resolution: the group field is
+// checked with kanban.IsFieldGroupBy and the sorted file list grouped with filter.GroupFiles.
+// Status columns keep the configured order with files without a status last, priority groups
+// by the priority custom field and other fields are rejected.
+func caseWidgetKanbanData() test.CaseResult {
+	name := "widget-kanban-data"
+	statuses := configmanager.GetKanbanStatuses()
+	statusTag := configmanager.GetKanbanPrefix() + "-status-"
+	notes := map[string]*files.Metadata{
+		"first":  {Tags: []string{"plain", statusTag + statuses[0]}, Custom: map[string]string{"priority": "high"}},
+		"second": {Tags: []string{statusTag + statuses[1]}, Custom: map[string]string{"priority": "low"}},
+		"third":  {Tags: []string{statusTag + statuses[0]}, Custom: map[string]string{"priority": "low"}},
+		"none":   {Tags: []string{"plain"}},
+	}
+	for note, metadata := range notes {
+		rel := testPath("kanban/" + note + ".md")
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		metadata.Path = pathutils.ToWithPrefix(rel)
+		if err := files.MetaDataSaveNoRefresh(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	// columns maps each group value to the case's notes in it, in group order
+	folder := pathutils.ToWithPrefix(testPath("kanban")) + "/"
+	columns := func(groupField string) (map[string][]string, []string, error) {
+		groupBy := dashboard.KanbanConfig{GroupField: groupField}.GroupBy()
+		if !kanban.IsFieldGroupBy(groupBy) {
+			return nil, nil, fmt.Errorf("invalid kanban group field: %s", groupField)
+		}
+		allFiles, err := files.GetAllFilesCached()
+		if err != nil {
+			return nil, nil, err
+		}
+		files.SortFilesDefault(allFiles)
+		got := make(map[string][]string)
+		var order []string
+		for _, group := range filter.GroupFiles(allFiles, groupBy) {
+			for _, f := range group.Files {
+				if rest, ok := strings.CutPrefix(pathutils.ToWithPrefix(f.Path), folder); ok {
+					if len(got[group.Value]) == 0 {
+						order = append(order, group.Value)
+					}
+					got[group.Value] = append(got[group.Value], strings.TrimSuffix(rest, ".md"))
+				}
+			}
+		}
+		return got, order, nil
+	}
+
+	var mismatches []string
+	got, order, err := columns("status")
+	if err != nil {
+		return errCase(name, err)
+	}
+	for value, want := range map[string][]string{statuses[0]: {"first", "third"}, statuses[1]: {"second"}, "": {"none"}} {
+		slices.Sort(got[value])
+		if !slices.Equal(got[value], want) {
+			mismatches = append(mismatches, fmt.Sprintf("status column %q: %v", value, got[value]))
+		}
+	}
+	if want := []string{statuses[0], statuses[1], ""}; !slices.Equal(order, want) {
+		mismatches = append(mismatches, fmt.Sprintf("status columns %q", order))
+	}
+
+	got, _, err = columns("priority")
+	if err != nil {
+		return errCase(name, err)
+	}
+	slices.Sort(got["low"])
+	if !slices.Equal(got["high"], []string{"first"}) || !slices.Equal(got["low"], []string{"second", "third"}) {
+		mismatches = append(mismatches, fmt.Sprintf("priority columns %v", got))
+	}
+	if _, _, err := columns("editor"); err == nil {
+		mismatches = append(mismatches, "group field editor accepted")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "status columns in configured order with first and third, second, then none; priority columns high and low; editor rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "kanban widget columns did not group the files as expected"
+	}
+	return cr
+}
+
+// caseWidgetFilterPreset covers filter widgets naming a preset: the widget's own criteria
+// are ignored and the preset is looked up with filter.GetPreset on every render, so saving
+// the preset again changes the widget without touching the dashboard. A missing preset fails
+// with filter.ErrPresetNotFound.
+func caseWidgetFilterPreset() test.CaseResult {
+	name := "widget-filter-preset"
+	const presetName = "dashtest-widgettags"
+	for note, tag := range map[string]string{"urgent": "dashtest-urgent", "later": "dashtest-later"} {
+		rel := testPath("preset/" + note + ".md")
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := saveMetadata(rel, []string{tag}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	savePreset := func(tag string) error {
+		return filter.SavePreset(presetName, &filter.Config{
+			Logic:    "and",
+			Criteria: []filter.Criteria{{Metadata: "tags", Operator: "equals", Value: tag, Action: "include"}},
+		})
+	}
+	if err := savePreset("dashtest-urgent"); err != nil {
+		return errCase(name, err)
+	}
+	defer filter.DeletePreset(presetName) //nolint:errcheck
+
+	d := &dashboard.Dashboard{Name: "Dashtest Widget Preset", Layout: dashboard.OneColumn, Widgets: []dashboard.Widget{{
+		Type: dashboard.WidgetTypeFilter,
+		Config: dashboard.WidgetConfig{Filter: &dashboard.FilterConfig{
+			Preset:   presetName,
+			Criteria: []filter.Criteria{{Metadata: "tags", Operator: "equals", Value: "dashtest-later", Action: "include"}},
+		}},
+	}}}
+	if err := dashboard.Create(d); err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(d.ID)
+
+	// widgetNotes resolves the stored widget like render.RenderWidget and returns the case's
+	// notes it lists
+	widgetNotes := func() ([]string, error) {
+		stored, err := dashboard.Get(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		preset, err := filter.GetPreset(stored.Widgets[0].Config.Filter.Preset)
+		if err != nil {
+			return nil, err
+		}
+		result, err := filter.FilterFilesWithConfig(preset)
+		if err != nil {
+			return nil, err
+		}
+		var notes []string
+		for _, f := range result.Files {
+			if filepath.Dir(pathutils.ToRelative(f.Path)) == testPath("preset") {
+				notes = append(notes, strings.TrimSuffix(f.Name, ".md"))
+			}
+		}
+		return notes, nil
+	}
+
+	var mismatches []string
+	if got, err := widgetNotes(); err != nil {
+		return errCase(name, err)
+	} else if !slices.Equal(got, []string{"urgent"}) {
+		mismatches = append(mismatches, fmt.Sprintf("preset widget listed %v", got))
+	}
+	if err := savePreset("dashtest-later"); err != nil {
+		return errCase(name, err)
+	}
+	if got, err := widgetNotes(); err != nil {
+		return errCase(name, err)
+	} else if !slices.Equal(got, []string{"later"}) {
+		mismatches = append(mismatches, fmt.Sprintf("after the preset changed %v", got))
+	}
+	if _, err := filter.GetPreset("dashtest-missing"); !errors.Is(err, filter.ErrPresetNotFound) {
+		mismatches = append(mismatches, fmt.Sprintf("missing preset: %v", err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "the widget lists urgent, then later after the preset changed, a missing preset is not found",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "filter widget did not follow its preset"
+	}
+	return cr
+}
+
+// caseWidgetRefreshInterval posts a dashboard form through dashboard.ParseWidgetsForm, the
+// parser the create and update handlers use, and stores the result. A negative interval is
+// clamped to 0 and a missing one turns the refresh off again.
+func caseWidgetRefreshInterval() test.CaseResult {
+	name := "widget-refresh-interval"
+	form := url.Values{
+		"widgets[0][type]":           {string(dashboard.WidgetTypeTags)},
+		"widgets[0][refreshSeconds]": {"30"},
+		"widgets[1][type]":           {string(dashboard.WidgetTypeFolders)},
+		"widgets[1][refreshSeconds]": {"-5"},
+	}
+	parse := func() ([]dashboard.Widget, error) {
+		r := httptest.NewRequest(http.MethodPost, "/api/dashboards", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return dashboard.ParseWidgetsForm(r)
+	}
+
+	widgets, err := parse()
+	if err != nil {
+		return errCase(name, err)
+	}
+	d := &dashboard.Dashboard{Name: "Dashtest Widget Refresh", Layout: dashboard.OneColumn, Widgets: widgets}
+	if err := dashboard.Create(d); err != nil {
+		return errCase(name, err)
+	}
+	defer dashboard.Delete(d.ID)
+
+	var mismatches []string
+	if stored, err := dashboard.Get(d.ID); err != nil {
+		return errCase(name, err)
+	} else if len(stored.Widgets) != 2 || stored.Widgets[0].RefreshSeconds != 30 || stored.Widgets[1].RefreshSeconds != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("created intervals %v", refreshIntervals(stored.Widgets)))
+	}
+
+	form.Del("widgets[0][refreshSeconds]")
+	if d.Widgets, err = parse(); err != nil {
+		return errCase(name, err)
+	}
+	if err := dashboard.Update(d); err != nil {
+		return errCase(name, err)
+	}
+	if stored, err := dashboard.Get(d.ID); err != nil {
+		return errCase(name, err)
+	} else if len(stored.Widgets) != 2 || stored.Widgets[0].RefreshSeconds != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("updated intervals %v", refreshIntervals(stored.Widgets)))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "intervals 30 and 0 after create, 0 after the interval was left out on update",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "widget refresh interval not stored as posted"
+	}
+	return cr
+}
+
+func refreshIntervals(widgets []dashboard.Widget) []int {
+	intervals := make([]int, len(widgets))
+	for i, w := range widgets {
+		intervals[i] = w.RefreshSeconds
+	}
+	return intervals
+}
@@ -27,11 +27,20 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		caseTodoCreateEditSave,
 		caseIndexCreateEditSave,
 		caseTableCreateEditSave,
+		caseFileHandler,
 		caseSectionSave,
 		caseTodoToggle,
 		caseConvertToMarkdown,
 		caseFileRename,
 		caseFileMove,
+		caseFolderMove,
+		caseJournalToday,
+		caseUnsafePaths,
+		caseExternalChange,
+		caseNewFileScaffold,
+		caseRetitleOnSave,
+		caseCacheExternalImages,
+		caseEditorLink,
 		caseBulkDeleteFiles,
 		caseBulkMetadataPatch,
 		caseBulkChatMoveDelete,
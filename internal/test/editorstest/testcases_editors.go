@@ -8,6 +8,7 @@ import (
 	"knov/internal/contentHandler"
 	"knov/internal/files"
 	"knov/internal/filter"
+	"knov/internal/parser"
 	"knov/internal/pathutils"
 	"knov/internal/test"
 )
@@ -277,3 +278,57 @@ func caseTableCreateEditSave() test.CaseResult {
 	}
 	return cr
 }
+
+// caseFileHandler covers what GET /api/files/handler reports: the parser of a file by its
+// extension and the editor it opens in - the editor of its metadata, else the textarea
+// editor for non-markdown parsers and the default markdown editor for the rest. The handler
+// is unexported, so its lookup is replicated around the same parser and files calls.
+func caseFileHandler() test.CaseResult {
+	name := "file-handler"
+	note, page, groceries := testPath("handler/note.md"), testPath("handler/page.dokuwiki"), testPath("handler/groceries.md")
+	for _, rel := range []string{note, page, groceries} {
+		if err := writeFile(rel, "note\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.MetaDataSaveRaw(&files.Metadata{Path: pathutils.ToWithPrefix(groceries), Editor: files.EditorTypeList}); err != nil {
+		return errCase(name, err)
+	}
+
+	fileHandler := func(rel string) (string, files.EditorType) {
+		handlerName := ""
+		if handler := parser.GetParserRegistry().GetHandler(rel); handler != nil {
+			handlerName = handler.Name()
+		}
+		if metadata, _ := files.MetaDataGet(pathutils.ToWithPrefix(rel)); metadata != nil && metadata.Editor != "" {
+			return handlerName, metadata.Editor
+		}
+		if handlerName != "markdown" {
+			return handlerName, files.EditorTypeTextarea
+		}
+		return handlerName, files.EditorType(configmanager.GetDefaultMarkdownEditor())
+	}
+
+	var mismatches []string
+	for rel, want := range map[string][2]string{
+		note:      {"markdown", configmanager.GetDefaultMarkdownEditor()},
+		page:      {"plaintext", string(files.EditorTypeTextarea)},
+		groceries: {"markdown", string(files.EditorTypeList)},
+	} {
+		if handlerName, editor := fileHandler(rel); handlerName != want[0] || string(editor) != want[1] {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %s/%s", rel, handlerName, editor))
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "note.md markdown/default editor, page.dokuwiki plaintext/textarea, groceries.md markdown/list",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "parser or editor of a file did not match its extension and metadata"
+	}
+	return cr
+}
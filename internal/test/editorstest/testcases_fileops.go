@@ -2,14 +2,20 @@ package editorstest
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"knov/internal/configmanager"
 	"knov/internal/files"
 	"knov/internal/logging"
 	"knov/internal/pathutils"
 	"knov/internal/test"
+	"knov/internal/watcher"
 )
 
 // renameCase seeds a target file plus a referencer linking to it, moves the target on disk
@@ -78,3 +84,419 @@ func caseFileRename() test.CaseResult {
 func caseFileMove() test.CaseResult {
 	return renameCase("file-move", testPath("move-src/movefile.md"), testPath("move-dst/movefile.md"))
 }
+
+// caseFolderMove covers files.MoveFolder (handleAPIMoveFolder): a folder holding two files
+// that link to each other, plus an outside referencer linking into it, is moved in one go,
+// then every link - inbound and cross-file inside the folder - must point at the new paths.
+func caseFolderMove() test.CaseResult {
+	name := "folder-move"
+	oldDir := testPath("movefolder-src")
+	newDir := testPath("movefolder-dst")
+	first := filepath.ToSlash(filepath.Join(oldDir, "first.md"))
+	second := filepath.ToSlash(filepath.Join(oldDir, "second.md"))
+	referencer := testPath(name + "-referencer.md")
+
+	seed := map[string]string{
+		first:      fmt.Sprintf("# first\n[second](%s)\n", second),
+		second:     fmt.Sprintf("# second\n[first](%s)\n", first),
+		referencer: fmt.Sprintf("[first](%s)\n[second](%s)\n", first, second),
+	}
+	for rel, content := range seed {
+		if err := writeFile(rel, content); err != nil {
+			return errCase(name, err)
+		}
+		if err := saveMetadata(rel, files.EditorTypeToastUI); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.MetaDataLinksRebuild(logging.KeyApp); err != nil {
+		return errCase(name, err)
+	}
+
+	if err := files.MoveFolder(oldDir, newDir); err != nil {
+		return errCase(name, err)
+	}
+
+	newFirst := filepath.ToSlash(filepath.Join(newDir, "first.md"))
+	newSecond := filepath.ToSlash(filepath.Join(newDir, "second.md"))
+	expected := []struct {
+		rel  string
+		want []string
+	}{
+		{referencer, []string{newFirst, newSecond}},
+		{newFirst, []string{newSecond}},
+		{newSecond, []string{newFirst}},
+	}
+
+	var actual []string
+	success := true
+	for _, e := range expected {
+		got, err := readFile(e.rel)
+		if err != nil {
+			return errCase(name, err)
+		}
+		actual = append(actual, fmt.Sprintf("%s: %q", e.rel, got))
+		if strings.Contains(got, oldDir+"/") {
+			success = false
+		}
+		for _, w := range e.want {
+			if !strings.Contains(got, w) {
+				success = false
+			}
+		}
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("all links point into %s", newDir),
+		Actual:   strings.Join(actual, "\n"),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "not every link into the moved folder was rewritten"
+	}
+	return cr
+}
+
+// caseJournalToday mirrors handleAPIJournalToday: the first call for a day creates the entry
+// in the journal folder from the journal template, a second call returns the same entry
+// without touching it.
+func caseJournalToday() test.CaseResult {
+	name := "journal-today"
+	template := testPath("journal-template.md")
+	if err := writeFile(template, "# Journal {{date}}\n"); err != nil {
+		return errCase(name, err)
+	}
+
+	// settings are only changed in memory, nothing persists them during the case
+	prevFolder, prevTemplate := configmanager.JournalFolder.Get(), configmanager.JournalTemplate.Get()
+	defer configmanager.JournalFolder.SetFromString(prevFolder)     //nolint:errcheck
+	defer configmanager.JournalTemplate.SetFromString(prevTemplate) //nolint:errcheck
+	if err := configmanager.JournalFolder.SetFromString(testPath("journal")); err != nil {
+		return errCase(name, err)
+	}
+	if err := configmanager.JournalTemplate.SetFromString(template); err != nil {
+		return errCase(name, err)
+	}
+
+	day := time.Date(2026, 1, 31, 9, 0, 0, 0, time.Local)
+	first, created, err := files.EnsureJournalEntry(day)
+	if err != nil {
+		return errCase(name, err)
+	}
+	initial, err := readFile(first)
+	if err != nil {
+		return errCase(name, err)
+	}
+	if err := writeFile(first, initial+"written during the day\n"); err != nil {
+		return errCase(name, err)
+	}
+	second, createdAgain, err := files.EnsureJournalEntry(day.Add(8 * time.Hour))
+	if err != nil {
+		return errCase(name, err)
+	}
+	got, err := readFile(second)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	// a journal folder outside the docs folder is rejected by the setting itself
+	var accepted []string
+	for _, folder := range []string{"../outside", "journal/../../outside", "/tmp/journal"} {
+		if configmanager.JournalFolder.SetFromString(folder) == nil {
+			accepted = append(accepted, folder)
+		}
+	}
+
+	want := testPath("journal/2026-01-31.md")
+	success := created && !createdAgain && first == want && second == want &&
+		initial == "# Journal 2026-01-31\n" && strings.Contains(got, "written during the day") && len(accepted) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%s created once from the template, then returned unchanged; folders outside docs rejected", want),
+		Actual:   fmt.Sprintf("first=%s created=%v second=%s created=%v content=%q accepted=%v", first, created, second, createdAgain, got, accepted),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "journal entry was not created once and reused"
+	}
+	return cr
+}
+
+// caseNewFileScaffold mirrors handleAPIFileSave and handleAPIGetEditor: a new file saved
+// without content starts from the template configured for its editor, with {{date}}
+// replaced, while supplied content and editors without a template are left alone.
+func caseNewFileScaffold() test.CaseResult {
+	name := "new-file-scaffold"
+	template := testPath("literature-template.md")
+	if err := writeFile(template, "# Literature {{date}}\n\n## Source\n\n## Summary\n"); err != nil {
+		return errCase(name, err)
+	}
+
+	// settings are only changed in memory, nothing persists them during the case
+	prevTemplates := strings.Join(configmanager.NewFileTemplates.Get(), ",")
+	defer configmanager.NewFileTemplates.SetFromString(prevTemplates) //nolint:errcheck
+	if err := configmanager.NewFileTemplates.SetFromString(string(files.EditorTypeToastUI) + "=" + template); err != nil {
+		return errCase(name, err)
+	}
+
+	// the new file branch of handleAPIFileSave
+	save := func(relPath, content string) (string, error) {
+		if _, err := os.Stat(pathutils.ToDocsPath(relPath)); os.IsNotExist(err) && content == "" {
+			content = files.NewFileScaffold(files.EditorTypeToastUI)
+		}
+		if err := writeFile(relPath, content); err != nil {
+			return "", err
+		}
+		return readFile(relPath)
+	}
+	scaffolded, err := save(testPath("scaffold/literature-note.md"), "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	own, err := save(testPath("scaffold/own-content.md"), "# mine\n")
+	if err != nil {
+		return errCase(name, err)
+	}
+	untemplated := files.NewFileScaffold(files.EditorTypeTextarea)
+
+	want := "# Literature " + time.Now().Format("2006-01-02") + "\n\n## Source\n\n## Summary\n"
+	success := scaffolded == want && own == "# mine\n" && untemplated == ""
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("new note %q, supplied content kept, no scaffold for textarea", want),
+		Actual:   fmt.Sprintf("new note %q, supplied %q, textarea %q", scaffolded, own, untemplated),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "new files don't start from the scaffold of their editor"
+	}
+	return cr
+}
+
+// caseRetitleOnSave mirrors handleAPIFileSave with the retitleOnSave setting: a new file
+// takes the title of its first heading, saving a changed heading retitles the file and the
+// title list follows, and with the setting off the title stays as it was.
+func caseRetitleOnSave() test.CaseResult {
+	name := "retitle-on-save"
+	relPath := testPath("retitle/note.md")
+
+	// settings are only changed in memory, nothing persists them during the case
+	prevRetitle := configmanager.GetRetitleOnSave()
+	defer configmanager.RetitleOnSave.SetFromString(fmt.Sprint(prevRetitle)) //nolint:errcheck
+	if err := configmanager.RetitleOnSave.SetFromString("true"); err != nil {
+		return errCase(name, err)
+	}
+
+	// the new file and existing file branches of handleAPIFileSave
+	save := func(content string) (string, error) {
+		_, statErr := os.Stat(pathutils.ToDocsPath(relPath))
+		if err := writeFile(relPath, content); err != nil {
+			return "", err
+		}
+		if os.IsNotExist(statErr) {
+			if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(relPath)}); err != nil {
+				return "", err
+			}
+		} else if err := files.UpdateLinksForSingleFile(pathutils.ToWithPrefix(relPath)); err != nil {
+			return "", err
+		}
+		meta, err := files.MetaDataGet(relPath)
+		if err != nil || meta == nil {
+			return "", fmt.Errorf("metadata missing after save: %v", err)
+		}
+		return meta.Title, nil
+	}
+
+	created, err := save("# First Heading\n\nbody\n")
+	if err != nil {
+		return errCase(name, err)
+	}
+	edited, err := save("# Second Heading\n\nbody\n")
+	if err != nil {
+		return errCase(name, err)
+	}
+	titles, err := files.GetAllTitles()
+	if err != nil {
+		return errCase(name, err)
+	}
+	listed := slices.Contains(titles, "Second Heading")
+	if err := configmanager.RetitleOnSave.SetFromString("false"); err != nil {
+		return errCase(name, err)
+	}
+	kept, err := save("# Third Heading\n\nbody\n")
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := created == "First Heading" && edited == "Second Heading" && listed && kept == "Second Heading"
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "First Heading, then Second Heading in the title list, kept as Second Heading with retitleOnSave off",
+		Actual:   fmt.Sprintf("%s, then %s (in title list: %v), then %s", created, edited, listed, kept),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the title did not follow the first heading on save or changed with retitleOnSave off"
+	}
+	return cr
+}
+
+// caseEditorLink covers files.EditorLink (GET /api/files/editorlink): with {path} in the query
+// of the editorLinkTemplate setting the absolute path is one escaped value, elsewhere the
+// slashes are kept and each segment escaped. A template without {path} is rejected.
+func caseEditorLink() test.CaseResult {
+	name := "editor-link"
+	rel := testPath("editorlink/my note&more.md")
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	absPath, err := filepath.Abs(pathutils.ToDocsPath(rel))
+	if err != nil {
+		return errCase(name, err)
+	}
+	absPath = filepath.ToSlash(absPath)
+
+	previous := configmanager.GetEditorLinkTemplate()
+	defer configmanager.EditorLinkTemplate.SetFromString(previous) //nolint:errcheck // restoring a previously valid value
+
+	var mismatches []string
+	link := func(template string) string {
+		if err := configmanager.EditorLinkTemplate.SetFromString(template); err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", template, err))
+			return ""
+		}
+		link, err := files.EditorLink(rel)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", template, err))
+		}
+		return link
+	}
+
+	if got := link("obsidian://open?path={path}"); got != "obsidian://open?path="+url.QueryEscape(absPath) {
+		mismatches = append(mismatches, "query link "+got)
+	}
+	if got := link("vscode://file{path}"); !strings.HasPrefix(got, "vscode://file/") || !strings.HasSuffix(got, "/editorlink/my%20note&more.md") {
+		mismatches = append(mismatches, "path link "+got)
+	}
+	if configmanager.EditorLinkTemplate.SetFromString("no placeholder") == nil {
+		mismatches = append(mismatches, "template without {path} accepted")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "obsidian link with the escaped path as query value, vscode link with escaped segments, template without {path} rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "editor links were not built from the template as expected"
+	}
+	return cr
+}
+
+// caseUnsafePaths checks pathutils.SafeVaultPath, which the router and the file handlers run
+// every user supplied path through: ".." segments, absolute paths and a symlink out of the
+// docs folder are rejected; without strictPaths an absolute path into the data folder passes
+// while one outside it still doesn't.
+func caseUnsafePaths() test.CaseResult {
+	name := "unsafe-paths"
+	outside, err := os.MkdirTemp("", "knov-unsafe-paths-")
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer os.RemoveAll(outside)
+	escape := pathutils.ToDocsPath(testPath("escape"))
+	if err := os.Symlink(outside, escape); err != nil {
+		return errCase(name, err)
+	}
+	defer os.Remove(escape)
+	inside := pathutils.ToDocsPath(testPath("note.md"))
+
+	var mismatches []string
+	check := func(path string, wantSafe bool) {
+		if _, err := pathutils.SafeVaultPath(path); (err == nil) != wantSafe {
+			mismatches = append(mismatches, fmt.Sprintf("%s: safe=%t", path, err == nil))
+		}
+	}
+	check(testPath("note.md"), true)
+	for _, path := range []string{"../../etc/passwd", "test/../../etc/passwd", "/etc/passwd", inside, testPath("escape/secret.md")} {
+		check(path, false)
+	}
+
+	prevStrict := configmanager.StrictPaths.Get()
+	defer configmanager.StrictPaths.SetFromString(strconv.FormatBool(prevStrict)) //nolint:errcheck
+	if err := configmanager.StrictPaths.SetFromString("false"); err != nil {
+		return errCase(name, err)
+	}
+	check(inside, true)
+	check("/etc/passwd", false)
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "paths inside docs safe; .., absolute and symlinked escapes unsafe; absolute docs path safe without strictPaths",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "unsafe paths were not told apart from safe ones"
+	}
+	return cr
+}
+
+// caseExternalChange writes a note into a new folder behind knov's back, like a git pull
+// does, and waits for the file watcher - the source of the /api/events stream - to
+// reindex it and publish its change. The watcher is started for the case if the app did
+// not start it.
+func caseExternalChange() test.CaseResult {
+	name := "external-change"
+	if !watcher.Running() {
+		if err := watcher.Start(); err != nil {
+			return errCase(name, err)
+		}
+		defer watcher.Stop()
+	}
+	events, unsubscribe, err := watcher.Subscribe(configmanager.GetEventStreamLimit() + 1)
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer unsubscribe()
+
+	rel := testPath("external/note.md")
+	if err := writeFile(rel, "# written outside knov\n"); err != nil {
+		return errCase(name, err)
+	}
+	path := pathutils.ToWithPrefix(pathutils.ToDocsPath(rel))
+
+	var mismatches []string
+	timeout := time.After(5 * time.Second)
+	for received := false; !received; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return errCase(name, fmt.Errorf("event subscription closed"))
+			}
+			received = event.Type == watcher.EventFileChanged && event.Path == path
+		case <-timeout:
+			mismatches = append(mismatches, fmt.Sprintf("no %s event for %s", watcher.EventFileChanged, path))
+			received = true
+		}
+	}
+	if metadata, err := files.MetaDataGet(path); err != nil || metadata == nil {
+		mismatches = append(mismatches, fmt.Sprintf("not reindexed: %v", err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("a %s event for %s and its metadata", watcher.EventFileChanged, path),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "external change not picked up by the file watcher"
+	}
+	return cr
+}
@@ -0,0 +1,133 @@
+package editorstest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseCacheExternalImages mirrors handleAPIFileSave with the cacheExternalImages setting,
+// against a local image server: with the setting off content is saved as it is, with it on
+// every image url is downloaded once into the media folder next to the file and relinked,
+// while oversized and non-image downloads stay linked externally. Saving the file again
+// reuses the cached copy.
+func caseCacheExternalImages() test.CaseResult {
+	name := "cache-external-images"
+	mediaDir := pathutils.ToWithPrefix("media/" + testPath("extimg"))
+	cleanMedia := func() {
+		os.RemoveAll(pathutils.ToMediaPath(testPath("extimg"))) //nolint:errcheck
+		files.MetaDataDeletePrefix(mediaDir + "/")              //nolint:errcheck
+	}
+	cleanMedia()
+	defer cleanMedia()
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/cat.png":
+			w.Write(png)
+		case "/huge.png":
+			w.Write(append(png, bytes.Repeat([]byte{0}, 2<<20)...))
+		case "/page.png":
+			fmt.Fprint(w, "<html>not an image</html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer remote.Close()
+
+	// settings are only changed in memory, nothing persists them during the case
+	prevCache, prevMaxUpload := configmanager.GetCacheExternalImages(), configmanager.MaxUploadSizeMB.Get()
+	defer configmanager.CacheExternalImages.SetFromString(strconv.FormatBool(prevCache)) //nolint:errcheck
+	defer configmanager.MaxUploadSizeMB.SetFromString(strconv.Itoa(prevMaxUpload))       //nolint:errcheck
+	if err := configmanager.MaxUploadSizeMB.SetFromString("1"); err != nil {
+		return errCase(name, err)
+	}
+	if err := configmanager.CacheExternalImages.SetFromString("false"); err != nil {
+		return errCase(name, err)
+	}
+
+	// the new file and existing file branches of handleAPIFileSave
+	save := func(relPath, content string) (string, error) {
+		_, statErr := os.Stat(pathutils.ToDocsPath(relPath))
+		if err := writeFile(relPath, content); err != nil {
+			return "", err
+		}
+		if os.IsNotExist(statErr) {
+			if _, err := files.CacheExternalImages(relPath); err != nil {
+				return "", err
+			}
+			if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(relPath)}); err != nil {
+				return "", err
+			}
+		} else if err := files.UpdateLinksForSingleFile(pathutils.ToWithPrefix(relPath)); err != nil {
+			return "", err
+		}
+		return readFile(relPath)
+	}
+
+	content := fmt.Sprintf("![cat](%[1]s/img/cat.png)\n![again](%[1]s/img/cat.png)\n![big](%[1]s/huge.png)\n![page](%[1]s/page.png)\n", remote.URL)
+	off, err := save(testPath("extimg/off.md"), content)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	if err := configmanager.CacheExternalImages.SetFromString("true"); err != nil {
+		return errCase(name, err)
+	}
+	on := testPath("extimg/on.md")
+	created, err := save(on, content)
+	if err != nil {
+		return errCase(name, err)
+	}
+	resaved, err := save(on, content)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	local := "/" + mediaDir + "/cat.png"
+	want := fmt.Sprintf("![cat](%[2]s)\n![again](%[2]s)\n![big](%[1]s/huge.png)\n![page](%[1]s/page.png)\n", remote.URL, local)
+	if off != content {
+		mismatches = append(mismatches, "content changed with the setting off")
+	}
+	if created != want || resaved != want {
+		mismatches = append(mismatches, fmt.Sprintf("content %q, resaved %q", created, resaved))
+	}
+	if data, err := os.ReadFile(pathutils.ToMediaPath(testPath("extimg/cat.png"))); err != nil || !bytes.Equal(data, png) {
+		mismatches = append(mismatches, fmt.Sprintf("cached image: %v (%d bytes)", err, len(data)))
+	}
+	for _, notCached := range []string{"huge.png", "cat-1.png"} {
+		if _, err := os.Stat(pathutils.ToMediaPath(testPath(filepath.Join("extimg", notCached)))); !os.IsNotExist(err) {
+			mismatches = append(mismatches, notCached+" stored")
+		}
+	}
+	if media, err := files.MetaDataGet(mediaDir + "/cat.png"); err != nil || media == nil || len(media.References) != 1 || media.References[0].URL != remote.URL+"/img/cat.png" {
+		mismatches = append(mismatches, fmt.Sprintf("media metadata %+v", media))
+	}
+	if meta, err := files.MetaDataGet(on); err != nil || meta == nil || !slices.Contains(meta.UsedLinks, mediaDir+"/cat.png") {
+		mismatches = append(mismatches, "cached image not in the usedlinks")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("unchanged with the setting off, %q with it on and after saving again, the source url as media reference", want),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "external images were not cached and relinked as expected"
+	}
+	return cr
+}
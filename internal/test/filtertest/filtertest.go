@@ -32,8 +32,28 @@ func (Suite) Run() (*test.SuiteResult, error) {
 
 	result := &test.SuiteResult{Suite: "filter"}
 
+	var caseResults []test.CaseResult
 	for _, tc := range testConfigs {
-		caseResult := runCase(tc)
+		caseResults = append(caseResults, runCase(tc))
+	}
+	caseResults = append(caseResults, runTruncationCase())
+	caseResults = append(caseResults, runEmptyFilterCases()...)
+	caseResults = append(caseResults, runValidationCases()...)
+	caseResults = append(caseResults, runDisplayCases()...)
+	caseResults = append(caseResults, runTableColumnCases()...)
+	caseResults = append(caseResults, runPresetCase())
+	caseResults = append(caseResults, runPaginationCase())
+	caseResults = append(caseResults, runSortingCase())
+	caseResults = append(caseResults, runGroupByCase())
+	caseResults = append(caseResults, runCriteriaGroupsCase())
+	caseResults = append(caseResults, runUnderFolderCase())
+	caseResults = append(caseResults, runDefaultLogicCase())
+	caseResults = append(caseResults, runFilesByTagsCase())
+	caseResults = append(caseResults, runCountCase())
+	caseResults = append(caseResults, runQueryCase())
+	caseResults = append(caseResults, runSavedQueryCase())
+
+	for _, caseResult := range caseResults {
 		result.Cases = append(result.Cases, caseResult)
 		if caseResult.Success {
 			result.Passed++
@@ -43,7 +63,7 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		}
 	}
 
-	result.Total = len(testConfigs)
+	result.Total = len(caseResults)
 	result.Success = result.Failed == 0
 
 	if result.Failed > 0 {
@@ -10,6 +10,7 @@ import (
 	"knov/internal/contentStorage"
 	"knov/internal/files"
 	"knov/internal/logging"
+	"knov/internal/pathutils"
 )
 
 // createFilterTestFiles creates the physical test files on disk
@@ -160,3 +161,59 @@ func createFilterTestMetadata() error {
 
 	return nil
 }
+
+// Cases needing files the fixed sample set doesn't have seed them into a folder of their own
+// next to test/filter-tests, so the cases counting the files of that folder are not affected.
+const (
+	sortTestDir          = "test/filter-sort-tests"
+	groupTestDir         = "test/filter-group-tests"
+	criteriaGroupTestDir = "test/filter-criteria-group-tests"
+	underTestDir         = "test/filter-under-tests"
+	logicTestDir         = "test/filter-logic-tests"
+	byTagsTestDir        = "test/filter-bytags-tests"
+	countTestDir         = "test/filter-count-tests"
+	queryTestDir         = "test/filter-query-tests"
+	savedQueryTestDir    = "test/filter-saved-query-tests"
+)
+
+// caseFile is a sample file of a case folder, see createCaseFiles.
+type caseFile struct {
+	name    string
+	content string
+	tags    []string
+	custom  map[string]string
+	// createdAt pins the creation date, which the metadata save otherwise stamps
+	createdAt time.Time
+}
+
+// createCaseFiles wipes dir and seeds caseFiles into it with their metadata.
+func createCaseFiles(dir string, caseFiles []caseFile) error {
+	if err := os.RemoveAll(pathutils.ToDocsPath(dir)); err != nil {
+		return fmt.Errorf("failed to remove existing %s directory: %v", dir, err)
+	}
+	for _, file := range caseFiles {
+		relPath := filepath.ToSlash(filepath.Join(dir, file.name))
+		fullPath := pathutils.ToDocsPath(relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %v", relPath, err)
+		}
+		if err := contentStorage.WriteFile(fullPath, []byte(file.content), 0644); err != nil {
+			return fmt.Errorf("failed to create file %s: %v", relPath, err)
+		}
+		metadata := &files.Metadata{Path: pathutils.ToWithPrefix(relPath), Tags: file.tags, Custom: file.custom}
+		if err := files.MetaDataSave(metadata); err != nil {
+			return fmt.Errorf("failed to save metadata for %s: %v", relPath, err)
+		}
+		if !file.createdAt.IsZero() {
+			metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(relPath))
+			if err != nil || metadata == nil {
+				return fmt.Errorf("failed to get metadata for %s: %v", relPath, err)
+			}
+			metadata.CreatedAt = file.createdAt
+			if err := files.MetaDataSaveRaw(metadata); err != nil {
+				return fmt.Errorf("failed to save metadata for %s: %v", relPath, err)
+			}
+		}
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"slices"
+	"strconv"
 
+	"knov/internal/configmanager"
+	"knov/internal/files"
 	"knov/internal/filter"
 	"knov/internal/test"
 )
@@ -474,6 +477,179 @@ var testConfigs = []testConfig{
 		expectedCount: 1,
 		expectedFiles: []string{"filterTestE.md"},
 	},
+	{
+		// test1and as a single criteria group
+		name: "test22groupflat",
+		config: filter.Config{
+			CriteriaGroups: []filter.CriteriaGroup{
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+						{Metadata: "folders", Operator: "equals", Value: "filtertestfolder", Action: "include"},
+					},
+					Logic:  "and",
+					Action: "include",
+				},
+			},
+			Logic: "and",
+		},
+		expectedCount: 2,
+		expectedFiles: []string{"filterTestA.md", "filterTestB.md"},
+	},
+	{
+		// filter-tests and (unique or group2) and not (group and group2)
+		name: "test23groupandnot",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+			},
+			CriteriaGroups: []filter.CriteriaGroup{
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-unique", Action: "include"},
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group2", Action: "include"},
+					},
+					Logic:  "or",
+					Action: "include",
+				},
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group", Action: "include"},
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group2", Action: "include"},
+					},
+					Logic:  "and",
+					Action: "exclude",
+				},
+			},
+			Logic: "and",
+		},
+		expectedCount: 2,
+		expectedFiles: []string{"filterTestA.md", "filterTestD.md"},
+	},
+	{
+		// (filtertestfolder and group) or (filter-tests and references)
+		name: "test24groupsor",
+		config: filter.Config{
+			CriteriaGroups: []filter.CriteriaGroup{
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "folders", Operator: "equals", Value: "filtertestfolder", Action: "include"},
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group", Action: "include"},
+					},
+					Logic:  "and",
+					Action: "include",
+				},
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+						{Metadata: "references", Operator: "contains", Value: "example reference", Action: "include"},
+					},
+					Logic:  "and",
+					Action: "include",
+				},
+			},
+			Logic: "or",
+		},
+		expectedCount: 2,
+		expectedFiles: []string{"filterTestB.md", "filterTestE.md"},
+	},
+	{
+		// filter-tests and not (group or group2)
+		name: "test25groupexclude",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+			},
+			CriteriaGroups: []filter.CriteriaGroup{
+				{
+					Criteria: []filter.Criteria{
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group", Action: "include"},
+						{Metadata: "tags", Operator: "equals", Value: "filtertest-group2", Action: "include"},
+					},
+					Logic:  "or",
+					Action: "exclude",
+				},
+			},
+			Logic: "and",
+		},
+		expectedCount: 3,
+		expectedFiles: []string{"filterTestA.md", "filterTestE.md", "filterTestF.md"},
+	},
+	{
+		name: "test26folderunder",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{
+					Metadata: "folders",
+					Operator: "under",
+					Value:    "test/filter-tests/filtertestfolder",
+					Action:   "include",
+				},
+			},
+			Logic: "and",
+			Limit: 0,
+		},
+		expectedCount: 2,
+		expectedFiles: []string{"filterTestA.md", "filterTestB.md"},
+	},
+	{
+		name: "test36tagregex",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: `group2$`, Action: "include"},
+			},
+			Logic: "and",
+		},
+		expectedCount: 2,
+		expectedFiles: []string{"filterTestC.md", "filterTestD.md"},
+	},
+	{
+		name: "test37tagregexanchored",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: `^group`, Action: "include"},
+			},
+			Logic: "and",
+		},
+		expectedCount: 0,
+	},
+	{
+		name: "test38tagregexcase",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: `FILTERTEST-UNIQUE`, Action: "include"},
+			},
+			Logic: "and",
+		},
+		expectedCount: 0,
+	},
+	{
+		name: "test39tagregexignorecase",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: `(?i)^FILTERTEST-UNIQUE$`, Action: "include"},
+			},
+			Logic: "and",
+		},
+		expectedCount: 1,
+		expectedFiles: []string{"filterTestA.md"},
+	},
+	{
+		name: "test40tagregexalternation",
+		config: filter.Config{
+			Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: `-(unique|group)$`, Action: "include"},
+			},
+			Logic: "and",
+		},
+		expectedCount: 3,
+		expectedFiles: []string{"filterTestA.md", "filterTestB.md", "filterTestC.md"},
+	},
 }
 
 // runCase executes a single scenario against the real filter engine and compares the
@@ -526,3 +702,102 @@ func runCase(tc testConfig) test.CaseResult {
 
 	return caseResult
 }
+
+// runTruncationCase lowers the filter result cap and checks that a filter matching more
+// files than the cap is cut to the cap and flagged as truncated, while one below it is not.
+func runTruncationCase() test.CaseResult {
+	const name = "test19resultcap"
+	const resultCap = 2
+	expected := fmt.Sprintf("unfiltered: %d files, truncated; references: not truncated", resultCap)
+
+	previous := configmanager.GetFilterResultCap()
+	if err := configmanager.FilterResultCap.SetFromString(strconv.Itoa(resultCap)); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	defer configmanager.FilterResultCap.SetFromString(strconv.Itoa(previous)) //nolint:errcheck // restoring a previously valid value
+
+	all, err := filter.FilterFilesWithConfig(&filter.Config{Logic: "and"})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	narrow, err := filter.FilterFilesWithConfig(&filter.Config{
+		Criteria: []filter.Criteria{
+			{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"},
+			{Metadata: "references", Operator: "contains", Value: "example reference", Action: "include"},
+		},
+		Logic: "and",
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	actual := fmt.Sprintf("unfiltered: %d of %d files, truncated=%t; references: %d of %d files, truncated=%t",
+		len(all.Files), all.Total, all.Truncated, len(narrow.Files), narrow.Total, narrow.Truncated)
+	caseResult := test.CaseResult{Name: name, Expected: expected, Actual: actual}
+
+	switch {
+	case all.Total <= resultCap:
+		caseResult.Error = fmt.Sprintf("need more than %d files to test the cap, got %d", resultCap, all.Total)
+	case len(all.Files) != resultCap || !all.Truncated:
+		caseResult.Error = "results above the cap were not cut and flagged as truncated"
+	case narrow.Truncated || len(narrow.Files) != narrow.Total:
+		caseResult.Error = "results below the cap were flagged as truncated"
+	default:
+		caseResult.Success = true
+	}
+	return caseResult
+}
+
+// runEmptyFilterCases checks the "no filter" semantics documented on filter.Config against
+// the full vault: an empty filter returns every visible file, an exclude-only filter every
+// visible file except the excluded ones.
+func runEmptyFilterCases() []test.CaseResult {
+	allFiles, err := files.GetAllFilesCached()
+	if err != nil {
+		return []test.CaseResult{{Name: "test20emptyfilter", Actual: "error", Error: err.Error()}}
+	}
+	vault := make([]string, 0, len(allFiles))
+	for _, file := range files.FilterByVisibility(allFiles) {
+		vault = append(vault, filepath.Base(file.Path))
+	}
+
+	excluded := []string{"filterTestA.md", "filterTestB.md"}
+	allButExcluded := slices.DeleteFunc(slices.Clone(vault), func(name string) bool { return slices.Contains(excluded, name) })
+
+	return []test.CaseResult{
+		runVaultCase("test20emptyfilter", filter.Config{Logic: "and"}, vault),
+		runVaultCase("test21excludeonly", filter.Config{
+			Criteria: []filter.Criteria{{Metadata: "folders", Operator: "equals", Value: "filtertestfolder", Action: "exclude"}},
+			Logic:    "and",
+		}, allButExcluded),
+	}
+}
+
+// runVaultCase runs config and expects exactly the expected file basenames, in any order.
+func runVaultCase(name string, config filter.Config, expected []string) test.CaseResult {
+	expectedStr := fmt.Sprintf("%d files", len(expected))
+
+	result, err := filter.FilterFilesWithConfig(&config)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expectedStr, Actual: "error", Error: err.Error(), Detail: config}
+	}
+
+	actual := make([]string, len(result.Files))
+	for i, file := range result.Files {
+		actual[i] = filepath.Base(file.Path)
+	}
+	slices.Sort(actual)
+	want := slices.Sorted(slices.Values(expected))
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expectedStr,
+		Actual:   fmt.Sprintf("%d files", len(actual)),
+		Success:  result.Total == len(want) && slices.Equal(actual, want),
+		Detail:   config,
+	}
+	if !caseResult.Success {
+		caseResult.Error = fmt.Sprintf("expected %v, got %v (total %d)", want, actual, result.Total)
+	}
+	return caseResult
+}
@@ -0,0 +1,99 @@
+package filtertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// filesByTags mirrors handleAPIGetFilesByTags: one include criterion per comma-separated
+// tag, the filterDefaultLogic setting when logic is empty. Returns the sorted file names and
+// whether the request would have been accepted.
+func filesByTags(tags, logic string) ([]string, bool, error) {
+	var criteria []filter.Criteria
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			criteria = append(criteria, filter.Criteria{Metadata: "tags", Operator: "contains", Value: tag, Action: "include"})
+		}
+	}
+	if logic == "" {
+		logic = configmanager.GetFilterDefaultLogic()
+	}
+	if len(criteria) == 0 || !slices.Contains(filter.GetLogics(), logic) {
+		return nil, false, nil
+	}
+
+	matched, err := filter.FilterFiles(criteria, logic)
+	if err != nil {
+		return nil, true, err
+	}
+	var names []string
+	for _, file := range matched {
+		names = append(names, filepath.Base(file.Path))
+	}
+	slices.Sort(names)
+	return names, true, nil
+}
+
+// runFilesByTagsCase covers GET /api/metadata/bytags: files carrying all or any of the
+// tags, the default logic without a logic parameter, and no result for an unknown logic or
+// an empty tag list.
+func runFilesByTagsCase() test.CaseResult {
+	const name = "test48bytags"
+	expected := "and [both.md], or [blue.md both.md red.md], default and [both.md], xor and empty tags rejected"
+
+	err := createCaseFiles(byTagsTestDir, []caseFile{
+		{name: "both.md", content: "# note\n", tags: []string{"filtertest-bytags-red", "filtertest-bytags-blue"}},
+		{name: "red.md", content: "# note\n", tags: []string{"filtertest-bytags-red"}},
+		{name: "blue.md", content: "# note\n", tags: []string{"filtertest-bytags-blue", "filtertest-bytags-other"}},
+		{name: "other.md", content: "# note\n", tags: []string{"filtertest-bytags-other"}},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	previous := configmanager.GetFilterDefaultLogic()
+	defer func() {
+		configmanager.FilterDefaultLogic.SetFromString(previous) //nolint:errcheck // restoring a previously valid value
+		configmanager.SaveSettings()                             //nolint:errcheck
+	}()
+	if err := configmanager.FilterDefaultLogic.SetFromString("and"); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	var mismatches []string
+	for _, tc := range []struct {
+		tags, logic string
+		want        []string
+		accepted    bool
+	}{
+		{"filtertest-bytags-red,filtertest-bytags-blue", "and", []string{"both.md"}, true},
+		{"filtertest-bytags-red, filtertest-bytags-blue", "or", []string{"blue.md", "both.md", "red.md"}, true},
+		{"filtertest-bytags-red,filtertest-bytags-blue", "", []string{"both.md"}, true},
+		{"filtertest-bytags-red", "xor", nil, false},
+		{",", "and", nil, false},
+	} {
+		got, accepted, err := filesByTags(tc.tags, tc.logic)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		if accepted != tc.accepted || !slices.Equal(got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("tags %q logic %q: accepted=%v %v", tc.tags, tc.logic, accepted, got))
+		}
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "files were not matched by their tags as expected"
+	}
+	return caseResult
+}
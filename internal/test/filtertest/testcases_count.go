@@ -0,0 +1,69 @@
+package filtertest
+
+import (
+	"fmt"
+
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// runCountCase covers filter.CountFilesWithConfig, which the count display of filter widgets
+// shows: it ignores limit and offset, applies exclude criteria and criteria groups like
+// FilterFilesWithConfig and matches its Total, and rejects a nil config or an invalid regex.
+func runCountCase() test.CaseResult {
+	const name = "test49count"
+	expected := "folder 4, limit ignored 4, include and exclude 2, exclude group 1, each equal to the filter total; nil config and invalid regex rejected"
+
+	err := createCaseFiles(countTestDir, []caseFile{
+		{name: "note0.md", content: "# note\n", tags: []string{"filtertest-counted"}},
+		{name: "note1.md", content: "# note\n", tags: []string{"filtertest-counted"}},
+		{name: "note2.md", content: "# note\n", tags: []string{"filtertest-counted", "filtertest-skip"}},
+		{name: "note3.md", content: "# note\n"},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	folder := filter.Criteria{Metadata: "folders", Operator: "equals", Value: "filter-count-tests", Action: "include"}
+	counted := filter.Criteria{Metadata: "tags", Operator: "equals", Value: "filtertest-counted", Action: "include"}
+	var mismatches []string
+	for _, tc := range []struct {
+		name   string
+		config filter.Config
+		want   int
+	}{
+		{"folder", filter.Config{Criteria: []filter.Criteria{folder}, Logic: "and"}, 4},
+		{"limit ignored", filter.Config{Criteria: []filter.Criteria{folder}, Logic: "and", Limit: 1, Offset: 1}, 4},
+		{"include and exclude", filter.Config{Criteria: []filter.Criteria{folder, counted, {Metadata: "tags", Operator: "equals", Value: "filtertest-skip", Action: "exclude"}}, Logic: "and"}, 2},
+		{"exclude group", filter.Config{Criteria: []filter.Criteria{folder}, CriteriaGroups: []filter.CriteriaGroup{{Criteria: []filter.Criteria{counted}, Logic: "and", Action: "exclude"}}, Logic: "and"}, 1},
+	} {
+		count, err := filter.CountFilesWithConfig(&tc.config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		result, err := filter.FilterFilesWithConfig(&tc.config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		if count != tc.want || count != result.Total {
+			mismatches = append(mismatches, fmt.Sprintf("%s: count %d total %d", tc.name, count, result.Total))
+		}
+	}
+	if _, err := filter.CountFilesWithConfig(nil); err == nil {
+		mismatches = append(mismatches, "nil config accepted")
+	}
+	if _, err := filter.CountFilesWithConfig(&filter.Config{Criteria: []filter.Criteria{{Metadata: "name", Operator: "regex", Value: "(", Action: "include"}}}); err == nil {
+		mismatches = append(mismatches, "invalid regex accepted")
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "the filter count did not match the filtered files"
+	}
+	return caseResult
+}
@@ -0,0 +1,154 @@
+package filtertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// parseFilterForm parses form the way handleAPIFilterFiles does, from an in-memory request.
+func parseFilterForm(form url.Values) (*filter.Config, error) {
+	req, err := http.NewRequest(http.MethodPost, "/api/filters", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return filter.ParseFilterConfigFromForm(req, -1), nil
+}
+
+// folderForm returns a filter form including the files of filtertestfolder (A and B).
+func folderForm(extra url.Values) url.Values {
+	form := url.Values{
+		"logic":       {"and"},
+		"metadata[0]": {"folders"}, "operator[0]": {"equals"}, "value[0]": {"filtertestfolder"}, "action[0]": {"include"},
+	}
+	for key, values := range extra {
+		form[key] = values
+	}
+	return form
+}
+
+// runDisplayCases checks the display mode of filter forms: every mode parses and validates
+// without changing the matched files, a form without one defaults to the list, and an
+// unknown mode is rejected before filtering.
+func runDisplayCases() []test.CaseResult {
+	modes := append(filter.GetDisplayModes(), "")
+	var mismatches []string
+	for _, mode := range modes {
+		config, err := parseFilterForm(folderForm(url.Values{"display": {mode}}))
+		if err != nil {
+			return []test.CaseResult{{Name: "test31displaymodes", Actual: "error", Error: err.Error()}}
+		}
+		want := mode
+		if want == "" {
+			want = "list"
+		}
+		if config.Display != want {
+			mismatches = append(mismatches, fmt.Sprintf("%q parsed as %q", mode, config.Display))
+			continue
+		}
+		if err := filter.ValidateConfig(config); err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", want, err))
+			continue
+		}
+		result, err := filter.FilterFilesWithConfig(config)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", want, err))
+		} else if result.Total != 2 || len(result.Files) != 2 {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %d of %d files", want, len(result.Files), result.Total))
+		}
+	}
+	modesCase := test.CaseResult{
+		Name:     "test31displaymodes",
+		Expected: fmt.Sprintf("%v parsed, valid and matching 2 files, no mode parsed as list", filter.GetDisplayModes()),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !modesCase.Success {
+		modesCase.Error = "display modes don't parse or validate"
+	}
+
+	unknownCase := test.CaseResult{Name: "test32unknowndisplay", Expected: "invalid display mode: gallery"}
+	config, err := parseFilterForm(folderForm(url.Values{"display": {"gallery"}}))
+	if err == nil {
+		err = filter.ValidateConfig(config)
+	}
+	if err != nil {
+		unknownCase.Actual = err.Error()
+	}
+	unknownCase.Success = unknownCase.Actual == unknownCase.Expected
+	if !unknownCase.Success {
+		unknownCase.Error = "an unknown display mode was not rejected"
+	}
+	return []test.CaseResult{modesCase, unknownCase}
+}
+
+// runTableColumnCases checks the columns of the table display mode: the filterTableColumns
+// setting without unknown fields, columns of the form in their order, the plain cell values
+// of filterTestA, and the rejection of an unknown column.
+func runTableColumnCases() []test.CaseResult {
+	const name = "test33tablecolumns"
+	previous := strings.Join(configmanager.FilterTableColumns.Get(), ",")
+	if err := configmanager.FilterTableColumns.SetFromString("title,tags,unknown"); err != nil {
+		return []test.CaseResult{{Name: name, Actual: "error", Error: err.Error()}}
+	}
+	defaults := filter.TableColumns(nil)
+	configmanager.FilterTableColumns.SetFromString(previous) //nolint:errcheck // restoring a previously valid value
+
+	config, err := parseFilterForm(folderForm(url.Values{"display": {"table"}, "columns": {"path, createdAt, tags"}}))
+	if err != nil {
+		return []test.CaseResult{{Name: name, Actual: "error", Error: err.Error()}}
+	}
+	columns := filter.TableColumns(config.Columns)
+	result, err := filter.FilterFilesWithConfig(config)
+	if err != nil {
+		return []test.CaseResult{{Name: name, Actual: "error", Error: err.Error()}}
+	}
+	var cells []string
+	for _, file := range result.Files {
+		if filepath.Base(file.Path) == "filterTestA.md" {
+			for _, column := range columns {
+				cells = append(cells, filter.TableCellValue(file, column))
+			}
+		}
+	}
+
+	wantCells := []string{
+		"test/filter-tests/filtertestfolder/filterTestA.md",
+		configmanager.FormatDateTime(time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)),
+		"filtertest-unique",
+	}
+	columnsCase := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("setting [title tags], form [path createdAt tags], cells %q", wantCells),
+		Actual:   fmt.Sprintf("setting %v, form %v, cells %q", defaults, columns, cells),
+		Detail:   config,
+	}
+	columnsCase.Success = slices.Equal(defaults, []string{"title", "tags"}) &&
+		slices.Equal(columns, []string{"path", "createdAt", "tags"}) && slices.Equal(cells, wantCells)
+	if !columnsCase.Success {
+		columnsCase.Error = "table columns or cell values differ"
+	}
+
+	unknownCase := test.CaseResult{Name: "test34unknowncolumn", Expected: "invalid table column: secret"}
+	config, err = parseFilterForm(folderForm(url.Values{"display": {"table"}, "columns": {"title,secret"}}))
+	if err == nil {
+		err = filter.ValidateConfig(config)
+	}
+	if err != nil {
+		unknownCase.Actual = err.Error()
+	}
+	unknownCase.Success = unknownCase.Actual == unknownCase.Expected
+	if !unknownCase.Success {
+		unknownCase.Error = "an unknown table column was not rejected"
+	}
+	return []test.CaseResult{columnsCase, unknownCase}
+}
@@ -0,0 +1,161 @@
+package filtertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"knov/internal/configmanager"
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// runGroupByCase groups four files by a custom field, their tags and their kanban status.
+// Files without a value land in a trailing group with an empty value, status groups follow
+// the order of the kanban statuses and grouping by an unsupported field fails validation.
+func runGroupByCase() test.CaseResult {
+	const name = "test44groupby"
+	blocked, inbox := configmanager.KanbanStatusTag("blocked"), configmanager.KanbanStatusTag("inbox")
+	want := map[string][]string{
+		"custom.owner": {"alice=2", "bob=1", "=1"},
+		"tags":         {blocked + "=2", inbox + "=1", "x=2", "y=1", "=1"},
+		"status":       {"inbox=1", "blocked=2", "=1"},
+	}
+	expected := fmt.Sprintf("groups %v, grouping by size rejected", want)
+
+	statuses := configmanager.GetKanbanStatuses()
+	if i := slices.Index(statuses, "inbox"); i < 0 || i > slices.Index(statuses, "blocked") {
+		return test.CaseResult{Name: name, Expected: expected, Actual: fmt.Sprintf("kanban statuses %v", statuses), Error: "the case expects inbox before blocked in the kanban statuses"}
+	}
+	err := createCaseFiles(groupTestDir, []caseFile{
+		{name: "one.md", content: "# note\n", tags: []string{blocked, "x"}, custom: map[string]string{"owner": "bob"}},
+		{name: "two.md", content: "# note\n", tags: []string{inbox, "x", "y"}, custom: map[string]string{"owner": "alice"}},
+		{name: "three.md", content: "# note\n", tags: []string{blocked}, custom: map[string]string{"owner": "alice"}},
+		{name: "four.md", content: "# note\n"},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	criteria := []filter.Criteria{{Metadata: "folders", Operator: "equals", Value: "filter-group-tests", Action: "include"}}
+	var mismatches []string
+	for _, groupBy := range []string{"custom.owner", "tags", "status"} {
+		config := &filter.Config{Criteria: criteria, Logic: "and", GroupBy: groupBy}
+		if err := filter.ValidateConfig(config); err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: config}
+		}
+		result, err := filter.FilterFilesWithConfig(config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: config}
+		}
+		var counts []string
+		for _, group := range result.Groups {
+			counts = append(counts, fmt.Sprintf("%s=%d", group.Value, len(group.Files)))
+		}
+		if !slices.Equal(counts, want[groupBy]) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", groupBy, counts))
+		}
+	}
+	if filter.ValidateConfig(&filter.Config{Logic: "and", GroupBy: "size"}) == nil {
+		mismatches = append(mismatches, "grouping by size accepted")
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "files are not grouped as expected or an unsupported field was accepted"
+	}
+	return caseResult
+}
+
+// runCriteriaGroupsCase combines criteria groups: an include and an exclude group for
+// "alpha or beta, but not both", an exclude group keeping untagged files, and groups joined
+// by or. A group with an invalid logic and an empty group fail validation.
+func runCriteriaGroupsCase() test.CaseResult {
+	const name = "test45criteriagroups"
+	expected := "[a.md b.md], [b.md d.md], [a.md b.md], invalid group logic and empty group rejected"
+
+	err := createCaseFiles(criteriaGroupTestDir, []caseFile{
+		{name: "a.md", content: "# note\n", tags: []string{"alpha"}},
+		{name: "b.md", content: "# note\n", tags: []string{"beta"}},
+		{name: "c.md", content: "# note\n", tags: []string{"alpha", "beta"}},
+		{name: "d.md", content: "# note\n"},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	folder := filter.Criteria{Metadata: "folders", Operator: "equals", Value: "filter-criteria-group-tests", Action: "include"}
+	alpha := filter.Criteria{Metadata: "tags", Operator: "equals", Value: "alpha", Action: "include"}
+	beta := filter.Criteria{Metadata: "tags", Operator: "equals", Value: "beta", Action: "include"}
+	notAlpha := filter.Criteria{Metadata: "tags", Operator: "equals", Value: "alpha", Action: "exclude"}
+	notBeta := filter.Criteria{Metadata: "tags", Operator: "equals", Value: "beta", Action: "exclude"}
+
+	var mismatches []string
+	for _, tc := range []struct {
+		label  string
+		config filter.Config
+		want   []string
+	}{
+		{"alpha or beta, but not both", filter.Config{
+			Criteria: []filter.Criteria{folder},
+			CriteriaGroups: []filter.CriteriaGroup{
+				{Criteria: []filter.Criteria{alpha, beta}, Logic: "or", Action: "include"},
+				{Criteria: []filter.Criteria{alpha, beta}, Logic: "and", Action: "exclude"},
+			},
+			Logic: "and",
+		}, []string{"a.md", "b.md"}},
+		{"exclude group keeps untagged files", filter.Config{
+			Criteria:       []filter.Criteria{folder},
+			CriteriaGroups: []filter.CriteriaGroup{{Criteria: []filter.Criteria{alpha}, Logic: "and", Action: "exclude"}},
+			Logic:          "and",
+		}, []string{"b.md", "d.md"}},
+		{"groups joined by or", filter.Config{
+			CriteriaGroups: []filter.CriteriaGroup{
+				{Criteria: []filter.Criteria{folder, alpha, notBeta}, Logic: "and"},
+				{Criteria: []filter.Criteria{folder, beta, notAlpha}, Logic: "and"},
+			},
+			Logic: "or",
+		}, []string{"a.md", "b.md"}},
+	} {
+		if err := filter.ValidateConfig(&tc.config); err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: tc.config}
+		}
+		result, err := filter.FilterFilesWithConfig(&tc.config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: tc.config}
+		}
+		var got []string
+		for _, file := range result.Files {
+			got = append(got, filepath.Base(file.Path))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", tc.label, got))
+		}
+	}
+
+	invalid := filter.Config{Logic: "and", CriteriaGroups: []filter.CriteriaGroup{{Criteria: []filter.Criteria{alpha}, Logic: "xor"}}}
+	if filter.ValidateConfig(&invalid) == nil {
+		mismatches = append(mismatches, "group logic xor accepted")
+	}
+	invalid.CriteriaGroups = []filter.CriteriaGroup{{Logic: "and"}}
+	if filter.ValidateConfig(&invalid) == nil {
+		mismatches = append(mismatches, "empty group accepted")
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "criteria groups did not combine as expected or an invalid group was accepted"
+	}
+	return caseResult
+}
@@ -0,0 +1,93 @@
+package filtertest
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+
+	"knov/internal/configmanager"
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// runDefaultLogicCase parses a filter form with two tag criteria and no logic field, like
+// handleAPIFilterFiles: the filterDefaultLogic setting decides whether files need both tags
+// or either. The setting only takes the logics the filter editor offers.
+func runDefaultLogicCase() test.CaseResult {
+	const name = "test47defaultlogic"
+	expected := "and [both.md], or [blue.md both.md red.md], logics [and or], xor rejected"
+
+	err := createCaseFiles(logicTestDir, []caseFile{
+		{name: "red.md", content: "# note\n", tags: []string{"filtertest-logic-red"}},
+		{name: "blue.md", content: "# note\n", tags: []string{"filtertest-logic-blue"}},
+		{name: "both.md", content: "# note\n", tags: []string{"filtertest-logic-red", "filtertest-logic-blue"}},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	previous := configmanager.GetFilterDefaultLogic()
+	defer func() {
+		configmanager.FilterDefaultLogic.SetFromString(previous) //nolint:errcheck // restoring a previously valid value
+		configmanager.SaveSettings()                             //nolint:errcheck
+	}()
+
+	matched := func(logic string) ([]string, error) {
+		if err := configmanager.FilterDefaultLogic.SetFromString(logic); err != nil {
+			return nil, err
+		}
+		config, err := parseFilterForm(url.Values{
+			"metadata[]": {"tags", "tags"},
+			"operator[]": {"equals", "equals"},
+			"value[]":    {"filtertest-logic-red", "filtertest-logic-blue"},
+			"action[]":   {"include", "include"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		result, err := filter.FilterFilesWithConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, file := range result.Files {
+			names = append(names, filepath.Base(file.Path))
+		}
+		slices.Sort(names)
+		return names, nil
+	}
+
+	var mismatches []string
+	for _, tc := range []struct {
+		logic string
+		want  []string
+	}{
+		{"and", []string{"both.md"}},
+		{"or", []string{"blue.md", "both.md", "red.md"}},
+	} {
+		got, err := matched(tc.logic)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		if !slices.Equal(got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("default %s: %v", tc.logic, got))
+		}
+	}
+	if logics := filter.GetLogics(); !slices.Equal(logics, []string{"and", "or"}) {
+		mismatches = append(mismatches, fmt.Sprintf("logics %v", logics))
+	}
+	if configmanager.FilterDefaultLogic.SetFromString("xor") == nil {
+		mismatches = append(mismatches, "default logic xor accepted")
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "a form without logic did not use the configured default logic"
+	}
+	return caseResult
+}
@@ -0,0 +1,74 @@
+package filtertest
+
+import (
+	"fmt"
+	"net/url"
+
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// runPaginationCase pages through the six files of the filter-tests folder with offset and
+// limit: every page has to be the matching slice of the unpaginated result, with the total
+// of all matches. A negative offset starts at the first file, an offset past the end returns
+// no files. The last page is also requested through a filter form like handleAPIFilterFiles.
+func runPaginationCase() test.CaseResult {
+	const name = "test42pagination"
+	criteria := []filter.Criteria{{Metadata: "folders", Operator: "equals", Value: "filter-tests", Action: "include"}}
+	expected := "total 6 on every page, pages are slices of the unpaginated result"
+
+	all, err := filter.FilterFilesWithConfig(&filter.Config{Criteria: criteria, Logic: "and"})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	if len(all.Files) != 6 {
+		return test.CaseResult{Name: name, Expected: expected, Actual: fmt.Sprintf("%d unpaginated files", len(all.Files)), Error: "expected 6 unpaginated files"}
+	}
+
+	form, err := parseFilterForm(url.Values{
+		"logic": {"and"}, "limit": {"2"}, "offset": {"5"},
+		"metadata[0]": {"folders"}, "operator[0]": {"equals"}, "value[0]": {"filter-tests"}, "action[0]": {"include"},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	pages := []struct {
+		config            *filter.Config
+		wantOffset, count int
+	}{
+		{&filter.Config{Criteria: criteria, Logic: "and", Offset: 1, Limit: 2}, 1, 2},
+		{&filter.Config{Criteria: criteria, Logic: "and", Offset: 4}, 4, 2},
+		{&filter.Config{Criteria: criteria, Logic: "and", Offset: -3, Limit: 2}, 0, 2},
+		{&filter.Config{Criteria: criteria, Logic: "and", Offset: 10, Limit: 2}, 10, 0},
+		{form, 5, 1},
+	}
+	var mismatches []string
+	for _, page := range pages {
+		result, err := filter.FilterFilesWithConfig(page.config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: page.config}
+		}
+		label := fmt.Sprintf("offset %d limit %d", page.config.Offset, page.config.Limit)
+		if result.Total != 6 || result.Offset != page.wantOffset || len(result.Files) != page.count {
+			mismatches = append(mismatches, fmt.Sprintf("%s: total %d, offset %d, %d files", label, result.Total, result.Offset, len(result.Files)))
+			continue
+		}
+		for i, file := range result.Files {
+			if want := all.Files[page.wantOffset+i].Path; file.Path != want {
+				mismatches = append(mismatches, fmt.Sprintf("%s: file %d is %s, not %s", label, i, file.Path, want))
+			}
+		}
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "pages don't match the unpaginated result"
+	}
+	return caseResult
+}
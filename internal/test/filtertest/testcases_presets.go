@@ -0,0 +1,68 @@
+package filtertest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// presetName is the quick filter preset the preset case saves and deletes again. Presets
+// live in configStorage, so a leftover from an aborted run is removed first.
+const presetName = "filtertest-preset"
+
+// runPresetCase saves a preset from a filter form like handleAPISaveFilterPreset, then lists,
+// runs and deletes it. A deleted preset is not found anymore.
+func runPresetCase() test.CaseResult {
+	const name = "test35presets"
+	expected := "listed, runs to [filterTestA.md], not found after deleting"
+	filter.DeletePreset(presetName)       //nolint:errcheck // only a leftover of an aborted run
+	defer filter.DeletePreset(presetName) //nolint:errcheck
+
+	config, err := parseFilterForm(folderForm(url.Values{
+		"metadata[1]": {"tags"}, "operator[1]": {"contains"}, "value[1]": {"filtertest-unique"}, "action[1]": {"include"},
+	}))
+	if err == nil {
+		err = filter.SavePreset(presetName, config)
+	}
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	names, err := filter.ListPresets()
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	saved, err := filter.GetPreset(presetName)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	result, err := filter.FilterFilesWithConfig(saved)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: saved}
+	}
+	var matched []string
+	for _, file := range result.Files {
+		matched = append(matched, filepath.Base(file.Path))
+	}
+
+	deleteErr := filter.DeletePreset(presetName)
+	_, getErr := filter.GetPreset(presetName)
+
+	listed := slices.Contains(names, presetName)
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("listed=%t, runs to %v, delete error %v, load after deleting %v", listed, matched, deleteErr, getErr),
+		Success:  listed && slices.Equal(matched, []string{"filterTestA.md"}) && deleteErr == nil && errors.Is(getErr, filter.ErrPresetNotFound),
+		Detail:   saved,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "the preset was not saved, run or deleted as expected"
+	}
+	return caseResult
+}
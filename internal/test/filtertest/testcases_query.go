@@ -0,0 +1,201 @@
+package filtertest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// runQueryCase covers filter.ParseQueryString, which GET /api/files/query runs before
+// FilterFilesWithConfig: queries parse into the expected criteria, logic and display options,
+// malformed ones are rejected, and parsed queries find the expected files. Matches outside the
+// case folder are ignored, since the queries without under: search the whole vault.
+func runQueryCase() test.CaseResult {
+	const name = "test50query"
+	expected := "3 queries parsed into their configs, 9 malformed ones rejected, 4 queries finding their files"
+
+	archive, inprogress := configmanager.KanbanStatusTag("archive"), configmanager.KanbanStatusTag("inprogress")
+	err := createCaseFiles(queryTestDir, []caseFile{
+		{name: "ml.md", content: "# note\n", tags: []string{"ml", archive}, custom: map[string]string{"type": "literature"}, createdAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "old.md", content: "# note\n", tags: []string{"ml", archive}, custom: map[string]string{"type": "literature"}, createdAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "draft.md", content: "# note\n", tags: []string{"ml", inprogress}, custom: map[string]string{"type": "literature"}, createdAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "sub/idea.md", content: "# note\n", tags: []string{"idea"}, custom: map[string]string{"type": "note"}, createdAt: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	var mismatches []string
+	for _, tc := range []struct {
+		query string
+		want  filter.Config
+	}{
+		{
+			query: "type:literature status:archive tag:#ml created>2025-01-01",
+			want: filter.Config{Logic: "and", Display: "list", Limit: 50, Criteria: []filter.Criteria{
+				{Metadata: "custom.type", Operator: "equals", Value: "literature", Action: "include"},
+				{Metadata: "tags", Operator: "equals", Value: archive, Action: "include"},
+				{Metadata: "tags", Operator: "equals", Value: "ml", Action: "include"},
+				{Metadata: "createdAt", Operator: "greater", Value: "2025-01-01", Action: "include"},
+			}},
+		},
+		{
+			query: `under:querytest -folder:querytest/sub title~"my note" or tag:/^i/ tag:a,#b`,
+			want: filter.Config{Logic: "or", Display: "list", Limit: 50, Criteria: []filter.Criteria{
+				{Metadata: "folders", Operator: "under", Value: "querytest", Action: "include"},
+				{Metadata: "folders", Operator: "equals", Value: "querytest/sub", Action: "exclude"},
+				{Metadata: "title", Operator: "contains", Value: "my note", Action: "include"},
+				{Metadata: "tags", Operator: "regex", Value: "^i", Action: "include"},
+				{Metadata: "tags", Operator: "in", Value: "a,b", Action: "include"},
+			}},
+		},
+		{
+			query: "words<100 idea sort:title order:desc limit:5 offset:2 display:cards group:custom.type",
+			want: filter.Config{Logic: "and", Display: "cards", Limit: 5, Offset: 2, SortBy: "title", SortOrder: "desc", GroupBy: "custom.type", Criteria: []filter.Criteria{
+				{Metadata: "wordCount", Operator: "less", Value: "100", Action: "include"},
+				{Metadata: "title", Operator: "contains", Value: "idea", Action: "include"},
+			}},
+		},
+	} {
+		got, err := filter.ParseQueryString(tc.query)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("parse %q: %v", tc.query, err))
+		} else if !reflect.DeepEqual(*got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("parse %q: %+v", tc.query, *got))
+		}
+	}
+	for _, query := range []string{"created>yesterday", "words:/x/", "words:many", `title:"open`, "limit:-1", "-sort:title", "tag:a and tag:b or tag:c", "tag:", "created>someday"} {
+		if _, err := filter.ParseQueryString(query); err == nil {
+			mismatches = append(mismatches, fmt.Sprintf("%q accepted", query))
+		}
+	}
+
+	for query, want := range map[string][]string{
+		"type:literature status:archive tag:#ml created>2025-01-01":                {"ml.md"},
+		"under:" + queryTestDir + " -status:archive":                               {"draft.md", "sub/idea.md"},
+		"type:note or status:inprogress":                                           {"draft.md", "sub/idea.md"},
+		"under:" + queryTestDir + " status:archive,inprogress -created<2025-01-01": {"draft.md", "ml.md"},
+	} {
+		config, err := filter.ParseQueryString(query)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("parse %q: %v", query, err))
+			continue
+		}
+		result, err := filter.FilterFilesWithConfig(config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		var got []string
+		for _, f := range result.Files {
+			if rest, ok := strings.CutPrefix(pathutils.ToRelative(f.Path), queryTestDir+"/"); ok {
+				got = append(got, rest)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			mismatches = append(mismatches, fmt.Sprintf("query %q: %v", query, got))
+		}
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "text queries were not parsed or matched as expected"
+	}
+	return caseResult
+}
+
+// runSavedQueryCase covers the saved queries of /api/filters/queries and the query widget:
+// filter.SaveQuery rejects an invalid query, ListQueries lists the saved one, running it by
+// name like GET /api/files/query?name= finds its files, and DeleteQuery removes it. The
+// query name is prefixed so it never replaces a query of the vault.
+func runSavedQueryCase() test.CaseResult {
+	const name = "test51savedquery"
+	const queryName = "filtertest-literature"
+	expected := "invalid query rejected, the saved query listed and matching paper.md and book.md, not found after deleting"
+
+	err := createCaseFiles(savedQueryTestDir, []caseFile{
+		{name: "paper.md", content: "# note\n", custom: map[string]string{"type": "literature"}},
+		{name: "book.md", content: "# note\n", custom: map[string]string{"type": "literature"}},
+		{name: "idea.md", content: "# note\n", custom: map[string]string{"type": "idea"}},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	var mismatches []string
+	query := "type:literature under:" + savedQueryTestDir
+	if err := filter.SaveQuery(queryName, query); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	if err := filter.SaveQuery("filtertest-broken", "words>many"); err == nil {
+		mismatches = append(mismatches, "invalid query saved")
+		filter.DeleteQuery("filtertest-broken") //nolint:errcheck
+	}
+
+	queries, err := filter.ListQueries()
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	if !slices.Contains(queries, filter.SavedQuery{Name: queryName, Query: query}) {
+		mismatches = append(mismatches, fmt.Sprintf("saved query not listed in %+v", queries))
+	}
+
+	saved, err := filter.GetQuery(queryName)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	config, err := filter.ParseQueryString(saved)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	result, err := filter.FilterFilesWithConfig(config)
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	var got []string
+	for _, f := range result.Files {
+		got = append(got, strings.TrimPrefix(pathutils.ToRelative(f.Path), savedQueryTestDir+"/"))
+	}
+	slices.Sort(got)
+	if want := []string{"book.md", "paper.md"}; !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("saved query matched %v", got))
+	}
+
+	if err := filter.DeleteQuery(queryName); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("delete: %v", err))
+	}
+	if _, err := filter.GetQuery(queryName); !errors.Is(err, filter.ErrQueryNotFound) {
+		mismatches = append(mismatches, fmt.Sprintf("deleted query: %v", err))
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "saved queries were not stored, run or deleted as expected"
+	}
+	return caseResult
+}
@@ -0,0 +1,106 @@
+package filtertest
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// runSortingCase sorts three files, whose title and size orders differ from their path
+// order, by title, size and the default sort. An empty sortBy or
+// sortOrder falls back to the fileListSort and fileListOrder settings, pinned to path asc
+// for the case. Unknown sort fields and orders from a filter form fail validation like in
+// handleAPIFilterFiles.
+func runSortingCase() test.CaseResult {
+	const name = "test43sorting"
+	expected := "default [a b c], title asc [b c a], size desc [a c b], default desc [c b a], invalid sortBy and sortOrder rejected"
+
+	err := createCaseFiles(sortTestDir, []caseFile{
+		{name: "a.md", content: "# Charlie\n\nsome longer content\n"},
+		{name: "b.md", content: "# Alpha\n"},
+		{name: "c.md", content: "# Bravo\n\nmid content\n"},
+	})
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+	restore, err := pinDefaultSort()
+	defer restore()
+	if err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	criteria := []filter.Criteria{{Metadata: "folders", Operator: "equals", Value: "filter-sort-tests", Action: "include"}}
+	var mismatches []string
+	for _, tc := range []struct {
+		sortBy, sortOrder string
+		want              []string
+	}{
+		{"", "", []string{"a.md", "b.md", "c.md"}},
+		{"title", "asc", []string{"b.md", "c.md", "a.md"}},
+		{"size", "desc", []string{"a.md", "c.md", "b.md"}},
+		{"", "desc", []string{"c.md", "b.md", "a.md"}},
+	} {
+		config := &filter.Config{Criteria: criteria, Logic: "and", SortBy: tc.sortBy, SortOrder: tc.sortOrder}
+		if err := filter.ValidateConfig(config); err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: config}
+		}
+		result, err := filter.FilterFilesWithConfig(config)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: config}
+		}
+		var got []string
+		for _, file := range result.Files {
+			got = append(got, filepath.Base(file.Path))
+		}
+		if !slices.Equal(got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("sort %q %q: %v", tc.sortBy, tc.sortOrder, got))
+		}
+	}
+
+	for _, invalid := range []url.Values{{"sortBy": {"priority"}}, {"sortOrder": {"sideways"}}} {
+		form := url.Values{"logic": {"and"}, "metadata[0]": {"folders"}, "operator[0]": {"equals"}, "value[0]": {"filter-sort-tests"}, "action[0]": {"include"}}
+		for key, values := range invalid {
+			form[key] = values
+		}
+		config, err := parseFilterForm(form)
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+		}
+		if filter.ValidateConfig(config) == nil {
+			mismatches = append(mismatches, fmt.Sprintf("%v accepted", invalid))
+		}
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "files are not in the requested sort order or an invalid sort was accepted"
+	}
+	return caseResult
+}
+
+// pinDefaultSort sets the default file list sort to path asc and returns a func restoring
+// the previous fileListSort and fileListOrder settings.
+func pinDefaultSort() (func(), error) {
+	sortSetting, orderSetting := configmanager.GetSetting("fileListSort"), configmanager.GetSetting("fileListOrder")
+	previousSort, previousOrder := configmanager.GetFileListSort(), configmanager.GetFileListOrder()
+	restore := func() {
+		sortSetting.SetFromString(previousSort)   //nolint:errcheck // restoring a previously valid value
+		orderSetting.SetFromString(previousOrder) //nolint:errcheck
+		configmanager.SaveSettings()              //nolint:errcheck
+	}
+	if err := sortSetting.SetFromString(files.SortByPath); err != nil {
+		return restore, err
+	}
+	return restore, orderSetting.SetFromString("asc")
+}
@@ -0,0 +1,73 @@
+package filtertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/filter"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// runUnderFolderCase matches the folders under operator against a small folder tree: a
+// folder matches its own files and those of every subfolder, but not a sibling folder
+// sharing its name as a prefix. Leading and trailing slashes are ignored, and the operator
+// is rejected for other fields than folders.
+func runUnderFolderCase() test.CaseResult {
+	const name = "test46underfolder"
+	expected := "a/b [a/b/c/note.md a/b/note.md], /a/b/ the same, a/x [a/x/note.md], a/b/c [a/b/c/note.md], b [], under rejected for tags"
+
+	var caseFiles []caseFile
+	for _, relPath := range []string{"a/b/c/note.md", "a/b/note.md", "a/x/note.md", "a/bc/note.md"} {
+		caseFiles = append(caseFiles, caseFile{name: relPath, content: "# note\n"})
+	}
+	if err := createCaseFiles(underTestDir, caseFiles); err != nil {
+		return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error()}
+	}
+
+	var mismatches []string
+	for _, tc := range []struct {
+		value string
+		want  []string
+	}{
+		{underTestDir + "/a/b", []string{"a/b/c/note.md", "a/b/note.md"}},
+		{"/" + underTestDir + "/a/b/", []string{"a/b/c/note.md", "a/b/note.md"}},
+		{underTestDir + "/a/x", []string{"a/x/note.md"}},
+		{underTestDir + "/a/b/c", []string{"a/b/c/note.md"}},
+		{underTestDir + "/b", nil},
+	} {
+		criterion := filter.Criteria{Metadata: "folders", Operator: "under", Value: tc.value, Action: "include"}
+		if err := filter.ValidateCriterion(criterion); err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: criterion}
+		}
+		result, err := filter.FilterFiles([]filter.Criteria{criterion}, "and")
+		if err != nil {
+			return test.CaseResult{Name: name, Expected: expected, Actual: "error", Error: err.Error(), Detail: criterion}
+		}
+		var got []string
+		for _, file := range result {
+			rel, _ := filepath.Rel(underTestDir, pathutils.ToRelative(file.Path))
+			got = append(got, filepath.ToSlash(rel))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("under %q: %v", strings.TrimPrefix(tc.value, "/"), got))
+		}
+	}
+	if filter.ValidateCriterion(filter.Criteria{Metadata: "tags", Operator: "under", Value: "a", Action: "include"}) == nil {
+		mismatches = append(mismatches, "under accepted for tags")
+	}
+
+	caseResult := test.CaseResult{
+		Name:     name,
+		Expected: expected,
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  len(mismatches) == 0,
+	}
+	if !caseResult.Success {
+		caseResult.Error = "the under operator matched the wrong folders or was accepted for tags"
+	}
+	return caseResult
+}
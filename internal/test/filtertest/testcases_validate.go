@@ -0,0 +1,100 @@
+package filtertest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/filter"
+	"knov/internal/test"
+)
+
+// validationConfig is a filter config checked by ValidateCriteria and ValidateConfig the way
+// handleAPIValidateFilter does, with the expected validity of each criterion.
+type validationConfig struct {
+	name      string
+	criteria  []filter.Criteria
+	wantValid []bool
+	wantError string // substring of the error of the first invalid criterion
+}
+
+var validationConfigs = []validationConfig{
+	{
+		name: "test27validconfig",
+		criteria: []filter.Criteria{
+			{Metadata: "createdAt", Operator: "greater", Value: "2025-01-01", Action: "include"},
+			{Metadata: "tags", Operator: "contains", Value: "draft", Action: "exclude"},
+		},
+		wantValid: []bool{true, true},
+	},
+	{
+		// greater/less compare non-date fields as strings
+		name: "test28stringcompare",
+		criteria: []filter.Criteria{
+			{Metadata: "title", Operator: "greater", Value: "m", Action: "include"},
+			{Metadata: "collection", Operator: "less", Value: "n", Action: "include"},
+		},
+		wantValid: []bool{true, true},
+	},
+	{
+		name: "test29unsupportedoperator",
+		criteria: []filter.Criteria{
+			{Metadata: "tags", Operator: "contains", Value: "draft", Action: "include"},
+			{Metadata: "title", Operator: "under", Value: "m", Action: "include"},
+		},
+		wantValid: []bool{true, false},
+		wantError: "not supported",
+	},
+	{
+		name: "test30unparseabledate",
+		criteria: []filter.Criteria{
+			{Metadata: "lastEdited", Operator: "less", Value: "yesterday", Action: "include"},
+		},
+		wantValid: []bool{false},
+	},
+	{
+		name: "test41invalidregex",
+		criteria: []filter.Criteria{
+			{Metadata: "tags", Operator: "regex", Value: "proj(", Action: "include"},
+		},
+		wantValid: []bool{false},
+		wantError: "invalid regex",
+	},
+}
+
+// runValidationCases validates each validation config without running the filter.
+func runValidationCases() []test.CaseResult {
+	var results []test.CaseResult
+	for _, vc := range validationConfigs {
+		config := &filter.Config{Criteria: vc.criteria, Logic: "and"}
+		validations := filter.ValidateCriteria(config)
+
+		valid := filter.ValidateConfig(config) == nil
+		var gotValid []bool
+		var firstError string
+		for _, v := range validations {
+			gotValid = append(gotValid, v.Valid)
+			if !v.Valid && firstError == "" {
+				firstError = v.Error
+			}
+		}
+		wantConfigValid := !slices.Contains(vc.wantValid, false)
+
+		caseResult := test.CaseResult{
+			Name:     vc.name,
+			Expected: fmt.Sprintf("config valid=%t, criteria %v", wantConfigValid, vc.wantValid),
+			Actual:   fmt.Sprintf("config valid=%t, criteria %v", valid, gotValid),
+			Detail:   config,
+		}
+		switch {
+		case valid != wantConfigValid || !slices.Equal(gotValid, vc.wantValid):
+			caseResult.Error = fmt.Sprintf("unexpected validation result, first error %q", firstError)
+		case !strings.Contains(firstError, vc.wantError):
+			caseResult.Error = fmt.Sprintf("error %q doesn't mention %q", firstError, vc.wantError)
+		default:
+			caseResult.Success = true
+		}
+		results = append(results, caseResult)
+	}
+	return results
+}
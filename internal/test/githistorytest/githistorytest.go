@@ -22,10 +22,13 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		caseGitLatestChangesCollectionFilter,
 		caseGitSearchByFilename,
 		caseGitFileHistoryVersions,
+		caseGitFileHistoryLimitRename,
 		caseGitFileViewVersion,
 		caseGitFileDiff,
 		caseGitFileRestore,
+		caseGitSnapshot,
 		caseGitRemotePushPullTestAuth,
+		caseGitPullRemoteChanges,
 	}
 
 	result := &test.SuiteResult{Suite: "git-history"}
@@ -2,9 +2,12 @@ package githistorytest
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
-	"knov/internal/files"
 	"knov/internal/git"
 	"knov/internal/pathutils"
 	"knov/internal/test"
@@ -48,50 +51,41 @@ func pathsOf(fs []git.GitHistoryFile) []string {
 	return out
 }
 
-// caseGitLatestChangesCollectionFilter replicates handleAPIGetRecentlyChanged's inline
-// collection filter (it's not extracted into internal/git, so this reproduces the same
-// loop+files.MetaDataGet comparison directly, same approach editorstest uses for its
-// unexported-handler bulk-op cases). Collection is derived from a file's top-level folder
-// (files.CollectionFromPath), and every sample file here lives under "test/" (so the admin
-// "Clean Test Data" button can remove it), meaning they all share the "test" collection -
-// checks that collection=testCollection includes gamma while a bogus collection excludes it.
+// caseGitLatestChangesCollectionFilter covers git.RecentChanges, the latest changes of the
+// history page and GET /api/git/latestchanges filtered by collection or folder. Collection is
+// derived from a file's top-level folder (files.CollectionFromPath), and every sample file
+// here lives under "test/" (so the admin "Clean Test Data" button can remove it), meaning
+// they all share the "test" collection - checks that collection=testCollection and the
+// sample folder include gamma while a bogus collection or folder excludes it.
 func caseGitLatestChangesCollectionFilter(_ *sampleState) test.CaseResult {
 	name := "git-latestchanges-collection-filter"
 
 	wantPath := pathutils.ToWithPrefix(testPath(gammaFile))
 
-	filterByCollection := func(collection string) []string {
-		all, err := git.GetRecentlyChangedFiles(20, 0)
+	recent := func(collection, folder string) []string {
+		changed, _, err := git.RecentChanges(20, 0, collection, folder)
 		if err != nil {
 			return nil
 		}
-		var filtered []string
-		for _, f := range all {
-			meta, err := files.MetaDataGet(f.Path)
-			if err != nil || meta == nil {
-				continue
-			}
-			if meta.Collection == collection {
-				filtered = append(filtered, f.Path)
-			}
-		}
-		return filtered
+		return pathsOf(changed)
 	}
 
-	matching := filterByCollection(testCollection)
-	nonMatching := filterByCollection("nonexistent-collection-zzz")
+	matching := recent(testCollection, "")
+	inFolder := recent("", testDir)
+	nonMatching := recent("nonexistent-collection-zzz", "")
+	otherFolder := recent("", "nonexistent-folder-zzz")
 
-	hasGamma := containsPath(matching, wantPath)
-	success := hasGamma && len(nonMatching) == 0
+	success := containsPath(matching, wantPath) && containsPath(inFolder, wantPath) &&
+		len(nonMatching) == 0 && len(otherFolder) == 0
 
 	cr := test.CaseResult{
 		Name:     name,
-		Expected: fmt.Sprintf("collection=%s contains %s, a nonexistent collection matches nothing", testCollection, gammaFile),
-		Actual:   fmt.Sprintf("matching=%v nonMatching=%v", matching, nonMatching),
+		Expected: fmt.Sprintf("collection=%s and folder=%s contain %s, a nonexistent collection or folder matches nothing", testCollection, testDir, gammaFile),
+		Actual:   fmt.Sprintf("matching=%v inFolder=%v nonMatching=%v otherFolder=%v", matching, inFolder, nonMatching, otherFolder),
 		Success:  success,
 	}
 	if !success {
-		cr.Error = "collection filter over latest-changes did not match expected files"
+		cr.Error = "collection or folder filter over latest-changes did not match expected files"
 	}
 	return cr
 }
@@ -225,3 +219,120 @@ func caseGitFileRestore(state *sampleState) test.CaseResult {
 	}
 	return cr
 }
+
+// caseGitFileHistoryLimitRename covers git.FileHistory (GET /api/git/history): two commits
+// of a file are two versions newest first, the limit caps them, a rename keeps the history
+// of the old path and a file git doesn't know has an empty, non-nil history. The files get
+// a folder of their own per run, so the history of an earlier run's files doesn't count.
+func caseGitFileHistoryLimitRename(_ *sampleState) test.CaseResult {
+	name := "git-file-history-limit-rename"
+	folder := testPath(fmt.Sprintf("history-%d", time.Now().UnixNano()))
+	note, renamed, untracked := filepath.Join(folder, "note.md"), filepath.Join(folder, "renamed.md"), filepath.Join(folder, "untracked.md")
+	for i, content := range []string{"# first", "# second"} {
+		if err := writeFile(note, content); err != nil {
+			return errCase(name, err)
+		}
+		if err := commitAll(fmt.Sprintf("githistorytest: history note v%d", i+1)); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	history := func(rel string, limit int) []git.FileVersion {
+		versions, err := git.FileHistory(pathutils.ToFullPath(rel), limit)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", rel, err))
+		}
+		return versions
+	}
+	if versions := history(note, 0); len(versions) != 2 || !versions[0].IsCurrent {
+		mismatches = append(mismatches, fmt.Sprintf("versions %+v", versions))
+	}
+	if versions := history(note, 1); len(versions) != 1 {
+		mismatches = append(mismatches, fmt.Sprintf("%d versions with limit 1", len(versions)))
+	}
+
+	// a rename keeps the history of the old path
+	if err := os.Rename(pathutils.ToDocsPath(note), pathutils.ToDocsPath(renamed)); err != nil {
+		return errCase(name, err)
+	}
+	if err := commitAll("githistorytest: rename history note"); err != nil {
+		return errCase(name, err)
+	}
+	if versions := history(renamed, 0); len(versions) != 3 {
+		mismatches = append(mismatches, fmt.Sprintf("%d versions after the rename", len(versions)))
+	}
+
+	if err := writeFile(untracked, "# new"); err != nil {
+		return errCase(name, err)
+	}
+	if versions := history(untracked, 0); versions == nil || len(versions) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("untracked versions %+v", versions))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "2 versions newest first, 1 with limit 1, 3 after the rename, an empty list for an untracked file",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "file history did not follow the limit and the rename as expected"
+	}
+	return cr
+}
+
+// caseGitSnapshot covers git.CommitSnapshot with git.SnapshotMessage, what the git-snapshot
+// job runs: pending changes are committed with the template's {timestamp} filled in, a clean
+// tree is skipped without a commit and an empty template falls back to the default message.
+func caseGitSnapshot(_ *sampleState) test.CaseResult {
+	name := "git-snapshot"
+	rel := testPath(fmt.Sprintf("snapshot-%d.md", time.Now().UnixNano()))
+	if err := writeFile(rel, "# note"); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	committed, err := git.CommitSnapshot(git.SnapshotMessage("githistorytest snapshot at {timestamp}", time.Now()))
+	if err != nil {
+		return errCase(name, err)
+	}
+	head, err := git.GetCurrentCommit()
+	if err != nil {
+		return errCase(name, err)
+	}
+	_, message, err := git.GetCommitDetails(head)
+	if err != nil {
+		return errCase(name, err)
+	}
+	if !committed || !regexp.MustCompile(`^githistorytest snapshot at \d{4}-\d{2}-\d{2}T`).MatchString(message) {
+		mismatches = append(mismatches, fmt.Sprintf("committed=%t message %q", committed, message))
+	}
+	if versions, _ := git.FileHistory(pathutils.ToDocsPath(rel), 0); len(versions) != 1 {
+		mismatches = append(mismatches, fmt.Sprintf("%d versions of the snapshot note", len(versions)))
+	}
+
+	// a clean tree is skipped without a commit
+	if committed, err := git.CommitSnapshot("githistorytest clean snapshot"); err != nil || committed {
+		mismatches = append(mismatches, fmt.Sprintf("clean tree committed=%t, %v", committed, err))
+	}
+	if again, _ := git.GetCurrentCommit(); again != head {
+		mismatches = append(mismatches, fmt.Sprintf("head moved from %s to %s", head, again))
+	}
+	if got := git.SnapshotMessage("", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)); got != "auto-snapshot 2026-01-02T03:04:05Z" {
+		mismatches = append(mismatches, fmt.Sprintf("default message %q", got))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "the note committed with the timestamped message, no commit for a clean tree, the default message for an empty template",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the snapshot was not committed as expected"
+	}
+	return cr
+}
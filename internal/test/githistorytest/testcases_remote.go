@@ -1,21 +1,28 @@
 package githistorytest
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"knov/internal/configmanager"
 	"knov/internal/git"
+	"knov/internal/pathutils"
 	"knov/internal/test"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // caseGitRemotePushPullTestAuth points the app's git remote at a throwaway local bare repo
 // (file:// transport, no network involved) and exercises EnsureRemote/TestAuth/Push/
-// PullRebase against it, then always restores whatever remote was configured before the
+// Pull against it, then always restores whatever remote was configured before the
 // case ran. KNOV_GIT_REMOTE is the only remote setting UpdateEnvFile applies live (branch/
 // autopush changes need a restart), so the case works with the currently configured branch.
 func caseGitRemotePushPullTestAuth(_ *sampleState) test.CaseResult {
@@ -48,7 +55,7 @@ func caseGitRemotePushPullTestAuth(_ *sampleState) test.CaseResult {
 		return errCase(name, err)
 	}
 
-	// git.Push/PullRebase always push/pull refs/heads/<configured branch>, which only
+	// git.Push/Pull always push/pull refs/heads/<configured branch>, which only
 	// exists locally if the repo's actual branch happens to match it (KNOV_GIT_REMOTE_BRANCH
 	// isn't a live-editable setting, so it can't be pointed at whatever branch this repo
 	// really uses) - create a temporary local ref under that name pointing at HEAD so the
@@ -62,9 +69,8 @@ func caseGitRemotePushPullTestAuth(_ *sampleState) test.CaseResult {
 		defer removeLocalBranchRef(branch)
 	}
 
-	git.Push() // fire-and-forget; poll the bare remote below instead of assuming completion
-
-	pushed := waitForBranch(bareDir, branch, 3*time.Second)
+	pushErr := git.Push()
+	pushed := pushErr == nil && waitForBranch(bareDir, branch, 3*time.Second)
 
 	// test-auth (ls-remote) only after the push - go-git errors on ls-remote against a
 	// truly empty bare repo (zero refs), which a fresh PlainInit(bare) always is
@@ -73,13 +79,13 @@ func caseGitRemotePushPullTestAuth(_ *sampleState) test.CaseResult {
 		return errCase(name, fmt.Errorf("test-auth against local bare remote failed: %w", err))
 	}
 
-	pullErr := git.PullRebase()
+	pullErr := git.Pull()
 
 	success := pushed && pullErr == nil
 	cr := test.CaseResult{
 		Name:     name,
 		Expected: "test-auth connects, push lands the current branch on the bare remote, pull is a no-op (already up to date)",
-		Actual:   fmt.Sprintf("test-auth=%q pushed=%v pullErr=%v", authResult, pushed, pullErr),
+		Actual:   fmt.Sprintf("test-auth=%q pushed=%v pushErr=%v pullErr=%v", authResult, pushed, pushErr, pullErr),
 		Success:  success,
 	}
 	if !success {
@@ -150,3 +156,153 @@ func waitForBranch(bareDir, branch string, timeout time.Duration) bool {
 	}
 	return false
 }
+
+// caseGitPullRemoteChanges covers git.Pull (POST /api/git/pull) against a throwaway local
+// bare repo a second clone commits to: without a remote it returns ErrNoRemote, a remote
+// change is fast-forwarded into the data directory, and when both sides changed the note the
+// pull fails with a ConflictError listing it and keeps the local content. The reindex the
+// pull job runs afterwards is the file sync job's, not checked here. Restores the configured
+// remote like caseGitRemotePushPullTestAuth.
+func caseGitPullRemoteChanges(_ *sampleState) test.CaseResult {
+	name := "git-pull-remote-changes"
+
+	bareDir, err := os.MkdirTemp("", "knov-githistorytest-pull-*")
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer os.RemoveAll(bareDir)
+	cloneDir, err := os.MkdirTemp("", "knov-githistorytest-clone-*")
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer os.RemoveAll(cloneDir)
+	if _, err := gogit.PlainInit(bareDir, true); err != nil {
+		return errCase(name, err)
+	}
+
+	origRemote := configmanager.GetGitRemote()
+	defer func() {
+		_ = configmanager.UpdateEnvFile("KNOV_GIT_REMOTE", origRemote)
+		if origRemote == "" {
+			removeOriginRemote()
+		} else {
+			_ = git.EnsureRemote()
+		}
+	}()
+
+	var mismatches []string
+	if err := configmanager.UpdateEnvFile("KNOV_GIT_REMOTE", ""); err != nil {
+		return errCase(name, err)
+	}
+	if err := git.Pull(); !errors.Is(err, git.ErrNoRemote) {
+		mismatches = append(mismatches, fmt.Sprintf("pull without a remote: %v", err))
+	}
+
+	folder := testPath(fmt.Sprintf("sync-%d", time.Now().UnixNano()))
+	note := filepath.Join(folder, "note.md")
+	notePath := pathutils.ToDocsPath(note)
+	noteRel, err := filepath.Rel(configmanager.GetAppConfig().DataPath, notePath)
+	if err != nil {
+		return errCase(name, err)
+	}
+	noteRel = filepath.ToSlash(noteRel)
+	if err := writeFile(note, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := commitAll("githistorytest: sync note"); err != nil {
+		return errCase(name, err)
+	}
+
+	if err := configmanager.UpdateEnvFile("KNOV_GIT_REMOTE", "file://"+bareDir); err != nil {
+		return errCase(name, err)
+	}
+	if err := git.EnsureRemote(); err != nil {
+		return errCase(name, err)
+	}
+	branch := configmanager.GetGitRemoteBranch()
+	createdRef, err := ensureLocalBranchRef(branch)
+	if err != nil {
+		return errCase(name, err)
+	}
+	if createdRef {
+		defer removeLocalBranchRef(branch)
+	}
+	if err := git.Push(); err != nil || !waitForBranch(bareDir, branch, 3*time.Second) {
+		return errCase(name, fmt.Errorf("push to the bare remote failed: %v", err))
+	}
+
+	// a second clone commits to the remote
+	clone, err := gogit.PlainClone(cloneDir, false, &gogit.CloneOptions{URL: bareDir, ReferenceName: plumbing.NewBranchReferenceName(branch)})
+	if err != nil {
+		return errCase(name, err)
+	}
+	remoteRel := path.Join(path.Dir(noteRel), "remote.md")
+	if err := commitInClone(clone, map[string]string{noteRel: "# note\n\nfrom the remote\n", remoteRel: "# remote\n"}); err != nil {
+		return errCase(name, err)
+	}
+	if err := git.Pull(); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("pull: %v", err))
+	}
+	if content, _ := readFile(note); !strings.Contains(content, "from the remote") {
+		mismatches = append(mismatches, fmt.Sprintf("pulled note %q", content))
+	}
+	if _, err := os.Stat(pathutils.ToDocsPath(filepath.Join(folder, "remote.md"))); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("pulled file: %v", err))
+	}
+
+	// both sides change the note: the pull can't fast-forward and lists it
+	if err := writeFile(note, "# note\n\nlocal\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := commitAll("githistorytest: local sync change"); err != nil {
+		return errCase(name, err)
+	}
+	if err := commitInClone(clone, map[string]string{noteRel: "# note\n\nremote\n", path.Join(path.Dir(noteRel), "other.md"): "# other\n"}); err != nil {
+		return errCase(name, err)
+	}
+	var conflict *git.ConflictError
+	if err := git.Pull(); !errors.As(err, &conflict) || !slices.Equal(conflict.Files, []string{noteRel}) {
+		mismatches = append(mismatches, fmt.Sprintf("diverged pull: %v", err))
+	}
+	if content, _ := readFile(note); !strings.Contains(content, "local") {
+		mismatches = append(mismatches, fmt.Sprintf("local note after the conflict %q", content))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "ErrNoRemote without a remote, the remote change pulled, a ConflictError listing the note when both sides changed it",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "pull did not bring in or report the remote changes as expected"
+	}
+	return cr
+}
+
+// commitInClone writes contents (paths relative to the clone's root) into clone, commits
+// them and pushes the commit to the clone's origin.
+func commitInClone(clone *gogit.Repository, contents map[string]string) error {
+	worktree, err := clone.Worktree()
+	if err != nil {
+		return err
+	}
+	for rel, content := range contents {
+		full := filepath.Join(worktree.Filesystem.Root(), rel)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			return err
+		}
+		if _, err := worktree.Add(rel); err != nil {
+			return err
+		}
+	}
+	signature := &object.Signature{Name: "githistorytest", Email: "githistorytest@localhost", When: time.Now()}
+	if _, err := worktree.Commit("githistorytest: remote edit", &gogit.CommitOptions{Author: signature}); err != nil {
+		return err
+	}
+	return clone.Push(&gogit.PushOptions{})
+}
@@ -32,6 +32,7 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		caseTagsAndFilesForFolder,
 		caseExcerpt,
 		caseKanbanHelpers,
+		caseMoveCardToField,
 	}
 
 	result := &test.SuiteResult{Suite: "kanban"}
@@ -1,9 +1,14 @@
 package kanbantest
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"knov/internal/configmanager"
+	"knov/internal/files"
 	"knov/internal/filter"
 	"knov/internal/kanban"
 	"knov/internal/pathutils"
@@ -304,3 +309,113 @@ func caseKanbanHelpers() test.CaseResult {
 	}
 	return cr
 }
+
+// caseMoveCardToField covers kanban.MoveCardToField (POST /api/metadata/board/move), the
+// card move of kanban dashboard widgets: a status move between two cards sets the status
+// and stores the dragged order, a reorder within the column stores the new one, a priority
+// move sets the custom field, and unknown statuses, new priorities, ungroupable fields and
+// files without metadata are rejected. Runs last, since its cards would otherwise show up
+// on the boards of the other cases.
+func caseMoveCardToField() test.CaseResult {
+	name := "move-card-to-field"
+	statuses := configmanager.GetKanbanStatuses()
+	a, b, c := testPath("fieldmove/a.md"), testPath("fieldmove/b.md"), testPath("fieldmove/c.md")
+	for rel, metadata := range map[string]*files.Metadata{
+		a: {Tags: []string{kanbanTag(statuses[0])}, Custom: map[string]string{"priority": "high"}},
+		b: {Tags: []string{kanbanTag(statuses[0])}, Custom: map[string]string{"priority": "low"}},
+		c: {Tags: []string{kanbanTag(statuses[1])}, Custom: map[string]string{"priority": "low"}},
+	} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		metadata.Path = pathutils.ToWithPrefix(rel)
+		if err := files.MetaDataSaveNoRefresh(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	// column returns the case's cards of a widget column in display order, the way the
+	// kanban widget applies the stored order to the grouped files
+	column := func(value string) ([]string, error) {
+		allFiles, err := files.GetAllFilesCached()
+		if err != nil {
+			return nil, err
+		}
+		files.SortFilesDefault(allFiles)
+		stored, err := kanban.GetFieldOrder("status")
+		if err != nil {
+			return nil, err
+		}
+		var cards []string
+		for _, group := range filter.GroupFiles(allFiles, "status") {
+			if group.Value != value {
+				continue
+			}
+			var paths []string
+			for _, f := range group.Files {
+				paths = append(paths, pathutils.ToRelative(f.Path))
+			}
+			for _, path := range kanban.ApplyOrder(stored[value], paths) {
+				if strings.HasPrefix(path, testPath("fieldmove")+"/") {
+					cards = append(cards, filepath.Base(path))
+				}
+			}
+		}
+		return cards, nil
+	}
+
+	var mismatches []string
+	// c is dragged between a and b of the first status column
+	if _, err := kanban.MoveCardToField(c, "status", statuses[0], []string{a, c, b}); err != nil {
+		return errCase(name, err)
+	}
+	if metadata, _ := files.MetaDataGet(pathutils.ToWithPrefix(c)); metadata == nil || files.KanbanStatusFromTags(metadata.Tags) != statuses[0] {
+		mismatches = append(mismatches, fmt.Sprintf("c after the move %+v", metadata))
+	}
+	if got, err := column(statuses[0]); err != nil || !slices.Equal(got, []string{"a.md", "c.md", "b.md"}) {
+		mismatches = append(mismatches, fmt.Sprintf("dragged column %v, %v", got, err))
+	}
+
+	// reordering within a column keeps the status and stores the new order
+	if _, err := kanban.MoveCardToField(b, "status", statuses[0], []string{b, a, c}); err != nil {
+		return errCase(name, err)
+	}
+	if got, err := column(statuses[0]); err != nil || !slices.Equal(got, []string{"b.md", "a.md", "c.md"}) {
+		mismatches = append(mismatches, fmt.Sprintf("reordered column %v, %v", got, err))
+	}
+
+	// priority moves set the custom field, to one of its existing values only
+	if _, err := kanban.MoveCardToField(b, "custom.priority", "high", nil); err != nil {
+		return errCase(name, err)
+	}
+	if metadata, _ := files.MetaDataGet(pathutils.ToWithPrefix(b)); metadata == nil || metadata.Custom["priority"] != "high" {
+		mismatches = append(mismatches, fmt.Sprintf("b after the priority move %+v", metadata))
+	}
+	for reason, move := range map[string][2]string{
+		"unknown status":    {"status", "no-such-status"},
+		"new priority":      {"custom.priority", "kanbantest-no-such-priority"},
+		"ungroupable field": {"editor", "markdown"},
+	} {
+		if _, err := kanban.MoveCardToField(a, move[0], move[1], nil); !errors.Is(err, kanban.ErrInvalidFieldValue) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", reason, err))
+		}
+	}
+	if _, err := kanban.MoveCardToField(testPath("fieldmove/missing.md"), "status", statuses[0], nil); !errors.Is(err, kanban.ErrCardNotFound) {
+		mismatches = append(mismatches, fmt.Sprintf("file without metadata: %v", err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "column a, c, b after the drag and b, a, c after the reorder, b high priority, invalid moves and missing files rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "field moves did not update the card and column order as expected"
+	}
+	return cr
+}
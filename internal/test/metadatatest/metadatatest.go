@@ -0,0 +1,75 @@
+// Package metadatatest - metadata suite: seeds real files and exercises internal/files'
+// metadata initialization and bulk metadata operations directly, without going through HTTP.
+package metadatatest
+
+import "knov/internal/test"
+
+// Suite runs the metadata test cases against real files, metadata and settings.
+type Suite struct{}
+
+func init() {
+	test.Register(Suite{})
+}
+
+func (Suite) Name() string { return "metadata" }
+
+func (Suite) Run() (*test.SuiteResult, error) {
+	if err := resetTestDir(); err != nil {
+		return nil, err
+	}
+
+	cases := []func() test.CaseResult{
+		caseMetadataDefaults,
+		caseExcludedCollections,
+		caseTagByFilter,
+		caseTargetDateByFilter,
+		caseDatalistOptionsCap,
+		caseCollectionMOC,
+		caseCollectionMOCPathSafe,
+		caseCustomMetadata,
+		caseCustomFiletypes,
+		caseImportObsidian,
+		caseArchiveTier,
+		caseRecentlyCreated,
+		caseRefreshTimes,
+		caseTagSynonyms,
+		caseMetadataPreview,
+		caseFiletypeIcons,
+		caseWordCount,
+		caseLinkGraph,
+		caseRenameTag,
+		caseFrontMatterSync,
+		caseReindexChangedSince,
+		caseConcurrentSaves,
+		caseSuggestMetadataValues,
+		caseDeletePrefix,
+		caseValidateMetadata,
+		caseRepairMetadata,
+		caseCreateMissingParents,
+		caseLinkGraphDOT,
+		caseMetadataLabel,
+		caseMetadataHistory,
+		caseRecompute,
+		caseBulkSet,
+		caseSuggestFromContent,
+		caseMetadataImport,
+		caseCollectionIndex,
+		caseAgendaFeed,
+		caseHierarchyProblems,
+		caseStrictMetadataJSON,
+	}
+
+	result := &test.SuiteResult{Suite: "metadata"}
+	for _, c := range cases {
+		cr := c()
+		result.Cases = append(result.Cases, cr)
+		if cr.Success {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	result.Total = len(cases)
+	result.Success = result.Failed == 0
+	return result, nil
+}
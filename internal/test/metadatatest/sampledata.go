@@ -0,0 +1,74 @@
+// Package metadatatest - sample folder, per-case sample file helpers and temporary setting overrides
+package metadatatest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// testDir is the docs-relative sample folder every case seeds into, wiped at the start
+// of each run so cases never see stale state from a previous run.
+const testDir = "test/metadata-tests"
+
+// resetTestDir clears the sample folder on disk so every run starts from a clean state.
+func resetTestDir() error {
+	full := pathutils.ToDocsPath(testDir)
+	if err := os.RemoveAll(full); err != nil {
+		return err
+	}
+	return os.MkdirAll(full, 0755)
+}
+
+// testPath returns a docs-relative path under the sample folder, e.g. "test/metadata-tests/a.md".
+func testPath(name string) string {
+	return filepath.ToSlash(filepath.Join(testDir, name))
+}
+
+func writeFile(relPath, content string) error {
+	full := pathutils.ToDocsPath(relPath)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	return contentStorage.WriteFile(full, []byte(content), 0644)
+}
+
+// overrideSetting sets a registry setting for the duration of a case and returns a func
+// restoring the previous value - settings are persisted in configStorage, not under
+// docs/test/, so a folder wipe would never undo them.
+func overrideSetting(key, value string) (func(), error) {
+	s := configmanager.GetSetting(key)
+	previous := settingString(s.GetValue())
+	if err := s.SetFromString(value); err != nil {
+		return func() {}, err
+	}
+	return func() {
+		s.SetFromString(previous)    //nolint:errcheck // restoring a previously valid value
+		configmanager.SaveSettings() //nolint:errcheck
+	}, nil
+}
+
+// settingString formats a setting value the way SetFromString expects it back.
+func settingString(v any) string {
+	switch val := v.(type) {
+	case []string:
+		return strings.Join(val, ",")
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case string:
+		return val
+	}
+	return ""
+}
+
+func errCase(name string, err error) test.CaseResult {
+	return test.CaseResult{Name: name, Success: false, Error: err.Error()}
+}
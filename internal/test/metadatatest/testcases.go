@@ -0,0 +1,772 @@
+package metadatatest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/contentStorage"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/metadataStorage"
+	"knov/internal/pathutils"
+	"knov/internal/search"
+	"knov/internal/test"
+)
+
+// caseMetadataDefaults covers the configurable metadata defaults: a new markdown file saved
+// without editor or tags must pick up the configured default editor and tags instead of the
+// hardcoded toastui fallback, while a file saved with explicit tags keeps only its own. Both
+// get the default kanban status and priority, neither of which they were saved with.
+func caseMetadataDefaults() test.CaseResult {
+	name := "metadata-defaults"
+
+	statuses := configmanager.GetKanbanStatuses()
+	if len(statuses) == 0 {
+		return errCase(name, fmt.Errorf("no kanban statuses configured"))
+	}
+	status := statuses[0]
+	statusTag := configmanager.KanbanStatusTag(status)
+
+	for key, value := range map[string]string{
+		"defaultMarkdownEditor": string(files.EditorTypeCodeMirror),
+		"defaultTags":           "draft",
+		"defaultStatus":         status,
+		"defaultPriority":       "high",
+	} {
+		restore, err := overrideSetting(key, value)
+		defer restore()
+		if err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	plain := testPath("defaults-plain.md")
+	tagged := testPath("defaults-tagged.md")
+	for _, rel := range []string{plain, tagged} {
+		if err := writeFile(rel, "# defaults\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(plain)}); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(tagged), Tags: []string{"explicit"}}); err != nil {
+		return errCase(name, err)
+	}
+
+	plainMeta, err := files.MetaDataGet(plain)
+	if err != nil || plainMeta == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", plain, err))
+	}
+	taggedMeta, err := files.MetaDataGet(tagged)
+	if err != nil || taggedMeta == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", tagged, err))
+	}
+
+	// a status or priority the defaults can't hold is rejected and leaves the setting as it was
+	var accepted []string
+	for key, value := range map[string]string{"defaultStatus": "no-such-status", "defaultPriority": "urgent"} {
+		if configmanager.GetSetting(key).SetFromString(value) == nil {
+			accepted = append(accepted, key+"="+value)
+		}
+	}
+
+	success := plainMeta.Editor == files.EditorTypeCodeMirror &&
+		slices.Equal(plainMeta.Tags, []string{"draft", statusTag}) &&
+		slices.Equal(taggedMeta.Tags, []string{"explicit", statusTag}) &&
+		plainMeta.Custom[files.PriorityField] == "high" &&
+		taggedMeta.Custom[files.PriorityField] == "high" &&
+		len(accepted) == 0 && configmanager.GetDefaultPriority() == "high" && configmanager.GetDefaultStatus() == status
+	cr := test.CaseResult{
+		Name: name,
+		Expected: fmt.Sprintf("plain: editor=%s tags=[draft %s] priority=high, tagged: tags=[explicit %s] priority=high, invalid defaults rejected",
+			files.EditorTypeCodeMirror, statusTag, statusTag),
+		Actual: fmt.Sprintf("plain: editor=%s tags=%v priority=%s, tagged: tags=%v priority=%s, accepted=%v",
+			plainMeta.Editor, plainMeta.Tags, plainMeta.Custom[files.PriorityField], taggedMeta.Tags, taggedMeta.Custom[files.PriorityField], accepted),
+		Success: success,
+	}
+	if !success {
+		cr.Error = "new file metadata did not pick up the configured defaults"
+	}
+	return cr
+}
+
+// caseExcludedCollections covers the excludedCollections setting: an excluded collection must
+// disappear from the live and cached collection aggregations while its files still match an
+// explicit collection filter. Every sample file lives under test/, i.e. the "test" collection.
+func caseExcludedCollections() test.CaseResult {
+	name := "excluded-collections"
+	const collection = "test"
+
+	rel := testPath("excluded-collection.md")
+	if err := writeFile(rel, "# excluded\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+		return errCase(name, err)
+	}
+
+	restore, err := overrideSetting("excludedCollections", collection)
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	files.RefreshCaches()
+
+	live, err := files.GetAllCollections(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	cached, err := files.GetAllCollectionsCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	cachedNames, err := files.GetAllCollectionsFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	_, inLive := live[collection]
+	_, inCached := cached[collection]
+	inCachedNames := slices.Contains(cachedNames, collection)
+
+	matches, err := filter.FilterFiles([]filter.Criteria{
+		{Metadata: "collection", Operator: "equals", Value: collection, Action: "include"},
+	}, "and")
+	if err != nil {
+		return errCase(name, err)
+	}
+	filtered := slices.ContainsFunc(matches, func(f files.File) bool {
+		return pathutils.ToRelative(f.Path) == rel
+	})
+
+	success := !inLive && !inCached && !inCachedNames && filtered
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%q absent from live/cached aggregations, %s still matched by collection filter", collection, rel),
+		Actual:   fmt.Sprintf("live=%v cached=%v cachedNames=%v filtered=%v", inLive, inCached, inCachedNames, filtered),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "excluded collection leaked into aggregations or was no longer filterable"
+	}
+	return cr
+}
+
+// caseTagByFilter covers filter.TagByFilter (POST /api/metadata/tag/byfilter): only files matching
+// the filter get the tags merged into their existing ones, non-matching files stay unchanged.
+func caseTagByFilter() test.CaseResult {
+	name := "tag-by-filter"
+
+	seeds := []struct {
+		rel  string
+		tags []string
+	}{
+		{testPath("retag-match/tagged.md"), []string{"keep"}},
+		{testPath("retag-match/untagged.md"), nil},
+		{testPath("retag-other/tagged.md"), []string{"keep"}},
+	}
+	for _, seed := range seeds {
+		if err := writeFile(seed.rel, "# retag\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(seed.rel), Tags: seed.tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	count, err := filter.TagByFilter([]filter.Criteria{
+		{Metadata: "folders", Operator: "equals", Value: "retag-match", Action: "include"},
+	}, "and", []string{"bulk", "keep"})
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	want := [][]string{{"keep", "bulk"}, {"bulk", "keep"}, {"keep"}}
+	got := make([][]string, len(seeds))
+	success := count == 2
+	for i, seed := range seeds {
+		metadata, err := files.MetaDataGet(seed.rel)
+		if err != nil || metadata == nil {
+			return errCase(name, fmt.Errorf("metadata missing for %s: %v", seed.rel, err))
+		}
+		got[i] = metadata.Tags
+		success = success && slices.Equal(metadata.Tags, want[i])
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("count=2 tags=%v", want),
+		Actual:   fmt.Sprintf("count=%d tags=%v", count, got),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "tags were not merged into exactly the filtered files"
+	}
+	return cr
+}
+
+// caseTargetDateByFilter covers filter.SetTargetDateByFilter (POST /api/metadata/targetdate/byfilter):
+// only matching files get the target date, other custom fields are kept and an empty date clears it.
+func caseTargetDateByFilter() test.CaseResult {
+	name := "target-date-by-filter"
+
+	seeds := []struct {
+		rel    string
+		custom map[string]string
+	}{
+		{testPath("target-match/owned.md"), map[string]string{"owner": "me"}},
+		{testPath("target-match/plain.md"), nil},
+		{testPath("target-other/dated.md"), map[string]string{files.TargetDateField: "2020-01-01"}},
+	}
+	for _, seed := range seeds {
+		if err := writeFile(seed.rel, "# target\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(seed.rel), Custom: seed.custom}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	criteria := []filter.Criteria{
+		{Metadata: "folders", Operator: "equals", Value: "target-match", Action: "include"},
+	}
+	customOf := func() ([]map[string]string, error) {
+		got := make([]map[string]string, len(seeds))
+		for i, seed := range seeds {
+			metadata, err := files.MetaDataGet(seed.rel)
+			if err != nil || metadata == nil {
+				return nil, fmt.Errorf("metadata missing for %s: %v", seed.rel, err)
+			}
+			got[i] = metadata.Custom
+		}
+		return got, nil
+	}
+
+	count, err := filter.SetTargetDateByFilter(criteria, "and", "2026-12-31")
+	if err != nil {
+		return errCase(name, err)
+	}
+	set, err := customOf()
+	if err != nil {
+		return errCase(name, err)
+	}
+	success := count == 2 &&
+		set[0][files.TargetDateField] == "2026-12-31" && set[0]["owner"] == "me" &&
+		set[1][files.TargetDateField] == "2026-12-31" &&
+		set[2][files.TargetDateField] == "2020-01-01"
+
+	clearedCount, err := filter.SetTargetDateByFilter(criteria, "and", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	cleared, err := customOf()
+	if err != nil {
+		return errCase(name, err)
+	}
+	_, stillSet := cleared[0][files.TargetDateField]
+	success = success && clearedCount == 2 && !stillSet && cleared[0]["owner"] == "me" &&
+		cleared[1][files.TargetDateField] == "" &&
+		cleared[2][files.TargetDateField] == "2020-01-01"
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "count=2, target-match files dated 2026-12-31 then cleared, owner kept, target-other untouched",
+		Actual:   fmt.Sprintf("count=%d set=%v cleared=%v", count, set, cleared),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "target date was not applied to exactly the filtered files"
+	}
+	return cr
+}
+
+// caseDatalistOptionsCap covers files.TopCountKeys as used by the format=options branches:
+// options are ordered by how many files use them, narrowed by the search query and capped.
+func caseDatalistOptionsCap() test.CaseResult {
+	name := "datalist-options-cap"
+
+	seeds := map[string][]string{
+		"options-1.md": {"opt-common", "opt-middle", "opt-rare"},
+		"options-2.md": {"opt-common", "opt-middle"},
+		"options-3.md": {"opt-common"},
+	}
+	for file, tags := range seeds {
+		rel := testPath(file)
+		if err := writeFile(rel, "# options\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	counts, err := files.GetAllTags(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	capped := files.TopCountKeys(counts, "OPT-", 2)
+	searched := files.TopCountKeys(counts, "rare", 2)
+
+	success := slices.Equal(capped, []string{"opt-common", "opt-middle"}) &&
+		slices.Equal(searched, []string{"opt-rare"})
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "capped=[opt-common opt-middle] searched=[opt-rare]",
+		Actual:   fmt.Sprintf("capped=%v searched=%v", capped, searched),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "options were not capped and ordered by frequency"
+	}
+	return cr
+}
+
+// caseCollectionMOC covers files.GenerateCollectionMOC (POST /api/metadata/collection/moc): the
+// generated MOC links every file of the collection exactly once, each under the title of its
+// folder, and never lists itself.
+func caseCollectionMOC() test.CaseResult {
+	name := "collection-moc"
+	const collection = "test"
+
+	restore, err := overrideSetting("collectionMocPath", testPath("moc/{{collection}}.moc"))
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	top := testPath("moc-src/top.md")
+	nested := testPath("moc-src/nested/deep.md")
+	for _, rel := range []string{top, nested} {
+		if err := writeFile(rel, "# moc source\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	counts, err := files.GetAllCollections(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	mocPath, err := files.GenerateCollectionMOC(collection)
+	if err != nil {
+		return errCase(name, err)
+	}
+	content, err := contentStorage.ReadFile(pathutils.ToDocsPath(mocPath))
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	// map every listed link to the folder title it appears under
+	listed := make(map[string]string)
+	links, title := 0, ""
+	for line := range strings.Lines(string(content)) {
+		line = strings.TrimSpace(line)
+		if t, ok := strings.CutPrefix(line, "## "); ok {
+			title = t
+		} else if link, ok := strings.CutPrefix(line, "- [["); ok {
+			listed[strings.TrimSuffix(link, "]]")] = title
+			links++
+		}
+	}
+
+	_, listsItself := listed[mocPath]
+	success := mocPath == testPath("moc/test.moc") && !listsItself &&
+		links == counts[collection] && len(listed) == links &&
+		listed[top] == testPath("moc-src") && listed[nested] == testPath("moc-src/nested")
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%d links, %s under %s, %s under %s", counts[collection], top, testPath("moc-src"), nested, testPath("moc-src/nested")),
+		Actual:   fmt.Sprintf("path=%s links=%d top under %q, nested under %q, self listed=%v", mocPath, links, listed[top], listed[nested], listsItself),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "generated moc did not list the collection's files grouped by folder"
+	}
+	return cr
+}
+
+// caseCollectionMOCPathSafe covers the collection name inserted into the MOC path: a real
+// collection name passes unchanged, while names with path separators or dots, which only a
+// crafted request can send, stay a single segment inside the docs folder.
+func caseCollectionMOCPathSafe() test.CaseResult {
+	name := "collection-moc-path-safe"
+
+	restore, err := overrideSetting("collectionMocPath", "{{collection}}/{{collection}}.moc")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var problems []string
+	if got := configmanager.GetCollectionMOCPath("my notes"); got != "my notes/my notes.moc" {
+		problems = append(problems, fmt.Sprintf("my notes -> %s", got))
+	}
+	for _, collection := range []string{"..", "../../etc", `..\..\etc`, "a/../../b"} {
+		mocPath := configmanager.GetCollectionMOCPath(collection)
+		if _, err := pathutils.SafeVaultPath(pathutils.ToWithPrefix(mocPath)); err != nil || len(strings.Split(mocPath, "/")) != 2 {
+			problems = append(problems, fmt.Sprintf("%s -> %s", collection, mocPath))
+		}
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "my notes/my notes.moc, crafted names one segment inside docs",
+		Actual:   fmt.Sprintf("problems: %v", problems),
+		Success:  len(problems) == 0,
+	}
+	if len(problems) > 0 {
+		cr.Error = "collection name escaped its path segment"
+	}
+	return cr
+}
+
+// caseCustomMetadata mirrors handleAPISetMetadataCustom: a custom key/value pair set on one
+// file survives the storage round trip and is matched by a custom.<key> filter, which
+// leaves files without that value out.
+func caseCustomMetadata() test.CaseResult {
+	name := "custom-metadata"
+	withAuthor := testPath("custom-author.md")
+	without := testPath("custom-none.md")
+
+	for _, rel := range []string{withAuthor, without} {
+		if err := writeFile(rel, "# custom\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	metadata, err := files.MetaDataGet(withAuthor)
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", withAuthor, err))
+	}
+	metadata.Custom = map[string]string{"author": "ada", "source": "notebook"}
+	if err := files.MetaDataSave(metadata); err != nil {
+		return errCase(name, err)
+	}
+
+	stored, err := files.MetaDataGet(withAuthor)
+	if err != nil || stored == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", withAuthor, err))
+	}
+
+	config := &filter.Config{
+		Criteria: []filter.Criteria{{Metadata: "custom.author", Operator: "equals", Value: "ada", Action: "include"}},
+		Logic:    "and",
+	}
+	if err := filter.ValidateConfig(config); err != nil {
+		return errCase(name, err)
+	}
+	matches, err := filter.FilterFiles(config.Criteria, config.Logic)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var matched []string
+	for _, f := range matches {
+		matched = append(matched, pathutils.ToRelative(f.Path))
+	}
+
+	success := stored.Custom["author"] == "ada" && stored.Custom["source"] == "notebook" &&
+		slices.Equal(matched, []string{withAuthor})
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("custom=map[author:ada source:notebook], custom.author=ada matches [%s]", withAuthor),
+		Actual:   fmt.Sprintf("custom=%v, matches %v", stored.Custom, matched),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "custom metadata not persisted or not filterable"
+	}
+	return cr
+}
+
+// caseCustomFiletypes covers the custom filetype registry (POST /api/config/filetypes):
+// registered filetypes are valid, files whose type custom field or front matter key names
+// one get its editor on their first save, the textarea editor when it has none, and the
+// type stays filterable as the raw custom field. Editor type names can't be registered.
+func caseCustomFiletypes() test.CaseResult {
+	name := "custom-filetypes"
+
+	restore, err := overrideSetting("customFiletypes", "")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	if err := files.RegisterFiletype("recipe", "Recipe", files.EditorTypeList); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.RegisterFiletype("contact", "Contact", ""); err != nil {
+		return errCase(name, err)
+	}
+	builtinErr := files.RegisterFiletype(string(files.EditorTypeTodo), "Todo", "")
+	unknownEditorErr := files.RegisterFiletype("meeting", "Meeting", "no-such-editor")
+
+	recipe := testPath("filetype-recipe.md")
+	contact := testPath("filetype-contact.md")
+	unknown := testPath("filetype-unknown.md")
+	seeds := map[string]string{
+		recipe:  "---\ntype: recipe\n---\n# recipe\n",
+		contact: "# contact\n",
+		unknown: "# unknown\n",
+	}
+	custom := map[string]map[string]string{
+		contact: {files.FiletypeField: "contact"},
+		unknown: {files.FiletypeField: "unregistered"},
+	}
+	editors := map[string]files.EditorType{}
+	for rel, content := range seeds {
+		if err := writeFile(rel, content); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Custom: custom[rel]}); err != nil {
+			return errCase(name, err)
+		}
+		metadata, err := files.MetaDataGet(rel)
+		if err != nil || metadata == nil {
+			return errCase(name, fmt.Errorf("metadata missing for %s: %v", rel, err))
+		}
+		editors[rel] = metadata.Editor
+	}
+
+	matches, err := filter.FilterFiles([]filter.Criteria{{Metadata: "custom." + files.FiletypeField, Operator: "equals", Value: "recipe", Action: "include"}}, "and")
+	if err != nil {
+		return errCase(name, err)
+	}
+	var matched []string
+	for _, f := range matches {
+		matched = append(matched, pathutils.ToRelative(f.Path))
+	}
+
+	defaultEditor := files.EditorType(configmanager.GetDefaultMarkdownEditor())
+	success := files.IsValidFiletype("recipe") && files.IsValidFiletype("contact") && !files.IsValidFiletype("unregistered") &&
+		editors[recipe] == files.EditorTypeList && editors[contact] == files.EditorTypeTextarea && editors[unknown] == defaultEditor &&
+		errors.Is(builtinErr, files.ErrBuiltinFiletype) && errors.Is(unknownEditorErr, files.ErrInvalidFiletype) &&
+		slices.Equal(matched, []string{recipe})
+	cr := test.CaseResult{
+		Name: name,
+		Expected: fmt.Sprintf("editors recipe=%s contact=%s unregistered=%s, built-in and unknown editor rejected, type=recipe matches [%s]",
+			files.EditorTypeList, files.EditorTypeTextarea, defaultEditor, recipe),
+		Actual: fmt.Sprintf("editors recipe=%s contact=%s unregistered=%s, errors %v / %v, type=recipe matches %v",
+			editors[recipe], editors[contact], editors[unknown], builtinErr, unknownEditorErr, matched),
+		Success: success,
+	}
+	if !success {
+		cr.Error = "custom filetypes were not registered or did not pick the editor of new files"
+	}
+	return cr
+}
+
+// caseImportObsidian covers files.ImportExternalMetadata (POST /api/metadata/import/obsidian):
+// Obsidian front matter and Logseq page properties are mapped onto tags, the kanban status
+// tag, the "aliases" custom field and custom fields for every other property.
+func caseImportObsidian() test.CaseResult {
+	name := "import-obsidian"
+	obsidian := testPath("import-obsidian.md")
+	logseq := testPath("import-logseq.md")
+
+	seeds := map[string]string{
+		obsidian: "---\ntags: [project, \"#reading\"]\nstatus: inbox\naliases:\n  - Ada Notes\n  - AN\nauthor: Ada\nrating: 5\n---\n# Obsidian page\n",
+		logseq:   "tags:: [[logseq]], #imported\nalias:: LS\nsource:: journal\n\n- first block\n",
+	}
+	for rel, content := range seeds {
+		if err := writeFile(rel, content); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: []string{"existing"}}); err != nil {
+			return errCase(name, err)
+		}
+		if _, err := files.ImportExternalMetadata(rel); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	obsidianMeta, err := files.MetaDataGet(obsidian)
+	if err != nil || obsidianMeta == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", obsidian, err))
+	}
+	logseqMeta, err := files.MetaDataGet(logseq)
+	if err != nil || logseqMeta == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", logseq, err))
+	}
+
+	statusTag := configmanager.KanbanStatusTag("inbox")
+	success := slices.Equal(obsidianMeta.Tags, []string{"existing", "project", "reading", statusTag}) &&
+		obsidianMeta.Custom["aliases"] == "Ada Notes, AN" && obsidianMeta.Custom["status"] == "" &&
+		obsidianMeta.Custom["author"] == "Ada" && obsidianMeta.Custom["rating"] == "5" &&
+		slices.Equal(logseqMeta.Tags, []string{"existing", "logseq", "imported"}) &&
+		logseqMeta.Custom["aliases"] == "LS" && logseqMeta.Custom["source"] == "journal"
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("obsidian: tags=[existing project reading %s] custom=map[aliases:Ada Notes, AN author:Ada rating:5], logseq: tags=[existing logseq imported] custom=map[aliases:LS source:journal]", statusTag),
+		Actual:   fmt.Sprintf("obsidian: tags=%v custom=%v, logseq: tags=%v custom=%v", obsidianMeta.Tags, obsidianMeta.Custom, logseqMeta.Tags, logseqMeta.Custom),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "external metadata was not mapped onto tags, the status tag and custom fields"
+	}
+	return cr
+}
+
+// caseArchiveTier covers the archiveTier setting: archiving a file moves its metadata under
+// the archive/ key prefix, where it stays readable but is left out of the tag counts and
+// title search unless archived files are asked for, and unarchiving or turning the setting
+// off moves it back into the main store.
+func caseArchiveTier() test.CaseResult {
+	name := "archive-tier"
+	const tag = "archive-tier-tag"
+	live := testPath("archive-live.md")
+	old := testPath("archive-old.md")
+
+	for _, rel := range []string{live, old} {
+		if err := writeFile(rel, "# archive tier\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	restore, err := overrideSetting("archiveTier", "true")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	archiveTag := configmanager.KanbanStatusTag(configmanager.GetKanbanArchiveStatus())
+	setTags := func(rel string, tags ...string) error {
+		return files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: tags})
+	}
+	tagCount := func(includeArchived bool) (int, error) {
+		counts, err := files.GetAllTags(includeArchived)
+		return counts[tag], err
+	}
+	titleMatches := func(includeArchived bool) (int, error) {
+		results, err := search.SearchFilesByTitle("archive-", 0, includeArchived)
+		return len(results), err
+	}
+	oldKey := pathutils.ToWithPrefix(old)
+	inArchive := func() bool {
+		return !metadataStorage.Exists(oldKey) && metadataStorage.Exists("archive/"+oldKey)
+	}
+
+	if err := setTags(live, tag); err != nil {
+		return errCase(name, err)
+	}
+	if err := setTags(old, tag, archiveTag); err != nil {
+		return errCase(name, err)
+	}
+	// synchronously, the background refresh of the saves could still be running
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	archived := inArchive()
+	readable, err := files.MetaDataGet(old)
+	if err != nil {
+		return errCase(name, err)
+	}
+	readBack := readable != nil && readable.Path == oldKey && slices.Contains(readable.Tags, archiveTag)
+
+	counted, err := tagCount(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	countedAll, err := tagCount(true)
+	if err != nil {
+		return errCase(name, err)
+	}
+	found, err := titleMatches(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	foundAll, err := titleMatches(true)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	// unarchiving moves the metadata back
+	if err := setTags(old, tag); err != nil {
+		return errCase(name, err)
+	}
+	unarchived := !inArchive() && metadataStorage.Exists(oldKey)
+
+	// turning the tier off moves archived metadata back with the migration
+	if err := setTags(old, tag, archiveTag); err != nil {
+		return errCase(name, err)
+	}
+	restore()
+	if _, err := files.MigrateArchiveTier(); err != nil {
+		return errCase(name, err)
+	}
+	migrated := !inArchive() && metadataStorage.Exists(oldKey)
+
+	success := archived && readBack && counted == 1 && countedAll == 2 && found == 1 && foundAll == 2 && unarchived && migrated
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "archived=true readable=true counts=1/2 title matches=1/2 unarchived=true migrated=true",
+		Actual: fmt.Sprintf("archived=%v readable=%v counts=%d/%d title matches=%d/%d unarchived=%v migrated=%v",
+			archived, readBack, counted, countedAll, found, foundAll, unarchived, migrated),
+		Success: success,
+	}
+	if !success {
+		cr.Error = "archived metadata was not kept in the archive tier or not left out of counts and search"
+	}
+	return cr
+}
+
+// caseCollectionIndex covers files.CollectionIndex (GET /api/metadata/collection): files of a
+// collection link to its MOC once the file at the collectionMocPath setting exists, never the
+// MOC itself, and files outside a collection have no index.
+func caseCollectionIndex() test.CaseResult {
+	name := "collection index"
+	restore, err := overrideSetting("collectionMocPath", testPath("collidx/{{collection}}.moc"))
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	note := testPath("collidx/note.md")
+	if err := writeFile(note, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(note)}); err != nil {
+		return errCase(name, err)
+	}
+	metadata, err := files.MetaDataGet(note)
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", note, err))
+	}
+
+	var mismatches []string
+	mocPath := configmanager.GetCollectionMOCPath(metadata.Collection)
+	if index := files.CollectionIndex(metadata.Path); index != "" {
+		mismatches = append(mismatches, fmt.Sprintf("index %q before the moc exists", index))
+	}
+	if err := writeFile(mocPath, "- [["+note+"]]\n"); err != nil {
+		return errCase(name, err)
+	}
+	if index := files.CollectionIndex(metadata.Path); index != mocPath {
+		mismatches = append(mismatches, fmt.Sprintf("index %q, want %q", index, mocPath))
+	}
+	if index := files.CollectionIndex(mocPath); index != "" {
+		mismatches = append(mismatches, fmt.Sprintf("the moc links to itself %q", index))
+	}
+	if index := files.CollectionIndex("metadatatest-root.md"); index != "" {
+		mismatches = append(mismatches, fmt.Sprintf("root file index %q", index))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("no index before the moc exists, then %s, none for the moc itself or a root file", mocPath),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "collection index was not resolved as expected"
+	}
+	return cr
+}
@@ -0,0 +1,149 @@
+package metadatatest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseAgendaFeed covers GET /api/metadata/agenda.ics: files.TargetDateAgenda lists the files
+// with a valid targetDate by date, leaving out private collections unless asked for, and
+// files.AgendaICS renders them as all-day events with CRLF lines folded at 75 octets, closed
+// components and escaped text.
+func caseAgendaFeed() test.CaseResult {
+	name := "agenda feed"
+	folder := testPath("agenda") + "/"
+	notes := map[string]struct{ content, date string }{
+		"later.md":   {"# note\n", "2026-11-01"},
+		"sooner.md":  {"# Review; notes, " + strings.Repeat("long title ", 8) + "\n", "2026-10-20"},
+		"undated.md": {"# note\n", ""},
+		"invalid.md": {"# note\n", "soon"},
+	}
+	for note, seed := range notes {
+		rel := testPath("agenda/" + note)
+		if err := writeFile(rel, seed.content); err != nil {
+			return errCase(name, err)
+		}
+		metadata := &files.Metadata{Path: pathutils.ToWithPrefix(rel)}
+		if seed.date != "" {
+			metadata.Custom = map[string]string{files.TargetDateField: seed.date}
+		}
+		if err := files.MetaDataSave(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	// agenda returns the case's items of the agenda
+	agenda := func(includePrivate bool) ([]files.AgendaItem, error) {
+		items, err := files.TargetDateAgenda(includePrivate)
+		if err != nil {
+			return nil, err
+		}
+		var own []files.AgendaItem
+		for _, item := range items {
+			if strings.HasPrefix(pathutils.ToRelative(item.Path), folder) {
+				own = append(own, item)
+			}
+		}
+		return own, nil
+	}
+
+	var mismatches []string
+	items, err := agenda(false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var paths []string
+	for _, item := range items {
+		paths = append(paths, strings.TrimPrefix(pathutils.ToRelative(item.Path), folder))
+	}
+	if want := []string{"sooner.md", "later.md"}; !slices.Equal(paths, want) {
+		mismatches = append(mismatches, fmt.Sprintf("agenda %v", paths))
+	}
+
+	ics := files.AgendaICS(items, "http://knov.test", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	if !strings.HasSuffix(ics, "\r\n") {
+		mismatches = append(mismatches, "no CRLF line endings")
+	}
+	for line := range strings.SplitSeq(ics, "\r\n") {
+		if len(line) > 75 {
+			mismatches = append(mismatches, fmt.Sprintf("unfolded line %q", line))
+		}
+	}
+
+	// unfold the content lines and check every component is closed
+	var open []string
+	var events []map[string]string
+	for _, line := range strings.Split(strings.TrimSuffix(strings.ReplaceAll(ics, "\r\n ", ""), "\r\n"), "\r\n") {
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "BEGIN":
+			open = append(open, value)
+			if value == "VEVENT" {
+				events = append(events, map[string]string{})
+			}
+		case "END":
+			if len(open) == 0 || open[len(open)-1] != value {
+				mismatches = append(mismatches, fmt.Sprintf("unexpected END:%s in %v", value, open))
+				continue
+			}
+			open = open[:len(open)-1]
+		default:
+			if len(open) > 0 && open[len(open)-1] == "VEVENT" {
+				events[len(events)-1][key] = value
+			}
+		}
+	}
+	if len(open) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("unclosed components %v", open))
+	}
+	var starts []string
+	for _, event := range events {
+		starts = append(starts, event["DTSTART;VALUE=DATE"])
+		if event["UID"] == "" || !strings.HasPrefix(event["URL"], "http://knov.test/files/"+folder) {
+			mismatches = append(mismatches, fmt.Sprintf("incomplete event %v", event))
+		}
+	}
+	if want := []string{"20261020", "20261101"}; !slices.Equal(starts, want) {
+		mismatches = append(mismatches, fmt.Sprintf("event starts %v", starts))
+	}
+	if len(events) > 0 && !strings.HasPrefix(events[0]["SUMMARY"], `Review\; notes\, long title`) {
+		mismatches = append(mismatches, fmt.Sprintf("summary %q", events[0]["SUMMARY"]))
+	}
+
+	restore, err := overrideSetting("privateCollections", files.CollectionFromPath(folder))
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	if private, err := agenda(false); err != nil {
+		return errCase(name, err)
+	} else if len(private) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("private files listed %v", private))
+	}
+	if private, err := agenda(true); err != nil {
+		return errCase(name, err)
+	} else if len(private) != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("%d private files listed when asked for", len(private)))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "sooner.md and later.md as closed all-day events on 20261020 and 20261101 with folded lines and escaped text, left out once private unless asked for",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "agenda feed did not list the target dates as expected"
+	}
+	return cr
+}
@@ -0,0 +1,177 @@
+package metadatatest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseWordCount covers the wordCount and readingTimeMinutes metadata behind
+// GET /api/metadata/wordcount: front matter, markup, link targets and code blocks are not
+// counted, a change made outside the app only shows after files.MetaDataRecountWords (the
+// POST endpoint), and the wordCount filter field compares numerically.
+func caseWordCount() test.CaseResult {
+	name := "word count and reading time"
+	short := testPath("metadata-wordcount/short.md")
+	long := testPath("metadata-wordcount/long.md")
+	content := "---\ntitle: ignored front matter words\n---\n" +
+		"# Reading List\n\n" +
+		"Some **bold** words and a [link text](https://example.com/a/very/long/target) here.\n\n" +
+		"```go\nfunc main() { fmt.Println(\"code is not counted\") }\n```\n\n" +
+		"A [[wiki:target|wiki label]] and [[plain]] plus {{media.png|a caption}}.\n\n" +
+		"| --- | --- |\n"
+	// Reading List / Some bold words and a link text here. / A wiki label and plain plus a caption.
+	const want = 2 + 8 + 8
+
+	for rel, body := range map[string]string{short: content, long: strings.Repeat("word ", 401)} {
+		if err := writeFile(rel, body); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	counts := func(rel string) string {
+		metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+		if err != nil || metadata == nil {
+			return fmt.Sprintf("no metadata (%v)", err)
+		}
+		return fmt.Sprintf("%d words in %d min", metadata.WordCount, metadata.ReadingTimeMinutes)
+	}
+	shortCount, longCount := counts(short), counts(long)
+
+	// editing the file outside the app only changes the count after a recount
+	if err := os.WriteFile(pathutils.ToDocsPath(long), []byte("just three words"), 0644); err != nil {
+		return errCase(name, err)
+	}
+	if _, err := files.MetaDataRecountWords(long); err != nil {
+		return errCase(name, err)
+	}
+	recounted := counts(long)
+
+	criteria := []filter.Criteria{
+		{Metadata: "folders", Operator: "equals", Value: "metadata-wordcount", Action: "include"},
+		{Metadata: "wordCount", Operator: "greater", Value: "10", Action: "include"},
+	}
+	for _, c := range criteria {
+		if err := filter.ValidateCriterion(c); err != nil {
+			return errCase(name, err)
+		}
+	}
+	matches, err := filter.FilterFiles(criteria, "and")
+	if err != nil {
+		return errCase(name, err)
+	}
+	var matched []string
+	for _, f := range matches {
+		matched = append(matched, filepath.Base(f.Path))
+	}
+	nonNumeric := filter.ValidateCriterion(filter.Criteria{Metadata: "wordCount", Operator: "greater", Value: "many", Action: "include"})
+
+	wantShort := fmt.Sprintf("%d words in 1 min", want)
+	success := shortCount == wantShort && longCount == "401 words in 3 min" && recounted == "3 words in 1 min" &&
+		len(matched) == 1 && matched[0] == "short.md" && nonNumeric != nil
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("short %s, long 401 words in 3 min then 3 words in 1 min, more than 10 words [short.md], non-numeric rejected", wantShort),
+		Actual:   fmt.Sprintf("short %s, long %s then %s, more than 10 words %v, non-numeric error %v", shortCount, longCount, recounted, matched, nonNumeric),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "words were not counted as expected or the wordCount filter field did not compare numerically"
+	}
+	return cr
+}
+
+// caseFrontMatterSync covers the front matter sync of files.MetaDataSave with the
+// frontMatterWins setting: a new file takes its tags, status and custom fields from the
+// front matter without Obsidian's tag and link markup, stored values stay while the
+// database is authoritative and are replaced once the front matter is, and malformed yaml
+// leaves the stored metadata alone. The collection is derived from the folder, so only an
+// authoritative front matter overrides it.
+func caseFrontMatterSync() test.CaseResult {
+	name := "front matter sync"
+	rel := testPath("frontmatter/note.md")
+	restore, err := overrideSetting("frontMatterWins", "false")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	save := func(frontMatter string, tags []string) (*files.Metadata, error) {
+		if err := writeFile(rel, "---\n"+frontMatter+"\n---\n# note\n"); err != nil {
+			return nil, err
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: tags}); err != nil {
+			return nil, err
+		}
+		metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+		if err != nil || metadata == nil {
+			return nil, fmt.Errorf("metadata missing for %s: %v", rel, err)
+		}
+		return metadata, nil
+	}
+	describe := func(m *files.Metadata) string {
+		return fmt.Sprintf("tags %v, collection %s, priority %s, type %s, targetDate %s",
+			m.Tags, m.Collection, m.Custom["priority"], m.Custom["type"], m.Custom[files.TargetDateField])
+	}
+	statusTag := configmanager.KanbanStatusTag
+
+	var mismatches []string
+	// an empty collection isn't checked
+	check := func(step string, m *files.Metadata, tags []string, collection, priority string) {
+		if !slices.Equal(m.Tags, tags) || (collection != "" && m.Collection != collection) || m.Custom["priority"] != priority {
+			mismatches = append(mismatches, step+": "+describe(m))
+		}
+	}
+
+	created, err := save("tags: [alpha, \"[[beta]]\", \"#alpha\"]\nstatus: inbox\npriority: 2\ntype: draft\ncollection: reading\ntargetDate: 2026-12-31", nil)
+	if err != nil {
+		return errCase(name, err)
+	}
+	check("new file", created, []string{"alpha", "beta", statusTag("inbox")}, "test", "2")
+	if created.Custom["type"] != "draft" || created.Custom[files.TargetDateField] != "2026-12-31" {
+		mismatches = append(mismatches, "new file: "+describe(created))
+	}
+
+	databaseWins, err := save("tags: gamma\nstatus: blocked\npriority: 5\ncollection: other", nil)
+	if err != nil {
+		return errCase(name, err)
+	}
+	check("database wins", databaseWins, []string{"alpha", "beta", statusTag("inbox")}, "test", "2")
+
+	if err := configmanager.FrontMatterWins.SetFromString("true"); err != nil {
+		return errCase(name, err)
+	}
+	frontMatterWins, err := save("tags: gamma\nstatus: blocked\npriority: 5\ncollection: other", []string{"passed"})
+	if err != nil {
+		return errCase(name, err)
+	}
+	check("front matter wins", frontMatterWins, []string{"gamma", statusTag("blocked")}, "other", "5")
+
+	malformed, err := save("tags: [unclosed\npriority: 9", nil)
+	if err != nil {
+		return errCase(name, err)
+	}
+	check("malformed front matter", malformed, []string{"gamma", statusTag("blocked")}, "", "5")
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "front matter taken for a new file, kept while the database wins, replaced once the front matter wins, malformed yaml ignored",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the front matter was not synced into the metadata as configured"
+	}
+	return cr
+}
@@ -0,0 +1,313 @@
+package metadatatest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseMetadataPreview checks files.MetaDataPreview (the preview endpoint): the posted fields
+// are merged onto the stored metadata and the auto fields filled in, without saving anything.
+func caseMetadataPreview() test.CaseResult {
+	name := "metadata preview merges without saving"
+	rel := testPath("preview.md")
+	if err := writeFile(rel, "# Preview Note\n"); err != nil {
+		return errCase(name, err)
+	}
+	metadataPath := pathutils.ToWithPrefix(rel)
+	createdAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := files.MetaDataSave(&files.Metadata{
+		Path: metadataPath, Tags: []string{"old"}, Editor: files.EditorTypeTextarea,
+		CreatedAt: createdAt, Custom: map[string]string{"owner": "me"},
+	}); err != nil {
+		return errCase(name, err)
+	}
+
+	preview := files.MetaDataPreview(&files.Metadata{Path: metadataPath, Tags: []string{"new"}})
+	stored, err := files.MetaDataGet(metadataPath)
+	if err != nil || stored == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", rel, err))
+	}
+
+	wantFolders := strings.Split(files.FolderFromPath(rel), "/")
+	var problems []string
+	if !slices.Equal(preview.Tags, []string{"new"}) {
+		problems = append(problems, fmt.Sprintf("previewed tags %v", preview.Tags))
+	}
+	if preview.Collection != files.CollectionFromPath(rel) || !slices.Equal(preview.Folders, wantFolders) {
+		problems = append(problems, fmt.Sprintf("collection %q folders %v", preview.Collection, preview.Folders))
+	}
+	if preview.Editor != files.EditorTypeTextarea || !preview.CreatedAt.Equal(createdAt) || preview.Custom["owner"] != "me" {
+		problems = append(problems, fmt.Sprintf("editor %s createdAt %v custom %v", preview.Editor, preview.CreatedAt, preview.Custom))
+	}
+	if preview.Title != "Preview Note" || preview.Size == 0 {
+		problems = append(problems, fmt.Sprintf("title %q size %d", preview.Title, preview.Size))
+	}
+	if !slices.Equal(stored.Tags, []string{"old"}) {
+		problems = append(problems, fmt.Sprintf("stored tags %v", stored.Tags))
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "tags [new] previewed, stored fields kept, title and size filled, stored tags still [old]",
+		Actual:   fmt.Sprintf("problems: %v", problems),
+		Success:  len(problems) == 0,
+	}
+	if !cr.Success {
+		cr.Error = "the preview did not merge the posted fields or saved them"
+	}
+	return cr
+}
+
+// caseMetadataHistory covers files.GetMetadataHistory (GET /api/metadata/history): creating
+// the metadata and raw saves (as link rebuilds do) record nothing, tag, custom and label
+// changes are recorded newest first, the metadataHistoryLimit setting caps the history and
+// at 0 records nothing, and deleting the metadata drops its history. The metadata of the
+// previous run is deleted first, so the file starts without any.
+func caseMetadataHistory() test.CaseResult {
+	name := "metadata history"
+	rel := testPath("history/note.md")
+	path := pathutils.ToWithPrefix(rel)
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataDeleteNoRefresh(logging.KeyApp, path); err != nil {
+		return errCase(name, err)
+	}
+	restore, err := overrideSetting("metadataHistoryLimit", "100")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	save := func(m *files.Metadata) {
+		m.Path = path
+		if err := files.MetaDataSaveNoRefresh(m); err != nil {
+			mismatches = append(mismatches, err.Error())
+		}
+	}
+	history := func() []string {
+		changes, err := files.GetMetadataHistory(rel)
+		if err != nil {
+			mismatches = append(mismatches, err.Error())
+		}
+		var out []string
+		for _, c := range changes {
+			if c.Path != path || c.Timestamp.IsZero() {
+				mismatches = append(mismatches, fmt.Sprintf("change %+v", c))
+			}
+			out = append(out, fmt.Sprintf("%s: %q -> %q", c.Field, c.OldValue, c.NewValue))
+		}
+		return out
+	}
+
+	// creating the metadata records nothing, the first tag change does
+	save(&files.Metadata{Tags: []string{"draft"}})
+	if got := history(); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("history of a new file %v", got))
+	}
+	save(&files.Metadata{Tags: []string{"draft", "review"}, Custom: map[string]string{"priority": "high"}})
+	metadata, err := files.MetaDataGet(path)
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing after save: %v", err))
+	}
+	metadata.UsedLinks = []string{"docs/elsewhere.md"}
+	metadata.Tags = []string{"raw"}
+	if err := files.MetaDataSaveRaw(metadata); err != nil {
+		return errCase(name, err)
+	}
+	if _, err := files.MetaDataSetLabel(path, "red"); err != nil {
+		return errCase(name, err)
+	}
+	want := []string{`label: "" -> "red"`, `tags: "draft" -> "draft, review"`, `custom.priority: "" -> "high"`}
+	if got := history(); !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("history %q", got))
+	}
+
+	// only the newest changes are kept
+	if err := configmanager.MetadataHistoryLimit.SetFromString("2"); err != nil {
+		return errCase(name, err)
+	}
+	save(&files.Metadata{Tags: []string{"done"}})
+	capped := []string{`tags: "raw" -> "done"`, `label: "" -> "red"`}
+	if got := history(); !slices.Equal(got, capped) {
+		mismatches = append(mismatches, fmt.Sprintf("capped history %q", got))
+	}
+	if err := configmanager.MetadataHistoryLimit.SetFromString("0"); err != nil {
+		return errCase(name, err)
+	}
+	save(&files.Metadata{Tags: []string{"ignored"}})
+	if got := history(); !slices.Equal(got, capped) {
+		mismatches = append(mismatches, fmt.Sprintf("history at limit 0 %q", got))
+	}
+
+	if err := files.MetaDataDeleteNoRefresh(logging.KeyApp, path); err != nil {
+		return errCase(name, err)
+	}
+	if got := history(); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("history after delete %q", got))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("history %q, capped to %q at limit 2, unchanged at 0, empty after delete", want, capped),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata changes were not recorded as expected"
+	}
+	return cr
+}
+
+// caseBulkSet checks files.MetaDataBulkSet (POST /api/metadata/bulk): duplicate paths are
+// applied once, a missing file fails alone, the status replaces the kanban tag only, empty
+// custom values remove the key, unset fields stay untouched, and MetadataChanges rejects
+// empty changes, an unknown status and an unknown editor.
+func caseBulkSet() test.CaseResult {
+	name := "bulk set metadata"
+	a, b := testPath("bulkset/a.md"), testPath("bulkset/b.md")
+	for _, f := range []struct {
+		relPath string
+		tags    []string
+		custom  map[string]string
+	}{
+		{a, []string{"alpha", configmanager.KanbanStatusTag("inbox")}, map[string]string{"type": "note", "old": "x"}},
+		{b, []string{"beta"}, nil},
+	} {
+		if err := writeFile(f.relPath, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		// start from the seeded fields, not the changes of the previous run
+		path := pathutils.ToWithPrefix(f.relPath)
+		if err := files.MetaDataDeleteNoRefresh(logging.KeyApp, path); err != nil {
+			return errCase(name, err)
+		}
+		metadata := &files.Metadata{Path: path, Tags: f.tags, Custom: f.custom, Editor: files.EditorTypeTextarea}
+		if err := files.MetaDataSaveNoRefresh(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	results := files.MetaDataBulkSet(
+		[]string{a, pathutils.ToWithPrefix(b), testPath("bulkset/missing.md"), a},
+		files.MetadataChanges{Status: "inprogress", Label: "green", Custom: map[string]string{"type": "paper", "old": ""}},
+	)
+	want := []files.BulkSetResult{
+		{Path: pathutils.ToWithPrefix(a), Success: true},
+		{Path: pathutils.ToWithPrefix(b), Success: true},
+		{Path: pathutils.ToWithPrefix(testPath("bulkset/missing.md")), Success: false, Error: "metadata not found"},
+	}
+	if !slices.Equal(results, want) {
+		mismatches = append(mismatches, fmt.Sprintf("results %+v", results))
+	}
+
+	inprogress := configmanager.KanbanStatusTag("inprogress")
+	if m, err := files.MetaDataGet(a); err != nil || m == nil {
+		mismatches = append(mismatches, fmt.Sprintf("a: metadata missing: %v", err))
+	} else if !slices.Equal(m.Tags, []string{"alpha", inprogress}) || !maps.Equal(m.Custom, map[string]string{"type": "paper"}) ||
+		m.Label != "green" || m.Editor != files.EditorTypeTextarea {
+		mismatches = append(mismatches, fmt.Sprintf("a: tags %v custom %v label %q editor %q", m.Tags, m.Custom, m.Label, m.Editor))
+	}
+	if m, err := files.MetaDataGet(b); err != nil || m == nil {
+		mismatches = append(mismatches, fmt.Sprintf("b: metadata missing: %v", err))
+	} else if !slices.Equal(m.Tags, []string{"beta", inprogress}) || m.Custom["type"] != "paper" || m.Label != "green" {
+		mismatches = append(mismatches, fmt.Sprintf("b: tags %v custom %v label %q", m.Tags, m.Custom, m.Label))
+	}
+
+	if !(files.MetadataChanges{}).IsEmpty() {
+		mismatches = append(mismatches, "empty changes not reported empty")
+	}
+	for label, changes := range map[string]files.MetadataChanges{
+		"invalid status": {Status: "nonsense"},
+		"invalid editor": {Editor: "nonsense"},
+	} {
+		if changes.Validate() == nil {
+			mismatches = append(mismatches, label+" accepted")
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "a and b updated once, missing.md not found, status tag replaced, custom old removed, editor kept, empty changes and unknown status or editor rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "bulk set did not apply the changes as expected"
+	}
+	return cr
+}
+
+// caseStrictMetadataJSON runs posted metadata through what the POST /api/metadata handler
+// calls: files.DecodeMetadataJSON rejects an unknown field and, like the handler,
+// files.ValidateMetadataEnums an unknown editor or kanban tag while strictMetadataJSON is on.
+// With the setting off the unknown field is ignored.
+func caseStrictMetadataJSON() test.CaseResult {
+	name := "strict metadata json"
+	path := pathutils.ToWithPrefix(testPath("strict.md"))
+
+	validate := func(body string) error {
+		metadata, err := files.DecodeMetadataJSON(strings.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if configmanager.GetStrictMetadataJSON() {
+			return files.ValidateMetadataEnums(metadata)
+		}
+		return nil
+	}
+
+	restore, err := overrideSetting("strictMetadataJSON", "true")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	for body, want := range map[string]string{
+		`{"path":"` + path + `","statu":"x"}`:                "statu",
+		`{"path":"` + path + `","editor":"wordpad"}`:         "wordpad",
+		`{"path":"` + path + `","tags":["kb-status-bogus"]}`: "kb-status-bogus",
+	} {
+		if err := validate(body); err == nil || !strings.Contains(err.Error(), want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", body, err))
+		}
+	}
+	valid := `{"path":"` + path + `","editor":"textarea-editor","tags":["x"]}`
+	if err := validate(valid); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("%s: %v", valid, err))
+	}
+
+	if err := configmanager.StrictMetadataJSON.SetFromString("false"); err != nil {
+		return errCase(name, err)
+	}
+	lenient := `{"path":"` + path + `","statu":"x"}`
+	if err := validate(lenient); err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("%s without strict mode: %v", lenient, err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "unknown field, editor and kanban tag rejected naming the value, valid metadata and unknown fields without strict mode accepted",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "posted metadata was not validated as expected"
+	}
+	return cr
+}
@@ -0,0 +1,59 @@
+package metadatatest
+
+import (
+	"fmt"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/test"
+)
+
+// caseFiletypeIcons covers files.EditorTypeIcons (GET /api/metadata/filetype/icons) with the
+// filetypeIcons setting: every editor type has a default icon, configured entries replace
+// it, unknown editor types are ignored and invalid entries are rejected without changing
+// the configured icons.
+func caseFiletypeIcons() test.CaseResult {
+	name := "filetype icons"
+
+	restore, err := overrideSetting("filetypeIcons", "")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	defaults := files.EditorTypeIcons()
+	var missing []files.EditorType
+	for _, editor := range files.AllEditorTypes() {
+		if defaults[editor] == "" {
+			missing = append(missing, editor)
+		}
+	}
+
+	if err := configmanager.FiletypeIcons.SetFromString("todo-editor=fa-square-check, unknown-editor=fa-star"); err != nil {
+		return errCase(name, err)
+	}
+	configured := files.EditorTypeIcons()
+	_, unknownKept := configured["unknown-editor"]
+
+	var accepted []string
+	for _, invalid := range []string{"todo-editor=<script>", "todo-editor", "=fa-star", "todo-editor=fa-"} {
+		if configmanager.FiletypeIcons.SetFromString(invalid) == nil {
+			accepted = append(accepted, invalid)
+		}
+	}
+	afterInvalid := files.EditorTypeIcons()[files.EditorTypeTodo]
+
+	success := len(missing) == 0 && defaults[files.EditorTypeTodo] == "fa-list-check" &&
+		configured[files.EditorTypeTodo] == "fa-square-check" && configured[files.EditorTypeList] == "fa-list" &&
+		!unknownKept && len(accepted) == 0 && afterInvalid == "fa-square-check"
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "a default icon per editor type, todo fa-list-check then fa-square-check, list fa-list, unknown editor ignored, invalid entries rejected",
+		Actual: fmt.Sprintf("missing defaults %v, todo %s then %s, list %s, unknown editor kept %v, invalid accepted %v, todo after invalid %s",
+			missing, defaults[files.EditorTypeTodo], configured[files.EditorTypeTodo], configured[files.EditorTypeList], unknownKept, accepted, afterInvalid),
+		Success: success,
+	}
+	if !success {
+		cr.Error = "filetype icons did not fall back to the defaults or accepted an invalid entry"
+	}
+	return cr
+}
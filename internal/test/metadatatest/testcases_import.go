@@ -0,0 +1,116 @@
+package metadatatest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseMetadataImport covers POST /api/metadata/import: files.ParseMetadataImport reads a CSV
+// in the layout of files.MetadataCSV or a JSON array, and files.MetaDataImport skips rows
+// without a path or for missing files. The case's own rows of the export import again without
+// changes, and blank data, unknown or missing columns and broken json are rejected.
+func caseMetadataImport() test.CaseResult {
+	name := "import metadata"
+	first, second := pathutils.ToWithPrefix(testPath("import/first.md")), pathutils.ToWithPrefix(testPath("import/second.md"))
+	for path, tags := range map[string][]string{first: {"old"}, second: nil} {
+		if err := writeFile(pathutils.ToRelative(path), "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		// start from the seeded fields, not the import of the previous run
+		if err := files.MetaDataDeleteNoRefresh(logging.KeyApp, path); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: path, Tags: tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	importData := func(label, data string) files.MetadataImportSummary {
+		rows, err := files.ParseMetadataImport([]byte(data))
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", label, err))
+			return files.MetadataImportSummary{}
+		}
+		return files.MetaDataImport(rows)
+	}
+
+	// a CSV in the export's layout, with a missing file and a row without a path
+	createdAt := time.Date(2024, 3, 1, 10, 30, 0, 0, configmanager.GetTimezone())
+	summary := importData("csv", files.MetadataCSV([]*files.Metadata{
+		{Path: first, Tags: []string{"devops", "imported"}, CreatedAt: createdAt},
+		{Path: pathutils.ToWithPrefix(testPath("import/missing.md")), Tags: []string{"devops"}},
+		{Path: "", Tags: []string{"devops"}},
+	}))
+	if summary.Imported != 1 || summary.Skipped != 2 || len(summary.Errors) != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("csv summary %+v", summary))
+	}
+	if m, err := files.MetaDataGet(first); err != nil || m == nil {
+		mismatches = append(mismatches, fmt.Sprintf("first.md metadata missing: %v", err))
+	} else if !slices.Equal(m.Tags, []string{"devops", "imported"}) || !m.CreatedAt.Equal(createdAt) {
+		mismatches = append(mismatches, fmt.Sprintf("first.md tags %v created %v", m.Tags, m.CreatedAt))
+	}
+	if m, _ := files.MetaDataGet(testPath("import/missing.md")); m != nil {
+		mismatches = append(mismatches, "metadata created for missing.md")
+	}
+
+	// the case's rows of an unchanged export import cleanly
+	all, err := files.MetaDataExportAll()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var exported []*files.Metadata
+	for _, m := range all {
+		if strings.HasPrefix(m.Path, pathutils.ToWithPrefix(testPath("import"))+"/") {
+			exported = append(exported, m)
+		}
+	}
+	if summary := importData("export", files.MetadataCSV(exported)); summary.Imported != 2 || summary.Skipped != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("export summary %+v", summary))
+	}
+	if m, _ := files.MetaDataGet(first); m == nil || !slices.Equal(m.Tags, []string{"devops", "imported"}) {
+		mismatches = append(mismatches, fmt.Sprintf("round trip metadata %+v", m))
+	}
+
+	// a JSON array
+	if summary := importData("json", `[{"path": "`+testPath("import/second.md")+`", "tags": ["fromjson"]}]`); summary.Imported != 1 {
+		mismatches = append(mismatches, fmt.Sprintf("json summary %+v", summary))
+	}
+	if m, _ := files.MetaDataGet(second); m == nil || !slices.Equal(m.Tags, []string{"fromjson"}) {
+		mismatches = append(mismatches, fmt.Sprintf("json metadata %+v", m))
+	}
+
+	if _, err := files.ParseMetadataImport([]byte("  ")); !errors.Is(err, files.ErrEmptyImport) {
+		mismatches = append(mismatches, fmt.Sprintf("blank import: %v", err))
+	}
+	for label, data := range map[string]string{
+		"unknown column": "path,color\n" + first + ",red\n",
+		"no path column": "title,tags\nfirst,a\n",
+		"invalid json":   `[{"path": `,
+	} {
+		if _, err := files.ParseMetadataImport([]byte(data)); err == nil {
+			mismatches = append(mismatches, label+" accepted")
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "csv imports first.md and skips 2 rows, the export imports again unchanged, json imports second.md, blank data, unknown or missing columns and broken json rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata was not imported as expected"
+	}
+	return cr
+}
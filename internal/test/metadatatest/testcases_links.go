@@ -0,0 +1,365 @@
+package metadatatest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseLinkGraph covers files.BuildLinkGraph and LinkGraph.Subgraph (GET /api/links/graph):
+// links and parents become edges, a link to a missing file a missing node, and a subgraph
+// keeps the nodes within depth hops of its root, ending the walk at the parent cycle of b
+// and c. An unknown root is an error.
+func caseLinkGraph() test.CaseResult {
+	name := "link graph"
+	a, b, c, d := testPath("graph/a.md"), testPath("graph/b.md"), testPath("graph/c.md"), testPath("graph/d.md")
+
+	// a links to b, b and c are each other's parents (a cycle), d is only linked from c
+	seeds := []struct {
+		rel, content string
+		parents      []string
+	}{
+		{a, fmt.Sprintf("# Note A\n\n[b](%s) and [gone](%s)\n", b, testPath("graph/missing.md")), nil},
+		{b, "# Note B\n", []string{pathutils.ToWithPrefix(c)}},
+		{c, fmt.Sprintf("# Note C\n\n[d](%s)\n", d), []string{pathutils.ToWithPrefix(b)}},
+		{d, "# Note D\n", nil},
+	}
+	for _, seed := range seeds {
+		if err := writeFile(seed.rel, seed.content); err != nil {
+			return errCase(name, err)
+		}
+	}
+	for _, seed := range seeds {
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(seed.rel), Parents: seed.parents}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	graph, err := files.BuildLinkGraph()
+	if err != nil {
+		return errCase(name, err)
+	}
+	folder := pathutils.ToWithPrefix(testPath("graph")) + "/"
+	var edges []string
+	for _, e := range graph.Edges {
+		if strings.HasPrefix(e.Source, folder) {
+			edges = append(edges, fmt.Sprintf("%s %s %s", filepath.Base(e.Source), e.Relation, filepath.Base(e.Target)))
+		}
+	}
+	nodes := make(map[string]files.GraphNode)
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	nodeA, nodeMissing := nodes[folder+"a.md"], nodes[folder+"missing.md"]
+
+	var mismatches []string
+	wantEdges := []string{"a.md link b.md", "a.md link missing.md", "b.md parent c.md", "c.md parent b.md", "c.md link d.md"}
+	if !slices.Equal(edges, wantEdges) {
+		mismatches = append(mismatches, fmt.Sprintf("edges %v", edges))
+	}
+	if nodeA.Title != "Note A" || nodeA.Type != files.GraphNodeDoc {
+		mismatches = append(mismatches, fmt.Sprintf("a.md node %+v", nodeA))
+	}
+	if nodeMissing.Type != files.GraphNodeMissing {
+		mismatches = append(mismatches, fmt.Sprintf("missing.md node %+v", nodeMissing))
+	}
+	for _, tc := range []struct {
+		root  string
+		depth int
+		want  []string
+	}{
+		{b, 1, []string{"a.md", "b.md", "c.md"}},
+		{b, 2, []string{"a.md", "b.md", "c.md", "d.md", "missing.md"}},
+		{c, 0, []string{"c.md"}},
+		{d, 10, []string{"a.md", "b.md", "c.md", "d.md", "missing.md"}},
+	} {
+		sub, err := graph.Subgraph(tc.root, tc.depth)
+		if err != nil {
+			return errCase(name, err)
+		}
+		var ids []string
+		for _, n := range sub.Nodes {
+			ids = append(ids, filepath.Base(n.ID))
+		}
+		if !slices.Equal(ids, tc.want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s depth %d: nodes %v", filepath.Base(tc.root), tc.depth, ids))
+		}
+	}
+	if _, err := graph.Subgraph(testPath("graph/nope.md"), 1); err == nil {
+		mismatches = append(mismatches, "unknown root accepted")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("edges %v, a.md titled Note A, missing.md a missing node, subgraphs within depth, unknown root rejected", wantEdges),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the link graph or a subgraph did not have the expected nodes and edges"
+	}
+	return cr
+}
+
+// caseCreateMissingParents covers the createMissingParents setting: off, a parent that
+// doesn't exist is only recorded; on, saving the metadata creates it as an empty note
+// listing the child, but never outside the data folder.
+func caseCreateMissingParents() test.CaseResult {
+	name := "create missing parents"
+	child, other := testPath("stub/child.md"), testPath("stub/other.md")
+	absent, parent := testPath("stub/absent.md"), testPath("stub/sub/parent.md")
+	for _, rel := range []string{child, other} {
+		if err := writeFile(rel, "# child\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	key := pathutils.ToWithPrefix
+
+	restore, err := overrideSetting("createMissingParents", "false")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var mismatches []string
+	if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: key(other), Parents: []string{key(absent)}}); err != nil {
+		return errCase(name, err)
+	}
+	if _, err := os.Stat(pathutils.ToDocsPath(absent)); !os.IsNotExist(err) {
+		mismatches = append(mismatches, fmt.Sprintf("parent created with the setting off: %v", err))
+	}
+
+	if err := configmanager.CreateMissingParents.SetFromString("true"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: key(child), Parents: []string{key(parent)}}); err != nil {
+		return errCase(name, err)
+	}
+	if content, err := os.ReadFile(pathutils.ToDocsPath(parent)); err != nil || len(content) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("parent note %q, %v", content, err))
+	}
+	if stored, _ := files.MetaDataGet(key(parent)); stored == nil || !slices.Equal(stored.Kids, []string{key(child)}) || stored.CreatedAt.IsZero() ||
+		stored.Collection != files.CollectionFromPath(parent) {
+		mismatches = append(mismatches, fmt.Sprintf("parent metadata %+v", stored))
+	}
+	if stored, _ := files.MetaDataGet(key(child)); stored == nil || !slices.Equal(stored.Parents, []string{key(parent)}) {
+		mismatches = append(mismatches, fmt.Sprintf("child metadata %+v", stored))
+	}
+
+	// parents outside the data folder are never created
+	escaped := filepath.Join(filepath.Dir(configmanager.GetAppConfig().DataPath), "escaped.md")
+	if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: key(other), Parents: []string{"../../escaped.md"}}); err != nil {
+		return errCase(name, err)
+	}
+	if _, err := os.Stat(escaped); !os.IsNotExist(err) {
+		mismatches = append(mismatches, fmt.Sprintf("parent created outside the data folder: %v", err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "no parent note with the setting off, an empty parent note listing the child with it on, none outside the data folder",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "missing parents were not created as expected"
+	}
+	return cr
+}
+
+// caseLinkGraphDOT covers LinkGraph.DOT (GET /api/links/graph?format=dot): one balanced
+// digraph whose statements are nodes labelled with their escaped titles and edges between
+// quoted ids, with missing targets and parent edges dashed.
+func caseLinkGraphDOT() test.CaseResult {
+	name := "link graph dot"
+	a, b, missing := testPath("dot/a.md"), testPath("dot/b.md"), testPath("dot/missing.md")
+	for rel, content := range map[string]string{
+		a: fmt.Sprintf("# Say \"Hi\" \\o/\n\n[b](%s) and [gone](%s)\n", b, missing),
+		b: "# Note B\n",
+	} {
+		if err := writeFile(rel, content); err != nil {
+			return errCase(name, err)
+		}
+	}
+	key := pathutils.ToWithPrefix
+	for rel, parents := range map[string][]string{a: nil, b: {key(a)}} {
+		if err := files.MetaDataSave(&files.Metadata{Path: key(rel), Parents: parents}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	graph, err := files.BuildLinkGraph()
+	if err == nil {
+		graph, err = graph.Subgraph(a, 1)
+	}
+	if err != nil {
+		return errCase(name, err)
+	}
+	dot := graph.DOT()
+
+	var mismatches []string
+	if !strings.HasPrefix(dot, "digraph links {\n") || !strings.HasSuffix(dot, "}\n") {
+		mismatches = append(mismatches, "not a digraph")
+	}
+	if open, closed := strings.Count(dot, "{"), strings.Count(dot, "}"); open != 1 || closed != 1 {
+		mismatches = append(mismatches, fmt.Sprintf("%d { and %d }", open, closed))
+	}
+	quote := func(rel string) string { return `"` + key(rel) + `"` }
+	for _, want := range []string{
+		quote(a) + ` [label="Say \"Hi\" \\o/"];`,
+		quote(b) + ` [label="Note B"];`,
+		quote(missing) + ` [label="missing.md", style=dashed];`,
+		quote(a) + ` -> ` + quote(b) + `;`,
+		quote(a) + ` -> ` + quote(missing) + `;`,
+		quote(b) + ` -> ` + quote(a) + ` [style=dashed];`,
+	} {
+		if !strings.Contains(dot, "  "+want+"\n") {
+			mismatches = append(mismatches, "missing line "+want)
+		}
+	}
+
+	// every statement between the braces is a node or an edge between quoted ids
+	quoted := `"(?:[^"\\]|\\.)*"`
+	statement := regexp.MustCompile(`^  ` + quoted + `(?: -> ` + quoted + `)?(?: \[[^\]]*\])?;$`)
+	lines := strings.Split(strings.TrimSuffix(dot, "\n"), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		if !statement.MatchString(line) {
+			mismatches = append(mismatches, "invalid statement "+line)
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "a balanced digraph with escaped labels, dashed missing node and parent edge, only node and edge statements",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the link graph was not written as valid DOT"
+	}
+	return cr
+}
+
+// caseHierarchyProblems covers the ancestor computation of files.MetaDataLinksRebuild and
+// files.GetHierarchyProblems (GET /api/links/hierarchy/problems): a parent cycle terminates
+// and reports both files, a chain past the maxHierarchyDepth setting reports its deepest file
+// and the ancestors within the depth are kept. Fixing the cycle clears it on the next rebuild.
+// The rebuild covers the whole vault, so only the problems of the case's files are compared,
+// and it is run again with the previous depth at the end.
+func caseHierarchyProblems() test.CaseResult {
+	name := "hierarchy problems"
+	restore, err := overrideSetting("maxHierarchyDepth", "2")
+	defer func() {
+		restore()
+		if err := files.MetaDataLinksRebuild(logging.KeyApp); err != nil {
+			logging.LogWarning(logging.KeyApp, "failed to rebuild links after the hierarchy case: %v", err)
+		}
+	}()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	path := func(note string) string { return pathutils.ToWithPrefix(testPath("hierarchy/" + note + ".md")) }
+	parents := map[string]string{"a": "b", "b": "a", "c1": "", "c2": "c1", "c3": "c2", "c4": "c3"}
+	for note := range parents {
+		if err := writeFile(pathutils.ToRelative(path(note)), "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: path(note)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	setParents := func(note string, parents ...string) error {
+		metadata, err := files.MetaDataGet(path(note))
+		if err != nil || metadata == nil {
+			return fmt.Errorf("metadata missing for %s: %v", note, err)
+		}
+		metadata.Parents = parents
+		return files.MetaDataSaveRaw(metadata)
+	}
+	for note, parent := range parents {
+		if parent != "" {
+			if err := setParents(note, path(parent)); err != nil {
+				return errCase(name, err)
+			}
+		}
+	}
+
+	// problems rebuilds the links and returns the problems of the case's files by note
+	problems := func() (map[string]files.HierarchyProblem, error) {
+		done := make(chan error, 1)
+		go func() { done <- files.MetaDataLinksRebuild(logging.KeyApp) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				return nil, err
+			}
+		case <-time.After(10 * time.Second):
+			return nil, fmt.Errorf("ancestor computation did not terminate")
+		}
+		byNote := map[string]files.HierarchyProblem{}
+		for _, problem := range files.GetHierarchyProblems() {
+			if rest, ok := strings.CutPrefix(problem.Path, pathutils.ToWithPrefix(testPath("hierarchy"))+"/"); ok {
+				byNote[strings.TrimSuffix(rest, ".md")] = problem
+			}
+		}
+		return byNote, nil
+	}
+
+	var mismatches []string
+	got, err := problems()
+	if err != nil {
+		return errCase(name, err)
+	}
+	for _, note := range []string{"a", "b"} {
+		if got[note].Kind != files.HierarchyCycle {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %+v", note, got[note]))
+		}
+	}
+	if problem := got["c4"]; problem.Kind != files.HierarchyTooDeep || len(problem.Chain) != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("c4: %+v", problem))
+	}
+	if len(got) != 3 {
+		mismatches = append(mismatches, fmt.Sprintf("%d problems", len(got)))
+	}
+	if metadata, _ := files.MetaDataGet(path("c3")); metadata == nil || !slices.Equal(metadata.Ancestor, []string{path("c1")}) {
+		mismatches = append(mismatches, fmt.Sprintf("c3 ancestors %+v", metadata))
+	}
+
+	if err := setParents("b"); err != nil {
+		return errCase(name, err)
+	}
+	if got, err = problems(); err != nil {
+		return errCase(name, err)
+	}
+	if _, ok := got["a"]; ok {
+		mismatches = append(mismatches, "a still reported after fixing the cycle")
+	}
+	if _, ok := got["b"]; ok {
+		mismatches = append(mismatches, "b still reported after fixing the cycle")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "a and b reported as a cycle, c4 too deep after 2 parents, c3 keeps ancestor c1, the cycle cleared once fixed",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "hierarchy problems were not reported as expected"
+	}
+	return cr
+}
@@ -0,0 +1,69 @@
+package metadatatest
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseRecentlyCreated seeds files with creation dates far in the future, so they sort ahead
+// of anything else in the vault, and checks that files.GetRecentlyCreated (the
+// recentlycreated endpoint) returns them newest first, cut to the limit.
+func caseRecentlyCreated() test.CaseResult {
+	name := "recently created files are listed newest first"
+
+	seeds := []struct {
+		rel       string
+		createdAt time.Time
+	}{
+		{testPath("recent-middle.md"), time.Date(2091, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{testPath("recent-oldest.md"), time.Date(2090, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{testPath("recent-newest.md"), time.Date(2092, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, seed := range seeds {
+		if err := writeFile(seed.rel, "# recent\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(seed.rel), CreatedAt: seed.createdAt}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	recent := func(limit int) ([]string, error) {
+		created, err := files.GetRecentlyCreated(limit)
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, m := range created {
+			paths = append(paths, pathutils.ToRelative(m.Path))
+		}
+		return paths, nil
+	}
+	two, err := recent(2)
+	if err != nil {
+		return errCase(name, err)
+	}
+	three, err := recent(3)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	wantTwo := []string{testPath("recent-newest.md"), testPath("recent-middle.md")}
+	wantThree := append(slices.Clone(wantTwo), testPath("recent-oldest.md"))
+	success := slices.Equal(two, wantTwo) && slices.Equal(three, wantThree)
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("limit 2: %v, limit 3: %v", wantTwo, wantThree),
+		Actual:   fmt.Sprintf("limit 2: %v, limit 3: %v", two, three),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "recently created files are not sorted newest first or not cut to the limit"
+	}
+	return cr
+}
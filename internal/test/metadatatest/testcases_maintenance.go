@@ -0,0 +1,340 @@
+package metadatatest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/kanban"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseRefreshTimes changes a file and its mtime behind the app's back, like a git checkout
+// does, and checks that files.MetaDataRefreshTimes (the refreshtimes endpoint) takes over
+// lastEdited and size from disk while keeping the manual tags.
+func caseRefreshTimes() test.CaseResult {
+	name := "refresh file times from disk"
+	rel := testPath("refresh-times.md")
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: []string{"manual"}}); err != nil {
+		return errCase(name, err)
+	}
+
+	content := "# note\n\nchanged by checkout\n"
+	if err := writeFile(rel, content); err != nil {
+		return errCase(name, err)
+	}
+	mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(pathutils.ToDocsPath(rel), mtime, mtime); err != nil {
+		return errCase(name, err)
+	}
+
+	count, err := files.MetaDataRefreshTimes()
+	if err != nil {
+		return errCase(name, err)
+	}
+	metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", rel, err))
+	}
+
+	success := count >= 1 && metadata.LastEdited.Equal(mtime) && metadata.Size == int64(len(content)) &&
+		slices.Equal(metadata.Tags, []string{"manual"})
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("at least 1 file refreshed, lastEdited %v, size %d, tags [manual]", mtime, len(content)),
+		Actual:   fmt.Sprintf("%d files refreshed, lastEdited %v, size %d, tags %v", count, metadata.LastEdited, metadata.Size, metadata.Tags),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "file times were not refreshed from disk"
+	}
+	return cr
+}
+
+// caseReindexChangedSince covers files.ReindexChangedSince, the startup catch-up for edits
+// made outside knov: files with a later mtime are reindexed or get metadata, the metadata of
+// deleted files is purged and unchanged files are left alone.
+func caseReindexChangedSince() test.CaseResult {
+	name := "reindex files changed since"
+	unchanged, edited, deleted, added := testPath("reindex/unchanged.md"), testPath("reindex/edited.md"), testPath("reindex/deleted.md"), testPath("reindex/new.md")
+	get := func(rel string) *files.Metadata {
+		metadata, _ := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+		return metadata
+	}
+	for _, rel := range []string{unchanged, edited, deleted} {
+		if err := writeFile(rel, "# before\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	unchangedMetadata := get(unchanged)
+	if unchangedMetadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s", unchanged))
+	}
+
+	// edits made outside of knov after the last run: mtimes later than the saved metadata
+	since := time.Now()
+	later := since.Add(5 * time.Second)
+	for rel, content := range map[string]string{edited: "# after\n", added: "# new\n"} {
+		if err := writeFile(rel, content); err != nil {
+			return errCase(name, err)
+		}
+		if err := os.Chtimes(pathutils.ToDocsPath(rel), later, later); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := os.Remove(pathutils.ToDocsPath(deleted)); err != nil {
+		return errCase(name, err)
+	}
+
+	if err := files.ReindexChangedSince(since); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	if m := get(edited); m == nil || m.Title != "after" {
+		mismatches = append(mismatches, fmt.Sprintf("edited file %+v", m))
+	}
+	if m := get(added); m == nil || m.Title != "new" {
+		mismatches = append(mismatches, fmt.Sprintf("new file %+v", m))
+	}
+	if m := get(deleted); m != nil {
+		mismatches = append(mismatches, "deleted file kept its metadata")
+	}
+	if m := get(unchanged); m == nil || !m.LastEdited.Equal(unchangedMetadata.LastEdited) {
+		mismatches = append(mismatches, "unchanged file was reindexed")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "edited file retitled after, new file titled new, deleted file purged, unchanged file left alone",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "files changed outside knov were not reindexed as expected"
+	}
+	return cr
+}
+
+// caseConcurrentSaves races tag additions through files.MetaDataModifyRaw, a kanban move
+// and a custom field save on the same file: the per-path metadata lock has to keep every
+// one of the changes.
+func caseConcurrentSaves() test.CaseResult {
+	name := "concurrent metadata saves"
+	rel := testPath("concurrent/note.md")
+	metadataPath := pathutils.ToWithPrefix(rel)
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSave(&files.Metadata{Path: metadataPath, Tags: []string{"base"}}); err != nil {
+		return errCase(name, err)
+	}
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var errs []error
+	record := func(err error) {
+		if err != nil {
+			errMu.Lock()
+			errs = append(errs, err)
+			errMu.Unlock()
+		}
+	}
+	start := make(chan struct{})
+	wantTags := []string{"base", configmanager.KanbanStatusTag("blocked")}
+	for i := range 30 {
+		tag := fmt.Sprintf("tag%d", i)
+		wantTags = append(wantTags, tag)
+		wg.Go(func() {
+			<-start
+			_, err := files.MetaDataModifyRaw(metadataPath, func(m *files.Metadata) error {
+				m.Tags = append(m.Tags, tag)
+				return nil
+			})
+			record(err)
+		})
+	}
+	wg.Go(func() {
+		<-start
+		_, err := kanban.MoveCard("", metadataPath, "blocked")
+		record(err)
+	})
+	wg.Go(func() {
+		<-start
+		record(files.MetaDataSave(&files.Metadata{Path: metadataPath, Custom: map[string]string{files.TargetDateField: "2026-12-31"}}))
+	})
+	close(start)
+	wg.Wait()
+	if len(errs) > 0 {
+		return errCase(name, errors.Join(errs...))
+	}
+
+	metadata, err := files.MetaDataGet(metadataPath)
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", rel, err))
+	}
+	var lost []string
+	for _, tag := range wantTags {
+		if !slices.Contains(metadata.Tags, tag) {
+			lost = append(lost, tag)
+		}
+	}
+	targetDate := metadata.Custom[files.TargetDateField]
+
+	success := len(lost) == 0 && targetDate == "2026-12-31"
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "all 30 tags, base, the blocked status and targetDate 2026-12-31 kept",
+		Actual:   fmt.Sprintf("lost tags %v, targetDate %q", lost, targetDate),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "a concurrent metadata save overwrote another one"
+	}
+	return cr
+}
+
+// caseDeletePrefix covers files.MetaDataDeletePrefix (DELETE /api/metadata?prefix=): the
+// metadata under the folder prefix is deleted, a sibling folder sharing the name as prefix
+// is kept, and the deleted files are dropped from the kids and parents of the remaining
+// files. An empty prefix or the whole docs folder is rejected.
+func caseDeletePrefix() test.CaseResult {
+	name := "delete metadata by prefix"
+	a, b, keep := testPath("prefixdel/a.md"), testPath("prefixdel/sub/b.md"), testPath("prefixdel-keep/c.md")
+	parent, kid := testPath("prefixparent.md"), testPath("prefixkid.md")
+	for _, rel := range []string{a, b, keep, parent, kid} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	key := pathutils.ToWithPrefix
+	for _, metadata := range []*files.Metadata{
+		{Path: key(parent)},
+		{Path: key(a), Parents: []string{key(parent)}},
+		{Path: key(b)},
+		{Path: key(keep), Parents: []string{key(parent)}},
+		{Path: key(kid), Parents: []string{key(b), key(parent)}},
+	} {
+		if err := files.MetaDataSaveNoRefresh(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	if stored, err := files.MetaDataGet(key(parent)); err != nil || stored == nil || !slices.Contains(stored.Kids, key(a)) {
+		mismatches = append(mismatches, "parent doesn't list its kid before the delete")
+	}
+	for _, prefix := range []string{"", "docs/"} {
+		if _, err := files.MetaDataDeletePrefix(prefix); err == nil {
+			mismatches = append(mismatches, fmt.Sprintf("prefix %q accepted", prefix))
+		}
+	}
+
+	count, err := files.MetaDataDeletePrefix(testPath("prefixdel") + "/")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if count != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("%d deleted", count))
+	}
+	for rel, exists := range map[string]bool{a: false, b: false, keep: true} {
+		if metadata, _ := files.MetaDataGet(key(rel)); (metadata != nil) != exists {
+			mismatches = append(mismatches, fmt.Sprintf("%s exists=%t", rel, metadata != nil))
+		}
+	}
+	if stored, _ := files.MetaDataGet(key(parent)); stored == nil || !slices.Equal(stored.Kids, []string{key(keep), key(kid)}) {
+		mismatches = append(mismatches, fmt.Sprintf("parent kids %+v", stored))
+	}
+	if stored, _ := files.MetaDataGet(key(kid)); stored == nil || !slices.Equal(stored.Parents, []string{key(parent)}) {
+		mismatches = append(mismatches, fmt.Sprintf("kid parents %+v", stored))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "2 entries under prefixdel/ deleted, prefixdel-keep kept, relations to them dropped, empty and docs/ prefix rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata under the prefix was not deleted as expected"
+	}
+	return cr
+}
+
+// caseRecompute covers files.MetaDataRecompute (POST /api/metadata/recompute): stale derived
+// fields, as left behind by an edit outside knov, are read again from the path and the
+// content and saved, the manual fields are kept, and a file without metadata returns nil.
+func caseRecompute() test.CaseResult {
+	name := "recompute metadata"
+	rel := testPath("recompute/sub/note.md")
+	path := pathutils.ToWithPrefix(rel)
+	if err := writeFile(rel, "# Current title\n\nthree more words"); err != nil {
+		return errCase(name, err)
+	}
+	if err := files.MetaDataSaveRaw(&files.Metadata{
+		Path:       path,
+		Title:      "Old title",
+		Folders:    []string{"elsewhere"},
+		Collection: "elsewhere",
+		Tags:       []string{"manual"},
+		Label:      "green",
+		Custom:     map[string]string{"priority": "high"},
+		Editor:     files.EditorTypeTextarea,
+	}); err != nil {
+		return errCase(name, err)
+	}
+
+	got, err := files.MetaDataRecompute(rel)
+	if err != nil {
+		return errCase(name, err)
+	}
+	if got == nil {
+		return errCase(name, errors.New("no metadata recomputed"))
+	}
+
+	var mismatches []string
+	if got.Title != "Current title" || got.WordCount != 5 {
+		mismatches = append(mismatches, fmt.Sprintf("title %q, word count %d", got.Title, got.WordCount))
+	}
+	wantFolders := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+	if !slices.Equal(got.Folders, wantFolders) || got.Collection != files.CollectionFromPath(path) {
+		mismatches = append(mismatches, fmt.Sprintf("folders %v, collection %q", got.Folders, got.Collection))
+	}
+	if !slices.Equal(got.Tags, []string{"manual"}) || got.Label != "green" || got.Custom["priority"] != "high" || got.Editor != files.EditorTypeTextarea {
+		mismatches = append(mismatches, fmt.Sprintf("manual fields %+v", got))
+	}
+	if stored, _ := files.MetaDataGet(path); stored == nil || stored.Title != "Current title" {
+		mismatches = append(mismatches, fmt.Sprintf("stored %+v", stored))
+	}
+	if missing, err := files.MetaDataRecompute(testPath("recompute/missing.md")); err != nil || missing != nil {
+		mismatches = append(mismatches, fmt.Sprintf("missing file %+v, %v", missing, err))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("title Current title, 5 words, folders %v, manual fields kept and saved, nil for a missing file", wantFolders),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "derived fields were not recomputed as expected"
+	}
+	return cr
+}
@@ -0,0 +1,355 @@
+package metadatatest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/filter"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseTagSynonyms mirrors handleAPISetMetadataTags with the tagSynonyms setting: tags that
+// are synonyms of each other are saved anyway and only reported by files.TagSynonymConflicts,
+// case-insensitively, while tags from different synonym pairs report nothing.
+func caseTagSynonyms() test.CaseResult {
+	name := "tag synonyms are saved and reported"
+	rel := testPath("synonyms.md")
+	if err := writeFile(rel, "# note\n"); err != nil {
+		return errCase(name, err)
+	}
+	restore, err := overrideSetting("tagSynonyms", "ml=machine-learning,ai=artificial-intelligence")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	setTags := func(tags ...string) ([][2]string, error) {
+		sanitized, err := files.SanitizeKanbanTags(tags)
+		if err != nil {
+			return nil, err
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: sanitized}); err != nil {
+			return nil, err
+		}
+		return files.TagSynonymConflicts(sanitized), nil
+	}
+	conflicts, err := setTags("ML", "machine-learning", "go")
+	if err != nil {
+		return errCase(name, err)
+	}
+	metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+	if err != nil || metadata == nil {
+		return errCase(name, fmt.Errorf("metadata missing for %s: %v", rel, err))
+	}
+	saved := metadata.Tags
+	unrelated, err := setTags("ml", "artificial-intelligence")
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := slices.Equal(conflicts, [][2]string{{"ml", "machine-learning"}}) &&
+		slices.Equal(saved, []string{"ML", "machine-learning", "go"}) && len(unrelated) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "conflict [ml machine-learning], tags [ML machine-learning go] saved, no conflict for ml and artificial-intelligence",
+		Actual:   fmt.Sprintf("conflicts %v, tags %v saved, conflicts %v", conflicts, saved, unrelated),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "tag synonyms were not reported or the tags were not saved"
+	}
+	return cr
+}
+
+// caseRenameTag covers files.RenameTagPreview and files.RenameTag (POST
+// /api/metadata/tags/rename): the preview lists the affected files without changing them,
+// the rename replaces the tag in place and drops it where the new tag is already set, and a
+// missing or unchanged name or a kanban status tag as the new name is rejected.
+func caseRenameTag() test.CaseResult {
+	name := "rename tag"
+	const oldTag, newTag, keepTag = "metadatatest-renameme", "metadatatest-renamed", "metadatatest-keep"
+	oldOnly, both, neither := testPath("tagrename/old-only.md"), testPath("tagrename/both.md"), testPath("tagrename/neither.md")
+	seeds := map[string][]string{
+		oldOnly: {oldTag, keepTag},
+		both:    {oldTag, newTag},
+		neither: {keepTag},
+	}
+	for rel, tags := range seeds {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	tagsOf := func(rel string) []string {
+		metadata, err := files.MetaDataGet(pathutils.ToWithPrefix(rel))
+		if err != nil || metadata == nil {
+			return nil
+		}
+		return metadata.Tags
+	}
+
+	preview, err := files.RenameTagPreview(oldTag, newTag)
+	if err != nil {
+		return errCase(name, err)
+	}
+	untouched := slices.Contains(tagsOf(oldOnly), oldTag)
+	count, err := files.RenameTag(oldTag, newTag)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	if want := []string{pathutils.ToWithPrefix(both), pathutils.ToWithPrefix(oldOnly)}; !slices.Equal(preview, want) || !untouched {
+		mismatches = append(mismatches, fmt.Sprintf("preview %v (tags untouched: %v)", preview, untouched))
+	}
+	if count != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("%d files renamed", count))
+	}
+	for rel, want := range map[string][]string{oldOnly: {newTag, keepTag}, both: {newTag}, neither: {keepTag}} {
+		if tags := tagsOf(rel); !slices.Equal(tags, want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s tags %v", rel, tags))
+		}
+	}
+	for _, invalid := range [][2]string{{oldTag, ""}, {keepTag, keepTag}, {keepTag, configmanager.KanbanStatusTag("inbox")}} {
+		if _, err := files.RenameTag(invalid[0], invalid[1]); err == nil {
+			mismatches = append(mismatches, fmt.Sprintf("rename %q to %q accepted", invalid[0], invalid[1]))
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "preview [both old-only] without changes, 2 files renamed, duplicates dropped, invalid names rejected",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the tag was not renamed as expected or an invalid rename was accepted"
+	}
+	return cr
+}
+
+// caseSuggestMetadataValues mirrors metadataSuggestions (GET /api/metadata/suggest), which
+// narrows the cached tag, folder and path lists with files.TopCountKeys: values containing
+// the query case-insensitively come most used first, the limit keeps the most used, and
+// paths and folders are completed the same way.
+func caseSuggestMetadataValues() test.CaseResult {
+	name := "suggest metadata values"
+	seeds := map[string][]string{
+		testPath("suggestvalues/a.md"): {"metadatatest-kube", "metadatatest-kubectl"},
+		testPath("suggestvalues/b.md"): {"metadatatest-kube", "metadatatest-helm"},
+		testPath("suggestvalues/c.md"): {"metadatatest-kube", "metadatatest-kubectl", "Metadatatest-Kube<ops>"},
+	}
+	for rel, tags := range seeds {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	tagCounts, err := files.GetAllTagsCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	folderCounts, err := files.GetAllFoldersCountFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	paths, err := files.GetAllFilePathsFromCache()
+	if err != nil {
+		return errCase(name, err)
+	}
+	pathCounts := make(map[string]int, len(paths))
+	for _, path := range paths {
+		pathCounts[path] = 0
+	}
+
+	var mismatches []string
+	want := []string{"metadatatest-kube", "metadatatest-kubectl", "Metadatatest-Kube<ops>"}
+	if got := files.TopCountKeys(tagCounts, "METADATATEST-KUB", 20); !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("tags %v", got))
+	} else if counts := []int{tagCounts[got[0]], tagCounts[got[1]], tagCounts[got[2]]}; !slices.Equal(counts, []int{3, 2, 1}) {
+		mismatches = append(mismatches, fmt.Sprintf("tag counts %v", counts))
+	}
+	if got := files.TopCountKeys(tagCounts, "metadatatest-kub", 1); !slices.Equal(got, want[:1]) {
+		mismatches = append(mismatches, fmt.Sprintf("tags with limit 1 %v", got))
+	}
+	if got := files.TopCountKeys(pathCounts, "suggestvalues/b", 20); !slices.Equal(got, []string{testPath("suggestvalues/b.md")}) {
+		mismatches = append(mismatches, fmt.Sprintf("paths %v", got))
+	}
+	if got := files.TopCountKeys(folderCounts, "SUGGESTVAL", 20); !slices.Equal(got, []string{"suggestvalues"}) || folderCounts["suggestvalues"] != 3 {
+		mismatches = append(mismatches, fmt.Sprintf("folders %v", got))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("tags %v counted 3/2/1, the first only with limit 1, the b.md path and the suggestvalues folder counted 3", want),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata values were not suggested by use as expected"
+	}
+	return cr
+}
+
+// caseMetadataLabel covers files.MetaDataSetLabel (POST /api/metadata/label) with the
+// labelColors setting: colors that aren't colors are rejected by the setting, labels of the
+// palette are saved and an empty one clears the label, others fail with ErrInvalidLabel and
+// a file without metadata isn't labelled. A regular save keeps the label and the filter
+// finds the files by it.
+func caseMetadataLabel() test.CaseResult {
+	name := "metadata label"
+	red, green, none := testPath("labelcolors/red.md"), testPath("labelcolors/green.md"), testPath("labelcolors/none.md")
+	key := pathutils.ToWithPrefix
+
+	restore, err := overrideSetting("labelColors", "red,#00aa00")
+	defer restore()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var mismatches []string
+	if configmanager.LabelColors.SetFromString("red,url(x)") == nil {
+		mismatches = append(mismatches, "label color url(x) accepted")
+	}
+
+	for _, rel := range []string{red, green, none} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: key(rel)}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	for _, set := range []struct{ rel, label string }{{red, "red"}, {green, "#00aa00"}, {none, "red"}, {none, ""}} {
+		if _, err := files.MetaDataSetLabel(key(set.rel), set.label); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if _, err := files.MetaDataSetLabel(key(red), "blue"); !errors.Is(err, files.ErrInvalidLabel) {
+		mismatches = append(mismatches, fmt.Sprintf("label outside the palette: %v", err))
+	}
+	if metadata, err := files.MetaDataSetLabel(key(testPath("labelcolors/missing.md")), "red"); err != nil || metadata != nil {
+		mismatches = append(mismatches, fmt.Sprintf("file without metadata labelled: %+v, %v", metadata, err))
+	}
+
+	// a regular save keeps the label
+	if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: key(red), Tags: []string{"kept"}}); err != nil {
+		return errCase(name, err)
+	}
+	for rel, want := range map[string]string{red: "red", green: "#00aa00", none: ""} {
+		if stored, _ := files.MetaDataGet(key(rel)); stored == nil || stored.Label != want {
+			mismatches = append(mismatches, fmt.Sprintf("%s label %+v", rel, stored))
+		}
+	}
+
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+	folder := filter.Criteria{Metadata: "folders", Operator: "equals", Value: "labelcolors", Action: "include"}
+	for label, want := range map[string][]string{"red": {key(red)}, "#00aa00": {key(green)}} {
+		result, err := filter.FilterFilesWithConfig(&filter.Config{Criteria: []filter.Criteria{folder, {Metadata: "label", Operator: "equals", Value: label, Action: "include"}}, Logic: "and"})
+		if err != nil {
+			return errCase(name, err)
+		}
+		var got []string
+		for _, f := range result.Files {
+			got = append(got, key(f.Path))
+		}
+		if !slices.Equal(got, want) {
+			mismatches = append(mismatches, fmt.Sprintf("filter by label %s: %v", label, got))
+		}
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "palette labels saved and kept by a save, empty label cleared, blue and url(x) rejected, the filter finds each file by its label",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "file labels were not saved or filtered as expected"
+	}
+	return cr
+}
+
+// caseSuggestFromContent covers files.SuggestMetadata (POST /api/metadata/suggest): the tags
+// of the notes sharing the most words with the content are suggested first, the tags of an
+// unrelated note not at all, and blank content suggests nothing. The seeded words are made up,
+// so the notes of the vault don't compete with them.
+func caseSuggestFromContent() test.CaseResult {
+	name := "suggest metadata from content"
+	deploy, cluster := testPath("suggestops/deploy.md"), testPath("suggestops/cluster.md")
+	seeds := map[string]struct {
+		content string
+		tags    []string
+	}{
+		deploy:                          {"zorbnet deployment with quillcharts, the vexmage is built in the plonkline", []string{"metadatatest-devops", "metadatatest-zorbnet"}},
+		cluster:                         {"vexmage containers run in a zorbnet cluster, the plonkline pushes the vexmage", []string{"metadatatest-devops"}},
+		testPath("suggestkitchen/b.md"): {"brindlebread needs flourmix, saltwater and a long proofing time in the ovenbox", []string{"metadatatest-recipes"}},
+	}
+	for rel, seed := range seeds {
+		if err := writeFile(rel, seed.content); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: seed.tags}); err != nil {
+			return errCase(name, err)
+		}
+	}
+	if err := files.RebuildAllCaches(); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	suggestions, err := files.SuggestMetadata("how do I roll out the new vexmage to the zorbnet cluster from the plonkline?")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if len(suggestions.Tags) == 0 || suggestions.Tags[0].Name != "metadatatest-devops" {
+		mismatches = append(mismatches, fmt.Sprintf("tags %+v", suggestions.Tags))
+	}
+	if slices.ContainsFunc(suggestions.Tags, func(s files.Suggestion) bool { return s.Name == "metadatatest-recipes" }) {
+		mismatches = append(mismatches, fmt.Sprintf("unrelated tag suggested in %+v", suggestions.Tags))
+	}
+	similar := suggestions.Similar[:min(2, len(suggestions.Similar))]
+	slices.Sort(similar)
+	if want := []string{pathutils.ToWithPrefix(cluster), pathutils.ToWithPrefix(deploy)}; !slices.Equal(similar, want) {
+		mismatches = append(mismatches, fmt.Sprintf("most similar %v", suggestions.Similar))
+	}
+	if len(suggestions.Collections) == 0 || suggestions.Collections[0].Name != files.CollectionFromPath(deploy) {
+		mismatches = append(mismatches, fmt.Sprintf("collections %+v", suggestions.Collections))
+	}
+
+	blank, err := files.SuggestMetadata("  ")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if len(blank.Tags) != 0 || len(blank.Collections) != 0 || len(blank.Similar) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("blank content suggested %+v", blank))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "metadatatest-devops first, no metadatatest-recipes, deploy.md and cluster.md most similar, their collection first, nothing for blank content",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata was not suggested from similar notes as expected"
+	}
+	return cr
+}
@@ -0,0 +1,184 @@
+package metadatatest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseValidateMetadata covers files.ValidateAllMetadata (GET /api/metadata/validate): parents,
+// kids and used links to missing files, an unknown editor and an unknown kanban status are
+// reported for the broken file, nothing for the consistent one, and the metadata is left as is.
+func caseValidateMetadata() test.CaseResult {
+	name := "validate metadata"
+	bad, good := testPath("validate/bad.md"), testPath("validate/good.md")
+	for _, rel := range []string{bad, good} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	key := pathutils.ToWithPrefix
+	statusTag := configmanager.GetKanbanPrefix() + "-status-"
+	for _, metadata := range []*files.Metadata{
+		{
+			Path:      key(bad),
+			Parents:   []string{key(good), key(testPath("validate/gone-parent.md"))},
+			Kids:      []string{key(testPath("validate/gone-kid.md"))},
+			UsedLinks: []string{good, testPath("validate/gone-link.md")},
+			Editor:    "no-such-editor",
+			Tags:      []string{"plain", statusTag + "no-such-status"},
+		},
+		{
+			Path:   key(good),
+			Kids:   []string{key(bad)},
+			Editor: files.EditorTypeToastUI,
+			Tags:   []string{statusTag + configmanager.GetKanbanStatuses()[0]},
+		},
+	} {
+		if err := files.MetaDataSaveRaw(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	issues, err := files.ValidateAllMetadata()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var got []string
+	for _, issue := range issues {
+		if strings.HasPrefix(issue.Path, key(testPath("validate"))+"/") {
+			got = append(got, issue.Path+" "+issue.Field+": "+issue.Problem)
+		}
+	}
+	want := []string{
+		key(bad) + " parents: parent " + key(testPath("validate/gone-parent.md")) + " does not exist",
+		key(bad) + " kids: kid " + key(testPath("validate/gone-kid.md")) + " does not exist",
+		key(bad) + " usedLinks: link target " + testPath("validate/gone-link.md") + " does not exist",
+		key(bad) + " editor: unknown editor no-such-editor",
+		key(bad) + " tags: unknown kanban status no-such-status",
+	}
+
+	var mismatches []string
+	if !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("issues %q", got))
+	}
+	// the check is read-only
+	if stored, _ := files.MetaDataGet(key(bad)); stored == nil || len(stored.Parents) != 2 || stored.Editor != "no-such-editor" {
+		mismatches = append(mismatches, fmt.Sprintf("metadata changed to %+v", stored))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("issues %q, metadata unchanged", want),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata validation did not report the expected issues"
+	}
+	return cr
+}
+
+// caseRepairMetadata covers files.RepairMetadata (POST /api/metadata/repair): a dry run
+// reports the pruned relations, the reset editor and status tag and the rebuilt linksToHere
+// without saving them, the repair saves them and a second repair finds nothing left. The
+// repair runs over the whole vault like the endpoint, so only changes to the case's own
+// files are compared.
+func caseRepairMetadata() test.CaseResult {
+	name := "repair metadata"
+	a, b := testPath("repair/a.md"), testPath("repair/b.md")
+	for _, rel := range []string{a, b} {
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+	}
+	key := pathutils.ToWithPrefix
+	goneParent, goneKid, goneLink := key(testPath("repair/gone-parent.md")), key(testPath("repair/gone-kid.md")), key(testPath("repair/gone-link.md"))
+	statusTag := configmanager.GetKanbanPrefix() + "-status-"
+	for _, metadata := range []*files.Metadata{
+		{
+			Path:    key(a),
+			Parents: []string{key(b), goneParent},
+			Kids:    []string{goneKid},
+			Editor:  "no-such-editor",
+			Tags:    []string{"plain", statusTag + "no-such-status"},
+		},
+		{
+			Path:        key(b),
+			Kids:        []string{key(a)},
+			UsedLinks:   []string{key(a)},
+			LinksToHere: []string{goneLink},
+			Editor:      files.EditorTypeToastUI,
+		},
+	} {
+		if err := files.MetaDataSaveRaw(metadata); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	var mismatches []string
+	repair := func(dryRun bool) []string {
+		report, err := files.RepairMetadata(files.RepairOptions{PruneRelations: true, ResetInvalid: true, RebuildLinksToHere: true, DryRun: dryRun})
+		if err != nil {
+			mismatches = append(mismatches, err.Error())
+		}
+		if report.DryRun != dryRun {
+			mismatches = append(mismatches, fmt.Sprintf("report dryRun %t", report.DryRun))
+		}
+		var got []string
+		for _, change := range report.Changes {
+			if strings.HasPrefix(change.Path, key(testPath("repair"))+"/") {
+				got = append(got, change.Path+" "+change.Field+": "+change.Change)
+			}
+		}
+		return got
+	}
+	want := []string{
+		key(a) + " parents: removed missing parent " + goneParent,
+		key(a) + " kids: removed missing kid " + goneKid,
+		key(a) + ` editor: reset unknown editor no-such-editor to "` + configmanager.GetDefaultMarkdownEditor() + `"`,
+		key(a) + " tags: removed unknown kanban status tag " + statusTag + "no-such-status",
+		key(a) + " linksToHere: rebuilt from [] to [" + key(b) + "]",
+		key(b) + " linksToHere: rebuilt from [" + goneLink + "] to []",
+	}
+
+	if got := repair(true); !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("dry run changes %q", got))
+	}
+	if stored, _ := files.MetaDataGet(key(a)); stored == nil || len(stored.Parents) != 2 || stored.Editor != "no-such-editor" {
+		mismatches = append(mismatches, fmt.Sprintf("dry run saved %+v", stored))
+	}
+
+	if got := repair(false); !slices.Equal(got, want) {
+		mismatches = append(mismatches, fmt.Sprintf("repair changes %q", got))
+	}
+	if stored, _ := files.MetaDataGet(key(a)); stored == nil || !slices.Equal(stored.Parents, []string{key(b)}) || len(stored.Kids) != 0 ||
+		string(stored.Editor) != configmanager.GetDefaultMarkdownEditor() || !slices.Equal(stored.Tags, []string{"plain"}) ||
+		!slices.Equal(stored.LinksToHere, []string{key(b)}) {
+		mismatches = append(mismatches, fmt.Sprintf("a.md after repair %+v", stored))
+	}
+	if stored, _ := files.MetaDataGet(key(b)); stored == nil || len(stored.LinksToHere) != 0 || !slices.Equal(stored.Kids, []string{key(a)}) {
+		mismatches = append(mismatches, fmt.Sprintf("b.md after repair %+v", stored))
+	}
+	if got := repair(false); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("second repair changes %q", got))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("changes %q reported by the dry run without saving, saved by the repair, nothing left afterwards", want),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "metadata repair did not fix the expected issues"
+	}
+	return cr
+}
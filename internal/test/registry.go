@@ -18,3 +18,14 @@ func RunAllTests() (*SuiteResult, error) {
 	result.Success = result.Failed == 0
 	return result, nil
 }
+
+// Lookup returns the registered suite with the given name. Jobs use it for suites that
+// can't be imported from internal/job because they call into it themselves.
+func Lookup(name string) (Suite, bool) {
+	for _, suite := range suites {
+		if suite.Name() == name {
+			return suite, true
+		}
+	}
+	return nil, false
+}
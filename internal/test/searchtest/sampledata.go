@@ -28,6 +28,9 @@ const (
 	alphaFile = "AlphaUniqueTitle.md"
 	betaFile  = "beta-content.md"
 	deltaFile = "DeltaDeletedUniqueMarker.md"
+
+	smallIndexFile = "index-size-small.md"
+	largeIndexFile = "index-size-large.md"
 )
 
 const (
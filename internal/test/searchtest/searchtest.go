@@ -23,6 +23,10 @@ func (Suite) Run() (*test.SuiteResult, error) {
 		caseSearchLimit,
 		caseSearchDeletedFileByTitle,
 		caseSearchDeletedFileByContent,
+		caseSearchMaxIndexBytes,
+		caseSearchExport,
+		caseSearchSnippets,
+		caseFuzzySearch,
 	}
 
 	result := &test.SuiteResult{Suite: "search"}
@@ -2,15 +2,19 @@ package searchtest
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
+	"knov/internal/configmanager"
 	"knov/internal/search"
+	"knov/internal/searchStorage"
 	"knov/internal/test"
 )
 
 func caseSearchTitleOnly() test.CaseResult {
 	name := "search-title-only"
 
-	results, err := search.SearchFilesByTitle("AlphaUniqueTitle", 10)
+	results, err := search.SearchFilesByTitle("AlphaUniqueTitle", 10, false)
 	if err != nil {
 		return errCase(name, err)
 	}
@@ -37,7 +41,7 @@ func caseSearchTitleOnly() test.CaseResult {
 func caseSearchFullContent() test.CaseResult {
 	name := "search-full-content"
 
-	results, err := search.SearchFiles(betaContentMarker, 10)
+	results, err := search.SearchFiles(betaContentMarker, 10, false)
 	if err != nil {
 		return errCase(name, err)
 	}
@@ -64,7 +68,7 @@ func caseSearchFullContent() test.CaseResult {
 func caseSearchEmptyQuery() test.CaseResult {
 	name := "search-empty-query"
 
-	results, err := search.SearchFiles("", 10)
+	results, err := search.SearchFiles("", 10, false)
 	if err != nil {
 		return errCase(name, err)
 	}
@@ -90,11 +94,11 @@ func caseSearchEmptyQuery() test.CaseResult {
 func caseSearchLimit() test.CaseResult {
 	name := "search-limit"
 
-	unlimited, err := search.SearchFilesByTitle("", 0)
+	unlimited, err := search.SearchFilesByTitle("", 0, false)
 	if err != nil {
 		return errCase(name, err)
 	}
-	limited, err := search.SearchFilesByTitle("", 1)
+	limited, err := search.SearchFilesByTitle("", 1, false)
 	if err != nil {
 		return errCase(name, err)
 	}
@@ -165,3 +169,50 @@ func caseSearchDeletedFileByContent() test.CaseResult {
 	}
 	return cr
 }
+
+// caseSearchMaxIndexBytes lowers the index size limit and checks that a file over it is
+// left out of the search index while a file under it is indexed.
+func caseSearchMaxIndexBytes() test.CaseResult {
+	name := "search-max-index-bytes"
+
+	previous := configmanager.SearchMaxIndexBytes.Get()
+	if err := configmanager.SearchMaxIndexBytes.SetFromString("1024"); err != nil {
+		return errCase(name, err)
+	}
+	defer func() {
+		_ = configmanager.SearchMaxIndexBytes.SetFromString(strconv.Itoa(previous))
+	}()
+
+	smallPath := testPath(smallIndexFile)
+	largePath := testPath(largeIndexFile)
+	if err := writeFile(smallPath, "# small\nfits in the index\n"); err != nil {
+		return errCase(name, err)
+	}
+	if err := writeFile(largePath, "# large\n"+strings.Repeat("generated log line\n", 200)); err != nil {
+		return errCase(name, err)
+	}
+	if err := search.IndexAllFiles(); err != nil {
+		return errCase(name, err)
+	}
+
+	smallIndexedAt, err := searchStorage.GetIndexedAt(smallPath)
+	if err != nil {
+		return errCase(name, err)
+	}
+	largeIndexedAt, err := searchStorage.GetIndexedAt(largePath)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := !smallIndexedAt.IsZero() && largeIndexedAt.IsZero()
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("%s indexed, %s skipped", smallIndexFile, largeIndexFile),
+		Actual:   fmt.Sprintf("small indexed=%v, large indexed=%v", !smallIndexedAt.IsZero(), !largeIndexedAt.IsZero()),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "max index bytes limit not applied during indexing"
+	}
+	return cr
+}
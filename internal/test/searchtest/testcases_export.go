@@ -0,0 +1,57 @@
+package searchtest
+
+import (
+	"fmt"
+	"slices"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/search"
+	"knov/internal/test"
+)
+
+// caseSearchExport mirrors the export branch of handleAPISearch: a title search up to the
+// searchExportLimit setting, keeping the metadata of every match. The csv/json encoding
+// happens in internal/server and is out of reach here.
+func caseSearchExport() test.CaseResult {
+	name := "search-export"
+
+	for _, file := range []string{"exportmatch-alpha.md", "exportmatch-beta.md", "unrelated.md"} {
+		rel := testPath("export/" + file)
+		if err := writeFile(rel, "# note\n"); err != nil {
+			return errCase(name, err)
+		}
+		if err := files.MetaDataSave(&files.Metadata{Path: pathutils.ToWithPrefix(rel), Tags: []string{"exported"}}); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	results, err := search.SearchFilesByTitle("exportmatch", configmanager.GetSearchExportLimit(), false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var paths []string
+	tagged := true
+	for _, file := range results {
+		if file.Metadata == nil {
+			continue
+		}
+		paths = append(paths, file.Metadata.Path)
+		tagged = tagged && slices.Contains(file.Metadata.Tags, "exported")
+	}
+	slices.Sort(paths)
+
+	want := []string{pathutils.ToWithPrefix(testPath("export/exportmatch-alpha.md")), pathutils.ToWithPrefix(testPath("export/exportmatch-beta.md"))}
+	success := slices.Equal(paths, want) && tagged
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("metadata of %v with their tags", want),
+		Actual:   fmt.Sprintf("metadata of %v, tags kept: %v", paths, tagged),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "the export did not hold the metadata of exactly the matching files"
+	}
+	return cr
+}
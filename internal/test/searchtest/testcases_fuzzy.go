@@ -0,0 +1,102 @@
+package searchtest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/search"
+	"knov/internal/test"
+)
+
+// fuzzySearchFresh rebuilds the caches, so the title set by caseFuzzySearch is listed, and
+// runs search.SearchFiles or, without fuzzy, search.SearchFilesNoFuzzy.
+func fuzzySearchFresh(query string, fuzzy bool) ([]files.File, error) {
+	if err := files.RebuildAllCaches(); err != nil {
+		return nil, err
+	}
+	if fuzzy {
+		return search.SearchFiles(query, 100, false)
+	}
+	return search.SearchFilesNoFuzzy(query, 100, false)
+}
+
+// caseFuzzySearch covers the typo-tolerant fallback of search.SearchFiles (GET /api/search):
+// "quokkaplex" is only in a filename and a title, so neither fts nor the trigram index of the
+// contents know it. Titles and filenames two edits away are found and flagged fuzzy, three
+// edits away or with the searchFuzzyDistance setting at 0 nothing is, and an exact filename
+// match isn't flagged.
+func caseFuzzySearch() test.CaseResult {
+	name := "search-fuzzy"
+	setup, other := testPath("fuzzy/quokkaplex-setup.md"), testPath("fuzzy/other.md")
+	err := seedIndexed(map[string]string{
+		setup:                          "helm and kubectl\n",
+		other:                          "cni plugins\n",
+		testPath("fuzzy/unrelated.md"): "# Gardening\n\ntomatoes\n",
+	})
+	if err != nil {
+		return errCase(name, err)
+	}
+	// titles are derived on save, set one the contents don't contain directly
+	if _, err := files.MetaDataModifyRaw(pathutils.ToWithPrefix(other), func(m *files.Metadata) error {
+		m.Title = "Quokkaplex Networking"
+		return nil
+	}); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	find := func(query string, fuzzy bool) []files.File {
+		results, err := fuzzySearchFresh(query, fuzzy)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", query, err))
+		}
+		return results
+	}
+
+	// the filename of one and the title of the other are two edits away
+	var paths []string
+	for _, f := range find("quokkaplx", true) {
+		if !f.Fuzzy {
+			mismatches = append(mismatches, fmt.Sprintf("%s not flagged fuzzy", f.Path))
+		}
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{other, setup}; !slices.Equal(paths, want) {
+		mismatches = append(mismatches, fmt.Sprintf("fuzzy matches %v, want %v", paths, want))
+	}
+	if got := find("quokkaplx", false); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("without fuzzy %d results", len(got)))
+	}
+	if got := find("qokaplx", true); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("three edits away %d results", len(got)))
+	}
+	if got := find("quokkaplex", true); len(got) != 1 || got[0].Path != setup || got[0].Fuzzy {
+		mismatches = append(mismatches, fmt.Sprintf("exact match %+v", got))
+	}
+
+	previous := configmanager.SearchFuzzyDistance.Get()
+	defer configmanager.SearchFuzzyDistance.SetFromString(strconv.Itoa(previous)) //nolint:errcheck
+	if err := configmanager.SearchFuzzyDistance.SetFromString("0"); err != nil {
+		return errCase(name, err)
+	}
+	if got := find("quokkaplx", true); len(got) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("distance 0 %d results", len(got)))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "quokkaplx finds the filename and the title match flagged fuzzy, nothing without fuzzy, three edits away or at distance 0",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "fuzzy search did not match titles and filenames as expected"
+	}
+	return cr
+}
@@ -0,0 +1,96 @@
+package searchtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/files"
+	"knov/internal/pathutils"
+	"knov/internal/search"
+	"knov/internal/searchStorage"
+	"knov/internal/test"
+)
+
+// seedIndexed writes the sample files and indexes them synchronously.
+func seedIndexed(contents map[string]string) error {
+	for rel, content := range contents {
+		if err := writeFile(rel, content); err != nil {
+			return err
+		}
+		if err := files.MetaDataSaveNoRefresh(&files.Metadata{Path: pathutils.ToWithPrefix(rel)}); err != nil {
+			return err
+		}
+	}
+	return search.IndexAllFiles()
+}
+
+// searchSnippetsFresh rebuilds the caches and runs search.SearchFilesWithSnippets. Search
+// only returns files of the cached file list, which a background refresh started by an
+// earlier save can overwrite with a list missing the new files.
+func searchSnippetsFresh(query string) ([]searchStorage.SearchResult, error) {
+	if err := files.RebuildAllCaches(); err != nil {
+		return nil, err
+	}
+	return search.SearchFilesWithSnippets(query, 100, false)
+}
+
+// caseSearchSnippets covers search.SearchFilesWithSnippets (GET /api/search?snippets=true):
+// the file with more matches ranks first with the better score, matches are highlighted
+// and the content is escaped. A query that is no valid fts5 query is answered by the
+// substring fallback, cut to the searchSnippetTokens setting.
+func caseSearchSnippets() test.CaseResult {
+	name := "search-snippets"
+	often, once := testPath("snippets/often.md"), testPath("snippets/once.md")
+	err := seedIndexed(map[string]string{
+		often:                           "# often\n\nsnipzebra snipzebra and another snipzebra\n",
+		once:                            "# once\n\na long note where a snipzebra shows up <script>alert(1)</script> just once among many other words\n",
+		testPath("snippets/xray.md"):    "# xray\n\nalpha beta gamma snip-xray delta epsilon zeta\n",
+		testPath("snippets/nomatch.md"): "# nothing\n\nhorses only\n",
+	})
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	results, err := searchSnippetsFresh("snipzebra")
+	if err != nil {
+		return errCase(name, err)
+	}
+	var mismatches []string
+	if len(results) != 2 || results[0].Path != often || results[1].Path != once {
+		mismatches = append(mismatches, fmt.Sprintf("ranking %+v", results))
+	} else {
+		if !strings.Contains(results[0].Snippet, "<mark>snipzebra</mark>") || results[0].Score > results[1].Score {
+			mismatches = append(mismatches, fmt.Sprintf("first result %+v", results[0]))
+		}
+		if strings.Contains(results[1].Snippet, "<script>") || !strings.Contains(results[1].Snippet, "&lt;script&gt;") {
+			mismatches = append(mismatches, fmt.Sprintf("unescaped snippet %q", results[1].Snippet))
+		}
+	}
+
+	previous := configmanager.SearchSnippetTokens.Get()
+	defer configmanager.SearchSnippetTokens.SetFromString(strconv.Itoa(previous)) //nolint:errcheck
+	if err := configmanager.SearchSnippetTokens.SetFromString("3"); err != nil {
+		return errCase(name, err)
+	}
+	fallback, err := searchSnippetsFresh("snip-xray")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if len(fallback) != 1 || fallback[0].Snippet != "...gamma <mark>snip-xray</mark> delta..." {
+		mismatches = append(mismatches, fmt.Sprintf("fallback %+v", fallback))
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "often.md before once.md, highlighted and escaped snippets, fallback snippet ...gamma <mark>snip-xray</mark> delta...",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "search snippets were not ranked, highlighted or escaped as expected"
+	}
+	return cr
+}
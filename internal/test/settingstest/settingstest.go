@@ -0,0 +1,36 @@
+// Package settingstest - settings suite: changes registry settings and checks the behaviour
+// they drive directly, without going through HTTP.
+package settingstest
+
+import "knov/internal/test"
+
+// Suite runs the settings test cases against the real settings registry.
+type Suite struct{}
+
+func init() {
+	test.Register(Suite{})
+}
+
+func (Suite) Name() string { return "settings" }
+
+func (Suite) Run() (*test.SuiteResult, error) {
+	cases := []func() test.CaseResult{
+		caseTranslationFallback,
+		caseResponseSizeWarning,
+		caseThemeQueryOverride,
+	}
+
+	result := &test.SuiteResult{Suite: "settings"}
+	for _, c := range cases {
+		cr := c()
+		result.Cases = append(result.Cases, cr)
+		if cr.Success {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	result.Total = len(cases)
+	result.Success = result.Failed == 0
+	return result, nil
+}
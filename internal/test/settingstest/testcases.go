@@ -0,0 +1,209 @@
+// Package settingstest - test cases for settings and the behaviour they drive
+package settingstest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+
+	"knov/internal/configmanager"
+	"knov/internal/logging"
+	"knov/internal/server/metrics"
+	"knov/internal/test"
+	"knov/internal/thememanager"
+	"knov/internal/translation"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+func errCase(name string, err error) test.CaseResult {
+	return test.CaseResult{Name: name, Success: false, Error: err.Error()}
+}
+
+// caseTranslationFallback registers messages under keys no catalog has and translates them
+// for de-AT: a key missing in de-AT comes from de, then from the languageFallback setting's
+// languages, and is left untranslated last. Without en in the setting a key only en has
+// isn't found.
+func caseTranslationFallback() test.CaseResult {
+	name := "translation-fallback"
+
+	previous := strings.Join(configmanager.LanguageFallback.Get(), ",")
+	defer configmanager.LanguageFallback.SetFromString(previous) //nolint:errcheck // restoring a previously valid value
+	if err := configmanager.LanguageFallback.SetFromString("en"); err != nil {
+		return errCase(name, err)
+	}
+
+	deAT, de, en := language.MustParse("de-AT"), language.German, language.English
+	catalogs := map[language.Tag]map[string]string{
+		deAT: {"settingstest greeting": "Servus"},
+		de:   {"settingstest greeting": "Hallo", "settingstest farewell": "Tschüss"},
+		en:   {"settingstest greeting": "Hi", "settingstest farewell": "Bye", "settingstest %d notes": "%d notes in total"},
+	}
+	for tag, messages := range catalogs {
+		for key, msg := range messages {
+			if err := message.SetString(tag, key, msg); err != nil {
+				return errCase(name, err)
+			}
+		}
+	}
+
+	var mismatches []string
+	check := func(key, want string, args ...any) {
+		if got := translation.SprintfForRequest("de-AT", key, args...); got != want {
+			mismatches = append(mismatches, fmt.Sprintf("%q: %q", key, got))
+		}
+	}
+	check("settingstest greeting", "Servus")
+	check("settingstest farewell", "Tschüss")
+	check("settingstest %d notes", "3 notes in total", 3)
+	check("settingstest untranslated %s", "settingstest untranslated x", "x")
+
+	// without en in the chain a key only en has isn't found
+	if err := configmanager.LanguageFallback.SetFromString(""); err != nil {
+		return errCase(name, err)
+	}
+	check("settingstest farewell", "Tschüss")
+	check("settingstest %d notes", "settingstest 3 notes", 3)
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "de-AT, then de, then en, then the key; without fallbacks the key",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "translations did not fall back along the language chain"
+	}
+	return cr
+}
+
+// caseResponseSizeWarning records responses the way the response size middleware does: one
+// above the responseSizeWarnBytes threshold logs a warning, one below it doesn't, both count
+// for their endpoint, and requests no route matched share the unmatched entry.
+func caseResponseSizeWarning() test.CaseResult {
+	name := "response-size-warning"
+	const pattern = "/settingstest/{id}"
+
+	previous := configmanager.ResponseSizeWarnBytes.Get()
+	defer configmanager.ResponseSizeWarnBytes.SetFromString(strconv.Itoa(previous)) //nolint:errcheck // restoring a previously valid value
+	if err := configmanager.ResponseSizeWarnBytes.SetFromString("5"); err != nil {
+		return errCase(name, err)
+	}
+
+	warned := func(path string) bool {
+		return slices.ContainsFunc(logging.GetRecentEntries(500), func(entry logging.LogEntry) bool {
+			return entry.Level == "warning" && strings.Contains(entry.Message, "large response: GET "+path+" ")
+		})
+	}
+	stat := func(endpoint string) metrics.ResponseSizeStat {
+		for _, s := range metrics.ResponseSizes() {
+			if s.Endpoint == endpoint {
+				return s
+			}
+		}
+		return metrics.ResponseSizeStat{}
+	}
+
+	before, unmatchedBefore := stat("GET "+pattern), stat(metrics.UnmatchedEndpoint)
+	metrics.RecordResponse("GET", "/settingstest/small", pattern, 3)
+	metrics.RecordResponse("GET", "/settingstest/large", pattern, 50)
+	metrics.RecordResponse("GET", "/settingstest-no-route-a", "", 1)
+	metrics.RecordResponse("GET", "/settingstest-no-route-b", "", 1)
+	after, unmatchedAfter := stat("GET "+pattern), stat(metrics.UnmatchedEndpoint)
+
+	var mismatches []string
+	if warned("/settingstest/small") || !warned("/settingstest/large") {
+		mismatches = append(mismatches, fmt.Sprintf("warned small=%t large=%t", warned("/settingstest/small"), warned("/settingstest/large")))
+	}
+	if after.Count-before.Count != 2 || after.MaxBytes < 50 {
+		mismatches = append(mismatches, fmt.Sprintf("endpoint %+v", after))
+	}
+	if unmatchedAfter.Count-unmatchedBefore.Count != 2 {
+		mismatches = append(mismatches, fmt.Sprintf("unmatched %+v", unmatchedAfter))
+	}
+	if slices.ContainsFunc(metrics.ResponseSizes(), func(s metrics.ResponseSizeStat) bool { return strings.Contains(s.Endpoint, "no-route") }) {
+		mismatches = append(mismatches, "unmatched paths got their own entry")
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "only the response above 5 bytes warned, both counted for the route, unmatched requests counted together",
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "response sizes were not recorded or warned about as expected"
+	}
+	return cr
+}
+
+// caseThemeQueryOverride resolves requests with a theme query parameter the way every page
+// handler does, through thememanager.ThemeForRequest and Render: another loaded theme renders
+// that one request and links its stylesheet, an unknown name falls back to the current theme,
+// and with themeQueryOverride off the parameter is ignored. The current theme never changes.
+func caseThemeQueryOverride() test.CaseResult {
+	name := "theme-query-override"
+	tm := thememanager.GetThemeManager()
+	current := tm.GetCurrentThemeName()
+	other := ""
+	for _, theme := range tm.GetAvailableThemes() {
+		if theme.Name != current {
+			other = theme.Name
+			break
+		}
+	}
+	if other == "" {
+		return errCase(name, fmt.Errorf("no theme besides %s is loaded", current))
+	}
+
+	previous := configmanager.ThemeQueryOverride.Get()
+	defer configmanager.ThemeQueryOverride.SetFromString(strconv.FormatBool(previous)) //nolint:errcheck // restoring a previously valid value
+	if err := configmanager.ThemeQueryOverride.SetFromString("true"); err != nil {
+		return errCase(name, err)
+	}
+
+	var mismatches []string
+	check := func(query, want string) {
+		r := httptest.NewRequest(http.MethodGet, "/settings"+query, nil)
+		if got := tm.ThemeForRequest(r).Name; got != want {
+			mismatches = append(mismatches, fmt.Sprintf("%q: %s", query, got))
+		}
+	}
+	check("?theme="+other, other)
+	check("", current)
+	check("?theme=settingstest-missing", current)
+
+	rec := httptest.NewRecorder()
+	if err := tm.Render(rec, httptest.NewRequest(http.MethodGet, "/settings?theme="+other, nil), "settings", thememanager.NewSettingsTemplateData()); err != nil {
+		return errCase(name, err)
+	}
+	if page := rec.Body.String(); !strings.Contains(page, `data-theme="`+other+`"`) || !strings.Contains(page, "/themes/"+other+"/css/style.css") {
+		mismatches = append(mismatches, "rendered page does not use "+other)
+	}
+
+	if err := configmanager.ThemeQueryOverride.SetFromString("false"); err != nil {
+		return errCase(name, err)
+	}
+	check("?theme="+other, current)
+	if got := tm.GetCurrentThemeName(); got != current {
+		mismatches = append(mismatches, "current theme changed to "+got)
+	}
+
+	success := len(mismatches) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("?theme=%s renders with %s, otherwise and with the setting off %s", other, other, current),
+		Actual:   fmt.Sprintf("mismatches: %v", mismatches),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "theme query parameter not applied as expected"
+	}
+	return cr
+}
@@ -0,0 +1,118 @@
+// Package storagetest - sample folder, temporary backend folders and shared case helpers
+package storagetest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"knov/internal/logging"
+	"knov/internal/metadataStorage"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// testDir is the docs-relative sample folder of the cases that need real files, wiped at the
+// start of each run so cases never see stale state from a previous run.
+const testDir = "test/storage-tests"
+
+// resetTestDir clears the sample folder on disk so every run starts from a clean state.
+func resetTestDir() error {
+	full := pathutils.ToDocsPath(testDir)
+	if err := os.RemoveAll(full); err != nil {
+		return err
+	}
+	return os.MkdirAll(full, 0755)
+}
+
+// sampleKey returns the metadata storage key of name in the sample folder. Cases writing to
+// the active metadata storage only use these keys and remove them again via dropSampleKeys.
+func sampleKey(name string) string {
+	return "docs/" + testDir + "/" + name
+}
+
+// dropSampleKeys removes every sample key from the active metadata storage.
+func dropSampleKeys() {
+	if _, err := metadataStorage.DeletePrefix(sampleKey("")); err != nil {
+		logging.LogWarning(logging.KeyApp, "storage test: failed to remove sample metadata: %v", err)
+	}
+}
+
+// sampleKeys returns the sorted sample keys stored in the active metadata storage.
+func sampleKeys() ([]string, error) {
+	all, err := metadataStorage.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for key := range all {
+		if strings.HasPrefix(key, sampleKey("")) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// tempStoragePath creates a temporary storage folder for throwaway backends and returns it
+// with a func removing it again - the backends opened there never touch the app's storage.
+func tempStoragePath() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "knov-storage-test-")
+	if err != nil {
+		return "", func() {}, err
+	}
+	return dir, func() { os.RemoveAll(dir) }, nil
+}
+
+// openFillerDB creates a sqlite database in dir holding rows of filler data. When fragment is
+// set, most rows are deleted again, leaving their pages on the freelist.
+func openFillerDB(dir string, fragment bool) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", filepath.Join(dir, "filler.db")+"?mode=rwc")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE filler (id INTEGER PRIMARY KEY, data TEXT)`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	filler := strings.Repeat("x", 2000)
+	for i := range 500 {
+		if _, err := db.Exec(`INSERT INTO filler (id, data) VALUES (?, ?)`, i, filler); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if fragment {
+		if _, err := db.Exec(`DELETE FROM filler WHERE id >= 50`); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// freePages returns the number of pages on the freelist of db.
+func freePages(db *sql.DB) (int, error) {
+	var n int
+	err := db.QueryRow(`PRAGMA freelist_count`).Scan(&n)
+	return n, err
+}
+
+func errCase(name string, err error) test.CaseResult {
+	return test.CaseResult{Name: name, Success: false, Error: err.Error()}
+}
+
+// checkResult builds the CaseResult of a case that collected its mismatches in failures,
+// one per failed check, prefixed with the backend it was found in.
+func checkResult(name, expected string, failures []string, errMsg string) test.CaseResult {
+	cr := test.CaseResult{Name: name, Expected: expected, Actual: "no mismatches", Success: len(failures) == 0}
+	if !cr.Success {
+		cr.Actual = strings.Join(failures, "; ")
+		cr.Error = errMsg
+	}
+	return cr
+}
@@ -0,0 +1,52 @@
+// Package storagetest - storage suite: opens throwaway config, cache and metadata backends
+// in a temporary folder and runs the same operations against each of them, so every backend
+// of a storage behaves the same, runs the operations of the active metadata storage on
+// sample keys and compacts throwaway sqlite databases.
+package storagetest
+
+import "knov/internal/test"
+
+// Suite runs the storage test cases against temporary and the active storage backends.
+type Suite struct{}
+
+func init() {
+	test.Register(Suite{})
+}
+
+func (Suite) Name() string { return "storage" }
+
+func (Suite) Run() (*test.SuiteResult, error) {
+	if err := resetTestDir(); err != nil {
+		return nil, err
+	}
+
+	cases := []func() test.CaseResult{
+		caseConfigConformance,
+		caseConfigMemoryEphemeral,
+		caseCacheConformance,
+		caseCacheMemoryEphemeral,
+		caseMetadataConformance,
+		caseMetadataMemoryEphemeral,
+		caseMetadataRoundTrip,
+		caseMetadataDeletePrefix,
+		caseMetadataBulkSet,
+		caseMetadataExportSQLite,
+		caseYAMLArchiveKey,
+		caseCompactFragmented,
+		caseCompactHealthy,
+	}
+
+	result := &test.SuiteResult{Suite: "storage"}
+	for _, c := range cases {
+		cr := c()
+		result.Cases = append(result.Cases, cr)
+		if cr.Success {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	result.Total = len(cases)
+	result.Success = result.Failed == 0
+	return result, nil
+}
@@ -0,0 +1,633 @@
+package storagetest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"knov/internal/cacheStorage"
+	"knov/internal/configStorage"
+	"knov/internal/dbmigration"
+	"knov/internal/metadataStorage"
+	"knov/internal/pathutils"
+	"knov/internal/test"
+)
+
+// caseConfigConformance runs the same operations against every config storage backend,
+// opened in a temporary folder, and expects identical results.
+func caseConfigConformance() test.CaseResult {
+	name := "config-conformance"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var failures []string
+	for _, provider := range []string{"json", "memory"} {
+		s, err := configStorage.Open(provider, dir)
+		if err != nil {
+			return errCase(name, err)
+		}
+		fail := func(format string, args ...any) {
+			failures = append(failures, provider+": "+fmt.Sprintf(format, args...))
+		}
+
+		entries := map[string][]byte{
+			"settings":         []byte(`{"language":"en"}`),
+			"themes/builtin":   []byte(`{"color":"blue"}`),
+			"themes/test":      []byte(`{}`),
+			"metadata-backend": []byte("json"),
+		}
+		for key, data := range entries {
+			if err := s.Set(key, data); err != nil {
+				fail("Set %s: %v", key, err)
+			}
+		}
+
+		if got, err := s.Get("settings"); err != nil || string(got) != `{"language":"en"}` {
+			fail("Get settings = %q, %v", got, err)
+		}
+		if got, err := s.Get("missing"); got != nil || err != nil {
+			fail("Get missing = %q, %v, want nil", got, err)
+		}
+		if !s.Exists("themes/test") || s.Exists("missing") {
+			fail("Exists reported wrong results")
+		}
+		if keys, err := s.List("themes/"); err != nil || !slices.Equal(keys, []string{"themes/builtin", "themes/test"}) {
+			fail("List themes/ = %v, %v", keys, err)
+		}
+		if all, err := s.GetAll(); err != nil || !maps.EqualFunc(all, entries, slices.Equal) {
+			fail("GetAll = %v, %v", all, err)
+		}
+		if err := s.Delete("themes/test"); err != nil {
+			fail("Delete: %v", err)
+		}
+		if err := s.Delete("missing"); err != nil {
+			fail("Delete missing: %v", err)
+		}
+		if s.Exists("themes/test") {
+			fail("key still exists after Delete")
+		}
+	}
+
+	return checkResult(name, "json and memory backends behave the same", failures, "config storage backends disagree")
+}
+
+// caseConfigMemoryEphemeral covers the memory config storage: a freshly opened one starts
+// empty, whatever an earlier one stored.
+func caseConfigMemoryEphemeral() test.CaseResult {
+	name := "config-memory-ephemeral"
+
+	first, err := configStorage.Open("memory", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if err := first.Set("settings", []byte("{}")); err != nil {
+		return errCase(name, err)
+	}
+	second, err := configStorage.Open("memory", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	all, err := second.GetAll()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := first.GetBackendType() == "memory" && first.Exists("settings") && len(all) == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "first store has settings, a new store is empty",
+		Actual:   fmt.Sprintf("first has settings=%v, new store entries=%d", first.Exists("settings"), len(all)),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "memory config storage was not ephemeral"
+	}
+	return cr
+}
+
+// caseCacheConformance runs the same operations against every cache storage backend,
+// opened in a temporary folder, and expects identical results.
+func caseCacheConformance() test.CaseResult {
+	name := "cache-conformance"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var failures []string
+	for _, provider := range []string{"json", "sqlite", "memory"} {
+		s, err := cacheStorage.Open(provider, dir)
+		if err != nil {
+			return errCase(name, err)
+		}
+		fail := func(format string, args ...any) {
+			failures = append(failures, provider+": "+fmt.Sprintf(format, args...))
+		}
+
+		for _, key := range []string{"tags", "collections", "collections-count"} {
+			if err := s.Set(key, []byte(`["`+key+`"]`)); err != nil {
+				fail("Set %s: %v", key, err)
+			}
+		}
+		if got, err := s.Get("tags"); err != nil || string(got) != `["tags"]` {
+			fail("Get tags = %q, %v", got, err)
+		}
+		if got, err := s.Get("missing"); got != nil || err != nil {
+			fail("Get missing = %q, %v, want nil", got, err)
+		}
+		if !s.Exists("tags") || s.Exists("missing") {
+			fail("Exists reported wrong results")
+		}
+		keys, err := s.List("collections")
+		slices.Sort(keys)
+		if err != nil || !slices.Equal(keys, []string{"collections", "collections-count"}) {
+			fail("List collections = %v, %v", keys, err)
+		}
+		if err := s.Delete("tags"); err != nil {
+			fail("Delete: %v", err)
+		}
+		if s.Exists("tags") {
+			fail("key still exists after Delete")
+		}
+		if err := s.Flush(); err != nil {
+			fail("Flush: %v", err)
+		}
+		if keys, _ := s.List(""); len(keys) != 0 {
+			fail("keys left after Flush: %v", keys)
+		}
+	}
+
+	return checkResult(name, "json, sqlite and memory backends behave the same", failures, "cache storage backends disagree")
+}
+
+// caseCacheMemoryEphemeral covers the memory cache storage: a freshly opened one starts
+// empty, whatever an earlier one stored.
+func caseCacheMemoryEphemeral() test.CaseResult {
+	name := "cache-memory-ephemeral"
+
+	first, err := cacheStorage.Open("memory", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	if err := first.Set("tags", []byte("[]")); err != nil {
+		return errCase(name, err)
+	}
+	second, err := cacheStorage.Open("memory", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := first.GetBackendType() == "memory" && first.Exists("tags") && !second.Exists("tags")
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "first store has tags, a new store doesn't",
+		Actual:   fmt.Sprintf("first has tags=%v, new store has tags=%v", first.Exists("tags"), second.Exists("tags")),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "memory cache storage was not ephemeral"
+	}
+	return cr
+}
+
+// caseMetadataConformance runs the same operations against the raw-bytes metadata storage
+// backends, opened in a temporary folder, and expects identical results.
+func caseMetadataConformance() test.CaseResult {
+	name := "metadata-conformance"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var failures []string
+	for _, provider := range []string{"json", "memory"} {
+		s, err := metadataStorage.Open(provider, dir)
+		if err != nil {
+			return errCase(name, err)
+		}
+		fail := func(format string, args ...any) {
+			failures = append(failures, provider+": "+fmt.Sprintf(format, args...))
+		}
+
+		entries := map[string][]byte{
+			"docs/a.md":     []byte(`{"path":"docs/a.md"}`),
+			"docs/sub/b.md": []byte(`{"path":"docs/sub/b.md"}`),
+		}
+		for key, data := range entries {
+			if err := s.Set(key, data); err != nil {
+				fail("Set %s: %v", key, err)
+			}
+		}
+		if got, err := s.Get("docs/a.md"); err != nil || string(got) != `{"path":"docs/a.md"}` {
+			fail("Get docs/a.md = %q, %v", got, err)
+		}
+		if got, err := s.Get("docs/missing.md"); got != nil || err != nil {
+			fail("Get missing = %q, %v, want nil", got, err)
+		}
+		if !s.Exists("docs/sub/b.md") || s.Exists("docs/missing.md") {
+			fail("Exists reported wrong results")
+		}
+		if all, err := s.GetAll(); err != nil || !maps.EqualFunc(all, entries, slices.Equal) {
+			fail("GetAll = %v, %v", all, err)
+		}
+		if err := s.Delete("docs/a.md"); err != nil {
+			fail("Delete: %v", err)
+		}
+		if s.Exists("docs/a.md") {
+			fail("key still exists after Delete")
+		}
+		if err := s.Cleanup(); err != nil {
+			fail("Cleanup: %v", err)
+		}
+		if all, _ := s.GetAll(); len(all) != 0 {
+			fail("entries left after Cleanup: %v", all)
+		}
+	}
+
+	return checkResult(name, "json and memory backends behave the same", failures, "metadata storage backends disagree")
+}
+
+// caseMetadataMemoryEphemeral covers the memory metadata storage: a freshly opened one starts
+// empty while a json backend in the same storage folder keeps its entries - switching to
+// memory must not touch the persisted metadata.
+func caseMetadataMemoryEphemeral() test.CaseResult {
+	name := "metadata-memory-ephemeral"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	persisted, err := metadataStorage.Open("json", dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+	first, err := metadataStorage.Open("memory", dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+	for _, s := range []metadataStorage.MetadataStorage{persisted, first} {
+		if err := s.Set("docs/a.md", []byte(`{"path":"docs/a.md"}`)); err != nil {
+			return errCase(name, err)
+		}
+	}
+	second, err := metadataStorage.Open("memory", dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+	reopened, err := metadataStorage.Open("json", dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := first.Exists("docs/a.md") && !second.Exists("docs/a.md") && reopened.Exists("docs/a.md")
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "new memory store empty, json entry kept",
+		Actual:   fmt.Sprintf("new memory store has entry=%v, json entry kept=%v", second.Exists("docs/a.md"), reopened.Exists("docs/a.md")),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "memory metadata storage was not ephemeral or touched the json backend"
+	}
+	return cr
+}
+
+// caseYAMLArchiveKey covers the yaml front matter backend, which keeps the metadata in the
+// docs file itself: an archive tier key has no file to live in, so setting it fails instead
+// of silently storing nothing, and the front matter of the docs file stays untouched.
+func caseYAMLArchiveKey() test.CaseResult {
+	name := "yaml-archive-key"
+	rel := testDir + "/yaml-note.md"
+	if err := os.WriteFile(pathutils.ToDocsPath(rel), []byte("# yaml note\n"), 0644); err != nil {
+		return errCase(name, err)
+	}
+
+	s, err := metadataStorage.Open("yaml", "")
+	if err != nil {
+		return errCase(name, err)
+	}
+	key := "docs/" + rel
+	if err := s.Set(key, []byte(`{"path":"`+key+`","title":"kept"}`)); err != nil {
+		return errCase(name, err)
+	}
+	archiveErr := s.Set("archive/"+key, []byte(`{"path":"`+key+`","title":"archived"}`))
+	data, err := s.Get(key)
+	if err != nil {
+		return errCase(name, err)
+	}
+	var stored map[string]any
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return errCase(name, err)
+	}
+
+	success := archiveErr != nil && stored["title"] == "kept"
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "archive key rejected, front matter title \"kept\"",
+		Actual:   fmt.Sprintf("archive key error=%v, front matter title %q", archiveErr, stored["title"]),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "yaml backend accepted an archive tier key or lost the front matter"
+	}
+	return cr
+}
+
+// caseCompactFragmented covers the storage compaction: a sqlite database with most of its
+// pages on the freelist is vacuumed and has no free pages left afterwards.
+func caseCompactFragmented() test.CaseResult {
+	name := "compact-fragmented"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	db, err := openFillerDB(dir, true)
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer db.Close()
+	before, err := freePages(db)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	vacuumed, err := dbmigration.CompactIfFragmented(db, 20)
+	if err != nil {
+		return errCase(name, err)
+	}
+	after, err := freePages(db)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := before > 0 && vacuumed && after == 0
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "vacuumed, no free pages left",
+		Actual:   fmt.Sprintf("free pages before=%d, vacuumed=%v, free pages after=%d", before, vacuumed, after),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "fragmented database was not compacted"
+	}
+	return cr
+}
+
+// caseCompactHealthy covers the compaction threshold: a database without free pages is left
+// alone.
+func caseCompactHealthy() test.CaseResult {
+	name := "compact-healthy"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	db, err := openFillerDB(dir, false)
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer db.Close()
+
+	vacuumed, err := dbmigration.CompactIfFragmented(db, 20)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: "not vacuumed",
+		Actual:   fmt.Sprintf("vacuumed=%v", vacuumed),
+		Success:  !vacuumed,
+	}
+	if vacuumed {
+		cr.Error = "healthy database was vacuumed"
+	}
+	return cr
+}
+
+// caseMetadataRoundTrip stores a fully populated entry in a throwaway sqlite backend and, under
+// a sample key, in the active metadata backend, and expects it back unchanged from both - the
+// column based backends split it into columns and have to join it again.
+func caseMetadataRoundTrip() test.CaseResult {
+	name := "metadata-round-trip"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer dropSampleKeys()
+
+	sqliteBackend, err := metadataStorage.Open("sqlite", dir)
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer sqliteBackend.Cleanup() //nolint:errcheck
+
+	key := sampleKey("round-trip.md")
+	entry := map[string]any{
+		"path":               key,
+		"title":              "A",
+		"createdAt":          "2024-03-15T09:30:00Z",
+		"lastEdited":         "2024-04-01T12:00:00Z",
+		"collection":         "test",
+		"folders":            []any{"test", "test/storage-tests"},
+		"tags":               []any{"alpha", "beta"},
+		"parents":            []any{sampleKey("b.md")},
+		"usedLinks":          []any{sampleKey("c.md")},
+		"editor":             "toastui-editor",
+		"label":              "green",
+		"size":               float64(42),
+		"wordCount":          float64(250),
+		"readingTimeMinutes": float64(2),
+		"references":         []any{map[string]any{"path": sampleKey("c.md")}},
+		"kanbanAddedAt":      "2024-03-16T00:00:00Z",
+		"custom":             map[string]any{"status": "draft"},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	var failures []string
+	check := func(backend string, set func(string, []byte) error, get func(string) ([]byte, error)) {
+		if err := set(key, data); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: Set: %v", backend, err))
+			return
+		}
+		got, err := get(key)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: Get: %v", backend, err))
+			return
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(got, &decoded); err != nil || !reflect.DeepEqual(decoded, entry) {
+			failures = append(failures, fmt.Sprintf("%s: got %s", backend, got))
+		}
+	}
+	check("sqlite", sqliteBackend.Set, sqliteBackend.Get)
+	check("active "+metadataStorage.GetBackendType(), metadataStorage.Set, metadataStorage.Get)
+
+	return checkResult(name, "entry read back unchanged from sqlite and the active backend", failures, "metadata entry changed in a round trip")
+}
+
+// caseMetadataDeletePrefix deletes a sample folder's keys from the active metadata storage.
+// Prefixes are matched case-sensitively and literally, so "_" matches no other character.
+func caseMetadataDeletePrefix() test.CaseResult {
+	name := "metadata-delete-prefix"
+	defer dropSampleKeys()
+
+	for _, key := range []string{"foo/a.md", "foo/sub/b.md", "Foo/c.md", "foobar.md", "fxo/d.md"} {
+		if err := metadataStorage.Set(sampleKey(key), []byte(`{"path":"`+sampleKey(key)+`"}`)); err != nil {
+			return errCase(name, err)
+		}
+	}
+
+	literal, err := metadataStorage.DeletePrefix(sampleKey("f_o/"))
+	if err != nil {
+		return errCase(name, err)
+	}
+	deleted, err := metadataStorage.DeletePrefix(sampleKey("foo/"))
+	if err != nil {
+		return errCase(name, err)
+	}
+	remaining, err := sampleKeys()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	want := []string{sampleKey("Foo/c.md"), sampleKey("foobar.md"), sampleKey("fxo/d.md")}
+	success := literal == 0 && deleted == 2 && slices.Equal(remaining, want)
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("f_o/ deleted 0, foo/ deleted 2, remaining %v", want),
+		Actual:   fmt.Sprintf("f_o/ deleted %d, foo/ deleted %d, remaining %v", literal, deleted, remaining),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "DeletePrefix deleted the wrong keys on the " + metadataStorage.GetBackendType() + " backend"
+	}
+	return cr
+}
+
+// caseMetadataBulkSet stores sample entries in one BulkSet call on the active metadata
+// storage. On sqlite, whose bulk write is a transaction, a batch with an invalid entry has to
+// store nothing.
+func caseMetadataBulkSet() test.CaseResult {
+	name := "metadata-bulk-set"
+	defer dropSampleKeys()
+
+	a, b := sampleKey("a.md"), sampleKey("sub/b.md")
+	if err := metadataStorage.BulkSet(map[string][]byte{
+		a: []byte(`{"path":"` + a + `","tags":["x"]}`),
+		b: []byte(`{"path":"` + b + `","tags":["x"]}`),
+	}); err != nil {
+		return errCase(name, err)
+	}
+	stored, err := sampleKeys()
+	if err != nil {
+		return errCase(name, err)
+	}
+	var decoded map[string]any
+	data, err := metadataStorage.Get(b)
+	if err == nil {
+		err = json.Unmarshal(data, &decoded)
+	}
+	tagsKept := err == nil && reflect.DeepEqual(decoded["tags"], []any{"x"})
+	success := slices.Equal(stored, []string{a, b}) && tagsKept
+	actual := fmt.Sprintf("stored %v, tags kept=%v", stored, tagsKept)
+
+	if metadataStorage.GetBackendType() == "sqlite" {
+		ok := sampleKey("ok.md")
+		batchErr := metadataStorage.BulkSet(map[string][]byte{
+			ok:                  []byte(`{"path":"` + ok + `"}`),
+			sampleKey("bad.md"): []byte(`not json`),
+		})
+		partial := metadataStorage.Exists(ok)
+		success = success && batchErr != nil && !partial
+		actual += fmt.Sprintf(", failing batch error=%v, stored part of it=%v", batchErr != nil, partial)
+	}
+
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("stored %v, tags kept=true (sqlite: failing batch stores nothing)", []string{a, b}),
+		Actual:   actual,
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "BulkSet stored the wrong entries on the " + metadataStorage.GetBackendType() + " backend"
+	}
+	return cr
+}
+
+// caseMetadataExportSQLite exports the active metadata storage into a sqlite file, like GET
+// /api/metadata/export.db, and expects the sample entries and one row per stored entry in its
+// metadata table, whatever the active backend is.
+func caseMetadataExportSQLite() test.CaseResult {
+	name := "metadata-export-sqlite"
+	dir, cleanup, err := tempStoragePath()
+	defer cleanup()
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer dropSampleKeys()
+
+	a, b := sampleKey("a.md"), sampleKey("sub/b.md")
+	if err := metadataStorage.BulkSet(map[string][]byte{
+		a: []byte(`{"path":"` + a + `","title":"A","tags":["x"]}`),
+		b: []byte(`{"path":"` + b + `","title":"B"}`),
+	}); err != nil {
+		return errCase(name, err)
+	}
+
+	path := filepath.Join(dir, "export.db")
+	if err := metadataStorage.ExportSQLite(path); err != nil {
+		return errCase(name, err)
+	}
+	db, err := sql.Open("sqlite", path+"?mode=ro")
+	if err != nil {
+		return errCase(name, err)
+	}
+	defer db.Close()
+
+	var count, total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM metadata WHERE path LIKE ?", sampleKey("")+"%").Scan(&count); err != nil {
+		return errCase(name, err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM metadata").Scan(&total); err != nil {
+		return errCase(name, err)
+	}
+	var title string
+	if err := db.QueryRow("SELECT title FROM metadata WHERE path = ?", a).Scan(&title); err != nil && err != sql.ErrNoRows {
+		return errCase(name, err)
+	}
+	all, err := metadataStorage.GetAll()
+	if err != nil {
+		return errCase(name, err)
+	}
+
+	success := count == 2 && title == "A" && total == len(all)
+	cr := test.CaseResult{
+		Name:     name,
+		Expected: fmt.Sprintf("2 sample rows, title A, %d rows in all", len(all)),
+		Actual:   fmt.Sprintf("%d sample rows, title %q, %d rows in all", count, title, total),
+		Success:  success,
+	}
+	if !success {
+		cr.Error = "export of the " + metadataStorage.GetBackendType() + " backend is missing metadata"
+	}
+	return cr
+}
@@ -21,6 +21,7 @@ import (
 	"knov/internal/contentHandler"
 	"knov/internal/contentStorage"
 	"knov/internal/kanbanStorage"
+	"knov/internal/metadataHistoryStorage"
 	"knov/internal/metadataStorage"
 	"knov/internal/notificationStorage"
 	"knov/internal/parser"
@@ -33,7 +34,7 @@ import (
 // repoThemesPath resolves the absolute path to the repo's themes/ dir, so
 // tests can load real themes from disk regardless of the test's working
 // directory.
-func repoThemesPath(t *testing.T) string {
+func repoThemesPath(t testing.TB) string {
 	t.Helper()
 	_, thisFile, _, ok := runtime.Caller(0)
 	if !ok {
@@ -51,7 +52,7 @@ func repoThemesPath(t *testing.T) string {
 // NewApp initializes the app against a temp data/storage dir (with a
 // fresh local git repo) and returns an httptest.Server backed by the real
 // router. The server and all temp files are cleaned up automatically.
-func NewApp(t *testing.T) *httptest.Server {
+func NewApp(t testing.TB) *httptest.Server {
 	t.Helper()
 
 	dataPath := filepath.Join(t.TempDir(), "data")
@@ -102,6 +103,9 @@ func NewApp(t *testing.T) *httptest.Server {
 	if err := notificationStorage.Init(appConfig.StoragePath); err != nil {
 		t.Fatalf("notificationStorage.Init: %v", err)
 	}
+	if err := metadataHistoryStorage.Init(appConfig.StoragePath); err != nil {
+		t.Fatalf("metadataHistoryStorage.Init: %v", err)
+	}
 
 	configmanager.InitSettings()
 	translation.Init()
@@ -23,9 +23,10 @@ type SystemPageData struct {
 	Content     template.HTML
 }
 
-// RenderSystemPage renders an app-controlled system page using the current theme's base
-func (tm *ThemeManager) RenderSystemPage(w http.ResponseWriter, title string, content template.HTML) error {
-	currentTheme := tm.GetCurrentTheme()
+// RenderSystemPage renders an app-controlled system page using the base of the theme the
+// request asks for (see ThemeForRequest)
+func (tm *ThemeManager) RenderSystemPage(w http.ResponseWriter, r *http.Request, title string, content template.HTML) error {
+	currentTheme := tm.ThemeForRequest(r)
 	themesDir := configmanager.GetThemesPath()
 	baseFilePath := filepath.Join(themesDir, currentTheme.Name, "base.gohtml")
 
@@ -44,6 +45,7 @@ func (tm *ThemeManager) RenderSystemPage(w http.ResponseWriter, title string, co
 		SystemTitle:      title,
 		Content:          content,
 	}
+	data.CurrentTheme = currentTheme.Name
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
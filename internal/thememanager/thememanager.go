@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"text/template"
 
@@ -263,12 +264,17 @@ func LoadSingleTheme(themeName, themesDir string) error {
 // -------------------- Render --------------------
 // -----------------------------------------------
 
-func (tm *ThemeManager) Render(w http.ResponseWriter, templateName string, data any) error {
+// Render renders a page template of the theme the request asks for (see ThemeForRequest).
+func (tm *ThemeManager) Render(w http.ResponseWriter, r *http.Request, templateName string, data any) error {
 	var template *template.Template
 
-	template, err := tm.GetTemplate(templateName)
-	if err != nil {
-		return err
+	theme := tm.ThemeForRequest(r)
+	template, ok := theme.TemplateMap()[templateName]
+	if !ok {
+		return fmt.Errorf("unknown template: %s", templateName)
+	}
+	if theme.Name != tm.GetCurrentThemeName() {
+		data = withCurrentTheme(data, theme.Name)
 	}
 
 	if template == nil {
@@ -277,7 +283,7 @@ func (tm *ThemeManager) Render(w http.ResponseWriter, templateName string, data
 
 	// todo: make config
 	overwritePath := filepath.Join("themes", "overwrite", templateName+".gohtml")
-	err = validateTemplateFile(overwritePath)
+	err := validateTemplateFile(overwritePath)
 	if err == nil {
 		overwriteTemplate, parseErr := template.ParseFiles(overwritePath)
 
@@ -308,6 +314,46 @@ func (tm *ThemeManager) Render(w http.ResponseWriter, templateName string, data
 	return err
 }
 
+// ThemeForRequest returns the theme a request renders with: the loaded theme named by its
+// theme query parameter when the themeQueryOverride setting allows it, the current theme
+// otherwise. The current theme setting itself is not changed.
+func (tm *ThemeManager) ThemeForRequest(r *http.Request) Theme {
+	if name := r.URL.Query().Get("theme"); name != "" && configmanager.GetThemeQueryOverride() {
+		for _, theme := range tm.themes {
+			if theme.Name == name {
+				return theme
+			}
+		}
+		logging.LogDebug(logging.KeyApp, "requested theme '%s' not found, using the current theme", name)
+	}
+
+	currentTheme := tm.GetCurrentTheme()
+	// if current theme is empty, try to set builtin as default
+	if currentTheme.Name == "" {
+		setBuiltinAsDefault()
+		currentTheme = tm.GetCurrentTheme()
+	}
+	return currentTheme
+}
+
+// withCurrentTheme returns a copy of template data embedding BaseTemplateData with its
+// CurrentTheme set to name, so a page rendered with another theme links that theme's assets.
+// Other data is returned as is.
+func withCurrentTheme(data any, name string) any {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Struct {
+		return data
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	field := c.FieldByName("CurrentTheme")
+	if !field.IsValid() || field.Kind() != reflect.String || !field.CanSet() {
+		return data
+	}
+	field.SetString(name)
+	return c.Interface()
+}
+
 // injectDefaultCSS injects static CSS links that every theme requires into </head>.
 func injectDefaultCSS(html string) string {
 	headCloseIndex := strings.Index(html, "</head>")
@@ -2,16 +2,24 @@
 package translation
 
 import (
+	"errors"
+	"sync/atomic"
+
 	"knov/internal/logging"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
 )
 
 //go:generate sh -c "../../static/generate-translations.sh"
 
 var globalPrinter *message.Printer
 
+// fallbackLanguages are tried in order when a key is missing in the requested language and
+// its parents (de-AT -> de); the key itself, the reference language, comes last.
+var fallbackLanguages atomic.Pointer[[]language.Tag]
+
 // Init ..
 func Init() {
 	globalPrinter = message.NewPrinter(language.English)
@@ -48,25 +56,61 @@ func Sprintf(key string, args ...any) string {
 }
 
 // SprintfForRequest creates a language-specific printer and translates text
-// Use this for HTMX responses to get proper per-user translations
+// Use this for HTMX responses to get proper per-user translations. A key missing in lang
+// is looked up in its parent languages, then in the fallback languages, and finally
+// printed untranslated.
 func SprintfForRequest(lang string, key string, args ...any) string {
-	// Create language-specific printer
-	tag, _ := language.MatchStrings(language.NewMatcher([]language.Tag{
-		language.English,
-		language.German,
-	}), lang)
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
 
-	printer := message.NewPrinter(tag)
-	translated := printer.Sprintf(key, args...)
+	printer := message.NewPrinter(lookupLanguage(tag, key))
+	return printer.Sprintf(key, args...)
+}
 
-	// If translation failed, fallback to key
-	// if translated == key && len(args) == 0 {
-	// 	logging.LogDebug(logging.KeyApp, "translation missing for key: %s (lang: %s)", key, lang)
-	// }
+// SetFallbackLanguages sets the languages tried when a key is missing in the requested
+// language and its parents. Unparsable codes are skipped (logged).
+func SetFallbackLanguages(langs []string) {
+	tags := make([]language.Tag, 0, len(langs))
+	for _, lang := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			logging.LogWarning(logging.KeyApp, "ignoring invalid fallback language %q: %v", lang, err)
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	fallbackLanguages.Store(&tags)
+}
 
-	return translated
+// lookupLanguage returns the first language of the fallback chain of tag whose catalog
+// has key, or tag when none has it.
+func lookupLanguage(tag language.Tag, key string) language.Tag {
+	chain := []language.Tag{tag}
+	if fallbacks := fallbackLanguages.Load(); fallbacks != nil {
+		chain = append(chain, *fallbacks...)
+	}
+	for _, candidate := range chain {
+		if hasKey(candidate, key) {
+			return candidate
+		}
+	}
+	return tag
 }
 
+// hasKey reports whether the default catalog has key for tag or one of its parents.
+func hasKey(tag language.Tag, key string) bool {
+	err := message.DefaultCatalog.Context(tag, discardRenderer{}).Execute(key)
+	return !errors.Is(err, catalog.ErrNotFound)
+}
+
+// discardRenderer lets hasKey execute a catalog message without formatting it.
+type discardRenderer struct{}
+
+func (discardRenderer) Render(string) {}
+func (discardRenderer) Arg(int) any   { return nil }
+
 // func getBrowserLocale(r *http.Request) string {
 // 	acceptLanguage := r.Header.Get("Accept-Language")
 // 	if acceptLanguage == "" {
@@ -0,0 +1,253 @@
+// Package watcher watches the docs and media folders for changes made outside of knov (git
+// pull, external editors), reindexes the changed files and publishes them as events.
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"knov/internal/files"
+	"knov/internal/logging"
+	"knov/internal/pathutils"
+)
+
+// event types
+const (
+	EventFileChanged = "file-changed"
+	EventFileDeleted = "file-deleted"
+)
+
+// Event is a change of one file.
+type Event struct {
+	Type string `json:"type"`
+	Path string `json:"path"` // docs/ or media/ prefixed
+}
+
+// ErrTooManySubscribers is returned by Subscribe when the subscriber limit is reached.
+var ErrTooManySubscribers = errors.New("too many event subscribers")
+
+// debounce is how long a path has to stay quiet before it is reindexed and published:
+// editors and git write a file in several steps.
+const debounce = 500 * time.Millisecond
+
+// subscriberBuffer is how many events a slow subscriber can fall behind before further
+// events are dropped for it.
+const subscriberBuffer = 64
+
+var (
+	mu          sync.Mutex
+	subscribers = map[chan Event]struct{}{}
+	watcher     *fsnotify.Watcher
+	stopChan    chan struct{}
+	done        chan struct{}
+)
+
+// Start watches the docs and media folders and their subfolders. Folders created later are
+// watched as they appear. Hidden files and folders (.git, editor swap files) are ignored.
+func Start() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if watcher != nil {
+		return nil
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, root := range []string{pathutils.ToDocsPath(""), pathutils.ToMediaPath("")} {
+		addRecursive(w, root)
+	}
+
+	watcher, stopChan, done = w, make(chan struct{}), make(chan struct{})
+	go run(w, stopChan, done)
+	logging.LogInfo(logging.KeyApp, "file watcher started")
+	return nil
+}
+
+// Stop stops watching and closes the channels of all subscribers.
+func Stop() {
+	mu.Lock()
+	if watcher == nil {
+		mu.Unlock()
+		return
+	}
+	close(stopChan)
+	w, finished := watcher, done
+	watcher = nil
+	mu.Unlock()
+
+	<-finished
+	w.Close()
+
+	mu.Lock()
+	for ch := range subscribers {
+		close(ch)
+		delete(subscribers, ch)
+	}
+	mu.Unlock()
+	logging.LogInfo(logging.KeyApp, "file watcher stopped")
+}
+
+// Running reports whether the folders are watched.
+func Running() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return watcher != nil
+}
+
+// Subscribe returns a channel receiving every published event and a function ending the
+// subscription, which must be called. At most limit subscriptions are open at a time.
+func Subscribe(limit int) (<-chan Event, func(), error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if len(subscribers) >= limit {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	subscribers[ch] = struct{}{}
+	unsubscribe := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := subscribers[ch]; ok {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// Publish sends events to every subscriber without blocking: a subscriber whose buffer is
+// full misses them.
+func Publish(events ...Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	for ch := range subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+				logging.LogDebug(logging.KeyApp, "dropped %s event for %s: subscriber too slow", event.Type, event.Path)
+			}
+		}
+	}
+}
+
+// run collects the changed paths and flushes them once they were quiet for debounce.
+func run(w *fsnotify.Watcher, stop, finished chan struct{}) {
+	defer close(finished)
+
+	pending := map[string]struct{}{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			logging.LogWarning(logging.KeyApp, "file watcher error: %v", err)
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if isHidden(event.Name) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addRecursive(w, event.Name)
+					// files written into the folder before it was watched (e.g. a git pull
+					// bringing in a new folder) send no events of their own
+					queueFiles(event.Name, pending)
+					timer.Reset(debounce)
+					continue
+				}
+			}
+			pending[event.Name] = struct{}{}
+			timer.Reset(debounce)
+		case <-timer.C:
+			flush(pending)
+			pending = map[string]struct{}{}
+		}
+	}
+}
+
+// flush reindexes the changed files and publishes an event for each of them.
+func flush(pending map[string]struct{}) {
+	paths := make([]string, 0, len(pending))
+	for name := range pending {
+		paths = append(paths, pathutils.ToWithPrefix(name))
+	}
+	files.ReindexPaths(logging.KeyApp, paths)
+
+	events := make([]Event, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(pathutils.ToFullPath(path))
+		switch {
+		case err != nil:
+			events = append(events, Event{Type: EventFileDeleted, Path: path})
+		case !info.IsDir():
+			events = append(events, Event{Type: EventFileChanged, Path: path})
+		}
+	}
+	logging.LogDebug(logging.KeyApp, "file watcher: %d files changed", len(events))
+	Publish(events...)
+}
+
+// addRecursive watches dir and every non-hidden folder below it.
+func addRecursive(w *fsnotify.Watcher, dir string) {
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && isHidden(path) {
+			return filepath.SkipDir
+		}
+		if err := w.Add(path); err != nil {
+			logging.LogWarning(logging.KeyApp, "file watcher: failed to watch %s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "file watcher: failed to walk %s: %v", dir, err)
+	}
+}
+
+// queueFiles adds every non-hidden file below dir to pending.
+func queueFiles(dir string, pending map[string]struct{}) {
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != dir && isHidden(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			pending[path] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.LogWarning(logging.KeyApp, "file watcher: failed to walk %s: %v", dir, err)
+	}
+}
+
+// isHidden reports whether the file name starts with a dot or ends with '~' (editor backups).
+func isHidden(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~")
+}
@@ -11,12 +11,14 @@ import (
 	"knov/internal/configmanager"
 	"knov/internal/contentHandler"
 	"knov/internal/contentStorage"
+	"knov/internal/dbmigration"
 	"knov/internal/files"
 	"knov/internal/filter"
 	"knov/internal/git"
 	"knov/internal/job"
 	"knov/internal/kanbanStorage"
 	"knov/internal/logging"
+	"knov/internal/metadataHistoryStorage"
 	"knov/internal/metadataStorage"
 	"knov/internal/notificationStorage"
 	"knov/internal/parser"
@@ -27,6 +29,7 @@ import (
 	"knov/internal/test"
 	"knov/internal/thememanager"
 	"knov/internal/translation"
+	"knov/internal/watcher"
 )
 
 //go:embed static/*
@@ -74,6 +77,10 @@ func main() {
 	// initialize storage backends
 	appConfig := configmanager.GetAppConfig()
 
+	if appConfig.StorageCompactOnStart {
+		dbmigration.CompactDatabases(appConfig.StoragePath, appConfig.StorageCompactThreshold)
+	}
+
 	if err := configStorage.Init(appConfig.ConfigStorageProvider, appConfig.StoragePath); err != nil {
 		logging.LogError(logging.KeyApp, "failed to initialize config storage: %v", err)
 		return
@@ -109,12 +116,29 @@ func main() {
 		return
 	}
 
+	if err := metadataHistoryStorage.Init(appConfig.StoragePath); err != nil {
+		logging.LogError(logging.KeyApp, "failed to initialize metadata history storage: %v", err)
+		return
+	}
+
 	configmanager.InitSettings()
 	translation.SetLanguage(configmanager.GetLanguage())
 
 	thememanager.InitThemeManager()
 	// register filter index regeneration to run after every metadata rebuild
 	files.OnMetadataRebuild = filter.RegenerateAllIndexes
+	// move metadata in or out of the archive tier when archiveTier is changed in the settings
+	configmanager.ArchiveTier.OnChange = func(interface{}) {
+		go func() {
+			if _, err := files.MigrateArchiveTier(); err != nil {
+				logging.LogError(logging.KeyApp, "failed to migrate the archive tier: %v", err)
+			}
+		}()
+	}
+
+	if err := watcher.Start(); err != nil {
+		logging.LogWarning(logging.KeyApp, "failed to start file watcher, changes made outside knov are picked up by the reindex job only: %v", err)
+	}
 
 	go func() {
 		if err := search.InitSearch(); err != nil {
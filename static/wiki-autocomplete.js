@@ -409,21 +409,49 @@
 
     var suggestions = [];
 
-    fetch(apiEndpoint)
-      .then(function (r) {
-        return r.text();
-      })
-      .then(function (html) {
-        var tmp = document.createElement("datalist");
-        tmp.innerHTML = html;
-        suggestions = Array.from(tmp.options)
-          .map(function (o) {
-            return o.value;
-          })
-          .filter(Boolean);
+    function fetchOptions(url) {
+      return fetch(url)
+        .then(function (r) {
+          return r.text();
+        })
+        .then(function (html) {
+          var tmp = document.createElement("datalist");
+          tmp.innerHTML = html;
+          return Array.from(tmp.options)
+            .map(function (o) {
+              return o.value;
+            })
+            .filter(Boolean);
+        });
+    }
+
+    function toItems(values) {
+      return values.map(function (s) {
+        var parts = s.replace(/\/$/, "").split("/");
+        return { filename: parts[parts.length - 1], path: s };
+      });
+    }
+
+    fetchOptions(apiEndpoint)
+      .then(function (values) {
+        suggestions = values;
       })
       .catch(function () {});
 
+    // the option lists are capped server-side (most used first), so when
+    // nothing preloaded matches ask the endpoint for matches of the input
+    function debouncedFetchOptions(q) {
+      clearTimeout(fetchTimer);
+      fetchTimer = setTimeout(function () {
+        var sep = apiEndpoint.indexOf("?") === -1 ? "?" : "&";
+        fetchOptions(apiEndpoint + sep + "q=" + encodeURIComponent(q))
+          .then(function (values) {
+            if (inputEl.value === q) show(toItems(values), inputEl);
+          })
+          .catch(hide);
+      }, 120);
+    }
+
     attachSharedKeydown(inputEl);
 
     function refresh() {
@@ -450,14 +478,15 @@
       }
 
       var vl = v.toLowerCase();
-      var items = suggestions
-        .filter(function (s) {
+      var items = toItems(
+        suggestions.filter(function (s) {
           return s.toLowerCase().includes(vl);
-        })
-        .map(function (s) {
-          var parts = s.replace(/\/$/, "").split("/");
-          return { filename: parts[parts.length - 1], path: s };
-        });
+        }),
+      );
+      if (items.length === 0) {
+        debouncedFetchOptions(v);
+        return;
+      }
       show(items, inputEl);
     }
 